@@ -17,6 +17,7 @@ import (
 	"nofx/market"
 	"nofx/provider/nofxos"
 	"nofx/store"
+	"nofx/trader"
 
 	"github.com/gin-gonic/gin"
 )
@@ -33,16 +34,42 @@ func (s *Server) registerBacktestRoutes(router *gin.RouterGroup) {
 	router.GET("/equity", s.handleBacktestEquity)
 	router.GET("/trades", s.handleBacktestTrades)
 	router.GET("/metrics", s.handleBacktestMetrics)
+	router.GET("/montecarlo", s.handleBacktestMonteCarlo)
+	router.GET("/compare", s.handleBacktestCompare)
 	router.GET("/trace", s.handleBacktestTrace)
 	router.GET("/decisions", s.handleBacktestDecisions)
 	router.GET("/export", s.handleBacktestExport)
 	router.GET("/klines", s.handleBacktestKlines)
+	router.POST("/sweep/start", s.handleBacktestSweepStart)
+	router.POST("/sweep/stop", s.handleBacktestSweepStop)
+	router.GET("/sweep", s.handleBacktestSweepStatus)
+	router.GET("/sweeps", s.handleBacktestSweeps)
+	router.POST("/walkforward/start", s.handleBacktestWalkForwardStart)
+	router.POST("/walkforward/stop", s.handleBacktestWalkForwardStop)
+	router.GET("/walkforward", s.handleBacktestWalkForwardStatus)
+	router.GET("/walkforwards", s.handleBacktestWalkForwards)
 }
 
 type backtestStartRequest struct {
 	Config backtest.BacktestConfig `json:"config"`
 }
 
+type backtestSweepRequest struct {
+	Sweep backtest.SweepConfig `json:"sweep"`
+}
+
+type sweepIDRequest struct {
+	SweepID string `json:"sweep_id"`
+}
+
+type backtestWalkForwardRequest struct {
+	WalkForward backtest.WalkForwardConfig `json:"walk_forward"`
+}
+
+type walkForwardIDRequest struct {
+	WalkForwardID string `json:"walk_forward_id"`
+}
+
 type runIDRequest struct {
 	RunID string `json:"run_id"`
 }
@@ -68,6 +95,26 @@ func (s *Server) handleBacktestStart(c *gin.Context) {
 	if cfg.RunID == "" {
 		cfg.RunID = "bt_" + time.Now().UTC().Format("20060102_150405")
 	}
+	if !s.prepareBacktestConfig(c, &cfg) {
+		return
+	}
+
+	logger.Infof("📊 Starting backtest with final config: runID=%s, symbols=%v (count=%d), strategyID=%s",
+		cfg.RunID, cfg.Symbols, len(cfg.Symbols), cfg.StrategyID)
+
+	runner, err := s.backtestManager.Start(context.Background(), cfg)
+	if err != nil {
+		SafeError(c, http.StatusBadRequest, "Failed to start backtest", err)
+		return
+	}
+
+	meta := runner.CurrentMetadata()
+	c.JSON(http.StatusOK, meta)
+}
+
+// prepareBacktestConfig loads the saved strategy and AI model a backtest config refers to.
+// On failure it writes the error response and returns false.
+func (s *Server) prepareBacktestConfig(c *gin.Context, cfg *backtest.BacktestConfig) bool {
 	cfg.CustomPrompt = strings.TrimSpace(cfg.CustomPrompt)
 	cfg.UserID = normalizeUserID(c.GetString("user_id"))
 
@@ -79,16 +126,24 @@ func (s *Server) handleBacktestStart(c *gin.Context) {
 		strategy, err := s.store.Strategy().Get(cfg.UserID, cfg.StrategyID)
 		if err != nil {
 			SafeBadRequest(c, "Failed to load strategy")
-			return
+			return false
 		}
 		if strategy == nil {
 			SafeBadRequest(c, "Strategy not found")
-			return
+			return false
 		}
 		var strategyConfig store.StrategyConfig
 		if err := json.Unmarshal([]byte(strategy.Config), &strategyConfig); err != nil {
 			SafeBadRequest(c, "Failed to parse strategy config")
-			return
+			return false
+		}
+		if strategyConfig.StrategyType == "dca" {
+			SafeBadRequest(c, "DCA strategies cannot be backtested yet")
+			return false
+		}
+		if strategyConfig.StrategyType == "funding_arb" {
+			SafeBadRequest(c, "Funding arbitrage strategies cannot be backtested")
+			return false
 		}
 		cfg.SetLoadedStrategy(&strategyConfig)
 		logger.Infof("📊 Backtest using saved strategy: %s (%s)", strategy.Name, strategy.ID)
@@ -98,34 +153,129 @@ func (s *Server) handleBacktestStart(c *gin.Context) {
 			strategyConfig.CoinSource.UseOITop,
 			strategyConfig.CoinSource.StaticCoins)
 
-		// If no symbols provided, fetch from strategy's coin source
-		if len(cfg.Symbols) == 0 {
+		// If no symbols provided, fetch from strategy's coin source (grid and pairs strategies trade their own symbols;
+		// coin sources only list crypto, so stock/forex/metal runs must name their symbols)
+		if len(cfg.Symbols) == 0 && !cfg.IsMechanical() && cfg.IsCrypto() {
 			symbols, err := s.resolveStrategyCoins(&strategyConfig)
 			if err != nil {
 				SafeBadRequest(c, "Failed to resolve coins from strategy")
-				return
+				return false
 			}
 			cfg.Symbols = symbols
 			logger.Infof("📊 Resolved %d coins from strategy: %v", len(symbols), symbols)
 		}
 	}
 
-	if err := s.hydrateBacktestAIConfig(&cfg); err != nil {
-		SafeBadRequest(c, "Failed to configure AI model")
-		return
+	if cfg.IsLiveReplay() && !s.prepareLiveReplay(c, cfg) {
+		return false
 	}
 
-	logger.Infof("📊 Starting backtest with final config: runID=%s, symbols=%v (count=%d), strategyID=%s",
-		cfg.RunID, cfg.Symbols, len(cfg.Symbols), cfg.StrategyID)
+	if cfg.Margin != nil && strings.TrimSpace(cfg.Margin.SpecTraderID) != "" && !s.loadMarginBrackets(c, cfg) {
+		return false
+	}
 
-	runner, err := s.backtestManager.Start(context.Background(), cfg)
+	if !cfg.IsMechanical() {
+		if err := s.hydrateBacktestAIConfig(cfg); err != nil {
+			SafeBadRequest(c, "Failed to configure AI model")
+			return false
+		}
+	}
+
+	return true
+}
+
+// prepareLiveReplay checks the replayed trader belongs to the user and fills the period, symbols
+// and starting balance from its decision history when the request leaves them out.
+func (s *Server) prepareLiveReplay(c *gin.Context, cfg *backtest.BacktestConfig) bool {
+	traderID := strings.TrimSpace(cfg.LiveReplay.TraderID)
+	if _, err := s.store.Trader().GetFullConfig(c.GetString("user_id"), traderID); err != nil {
+		SafeNotFound(c, "Trader")
+		return false
+	}
+
+	end := time.Now().UTC()
+	if cfg.EndTS > 0 {
+		end = time.Unix(cfg.EndTS, 0).UTC()
+	}
+	start := time.Unix(cfg.StartTS, 0).UTC()
+	records, err := s.store.Decision().GetRecordsInRange(traderID, start, end)
 	if err != nil {
-		SafeError(c, http.StatusBadRequest, "Failed to start backtest", err)
-		return
+		SafeInternalError(c, "Load trader decisions", err)
+		return false
+	}
+	cycles := backtest.LiveDecisionsFromRecords(records)
+	if len(cycles) == 0 {
+		SafeBadRequest(c, "Trader has no recorded decisions in this period")
+		return false
 	}
 
-	meta := runner.CurrentMetadata()
-	c.JSON(http.StatusOK, meta)
+	if cfg.StartTS <= 0 {
+		cfg.StartTS = cycles[0].Timestamp / 1000
+	}
+	if cfg.EndTS <= 0 {
+		cfg.EndTS = cycles[len(cycles)-1].Timestamp/1000 + 1
+	}
+	if cfg.InitialBalance <= 0 {
+		for _, rec := range records {
+			if rec.AccountState.InitialBalance > 0 {
+				cfg.InitialBalance = rec.AccountState.InitialBalance
+				break
+			}
+		}
+	}
+	if len(cfg.Symbols) == 0 {
+		seen := make(map[string]bool)
+		for _, cycle := range cycles {
+			for _, dec := range cycle.Decisions {
+				if strings.HasPrefix(dec.Action, "open_") && !seen[dec.Symbol] {
+					seen[dec.Symbol] = true
+					cfg.Symbols = append(cfg.Symbols, dec.Symbol)
+				}
+			}
+		}
+	}
+	return true
+}
+
+// loadMarginBrackets fills leverage brackets the request leaves out from a live trader's exchange contract specs.
+// Symbols the exchange can't describe keep the default brackets.
+func (s *Server) loadMarginBrackets(c *gin.Context, cfg *backtest.BacktestConfig) bool {
+	traderID := strings.TrimSpace(cfg.Margin.SpecTraderID)
+	if _, err := s.store.Trader().GetFullConfig(c.GetString("user_id"), traderID); err != nil {
+		SafeNotFound(c, "Trader")
+		return false
+	}
+	autoTrader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		SafeBadRequest(c, "Trader must be loaded to read its exchange leverage brackets")
+		return false
+	}
+
+	if cfg.Margin.Brackets == nil {
+		cfg.Margin.Brackets = make(map[string][]trader.LeverageBracket)
+	}
+	for _, symbol := range cfg.Symbols {
+		symbol = market.Normalize(symbol)
+		if len(cfg.Margin.Brackets[symbol]) > 0 {
+			continue
+		}
+		spec, err := trader.ContractSpecs().Get(autoTrader.GetUnderlyingTrader(), autoTrader.GetExchange(), symbol)
+		if err != nil || spec == nil || len(spec.LeverageBrackets) == 0 {
+			logger.Infof("📊 No exchange leverage brackets for %s, using defaults", symbol)
+			continue
+		}
+		cfg.Margin.Brackets[symbol] = spec.LeverageBrackets
+	}
+	return true
+}
+
+// loadLiveDecisions is the backtest manager's source of recorded live cycles for live replay runs.
+func (s *Server) loadLiveDecisions(cfg *backtest.BacktestConfig) ([]backtest.LiveDecision, error) {
+	records, err := s.store.Decision().GetRecordsInRange(cfg.LiveReplay.TraderID, time.Unix(cfg.StartTS, 0), time.Unix(cfg.EndTS, 0))
+	if err != nil {
+		return nil, err
+	}
+	return backtest.LiveDecisionsFromRecords(records), nil
 }
 
 func (s *Server) handleBacktestPause(c *gin.Context) {
@@ -225,6 +375,190 @@ func (s *Server) handleBacktestDelete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
 }
 
+func (s *Server) handleBacktestSweepStart(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+
+	var req backtestSweepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+
+	sweep := req.Sweep
+	if sweep.SweepID == "" {
+		sweep.SweepID = "sweep_" + time.Now().UTC().Format("20060102_150405")
+	}
+	if !s.prepareBacktestConfig(c, &sweep.Base) {
+		return
+	}
+	sweep.UserID = sweep.Base.UserID
+
+	status, err := s.backtestManager.StartSweep(context.Background(), sweep)
+	if err != nil {
+		SafeError(c, http.StatusBadRequest, "Failed to start backtest sweep", err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+func (s *Server) handleBacktestSweepStop(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+	var req sweepIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.SweepID) == "" {
+		SafeBadRequest(c, "sweep_id is required")
+		return
+	}
+	userID := normalizeUserID(c.GetString("user_id"))
+	if _, err := s.ensureBacktestSweepOwnership(req.SweepID, userID); writeBacktestAccessError(c, err) {
+		return
+	}
+	if err := s.backtestManager.StopSweep(req.SweepID); err != nil {
+		SafeError(c, http.StatusBadRequest, "Failed to stop backtest sweep", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "stopping"})
+}
+
+func (s *Server) handleBacktestSweepStatus(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+	sweepID := c.Query("sweep_id")
+	if sweepID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sweep_id is required"})
+		return
+	}
+	userID := normalizeUserID(c.GetString("user_id"))
+	status, err := s.ensureBacktestSweepOwnership(sweepID, userID)
+	if writeBacktestAccessError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+func (s *Server) handleBacktestSweeps(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+	rawUserID := strings.TrimSpace(c.GetString("user_id"))
+	userID := normalizeUserID(rawUserID)
+	filterByUser := rawUserID != "" && rawUserID != "admin"
+
+	sweeps, err := s.backtestManager.ListSweeps()
+	if err != nil {
+		SafeInternalError(c, "List backtest sweeps", err)
+		return
+	}
+	filtered := make([]*backtest.SweepStatus, 0, len(sweeps))
+	for _, sweep := range sweeps {
+		if filterByUser && sweep.UserID != "" && sweep.UserID != userID {
+			continue
+		}
+		filtered = append(filtered, sweep)
+	}
+	c.JSON(http.StatusOK, gin.H{"total": len(filtered), "items": filtered})
+}
+
+func (s *Server) handleBacktestWalkForwardStart(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+
+	var req backtestWalkForwardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+
+	wf := req.WalkForward
+	if wf.WalkForwardID == "" {
+		wf.WalkForwardID = "wf_" + time.Now().UTC().Format("20060102_150405")
+	}
+	if !s.prepareBacktestConfig(c, &wf.Sweep.Base) {
+		return
+	}
+	wf.UserID = wf.Sweep.Base.UserID
+
+	status, err := s.backtestManager.StartWalkForward(context.Background(), wf)
+	if err != nil {
+		SafeError(c, http.StatusBadRequest, "Failed to start walk-forward analysis", err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+func (s *Server) handleBacktestWalkForwardStop(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+	var req walkForwardIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.WalkForwardID) == "" {
+		SafeBadRequest(c, "walk_forward_id is required")
+		return
+	}
+	userID := normalizeUserID(c.GetString("user_id"))
+	if _, err := s.ensureBacktestWalkForwardOwnership(req.WalkForwardID, userID); writeBacktestAccessError(c, err) {
+		return
+	}
+	if err := s.backtestManager.StopWalkForward(req.WalkForwardID); err != nil {
+		SafeError(c, http.StatusBadRequest, "Failed to stop walk-forward analysis", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "stopping"})
+}
+
+func (s *Server) handleBacktestWalkForwardStatus(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+	id := c.Query("walk_forward_id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "walk_forward_id is required"})
+		return
+	}
+	userID := normalizeUserID(c.GetString("user_id"))
+	status, err := s.ensureBacktestWalkForwardOwnership(id, userID)
+	if writeBacktestAccessError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+func (s *Server) handleBacktestWalkForwards(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+	rawUserID := strings.TrimSpace(c.GetString("user_id"))
+	userID := normalizeUserID(rawUserID)
+	filterByUser := rawUserID != "" && rawUserID != "admin"
+
+	items, err := s.backtestManager.ListWalkForwards()
+	if err != nil {
+		SafeInternalError(c, "List walk-forward analyses", err)
+		return
+	}
+	filtered := make([]*backtest.WalkForwardStatus, 0, len(items))
+	for _, item := range items {
+		if filterByUser && item.UserID != "" && item.UserID != userID {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+	c.JSON(http.StatusOK, gin.H{"total": len(filtered), "items": filtered})
+}
+
 func (s *Server) handleBacktestStatus(c *gin.Context) {
 	if s.backtestManager == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
@@ -410,6 +744,83 @@ func (s *Server) handleBacktestMetrics(c *gin.Context) {
 	c.JSON(http.StatusOK, metrics)
 }
 
+func (s *Server) handleBacktestMonteCarlo(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+	userID := normalizeUserID(c.GetString("user_id"))
+	runID := c.Query("run_id")
+	if runID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "run_id is required"})
+		return
+	}
+	if _, err := s.ensureBacktestRunOwnership(runID, userID); writeBacktestAccessError(c, err) {
+		return
+	}
+
+	cfg := backtest.MonteCarloConfig{
+		Method:     c.Query("method"),
+		Iterations: queryInt(c, "iterations", 0),
+		Seed:       int64(queryInt(c, "seed", 0)),
+	}
+	if value := c.Query("skip_pct"); value != "" {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			SafeBadRequest(c, "Invalid skip_pct")
+			return
+		}
+		cfg.SkipPct = v
+	}
+	if value := c.Query("ruin"); value != "" {
+		for _, part := range strings.Split(value, ",") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				SafeBadRequest(c, "Invalid ruin thresholds")
+				return
+			}
+			cfg.RuinThresholds = append(cfg.RuinThresholds, v)
+		}
+	}
+
+	result, err := s.backtestManager.MonteCarlo(runID, cfg)
+	if err != nil {
+		SafeError(c, http.StatusBadRequest, "Failed to run Monte Carlo analysis", err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) handleBacktestCompare(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+	userID := normalizeUserID(c.GetString("user_id"))
+	raw := c.Query("run_ids")
+	if raw == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "run_ids is required"})
+		return
+	}
+	runIDs := strings.Split(raw, ",")
+	for _, runID := range runIDs {
+		runID = strings.TrimSpace(runID)
+		if runID == "" {
+			continue
+		}
+		if _, err := s.ensureBacktestRunOwnership(runID, userID); writeBacktestAccessError(c, err) {
+			return
+		}
+	}
+
+	comparison, err := s.backtestManager.CompareRuns(runIDs, c.Query("tf"), queryInt(c, "limit", 1000))
+	if err != nil {
+		SafeError(c, http.StatusBadRequest, "Failed to compare backtest runs", err)
+		return
+	}
+	c.JSON(http.StatusOK, comparison)
+}
+
 func (s *Server) handleBacktestTrace(c *gin.Context) {
 	if s.backtestManager == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
@@ -613,6 +1024,34 @@ func (s *Server) ensureBacktestRunOwnership(runID, userID string) (*backtest.Run
 	return meta, nil
 }
 
+func (s *Server) ensureBacktestSweepOwnership(sweepID, userID string) (*backtest.SweepStatus, error) {
+	if s.backtestManager == nil {
+		return nil, fmt.Errorf("backtest manager unavailable")
+	}
+	status, err := s.backtestManager.GetSweep(sweepID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" || userID == "admin" || status.UserID == "" || status.UserID == userID {
+		return status, nil
+	}
+	return nil, errBacktestForbidden
+}
+
+func (s *Server) ensureBacktestWalkForwardOwnership(id, userID string) (*backtest.WalkForwardStatus, error) {
+	if s.backtestManager == nil {
+		return nil, fmt.Errorf("backtest manager unavailable")
+	}
+	status, err := s.backtestManager.GetWalkForward(id)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" || userID == "admin" || status.UserID == "" || status.UserID == userID {
+		return status, nil
+	}
+	return nil, errBacktestForbidden
+}
+
 func writeBacktestAccessError(c *gin.Context, err error) bool {
 	if err == nil {
 		return false
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleListDCADeals lists a DCA trader's deals with their realized PnL
+// Query: limit (default 50)
+func (s *Server) handleListDCADeals(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		SafeNotFound(c, "Trader")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	deals, err := s.store.DCA().ListDeals(traderID, limit)
+	if err != nil {
+		SafeInternalError(c, "List DCA deals", err)
+		return
+	}
+
+	// Totals cover the listed closed deals; the active deal has no realized PnL yet
+	var totalPnL float64
+	closed := 0
+	for _, deal := range deals {
+		if deal.ClosedAt > 0 {
+			totalPnL += deal.RealizedPnL
+			closed++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"deals":              deals,
+		"closed_deals":       closed,
+		"total_realized_pnl": totalPnL,
+	})
+}
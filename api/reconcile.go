@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"nofx/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleListDrifts lists position drifts detected by the reconciler
+// Query: status (OPEN/HEALED/RESOLVED, default all), limit (default 100)
+func (s *Server) handleListDrifts(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		SafeNotFound(c, "Trader")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	drifts, err := s.store.Drift().List(traderID, c.Query("status"), limit)
+	if err != nil {
+		SafeInternalError(c, "List drifts", err)
+		return
+	}
+
+	resp := gin.H{"drifts": drifts}
+	if autoTrader, err := s.traderManager.GetTrader(traderID); err == nil {
+		if reconciler := autoTrader.GetReconciler(); reconciler != nil {
+			resp["last_report"] = reconciler.LastReport()
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleReconcilePositions runs a reconcile pass immediately
+func (s *Server) handleReconcilePositions(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		SafeNotFound(c, "Trader")
+		return
+	}
+
+	autoTrader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		SafeNotFound(c, "Trader")
+		return
+	}
+	reconciler := autoTrader.GetReconciler()
+	if reconciler == nil {
+		SafeBadRequest(c, "Trader is not running")
+		return
+	}
+
+	report, err := reconciler.ReconcileOnce()
+	if err != nil {
+		SafeInternalError(c, "Reconcile positions", err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// handleResolveDrift marks a queued drift as resolved after manual review
+func (s *Server) handleResolveDrift(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		SafeNotFound(c, "Trader")
+		return
+	}
+
+	driftID, err := strconv.ParseInt(c.Param("driftId"), 10, 64)
+	if err != nil {
+		SafeBadRequest(c, "Invalid drift ID")
+		return
+	}
+
+	var req struct {
+		Resolution string `json:"resolution"`
+	}
+	_ = c.ShouldBindJSON(&req)
+	if req.Resolution == "" {
+		req.Resolution = "resolved manually"
+	}
+
+	drift, err := s.store.Drift().Get(driftID)
+	if err != nil {
+		SafeInternalError(c, "Get drift", err)
+		return
+	}
+	if drift == nil || drift.TraderID != traderID {
+		SafeNotFound(c, "Drift")
+		return
+	}
+
+	if err := s.store.Drift().Resolve(driftID, store.DriftStatusResolved, req.Resolution); err != nil {
+		SafeInternalError(c, "Resolve drift", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Drift resolved"})
+}
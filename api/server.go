@@ -79,6 +79,9 @@ func NewServer(traderManager *manager.TraderManager, st *store.Store, cryptoServ
 		debateHandler:   debateHandler,
 		port:            port,
 	}
+	if backtestManager != nil {
+		backtestManager.SetLiveDecisionLoader(s.loadLiveDecisions)
+	}
 
 	// Setup routes
 	s.setupRoutes()
@@ -167,6 +170,10 @@ func (s *Server) setupRoutes() {
 			protected.POST("/traders/:id/close-position", s.handleClosePosition)
 			protected.PUT("/traders/:id/competition", s.handleToggleCompetition)
 			protected.GET("/traders/:id/grid-risk", s.handleGetGridRiskInfo)
+			protected.GET("/traders/:id/dca-deals", s.handleListDCADeals)
+			protected.GET("/traders/:id/drifts", s.handleListDrifts)
+			protected.POST("/traders/:id/reconcile", s.handleReconcilePositions)
+			protected.POST("/traders/:id/drifts/:driftId/resolve", s.handleResolveDrift)
 
 			// AI model configuration
 			protected.GET("/models", s.handleGetModelConfigs)
@@ -209,10 +216,12 @@ func (s *Server) setupRoutes() {
 			protected.GET("/account", s.handleAccount)
 			protected.GET("/positions", s.handlePositions)
 			protected.GET("/positions/history", s.handlePositionHistory)
+			protected.GET("/positions/:id/exits", s.handlePositionExits) // Exit legs (take-profit rungs, scale-outs)
 			protected.GET("/trades", s.handleTrades)
-			protected.GET("/orders", s.handleOrders)               // Order list (all orders)
-			protected.GET("/orders/:id/fills", s.handleOrderFills) // Order fill details
-			protected.GET("/open-orders", s.handleOpenOrders)      // Open orders from exchange (pending SL/TP)
+			protected.GET("/orders", s.handleOrders)                     // Order list (all orders)
+			protected.GET("/orders/:id/fills", s.handleOrderFills)       // Order fill details
+			protected.GET("/orders/:id/children", s.handleOrderChildren) // Algo order slices (TWAP/iceberg/POV)
+			protected.GET("/open-orders", s.handleOpenOrders)            // Open orders from exchange (pending SL/TP)
 			protected.GET("/decisions", s.handleDecisions)
 			protected.GET("/decisions/latest", s.handleLatestDecisions)
 			protected.GET("/statistics", s.handleStatistics)
@@ -2296,6 +2305,54 @@ func (s *Server) handlePositionHistory(c *gin.Context) {
 	})
 }
 
+// handlePositionExits Exit legs of a position with their reasons (e.g. take_profit_2 for a ladder rung)
+func (s *Server) handlePositionExits(c *gin.Context) {
+	positionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid position ID"})
+		return
+	}
+
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		SafeBadRequest(c, "Invalid trader ID")
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		SafeNotFound(c, "Trader")
+		return
+	}
+
+	store := trader.GetStore()
+	if store == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Store not available"})
+		return
+	}
+
+	position, err := store.Position().GetPositionByID(positionID)
+	if err != nil {
+		SafeInternalError(c, "Get position", err)
+		return
+	}
+	if position == nil || position.TraderID != trader.GetID() {
+		SafeNotFound(c, "Position")
+		return
+	}
+
+	exits, err := store.Position().GetExits(positionID)
+	if err != nil {
+		SafeInternalError(c, "Get position exits", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"position": position,
+		"exits":    exits,
+	})
+}
+
 // handleTrades Historical trades list
 func (s *Server) handleTrades(c *gin.Context) {
 	_, traderID, err := s.getTraderFromQuery(c)
@@ -2433,6 +2490,59 @@ func (s *Server) handleOrderFills(c *gin.Context) {
 	c.JSON(http.StatusOK, fills)
 }
 
+// handleOrderChildren Algo order progress: the parent order and the child orders it has placed so far
+func (s *Server) handleOrderChildren(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		SafeBadRequest(c, "Invalid trader ID")
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		SafeNotFound(c, "Trader")
+		return
+	}
+
+	store := trader.GetStore()
+	if store == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Store not available"})
+		return
+	}
+
+	parent, err := store.Order().GetOrderByID(orderID)
+	if err != nil {
+		SafeInternalError(c, "Get order", err)
+		return
+	}
+	if parent == nil || parent.TraderID != trader.GetID() {
+		SafeNotFound(c, "Order")
+		return
+	}
+
+	children, err := store.Order().GetChildOrders(orderID)
+	if err != nil {
+		SafeInternalError(c, "Get child orders", err)
+		return
+	}
+
+	progress := 0.0
+	if parent.Quantity > 0 {
+		progress = parent.FilledQuantity / parent.Quantity * 100
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"parent":       parent,
+		"children":     children,
+		"progress_pct": progress,
+	})
+}
+
 // handleOpenOrders Get open orders (pending SL/TP) from exchange
 func (s *Server) handleOpenOrders(c *gin.Context) {
 	_, traderID, err := s.getTraderFromQuery(c)
@@ -25,9 +25,11 @@ type BacktestAccount struct {
 	initialBalance float64
 	cash           float64
 	feeRate        float64
+	makerFeeRate   float64
 	slippageRate   float64
 	positions      map[string]*position
 	realizedPnL    float64
+	margin         *marginModel // Tiered maintenance margin; nil keeps the simple entry ± entry/leverage formula
 }
 
 func NewBacktestAccount(initialBalance, feeBps, slippageBps float64) *BacktestAccount {
@@ -35,6 +37,7 @@ func NewBacktestAccount(initialBalance, feeBps, slippageBps float64) *BacktestAc
 		initialBalance: initialBalance,
 		cash:           initialBalance,
 		feeRate:        feeBps / 10000.0,
+		makerFeeRate:   feeBps / 10000.0,
 		slippageRate:   slippageBps / 10000.0,
 		positions:      make(map[string]*position),
 	}
@@ -59,7 +62,26 @@ func (acc *BacktestAccount) removePosition(pos *position) {
 	delete(acc.positions, key)
 }
 
+// SetMakerFeeBps sets the fee charged on resting limit-order fills (defaults to the taker fee).
+func (acc *BacktestAccount) SetMakerFeeBps(bps float64) {
+	acc.makerFeeRate = bps / 10000.0
+}
+
 func (acc *BacktestAccount) Open(symbol, side string, quantity float64, leverage int, price float64, ts int64) (*position, float64, float64, error) {
+	return acc.open(symbol, side, quantity, leverage, price, ts, acc.feeRate, acc.slippageRate)
+}
+
+// OpenMaker opens at exactly price with the maker fee, as a resting limit order would fill.
+func (acc *BacktestAccount) OpenMaker(symbol, side string, quantity float64, leverage int, price float64, ts int64) (*position, float64, float64, error) {
+	return acc.open(symbol, side, quantity, leverage, price, ts, acc.makerFeeRate, 0)
+}
+
+// OpenWithSlippage opens with the taker fee and the given slippage instead of the flat account rate.
+func (acc *BacktestAccount) OpenWithSlippage(symbol, side string, quantity float64, leverage int, price float64, ts int64, slippageBps float64) (*position, float64, float64, error) {
+	return acc.open(symbol, side, quantity, leverage, price, ts, acc.feeRate, slippageBps/10000.0)
+}
+
+func (acc *BacktestAccount) open(symbol, side string, quantity float64, leverage int, price float64, ts int64, feeRate, slippageRate float64) (*position, float64, float64, error) {
 	if quantity <= 0 {
 		return nil, 0, 0, fmt.Errorf("quantity must be positive")
 	}
@@ -67,10 +89,15 @@ func (acc *BacktestAccount) Open(symbol, side string, quantity float64, leverage
 		return nil, 0, 0, fmt.Errorf("leverage must be positive")
 	}
 
-	execPrice := applySlippage(price, acc.slippageRate, side, true)
+	execPrice := applySlippage(price, slippageRate, side, true)
 	notional := execPrice * quantity
+	existing := 0.0
+	if pos, ok := acc.positions[positionKey(symbol, side)]; ok {
+		existing = pos.Notional
+	}
+	leverage = acc.capLeverage(symbol, leverage, existing+notional)
 	margin := notional / float64(leverage)
-	fee := notional * acc.feeRate
+	fee := notional * feeRate
 
 	if margin+fee > acc.cash+epsilon {
 		return nil, 0, 0, fmt.Errorf("insufficient cash: need %.2f", margin+fee)
@@ -87,7 +114,7 @@ func (acc *BacktestAccount) Open(symbol, side string, quantity float64, leverage
 		pos.Margin = margin
 		pos.Notional = notional
 		pos.OpenTime = ts
-		pos.LiquidationPrice = computeLiquidation(execPrice, leverage, side)
+		pos.LiquidationPrice = acc.liquidationPrice(pos)
 		pos.AccumulatedFee = fee // Track opening fee
 	} else {
 		if leverage != pos.Leverage {
@@ -99,7 +126,7 @@ func (acc *BacktestAccount) Open(symbol, side string, quantity float64, leverage
 		pos.Margin += margin
 		pos.EntryPrice = ((pos.EntryPrice * pos.Quantity) + execPrice*quantity) / (pos.Quantity + quantity)
 		pos.Quantity += quantity
-		pos.LiquidationPrice = computeLiquidation(pos.EntryPrice, pos.Leverage, side)
+		pos.LiquidationPrice = acc.liquidationPrice(pos)
 		pos.AccumulatedFee += fee // Add to accumulated fee for position additions
 	}
 
@@ -107,6 +134,20 @@ func (acc *BacktestAccount) Open(symbol, side string, quantity float64, leverage
 }
 
 func (acc *BacktestAccount) Close(symbol, side string, quantity float64, price float64) (float64, float64, float64, error) {
+	return acc.close(symbol, side, quantity, price, acc.feeRate, acc.slippageRate)
+}
+
+// CloseMaker closes at exactly price with the maker fee, as a resting limit order would fill.
+func (acc *BacktestAccount) CloseMaker(symbol, side string, quantity float64, price float64) (float64, float64, float64, error) {
+	return acc.close(symbol, side, quantity, price, acc.makerFeeRate, 0)
+}
+
+// CloseWithSlippage closes with the taker fee and the given slippage instead of the flat account rate.
+func (acc *BacktestAccount) CloseWithSlippage(symbol, side string, quantity float64, price float64, slippageBps float64) (float64, float64, float64, error) {
+	return acc.close(symbol, side, quantity, price, acc.feeRate, slippageBps/10000.0)
+}
+
+func (acc *BacktestAccount) close(symbol, side string, quantity float64, price float64, feeRate, slippageRate float64) (float64, float64, float64, error) {
 	key := positionKey(symbol, side)
 	pos, ok := acc.positions[key]
 	if !ok || pos.Quantity <= epsilon {
@@ -121,9 +162,9 @@ func (acc *BacktestAccount) Close(symbol, side string, quantity float64, price f
 		}
 	}
 
-	execPrice := applySlippage(price, acc.slippageRate, side, false)
+	execPrice := applySlippage(price, slippageRate, side, false)
 	closeNotional := execPrice * quantity // Notional at close price (for fee calculation)
-	closingFee := closeNotional * acc.feeRate
+	closingFee := closeNotional * feeRate
 
 	// Calculate proportional values based on the portion being closed
 	closePortion := quantity / pos.Quantity
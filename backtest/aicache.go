@@ -27,6 +27,35 @@ type AICache struct {
 	Entries map[string]cachedDecision `json:"entries"`
 }
 
+var (
+	sharedCachesMu sync.Mutex
+	sharedCaches   = make(map[string]*AICache)
+)
+
+// loadSharedAICache returns one in-process cache per path, so concurrent runs sharing a cache file
+// (e.g. sweep children) see each other's decisions instead of overwriting the file with stale copies.
+func loadSharedAICache(path string) (*AICache, error) {
+	key := filepath.Clean(path)
+	sharedCachesMu.Lock()
+	defer sharedCachesMu.Unlock()
+	if cache, ok := sharedCaches[key]; ok {
+		return cache, nil
+	}
+	cache, err := LoadAICache(path)
+	if err != nil {
+		return nil, err
+	}
+	sharedCaches[key] = cache
+	return cache, nil
+}
+
+// releaseSharedAICache drops the in-process copy of a shared cache once no run needs it.
+func releaseSharedAICache(path string) {
+	sharedCachesMu.Lock()
+	delete(sharedCaches, filepath.Clean(path))
+	sharedCachesMu.Unlock()
+}
+
 func LoadAICache(path string) (*AICache, error) {
 	if path == "" {
 		return nil, fmt.Errorf("ai cache path is empty")
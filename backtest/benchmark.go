@@ -0,0 +1,333 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"nofx/market"
+)
+
+// Benchmarks a run can be compared against
+const (
+	BenchmarkBTCHold     = "btc_hold"     // Buy and hold BTC with the whole balance
+	BenchmarkEqualWeight = "equal_weight" // Buy and hold the run's symbols in equal parts
+	BenchmarkEMACross    = "ema_cross"    // Long-only EMA crossover on each symbol, equal parts
+)
+
+const (
+	benchmarkBTCSymbol = "BTCUSDT"
+	emaCrossFast       = 20
+	emaCrossSlow       = 50
+)
+
+var knownBenchmarks = map[string]bool{
+	BenchmarkBTCHold:     true,
+	BenchmarkEqualWeight: true,
+	BenchmarkEMACross:    true,
+}
+
+// BenchmarkMetrics compares the run with one benchmark over the same bars.
+type BenchmarkMetrics struct {
+	ReturnPct       float64 `json:"return_pct"`        // Benchmark total return
+	ExcessReturnPct float64 `json:"excess_return_pct"` // Run return minus benchmark return
+	MaxDrawdownPct  float64 `json:"max_drawdown_pct"`
+	Alpha           float64 `json:"alpha"` // Annualized from the decision timeframe, per bar if it is unknown
+	Beta            float64 `json:"beta"`
+	Correlation     float64 `json:"correlation"`
+}
+
+// normalizeBenchmarks lowercases, dedupes and checks the requested benchmark names.
+func normalizeBenchmarks(names []string) ([]string, error) {
+	var out []string
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if !knownBenchmarks[name] {
+			return nil, fmt.Errorf("unsupported benchmark '%s'", name)
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out, nil
+}
+
+// benchmarkTracker values each benchmark at every decision bar from the run's own data feed.
+// Its state depends only on the feed, so after a resume it simply replays the bars it missed.
+type benchmarkTracker struct {
+	names       []string
+	feed        *DataFeed
+	symbols     []string
+	balance     float64
+	costRate    float64 // Fee plus slippage paid on each EMA crossover switch
+	lastIndex   int     // Index into feed.decisionTimes of the last bar applied, -1 before the first
+	baseCloses  map[string]float64
+	prevCloses  map[string]float64
+	emaEquity   map[string]float64 // Per-symbol sleeve value of the EMA crossover baseline
+	emaHolding  map[string]bool
+	lastResults map[string]float64
+}
+
+func newBenchmarkTracker(cfg BacktestConfig, feed *DataFeed) *benchmarkTracker {
+	if len(cfg.Benchmarks) == 0 {
+		return nil
+	}
+	return &benchmarkTracker{
+		names:      cfg.Benchmarks,
+		feed:       feed,
+		symbols:    append([]string(nil), cfg.Symbols...),
+		balance:    cfg.InitialBalance,
+		costRate:   (cfg.FeeBps + cfg.SlippageBps) / 10000,
+		lastIndex:  -1,
+		baseCloses: make(map[string]float64),
+		prevCloses: make(map[string]float64),
+		emaEquity:  make(map[string]float64),
+		emaHolding: make(map[string]bool),
+	}
+}
+
+// valueAt returns each benchmark's equity at the decision bar ts.
+func (b *benchmarkTracker) valueAt(ts int64) map[string]float64 {
+	if b == nil {
+		return nil
+	}
+	times := b.feed.decisionTimes
+	target := sort.Search(len(times), func(i int) bool { return times[i] >= ts })
+	if target >= len(times) || times[target] != ts {
+		return b.lastResults
+	}
+	if target < b.lastIndex {
+		b.reset()
+	}
+	for i := b.lastIndex + 1; i <= target; i++ {
+		b.apply(times[i])
+		b.lastIndex = i
+	}
+	return b.lastResults
+}
+
+func (b *benchmarkTracker) reset() {
+	b.lastIndex = -1
+	b.baseCloses = make(map[string]float64)
+	b.prevCloses = make(map[string]float64)
+	b.emaEquity = make(map[string]float64)
+	b.emaHolding = make(map[string]bool)
+	b.lastResults = nil
+}
+
+// apply advances every benchmark to the bar closing at ts.
+func (b *benchmarkTracker) apply(ts int64) {
+	results := make(map[string]float64, len(b.names))
+	for _, name := range b.names {
+		switch name {
+		case BenchmarkBTCHold:
+			if v, ok := b.holdValue([]string{benchmarkBTCSymbol}, ts); ok {
+				results[name] = v
+			}
+		case BenchmarkEqualWeight:
+			if v, ok := b.holdValue(b.symbols, ts); ok {
+				results[name] = v
+			}
+		case BenchmarkEMACross:
+			results[name] = b.emaCrossValue(ts)
+		}
+	}
+	for _, symbol := range b.trackedSymbols() {
+		if price, ok := b.feed.closeAt(symbol, ts); ok {
+			b.prevCloses[symbol] = price
+		}
+	}
+	b.lastResults = results
+}
+
+// holdValue is the balance split equally across symbols, bought at the first bar and held.
+func (b *benchmarkTracker) holdValue(symbols []string, ts int64) (float64, bool) {
+	if len(symbols) == 0 {
+		return 0, false
+	}
+	sleeve := b.balance / float64(len(symbols))
+	total := 0.0
+	for _, symbol := range symbols {
+		price, ok := b.feed.closeAt(symbol, ts)
+		if !ok || price <= 0 {
+			return 0, false
+		}
+		base, ok := b.baseCloses[symbol]
+		if !ok {
+			base = price
+			b.baseCloses[symbol] = base
+		}
+		total += sleeve * (1 - b.costRate) * price / base
+	}
+	return total, true
+}
+
+// emaCrossValue holds each symbol's sleeve long while the fast EMA is above the slow EMA, flat otherwise.
+func (b *benchmarkTracker) emaCrossValue(ts int64) float64 {
+	total := 0.0
+	for _, symbol := range b.symbols {
+		equity, ok := b.emaEquity[symbol]
+		if !ok {
+			equity = b.balance / float64(len(b.symbols))
+		}
+		price, hasPrice := b.feed.closeAt(symbol, ts)
+		if prev := b.prevCloses[symbol]; b.emaHolding[symbol] && hasPrice && prev > 0 {
+			equity *= price / prev
+		}
+
+		klines := b.feed.sliceUpTo(symbol, b.feed.primaryTF, ts)
+		closes := make([]float64, len(klines))
+		for i, k := range klines {
+			closes[i] = k.Close
+		}
+		fast, okFast := lastEMA(closes, emaCrossFast)
+		slow, okSlow := lastEMA(closes, emaCrossSlow)
+		if want := okFast && okSlow && fast > slow; want != b.emaHolding[symbol] {
+			equity *= 1 - b.costRate
+			b.emaHolding[symbol] = want
+		}
+		b.emaEquity[symbol] = equity
+		total += equity
+	}
+	return total
+}
+
+func (b *benchmarkTracker) trackedSymbols() []string {
+	symbols := b.symbols
+	for _, name := range b.names {
+		if name == BenchmarkBTCHold && !containsSymbol(symbols, benchmarkBTCSymbol) {
+			symbols = append(append([]string(nil), symbols...), benchmarkBTCSymbol)
+		}
+	}
+	return symbols
+}
+
+// lastEMA returns the EMA of the closes at the last bar, seeded with the SMA of a window ending four periods back.
+func lastEMA(closes []float64, period int) (float64, bool) {
+	if period <= 0 || len(closes) < period {
+		return 0, false
+	}
+	start := len(closes) - period*4
+	if start < 0 {
+		start = 0
+	}
+	window := closes[start:]
+	ema := 0.0
+	for _, c := range window[:period] {
+		ema += c
+	}
+	ema /= float64(period)
+	k := 2.0 / float64(period+1)
+	for _, c := range window[period:] {
+		ema = c*k + ema*(1-k)
+	}
+	return ema, true
+}
+
+func containsSymbol(symbols []string, symbol string) bool {
+	for _, s := range symbols {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// barsPerYear is how many bars of the timeframe a year of round-the-clock trading holds, 0 if it is unknown.
+func barsPerYear(timeframe string) float64 {
+	d, err := market.TFDuration(timeframe)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return float64(365*24*time.Hour) / float64(d)
+}
+
+// benchmarkMetrics compares the run's equity curve with every benchmark recorded on its equity points.
+// Equity points are one per decision bar, so periodsPerYear is the decision timeframe's bars per year.
+func benchmarkMetrics(points []EquityPoint, initialBalance, runReturnPct, periodsPerYear float64) map[string]BenchmarkMetrics {
+	if len(points) < 2 || initialBalance <= 0 {
+		return nil
+	}
+	names := make(map[string]bool)
+	for _, pt := range points {
+		for name := range pt.Benchmarks {
+			names[name] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	result := make(map[string]BenchmarkMetrics, len(names))
+	for name := range names {
+		var runReturns, benchReturns []float64
+		var prevRun, prevBench float64
+		var lastBench float64
+		peak, maxDD := 0.0, 0.0
+		for _, pt := range points {
+			bench, ok := pt.Benchmarks[name]
+			if !ok || bench <= 0 {
+				continue
+			}
+			if prevRun > 0 && prevBench > 0 {
+				runReturns = append(runReturns, (pt.Equity-prevRun)/prevRun)
+				benchReturns = append(benchReturns, (bench-prevBench)/prevBench)
+			}
+			prevRun, prevBench, lastBench = pt.Equity, bench, bench
+			peak = math.Max(peak, bench)
+			maxDD = math.Max(maxDD, (peak-bench)/peak*100)
+		}
+		if lastBench <= 0 {
+			continue
+		}
+
+		m := BenchmarkMetrics{
+			ReturnPct:      (lastBench - initialBalance) / initialBalance * 100,
+			MaxDrawdownPct: maxDD,
+		}
+		m.ExcessReturnPct = runReturnPct - m.ReturnPct
+		m.Alpha, m.Beta, m.Correlation = regressReturns(runReturns, benchReturns, periodsPerYear)
+		result[name] = m
+	}
+	return result
+}
+
+// regressReturns fits run = alpha + beta*benchmark over per-bar returns.
+// Alpha is the per-bar intercept times periodsPerYear, or left per bar when periodsPerYear is 0.
+func regressReturns(run, bench []float64, periodsPerYear float64) (alpha, beta, correlation float64) {
+	n := len(run)
+	if n < 2 || n != len(bench) {
+		return 0, 0, 0
+	}
+	var meanRun, meanBench float64
+	for i := 0; i < n; i++ {
+		meanRun += run[i]
+		meanBench += bench[i]
+	}
+	meanRun /= float64(n)
+	meanBench /= float64(n)
+
+	var cov, varRun, varBench float64
+	for i := 0; i < n; i++ {
+		dr, db := run[i]-meanRun, bench[i]-meanBench
+		cov += dr * db
+		varRun += dr * dr
+		varBench += db * db
+	}
+	if varBench > 1e-18 {
+		beta = cov / varBench
+	}
+	if varRun > 1e-18 && varBench > 1e-18 {
+		correlation = cov / math.Sqrt(varRun*varBench)
+	}
+	alpha = meanRun - beta*meanBench
+	if periodsPerYear > 0 {
+		alpha *= periodsPerYear
+	}
+	return alpha, beta, correlation
+}
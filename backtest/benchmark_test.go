@@ -0,0 +1,184 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+
+	"nofx/market"
+)
+
+func TestRegressReturns(t *testing.T) {
+	bench := []float64{0.01, -0.02, 0.03, 0.005, -0.01}
+	scaled := func(beta, alpha float64) []float64 {
+		out := make([]float64, len(bench))
+		for i, b := range bench {
+			out[i] = alpha + beta*b
+		}
+		return out
+	}
+
+	tests := []struct {
+		name           string
+		run            []float64
+		bench          []float64
+		periodsPerYear float64
+		alpha          float64
+		beta           float64
+		correlation    float64
+	}{
+		{"per-bar alpha without a timeframe", scaled(2, 0.001), bench, 0, 0.001, 2, 1},
+		{"alpha annualized over hourly bars", scaled(2, 0.001), bench, barsPerYear("1h"), 8.76, 2, 1},
+		{"inverse benchmark", scaled(-1, 0), bench, barsPerYear("1h"), 0, -1, -1},
+		{"flat run", scaled(0, 0.002), bench, barsPerYear("4h"), 4.38, 0, 0},
+		{"flat benchmark", bench, []float64{0.01, 0.01, 0.01, 0.01, 0.01}, 0, 0.003, 0, 0},
+		{"mismatched lengths", bench[:3], bench, 0, 0, 0, 0},
+		{"single return", bench[:1], bench[:1], 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alpha, beta, correlation := regressReturns(tt.run, tt.bench, tt.periodsPerYear)
+			if math.Abs(alpha-tt.alpha) > 1e-9 || math.Abs(beta-tt.beta) > 1e-9 || math.Abs(correlation-tt.correlation) > 1e-9 {
+				t.Errorf("Expected alpha %.6f beta %.4f correlation %.4f, got %.6f %.4f %.4f",
+					tt.alpha, tt.beta, tt.correlation, alpha, beta, correlation)
+			}
+		})
+	}
+}
+
+func TestBarsPerYear(t *testing.T) {
+	tests := []struct {
+		timeframe string
+		want      float64
+	}{
+		{"1h", 8760},
+		{"4h", 2190},
+		{"1d", 365},
+		{"", 0},
+		{"7x", 0},
+	}
+	for _, tt := range tests {
+		if got := barsPerYear(tt.timeframe); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("barsPerYear(%q) = %v, want %v", tt.timeframe, got, tt.want)
+		}
+	}
+}
+
+// newTestBenchmarkTracker tracks the benchmarks over the feed's bars with 5 bps fee and 5 bps slippage.
+func newTestBenchmarkTracker(names, symbols []string, series map[string][]market.Kline) *benchmarkTracker {
+	feed := testFeed("1m", series)
+	for _, k := range series[symbols[0]] {
+		feed.decisionTimes = append(feed.decisionTimes, k.CloseTime)
+	}
+	return newBenchmarkTracker(BacktestConfig{Benchmarks: names, Symbols: symbols, InitialBalance: 1000, FeeBps: 5, SlippageBps: 5}, feed)
+}
+
+// closeBars builds one-minute bars closing at each price.
+func closeBars(closes ...float64) []market.Kline {
+	rows := make([][4]float64, len(closes))
+	for i, c := range closes {
+		rows[i] = [4]float64{c, c, c, c}
+	}
+	return testBars(rows...)
+}
+
+func TestHoldValue(t *testing.T) {
+	series := map[string][]market.Kline{
+		"BTCUSDT": closeBars(100, 110, 90),
+		"ETHUSDT": closeBars(10, 10, 12),
+	}
+	tests := []struct {
+		name    string
+		symbols []string
+		want    []float64 // Value at each bar, 0 = no value
+	}{
+		// The entry cost is paid once, on the whole balance
+		{"single symbol", []string{"BTCUSDT"}, []float64{999, 1098.9, 899.1}},
+		{"equal parts", []string{"BTCUSDT", "ETHUSDT"}, []float64{999, 1048.95, 1048.95}},
+		{"symbol without bars", []string{"BTCUSDT", "SOLUSDT"}, []float64{0, 0, 0}},
+		{"no symbols", nil, []float64{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBenchmarkTracker([]string{BenchmarkEqualWeight}, []string{"BTCUSDT"}, series)
+			for i, k := range series["BTCUSDT"] {
+				got, ok := b.holdValue(tt.symbols, k.CloseTime)
+				if ok != (tt.want[i] != 0) || math.Abs(got-tt.want[i]) > 1e-9 {
+					t.Errorf("Bar %d: expected %.4f, got %.4f (ok %v)", i, tt.want[i], got, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestEMACrossValueSwitching(t *testing.T) {
+	closes := make([]float64, 0, emaCrossSlow+3)
+	for len(closes) < emaCrossSlow {
+		closes = append(closes, 100) // Flat: fast equals slow, so the sleeve stays out
+	}
+	closes = append(closes, 110, 121, 40)
+	series := map[string][]market.Kline{"BTCUSDT": closeBars(closes...)}
+	b := newTestBenchmarkTracker([]string{BenchmarkEMACross}, []string{"BTCUSDT"}, series)
+
+	const cost = 0.999
+	tests := []struct {
+		bar     int
+		want    float64
+		holding bool
+	}{
+		{emaCrossSlow - 1, 1000, false},                                // Both EMAs at 100
+		{emaCrossSlow, 1000 * cost, true},                              // Fast crosses above slow: buy, paying the switch cost
+		{emaCrossSlow + 1, 1000 * cost * 1.1, true},                    // Held through 110 -> 121
+		{emaCrossSlow + 2, 1000 * cost * 1.1 * 40 / 121 * cost, false}, // Held into the drop to 40, then sold
+	}
+	for _, tt := range tests {
+		values := b.valueAt(series["BTCUSDT"][tt.bar].CloseTime)
+		if got := values[BenchmarkEMACross]; math.Abs(got-tt.want) > 1e-9 || b.emaHolding["BTCUSDT"] != tt.holding {
+			t.Errorf("Bar %d: expected %.4f holding %v, got %.4f holding %v", tt.bar, tt.want, tt.holding, got, b.emaHolding["BTCUSDT"])
+		}
+	}
+}
+
+func TestBenchmarkValueAtResetsOnResume(t *testing.T) {
+	series := map[string][]market.Kline{
+		"BTCUSDT": closeBars(100, 110, 90, 120),
+		"ETHUSDT": closeBars(10, 11, 12, 9),
+	}
+	names := []string{BenchmarkBTCHold, BenchmarkEqualWeight, BenchmarkEMACross}
+	symbols := []string{"ETHUSDT"}
+	bars := series["BTCUSDT"]
+
+	fresh := newTestBenchmarkTracker(names, symbols, series)
+	want := make([]map[string]float64, len(bars))
+	for i, k := range bars {
+		want[i] = fresh.valueAt(k.CloseTime)
+	}
+
+	tests := []struct {
+		name string
+		bars []int // Bars valued in order; a resumed run may start again from an earlier checkpoint
+	}{
+		{"resume from an earlier bar", []int{3, 1, 2, 3}},
+		{"resume from the first bar", []int{2, 0, 1}},
+		{"skip ahead", []int{0, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBenchmarkTracker(names, symbols, series)
+			for _, i := range tt.bars {
+				got := b.valueAt(bars[i].CloseTime)
+				for _, name := range names {
+					if math.Abs(got[name]-want[i][name]) > 1e-9 {
+						t.Errorf("Bar %d %s: expected %.4f, got %.4f", i, name, want[i][name], got[name])
+					}
+				}
+			}
+		})
+	}
+
+	// A timestamp between bars keeps the last values
+	b := newTestBenchmarkTracker(names, symbols, series)
+	last := b.valueAt(bars[1].CloseTime)
+	if got := b.valueAt(bars[1].CloseTime + 1); got[BenchmarkBTCHold] != last[BenchmarkBTCHold] || b.lastIndex != 1 {
+		t.Errorf("Expected an off-bar timestamp to keep bar 1's values, got %v at index %d", got, b.lastIndex)
+	}
+}
@@ -0,0 +1,239 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	minCompareRuns = 2
+	maxCompareRuns = 10
+)
+
+// ComparedField is one config setting across the compared runs, in run order.
+type ComparedField struct {
+	Name    string        `json:"name"`
+	Values  []interface{} `json:"values"`
+	Differs bool          `json:"differs"`
+}
+
+// ComparedEquity is every run's equity on a shared timeline, normalised so each run starts at 100.
+// A nil value means the run has no point yet at that timestamp.
+type ComparedEquity struct {
+	Timestamps []int64               `json:"timestamps"`
+	Series     map[string][]*float64 `json:"series"`
+}
+
+// RunComparison lines up several runs for A/B analysis.
+type RunComparison struct {
+	RunIDs    []string                      `json:"run_ids"`
+	Config    []ComparedField               `json:"config"`
+	Metrics   map[string]*Metrics           `json:"metrics"`
+	Equity    ComparedEquity                `json:"equity"`
+	Symbols   []string                      `json:"symbols"`
+	SymbolPnL map[string]map[string]float64 `json:"symbol_pnl"` // symbol -> run ID -> realized PnL
+}
+
+// comparedConfigFields lists the settings that usually explain a difference between runs.
+func comparedConfigFields(cfg *BacktestConfig) []ComparedField {
+	symbols := append([]string(nil), cfg.Symbols...)
+	sort.Strings(symbols)
+	field := func(name string, value interface{}) ComparedField {
+		return ComparedField{Name: name, Values: []interface{}{value}}
+	}
+	fields := []ComparedField{
+		field("symbols", strings.Join(symbols, ",")),
+		field("strategy_id", cfg.StrategyID),
+		field("ai_model_id", cfg.AIModelID),
+		field("ai_provider", cfg.AICfg.Provider),
+		field("ai_model", cfg.AICfg.Model),
+		field("ai_temperature", cfg.AICfg.Temperature),
+		field("prompt_variant", cfg.PromptVariant),
+		field("prompt_template", cfg.PromptTemplate),
+		field("custom_prompt", cfg.CustomPrompt),
+		field("override_prompt", cfg.OverrideBasePrompt),
+		field("decision_timeframe", cfg.DecisionTimeframe),
+		field("timeframes", strings.Join(cfg.Timeframes, ",")),
+		field("decision_cadence_nbars", cfg.DecisionCadenceNBars),
+		field("start_ts", cfg.StartTS),
+		field("end_ts", cfg.EndTS),
+		field("initial_balance", cfg.InitialBalance),
+		field("asset_class", cfg.AssetClass),
+		field("fee_bps", cfg.FeeBps),
+		field("slippage_bps", cfg.SlippageBps),
+		field("fill_policy", cfg.FillPolicy),
+		field("btc_eth_leverage", cfg.Leverage.BTCETHLeverage),
+		field("altcoin_leverage", cfg.Leverage.AltcoinLeverage),
+	}
+	if cfg.FillModel != nil {
+		fields = append(fields,
+			field("fill_max_volume_pct", cfg.FillModel.MaxVolumePct),
+			field("fill_impact_factor", cfg.FillModel.ImpactFactor),
+			field("fill_latency_seconds", cfg.FillModel.LatencySeconds),
+		)
+	}
+	if cfg.Margin != nil {
+		fields = append(fields, field("margin_mode", cfg.Margin.Mode))
+	}
+	if cfg.Overrides != nil {
+		fields = append(fields,
+			field("btc_eth_position_ratio", cfg.Overrides.BTCETHPositionRatio),
+			field("altcoin_position_ratio", cfg.Overrides.AltcoinPositionRatio),
+		)
+		names := make([]string, 0, len(cfg.Overrides.Indicators))
+		for name := range cfg.Overrides.Indicators {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fields = append(fields, field(name, cfg.Overrides.Indicators[name]))
+		}
+	}
+	return fields
+}
+
+// mergeComparedFields merges each run's fields by name; settings a run lacks show as nil.
+func mergeComparedFields(perRun [][]ComparedField) []ComparedField {
+	var order []string
+	byName := make(map[string]*ComparedField)
+	for i, fields := range perRun {
+		for _, f := range fields {
+			merged, ok := byName[f.Name]
+			if !ok {
+				merged = &ComparedField{Name: f.Name, Values: make([]interface{}, len(perRun))}
+				byName[f.Name] = merged
+				order = append(order, f.Name)
+			}
+			merged.Values[i] = f.Values[0]
+		}
+	}
+
+	result := make([]ComparedField, 0, len(order))
+	for _, name := range order {
+		f := byName[name]
+		for _, v := range f.Values[1:] {
+			if !reflect.DeepEqual(v, f.Values[0]) {
+				f.Differs = true
+				break
+			}
+		}
+		result = append(result, *f)
+	}
+	return result
+}
+
+// alignEquity puts every run on the union of their timestamps, carrying each run's last value forward.
+func alignEquity(runIDs []string, curves map[string][]EquityPoint, balances map[string]float64, limit int) ComparedEquity {
+	seen := make(map[int64]bool)
+	var timestamps []int64
+	for _, points := range curves {
+		for _, pt := range points {
+			if !seen[pt.Timestamp] {
+				seen[pt.Timestamp] = true
+				timestamps = append(timestamps, pt.Timestamp)
+			}
+		}
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+	if limit > 0 && len(timestamps) > limit {
+		step := float64(len(timestamps)) / float64(limit)
+		sampled := make([]int64, 0, limit)
+		for i := 0; i < limit; i++ {
+			idx := int(math.Round(step * float64(i)))
+			if idx >= len(timestamps) {
+				idx = len(timestamps) - 1
+			}
+			sampled = append(sampled, timestamps[idx])
+		}
+		sampled[len(sampled)-1] = timestamps[len(timestamps)-1]
+		timestamps = sampled
+	}
+
+	aligned := ComparedEquity{Timestamps: timestamps, Series: make(map[string][]*float64, len(runIDs))}
+	for _, runID := range runIDs {
+		points := curves[runID]
+		base := balances[runID]
+		if base <= 0 && len(points) > 0 {
+			base = points[0].Equity
+		}
+		series := make([]*float64, len(timestamps))
+		j := -1
+		for i, ts := range timestamps {
+			for j+1 < len(points) && points[j+1].Timestamp <= ts {
+				j++
+			}
+			if j >= 0 && base > 0 {
+				v := points[j].Equity / base * 100
+				series[i] = &v
+			}
+		}
+		aligned.Series[runID] = series
+	}
+	return aligned
+}
+
+// CompareRuns loads several runs and returns their config differences, metrics, aligned equity and per-symbol PnL.
+// timeframe optionally resamples each equity curve first; limit caps the number of aligned points.
+func (m *Manager) CompareRuns(runIDs []string, timeframe string, limit int) (*RunComparison, error) {
+	ids := make([]string, 0, len(runIDs))
+	seen := make(map[string]bool, len(runIDs))
+	for _, id := range runIDs {
+		id = strings.TrimSpace(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) < minCompareRuns || len(ids) > maxCompareRuns {
+		return nil, fmt.Errorf("compare needs %d to %d run ids, got %d", minCompareRuns, maxCompareRuns, len(ids))
+	}
+
+	comparison := &RunComparison{
+		RunIDs:    ids,
+		Metrics:   make(map[string]*Metrics, len(ids)),
+		SymbolPnL: make(map[string]map[string]float64),
+	}
+	perRunFields := make([][]ComparedField, len(ids))
+	curves := make(map[string][]EquityPoint, len(ids))
+	balances := make(map[string]float64, len(ids))
+	symbols := make(map[string]bool)
+
+	for i, runID := range ids {
+		cfg, err := LoadConfig(runID)
+		if err != nil {
+			return nil, fmt.Errorf("load config for %s: %w", runID, err)
+		}
+		perRunFields[i] = comparedConfigFields(cfg)
+		balances[runID] = cfg.InitialBalance
+
+		points, err := m.LoadEquity(runID, timeframe, 0)
+		if err != nil {
+			return nil, fmt.Errorf("load equity for %s: %w", runID, err)
+		}
+		curves[runID] = points
+
+		metrics, err := m.GetMetrics(runID)
+		if err != nil {
+			continue // Still running or never produced metrics; config and equity are still comparable
+		}
+		comparison.Metrics[runID] = metrics
+		for symbol, stats := range metrics.SymbolStats {
+			if comparison.SymbolPnL[symbol] == nil {
+				comparison.SymbolPnL[symbol] = make(map[string]float64, len(ids))
+			}
+			comparison.SymbolPnL[symbol][runID] = stats.TotalPnL
+			symbols[symbol] = true
+		}
+	}
+
+	comparison.Config = mergeComparedFields(perRunFields)
+	comparison.Equity = alignEquity(ids, curves, balances, limit)
+	for symbol := range symbols {
+		comparison.Symbols = append(comparison.Symbols, symbol)
+	}
+	sort.Strings(comparison.Symbols)
+	return comparison, nil
+}
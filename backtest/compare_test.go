@@ -0,0 +1,207 @@
+package backtest
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMergeComparedFields(t *testing.T) {
+	base := func() *BacktestConfig {
+		return &BacktestConfig{
+			Symbols:        []string{"ETHUSDT", "BTCUSDT"},
+			InitialBalance: 1000,
+			FeeBps:         5,
+			Leverage:       LeverageConfig{BTCETHLeverage: 5, AltcoinLeverage: 3},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(cfg *BacktestConfig)
+		differs map[string]bool
+		missing map[string]bool // Fields only the second run has, so the first run shows nil
+	}{
+		{
+			name:    "identical configs",
+			mutate:  func(cfg *BacktestConfig) {},
+			differs: map[string]bool{},
+		},
+		{
+			name:    "symbol order does not matter",
+			mutate:  func(cfg *BacktestConfig) { cfg.Symbols = []string{"BTCUSDT", "ETHUSDT"} },
+			differs: map[string]bool{},
+		},
+		{
+			name: "changed scalars",
+			mutate: func(cfg *BacktestConfig) {
+				cfg.FeeBps = 10
+				cfg.Leverage.BTCETHLeverage = 10
+			},
+			differs: map[string]bool{"fee_bps": true, "btc_eth_leverage": true},
+		},
+		{
+			name: "settings only one run has",
+			mutate: func(cfg *BacktestConfig) {
+				cfg.Margin = &MarginConfig{Mode: "cross"}
+				cfg.Overrides = &StrategyOverrides{Indicators: map[string]bool{"enable_rsi": true}}
+			},
+			differs: map[string]bool{"margin_mode": true, "btc_eth_position_ratio": true, "altcoin_position_ratio": true, "enable_rsi": true},
+			missing: map[string]bool{"margin_mode": true, "btc_eth_position_ratio": true, "altcoin_position_ratio": true, "enable_rsi": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base()
+			tt.mutate(other)
+			fields := mergeComparedFields([][]ComparedField{comparedConfigFields(base()), comparedConfigFields(other)})
+
+			seen := make(map[string]bool)
+			for _, f := range fields {
+				seen[f.Name] = true
+				if len(f.Values) != 2 {
+					t.Fatalf("%s: expected one value per run, got %v", f.Name, f.Values)
+				}
+				if f.Differs != tt.differs[f.Name] {
+					t.Errorf("%s: expected differs=%v, got %v (values %v)", f.Name, tt.differs[f.Name], f.Differs, f.Values)
+				}
+				if tt.missing[f.Name] && f.Values[0] != nil {
+					t.Errorf("%s: expected nil for the run without the setting, got %v", f.Name, f.Values[0])
+				}
+			}
+			for name := range tt.differs {
+				if !seen[name] {
+					t.Errorf("Expected field %s in the comparison", name)
+				}
+			}
+		})
+	}
+}
+
+func TestAlignEquity(t *testing.T) {
+	curves := map[string][]EquityPoint{
+		"a": {{Timestamp: 100, Equity: 1000}, {Timestamp: 200, Equity: 1100}, {Timestamp: 400, Equity: 1200}},
+		"b": {{Timestamp: 200, Equity: 500}, {Timestamp: 300, Equity: 450}},
+	}
+	balances := map[string]float64{"a": 1000, "b": 0} // b falls back to its first point
+
+	tests := []struct {
+		name       string
+		limit      int
+		timestamps []int64
+		series     map[string][]float64 // NaN = no value yet
+	}{
+		{
+			name:       "union of timestamps, carried forward",
+			timestamps: []int64{100, 200, 300, 400},
+			series: map[string][]float64{
+				"a": {100, 110, 110, 120},
+				"b": {math.NaN(), 100, 90, 90},
+			},
+		},
+		{
+			name:       "limit keeps the last timestamp",
+			limit:      2,
+			timestamps: []int64{100, 400},
+			series: map[string][]float64{
+				"a": {100, 120},
+				"b": {math.NaN(), 90},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aligned := alignEquity([]string{"a", "b"}, curves, balances, tt.limit)
+			if len(aligned.Timestamps) != len(tt.timestamps) {
+				t.Fatalf("Expected timestamps %v, got %v", tt.timestamps, aligned.Timestamps)
+			}
+			for i, ts := range tt.timestamps {
+				if aligned.Timestamps[i] != ts {
+					t.Fatalf("Expected timestamps %v, got %v", tt.timestamps, aligned.Timestamps)
+				}
+			}
+			for runID, want := range tt.series {
+				got := aligned.Series[runID]
+				for i, w := range want {
+					switch {
+					case math.IsNaN(w) && got[i] != nil:
+						t.Errorf("%s[%d]: expected no value before the run's first point, got %.2f", runID, i, *got[i])
+					case !math.IsNaN(w) && (got[i] == nil || math.Abs(*got[i]-w) > 1e-9):
+						t.Errorf("%s[%d]: expected %.2f, got %v", runID, i, w, got[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCompareRunsSymbolPnL(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	runs := []struct {
+		id      string
+		symbols map[string]float64 // nil = no metrics yet
+	}{
+		{id: "a", symbols: map[string]float64{"BTCUSDT": 50, "ETHUSDT": -20}},
+		{id: "b", symbols: map[string]float64{"BTCUSDT": 10, "SOLUSDT": 5}},
+		{id: "c"},
+	}
+	for _, run := range runs {
+		if err := SaveConfig(run.id, &BacktestConfig{InitialBalance: 1000}); err != nil {
+			t.Fatalf("SaveConfig: %v", err)
+		}
+		if err := appendEquityPoint(run.id, EquityPoint{Timestamp: 1, Equity: 1000}); err != nil {
+			t.Fatalf("appendEquityPoint: %v", err)
+		}
+		if run.symbols == nil {
+			continue
+		}
+		metrics := &Metrics{SymbolStats: make(map[string]SymbolMetrics)}
+		for symbol, pnl := range run.symbols {
+			metrics.SymbolStats[symbol] = SymbolMetrics{TotalTrades: 1, TotalPnL: pnl}
+		}
+		if err := saveMetrics(run.id, metrics); err != nil {
+			t.Fatalf("saveMetrics: %v", err)
+		}
+	}
+
+	comparison, err := NewManager(nil).CompareRuns([]string{"a", " b", "c", "a"}, "", 0)
+	if err != nil {
+		t.Fatalf("CompareRuns: %v", err)
+	}
+	if len(comparison.RunIDs) != 3 {
+		t.Errorf("Expected duplicate and padded ids to collapse to 3 runs, got %v", comparison.RunIDs)
+	}
+	if want := "BTCUSDT,ETHUSDT,SOLUSDT"; strings.Join(comparison.Symbols, ",") != want {
+		t.Errorf("Expected the sorted union of symbols %v, got %v", want, comparison.Symbols)
+	}
+	if _, ok := comparison.Metrics["c"]; ok {
+		t.Errorf("A run without metrics must be left out of the metrics")
+	}
+
+	cells := []struct {
+		symbol, runID string
+		pnl           float64
+		present       bool
+	}{
+		{"BTCUSDT", "a", 50, true},
+		{"BTCUSDT", "b", 10, true},
+		{"ETHUSDT", "a", -20, true},
+		{"ETHUSDT", "b", 0, false},
+		{"SOLUSDT", "b", 5, true},
+		{"SOLUSDT", "a", 0, false},
+		{"BTCUSDT", "c", 0, false},
+	}
+	for _, c := range cells {
+		pnl, ok := comparison.SymbolPnL[c.symbol][c.runID]
+		if ok != c.present || pnl != c.pnl {
+			t.Errorf("%s/%s: expected pnl %.2f present=%v, got %.2f present=%v", c.symbol, c.runID, c.pnl, c.present, pnl, ok)
+		}
+	}
+
+	if _, err := NewManager(nil).CompareRuns([]string{"a", "a"}, "", 0); err == nil {
+		t.Errorf("Expected an error when fewer than %d distinct runs are given", minCompareRuns)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"nofx/market"
 	"nofx/store"
+	"nofx/trader/strategy"
 )
 
 // AIConfig defines the AI client configuration used in backtesting.
@@ -50,11 +51,36 @@ type BacktestConfig struct {
 	AICfg    AIConfig       `json:"ai"`
 	Leverage LeverageConfig `json:"leverage"`
 
+	// Market of the symbols (crypto, stock, forex, metal); picks the history source, session calendar and cost defaults
+	AssetClass string `json:"asset_class,omitempty"`
+
 	SharedAICachePath         string `json:"ai_cache_path,omitempty"`
 	CheckpointIntervalBars    int    `json:"checkpoint_interval_bars,omitempty"`
 	CheckpointIntervalSeconds int    `json:"checkpoint_interval_seconds,omitempty"`
 	ReplayDecisionDir         string `json:"replay_decision_dir,omitempty"`
 
+	// Grid strategy to replay instead of AI decisions (set directly or from a grid_trading StrategyID)
+	GridConfig  *store.GridStrategyConfig `json:"grid_config,omitempty"`
+	MakerFeeBps float64                   `json:"maker_fee_bps,omitempty"` // Fee on resting grid limit fills
+
+	// Pairs strategy to replay instead of AI decisions (set directly or from a pairs StrategyID)
+	PairsConfig *store.PairsStrategyConfig `json:"pairs_config,omitempty"`
+
+	// Live trader whose recorded decisions are replayed instead of AI calls, with optional risk overrides
+	LiveReplay *LiveReplayConfig `json:"live_replay,omitempty"`
+
+	// Volume-capped fills with size-dependent slippage for AI and live replay orders
+	FillModel *FillModelConfig `json:"fill_model,omitempty"`
+
+	// Exchange-style maintenance margin tiers and isolated/cross margin instead of entry ± entry/leverage liquidation
+	Margin *MarginConfig `json:"margin,omitempty"`
+
+	// Baselines valued on the same data feed and compared in the metrics (btc_hold, equal_weight, ema_cross)
+	Benchmarks []string `json:"benchmarks,omitempty"`
+
+	// Strategy settings applied on top of ToStrategyConfig (set per child run by parameter sweeps)
+	Overrides *StrategyOverrides `json:"strategy_overrides,omitempty"`
+
 	// Internal: loaded strategy config (set by Manager when StrategyID is provided)
 	loadedStrategy *store.StrategyConfig `json:"-"`
 }
@@ -74,15 +100,46 @@ func (cfg *BacktestConfig) Validate() error {
 	}
 	cfg.AIModelID = strings.TrimSpace(cfg.AIModelID)
 
+	assetClass, err := market.NormalizeAssetClass(cfg.AssetClass)
+	if err != nil {
+		return err
+	}
+	cfg.AssetClass = assetClass
+	if assetClass != market.AssetCrypto && (cfg.PairsConfig != nil || cfg.LiveReplay != nil) {
+		return fmt.Errorf("pairs and live replay backtests only support crypto")
+	}
+
+	if cfg.GridConfig != nil {
+		if err := cfg.validateGrid(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.PairsConfig != nil {
+		if err := strategy.NormalizePairsConfig(cfg.PairsConfig); err != nil {
+			return err
+		}
+		cfg.Symbols = []string{cfg.PairsConfig.SymbolA, cfg.PairsConfig.SymbolB}
+	}
+
+	if cfg.LiveReplay != nil {
+		if err := cfg.LiveReplay.validate(); err != nil {
+			return err
+		}
+	}
+
 	if len(cfg.Symbols) == 0 {
 		return fmt.Errorf("at least one symbol is required")
 	}
 	for i, sym := range cfg.Symbols {
-		cfg.Symbols[i] = market.Normalize(sym)
+		cfg.Symbols[i] = market.NormalizeAssetSymbol(assetClass, sym)
 	}
 
 	if len(cfg.Timeframes) == 0 {
 		cfg.Timeframes = []string{"3m", "15m", "4h"}
+		if assetClass != market.AssetCrypto {
+			cfg.Timeframes[0] = "5m" // Twelve Data has no 3m bars
+		}
 	}
 	normTF := make([]string, 0, len(cfg.Timeframes))
 	for _, tf := range cfg.Timeframes {
@@ -90,9 +147,21 @@ func (cfg *BacktestConfig) Validate() error {
 		if err != nil {
 			return fmt.Errorf("invalid timeframe '%s': %w", tf, err)
 		}
+		if err := validateHistoryTimeframe(assetClass, normalized); err != nil {
+			return fmt.Errorf("invalid timeframe '%s': %w", tf, err)
+		}
 		normTF = append(normTF, normalized)
 	}
 	cfg.Timeframes = normTF
+	if cfg.PairsConfig != nil {
+		// The spread is computed on the pairs timeframe, so the feed has to load it
+		if !contains(cfg.Timeframes, cfg.PairsConfig.Timeframe) {
+			cfg.Timeframes = append(cfg.Timeframes, cfg.PairsConfig.Timeframe)
+		}
+		if cfg.DecisionTimeframe == "" {
+			cfg.DecisionTimeframe = cfg.PairsConfig.Timeframe
+		}
+	}
 
 	if cfg.DecisionTimeframe == "" {
 		cfg.DecisionTimeframe = cfg.Timeframes[0]
@@ -101,6 +170,9 @@ func (cfg *BacktestConfig) Validate() error {
 	if err != nil {
 		return fmt.Errorf("invalid decision_timeframe: %w", err)
 	}
+	if err := validateHistoryTimeframe(assetClass, normalizedDecision); err != nil {
+		return fmt.Errorf("invalid decision_timeframe: %w", err)
+	}
 	cfg.DecisionTimeframe = normalizedDecision
 
 	if cfg.DecisionCadenceNBars <= 0 {
@@ -121,6 +193,28 @@ func (cfg *BacktestConfig) Validate() error {
 	if err := validateFillPolicy(cfg.FillPolicy); err != nil {
 		return err
 	}
+	if cfg.FillModel != nil {
+		if err := cfg.FillModel.validate(); err != nil {
+			return err
+		}
+	}
+	if cfg.Margin != nil {
+		if err := cfg.Margin.validate(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Overrides != nil {
+		if err := cfg.Overrides.validate(); err != nil {
+			return err
+		}
+	}
+
+	benchmarks, err := normalizeBenchmarks(cfg.Benchmarks)
+	if err != nil {
+		return err
+	}
+	cfg.Benchmarks = benchmarks
 
 	if cfg.CheckpointIntervalBars <= 0 {
 		cfg.CheckpointIntervalBars = 20
@@ -146,6 +240,19 @@ func (cfg *BacktestConfig) Validate() error {
 		cfg.AICfg.Temperature = 0.4
 	}
 
+	if defaults, ok := assetDefaults[assetClass]; ok {
+		if cfg.FeeBps == 0 {
+			cfg.FeeBps = defaults.feeBps
+		}
+		if cfg.SlippageBps == 0 {
+			cfg.SlippageBps = defaults.slippageBps
+		}
+		// Non-crypto symbols are never BTC/ETH, so the altcoin cap is the one resolveLeverage applies
+		if cfg.Leverage.AltcoinLeverage <= 0 {
+			cfg.Leverage.AltcoinLeverage = defaults.leverage
+		}
+	}
+
 	if cfg.Leverage.BTCETHLeverage <= 0 {
 		cfg.Leverage.BTCETHLeverage = 5
 	}
@@ -156,6 +263,116 @@ func (cfg *BacktestConfig) Validate() error {
 	return nil
 }
 
+// validateGrid fills grid defaults and pins the run to the grid symbol.
+func (cfg *BacktestConfig) validateGrid() error {
+	grid := cfg.GridConfig
+	if len(grid.Basket) > 0 {
+		return fmt.Errorf("grid_config.basket is not supported in backtests yet, run one symbol per backtest")
+	}
+	grid.Symbol = strings.TrimSpace(grid.Symbol)
+	if grid.Symbol == "" && len(cfg.Symbols) > 0 {
+		grid.Symbol = cfg.Symbols[0]
+	}
+	if grid.Symbol == "" {
+		return fmt.Errorf("grid_config.symbol is required")
+	}
+	grid.Symbol = market.NormalizeAssetSymbol(cfg.AssetClass, grid.Symbol)
+	cfg.Symbols = []string{grid.Symbol}
+
+	if grid.GridCount < 2 {
+		return fmt.Errorf("grid_config.grid_count must be at least 2")
+	}
+	if grid.TotalInvestment <= 0 {
+		return fmt.Errorf("grid_config.total_investment must be positive")
+	}
+	if grid.Leverage <= 0 {
+		grid.Leverage = 1
+	}
+	if !grid.UseATRBounds && (grid.LowerPrice <= 0 || grid.UpperPrice <= grid.LowerPrice) {
+		return fmt.Errorf("grid_config requires upper_price > lower_price > 0 or use_atr_bounds")
+	}
+	if cfg.MakerFeeBps <= 0 {
+		cfg.MakerFeeBps = 2
+	}
+	return nil
+}
+
+// StrategyOverrides are strategy settings that have no BacktestConfig field of their own.
+type StrategyOverrides struct {
+	BTCETHPositionRatio  float64         `json:"btc_eth_position_ratio,omitempty"` // RiskControl.BTCETHMaxPositionValueRatio
+	AltcoinPositionRatio float64         `json:"altcoin_position_ratio,omitempty"` // RiskControl.AltcoinMaxPositionValueRatio
+	Indicators           map[string]bool `json:"indicators,omitempty"`             // Indicator toggles by JSON name, e.g. "enable_rsi"
+}
+
+// sweepableIndicators maps indicator toggle names to their IndicatorConfig fields.
+var sweepableIndicators = map[string]func(*store.IndicatorConfig) *bool{
+	"enable_raw_klines":   func(ind *store.IndicatorConfig) *bool { return &ind.EnableRawKlines },
+	"enable_ema":          func(ind *store.IndicatorConfig) *bool { return &ind.EnableEMA },
+	"enable_macd":         func(ind *store.IndicatorConfig) *bool { return &ind.EnableMACD },
+	"enable_rsi":          func(ind *store.IndicatorConfig) *bool { return &ind.EnableRSI },
+	"enable_atr":          func(ind *store.IndicatorConfig) *bool { return &ind.EnableATR },
+	"enable_boll":         func(ind *store.IndicatorConfig) *bool { return &ind.EnableBOLL },
+	"enable_volume":       func(ind *store.IndicatorConfig) *bool { return &ind.EnableVolume },
+	"enable_oi":           func(ind *store.IndicatorConfig) *bool { return &ind.EnableOI },
+	"enable_funding_rate": func(ind *store.IndicatorConfig) *bool { return &ind.EnableFundingRate },
+}
+
+func (o *StrategyOverrides) validate() error {
+	if o.BTCETHPositionRatio < 0 || o.AltcoinPositionRatio < 0 {
+		return fmt.Errorf("position ratios cannot be negative")
+	}
+	for name := range o.Indicators {
+		if _, ok := sweepableIndicators[name]; !ok {
+			return fmt.Errorf("unsupported indicator toggle '%s'", name)
+		}
+	}
+	return nil
+}
+
+func (o *StrategyOverrides) apply(cfg *store.StrategyConfig) {
+	if o == nil {
+		return
+	}
+	if o.BTCETHPositionRatio > 0 {
+		cfg.RiskControl.BTCETHMaxPositionValueRatio = o.BTCETHPositionRatio
+	}
+	if o.AltcoinPositionRatio > 0 {
+		cfg.RiskControl.AltcoinMaxPositionValueRatio = o.AltcoinPositionRatio
+	}
+	for name, on := range o.Indicators {
+		if field, ok := sweepableIndicators[name]; ok {
+			*field(&cfg.Indicators) = on
+		}
+	}
+}
+
+// IsGrid reports whether the run replays a grid strategy rather than AI decisions.
+func (cfg *BacktestConfig) IsGrid() bool {
+	return cfg != nil && cfg.GridConfig != nil
+}
+
+// IsPairs reports whether the run replays a pairs strategy rather than AI decisions.
+func (cfg *BacktestConfig) IsPairs() bool {
+	return cfg != nil && cfg.PairsConfig != nil
+}
+
+// IsLiveReplay reports whether the run replays a live trader's recorded decisions rather than calling the AI.
+func (cfg *BacktestConfig) IsLiveReplay() bool {
+	return cfg != nil && cfg.LiveReplay != nil
+}
+
+// IsMechanical reports whether the run replays a rule-based strategy or recorded decisions and never calls the model.
+// Pairs AI entry confirmation is live-only; backtests take every signal.
+func (cfg *BacktestConfig) IsMechanical() bool {
+	return cfg.IsGrid() || cfg.IsPairs() || cfg.IsLiveReplay()
+}
+
+// IsCrypto reports whether the run trades crypto, the default asset class, rather than stocks, forex or metals.
+func (cfg *BacktestConfig) IsCrypto() bool {
+	class, err := market.NormalizeAssetClass(cfg.AssetClass)
+	return err == nil && class == market.AssetCrypto
+}
+
 // Duration returns the backtest interval duration.
 func (cfg *BacktestConfig) Duration() time.Duration {
 	if cfg == nil {
@@ -185,12 +402,26 @@ func validateFillPolicy(policy string) error {
 // SetLoadedStrategy sets the loaded strategy config from database.
 func (cfg *BacktestConfig) SetLoadedStrategy(strategy *store.StrategyConfig) {
 	cfg.loadedStrategy = strategy
+	if strategy != nil && strategy.StrategyType == "grid_trading" && strategy.GridConfig != nil && cfg.GridConfig == nil {
+		grid := *strategy.GridConfig
+		cfg.GridConfig = &grid
+	}
+	if strategy != nil && strategy.StrategyType == "pairs" && strategy.PairsConfig != nil && cfg.PairsConfig == nil {
+		pairs := *strategy.PairsConfig
+		cfg.PairsConfig = &pairs
+	}
 }
 
 // ToStrategyConfig converts BacktestConfig to StrategyConfig for unified prompt generation.
 // This ensures backtest uses the same StrategyEngine logic as live trading.
 // If a strategy was loaded from database (via StrategyID), it will be used with overrides.
 func (cfg *BacktestConfig) ToStrategyConfig() *store.StrategyConfig {
+	result := cfg.baseStrategyConfig()
+	cfg.Overrides.apply(result)
+	return result
+}
+
+func (cfg *BacktestConfig) baseStrategyConfig() *store.StrategyConfig {
 	// If a strategy was loaded from database, use it with some overrides
 	if cfg.loadedStrategy != nil {
 		result := *cfg.loadedStrategy // Make a copy
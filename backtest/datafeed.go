@@ -65,34 +65,29 @@ func (df *DataFeed) loadAll() error {
 	for _, symbol := range df.symbols {
 		ss := &symbolSeries{byTF: make(map[string]*timeframeSeries)}
 		for _, tf := range df.timeframes {
-			dur, _ := market.TFDuration(tf)
-			buffer := dur * 200
-			fetchStart := start.Add(-buffer)
-			if fetchStart.Before(time.Unix(0, 0)) {
-				fetchStart = time.Unix(0, 0)
-			}
-			fetchEnd := end.Add(dur)
-
-			klines, err := market.GetKlinesRange(symbol, tf, fetchStart, fetchEnd)
+			series, err := fetchSeries(df.cfg.AssetClass, symbol, tf, start, end)
 			if err != nil {
-				return fmt.Errorf("fetch klines for %s %s: %w", symbol, tf, err)
-			}
-			if len(klines) == 0 {
-				return fmt.Errorf("no klines for %s %s", symbol, tf)
-			}
-
-			series := &timeframeSeries{
-				klines:     klines,
-				closeTimes: make([]int64, len(klines)),
-			}
-			for i, k := range klines {
-				series.closeTimes[i] = k.CloseTime
+				return err
 			}
 			ss.byTF[tf] = series
 		}
 		df.symbolSeries[symbol] = ss
 	}
 
+	// The BTC benchmark needs BTC prices even when the run doesn't trade it; only the decision timeframe is loaded
+	for _, name := range df.cfg.Benchmarks {
+		if name != BenchmarkBTCHold {
+			continue
+		}
+		if _, ok := df.symbolSeries[benchmarkBTCSymbol]; !ok {
+			series, err := fetchSeries(market.AssetCrypto, benchmarkBTCSymbol, df.primaryTF, start, end)
+			if err != nil {
+				return fmt.Errorf("benchmark: %w", err)
+			}
+			df.symbolSeries[benchmarkBTCSymbol] = &symbolSeries{byTF: map[string]*timeframeSeries{df.primaryTF: series}}
+		}
+	}
+
 	// Generate backtest progress timeline using the primary timeframe of the first symbol
 	firstSymbol := df.symbols[0]
 	primarySeries := df.symbolSeries[firstSymbol].byTF[df.primaryTF]
@@ -119,6 +114,46 @@ func (df *DataFeed) loadAll() error {
 	return nil
 }
 
+// fetchSeries loads one symbol's klines for the run range plus a 200-bar indicator warmup.
+// Bars outside the asset class's session (pre/after-market, weekend quotes) are dropped, so neither decisions nor indicators see them.
+func fetchSeries(class, symbol, tf string, start, end time.Time) (*timeframeSeries, error) {
+	dur, err := market.TFDuration(tf)
+	if err != nil {
+		return nil, err
+	}
+	fetchStart := start.Add(-warmupSpan(class, dur))
+	if fetchStart.Before(time.Unix(0, 0)) {
+		fetchStart = time.Unix(0, 0)
+	}
+	fetchEnd := end.Add(dur)
+
+	klines, err := fetchKlines(class, symbol, tf, fetchStart, fetchEnd)
+	if err != nil {
+		return nil, fmt.Errorf("fetch klines for %s %s: %w", symbol, tf, err)
+	}
+	if class != market.AssetCrypto {
+		inSession := klines[:0]
+		for _, k := range klines {
+			if market.InSession(class, k.OpenTime, k.CloseTime) {
+				inSession = append(inSession, k)
+			}
+		}
+		klines = inSession
+	}
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("no klines for %s %s", symbol, tf)
+	}
+
+	series := &timeframeSeries{
+		klines:     klines,
+		closeTimes: make([]int64, len(klines)),
+	}
+	for i, k := range klines {
+		series.closeTimes[i] = k.CloseTime
+	}
+	return series, nil
+}
+
 func (df *DataFeed) DecisionBarCount() int {
 	return len(df.decisionTimes)
 }
@@ -150,6 +185,15 @@ func (df *DataFeed) sliceUpTo(symbol, tf string, ts int64) []market.Kline {
 	return series.klines[:idx]
 }
 
+// closeAt returns the close of the last decision-timeframe bar closed by ts.
+func (df *DataFeed) closeAt(symbol string, ts int64) (float64, bool) {
+	klines := df.sliceUpTo(symbol, df.primaryTF, ts)
+	if len(klines) == 0 {
+		return 0, false
+	}
+	return klines[len(klines)-1].Close, true
+}
+
 func (df *DataFeed) BuildMarketData(ts int64) (map[string]*market.Data, map[string]map[string]*market.Data, error) {
 	result := make(map[string]*market.Data, len(df.symbols))
 	multi := make(map[string]map[string]*market.Data, len(df.symbols))
@@ -0,0 +1,316 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+
+	"nofx/market"
+)
+
+const (
+	defaultFillMaxVolumePct = 10.0
+	defaultFillImpactFactor = 1.0
+	defaultFillMaxQueueBars = 20
+)
+
+// FillModelConfig makes AI and live replay fills depend on bar liquidity instead of always filling in full.
+// Each bar a symbol's orders may take at most MaxVolumePct of that bar's volume; the rest waits for later bars.
+// Slippage is SlippageBps plus ImpactFactor × bar range (bps) × √(fill quantity / bar volume).
+// Grid and pairs runs keep their own fills and ignore it.
+type FillModelConfig struct {
+	MaxVolumePct   float64 `json:"max_volume_pct,omitempty"`  // Share of bar volume (0-100, default 10)
+	ImpactFactor   float64 `json:"impact_factor,omitempty"`   // Scales the size and volatility slippage term (default 1)
+	LatencySeconds int     `json:"latency_seconds,omitempty"` // Execute this far into the next bar instead of at the fill policy price
+	MaxQueueBars   int     `json:"max_queue_bars,omitempty"`  // Unfilled remainders are dropped after this many bars (default 20)
+}
+
+func (fc *FillModelConfig) validate() error {
+	if fc.MaxVolumePct < 0 || fc.MaxVolumePct > 100 {
+		return fmt.Errorf("fill_model.max_volume_pct must be between 0 and 100")
+	}
+	if fc.MaxVolumePct == 0 {
+		fc.MaxVolumePct = defaultFillMaxVolumePct
+	}
+	if fc.ImpactFactor < 0 {
+		return fmt.Errorf("fill_model.impact_factor cannot be negative")
+	}
+	if fc.ImpactFactor == 0 {
+		fc.ImpactFactor = defaultFillImpactFactor
+	}
+	if fc.LatencySeconds < 0 {
+		return fmt.Errorf("fill_model.latency_seconds cannot be negative")
+	}
+	if fc.MaxQueueBars < 0 {
+		return fmt.Errorf("fill_model.max_queue_bars cannot be negative")
+	}
+	if fc.MaxQueueBars == 0 {
+		fc.MaxQueueBars = defaultFillMaxQueueBars
+	}
+	return nil
+}
+
+// PendingFill is the unfilled remainder of an order, worked on later bars.
+type PendingFill struct {
+	Symbol   string  `json:"symbol"`
+	Side     string  `json:"side"`
+	Open     bool    `json:"open"`
+	Quantity float64 `json:"quantity"` // Still to fill
+	Leverage int     `json:"leverage,omitempty"`
+	Cycle    int     `json:"cycle"`
+	Bars     int     `json:"bars"` // Bars waited so far
+}
+
+func (p PendingFill) action() string {
+	if p.Open {
+		return "open_" + p.Side
+	}
+	return "close_" + p.Side
+}
+
+// FillModelSnapshot holds the queued remainders, carried in checkpoints so resumed runs keep working them.
+type FillModelSnapshot struct {
+	Pending []PendingFill `json:"pending,omitempty"`
+}
+
+// fillModel tracks how much of each bar's volume has been used and which remainders are still queued.
+type fillModel struct {
+	cfg             *FillModelConfig
+	feed            *DataFeed
+	nextBarFills    bool    // The fill policy executes on the next bar, so its volume is the one consumed
+	baseSlippageBps float64 // The run's flat SlippageBps, paid on top of the impact term
+	pending         []PendingFill
+	usedTS          int64
+	used            map[string]float64
+}
+
+func newFillModel(cfg BacktestConfig, feed *DataFeed) *fillModel {
+	if cfg.FillModel == nil {
+		return nil
+	}
+	return &fillModel{
+		cfg:             cfg.FillModel,
+		feed:            feed,
+		nextBarFills:    cfg.FillPolicy == FillPolicyNextOpen || cfg.FillModel.LatencySeconds > 0,
+		baseSlippageBps: cfg.SlippageBps,
+		used:            make(map[string]float64),
+	}
+}
+
+func (f *fillModel) snapshot() *FillModelSnapshot {
+	return &FillModelSnapshot{Pending: append([]PendingFill(nil), f.pending...)}
+}
+
+func (f *fillModel) restore(snap *FillModelSnapshot) {
+	if snap == nil {
+		return
+	}
+	f.pending = append([]PendingFill(nil), snap.Pending...)
+}
+
+// execBar is the bar whose volume an order at decision bar ts trades against.
+func (f *fillModel) execBar(symbol string, ts int64) *market.Kline {
+	curr, next := f.feed.decisionBarSnapshot(symbol, ts)
+	if f.nextBarFills && next != nil {
+		return next
+	}
+	return curr
+}
+
+// latencyPrice interpolates between the next bar's open and close, LatencySeconds into that bar.
+func (f *fillModel) latencyPrice(symbol string, ts int64) (float64, bool) {
+	if f.cfg.LatencySeconds <= 0 {
+		return 0, false
+	}
+	_, next := f.feed.decisionBarSnapshot(symbol, ts)
+	if next == nil || next.Open <= 0 {
+		return 0, false
+	}
+	barMs := float64(next.CloseTime - next.OpenTime + 1)
+	frac := 1.0
+	if barMs > 0 {
+		frac = math.Min(1, float64(f.cfg.LatencySeconds)*1000/barMs)
+	}
+	return next.Open + (next.Close-next.Open)*frac, true
+}
+
+// take reserves up to qty from the bar's remaining volume and returns the quantity filled and its slippage.
+// Bars without volume data fill in full at the flat slippage, as they did before the model existed.
+func (f *fillModel) take(symbol string, qty float64, ts int64) (float64, float64) {
+	if ts != f.usedTS {
+		f.usedTS = ts
+		f.used = make(map[string]float64)
+	}
+	bar := f.execBar(symbol, ts)
+	if bar == nil || bar.Volume <= 0 {
+		return qty, f.baseSlippageBps
+	}
+	capacity := bar.Volume*f.cfg.MaxVolumePct/100 - f.used[symbol]
+	filled := math.Max(0, math.Min(qty, capacity))
+	if filled <= epsilon {
+		return 0, 0
+	}
+	f.used[symbol] += filled
+	return filled, f.baseSlippageBps + f.impactBps(*bar, filled)
+}
+
+func (f *fillModel) impactBps(bar market.Kline, qty float64) float64 {
+	if bar.Close <= 0 || bar.Volume <= 0 {
+		return 0
+	}
+	rangeBps := (bar.High - bar.Low) / bar.Close * 10000
+	return f.cfg.ImpactFactor * rangeBps * math.Sqrt(qty/bar.Volume)
+}
+
+func (f *fillModel) queue(p PendingFill) {
+	f.pending = append(f.pending, p)
+}
+
+// cancel drops queued remainders for the symbol side; a new close supersedes both queued opens and closes.
+func (f *fillModel) cancel(symbol, side string) {
+	kept := f.pending[:0]
+	for _, p := range f.pending {
+		if p.Symbol != symbol || p.Side != side {
+			kept = append(kept, p)
+		}
+	}
+	f.pending = kept
+}
+
+// openOrder opens as much of qty as the fill model allows and queues the rest.
+// A zero filled quantity with a nil error means the whole order was queued.
+func (r *Runner) openOrder(symbol, side string, qty float64, leverage int, price float64, ts int64, cycle int) (*position, float64, float64, float64, error) {
+	if r.fills == nil {
+		pos, fee, execPrice, err := r.account.Open(symbol, side, qty, leverage, price, ts)
+		return pos, fee, execPrice, qty, err
+	}
+	filled, slippageBps := r.fills.take(symbol, qty, ts)
+	remainder := PendingFill{Symbol: symbol, Side: side, Open: true, Quantity: qty - filled, Leverage: leverage, Cycle: cycle}
+	if filled <= epsilon {
+		r.fills.queue(remainder)
+		return nil, 0, 0, 0, nil
+	}
+	pos, fee, execPrice, err := r.account.OpenWithSlippage(symbol, side, filled, leverage, price, ts, slippageBps)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+	if remainder.Quantity > epsilon {
+		r.fills.queue(remainder)
+	}
+	return pos, fee, execPrice, filled, nil
+}
+
+// closeOrder closes as much of qty as the fill model allows and queues the rest.
+func (r *Runner) closeOrder(symbol, side string, qty, price float64, ts int64, cycle int) (float64, float64, float64, float64, error) {
+	if r.fills == nil {
+		realized, fee, execPrice, err := r.account.Close(symbol, side, qty, price)
+		return realized, fee, execPrice, qty, err
+	}
+	r.fills.cancel(symbol, side)
+	filled, slippageBps := r.fills.take(symbol, qty, ts)
+	remainder := PendingFill{Symbol: symbol, Side: side, Quantity: qty - filled, Cycle: cycle}
+	if filled <= epsilon {
+		r.fills.queue(remainder)
+		return 0, 0, 0, 0, nil
+	}
+	realized, fee, execPrice, err := r.account.CloseWithSlippage(symbol, side, filled, price, slippageBps)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	if remainder.Quantity > epsilon {
+		r.fills.queue(remainder)
+	}
+	return realized, fee, execPrice, filled, nil
+}
+
+// stepPendingFills works the queued remainders against the bar before any new orders use its volume.
+func (r *Runner) stepPendingFills(ts int64, priceMap map[string]float64) ([]TradeEvent, []string) {
+	f := r.fills
+	if len(f.pending) == 0 {
+		return nil, nil
+	}
+	var (
+		events []TradeEvent
+		notes  []string
+		kept   []PendingFill
+	)
+	for _, p := range f.pending {
+		p.Bars++
+		if p.Bars > f.cfg.MaxQueueBars {
+			notes = append(notes, fmt.Sprintf("%s %s remainder %.6f dropped after %d bars", p.Symbol, p.action(), p.Quantity, f.cfg.MaxQueueBars))
+			continue
+		}
+		if !p.Open {
+			p.Quantity = math.Min(p.Quantity, r.remainingPosition(p.Symbol, p.Side))
+			if p.Quantity <= epsilon {
+				continue // Position already gone
+			}
+		}
+		mark := priceMap[p.Symbol]
+		if mark <= 0 {
+			kept = append(kept, p)
+			continue
+		}
+		filled, slippageBps := f.take(p.Symbol, p.Quantity, ts)
+		if filled <= epsilon {
+			kept = append(kept, p)
+			continue
+		}
+		evt, err := r.fillPending(p, filled, mark, r.executionPrice(p.Symbol, mark, ts), slippageBps, ts)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%s %s remainder dropped: %v", p.Symbol, p.action(), err))
+			continue
+		}
+		events = append(events, evt)
+		p.Quantity -= filled
+		if p.Quantity > epsilon {
+			kept = append(kept, p)
+		}
+	}
+	f.pending = kept
+	return events, notes
+}
+
+func (r *Runner) fillPending(p PendingFill, qty, mark, price, slippageBps float64, ts int64) (TradeEvent, error) {
+	evt := TradeEvent{
+		Timestamp: ts,
+		Symbol:    p.Symbol,
+		Action:    p.action(),
+		Side:      p.Side,
+		Quantity:  qty,
+		Cycle:     p.Cycle,
+		Note:      fmt.Sprintf("queued fill, bar %d", p.Bars),
+	}
+	if p.Open {
+		pos, fee, execPrice, err := r.account.OpenWithSlippage(p.Symbol, p.Side, qty, p.Leverage, price, ts, slippageBps)
+		if err != nil {
+			return TradeEvent{}, err
+		}
+		evt.Price, evt.Fee, evt.Leverage, evt.PositionAfter = execPrice, fee, pos.Leverage, pos.Quantity
+		evt.Slippage = execPrice - mark
+		if p.Side == "short" {
+			evt.Slippage = mark - execPrice
+		}
+	} else {
+		lev := r.account.positionLeverage(p.Symbol, p.Side)
+		realized, fee, execPrice, err := r.account.CloseWithSlippage(p.Symbol, p.Side, qty, price, slippageBps)
+		if err != nil {
+			return TradeEvent{}, err
+		}
+		evt.Price, evt.Fee, evt.Leverage = execPrice, fee, lev
+		evt.RealizedPnL = realized - fee
+		evt.PositionAfter = r.remainingPosition(p.Symbol, p.Side)
+		evt.Slippage = mark - execPrice
+		if p.Side == "short" {
+			evt.Slippage = execPrice - mark
+		}
+	}
+	evt.OrderValue = evt.Price * qty
+	return evt, nil
+}
+
+func (r *Runner) fillModelSnapshot() *FillModelSnapshot {
+	if r.fills == nil {
+		return nil
+	}
+	return r.fills.snapshot()
+}
@@ -0,0 +1,138 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+
+	"nofx/kernel"
+	"nofx/market"
+)
+
+// newFillTestRunner builds a runner with the fill model over one symbol's bars.
+func newFillTestRunner(t *testing.T, fm FillModelConfig, bars []market.Kline) *Runner {
+	t.Helper()
+	if err := fm.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	cfg := BacktestConfig{FillModel: &fm}
+	r := &Runner{
+		cfg:     cfg,
+		feed:    testFeed("1m", map[string][]market.Kline{"BTCUSDT": bars}),
+		account: NewBacktestAccount(100_000, 0, 0),
+		state:   &BacktestState{},
+	}
+	r.fills = newFillModel(cfg, r.feed)
+	return r
+}
+
+func TestFillModelTake(t *testing.T) {
+	bars := testBars(
+		[4]float64{100, 101, 99, 100}, // 200 bps range
+		[4]float64{100, 104, 96, 100}, // 800 bps range
+		[4]float64{100, 101, 99, 100},
+	)
+	bars[2].Volume = 0
+	f := newFillTestRunner(t, FillModelConfig{MaxVolumePct: 10, ImpactFactor: 1}, bars).fills
+	f.baseSlippageBps = 2
+
+	tests := []struct {
+		name     string
+		ts       int64
+		qty      float64
+		filled   float64
+		slippage float64
+	}{
+		{"within the volume cap", bars[0].CloseTime, 10, 10, 2 + 200*math.Sqrt(10.0/1000)},
+		{"capped at what the bar has left", bars[0].CloseTime, 200, 90, 2 + 200*math.Sqrt(90.0/1000)},
+		{"bar volume used up", bars[0].CloseTime, 5, 0, 0},
+		{"new bar resets the volume, wider range slips more", bars[1].CloseTime, 10, 10, 2 + 800*math.Sqrt(10.0/1000)},
+		{"larger size slips more", bars[1].CloseTime, 40, 40, 2 + 800*math.Sqrt(40.0/1000)},
+		{"zero volume fills in full at the flat slippage", bars[2].CloseTime, 500, 500, 2},
+	}
+	for _, tt := range tests {
+		filled, slippage := f.take("BTCUSDT", tt.qty, tt.ts)
+		if math.Abs(filled-tt.filled) > 1e-9 || math.Abs(slippage-tt.slippage) > 1e-9 {
+			t.Errorf("%s: expected %.4f filled at %.4f bps, got %.4f at %.4f bps", tt.name, tt.filled, tt.slippage, filled, slippage)
+		}
+	}
+}
+
+func TestFillModelLatency(t *testing.T) {
+	bars := testBars(
+		[4]float64{100, 100, 100, 100},
+		[4]float64{100, 112, 99, 110},
+	)
+	bars[0].Volume = 0 // Only the next bar's volume counts once latency is set
+
+	tests := []struct {
+		name    string
+		latency int
+		price   float64
+		ok      bool
+	}{
+		{"no latency uses the fill policy", 0, 0, false},
+		{"half way into the next bar", 30, 105, true},
+		{"capped at the next bar's close", 120, 110, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFillTestRunner(t, FillModelConfig{LatencySeconds: tt.latency}, bars)
+			price, ok := r.fills.latencyPrice("BTCUSDT", bars[0].CloseTime)
+			if ok != tt.ok || math.Abs(price-tt.price) > 1e-9 {
+				t.Errorf("Expected %.2f ok=%v, got %.2f ok=%v", tt.price, tt.ok, price, ok)
+			}
+			if tt.ok {
+				if got := r.executionPrice("BTCUSDT", 100, bars[0].CloseTime); math.Abs(got-tt.price) > 1e-9 {
+					t.Errorf("Expected orders to execute at the latency price %.2f, got %.2f", tt.price, got)
+				}
+				if filled, _ := r.fills.take("BTCUSDT", 50, bars[0].CloseTime); filled != 50 {
+					t.Errorf("Expected the next bar's volume to be used, filled %.2f", filled)
+				}
+			}
+		})
+	}
+}
+
+func TestFillModelQueuedRemainder(t *testing.T) {
+	bars := testBars(
+		[4]float64{100, 101, 99, 100},
+		[4]float64{100, 101, 99, 100},
+		[4]float64{100, 101, 99, 100},
+		[4]float64{100, 101, 99, 100},
+	)
+	r := newFillTestRunner(t, FillModelConfig{MaxVolumePct: 10}, bars)
+	priceMap := map[string]float64{"BTCUSDT": 100}
+
+	// 250 units against 100 per bar: fills over three bars
+	_, events, _, err := r.executeDecision(kernel.Decision{Symbol: "BTCUSDT", Action: "open_long", PositionSizeUSD: 25_000}, priceMap, bars[0].CloseTime, 1)
+	if err != nil {
+		t.Fatalf("executeDecision: %v", err)
+	}
+	if len(events) != 1 || math.Abs(events[0].Quantity-100) > 1e-9 {
+		t.Fatalf("Expected 100 filled on the decision bar, got %+v", events)
+	}
+	if len(r.fills.pending) != 1 || math.Abs(r.fills.pending[0].Quantity-150) > 1e-9 {
+		t.Fatalf("Expected 150 queued, got %+v", r.fills.pending)
+	}
+
+	for i, want := range []struct{ qty, after float64 }{{100, 200}, {50, 250}} {
+		bar := i + 1
+		events, _ := r.stepPendingFills(bars[bar].CloseTime, priceMap)
+		if len(events) != 1 || math.Abs(events[0].Quantity-want.qty) > 1e-9 || math.Abs(events[0].PositionAfter-want.after) > 1e-9 {
+			t.Fatalf("Bar %d: expected %.0f filled up to %.0f, got %+v", bar, want.qty, want.after, events)
+		}
+		if wantSlip := 100 * 200 * math.Sqrt(want.qty/1000) / 10000; math.Abs(events[0].Slippage-wantSlip) > 1e-9 {
+			t.Errorf("Bar %d: expected slippage %.4f, got %.4f", bar, wantSlip, events[0].Slippage)
+		}
+	}
+	if len(r.fills.pending) != 0 {
+		t.Errorf("Expected the queue to be empty once the order filled, got %+v", r.fills.pending)
+	}
+
+	// A remainder that outlives MaxQueueBars is dropped
+	r.fills.cfg.MaxQueueBars = 1
+	r.fills.queue(PendingFill{Symbol: "BTCUSDT", Side: "long", Open: true, Quantity: 10, Leverage: 5, Bars: 1})
+	if events, notes := r.stepPendingFills(bars[3].CloseTime, priceMap); len(events) != 0 || len(notes) != 1 || len(r.fills.pending) != 0 {
+		t.Errorf("Expected the stale remainder to be dropped with a note, got events %+v notes %v", events, notes)
+	}
+}
@@ -0,0 +1,512 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/store"
+	"nofx/trader/strategy"
+)
+
+// GridSnapshot is the simulated grid state, carried in checkpoints so resumed runs keep their levels.
+type GridSnapshot struct {
+	Levels               []kernel.GridLevelInfo `json:"levels"`
+	UpperPrice           float64                `json:"upper_price"`
+	LowerPrice           float64                `json:"lower_price"`
+	GridSpacing          float64                `json:"grid_spacing"`
+	Direction            market.GridDirection   `json:"direction"`
+	IsPaused             bool                   `json:"is_paused"`
+	Stopped              bool                   `json:"stopped"` // Max drawdown exit, never resumes
+	PositionReductionPct float64                `json:"position_reduction_pct"`
+	PeakEquity           float64                `json:"peak_equity"`
+	BreakoutLevel        string                 `json:"breakout_level,omitempty"`
+	BreakoutDirection    string                 `json:"breakout_direction,omitempty"`
+	BreakoutConfirmCount int                    `json:"breakout_confirm_count,omitempty"`
+	DailyPnL             float64                `json:"daily_pnl"`
+	DailyPnLDay          string                 `json:"daily_pnl_day,omitempty"`
+	InventoryQty         float64                `json:"inventory_qty,omitempty"`   // Base inventory kept by an infinity grid
+	InventoryEntry       float64                `json:"inventory_entry,omitempty"` // Average entry of the base inventory
+	TrailCount           int                    `json:"trail_count,omitempty"`
+}
+
+// gridSimulator replays the live grid rules against historical bars.
+// Every level on the right side of price rests a limit order; a fill re-arms the opposite
+// order one level away, so each round trip captures one grid spacing.
+type gridSimulator struct {
+	cfg     *store.GridStrategyConfig
+	account *BacktestAccount
+	state   GridSnapshot
+}
+
+func newGridSimulator(cfg *store.GridStrategyConfig, account *BacktestAccount) *gridSimulator {
+	return &gridSimulator{
+		cfg:     cfg,
+		account: account,
+		state:   GridSnapshot{Direction: market.GridDirectionNeutral},
+	}
+}
+
+func (g *gridSimulator) initialized() bool {
+	return len(g.state.Levels) > 0
+}
+
+// snapshot returns a copy of the grid state for checkpointing.
+func (g *gridSimulator) snapshot() *GridSnapshot {
+	snap := g.state
+	snap.Levels = append([]kernel.GridLevelInfo(nil), g.state.Levels...)
+	return &snap
+}
+
+func (g *gridSimulator) restore(snap *GridSnapshot) {
+	if snap == nil {
+		return
+	}
+	g.state = *snap
+	g.state.Levels = append([]kernel.GridLevelInfo(nil), snap.Levels...)
+}
+
+// initialize lays out the levels the same way InitializeGrid does and arms the opening orders.
+func (g *gridSimulator) initialize(price, atr float64) {
+	cfg := g.cfg
+	if cfg.UseATRBounds {
+		g.state.UpperPrice, g.state.LowerPrice = strategy.GridATRBounds(price, atr, cfg)
+	} else {
+		g.state.UpperPrice, g.state.LowerPrice = cfg.UpperPrice, cfg.LowerPrice
+	}
+	g.state.GridSpacing = (g.state.UpperPrice - g.state.LowerPrice) / float64(cfg.GridCount-1)
+	g.state.Levels = strategy.BuildGridLevels(g.state.LowerPrice, g.state.GridSpacing, price, cfg, nil)
+	if cfg.EnableDirectionAdjust {
+		strategy.ApplyGridDirection(g.state.Levels, g.state.Direction, cfg.DirectionBiasRatio, price)
+	}
+	g.arm(price)
+}
+
+// levelQuantity mirrors the per-level cap in placeGridLimitOrder, scaled down after a false breakout.
+func (g *gridSimulator) levelQuantity(level kernel.GridLevelInfo) float64 {
+	if level.Price <= 0 {
+		return 0
+	}
+	margin := g.cfg.TotalInvestment / float64(g.cfg.GridCount)
+	if level.AllocatedUSD > 0 && level.AllocatedUSD < margin {
+		margin = level.AllocatedUSD
+	}
+	margin *= 1 - g.state.PositionReductionPct/100
+	return margin * float64(g.cfg.Leverage) / level.Price
+}
+
+// arm rests an order on every level whose side can sit on the book at price.
+// The level nearest to price is left empty as the grid gap.
+func (g *gridSimulator) arm(price float64) {
+	for i := range g.state.Levels {
+		if g.state.Levels[i].State != "pending" {
+			g.state.Levels[i].State = "empty"
+			g.state.Levels[i].OrderQuantity = 0
+			g.state.Levels[i].PositionSize = 0
+		}
+	}
+	for _, i := range strategy.GridLevelsToArm(g.state.Levels, price) {
+		g.state.Levels[i].State = "pending"
+		g.state.Levels[i].OrderQuantity = g.levelQuantity(g.state.Levels[i])
+	}
+}
+
+// cancelAll drops every resting order, as cancelAllGridOrders does.
+func (g *gridSimulator) cancelAll() {
+	for i := range g.state.Levels {
+		if g.state.Levels[i].State == "pending" {
+			g.state.Levels[i].State = "empty"
+			g.state.Levels[i].OrderQuantity = 0
+		}
+	}
+}
+
+// fillBar fills the resting orders whose price the bar traded through, at the limit price with maker fees.
+// Orders re-armed by a fill only become eligible on the next bar.
+func (g *gridSimulator) fillBar(symbol string, bar market.Kline, ts int64, cycle int) []TradeEvent {
+	if g.state.IsPaused || g.state.Stopped {
+		return nil
+	}
+
+	var buys, sells []int
+	for i, level := range g.state.Levels {
+		if level.State != "pending" {
+			continue
+		}
+		if level.Side == "buy" && bar.Low <= level.Price {
+			buys = append(buys, i)
+		} else if level.Side == "sell" && bar.High >= level.Price {
+			sells = append(sells, i)
+		}
+	}
+	if len(buys) == 0 && len(sells) == 0 {
+		return nil
+	}
+
+	// Assume open→low→high→close on up bars and open→high→low→close on down bars
+	sort.Slice(buys, func(a, b int) bool { return g.state.Levels[buys[a]].Price > g.state.Levels[buys[b]].Price })
+	sort.Slice(sells, func(a, b int) bool { return g.state.Levels[sells[a]].Price < g.state.Levels[sells[b]].Price })
+	order := append(append([]int(nil), buys...), sells...)
+	if bar.Close < bar.Open {
+		order = append(append([]int(nil), sells...), buys...)
+	}
+
+	events := make([]TradeEvent, 0, len(order))
+	for _, idx := range order {
+		events = append(events, g.fillLevel(symbol, idx, ts, cycle)...)
+	}
+	return events
+}
+
+func (g *gridSimulator) fillLevel(symbol string, idx int, ts int64, cycle int) []TradeEvent {
+	level := &g.state.Levels[idx]
+	qty := level.OrderQuantity
+	side := level.Side
+	note := fmt.Sprintf("grid level %d %s", idx, side)
+
+	events, err := g.execute(symbol, side, qty, level.Price, ts, cycle, true, note)
+	level.OrderQuantity = 0
+	if err != nil {
+		level.State = "empty"
+		return events
+	}
+	level.State = "filled"
+	level.PositionSize = qty
+	level.PositionEntry = level.Price
+
+	// Re-arm the opposite order one level away to take the spacing back
+	next, counter := idx+1, "sell"
+	if side == "sell" {
+		next, counter = idx-1, "buy"
+	}
+	if next >= 0 && next < len(g.state.Levels) && g.state.Levels[next].State != "pending" {
+		g.state.Levels[next].Side = counter
+		g.state.Levels[next].State = "pending"
+		g.state.Levels[next].OrderQuantity = qty
+		g.state.Levels[next].PositionSize = 0
+	}
+	return events
+}
+
+// execute nets a grid order against the opposite position first, then opens the remainder.
+func (g *gridSimulator) execute(symbol, side string, qty, price float64, ts int64, cycle int, maker bool, note string) ([]TradeEvent, error) {
+	if qty <= epsilon {
+		return nil, fmt.Errorf("invalid grid quantity")
+	}
+	openSide, closeSide := "long", "short"
+	if side == "sell" {
+		openSide, closeSide = "short", "long"
+	}
+
+	events := make([]TradeEvent, 0, 2)
+	if pos, ok := g.account.positions[positionKey(symbol, closeSide)]; ok && pos.Quantity > epsilon {
+		closeQty := math.Min(qty, pos.Quantity)
+		lev := pos.Leverage
+		closeFn := g.account.Close
+		if maker {
+			closeFn = g.account.CloseMaker
+		}
+		realized, fee, execPrice, err := closeFn(symbol, closeSide, closeQty, price)
+		if err != nil {
+			return events, err
+		}
+		g.state.DailyPnL += realized - fee
+		events = append(events, TradeEvent{
+			Timestamp:     ts,
+			Symbol:        symbol,
+			Action:        "close_" + closeSide,
+			Side:          closeSide,
+			Quantity:      closeQty,
+			Price:         execPrice,
+			Fee:           fee,
+			OrderValue:    execPrice * closeQty,
+			RealizedPnL:   realized - fee,
+			Leverage:      lev,
+			Cycle:         cycle,
+			PositionAfter: g.positionQty(symbol, closeSide),
+			Note:          note,
+		})
+		qty -= closeQty
+	}
+
+	if qty <= epsilon {
+		return events, nil
+	}
+	openFn := g.account.Open
+	if maker {
+		openFn = g.account.OpenMaker
+	}
+	pos, fee, execPrice, err := openFn(symbol, openSide, qty, g.cfg.Leverage, price, ts)
+	if err != nil {
+		return events, err
+	}
+	events = append(events, TradeEvent{
+		Timestamp:     ts,
+		Symbol:        symbol,
+		Action:        "open_" + openSide,
+		Side:          openSide,
+		Quantity:      qty,
+		Price:         execPrice,
+		Fee:           fee,
+		OrderValue:    execPrice * qty,
+		Leverage:      pos.Leverage,
+		Cycle:         cycle,
+		PositionAfter: pos.Quantity,
+		Note:          note,
+	})
+	return events, nil
+}
+
+func (g *gridSimulator) positionQty(symbol, side string) float64 {
+	if pos, ok := g.account.positions[positionKey(symbol, side)]; ok {
+		return pos.Quantity
+	}
+	return 0
+}
+
+// closeAll market-closes the grid inventory with taker fees and slippage.
+func (g *gridSimulator) closeAll(symbol string, price float64, ts int64, cycle int, note string) []TradeEvent {
+	var events []TradeEvent
+	if qty := g.positionQty(symbol, "long"); qty > epsilon {
+		evts, _ := g.execute(symbol, "sell", qty, price, ts, cycle, false, note)
+		events = append(events, evts...)
+	}
+	if qty := g.positionQty(symbol, "short"); qty > epsilon {
+		evts, _ := g.execute(symbol, "buy", qty, price, ts, cycle, false, note)
+		events = append(events, evts...)
+	}
+	g.state.InventoryQty, g.state.InventoryEntry = 0, 0
+	return events
+}
+
+// evaluate runs the RunGridCycle risk checks at bar close: range breakout, max drawdown,
+// daily loss limit, box breakout and false-breakout recovery. Returns any forced trades and notes.
+func (g *gridSimulator) evaluate(symbol string, price, equity float64, box *market.BoxData, ts int64, cycle int) ([]TradeEvent, []string) {
+	if g.state.Stopped {
+		return nil, nil
+	}
+	cfg := g.cfg
+	var (
+		events []TradeEvent
+		notes  []string
+	)
+
+	// Range breakout: trailing grids follow the trend (trailGrid), others pause and cancel
+	// beyond 2% outside the grid (handleBreakout)
+	breakoutPct := 0.0
+	if price > g.state.UpperPrice && g.state.UpperPrice > 0 {
+		breakoutPct = (price - g.state.UpperPrice) / g.state.UpperPrice * 100
+	} else if price < g.state.LowerPrice && g.state.LowerPrice > 0 {
+		breakoutPct = (g.state.LowerPrice - price) / g.state.LowerPrice * 100
+	}
+	if breakoutPct > 0 && g.trail(symbol, price, ts, cycle, &events, &notes) {
+		breakoutPct = 0
+	}
+	if breakoutPct >= 2.0 && !g.state.IsPaused {
+		g.cancelAll()
+		g.state.IsPaused = true
+		notes = append(notes, fmt.Sprintf("grid paused: price %.2f%% outside range", breakoutPct))
+		return events, notes
+	}
+
+	// Max drawdown: emergency exit (checkMaxDrawdown)
+	if equity > g.state.PeakEquity {
+		g.state.PeakEquity = equity
+	}
+	if cfg.MaxDrawdownPct > 0 && g.state.PeakEquity > 0 {
+		drawdown := (g.state.PeakEquity - equity) / g.state.PeakEquity * 100
+		if drawdown >= cfg.MaxDrawdownPct {
+			g.cancelAll()
+			events = append(events, g.closeAll(symbol, price, ts, cycle, "grid emergency exit")...)
+			g.state.IsPaused = true
+			g.state.Stopped = true
+			notes = append(notes, fmt.Sprintf("grid emergency exit: max drawdown exceeded: %.2f%%", drawdown))
+			return events, notes
+		}
+	}
+
+	// Daily loss limit (checkDailyLossLimit), reset on each UTC day
+	day := time.UnixMilli(ts).UTC().Format("2006-01-02")
+	if day != g.state.DailyPnLDay {
+		g.state.DailyPnLDay = day
+		g.state.DailyPnL = 0
+	}
+	if cfg.DailyLossLimitPct > 0 && cfg.TotalInvestment > 0 && g.state.DailyPnL < 0 {
+		if lossPct := -g.state.DailyPnL / cfg.TotalInvestment * 100; lossPct >= cfg.DailyLossLimitPct && !g.state.IsPaused {
+			g.cancelAll()
+			g.state.IsPaused = true
+			notes = append(notes, fmt.Sprintf("grid paused: daily loss limit exceeded: %.2f%%", lossPct))
+			return events, notes
+		}
+	}
+
+	if box == nil {
+		return events, notes
+	}
+
+	// Multi-period box breakout (checkBoxBreakout)
+	breakout := &strategy.BreakoutState{
+		Level:        market.BreakoutLevel(g.state.BreakoutLevel),
+		Direction:    g.state.BreakoutDirection,
+		ConfirmCount: g.state.BreakoutConfirmCount,
+	}
+	action, newDirection := strategy.EvaluateBoxBreakout(box, breakout, g.state.Direction, cfg.EnableDirectionAdjust)
+	g.state.BreakoutLevel = string(breakout.Level)
+	g.state.BreakoutDirection = breakout.Direction
+	g.state.BreakoutConfirmCount = breakout.ConfirmCount
+
+	switch action {
+	case strategy.BreakoutActionReducePosition:
+		g.state.PositionReductionPct = 50
+	case strategy.BreakoutActionPauseGrid:
+		if !g.state.IsPaused {
+			g.cancelAll()
+			g.state.IsPaused = true
+			notes = append(notes, "grid paused: mid box breakout")
+		}
+	case strategy.BreakoutActionCloseAll:
+		g.cancelAll()
+		events = append(events, g.closeAll(symbol, price, ts, cycle, "long box breakout")...)
+		g.state.IsPaused = true
+		notes = append(notes, "grid paused: long box breakout, positions closed")
+	case strategy.BreakoutActionAdjustDirection:
+		g.setDirection(newDirection, price, &notes)
+	}
+
+	// False breakout recovery (checkFalseBreakoutRecovery)
+	needsRecoveryCheck := g.state.BreakoutLevel != string(market.BreakoutNone) ||
+		g.state.PositionReductionPct != 0 ||
+		g.state.IsPaused ||
+		(cfg.EnableDirectionAdjust && g.state.Direction != market.GridDirectionNeutral)
+	if !needsRecoveryCheck {
+		return events, notes
+	}
+	if box.CurrentPrice >= box.LongLower && box.CurrentPrice <= box.LongUpper {
+		g.state.BreakoutLevel = string(market.BreakoutNone)
+		g.state.BreakoutDirection = ""
+		g.state.BreakoutConfirmCount = 0
+		g.state.PositionReductionPct = 50 // Recover at 50%
+		if g.state.IsPaused {
+			g.state.IsPaused = false
+			g.arm(price)
+			notes = append(notes, "grid resumed: price back inside box")
+		}
+	}
+	if cfg.EnableDirectionAdjust {
+		g.setDirection(strategy.RecoverGridDirection(box, g.state.Direction), price, &notes)
+	}
+
+	return events, notes
+}
+
+// trail re-centres a trailing grid once price is the trigger distance outside it, as trailGrid does.
+// Returns false when the grid doesn't trail this breakout and the pause rule applies.
+func (g *gridSimulator) trail(symbol string, price float64, ts int64, cycle int, events *[]TradeEvent, notes *[]string) bool {
+	cfg := g.cfg
+	switch {
+	case cfg.TrailingMode == strategy.GridTrailingBoth:
+	case cfg.TrailingMode == strategy.GridTrailingInfinity && price > g.state.UpperPrice:
+	default:
+		return false
+	}
+
+	shift := strategy.GridTrailShift(cfg, g.state.LowerPrice, g.state.UpperPrice, g.state.GridSpacing, price)
+	if shift == 0 {
+		return true
+	}
+	trailed, dropped := strategy.TrailGridLevels(g.state.Levels, shift, g.state.LowerPrice, g.state.GridSpacing, price, nil)
+	g.state.Levels = trailed
+	g.state.LowerPrice += float64(shift) * g.state.GridSpacing
+	g.state.UpperPrice = g.state.LowerPrice + g.state.GridSpacing*float64(len(trailed)-1)
+	g.state.TrailCount++
+
+	// Levels left behind give up their orders; their positions are closed, except that an
+	// infinity grid keeps long inventory as its base position
+	for _, level := range dropped {
+		if level.State != "filled" || level.PositionSize <= epsilon {
+			continue
+		}
+		if level.Side == "buy" && cfg.TrailingMode == strategy.GridTrailingInfinity {
+			cost := g.state.InventoryQty*g.state.InventoryEntry + level.PositionSize*level.PositionEntry
+			g.state.InventoryQty += level.PositionSize
+			g.state.InventoryEntry = cost / g.state.InventoryQty
+			continue
+		}
+		side := "sell"
+		if level.Side == "sell" {
+			side = "buy"
+		}
+		evts, _ := g.execute(symbol, side, level.PositionSize, price, ts, cycle, false, fmt.Sprintf("grid trailed past level %d", level.Index))
+		*events = append(*events, evts...)
+	}
+
+	if cfg.EnableDirectionAdjust {
+		strategy.ApplyGridDirection(g.state.Levels, g.state.Direction, cfg.DirectionBiasRatio, price)
+	}
+	if !g.state.IsPaused {
+		for _, i := range strategy.GridLevelsToArm(g.state.Levels, price) {
+			g.state.Levels[i].State = "pending"
+			g.state.Levels[i].OrderQuantity = g.levelQuantity(g.state.Levels[i])
+		}
+	}
+	*notes = append(*notes, fmt.Sprintf("grid trailed %d levels: %.4f - %.4f", shift, g.state.LowerPrice, g.state.UpperPrice))
+	return true
+}
+
+// setDirection reassigns level sides for a new direction and re-arms the book around price.
+func (g *gridSimulator) setDirection(direction market.GridDirection, price float64, notes *[]string) {
+	if direction == g.state.Direction {
+		return
+	}
+	*notes = append(*notes, fmt.Sprintf("grid direction %s → %s", g.state.Direction, direction))
+	g.state.Direction = direction
+	g.cancelAll()
+	strategy.ApplyGridDirection(g.state.Levels, direction, g.cfg.DirectionBiasRatio, price)
+	if !g.state.IsPaused {
+		g.arm(price)
+	}
+}
+
+// stepGrid advances the grid by one bar: fills against the bar range, then the close-of-bar checks.
+func (r *Runner) stepGrid(ts int64, marketData map[string]*market.Data, priceMap map[string]float64, cycle int) ([]TradeEvent, []string, error) {
+	symbol := r.cfg.GridConfig.Symbol
+	price := priceMap[symbol]
+	if price <= 0 {
+		return nil, nil, fmt.Errorf("price unavailable for %s", symbol)
+	}
+
+	if !r.grid.initialized() {
+		atr := 0.0
+		if data := marketData[symbol]; data != nil && data.LongerTermContext != nil {
+			atr = data.LongerTermContext.ATR14
+		}
+		r.grid.initialize(price, atr)
+		note := fmt.Sprintf("grid initialized: %d levels, %.4f - %.4f", len(r.grid.state.Levels), r.grid.state.LowerPrice, r.grid.state.UpperPrice)
+		return nil, []string{note}, nil
+	}
+
+	var events []TradeEvent
+	if bar, _ := r.feed.decisionBarSnapshot(symbol, ts); bar != nil {
+		events = r.grid.fillBar(symbol, *bar, ts, cycle)
+	}
+
+	equity, _, _ := r.account.TotalEquity(priceMap)
+	forced, notes := r.grid.evaluate(symbol, price, equity, r.gridBox(symbol, ts, price), ts, cycle)
+	return append(events, forced...), notes, nil
+}
+
+// gridBox builds the multi-period box from 1h bars when loaded, else the decision timeframe.
+// The bar closing at ts is left out so its close can land outside the prior channel.
+func (r *Runner) gridBox(symbol string, ts int64, price float64) *market.BoxData {
+	klines := r.feed.sliceUpTo(symbol, "1h", ts)
+	if len(klines) == 0 {
+		klines = r.feed.sliceUpTo(symbol, r.cfg.DecisionTimeframe, ts)
+	}
+	if len(klines) < 2 {
+		return nil
+	}
+	return market.ExportCalculateBoxData(klines[:len(klines)-1], price)
+}
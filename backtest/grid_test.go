@@ -0,0 +1,148 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+
+	"nofx/market"
+	"nofx/store"
+	"nofx/trader/strategy"
+)
+
+// testGridConfig is a five-level 90-110 grid with 200 USDT of margin per level at 1x.
+func testGridConfig() *store.GridStrategyConfig {
+	return &store.GridStrategyConfig{
+		Symbol:          "BTCUSDT",
+		GridCount:       5,
+		TotalInvestment: 1000,
+		Leverage:        1,
+		UpperPrice:      110,
+		LowerPrice:      90,
+		Distribution:    "uniform",
+		MaxDrawdownPct:  20,
+	}
+}
+
+func TestGridSimulatorRoundTrip(t *testing.T) {
+	g := newGridSimulator(testGridConfig(), NewBacktestAccount(10_000, 0, 0))
+	g.initialize(100, 0)
+
+	// Buys below price, sells above, the level at price left as the gap
+	wantStates := []string{"pending", "pending", "empty", "pending", "pending"}
+	for i, level := range g.state.Levels {
+		if level.State != wantStates[i] {
+			t.Fatalf("Level %d (%.0f): state %s, want %s", i, level.Price, level.State, wantStates[i])
+		}
+	}
+
+	// A dip to 94 fills the 95 buy and re-arms a sell on the gap level above it
+	events := g.fillBar("BTCUSDT", market.Kline{Open: 100, High: 100, Low: 94, Close: 96}, 1, 1)
+	if len(events) != 1 || events[0].Action != "open_long" || events[0].Price != 95 {
+		t.Fatalf("Expected one long opened at 95, got %+v", events)
+	}
+	buyQty := events[0].Quantity
+	if math.Abs(buyQty-200.0/95) > 1e-9 {
+		t.Errorf("Expected 200 USDT of margin at 95, got quantity %.6f", buyQty)
+	}
+	if level := g.state.Levels[2]; level.State != "pending" || level.Side != "sell" || level.OrderQuantity != buyQty {
+		t.Fatalf("Expected a sell re-armed at 100 for the filled quantity, got %+v", level)
+	}
+
+	// The bounce to 101 sells it back one spacing higher
+	events = g.fillBar("BTCUSDT", market.Kline{Open: 96, High: 101, Low: 96, Close: 101}, 2, 2)
+	if len(events) != 1 || events[0].Action != "close_long" || events[0].Price != 100 {
+		t.Fatalf("Expected the long closed at 100, got %+v", events)
+	}
+	if math.Abs(events[0].RealizedPnL-buyQty*5) > 1e-9 {
+		t.Errorf("Expected one spacing of profit %.4f, got %.4f", buyQty*5, events[0].RealizedPnL)
+	}
+	if g.positionQty("BTCUSDT", "long") > epsilon {
+		t.Errorf("Round trip should leave the grid flat")
+	}
+}
+
+func TestGridSimulatorFillOrder(t *testing.T) {
+	g := newGridSimulator(testGridConfig(), NewBacktestAccount(10_000, 0, 0))
+	g.initialize(100, 0)
+
+	// A down bar trading the whole range fills the sells first, then the buys from the top down.
+	// Buys net against the shorts the sells opened; the 90 buy flips the remainder long
+	events := g.fillBar("BTCUSDT", market.Kline{Open: 100, High: 111, Low: 89, Close: 95}, 1, 1)
+	want := []struct {
+		action string
+		price  float64
+	}{
+		{"open_short", 105}, {"open_short", 110}, {"close_short", 95}, {"close_short", 90}, {"open_long", 90},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d fills, got %+v", len(want), events)
+	}
+	for i, w := range want {
+		if events[i].Action != w.action || events[i].Price != w.price {
+			t.Errorf("Fill %d: got %s @ %.0f, want %s @ %.0f", i, events[i].Action, events[i].Price, w.action, w.price)
+		}
+	}
+}
+
+func TestGridSimulatorEvaluate(t *testing.T) {
+	t.Run("pauses outside the range", func(t *testing.T) {
+		g := newGridSimulator(testGridConfig(), NewBacktestAccount(10_000, 0, 0))
+		g.initialize(100, 0)
+		_, notes := g.evaluate("BTCUSDT", 113, 10_000, nil, 1, 1)
+		if !g.state.IsPaused || len(notes) != 1 {
+			t.Fatalf("Expected the grid paused 2.7%% above its range, got paused=%v notes=%v", g.state.IsPaused, notes)
+		}
+		for i, level := range g.state.Levels {
+			if level.State == "pending" {
+				t.Errorf("Level %d still has a resting order after the pause", i)
+			}
+		}
+		if events := g.fillBar("BTCUSDT", market.Kline{Open: 100, High: 100, Low: 89, Close: 90}, 2, 2); len(events) != 0 {
+			t.Errorf("A paused grid must not fill, got %+v", events)
+		}
+	})
+
+	t.Run("max drawdown closes inventory and stops", func(t *testing.T) {
+		g := newGridSimulator(testGridConfig(), NewBacktestAccount(10_000, 0, 0))
+		g.initialize(100, 0)
+		g.fillBar("BTCUSDT", market.Kline{Open: 100, High: 100, Low: 94, Close: 96}, 1, 1)
+
+		g.evaluate("BTCUSDT", 96, 10_000, nil, 2, 2)
+		events, _ := g.evaluate("BTCUSDT", 96, 7_900, nil, 3, 3)
+		if !g.state.Stopped || len(events) != 1 || events[0].Action != "close_long" {
+			t.Fatalf("Expected an emergency close after a 21%% drawdown, got stopped=%v events=%+v", g.state.Stopped, events)
+		}
+		g.state.IsPaused = false
+		if events, _ := g.evaluate("BTCUSDT", 100, 10_000, nil, 4, 4); len(events) != 0 || !g.state.Stopped {
+			t.Errorf("A stopped grid never resumes")
+		}
+	})
+
+	t.Run("trailing grid re-centres instead of pausing", func(t *testing.T) {
+		cfg := testGridConfig()
+		cfg.TrailingMode = strategy.GridTrailingBoth
+		g := newGridSimulator(cfg, NewBacktestAccount(10_000, 0, 0))
+		g.initialize(100, 0)
+
+		_, notes := g.evaluate("BTCUSDT", 116, 10_000, nil, 1, 1)
+		if g.state.IsPaused || g.state.TrailCount != 1 || len(notes) != 1 {
+			t.Fatalf("Expected one trail and no pause, got paused=%v trails=%d notes=%v", g.state.IsPaused, g.state.TrailCount, notes)
+		}
+		if g.state.LowerPrice <= 90 || g.state.UpperPrice < 116 {
+			t.Errorf("Expected the range moved up around 116, got %.2f - %.2f", g.state.LowerPrice, g.state.UpperPrice)
+		}
+	})
+}
+
+func TestGridSimulatorSnapshot(t *testing.T) {
+	g := newGridSimulator(testGridConfig(), NewBacktestAccount(10_000, 0, 0))
+	g.initialize(100, 0)
+	snap := g.snapshot()
+	g.state.Levels[0].State = "filled" // Must not leak into the snapshot
+
+	restored := newGridSimulator(testGridConfig(), NewBacktestAccount(10_000, 0, 0))
+	restored.restore(snap)
+	if !restored.initialized() || restored.state.Levels[0].State != "pending" || restored.state.GridSpacing != 5 {
+		t.Errorf("Restored grid doesn't match the snapshot: %+v", restored.state)
+	}
+}
@@ -0,0 +1,137 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"nofx/market"
+	"nofx/provider/alpaca"
+	"nofx/provider/twelvedata"
+)
+
+// historyTimeout bounds one symbol/timeframe download from a stock or forex provider, including pagination.
+const historyTimeout = 2 * time.Minute
+
+// assetDefault holds the cost and leverage defaults of a non-crypto asset class, applied when the run leaves them unset.
+type assetDefault struct {
+	feeBps      float64
+	slippageBps float64
+	leverage    int
+}
+
+var assetDefaults = map[string]assetDefault{
+	market.AssetStock: {feeBps: 0.5, slippageBps: 2, leverage: 4}, // Commission-free, SEC/FINRA fees only; Reg T intraday buying power
+	market.AssetForex: {feeBps: 0, slippageBps: 1, leverage: 30},  // Cost is the spread
+	market.AssetMetal: {feeBps: 0, slippageBps: 3, leverage: 20},
+}
+
+// validateHistoryTimeframe checks the asset class's history source serves tf bars as-is.
+func validateHistoryTimeframe(class, tf string) error {
+	var err error
+	switch class {
+	case market.AssetStock:
+		_, err = alpaca.RangeTimeframe(tf)
+	case market.AssetForex, market.AssetMetal:
+		_, err = twelvedata.RangeInterval(tf)
+	}
+	return err
+}
+
+// fetchKlines loads klines from the asset class's history source: Binance futures for crypto,
+// Alpaca for US stocks and Twelve Data for forex and metals.
+func fetchKlines(class, symbol, tf string, start, end time.Time) ([]market.Kline, error) {
+	switch class {
+	case market.AssetStock:
+		return fetchAlpacaKlines(symbol, tf, start, end)
+	case market.AssetForex, market.AssetMetal:
+		return fetchTwelveDataKlines(symbol, tf, start, end)
+	default:
+		return market.GetKlinesRange(symbol, tf, start, end)
+	}
+}
+
+func fetchAlpacaKlines(symbol, tf string, start, end time.Time) ([]market.Kline, error) {
+	timeframe, err := alpaca.RangeTimeframe(tf)
+	if err != nil {
+		return nil, err
+	}
+	dur, err := market.TFDuration(tf)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+	defer cancel()
+	bars, err := alpaca.NewClient().GetBarsRange(ctx, symbol, timeframe, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	klines := make([]market.Kline, 0, len(bars))
+	for _, bar := range bars {
+		openTime := bar.Timestamp.UnixMilli()
+		klines = append(klines, market.Kline{
+			OpenTime:    openTime,
+			Open:        bar.Open,
+			High:        bar.High,
+			Low:         bar.Low,
+			Close:       bar.Close,
+			Volume:      float64(bar.Volume),
+			QuoteVolume: float64(bar.Volume) * bar.VWAP,
+			Trades:      int(bar.TradeCount),
+			CloseTime:   openTime + dur.Milliseconds() - 1,
+		})
+	}
+	return klines, nil
+}
+
+func fetchTwelveDataKlines(symbol, tf string, start, end time.Time) ([]market.Kline, error) {
+	interval, err := twelvedata.RangeInterval(tf)
+	if err != nil {
+		return nil, err
+	}
+	dur, err := market.TFDuration(tf)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+	defer cancel()
+	bars, err := twelvedata.NewClient().GetTimeSeriesRange(ctx, symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	klines := make([]market.Kline, 0, len(bars))
+	for _, bar := range bars {
+		open, high, low, closePrice, volume, openTime, err := twelvedata.ParseBar(bar)
+		if err != nil {
+			return nil, fmt.Errorf("%s bar %s: %w", symbol, bar.Datetime, err)
+		}
+		klines = append(klines, market.Kline{
+			OpenTime:  openTime,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     closePrice,
+			Volume:    volume,
+			CloseTime: openTime + dur.Milliseconds() - 1,
+		})
+	}
+	return klines, nil
+}
+
+// warmupSpan is how far before the run to fetch so 200 session bars are available for indicators.
+// Session-bound assets need extra calendar time to cover nights, weekends and holidays.
+func warmupSpan(class string, dur time.Duration) time.Duration {
+	span := dur * 200
+	switch class {
+	case market.AssetStock:
+		if dur < 24*time.Hour {
+			return span * 6 // 6.5 of 24 hours on 5 of 7 days, plus holidays
+		}
+		return span * 3 / 2
+	case market.AssetForex, market.AssetMetal:
+		return span * 3 / 2
+	}
+	return span
+}
@@ -0,0 +1,367 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/store"
+)
+
+// LiveReplayConfig replays a live trader's recorded decisions instead of asking the AI.
+// Leverage caps come from BacktestConfig.Leverage and the fee tier from FeeBps, as in any run.
+type LiveReplayConfig struct {
+	TraderID      string  `json:"trader_id"`
+	MinConfidence int     `json:"min_confidence,omitempty"`  // Skip opens below this confidence (0 = replay all)
+	StopLossPct   float64 `json:"stop_loss_pct,omitempty"`   // Replace the AI stop with a fixed distance from entry (0 = keep AI stop)
+	TakeProfitPct float64 `json:"take_profit_pct,omitempty"` // Replace the AI target with a fixed distance from entry (0 = keep AI target)
+	IgnoreAIStops bool    `json:"ignore_ai_stops,omitempty"` // Drop the AI stop/target entirely unless a pct override is set
+	IncludeFailed bool    `json:"include_failed,omitempty"`  // Also replay decisions whose live order failed
+}
+
+func (lc *LiveReplayConfig) validate() error {
+	lc.TraderID = strings.TrimSpace(lc.TraderID)
+	if lc.TraderID == "" {
+		return fmt.Errorf("live_replay.trader_id is required")
+	}
+	if lc.MinConfidence < 0 || lc.MinConfidence > 100 {
+		return fmt.Errorf("live_replay.min_confidence must be between 0 and 100")
+	}
+	if lc.StopLossPct < 0 || lc.StopLossPct >= 100 {
+		return fmt.Errorf("live_replay.stop_loss_pct must be between 0 and 100")
+	}
+	if lc.TakeProfitPct < 0 {
+		return fmt.Errorf("live_replay.take_profit_pct cannot be negative")
+	}
+	return nil
+}
+
+// LiveDecision is one live cycle: what the AI decided and what the exchange actually filled.
+type LiveDecision struct {
+	Timestamp int64                  `json:"timestamp"` // ms
+	Cycle     int                    `json:"cycle"`
+	Decisions []kernel.Decision      `json:"decisions"`
+	Actions   []store.DecisionAction `json:"actions"`
+}
+
+// LiveDecisionLoader fetches the recorded live cycles for a live replay run.
+type LiveDecisionLoader func(cfg *BacktestConfig) ([]LiveDecision, error)
+
+// LiveDecisionsFromRecords converts decision records into replayable cycles.
+// Records whose DecisionJSON cannot be parsed fall back to the executed actions.
+func LiveDecisionsFromRecords(records []*store.DecisionRecord) []LiveDecision {
+	cycles := make([]LiveDecision, 0, len(records))
+	for _, rec := range records {
+		if rec == nil {
+			continue
+		}
+		var decisions []kernel.Decision
+		if strings.TrimSpace(rec.DecisionJSON) != "" {
+			if err := json.Unmarshal([]byte(rec.DecisionJSON), &decisions); err != nil {
+				decisions = nil
+			}
+		}
+		if decisions == nil {
+			for _, act := range rec.Decisions {
+				decisions = append(decisions, kernel.Decision{
+					Symbol:     act.Symbol,
+					Action:     act.Action,
+					Leverage:   act.Leverage,
+					StopLoss:   act.StopLoss,
+					TakeProfit: act.TakeProfit,
+					Confidence: act.Confidence,
+					Reasoning:  act.Reasoning,
+				})
+			}
+		}
+		if len(decisions) == 0 {
+			continue
+		}
+		for i := range decisions {
+			decisions[i].Symbol = market.Normalize(decisions[i].Symbol)
+		}
+		cycles = append(cycles, LiveDecision{
+			Timestamp: rec.Timestamp.UnixMilli(),
+			Cycle:     rec.CycleNumber,
+			Decisions: decisions,
+			Actions:   rec.Decisions,
+		})
+	}
+	sort.SliceStable(cycles, func(i, j int) bool { return cycles[i].Timestamp < cycles[j].Timestamp })
+	return cycles
+}
+
+// LiveReplayStats reports what the replay did differently from the live trader.
+type LiveReplayStats struct {
+	LiveCycles           int     `json:"live_cycles"`
+	Replayed             int     `json:"replayed"`
+	SkippedLowConfidence int     `json:"skipped_low_confidence"`
+	SkippedFailedLive    int     `json:"skipped_failed_live"`
+	StopLossHits         int     `json:"stop_loss_hits"`
+	TakeProfitHits       int     `json:"take_profit_hits"`
+	FillGapSamples       int     `json:"fill_gap_samples"`
+	AvgFillGapBps        float64 `json:"avg_fill_gap_bps"` // Mean |simulated - live| fill price
+	MaxFillGapBps        float64 `json:"max_fill_gap_bps"`
+}
+
+// LiveReplayStop is the stop and target attached to one replayed position.
+type LiveReplayStop struct {
+	StopLoss   float64 `json:"stop_loss,omitempty"`
+	TakeProfit float64 `json:"take_profit,omitempty"`
+}
+
+// LiveReplaySnapshot is the replay cursor, carried in checkpoints so resumed runs continue where they stopped.
+type LiveReplaySnapshot struct {
+	Next   int                       `json:"next"` // Index of the next live cycle to apply
+	Stops  map[string]LiveReplayStop `json:"stops,omitempty"`
+	Stats  LiveReplayStats           `json:"stats"`
+	GapSum float64                   `json:"gap_sum"`
+}
+
+// liveReplaySimulator feeds recorded live decisions through the backtest account in timestamp order.
+type liveReplaySimulator struct {
+	cfg    *LiveReplayConfig
+	cycles []LiveDecision
+	state  LiveReplaySnapshot
+}
+
+func newLiveReplaySimulator(cfg *LiveReplayConfig) *liveReplaySimulator {
+	return &liveReplaySimulator{
+		cfg:   cfg,
+		state: LiveReplaySnapshot{Stops: make(map[string]LiveReplayStop)},
+	}
+}
+
+func (l *liveReplaySimulator) setCycles(cycles []LiveDecision) {
+	l.cycles = cycles
+	l.state.Stats.LiveCycles = len(cycles)
+}
+
+// snapshot returns a copy of the replay state for checkpointing.
+func (l *liveReplaySimulator) snapshot() *LiveReplaySnapshot {
+	snap := l.state
+	snap.Stops = make(map[string]LiveReplayStop, len(l.state.Stops))
+	for k, v := range l.state.Stops {
+		snap.Stops[k] = v
+	}
+	return &snap
+}
+
+func (l *liveReplaySimulator) restore(snap *LiveReplaySnapshot) {
+	if snap == nil {
+		return
+	}
+	l.state = *snap
+	if l.state.Stops == nil {
+		l.state.Stops = make(map[string]LiveReplayStop)
+	}
+	l.state.Stats.LiveCycles = len(l.cycles)
+}
+
+func (l *liveReplaySimulator) stats() *LiveReplayStats {
+	stats := l.state.Stats
+	return &stats
+}
+
+// stopsFor picks the stop and target for a new position: pct overrides first, then the AI's own levels.
+func (l *liveReplaySimulator) stopsFor(dec kernel.Decision, side string, entry float64) LiveReplayStop {
+	var stop LiveReplayStop
+	if !l.cfg.IgnoreAIStops {
+		stop.StopLoss, stop.TakeProfit = dec.StopLoss, dec.TakeProfit
+	}
+	sign := 1.0
+	if side == "short" {
+		sign = -1
+	}
+	if l.cfg.StopLossPct > 0 {
+		stop.StopLoss = entry * (1 - sign*l.cfg.StopLossPct/100)
+	}
+	if l.cfg.TakeProfitPct > 0 {
+		stop.TakeProfit = entry * (1 + sign*l.cfg.TakeProfitPct/100)
+	}
+	return stop
+}
+
+// recordFillGap compares a simulated fill with the live fill of the same action.
+func (l *liveReplaySimulator) recordFillGap(simPrice, livePrice float64) {
+	if simPrice <= 0 || livePrice <= 0 {
+		return
+	}
+	gap := math.Abs(simPrice-livePrice) / livePrice * 10000
+	l.state.GapSum += gap
+	l.state.Stats.FillGapSamples++
+	l.state.Stats.AvgFillGapBps = l.state.GapSum / float64(l.state.Stats.FillGapSamples)
+	if gap > l.state.Stats.MaxFillGapBps {
+		l.state.Stats.MaxFillGapBps = gap
+	}
+}
+
+// liveAction finds the live execution matching a decision; ok is false if the live trader never recorded one.
+func liveAction(cycle LiveDecision, dec kernel.Decision) (store.DecisionAction, bool) {
+	for _, act := range cycle.Actions {
+		if market.Normalize(act.Symbol) == dec.Symbol && act.Action == dec.Action {
+			return act, true
+		}
+	}
+	return store.DecisionAction{}, false
+}
+
+// stepLiveReplay checks the replayed stops against the bar, then applies every live cycle recorded up to ts.
+func (r *Runner) stepLiveReplay(ts int64, priceMap map[string]float64, cycle int) ([]TradeEvent, []string) {
+	l := r.replay
+	events, notes := r.checkReplayStops(ts, cycle)
+
+	for l.state.Next < len(l.cycles) && l.cycles[l.state.Next].Timestamp <= ts {
+		live := l.cycles[l.state.Next]
+		l.state.Next++
+		for _, dec := range sortDecisionsByPriority(live.Decisions) {
+			switch dec.Action {
+			case "open_long", "open_short", "close_long", "close_short":
+			default:
+				continue // hold/wait change nothing, and trailing or grid orders are not simulated
+			}
+			act, hasLive := liveAction(live, dec)
+			if hasLive && !act.Success && !l.cfg.IncludeFailed {
+				l.state.Stats.SkippedFailedLive++
+				continue
+			}
+			side := strings.TrimPrefix(strings.TrimPrefix(dec.Action, "open_"), "close_")
+			isOpen := strings.HasPrefix(dec.Action, "open_")
+			if isOpen && l.cfg.MinConfidence > 0 && dec.Confidence < l.cfg.MinConfidence {
+				l.state.Stats.SkippedLowConfidence++
+				continue
+			}
+			if !isOpen && r.remainingPosition(dec.Symbol, side) <= epsilon {
+				continue // Already closed here by a replayed stop or a skipped open
+			}
+			if isOpen && dec.PositionSizeUSD <= 0 && hasLive && act.Quantity > 0 {
+				dec.PositionSizeUSD = act.Quantity * priceMap[dec.Symbol]
+			}
+
+			actionRecord, trades, _, err := r.executeDecision(dec, priceMap, ts, cycle)
+			if err != nil {
+				notes = append(notes, fmt.Sprintf("live cycle %d %s %s not replayed: %v", live.Cycle, dec.Symbol, dec.Action, err))
+				continue
+			}
+			l.state.Stats.Replayed++
+			for i := range trades {
+				trades[i].Note = fmt.Sprintf("live cycle %d", live.Cycle)
+			}
+			events = append(events, trades...)
+			if hasLive && act.Success {
+				l.recordFillGap(actionRecord.Price, act.Price)
+			}
+			if isOpen {
+				if stop := l.stopsFor(dec, side, actionRecord.Price); stop.StopLoss > 0 || stop.TakeProfit > 0 {
+					l.state.Stops[positionKey(dec.Symbol, side)] = stop
+				}
+			}
+		}
+	}
+
+	// Drop stops whose positions are gone (closed by a live decision or liquidated)
+	for key := range l.state.Stops {
+		if _, ok := r.account.positions[key]; !ok {
+			delete(l.state.Stops, key)
+		}
+	}
+	return events, notes
+}
+
+// checkReplayStops closes positions whose stop or target was touched inside the bar closing at ts.
+// The stop wins when both were touched, and a bar that gaps through a level fills at its open.
+func (r *Runner) checkReplayStops(ts int64, cycle int) ([]TradeEvent, []string) {
+	l := r.replay
+	keys := make([]string, 0, len(l.state.Stops))
+	for key := range l.state.Stops {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var events []TradeEvent
+	var notes []string
+	for _, key := range keys {
+		pos, ok := r.account.positions[key]
+		if !ok || pos.Quantity <= epsilon {
+			continue
+		}
+		bar, _ := r.feed.decisionBarSnapshot(pos.Symbol, ts)
+		if bar == nil || bar.OpenTime < pos.OpenTime {
+			continue // Only bars that started after the entry can trigger its stops
+		}
+		stop := l.state.Stops[key]
+		price, reason := replayStopFill(pos.Side, stop, *bar)
+		if reason == "" {
+			continue
+		}
+
+		qty := pos.Quantity
+		lev := pos.Leverage
+		realized, fee, execPrice, err := r.account.Close(pos.Symbol, pos.Side, qty, price)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%s %s %s close failed: %v", pos.Symbol, pos.Side, reason, err))
+			continue
+		}
+		if reason == "stop_loss" {
+			l.state.Stats.StopLossHits++
+		} else {
+			l.state.Stats.TakeProfitHits++
+		}
+		delete(l.state.Stops, key)
+		events = append(events, TradeEvent{
+			Timestamp:     ts,
+			Symbol:        pos.Symbol,
+			Action:        "close_" + pos.Side,
+			Side:          pos.Side,
+			Quantity:      qty,
+			Price:         execPrice,
+			Fee:           fee,
+			OrderValue:    execPrice * qty,
+			RealizedPnL:   realized - fee,
+			Leverage:      lev,
+			Cycle:         cycle,
+			PositionAfter: r.remainingPosition(pos.Symbol, pos.Side),
+			Note:          "replay " + reason,
+		})
+		notes = append(notes, fmt.Sprintf("%s %s closed by replayed %s at %.4f", pos.Symbol, pos.Side, reason, execPrice))
+	}
+	return events, notes
+}
+
+// replayStopFill returns the fill price and reason if the bar touched the stop or target.
+func replayStopFill(side string, stop LiveReplayStop, bar market.Kline) (float64, string) {
+	if side == "long" {
+		if stop.StopLoss > 0 && bar.Low <= stop.StopLoss {
+			return math.Min(stop.StopLoss, bar.Open), "stop_loss"
+		}
+		if stop.TakeProfit > 0 && bar.High >= stop.TakeProfit {
+			return math.Max(stop.TakeProfit, bar.Open), "take_profit"
+		}
+		return 0, ""
+	}
+	if stop.StopLoss > 0 && bar.High >= stop.StopLoss {
+		return math.Max(stop.StopLoss, bar.Open), "stop_loss"
+	}
+	if stop.TakeProfit > 0 && bar.Low <= stop.TakeProfit {
+		return math.Min(stop.TakeProfit, bar.Open), "take_profit"
+	}
+	return 0, ""
+}
+
+func (r *Runner) liveReplaySnapshot() *LiveReplaySnapshot {
+	if r.replay == nil {
+		return nil
+	}
+	return r.replay.snapshot()
+}
+
+// SetLiveDecisions hands the recorded live cycles to a live replay runner.
+func (r *Runner) SetLiveDecisions(cycles []LiveDecision) {
+	if r.replay != nil {
+		r.replay.setCycles(cycles)
+	}
+}
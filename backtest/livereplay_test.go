@@ -0,0 +1,129 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/store"
+)
+
+func TestStepLiveReplay(t *testing.T) {
+	bars := testBars(
+		[4]float64{100, 100, 100, 100}, // Live cycle fires at this close
+		[4]float64{100, 102, 99, 101},  // Above the 5% stop at 95
+		[4]float64{101, 103, 94, 96},   // Touches the stop
+	)
+	r := &Runner{
+		cfg:     BacktestConfig{Leverage: LeverageConfig{BTCETHLeverage: 3, AltcoinLeverage: 2}},
+		feed:    testFeed("1m", map[string][]market.Kline{"BTCUSDT": bars}),
+		account: NewBacktestAccount(10_000, 0, 0),
+		state:   &BacktestState{},
+		replay:  newLiveReplaySimulator(&LiveReplayConfig{TraderID: "live", MinConfidence: 70, StopLossPct: 5}),
+	}
+	r.SetLiveDecisions([]LiveDecision{{
+		Timestamp: bars[0].CloseTime,
+		Cycle:     7,
+		Decisions: []kernel.Decision{
+			{Symbol: "BTCUSDT", Action: "open_long", Leverage: 20, StopLoss: 90, Confidence: 80},
+			{Symbol: "ETHUSDT", Action: "open_short", Confidence: 50},
+			{Symbol: "SOLUSDT", Action: "close_long", Confidence: 90},
+		},
+		Actions: []store.DecisionAction{
+			{Symbol: "BTCUSDT", Action: "open_long", Quantity: 2, Price: 99.5, Success: true},
+			{Symbol: "SOLUSDT", Action: "close_long", Success: false},
+		},
+	}})
+	priceMap := map[string]float64{"BTCUSDT": 100}
+
+	events, notes := r.stepLiveReplay(bars[0].CloseTime, priceMap, 1)
+	if len(events) != 1 || events[0].Action != "open_long" || events[0].Note != "live cycle 7" {
+		t.Fatalf("Expected only the confident BTC open to replay, got %+v (notes %v)", events, notes)
+	}
+	if events[0].Leverage != 3 {
+		t.Errorf("Expected the AI's 20x to be capped at the configured 3x, got %dx", events[0].Leverage)
+	}
+	if math.Abs(events[0].Quantity-2) > 1e-9 {
+		t.Errorf("Expected the live quantity 2 to size the replayed open, got %.4f", events[0].Quantity)
+	}
+	if stop := r.replay.state.Stops[positionKey("BTCUSDT", "long")]; math.Abs(stop.StopLoss-95) > 1e-9 {
+		t.Errorf("Expected the 5%% override to replace the AI stop at 90, got %+v", stop)
+	}
+
+	stats := r.replay.stats()
+	if stats.Replayed != 1 || stats.SkippedLowConfidence != 1 || stats.SkippedFailedLive != 1 {
+		t.Errorf("Unexpected replay counts %+v", stats)
+	}
+	// Simulated 100 against the live 99.5
+	wantGap := 0.5 / 99.5 * 10000
+	if stats.FillGapSamples != 1 || math.Abs(stats.AvgFillGapBps-wantGap) > 1e-9 || math.Abs(stats.MaxFillGapBps-wantGap) > 1e-9 {
+		t.Errorf("Expected one fill gap of %.2f bps, got %+v", wantGap, stats)
+	}
+
+	if events, _ := r.stepLiveReplay(bars[1].CloseTime, map[string]float64{"BTCUSDT": 101}, 2); len(events) != 0 {
+		t.Fatalf("Bar 1 stayed above the stop, got %+v", events)
+	}
+	events, _ = r.stepLiveReplay(bars[2].CloseTime, map[string]float64{"BTCUSDT": 96}, 3)
+	if len(events) != 1 || events[0].Price != 95 || events[0].Note != "replay stop_loss" {
+		t.Fatalf("Expected the replayed stop to close at 95, got %+v", events)
+	}
+	if r.replay.stats().StopLossHits != 1 || len(r.replay.state.Stops) != 0 {
+		t.Errorf("Expected one stop hit and no stops left, got %+v", r.replay.state)
+	}
+}
+
+func TestLiveReplayStopsFor(t *testing.T) {
+	dec := kernel.Decision{StopLoss: 90, TakeProfit: 120}
+	tests := []struct {
+		name string
+		cfg  LiveReplayConfig
+		side string
+		want LiveReplayStop
+	}{
+		{"AI levels kept", LiveReplayConfig{}, "long", LiveReplayStop{StopLoss: 90, TakeProfit: 120}},
+		{"long pct overrides", LiveReplayConfig{StopLossPct: 2, TakeProfitPct: 4}, "long", LiveReplayStop{StopLoss: 98, TakeProfit: 104}},
+		{"short pct overrides", LiveReplayConfig{StopLossPct: 2, TakeProfitPct: 4}, "short", LiveReplayStop{StopLoss: 102, TakeProfit: 96}},
+		{"only the target overridden", LiveReplayConfig{TakeProfitPct: 10}, "long", LiveReplayStop{StopLoss: 90, TakeProfit: 110}},
+		{"AI stops ignored", LiveReplayConfig{IgnoreAIStops: true}, "long", LiveReplayStop{}},
+		{"AI stops ignored, stop override kept", LiveReplayConfig{IgnoreAIStops: true, StopLossPct: 5}, "short", LiveReplayStop{StopLoss: 105}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			got := newLiveReplaySimulator(&cfg).stopsFor(dec, tt.side, 100)
+			if math.Abs(got.StopLoss-tt.want.StopLoss) > 1e-9 || math.Abs(got.TakeProfit-tt.want.TakeProfit) > 1e-9 {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReplayStopFill(t *testing.T) {
+	stop := LiveReplayStop{StopLoss: 95, TakeProfit: 110}
+	short := LiveReplayStop{StopLoss: 105, TakeProfit: 90}
+	tests := []struct {
+		name   string
+		side   string
+		stop   LiveReplayStop
+		bar    market.Kline
+		price  float64
+		reason string
+	}{
+		{"untouched", "long", stop, market.Kline{Open: 100, High: 105, Low: 96}, 0, ""},
+		{"long stop", "long", stop, market.Kline{Open: 100, High: 101, Low: 94}, 95, "stop_loss"},
+		{"long gap through stop fills at the open", "long", stop, market.Kline{Open: 92, High: 93, Low: 90}, 92, "stop_loss"},
+		{"long target", "long", stop, market.Kline{Open: 108, High: 111, Low: 107}, 110, "take_profit"},
+		{"both touched, stop wins", "long", stop, market.Kline{Open: 100, High: 112, Low: 94}, 95, "stop_loss"},
+		{"short stop", "short", short, market.Kline{Open: 100, High: 106, Low: 99}, 105, "stop_loss"},
+		{"short gap through target fills at the open", "short", short, market.Kline{Open: 88, High: 89, Low: 87}, 88, "take_profit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, reason := replayStopFill(tt.side, tt.stop, tt.bar)
+			if price != tt.price || reason != tt.reason {
+				t.Errorf("Expected %.2f %q, got %.2f %q", tt.price, tt.reason, price, reason)
+			}
+		})
+	}
+}
@@ -15,22 +15,27 @@ import (
 )
 
 type Manager struct {
-	mu         sync.RWMutex
-	runners    map[string]*Runner
-	metadata   map[string]*RunMetadata
-	cancels    map[string]context.CancelFunc
-	mcpClient  mcp.AIClient
-	aiResolver AIConfigResolver
+	mu           sync.RWMutex
+	runners      map[string]*Runner
+	metadata     map[string]*RunMetadata
+	cancels      map[string]context.CancelFunc
+	sweeps       map[string]*sweepRun
+	walkForwards map[string]*walkForwardRun
+	mcpClient    mcp.AIClient
+	aiResolver   AIConfigResolver
+	liveLoader   LiveDecisionLoader
 }
 
 type AIConfigResolver func(*BacktestConfig) error
 
 func NewManager(defaultClient mcp.AIClient) *Manager {
 	return &Manager{
-		runners:   make(map[string]*Runner),
-		metadata:  make(map[string]*RunMetadata),
-		cancels:   make(map[string]context.CancelFunc),
-		mcpClient: defaultClient,
+		runners:      make(map[string]*Runner),
+		metadata:     make(map[string]*RunMetadata),
+		cancels:      make(map[string]context.CancelFunc),
+		sweeps:       make(map[string]*sweepRun),
+		walkForwards: make(map[string]*walkForwardRun),
+		mcpClient:    defaultClient,
 	}
 }
 
@@ -40,6 +45,12 @@ func (m *Manager) SetAIResolver(resolver AIConfigResolver) {
 	m.aiResolver = resolver
 }
 
+func (m *Manager) SetLiveDecisionLoader(loader LiveDecisionLoader) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.liveLoader = loader
+}
+
 func (m *Manager) Start(ctx context.Context, cfg BacktestConfig) (*Runner, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, err
@@ -71,6 +82,10 @@ func (m *Manager) Start(ctx context.Context, cfg BacktestConfig) (*Runner, error
 	if err != nil {
 		return nil, err
 	}
+	if err := m.loadLiveDecisions(runner, &cfg); err != nil {
+		runner.releaseLock()
+		return nil, err
+	}
 
 	runCtx, cancel := context.WithCancel(ctx)
 
@@ -213,6 +228,10 @@ func (m *Manager) Resume(runID string) error {
 	if err != nil {
 		return err
 	}
+	if err := m.loadLiveDecisions(restored, &cfgCopy); err != nil {
+		restored.releaseLock()
+		return err
+	}
 	if err := restored.RestoreFromCheckpoint(); err != nil {
 		return err
 	}
@@ -427,6 +446,9 @@ func (m *Manager) resolveAIConfig(cfg *BacktestConfig) error {
 	if cfg == nil {
 		return fmt.Errorf("ai config missing")
 	}
+	if cfg.IsMechanical() {
+		return nil // Grid, pairs and live replays never call the model
+	}
 	provider := strings.TrimSpace(cfg.AICfg.Provider)
 	apiKey := strings.TrimSpace(cfg.AICfg.APIKey)
 	if provider != "" && !strings.EqualFold(provider, "inherit") && apiKey != "" {
@@ -445,6 +467,28 @@ func (m *Manager) resolveAIConfig(cfg *BacktestConfig) error {
 	return resolver(cfg)
 }
 
+// loadLiveDecisions hands a live replay runner the recorded cycles it replays.
+func (m *Manager) loadLiveDecisions(runner *Runner, cfg *BacktestConfig) error {
+	if !cfg.IsLiveReplay() {
+		return nil
+	}
+	m.mu.RLock()
+	loader := m.liveLoader
+	m.mu.RUnlock()
+	if loader == nil {
+		return fmt.Errorf("live replay requires a decision loader")
+	}
+	cycles, err := loader(cfg)
+	if err != nil {
+		return fmt.Errorf("load live decisions: %w", err)
+	}
+	if len(cycles) == 0 {
+		return fmt.Errorf("trader %s has no recorded decisions in the backtest range", cfg.LiveReplay.TraderID)
+	}
+	runner.SetLiveDecisions(cycles)
+	return nil
+}
+
 func (m *Manager) GetTrace(runID string, cycle int) (*store.DecisionRecord, error) {
 	return LoadDecisionTrace(runID, cycle)
 }
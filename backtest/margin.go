@@ -0,0 +1,391 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"nofx/market"
+	"nofx/trader/types"
+)
+
+// Margin modes
+const (
+	MarginModeIsolated = "isolated" // Each position risks only its own margin; a liquidation closes that position alone
+	MarginModeCross    = "cross"    // All positions share the account balance; a liquidation closes everything and ends the run
+)
+
+const defaultMarginCallRatio = 0.8
+
+// crossMarginCallKey is the margin-call state key used for the whole account in cross mode.
+const crossMarginCallKey = "*"
+
+// Default leverage brackets, modelled on Binance USDT-M perpetuals. Used for symbols without configured or loaded brackets.
+var (
+	defaultMajorBrackets = []types.LeverageBracket{
+		{NotionalCap: 50000, MaxLeverage: 125, MaintMarginRate: 0.004},
+		{NotionalCap: 600000, MaxLeverage: 100, MaintMarginRate: 0.005},
+		{NotionalCap: 3000000, MaxLeverage: 75, MaintMarginRate: 0.0065},
+		{NotionalCap: 12000000, MaxLeverage: 50, MaintMarginRate: 0.01},
+		{NotionalCap: 70000000, MaxLeverage: 25, MaintMarginRate: 0.02},
+		{NotionalCap: 100000000, MaxLeverage: 20, MaintMarginRate: 0.025},
+		{NotionalCap: 230000000, MaxLeverage: 10, MaintMarginRate: 0.05},
+		{NotionalCap: 480000000, MaxLeverage: 5, MaintMarginRate: 0.1},
+	}
+	defaultAltBrackets = []types.LeverageBracket{
+		{NotionalCap: 5000, MaxLeverage: 50, MaintMarginRate: 0.01},
+		{NotionalCap: 25000, MaxLeverage: 20, MaintMarginRate: 0.025},
+		{NotionalCap: 100000, MaxLeverage: 10, MaintMarginRate: 0.05},
+		{NotionalCap: 250000, MaxLeverage: 5, MaintMarginRate: 0.1},
+		{NotionalCap: 1000000, MaxLeverage: 4, MaintMarginRate: 0.125},
+		{NotionalCap: 3000000, MaxLeverage: 2, MaintMarginRate: 0.25},
+		{NotionalCap: 5000000, MaxLeverage: 1, MaintMarginRate: 0.5},
+	}
+)
+
+// MarginConfig replaces the simple entry ± entry/leverage liquidation with exchange-style maintenance margin tiers.
+type MarginConfig struct {
+	Mode            string                             `json:"mode,omitempty"`              // isolated (default) or cross
+	Brackets        map[string][]types.LeverageBracket `json:"brackets,omitempty"`          // Per symbol; symbols without brackets use the default ladder
+	SpecTraderID    string                             `json:"spec_trader_id,omitempty"`    // Load missing brackets from this live trader's exchange contract specs
+	MarginCallRatio float64                            `json:"margin_call_ratio,omitempty"` // Maintenance margin / margin balance that raises a margin call (default 0.8)
+}
+
+func (mc *MarginConfig) validate() error {
+	mc.Mode = strings.ToLower(strings.TrimSpace(mc.Mode))
+	if mc.Mode == "" {
+		mc.Mode = MarginModeIsolated
+	}
+	if mc.Mode != MarginModeIsolated && mc.Mode != MarginModeCross {
+		return fmt.Errorf("unsupported margin mode '%s'", mc.Mode)
+	}
+	if mc.MarginCallRatio < 0 || mc.MarginCallRatio >= 1 {
+		return fmt.Errorf("margin.margin_call_ratio must be between 0 and 1")
+	}
+	if mc.MarginCallRatio == 0 {
+		mc.MarginCallRatio = defaultMarginCallRatio
+	}
+	mc.SpecTraderID = strings.TrimSpace(mc.SpecTraderID)
+
+	normalized := make(map[string][]types.LeverageBracket, len(mc.Brackets))
+	for symbol, brackets := range mc.Brackets {
+		if len(brackets) == 0 {
+			continue
+		}
+		sorted := append([]types.LeverageBracket(nil), brackets...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].NotionalCap < sorted[j].NotionalCap })
+		for _, b := range sorted {
+			if b.NotionalCap <= 0 || b.MaxLeverage <= 0 || b.MaintMarginRate < 0 || b.MaintMarginRate >= 1 {
+				return fmt.Errorf("invalid leverage bracket for %s: cap %.2f, leverage %d, maintenance rate %.4f",
+					symbol, b.NotionalCap, b.MaxLeverage, b.MaintMarginRate)
+			}
+		}
+		normalized[market.Normalize(symbol)] = sorted
+	}
+	mc.Brackets = normalized
+	return nil
+}
+
+// marginModel looks up maintenance margin and leverage caps from the configured brackets.
+type marginModel struct {
+	cfg   *MarginConfig
+	calls map[string]bool // Position keys (or crossMarginCallKey) currently under a margin call
+}
+
+func newMarginModel(cfg *MarginConfig) *marginModel {
+	if cfg == nil {
+		return nil
+	}
+	return &marginModel{cfg: cfg, calls: make(map[string]bool)}
+}
+
+func (m *marginModel) cross() bool {
+	return m.cfg.Mode == MarginModeCross
+}
+
+func (m *marginModel) brackets(symbol string) []types.LeverageBracket {
+	if brackets := m.cfg.Brackets[strings.ToUpper(symbol)]; len(brackets) > 0 {
+		return brackets
+	}
+	if sym := strings.ToUpper(symbol); sym == "BTCUSDT" || sym == "ETHUSDT" {
+		return defaultMajorBrackets
+	}
+	return defaultAltBrackets
+}
+
+// tier returns the bracket covering notional and its maintenance amount, the deduction that makes
+// tiered maintenance margin continuous across bracket boundaries (Binance's "cum").
+func (m *marginModel) tier(symbol string, notional float64) (types.LeverageBracket, float64) {
+	brackets := m.brackets(symbol)
+	cum := 0.0
+	for i, b := range brackets {
+		if i > 0 {
+			prev := brackets[i-1]
+			cum += prev.NotionalCap * (b.MaintMarginRate - prev.MaintMarginRate)
+		}
+		if notional <= b.NotionalCap || i == len(brackets)-1 {
+			return b, cum
+		}
+	}
+	return types.LeverageBracket{}, 0
+}
+
+// maintenance returns the maintenance margin for a position of the given notional.
+func (m *marginModel) maintenance(symbol string, notional float64) float64 {
+	b, cum := m.tier(symbol, notional)
+	return math.Max(0, notional*b.MaintMarginRate-cum)
+}
+
+// maxLeverage is the highest leverage the brackets allow for a position of the given notional.
+func (m *marginModel) maxLeverage(symbol string, notional float64) int {
+	b, _ := m.tier(symbol, notional)
+	return b.MaxLeverage
+}
+
+// liquidationPrice solves for the mark price at which the position's margin balance meets its maintenance margin.
+// walletBalance is the position margin in isolated mode, or the account wallet balance in cross mode, where
+// otherMaint and otherUPnL carry the maintenance margin and unrealized PnL of every other position.
+func (m *marginModel) liquidationPrice(pos *position, walletBalance, otherMaint, otherUPnL float64) float64 {
+	if pos.Quantity <= epsilon {
+		return 0
+	}
+	b, cum := m.tier(pos.Symbol, pos.Quantity*pos.EntryPrice)
+	side := 1.0
+	if pos.Side == "short" {
+		side = -1
+	}
+	qty := pos.Quantity
+	denom := qty*b.MaintMarginRate - side*qty
+	if math.Abs(denom) < epsilon {
+		return 0
+	}
+	price := (walletBalance - otherMaint + otherUPnL + cum - side*qty*pos.EntryPrice) / denom
+	return math.Max(0, price)
+}
+
+// isolatedLiquidation is the liquidation price of a position backed by its own margin only.
+func (m *marginModel) isolatedLiquidation(pos *position) float64 {
+	return m.liquidationPrice(pos, pos.Margin, 0, 0)
+}
+
+// SetMarginModel switches the account from the simple liquidation formula to maintenance margin tiers.
+func (acc *BacktestAccount) SetMarginModel(model *marginModel) {
+	acc.margin = model
+}
+
+// liquidationPrice is the liquidation price set when a position is opened or added to.
+// Cross mode refreshes it every bar against the rest of the account.
+func (acc *BacktestAccount) liquidationPrice(pos *position) float64 {
+	if acc.margin == nil {
+		return computeLiquidation(pos.EntryPrice, pos.Leverage, pos.Side)
+	}
+	return acc.margin.isolatedLiquidation(pos)
+}
+
+// capLeverage lowers leverage to the bracket limit for the resulting notional, as live order sizing does.
+func (acc *BacktestAccount) capLeverage(symbol string, leverage int, notional float64) int {
+	if acc.margin == nil {
+		return leverage
+	}
+	if max := acc.margin.maxLeverage(symbol, notional); max > 0 && leverage > max {
+		return max
+	}
+	return leverage
+}
+
+// marginEvent builds a zero-quantity event recording a margin call.
+func marginEvent(ts int64, symbol, side string, cycle int, note string) TradeEvent {
+	return TradeEvent{
+		Timestamp: ts,
+		Symbol:    symbol,
+		Action:    "margin_call",
+		Side:      side,
+		Cycle:     cycle,
+		Note:      note,
+	}
+}
+
+// checkMarginLiquidation applies the tiered maintenance margin: margin calls when the ratio passes
+// MarginCallRatio, liquidation when margin balance falls to the maintenance margin.
+// Positions are marked at the bar's adverse extreme, so an intrabar wick through the liquidation price counts.
+func (r *Runner) checkMarginLiquidation(ts int64, priceMap map[string]float64, cycle int) ([]TradeEvent, string, error) {
+	if r.margin.cross() {
+		return r.checkCrossLiquidation(ts, priceMap, cycle)
+	}
+
+	positions := append([]*position(nil), r.account.Positions()...)
+	sort.Slice(positions, func(i, j int) bool {
+		return positionKey(positions[i].Symbol, positions[i].Side) < positionKey(positions[j].Symbol, positions[j].Side)
+	})
+	var (
+		events []TradeEvent
+		notes  []string
+	)
+	for _, pos := range positions {
+		price := priceMap[pos.Symbol]
+		if price <= 0 {
+			continue
+		}
+		price = r.adversePrice(pos, price, ts)
+		key := positionKey(pos.Symbol, pos.Side)
+		maint := r.margin.maintenance(pos.Symbol, pos.Quantity*price)
+		balance := pos.Margin + unrealizedPnL(pos, price)
+		if balance > maint {
+			r.updateMarginCall(key, maint/balance, func(ratio float64) {
+				events = append(events, marginEvent(ts, pos.Symbol, pos.Side, cycle,
+					fmt.Sprintf("margin call: maintenance %.2f is %.0f%% of margin balance %.2f", maint, ratio*100, balance)))
+			})
+			continue
+		}
+
+		evt, err := r.liquidatePosition(pos, liquidationFill(pos.Side, pos.LiquidationPrice, price), ts, cycle)
+		if err != nil {
+			return nil, "", err
+		}
+		delete(r.margin.calls, key)
+		events = append(events, evt)
+		notes = append(notes, fmt.Sprintf("%s %s @ %.4f", pos.Symbol, pos.Side, evt.Price))
+	}
+	// Isolated liquidations only close the affected position; the run goes on with the rest of the account
+	return events, strings.Join(notes, "; "), nil
+}
+
+// checkCrossLiquidation compares the whole account's margin balance with the summed maintenance margin,
+// marking every position at its bar's adverse extreme.
+func (r *Runner) checkCrossLiquidation(ts int64, priceMap map[string]float64, cycle int) ([]TradeEvent, string, error) {
+	positions := append([]*position(nil), r.account.Positions()...)
+	if len(positions) == 0 {
+		delete(r.margin.calls, crossMarginCallKey)
+		return nil, "", nil
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positionKey(positions[i].Symbol, positions[i].Side) < positionKey(positions[j].Symbol, positions[j].Side)
+	})
+
+	wallet := r.account.Cash()
+	prices := make([]float64, len(positions))
+	maints := make([]float64, len(positions))
+	upnls := make([]float64, len(positions))
+	totalMaint, totalUPnL := 0.0, 0.0
+	for i, pos := range positions {
+		price := priceMap[pos.Symbol]
+		if price <= 0 {
+			price = pos.EntryPrice
+		}
+		prices[i] = r.adversePrice(pos, price, ts)
+		wallet += pos.Margin
+		maints[i] = r.margin.maintenance(pos.Symbol, pos.Quantity*prices[i])
+		upnls[i] = unrealizedPnL(pos, prices[i])
+		totalMaint += maints[i]
+		totalUPnL += upnls[i]
+	}
+	for i, pos := range positions {
+		pos.LiquidationPrice = r.margin.liquidationPrice(pos, wallet, totalMaint-maints[i], totalUPnL-upnls[i])
+	}
+
+	balance := wallet + totalUPnL
+	if balance > totalMaint {
+		var events []TradeEvent
+		r.updateMarginCall(crossMarginCallKey, totalMaint/balance, func(ratio float64) {
+			events = append(events, marginEvent(ts, "", "", cycle,
+				fmt.Sprintf("margin call: maintenance %.2f is %.0f%% of cross margin balance %.2f", totalMaint, ratio*100, balance)))
+		})
+		return events, "", nil
+	}
+
+	var (
+		events []TradeEvent
+		notes  []string
+	)
+	for i, pos := range positions {
+		evt, err := r.liquidatePosition(pos, liquidationFill(pos.Side, pos.LiquidationPrice, prices[i]), ts, cycle)
+		if err != nil {
+			return nil, "", err
+		}
+		events = append(events, evt)
+		notes = append(notes, fmt.Sprintf("%s %s @ %.4f", pos.Symbol, pos.Side, evt.Price))
+	}
+	delete(r.margin.calls, crossMarginCallKey)
+	note := "cross margin: " + strings.Join(notes, "; ")
+
+	r.stateMu.Lock()
+	r.state.Liquidated = true
+	r.state.LiquidationNote = note
+	r.stateMu.Unlock()
+	return events, note, nil
+}
+
+// adversePrice is the worst price the position saw in the bar closing at ts: its low for a long, its high for a short.
+// Falls back to mark when the bar is missing or started before the position was opened.
+func (r *Runner) adversePrice(pos *position, mark float64, ts int64) float64 {
+	bar, _ := r.feed.decisionBarSnapshot(pos.Symbol, ts)
+	if bar == nil || bar.OpenTime < pos.OpenTime {
+		return mark
+	}
+	if pos.Side == "long" && bar.Low > 0 {
+		return math.Min(bar.Low, mark)
+	}
+	if pos.Side == "short" && bar.High > 0 {
+		return math.Max(bar.High, mark)
+	}
+	return mark
+}
+
+// liquidationFill is the worse of the liquidation price and the adverse extreme that triggered it.
+func liquidationFill(side string, liqPrice, extreme float64) float64 {
+	if liqPrice <= 0 {
+		return extreme
+	}
+	if side == "long" {
+		return math.Min(liqPrice, extreme)
+	}
+	return math.Max(liqPrice, extreme)
+}
+
+// updateMarginCall raises a margin call once when the ratio crosses the threshold and clears it when it recovers.
+func (r *Runner) updateMarginCall(key string, ratio float64, raise func(float64)) {
+	if ratio < r.margin.cfg.MarginCallRatio {
+		delete(r.margin.calls, key)
+		return
+	}
+	if !r.margin.calls[key] {
+		r.margin.calls[key] = true
+		raise(ratio)
+	}
+}
+
+func (r *Runner) liquidatePosition(pos *position, execPrice float64, ts int64, cycle int) (TradeEvent, error) {
+	qty, lev := pos.Quantity, pos.Leverage
+	realized, fee, finalPrice, err := r.account.Close(pos.Symbol, pos.Side, qty, execPrice)
+	if err != nil {
+		return TradeEvent{}, err
+	}
+	return TradeEvent{
+		Timestamp:       ts,
+		Symbol:          pos.Symbol,
+		Action:          "liquidated",
+		Side:            pos.Side,
+		Quantity:        qty,
+		Price:           finalPrice,
+		Fee:             fee,
+		OrderValue:      finalPrice * qty,
+		RealizedPnL:     realized - fee,
+		Leverage:        lev,
+		Cycle:           cycle,
+		LiquidationFlag: true,
+		Note:            fmt.Sprintf("forced liquidation at %.4f", finalPrice),
+	}, nil
+}
+
+func (r *Runner) marginCallsSnapshot() []string {
+	if r.margin == nil || len(r.margin.calls) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(r.margin.calls))
+	for key := range r.margin.calls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,188 @@
+package backtest
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"nofx/market"
+	"nofx/trader/types"
+)
+
+// newMarginTestRunner builds a runner whose account follows the margin config over the given bars.
+func newMarginTestRunner(t *testing.T, mc MarginConfig, cash float64, series map[string][]market.Kline) *Runner {
+	t.Helper()
+	if err := mc.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	r := &Runner{
+		cfg:     BacktestConfig{Margin: &mc},
+		feed:    testFeed("1m", series),
+		account: NewBacktestAccount(cash, 0, 0),
+		state:   &BacktestState{},
+		margin:  newMarginModel(&mc),
+	}
+	r.account.SetMarginModel(r.margin)
+	return r
+}
+
+// flatBrackets is a single tier with the given maintenance rate.
+func flatBrackets(rate float64) []types.LeverageBracket {
+	return []types.LeverageBracket{{NotionalCap: 1e9, MaxLeverage: 100, MaintMarginRate: rate}}
+}
+
+func TestMarginBrackets(t *testing.T) {
+	mc := MarginConfig{Brackets: map[string][]types.LeverageBracket{
+		"btcusdt": {
+			{NotionalCap: 10000, MaxLeverage: 50, MaintMarginRate: 0.01},
+			{NotionalCap: 1000, MaxLeverage: 100, MaintMarginRate: 0.005},
+		},
+	}}
+	if err := mc.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	m := newMarginModel(&mc)
+
+	tests := []struct {
+		name     string
+		symbol   string
+		notional float64
+		maint    float64
+		leverage int
+	}{
+		{"first tier", "BTCUSDT", 500, 2.5, 100},
+		{"tier boundary is continuous", "BTCUSDT", 1000, 5, 100},
+		{"second tier deducts the cum amount", "BTCUSDT", 5000, 45, 50},
+		{"beyond the last cap stays on the last tier", "BTCUSDT", 20000, 195, 50},
+		{"symbol without brackets uses the default ladder", "SOLUSDT", 10000, 175, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.maintenance(tt.symbol, tt.notional); math.Abs(got-tt.maint) > 1e-9 {
+				t.Errorf("Expected maintenance %.4f, got %.4f", tt.maint, got)
+			}
+			if got := m.maxLeverage(tt.symbol, tt.notional); got != tt.leverage {
+				t.Errorf("Expected max leverage %d, got %d", tt.leverage, got)
+			}
+		})
+	}
+
+	acc := NewBacktestAccount(10000, 0, 0)
+	acc.SetMarginModel(m)
+	pos, _, _, err := acc.Open("BTCUSDT", "long", 50, 100, 100, 0)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if pos.Leverage != 50 {
+		t.Errorf("Expected 100x to be capped at the 5000 notional bracket's 50x, got %dx", pos.Leverage)
+	}
+
+	bad := MarginConfig{Brackets: map[string][]types.LeverageBracket{"BTCUSDT": {{NotionalCap: 1000, MaxLeverage: 0, MaintMarginRate: 0.01}}}}
+	if err := bad.validate(); err == nil {
+		t.Errorf("Expected a bracket without max leverage to be rejected")
+	}
+}
+
+func TestIsolatedMarginCallsAndWickLiquidation(t *testing.T) {
+	bars := testBars(
+		[4]float64{100, 100, 100, 100}, // Entry
+		[4]float64{100, 101, 91, 100},  // Wick raises a margin call though the bar closes at entry
+		[4]float64{100, 101, 92, 100},  // Recovers below the call ratio
+		[4]float64{99, 100, 91, 99},    // Call raised again
+		[4]float64{99, 100, 90, 98},    // Wick through the 90.91 liquidation price
+	)
+	r := newMarginTestRunner(t, MarginConfig{Brackets: map[string][]types.LeverageBracket{"BTCUSDT": flatBrackets(0.01)}}, 10_000,
+		map[string][]market.Kline{"BTCUSDT": bars})
+	pos, _, _, err := r.account.Open("BTCUSDT", "long", 1, 10, 100, bars[0].CloseTime)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if math.Abs(pos.LiquidationPrice-90/0.99) > 1e-9 {
+		t.Fatalf("Expected liquidation at %.4f, got %.4f", 90/0.99, pos.LiquidationPrice)
+	}
+
+	wantCalls := []int{1, 0, 1}
+	for i, want := range wantCalls {
+		bar := bars[i+1]
+		events, _, err := r.checkLiquidation(bar.CloseTime, map[string]float64{"BTCUSDT": bar.Close}, i+1)
+		if err != nil {
+			t.Fatalf("Bar %d: %v", i+1, err)
+		}
+		if len(events) != want || (want == 1 && events[0].Action != "margin_call") {
+			t.Fatalf("Bar %d: expected %d margin call events, got %+v", i+1, want, events)
+		}
+	}
+
+	bar := bars[4]
+	events, note, err := r.checkLiquidation(bar.CloseTime, map[string]float64{"BTCUSDT": bar.Close}, 4)
+	if err != nil {
+		t.Fatalf("Bar 4: %v", err)
+	}
+	if len(events) != 1 || !events[0].LiquidationFlag || events[0].Price != 90 {
+		t.Fatalf("Expected the wick to liquidate at the bar low 90, got %+v", events)
+	}
+	if !strings.Contains(note, "BTCUSDT long") || r.remainingPosition("BTCUSDT", "long") > epsilon || len(r.margin.calls) != 0 {
+		t.Errorf("Expected the position and its margin call to be gone, note %q calls %v", note, r.margin.calls)
+	}
+	if r.state.Liquidated {
+		t.Errorf("An isolated liquidation must not end the run")
+	}
+}
+
+func TestCrossMarginLiquidation(t *testing.T) {
+	btc := testBars(
+		[4]float64{100, 100, 100, 100},
+		[4]float64{100, 100, 80, 85}, // Long down 20: more than its own margin
+		[4]float64{85, 86, 68, 75},
+		[4]float64{70, 71, 60, 70},
+	)
+	eth := testBars(
+		[4]float64{100, 100, 100, 100},
+		[4]float64{85, 86, 78, 80}, // Short up 14 at its worst, covering most of the long
+		[4]float64{80, 95, 79, 94},
+		[4]float64{94, 100, 90, 92},
+	)
+	brackets := map[string][]types.LeverageBracket{"BTCUSDT": flatBrackets(0.01), "ETHUSDT": flatBrackets(0.01)}
+	r := newMarginTestRunner(t, MarginConfig{Mode: MarginModeCross, MarginCallRatio: 0.5, Brackets: brackets}, 30,
+		map[string][]market.Kline{"BTCUSDT": btc, "ETHUSDT": eth})
+	for symbol, side := range map[string]string{"BTCUSDT": "long", "ETHUSDT": "short"} {
+		if _, _, _, err := r.account.Open(symbol, side, 1, 10, 100, btc[0].CloseTime); err != nil {
+			t.Fatalf("Open %s: %v", symbol, err)
+		}
+	}
+	step := func(i int) []TradeEvent {
+		events, _, err := r.checkLiquidation(btc[i].CloseTime, map[string]float64{"BTCUSDT": btc[i].Close, "ETHUSDT": eth[i].Close}, i)
+		if err != nil {
+			t.Fatalf("Bar %d: %v", i, err)
+		}
+		return events
+	}
+
+	if events := step(1); len(events) != 0 {
+		t.Fatalf("Bar 1: the short's gains should carry the long in cross mode, got %+v", events)
+	}
+	if events := step(2); len(events) != 1 || events[0].Action != "margin_call" || events[0].Symbol != "" {
+		t.Fatalf("Bar 2: expected one account-wide margin call, got %+v", events)
+	}
+
+	events := step(3)
+	if len(events) != 2 {
+		t.Fatalf("Bar 3: expected both positions liquidated, got %+v", events)
+	}
+	fills := map[string]float64{}
+	for _, evt := range events {
+		if !evt.LiquidationFlag {
+			t.Errorf("Expected liquidation events, got %+v", evt)
+		}
+		fills[evt.Symbol] = evt.Price
+	}
+	if fills["BTCUSDT"] != 60 || fills["ETHUSDT"] != 100 {
+		t.Errorf("Expected fills at each bar's adverse extreme (BTC 60, ETH 100), got %v", fills)
+	}
+	if !r.state.Liquidated || !strings.HasPrefix(r.state.LiquidationNote, "cross margin") {
+		t.Errorf("Expected a cross liquidation to end the run, got %+v", r.state)
+	}
+	if len(r.account.Positions()) != 0 || len(r.margin.calls) != 0 {
+		t.Errorf("Expected no positions or margin calls left")
+	}
+}
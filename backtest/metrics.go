@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 )
 
 // CalculateMetrics reads existing logs and calculates summary metrics. state is optional, used to supplement information not yet persisted.
@@ -42,9 +43,21 @@ func CalculateMetrics(runID string, cfg *BacktestConfig, state *BacktestState) (
 	metrics.TotalReturnPct = ((lastEquity - initialBalance) / initialBalance) * 100
 
 	metrics.MaxDrawdownPct = maxDrawdown(points, state)
-	metrics.SharpeRatio = sharpeRatio(points)
+	positions := closedPositions(events)
+	pnls := make([]float64, len(positions))
+	for i, pos := range positions {
+		pnls[i] = pos.PnL
+	}
+	metrics.SharpeRatio = sharpeRatio(pnls)
+	metrics.SortinoRatio = sortinoRatio(pnls)
+	if len(points) > 1 {
+		metrics.CalmarRatio = calmarRatio(metrics.TotalReturnPct, metrics.MaxDrawdownPct, points[len(points)-1].Timestamp-points[0].Timestamp)
+	}
 
-	fillTradeMetrics(metrics, events)
+	fillTradeMetrics(metrics, positions)
+	fillExposureMetrics(metrics, events, positions, points, initialBalance)
+	metrics.MonthlyReturns = monthlyReturns(points, initialBalance)
+	metrics.Benchmarks = benchmarkMetrics(points, initialBalance, metrics.TotalReturnPct, barsPerYear(cfg.DecisionTimeframe))
 
 	return metrics, nil
 }
@@ -91,67 +104,113 @@ func maxDrawdown(points []EquityPoint, state *BacktestState) float64 {
 	return maxDD
 }
 
-// sharpeRatio calculates the Sharpe ratio from equity points.
-// Uses sample standard deviation (n-1) and annualizes assuming ~252 trading days.
-// Returns math.NaN() for edge cases (insufficient data, zero variance).
-func sharpeRatio(points []EquityPoint) float64 {
-	// Need at least 10 data points for meaningful Sharpe calculation
-	const minDataPoints = 10
-	if len(points) < minDataPoints {
+// sharpeRatio is the mean closed-position PnL over its sample standard deviation (n-1), not annualized,
+// the same definition the live trader's PositionStore.GetFullStats uses.
+func sharpeRatio(pnls []float64) float64 {
+	if len(pnls) < 2 {
 		return 0
 	}
 
-	returns := make([]float64, 0, len(points)-1)
-	prev := points[0].Equity
-	for i := 1; i < len(points); i++ {
-		curr := points[i].Equity
-		if prev <= 0 {
-			prev = curr
-			continue
-		}
-		ret := (curr - prev) / prev
-		returns = append(returns, ret)
-		prev = curr
+	mean := 0.0
+	for _, pnl := range pnls {
+		mean += pnl
 	}
-	if len(returns) < minDataPoints-1 {
+	mean /= float64(len(pnls))
+
+	variance := 0.0
+	for _, pnl := range pnls {
+		diff := pnl - mean
+		variance += diff * diff
+	}
+	std := math.Sqrt(variance / float64(len(pnls)-1))
+	if std < 1e-10 {
+		// Zero or near-zero volatility - return 0 instead of infinity/NaN
 		return 0
 	}
+	return mean / std
+}
 
-	// Calculate mean return
-	mean := 0.0
-	for _, r := range returns {
-		mean += r
+// sortinoRatio is the Sharpe ratio with only the downside deviation of losing positions in the denominator.
+func sortinoRatio(pnls []float64) float64 {
+	if len(pnls) < 2 {
+		return 0
 	}
-	mean /= float64(len(returns))
 
-	// Calculate sample variance (using n-1 for unbiased estimator)
-	variance := 0.0
-	for _, r := range returns {
-		diff := r - mean
-		variance += diff * diff
+	mean := 0.0
+	downside := 0.0
+	for _, pnl := range pnls {
+		mean += pnl
+		if pnl < 0 {
+			downside += pnl * pnl
+		}
 	}
-	if len(returns) > 1 {
-		variance /= float64(len(returns) - 1)
+	mean /= float64(len(pnls))
+	downsideDev := math.Sqrt(downside / float64(len(pnls)))
+	if downsideDev < 1e-10 {
+		return 0
 	}
+	return mean / downsideDev
+}
 
-	std := math.Sqrt(variance)
-	if std < 1e-10 {
-		// Zero or near-zero volatility - return 0 instead of infinity/NaN
+// calmarRatio divides the annualized return by the max drawdown; durationMs is the span of the equity curve.
+func calmarRatio(totalReturnPct, maxDrawdownPct float64, durationMs int64) float64 {
+	if maxDrawdownPct <= 0 || durationMs <= 0 {
 		return 0
 	}
+	years := float64(durationMs) / float64(365*24*time.Hour/time.Millisecond)
+	growth := 1 + totalReturnPct/100
+	annualized := -100.0
+	if growth > 0 {
+		annualized = (math.Pow(growth, 1/years) - 1) * 100
+	}
+	return annualized / maxDrawdownPct
+}
 
-	// Calculate Sharpe ratio (assuming risk-free rate = 0 for crypto)
-	// Annualize by multiplying by sqrt(periods per year)
-	// Assuming each equity point represents ~1 hour, we have ~8760 periods/year
-	// For conservative estimate, use sqrt(252) as if daily returns
-	periodsPerYear := 252.0
-	annualizationFactor := math.Sqrt(periodsPerYear)
+// isClosingTrade reports whether an event realised PnL and counts as a trade in the metrics.
+func isClosingTrade(evt TradeEvent) bool {
+	return evt.LiquidationFlag || strings.HasPrefix(evt.Action, "close") || evt.RealizedPnL != 0
+}
 
-	sharpe := (mean / std) * annualizationFactor
-	return sharpe
+// closedPosition is one position from the open that took it off flat to the close that brought it back.
+type closedPosition struct {
+	Symbol   string
+	Side     string
+	OpenedAt int64
+	ClosedAt int64
+	PnL      float64 // Net of opening and closing fees
 }
 
-func fillTradeMetrics(metrics *Metrics, events []TradeEvent) {
+// closedPositions groups the trade log into positions, like the live trader's closed position records.
+// Each close's RealizedPnL already carries its share of the opening fee (see BacktestAccount.close),
+// so summing the closes gives the position's PnL net of every fee it paid. Positions still open are left out.
+func closedPositions(events []TradeEvent) []closedPosition {
+	open := make(map[string]*closedPosition)
+	var closed []closedPosition
+	for _, evt := range events {
+		if evt.Side == "" {
+			continue
+		}
+		key := positionKey(evt.Symbol, evt.Side)
+		pos, ok := open[key]
+		if !ok {
+			pos = &closedPosition{Symbol: evt.Symbol, Side: evt.Side, OpenedAt: evt.Timestamp}
+			open[key] = pos
+		}
+		if !isClosingTrade(evt) {
+			continue
+		}
+		pos.PnL += evt.RealizedPnL
+		if evt.PositionAfter <= epsilon {
+			pos.ClosedAt = evt.Timestamp
+			closed = append(closed, *pos)
+			delete(open, key)
+		}
+	}
+	return closed
+}
+
+// fillTradeMetrics counts each closed position as one trade, as the live trader's position stats do.
+func fillTradeMetrics(metrics *Metrics, positions []closedPosition) {
 	if metrics == nil {
 		return
 	}
@@ -161,37 +220,62 @@ func fillTradeMetrics(metrics *Metrics, events []TradeEvent) {
 	lossTrades := 0
 	totalWinAmount := 0.0
 	totalLossAmount := 0.0
+	totalPnL := 0.0
+	lossStreak := 0
+	if metrics.DirectionStats == nil {
+		metrics.DirectionStats = make(map[string]DirectionMetrics)
+	}
 
-	for _, evt := range events {
-		include := evt.LiquidationFlag || strings.HasPrefix(evt.Action, "close")
-		if evt.RealizedPnL != 0 {
-			include = true
+	for _, pos := range positions {
+		totalTrades++
+		totalPnL += pos.PnL
+
+		// Streaks count every non-winning trade as a loss, like the live history summary
+		if pos.PnL > 0 {
+			lossStreak = 0
+		} else {
+			lossStreak++
+			if lossStreak > metrics.MaxConsecutiveLosses {
+				metrics.MaxConsecutiveLosses = lossStreak
+			}
 		}
-		if !include {
-			continue
+
+		dir := metrics.DirectionStats[pos.Side]
+		dir.Trades++
+		dir.TotalPnL += pos.PnL
+		if pos.PnL > 0 {
+			dir.WinRate++ // Win count until normalised below
 		}
-		totalTrades++
+		metrics.DirectionStats[pos.Side] = dir
 
-		stats := metrics.SymbolStats[evt.Symbol]
+		stats := metrics.SymbolStats[pos.Symbol]
 		stats.TotalTrades++
-		stats.TotalPnL += evt.RealizedPnL
+		stats.TotalPnL += pos.PnL
 
-		if evt.RealizedPnL > 0 {
+		if pos.PnL > 0 {
 			winTrades++
-			totalWinAmount += evt.RealizedPnL
+			totalWinAmount += pos.PnL
 			stats.WinningTrades++
-		} else if evt.RealizedPnL < 0 {
+		} else if pos.PnL < 0 {
 			lossTrades++
-			totalLossAmount += -evt.RealizedPnL
+			totalLossAmount += -pos.PnL
 			stats.LosingTrades++
 		}
 
-		metrics.SymbolStats[evt.Symbol] = stats
+		metrics.SymbolStats[pos.Symbol] = stats
 	}
 
 	metrics.Trades = totalTrades
 	if totalTrades > 0 {
 		metrics.WinRate = (float64(winTrades) / float64(totalTrades)) * 100
+		metrics.Expectancy = totalPnL / float64(totalTrades)
+	}
+	for side, dir := range metrics.DirectionStats {
+		if dir.Trades > 0 {
+			dir.WinRate = dir.WinRate / float64(dir.Trades) * 100
+			dir.AvgPnL = dir.TotalPnL / float64(dir.Trades)
+		}
+		metrics.DirectionStats[side] = dir
 	}
 	if winTrades > 0 {
 		metrics.AvgWin = totalWinAmount / float64(winTrades)
@@ -237,3 +321,107 @@ func fillTradeMetrics(metrics *Metrics, events []TradeEvent) {
 		metrics.WorstSymbol = ""
 	}
 }
+
+// fillExposureMetrics derives holding times, time in market and cost drag from the trade log.
+// A position's holding time runs from the open that took it off flat to the close that brought it back.
+func fillExposureMetrics(metrics *Metrics, events []TradeEvent, positions []closedPosition, points []EquityPoint, initialBalance float64) {
+	if metrics == nil {
+		return
+	}
+
+	var totalHoldMins float64
+	for _, pos := range positions {
+		holdMins := float64(pos.ClosedAt-pos.OpenedAt) / 60000.0
+		totalHoldMins += holdMins
+		if holdMins > metrics.MaxHoldingMins {
+			metrics.MaxHoldingMins = holdMins
+		}
+	}
+	if len(positions) > 0 {
+		metrics.AvgHoldingMins = totalHoldMins / float64(len(positions))
+	}
+
+	// A close's fee includes the opening fee share it realises, so only the part still held is added from the opens
+	openFees := make(map[string]float64)
+	held := make(map[string]bool)
+	var inMarketMs, marketSince int64
+	for _, evt := range events {
+		metrics.TotalSlippage += math.Abs(evt.Slippage) * evt.Quantity
+		if evt.Side == "" {
+			metrics.TotalFees += evt.Fee
+			continue
+		}
+		key := positionKey(evt.Symbol, evt.Side)
+
+		if strings.HasPrefix(evt.Action, "open") {
+			if len(held) == 0 {
+				marketSince = evt.Timestamp
+			}
+			openFees[key] += evt.Fee
+			held[key] = true
+			continue
+		}
+		metrics.TotalFees += evt.Fee
+		if !isClosingTrade(evt) {
+			continue
+		}
+		if before := evt.Quantity + evt.PositionAfter; before > epsilon {
+			openFees[key] -= openFees[key] * evt.Quantity / before
+		}
+		if evt.PositionAfter > epsilon {
+			continue
+		}
+		delete(openFees, key)
+		if held[key] {
+			delete(held, key)
+			if len(held) == 0 {
+				inMarketMs += evt.Timestamp - marketSince
+			}
+		}
+	}
+	for _, fee := range openFees {
+		metrics.TotalFees += fee // Opening fees of positions still held at the end
+	}
+
+	if len(points) > 1 {
+		first, last := points[0].Timestamp, points[len(points)-1].Timestamp
+		if len(held) > 0 && last > marketSince {
+			inMarketMs += last - marketSince // Still holding at the end of the run
+		}
+		if span := last - first; span > 0 {
+			metrics.TimeInMarketPct = math.Min(float64(inMarketMs)/float64(span)*100, 100)
+		}
+	}
+
+	if initialBalance > 0 {
+		metrics.FeeDragPct = metrics.TotalFees / initialBalance * 100
+		metrics.SlippageDragPct = metrics.TotalSlippage / initialBalance * 100
+	}
+}
+
+// monthlyReturns groups the equity curve by UTC calendar month; each month is measured from the previous month's close.
+func monthlyReturns(points []EquityPoint, initialBalance float64) []MonthlyReturn {
+	var months []MonthlyReturn
+	var starts []float64
+	for _, pt := range points {
+		month := time.UnixMilli(pt.Timestamp).UTC().Format("2006-01")
+		n := len(months)
+		if n > 0 && months[n-1].Month == month {
+			months[n-1].EndEquity = pt.Equity
+			continue
+		}
+		start := initialBalance
+		if n > 0 {
+			start = months[n-1].EndEquity
+		}
+		starts = append(starts, start)
+		months = append(months, MonthlyReturn{Month: month, EndEquity: pt.Equity})
+	}
+	for i := range months {
+		months[i].PnL = months[i].EndEquity - starts[i]
+		if starts[i] > 0 {
+			months[i].ReturnPct = months[i].PnL / starts[i] * 100
+		}
+	}
+	return months
+}
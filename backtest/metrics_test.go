@@ -0,0 +1,91 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateMetricsPerPosition(t *testing.T) {
+	t.Chdir(t.TempDir())
+	const runID = "metrics"
+	minute := int64(60_000)
+
+	events := []TradeEvent{
+		{Timestamp: 0, Symbol: "BTCUSDT", Action: "open_long", Side: "long", Quantity: 1, Fee: 0.1, PositionAfter: 1},
+		// Closes carry their share of the opening fee, as BacktestAccount.close reports it
+		{Timestamp: 10 * minute, Symbol: "BTCUSDT", Action: "close_long", Side: "long", Quantity: 0.5, Fee: 0.105, RealizedPnL: 4.895, PositionAfter: 0.5},
+		{Timestamp: 30 * minute, Symbol: "BTCUSDT", Action: "close_long", Side: "long", Quantity: 0.5, Fee: 0.11, RealizedPnL: 9.89},
+		{Timestamp: 40 * minute, Symbol: "SOLUSDT", Action: "open_short", Side: "short", Quantity: 2, Fee: 0.1, PositionAfter: 2},
+		{Timestamp: 60 * minute, Symbol: "SOLUSDT", Action: "close_short", Side: "short", Quantity: 2, Fee: 0.21, RealizedPnL: -10.21},
+		{Timestamp: 70 * minute, Symbol: "ETHUSDT", Action: "open_long", Side: "long", Quantity: 1, Fee: 0.01, PositionAfter: 1},
+	}
+	for _, evt := range events {
+		if err := appendTradeEvent(runID, evt); err != nil {
+			t.Fatalf("appendTradeEvent: %v", err)
+		}
+	}
+	for i := int64(0); i <= 10; i++ {
+		if err := appendEquityPoint(runID, EquityPoint{Timestamp: i * 10 * minute, Equity: 1000 + float64(i)}); err != nil {
+			t.Fatalf("appendEquityPoint: %v", err)
+		}
+	}
+
+	m, err := CalculateMetrics(runID, &BacktestConfig{InitialBalance: 1000}, nil)
+	if err != nil {
+		t.Fatalf("CalculateMetrics: %v", err)
+	}
+
+	if m.Trades != 2 {
+		t.Fatalf("Expected the partial close to count with its position as one trade, got %d trades", m.Trades)
+	}
+	win, loss := 14.785, -10.21
+	mean := (win + loss) / 2
+	checks := []struct {
+		name      string
+		got, want float64
+	}{
+		{"win rate", m.WinRate, 50},
+		{"expectancy", m.Expectancy, mean},
+		{"avg win", m.AvgWin, win},
+		{"avg loss", m.AvgLoss, loss},
+		{"sharpe", m.SharpeRatio, mean / (math.Abs(win-mean) * math.Sqrt2)},
+		{"sortino", m.SortinoRatio, mean / math.Sqrt(loss*loss/2)},
+		{"avg holding", m.AvgHoldingMins, 25},
+		{"max holding", m.MaxHoldingMins, 30},
+		{"total fees", m.TotalFees, 0.435},
+		{"time in market", m.TimeInMarketPct, 80},
+		{"BTC pnl", m.SymbolStats["BTCUSDT"].TotalPnL, win},
+		{"long pnl", m.DirectionStats["long"].TotalPnL, win},
+		{"short win rate", m.DirectionStats["short"].WinRate, 0},
+	}
+	for _, c := range checks {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s: expected %.6f, got %.6f", c.name, c.want, c.got)
+		}
+	}
+	if m.MaxConsecutiveLosses != 1 {
+		t.Errorf("Expected 1 consecutive loss, got %d", m.MaxConsecutiveLosses)
+	}
+	if _, ok := m.SymbolStats["ETHUSDT"]; ok {
+		t.Errorf("A position still open must not count as a trade")
+	}
+}
+
+func TestTradeRatiosMatchLiveStats(t *testing.T) {
+	if got := sharpeRatio([]float64{5}); got != 0 {
+		t.Errorf("Expected 0 Sharpe for a single trade, got %.4f", got)
+	}
+	if got := sharpeRatio([]float64{3, 3, 3}); got != 0 {
+		t.Errorf("Expected 0 Sharpe without variance, got %.4f", got)
+	}
+	// Mean 2, sample standard deviation 2: not annualized, so exactly 1
+	if got := sharpeRatio([]float64{0, 4, 2}); math.Abs(got-1) > 1e-9 {
+		t.Errorf("Expected mean over sample deviation 1, got %.6f", got)
+	}
+	if got := sortinoRatio([]float64{1, 2, 3}); got != 0 {
+		t.Errorf("Expected 0 Sortino without losing trades, got %.4f", got)
+	}
+	if got := sortinoRatio([]float64{4, -2}); math.Abs(got-1/math.Sqrt2) > 1e-9 {
+		t.Errorf("Expected Sortino %.6f, got %.6f", 1/math.Sqrt2, got)
+	}
+}
@@ -0,0 +1,297 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Monte Carlo resampling methods
+const (
+	MonteCarloShuffle   = "shuffle"   // Same trades in a random order; final return is fixed, path risk varies
+	MonteCarloBootstrap = "bootstrap" // Draw the same number of trades with replacement
+	MonteCarloSkip      = "skip"      // Drop each trade with probability SkipPct, as if signals were missed
+)
+
+const (
+	defaultMonteCarloIterations = 1000
+	maxMonteCarloIterations     = 20000
+	defaultMonteCarloSkipPct    = 10.0
+)
+
+var defaultRuinThresholds = []float64{20, 30, 50}
+
+// MonteCarloConfig controls how a run's closed trades are resampled.
+type MonteCarloConfig struct {
+	Method         string    `json:"method"`
+	Iterations     int       `json:"iterations"`
+	SkipPct        float64   `json:"skip_pct,omitempty"`        // Skip method only
+	RuinThresholds []float64 `json:"ruin_thresholds,omitempty"` // Drawdown levels (%) counted as ruin
+	Seed           int64     `json:"seed,omitempty"`            // 0 = time based
+}
+
+// Distribution summarises one statistic across all simulated paths.
+type Distribution struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	Min    float64 `json:"min"`
+	P5     float64 `json:"p5"`
+	P25    float64 `json:"p25"`
+	Median float64 `json:"median"`
+	P75    float64 `json:"p75"`
+	P95    float64 `json:"p95"`
+	Max    float64 `json:"max"`
+}
+
+// RuinProbability is the share of paths whose drawdown reached the threshold.
+type RuinProbability struct {
+	ThresholdPct float64 `json:"threshold_pct"`
+	Probability  float64 `json:"probability"` // 0-1
+}
+
+// MonteCarloResult is the robustness report for one run.
+type MonteCarloResult struct {
+	RunID          string  `json:"run_id"`
+	Method         string  `json:"method"`
+	Iterations     int     `json:"iterations"`
+	Trades         int     `json:"trades"`
+	InitialBalance float64 `json:"initial_balance"`
+
+	// The realised trade sequence, measured the same way as the simulated paths
+	ActualReturnPct      float64 `json:"actual_return_pct"`
+	ActualMaxDrawdownPct float64 `json:"actual_max_drawdown_pct"`
+	ActualLosingStreak   int     `json:"actual_losing_streak"`
+
+	FinalReturnPct      Distribution      `json:"final_return_pct"`
+	MaxDrawdownPct      Distribution      `json:"max_drawdown_pct"`
+	LongestLosingStreak Distribution      `json:"longest_losing_streak"`
+	ProbabilityOfLoss   float64           `json:"probability_of_loss"` // Share of paths ending below the initial balance
+	RiskOfRuin          []RuinProbability `json:"risk_of_ruin"`
+}
+
+// tradePath is the outcome of replaying one sequence of trade PnLs.
+type tradePath struct {
+	returnPct     float64
+	maxDrawdown   float64
+	losingStreak  int
+	hitZeroEquity bool
+}
+
+// normalize fills in defaults and checks the limits.
+func (mc *MonteCarloConfig) normalize() error {
+	if mc.Method == "" {
+		mc.Method = MonteCarloBootstrap
+	}
+	switch mc.Method {
+	case MonteCarloShuffle, MonteCarloBootstrap, MonteCarloSkip:
+	default:
+		return fmt.Errorf("unsupported monte carlo method '%s'", mc.Method)
+	}
+	if mc.Iterations <= 0 {
+		mc.Iterations = defaultMonteCarloIterations
+	}
+	if mc.Iterations > maxMonteCarloIterations {
+		return fmt.Errorf("iterations %d exceed limit %d", mc.Iterations, maxMonteCarloIterations)
+	}
+	if mc.SkipPct <= 0 {
+		mc.SkipPct = defaultMonteCarloSkipPct
+	}
+	if mc.SkipPct >= 100 {
+		return fmt.Errorf("skip_pct must be below 100")
+	}
+	if len(mc.RuinThresholds) == 0 {
+		mc.RuinThresholds = append([]float64(nil), defaultRuinThresholds...)
+	}
+	for _, t := range mc.RuinThresholds {
+		if t <= 0 || t > 100 {
+			return fmt.Errorf("ruin threshold %.2f must be in (0, 100]", t)
+		}
+	}
+	sort.Float64s(mc.RuinThresholds)
+	return nil
+}
+
+// closedTradePnLs returns the net PnL of every closed position, counted the same way as the metrics.
+func closedTradePnLs(events []TradeEvent) []float64 {
+	positions := closedPositions(events)
+	pnls := make([]float64, len(positions))
+	for i, pos := range positions {
+		pnls[i] = pos.PnL
+	}
+	return pnls
+}
+
+// SimulateTrades resamples the trade PnLs and replays each path from the initial balance.
+// PnLs are applied as fixed amounts, so the result reads "what if these same trades had come in another order or mix".
+func SimulateTrades(pnls []float64, initialBalance float64, cfg MonteCarloConfig) (*MonteCarloResult, error) {
+	if err := cfg.normalize(); err != nil {
+		return nil, err
+	}
+	if len(pnls) == 0 {
+		return nil, fmt.Errorf("run has no closed trades to resample")
+	}
+	if initialBalance <= 0 {
+		return nil, fmt.Errorf("initial balance must be positive")
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	actual := replayTrades(pnls, initialBalance)
+	result := &MonteCarloResult{
+		Method:               cfg.Method,
+		Iterations:           cfg.Iterations,
+		Trades:               len(pnls),
+		InitialBalance:       initialBalance,
+		ActualReturnPct:      actual.returnPct,
+		ActualMaxDrawdownPct: actual.maxDrawdown,
+		ActualLosingStreak:   actual.losingStreak,
+	}
+
+	returns := make([]float64, cfg.Iterations)
+	drawdowns := make([]float64, cfg.Iterations)
+	streaks := make([]float64, cfg.Iterations)
+	ruined := make([]int, len(cfg.RuinThresholds))
+	losses := 0
+	sample := make([]float64, 0, len(pnls))
+
+	for i := 0; i < cfg.Iterations; i++ {
+		sample = sample[:0]
+		switch cfg.Method {
+		case MonteCarloShuffle:
+			sample = append(sample, pnls...)
+			rng.Shuffle(len(sample), func(a, b int) { sample[a], sample[b] = sample[b], sample[a] })
+		case MonteCarloBootstrap:
+			for range pnls {
+				sample = append(sample, pnls[rng.Intn(len(pnls))])
+			}
+		case MonteCarloSkip:
+			for _, pnl := range pnls {
+				if rng.Float64()*100 >= cfg.SkipPct {
+					sample = append(sample, pnl)
+				}
+			}
+		}
+
+		path := replayTrades(sample, initialBalance)
+		returns[i] = path.returnPct
+		drawdowns[i] = path.maxDrawdown
+		streaks[i] = float64(path.losingStreak)
+		if path.returnPct < 0 {
+			losses++
+		}
+		for t, threshold := range cfg.RuinThresholds {
+			if path.hitZeroEquity || path.maxDrawdown >= threshold {
+				ruined[t]++
+			}
+		}
+	}
+
+	result.FinalReturnPct = summarize(returns)
+	result.MaxDrawdownPct = summarize(drawdowns)
+	result.LongestLosingStreak = summarize(streaks)
+	result.ProbabilityOfLoss = float64(losses) / float64(cfg.Iterations)
+	for t, threshold := range cfg.RuinThresholds {
+		result.RiskOfRuin = append(result.RiskOfRuin, RuinProbability{
+			ThresholdPct: threshold,
+			Probability:  float64(ruined[t]) / float64(cfg.Iterations),
+		})
+	}
+	return result, nil
+}
+
+// replayTrades walks the equity through the PnLs; a path that hits zero equity stops there.
+func replayTrades(pnls []float64, initialBalance float64) tradePath {
+	equity := initialBalance
+	peak := initialBalance
+	var path tradePath
+	streak := 0
+	for _, pnl := range pnls {
+		equity += pnl
+		// Streaks count every non-winning trade as a loss, like the metrics
+		if pnl > 0 {
+			streak = 0
+		} else {
+			streak++
+			if streak > path.losingStreak {
+				path.losingStreak = streak
+			}
+		}
+		if equity <= 0 {
+			equity = 0
+			path.hitZeroEquity = true
+			path.maxDrawdown = 100
+			break
+		}
+		if equity > peak {
+			peak = equity
+		}
+		if dd := (peak - equity) / peak * 100; dd > path.maxDrawdown {
+			path.maxDrawdown = dd
+		}
+	}
+	path.returnPct = (equity - initialBalance) / initialBalance * 100
+	return path
+}
+
+// summarize sorts the values in place and returns their distribution.
+func summarize(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+	sort.Float64s(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return Distribution{
+		Mean:   mean,
+		StdDev: math.Sqrt(variance / float64(len(values))),
+		Min:    values[0],
+		P5:     percentile(values, 5),
+		P25:    percentile(values, 25),
+		Median: percentile(values, 50),
+		P75:    percentile(values, 75),
+		P95:    percentile(values, 95),
+		Max:    values[len(values)-1],
+	}
+}
+
+// percentile interpolates linearly between the closest ranks of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	frac := pos - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*frac
+}
+
+// MonteCarlo resamples a finished run's closed trades to test how much of its result depends on luck.
+func (m *Manager) MonteCarlo(runID string, cfg MonteCarloConfig) (*MonteCarloResult, error) {
+	runCfg, err := LoadConfig(runID)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	events, err := LoadTradeEvents(runID)
+	if err != nil {
+		return nil, fmt.Errorf("load trade events: %w", err)
+	}
+	result, err := SimulateTrades(closedTradePnLs(events), runCfg.InitialBalance, cfg)
+	if err != nil {
+		return nil, err
+	}
+	result.RunID = runID
+	return result, nil
+}
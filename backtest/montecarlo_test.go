@@ -0,0 +1,109 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClosedTradePnLsNetOfFees(t *testing.T) {
+	acc := NewBacktestAccount(10000, 10, 0)
+	var events []TradeEvent
+	open := func(symbol, side string, qty, price float64, ts int64) {
+		pos, fee, execPrice, err := acc.Open(symbol, side, qty, 1, price, ts)
+		if err != nil {
+			t.Fatalf("Open %s %s: %v", symbol, side, err)
+		}
+		events = append(events, TradeEvent{Timestamp: ts, Symbol: symbol, Action: "open_" + side, Side: side, Quantity: qty, Price: execPrice, Fee: fee, PositionAfter: pos.Quantity})
+	}
+	closePos := func(symbol, side string, qty, price float64, ts int64) {
+		realized, fee, execPrice, err := acc.Close(symbol, side, qty, price)
+		if err != nil {
+			t.Fatalf("Close %s %s: %v", symbol, side, err)
+		}
+		after := 0.0
+		if pos, ok := acc.positions[positionKey(symbol, side)]; ok {
+			after = pos.Quantity
+		}
+		events = append(events, TradeEvent{Timestamp: ts, Symbol: symbol, Action: "close_" + side, Side: side, Quantity: qty, Price: execPrice, Fee: fee, RealizedPnL: realized - fee, PositionAfter: after})
+	}
+
+	open("BTCUSDT", "long", 1, 100, 1)
+	closePos("BTCUSDT", "long", 0.5, 110, 2)
+	closePos("BTCUSDT", "long", 0.5, 120, 3)
+	open("SOLUSDT", "short", 2, 50, 4)
+	closePos("SOLUSDT", "short", 2, 55, 5)
+	open("ETHUSDT", "long", 1, 10, 6)
+
+	pnls := closedTradePnLs(events)
+	// BTC: +15 gross, 0.1 opening fee, 0.055 + 0.06 closing fees; SOL: -10 gross, 0.1 + 0.11 fees; ETH still open
+	want := []float64{14.785, -10.21}
+	if len(pnls) != len(want) {
+		t.Fatalf("Expected one trade per closed position %v, got %v", want, pnls)
+	}
+	for i := range want {
+		if math.Abs(pnls[i]-want[i]) > 1e-9 {
+			t.Errorf("Trade %d: expected PnL %.4f net of fees, got %.4f", i, want[i], pnls[i])
+		}
+	}
+}
+
+func TestReplayTradesLosingStreak(t *testing.T) {
+	path := replayTrades([]float64{-10, 0, -20, 50, -5}, 1000)
+	if path.losingStreak != 3 {
+		t.Errorf("Expected a break-even trade to extend the losing streak to 3, got %d", path.losingStreak)
+	}
+	if math.Abs(path.returnPct-1.5) > 1e-9 {
+		t.Errorf("Expected 1.5%% return, got %.4f", path.returnPct)
+	}
+	if math.Abs(path.maxDrawdown-3) > 1e-9 {
+		t.Errorf("Expected 3%% max drawdown, got %.4f", path.maxDrawdown)
+	}
+
+	ruined := replayTrades([]float64{-600, -600, 5000}, 1000)
+	if !ruined.hitZeroEquity || ruined.maxDrawdown != 100 || ruined.returnPct != -100 {
+		t.Errorf("Expected the path to stop at zero equity, got %+v", ruined)
+	}
+}
+
+func TestSimulateTrades(t *testing.T) {
+	pnls := []float64{100, -50, 30, -80, 120, -20, 10}
+
+	shuffled, err := SimulateTrades(pnls, 1000, MonteCarloConfig{Method: MonteCarloShuffle, Iterations: 200, Seed: 7})
+	if err != nil {
+		t.Fatalf("SimulateTrades shuffle: %v", err)
+	}
+	if math.Abs(shuffled.FinalReturnPct.Min-shuffled.ActualReturnPct) > 1e-9 || math.Abs(shuffled.FinalReturnPct.Max-shuffled.ActualReturnPct) > 1e-9 {
+		t.Errorf("Shuffling must keep the final return at %.4f, got %+v", shuffled.ActualReturnPct, shuffled.FinalReturnPct)
+	}
+	if shuffled.MaxDrawdownPct.Max < shuffled.MaxDrawdownPct.Min {
+		t.Errorf("Distribution out of order: %+v", shuffled.MaxDrawdownPct)
+	}
+
+	cfg := MonteCarloConfig{Method: MonteCarloBootstrap, Iterations: 500, Seed: 42, RuinThresholds: []float64{50, 5}}
+	first, err := SimulateTrades(pnls, 1000, cfg)
+	if err != nil {
+		t.Fatalf("SimulateTrades bootstrap: %v", err)
+	}
+	second, _ := SimulateTrades(pnls, 1000, cfg)
+	if first.FinalReturnPct != second.FinalReturnPct {
+		t.Errorf("Same seed must give the same distribution, got %+v and %+v", first.FinalReturnPct, second.FinalReturnPct)
+	}
+	if len(first.RiskOfRuin) != 2 || first.RiskOfRuin[0].ThresholdPct != 5 || first.RiskOfRuin[0].Probability < first.RiskOfRuin[1].Probability {
+		t.Errorf("Expected ruin thresholds sorted with the lower one at least as likely, got %+v", first.RiskOfRuin)
+	}
+
+	skipped, err := SimulateTrades(pnls, 1000, MonteCarloConfig{Method: MonteCarloSkip, Iterations: 200, SkipPct: 50, Seed: 3})
+	if err != nil {
+		t.Fatalf("SimulateTrades skip: %v", err)
+	}
+	if skipped.FinalReturnPct.Min == skipped.FinalReturnPct.Max {
+		t.Errorf("Skipping trades should spread the final return, got %+v", skipped.FinalReturnPct)
+	}
+
+	if _, err := SimulateTrades(nil, 1000, MonteCarloConfig{}); err == nil {
+		t.Errorf("Expected an error for a run without closed trades")
+	}
+	if _, err := SimulateTrades(pnls, 1000, MonteCarloConfig{Method: "martingale"}); err == nil {
+		t.Errorf("Expected an error for an unknown method")
+	}
+}
@@ -0,0 +1,228 @@
+package backtest
+
+import (
+	"fmt"
+
+	"nofx/market"
+	"nofx/store"
+	"nofx/trader/strategy"
+)
+
+// PairsSnapshot is the simulated spread trade, carried in checkpoints so resumed runs keep managing it.
+type PairsSnapshot struct {
+	Direction   string  `json:"direction,omitempty"` // Empty while flat
+	HedgeRatio  float64 `json:"hedge_ratio,omitempty"`
+	EntryZScore float64 `json:"entry_z_score,omitempty"`
+	QuantityA   float64 `json:"quantity_a,omitempty"`
+	QuantityB   float64 `json:"quantity_b,omitempty"`
+	EntryPriceA float64 `json:"entry_price_a,omitempty"`
+	EntryPriceB float64 `json:"entry_price_b,omitempty"`
+	OpenedAt    int64   `json:"opened_at,omitempty"`
+	LastZScore  float64 `json:"last_z_score"`
+	TradeCount  int     `json:"trade_count"`
+}
+
+// pairsSimulator replays the live pairs rules against historical bars.
+// Every decision bar recomputes the rolling hedge ratio and z-score from the loaded klines,
+// then opens, holds or closes both legs together exactly as RunPairsCycle does.
+type pairsSimulator struct {
+	cfg     *store.PairsStrategyConfig
+	account *BacktestAccount
+	state   PairsSnapshot
+}
+
+func newPairsSimulator(cfg *store.PairsStrategyConfig, account *BacktestAccount) *pairsSimulator {
+	return &pairsSimulator{cfg: cfg, account: account}
+}
+
+// snapshot returns a copy of the pairs state for checkpointing.
+func (p *pairsSimulator) snapshot() *PairsSnapshot {
+	snap := p.state
+	return &snap
+}
+
+func (p *pairsSimulator) restore(snap *PairsSnapshot) {
+	if snap == nil {
+		return
+	}
+	p.state = *snap
+}
+
+func (p *pairsSimulator) isOpen() bool {
+	return p.state.Direction != ""
+}
+
+// step advances the spread trade by one bar at the given leg prices.
+func (p *pairsSimulator) step(stats *strategy.PairsStats, priceA, priceB float64, ts int64, cycle int) ([]TradeEvent, []string) {
+	p.state.LastZScore = stats.ZScore
+	if p.isOpen() {
+		reason := p.exitReason(stats.ZScore, priceA, priceB, ts)
+		if reason == "" {
+			return nil, nil
+		}
+		events := p.closeLegs(priceA, priceB, ts, cycle, "pairs "+reason)
+		note := fmt.Sprintf("pairs trade closed (%s) at z %.2f", reason, stats.ZScore)
+		return events, []string{note}
+	}
+
+	direction := strategy.PairsEntrySignal(stats, p.cfg)
+	if direction == "" {
+		return nil, nil
+	}
+	events, err := p.openLegs(direction, stats, priceA, priceB, ts, cycle)
+	if err != nil {
+		return events, []string{fmt.Sprintf("pairs %s entry skipped: %v", direction, err)}
+	}
+	note := fmt.Sprintf("pairs trade opened %s at z %.2f, hedge ratio %.4f", direction, stats.ZScore, stats.HedgeRatio)
+	return events, []string{note}
+}
+
+// exitReason applies the shared stop to both legs at once, like monitorPairsTrade.
+func (p *pairsSimulator) exitReason(z, priceA, priceB float64, ts int64) string {
+	sideA, sideB := strategy.PairsLegSides(p.state.Direction)
+	if p.legQty(p.cfg.SymbolA, sideA) <= epsilon || p.legQty(p.cfg.SymbolB, sideB) <= epsilon {
+		return store.PairsCloseLegGone // A leg was liquidated on its own
+	}
+
+	pnlA := (priceA - p.state.EntryPriceA) * p.state.QuantityA
+	pnlB := (priceB - p.state.EntryPriceB) * p.state.QuantityB
+	pnl := pnlA - pnlB
+	if p.state.Direction == store.PairsShortSpread {
+		pnl = -pnl
+	}
+	pnlPct := 0.0
+	if notional := p.state.QuantityA*p.state.EntryPriceA + p.state.QuantityB*p.state.EntryPriceB; notional > 0 {
+		pnlPct = pnl / notional * 100
+	}
+	barsHeld := 0
+	if tfDuration, err := market.TFDuration(p.cfg.Timeframe); err == nil {
+		barsHeld = int((ts - p.state.OpenedAt) / tfDuration.Milliseconds())
+	}
+	return strategy.PairsExitReason(p.state.Direction, z, pnlPct, barsHeld, p.cfg)
+}
+
+// openLegs opens leg A then leg B; if B fails, A is closed again so no leg is left unhedged.
+func (p *pairsSimulator) openLegs(direction string, stats *strategy.PairsStats, priceA, priceB float64, ts int64, cycle int) ([]TradeEvent, error) {
+	qtyA, qtyB := strategy.PairsLegQuantities(p.cfg.NotionalUSD, stats.HedgeRatio, priceA, priceB)
+	if qtyA <= epsilon || qtyB <= epsilon {
+		return nil, fmt.Errorf("invalid leg quantities")
+	}
+	sideA, sideB := strategy.PairsLegSides(direction)
+	note := fmt.Sprintf("pairs %s z %.2f", direction, stats.ZScore)
+
+	eventA, execA, err := p.open(p.cfg.SymbolA, sideA, qtyA, priceA, ts, cycle, note)
+	if err != nil {
+		return nil, err
+	}
+	eventB, execB, err := p.open(p.cfg.SymbolB, sideB, qtyB, priceB, ts, cycle, note)
+	if err != nil {
+		events := []TradeEvent{eventA}
+		if unwind, closeErr := p.close(p.cfg.SymbolA, sideA, priceA, ts, cycle, "pairs open_failed"); closeErr == nil {
+			events = append(events, unwind)
+		}
+		return events, err
+	}
+
+	p.state = PairsSnapshot{
+		Direction:   direction,
+		HedgeRatio:  stats.HedgeRatio,
+		EntryZScore: stats.ZScore,
+		QuantityA:   qtyA,
+		QuantityB:   qtyB,
+		EntryPriceA: execA,
+		EntryPriceB: execB,
+		OpenedAt:    ts,
+		LastZScore:  stats.ZScore,
+		TradeCount:  p.state.TradeCount + 1,
+	}
+	return []TradeEvent{eventA, eventB}, nil
+}
+
+// closeLegs market-closes whatever is left of both legs and returns to flat.
+func (p *pairsSimulator) closeLegs(priceA, priceB float64, ts int64, cycle int, note string) []TradeEvent {
+	sideA, sideB := strategy.PairsLegSides(p.state.Direction)
+	var events []TradeEvent
+	if evt, err := p.close(p.cfg.SymbolA, sideA, priceA, ts, cycle, note); err == nil {
+		events = append(events, evt)
+	}
+	if evt, err := p.close(p.cfg.SymbolB, sideB, priceB, ts, cycle, note); err == nil {
+		events = append(events, evt)
+	}
+	p.state = PairsSnapshot{LastZScore: p.state.LastZScore, TradeCount: p.state.TradeCount}
+	return events
+}
+
+func (p *pairsSimulator) open(symbol, side string, qty, price float64, ts int64, cycle int, note string) (TradeEvent, float64, error) {
+	pos, fee, execPrice, err := p.account.Open(symbol, side, qty, p.cfg.Leverage, price, ts)
+	if err != nil {
+		return TradeEvent{}, 0, err
+	}
+	return TradeEvent{
+		Timestamp:     ts,
+		Symbol:        symbol,
+		Action:        "open_" + side,
+		Side:          side,
+		Quantity:      qty,
+		Price:         execPrice,
+		Fee:           fee,
+		OrderValue:    execPrice * qty,
+		Leverage:      pos.Leverage,
+		Cycle:         cycle,
+		PositionAfter: pos.Quantity,
+		Note:          note,
+	}, execPrice, nil
+}
+
+func (p *pairsSimulator) close(symbol, side string, price float64, ts int64, cycle int, note string) (TradeEvent, error) {
+	qty := p.legQty(symbol, side)
+	if qty <= epsilon {
+		return TradeEvent{}, fmt.Errorf("no %s %s position", symbol, side)
+	}
+	lev := p.account.positionLeverage(symbol, side)
+	realized, fee, execPrice, err := p.account.Close(symbol, side, qty, price)
+	if err != nil {
+		return TradeEvent{}, err
+	}
+	return TradeEvent{
+		Timestamp:     ts,
+		Symbol:        symbol,
+		Action:        "close_" + side,
+		Side:          side,
+		Quantity:      qty,
+		Price:         execPrice,
+		Fee:           fee,
+		OrderValue:    execPrice * qty,
+		RealizedPnL:   realized - fee,
+		Leverage:      lev,
+		Cycle:         cycle,
+		PositionAfter: p.legQty(symbol, side),
+		Note:          note,
+	}, nil
+}
+
+func (p *pairsSimulator) legQty(symbol, side string) float64 {
+	if pos, ok := p.account.positions[positionKey(symbol, side)]; ok {
+		return pos.Quantity
+	}
+	return 0
+}
+
+// stepPairs advances the pairs strategy by one bar using the klines the feed has loaded up to ts.
+func (r *Runner) stepPairs(ts int64, priceMap map[string]float64, cycle int) ([]TradeEvent, []string, error) {
+	cfg := r.cfg.PairsConfig
+	priceA, priceB := priceMap[cfg.SymbolA], priceMap[cfg.SymbolB]
+	if priceA <= 0 || priceB <= 0 {
+		return nil, nil, fmt.Errorf("price unavailable for %s / %s", cfg.SymbolA, cfg.SymbolB)
+	}
+
+	closesA, closesB := strategy.AlignPairsCloses(
+		r.feed.sliceUpTo(cfg.SymbolA, cfg.Timeframe, ts),
+		r.feed.sliceUpTo(cfg.SymbolB, cfg.Timeframe, ts),
+	)
+	stats, ok := strategy.PairsSpreadStats(closesA, closesB, cfg.LookbackBars)
+	if !ok {
+		return nil, nil, nil // Still warming up the window
+	}
+	events, notes := r.pairs.step(stats, priceA, priceB, ts, cycle)
+	return events, notes, nil
+}
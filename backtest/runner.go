@@ -35,6 +35,13 @@ type Runner struct {
 	feed           *DataFeed
 	account        *BacktestAccount
 	strategyEngine *kernel.StrategyEngine
+	grid           *gridSimulator       // Set for grid_trading runs instead of AI decisions
+	pairs          *pairsSimulator      // Set for pairs runs instead of AI decisions
+	replay         *liveReplaySimulator // Set for live replay runs instead of AI decisions
+	benchmarks     *benchmarkTracker
+	fills          *fillModel               // Set when fills are capped by bar volume
+	margin         *marginModel             // Set when liquidation follows maintenance margin tiers
+	trailing       map[string]*TrailingStop // Simulated trailing stops by position key
 
 	decisionLogDir string
 	mcpClient      mcp.AIClient
@@ -110,7 +117,11 @@ func NewRunner(cfg BacktestConfig, mcpClient mcp.AIClient) (*Runner, error) {
 		if cachePath == "" {
 			cachePath = filepath.Join(runDir(cfg.RunID), "ai_cache.json")
 		}
-		cache, err := LoadAICache(cachePath)
+		load := LoadAICache
+		if cfg.SharedAICachePath != "" {
+			load = loadSharedAICache
+		}
+		cache, err := load(cachePath)
 		if err != nil {
 			return nil, fmt.Errorf("load ai cache: %w", err)
 		}
@@ -139,6 +150,21 @@ func NewRunner(cfg BacktestConfig, mcpClient mcp.AIClient) (*Runner, error) {
 		cachePath:      cachePath,
 	}
 
+	if cfg.IsGrid() {
+		account.SetMakerFeeBps(cfg.MakerFeeBps)
+		r.grid = newGridSimulator(cfg.GridConfig, account)
+	}
+	if cfg.IsPairs() {
+		r.pairs = newPairsSimulator(cfg.PairsConfig, account)
+	}
+	if cfg.IsLiveReplay() {
+		r.replay = newLiveReplaySimulator(cfg.LiveReplay)
+	}
+	r.benchmarks = newBenchmarkTracker(cfg, feed)
+	r.fills = newFillModel(cfg, feed)
+	r.margin = newMarginModel(cfg.Margin)
+	account.SetMarginModel(r.margin)
+
 	if err := r.initLock(); err != nil {
 		return nil, err
 	}
@@ -297,6 +323,63 @@ func (r *Runner) stepOnce() error {
 
 	decisionAttempted := shouldDecide
 
+	if r.fills != nil {
+		// Remainders queued on earlier bars get this bar's volume before any new order
+		trades, notes := r.stepPendingFills(ts, priceMap)
+		tradeEvents = append(tradeEvents, trades...)
+		for _, note := range notes {
+			logger.Infof("📊 Backtest %s: %s", r.cfg.RunID, note)
+		}
+	}
+
+	if len(r.trailing) > 0 {
+		// Trailing stops are checked against this bar before any new decision
+		trades, notes := r.checkTrailingStops(ts, callCount)
+		tradeEvents = append(tradeEvents, trades...)
+		for _, note := range notes {
+			logger.Infof("📊 Backtest %s: %s", r.cfg.RunID, note)
+		}
+	}
+
+	if r.grid != nil {
+		// Grid runs replay the mechanical grid on every bar instead of asking the AI
+		trades, notes, err := r.stepGrid(ts, marketData, priceMap, callCount)
+		if err != nil {
+			return err
+		}
+		tradeEvents = append(tradeEvents, trades...)
+		for _, note := range notes {
+			logger.Infof("📊 Backtest %s: %s", r.cfg.RunID, note)
+		}
+		shouldDecide = false
+		decisionAttempted = true
+	}
+
+	if r.pairs != nil {
+		// Pairs runs recompute the spread on every bar and trade both legs together
+		trades, notes, err := r.stepPairs(ts, priceMap, callCount)
+		if err != nil {
+			return err
+		}
+		tradeEvents = append(tradeEvents, trades...)
+		for _, note := range notes {
+			logger.Infof("📊 Backtest %s: %s", r.cfg.RunID, note)
+		}
+		shouldDecide = false
+		decisionAttempted = true
+	}
+
+	if r.replay != nil {
+		// Live replay runs apply the live trader's recorded decisions as their timestamps are reached
+		trades, notes := r.stepLiveReplay(ts, priceMap, callCount)
+		tradeEvents = append(tradeEvents, trades...)
+		for _, note := range notes {
+			logger.Infof("📊 Backtest %s: %s", r.cfg.RunID, note)
+		}
+		shouldDecide = false
+		decisionAttempted = true
+	}
+
 	if shouldDecide {
 		ctx, rec, err := r.buildDecisionContext(ts, marketData, multiTF, priceMap, callCount)
 		if err != nil {
@@ -436,6 +519,7 @@ func (r *Runner) stepOnce() error {
 		PnLPct:      ((snapshot.Equity - r.account.InitialBalance()) / r.account.InitialBalance()) * 100,
 		DrawdownPct: drawdownPct,
 		Cycle:       snapshot.DecisionCycle,
+		Benchmarks:  r.benchmarks.valueAt(ts),
 	}
 
 	if err := appendEquityPoint(r.cfg.RunID, equityPoint); err != nil {
@@ -651,13 +735,18 @@ func (r *Runner) executeDecision(dec kernel.Decision, priceMap map[string]float6
 		if qty <= 0 {
 			return actionRecord, nil, "", fmt.Errorf("invalid qty")
 		}
-		pos, fee, execPrice, err := r.account.Open(symbol, "long", qty, usedLeverage, fillPrice, ts)
+		pos, fee, execPrice, filled, err := r.openOrder(symbol, "long", qty, usedLeverage, fillPrice, ts, cycle)
 		if err != nil {
 			return actionRecord, nil, "", err
 		}
+		if filled <= epsilon {
+			return actionRecord, nil, "no bar volume left, order queued", nil
+		}
+		qty = filled
 		actionRecord.Quantity = qty
 		actionRecord.Price = execPrice
 		actionRecord.Leverage = pos.Leverage
+		r.attachTrailingStop(symbol, "long", dec)
 		trade := TradeEvent{
 			Timestamp:     ts,
 			Symbol:        symbol,
@@ -680,13 +769,18 @@ func (r *Runner) executeDecision(dec kernel.Decision, priceMap map[string]float6
 		if qty <= 0 {
 			return actionRecord, nil, "", fmt.Errorf("invalid qty")
 		}
-		pos, fee, execPrice, err := r.account.Open(symbol, "short", qty, usedLeverage, fillPrice, ts)
+		pos, fee, execPrice, filled, err := r.openOrder(symbol, "short", qty, usedLeverage, fillPrice, ts, cycle)
 		if err != nil {
 			return actionRecord, nil, "", err
 		}
+		if filled <= epsilon {
+			return actionRecord, nil, "no bar volume left, order queued", nil
+		}
+		qty = filled
 		actionRecord.Quantity = qty
 		actionRecord.Price = execPrice
 		actionRecord.Leverage = pos.Leverage
+		r.attachTrailingStop(symbol, "short", dec)
 		trade := TradeEvent{
 			Timestamp:     ts,
 			Symbol:        symbol,
@@ -710,10 +804,14 @@ func (r *Runner) executeDecision(dec kernel.Decision, priceMap map[string]float6
 			return actionRecord, nil, "", fmt.Errorf("invalid close qty")
 		}
 		posLev := r.account.positionLeverage(symbol, "long")
-		realized, fee, execPrice, err := r.account.Close(symbol, "long", qty, fillPrice)
+		realized, fee, execPrice, filled, err := r.closeOrder(symbol, "long", qty, fillPrice, ts, cycle)
 		if err != nil {
 			return actionRecord, nil, "", err
 		}
+		if filled <= epsilon {
+			return actionRecord, nil, "no bar volume left, order queued", nil
+		}
+		qty = filled
 		actionRecord.Quantity = qty
 		actionRecord.Price = execPrice
 		actionRecord.Leverage = posLev
@@ -740,10 +838,14 @@ func (r *Runner) executeDecision(dec kernel.Decision, priceMap map[string]float6
 			return actionRecord, nil, "", fmt.Errorf("invalid close qty")
 		}
 		posLev := r.account.positionLeverage(symbol, "short")
-		realized, fee, execPrice, err := r.account.Close(symbol, "short", qty, fillPrice)
+		realized, fee, execPrice, filled, err := r.closeOrder(symbol, "short", qty, fillPrice, ts, cycle)
 		if err != nil {
 			return actionRecord, nil, "", err
 		}
+		if filled <= epsilon {
+			return actionRecord, nil, "no bar volume left, order queued", nil
+		}
+		qty = filled
 		actionRecord.Quantity = qty
 		actionRecord.Price = execPrice
 		actionRecord.Leverage = posLev
@@ -766,6 +868,12 @@ func (r *Runner) executeDecision(dec kernel.Decision, priceMap map[string]float6
 
 	case "hold", "wait":
 		return actionRecord, nil, fmt.Sprintf("hold position: %s", dec.Action), nil
+	case "set_trailing_stop":
+		attached, err := r.setTrailingStops(dec)
+		if err != nil {
+			return actionRecord, nil, "", err
+		}
+		return actionRecord, nil, fmt.Sprintf("trailing stop %.2f%% on %d position(s)", dec.TrailingCallbackRate, attached), nil
 	default:
 		return actionRecord, nil, "", fmt.Errorf("unsupported action %s", dec.Action)
 	}
@@ -919,6 +1027,11 @@ func (r *Runner) convertPositions(priceMap map[string]float64) []kernel.Position
 }
 
 func (r *Runner) executionPrice(symbol string, markPrice float64, ts int64) float64 {
+	if r.fills != nil {
+		if price, ok := r.fills.latencyPrice(symbol, ts); ok {
+			return price
+		}
+	}
 	curr, next := r.feed.decisionBarSnapshot(symbol, ts)
 	switch r.cfg.FillPolicy {
 	case FillPolicyNextOpen:
@@ -1035,6 +1148,9 @@ func (r *Runner) snapshotForCheckpoint(state BacktestState) []PositionSnapshot {
 }
 
 func (r *Runner) checkLiquidation(ts int64, priceMap map[string]float64, cycle int) ([]TradeEvent, string, error) {
+	if r.margin != nil {
+		return r.checkMarginLiquidation(ts, priceMap, cycle)
+	}
 	positions := append([]*position(nil), r.account.Positions()...)
 	events := make([]TradeEvent, 0)
 	var noteBuilder strings.Builder
@@ -1334,6 +1450,9 @@ func (r *Runner) persistMetrics(force bool) {
 	if metrics == nil {
 		return
 	}
+	if r.replay != nil {
+		metrics.LiveReplay = r.replay.stats()
+	}
 	if err := PersistMetrics(r.cfg.RunID, metrics); err != nil {
 		logger.Infof("failed to persist metrics for %s: %v", r.cfg.RunID, err)
 		return
@@ -1417,9 +1536,29 @@ func (r *Runner) buildCheckpointFromState(state BacktestState) *Checkpoint {
 		MinEquity:       state.MinEquity,
 		MaxDrawdownPct:  state.MaxDrawdownPct,
 		AICacheRef:      r.cachePath,
+		Grid:            r.gridSnapshot(),
+		Pairs:           r.pairsSnapshot(),
+		LiveReplay:      r.liveReplaySnapshot(),
+		Fills:           r.fillModelSnapshot(),
+		MarginCalls:     r.marginCallsSnapshot(),
+		TrailingStops:   r.trailingSnapshot(),
 	}
 }
 
+func (r *Runner) gridSnapshot() *GridSnapshot {
+	if r.grid == nil {
+		return nil
+	}
+	return r.grid.snapshot()
+}
+
+func (r *Runner) pairsSnapshot() *PairsSnapshot {
+	if r.pairs == nil {
+		return nil
+	}
+	return r.pairs.snapshot()
+}
+
 func (r *Runner) saveCheckpoint(state BacktestState) error {
 	ckpt := r.buildCheckpointFromState(state)
 	if ckpt == nil {
@@ -1452,6 +1591,25 @@ func (r *Runner) applyCheckpoint(ckpt *Checkpoint) error {
 		return fmt.Errorf("checkpoint is nil")
 	}
 	r.account.RestoreFromSnapshots(ckpt.Cash, ckpt.RealizedPnL, ckpt.Positions)
+	if r.grid != nil {
+		r.grid.restore(ckpt.Grid)
+	}
+	if r.pairs != nil {
+		r.pairs.restore(ckpt.Pairs)
+	}
+	if r.replay != nil {
+		r.replay.restore(ckpt.LiveReplay)
+	}
+	if r.fills != nil {
+		r.fills.restore(ckpt.Fills)
+	}
+	r.restoreTrailing(ckpt.TrailingStops)
+	if r.margin != nil {
+		r.margin.calls = make(map[string]bool, len(ckpt.MarginCalls))
+		for _, key := range ckpt.MarginCalls {
+			r.margin.calls[key] = true
+		}
+	}
 	r.stateMu.Lock()
 	defer r.stateMu.Unlock()
 	r.state.BarIndex = ckpt.BarIndex
@@ -344,6 +344,55 @@ func LoadRunIDs() ([]string, error) {
 	return runIDs, nil
 }
 
+// Multi-run analyses (sweeps, walk-forward) are stored beside the runs they schedule:
+// one JSON document each, in backtest_analyses or as <kind>_<id>.json in the backtests directory.
+const (
+	analysisSweep       = "sweep"
+	analysisWalkForward = "walkforward"
+)
+
+func analysisPath(kind, id string) string {
+	return filepath.Join(backtestsRootDir, kind+"_"+id+".json")
+}
+
+func saveAnalysis(kind, id string, v any) error {
+	if usingDB() {
+		return saveAnalysisDB(kind, id, v)
+	}
+	return writeJSONAtomic(analysisPath(kind, id), v)
+}
+
+// loadAnalysis decodes a stored analysis into v; a missing one returns os.ErrNotExist.
+func loadAnalysis(kind, id string, v any) error {
+	if usingDB() {
+		return loadAnalysisDB(kind, id, v)
+	}
+	data, err := os.ReadFile(analysisPath(kind, id))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func loadAnalysisIDs(kind string) ([]string, error) {
+	if usingDB() {
+		return loadAnalysisIDsDB(kind)
+	}
+	paths, err := filepath.Glob(filepath.Join(backtestsRootDir, kind+"_*.json"))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(paths))
+	for _, path := range paths {
+		id := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), kind+"_"), ".json")
+		if strings.HasSuffix(id, "_ai_cache") {
+			continue // The analysis' shared AI cache, not a status document
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 func loadJSONLines[T any](path string) ([]T, error) {
 	file, err := os.Open(path)
 	if err != nil {
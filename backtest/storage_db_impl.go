@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"nofx/store"
@@ -183,16 +184,24 @@ func loadRunIDsDB() ([]string, error) {
 }
 
 func appendEquityPointDB(runID string, point EquityPoint) error {
+	benchmarks := ""
+	if len(point.Benchmarks) > 0 {
+		data, err := json.Marshal(point.Benchmarks)
+		if err != nil {
+			return err
+		}
+		benchmarks = string(data)
+	}
 	_, err := persistenceDB.Exec(convertQuery(`
-		INSERT INTO backtest_equity (run_id, ts, equity, available, pnl, pnl_pct, dd_pct, cycle)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`), runID, point.Timestamp, point.Equity, point.Available, point.PnL, point.PnLPct, point.DrawdownPct, point.Cycle)
+		INSERT INTO backtest_equity (run_id, ts, equity, available, pnl, pnl_pct, dd_pct, cycle, benchmarks)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`), runID, point.Timestamp, point.Equity, point.Available, point.PnL, point.PnLPct, point.DrawdownPct, point.Cycle, benchmarks)
 	return err
 }
 
 func loadEquityPointsDB(runID string) ([]EquityPoint, error) {
 	rows, err := persistenceDB.Query(convertQuery(`
-		SELECT ts, equity, available, pnl, pnl_pct, dd_pct, cycle
+		SELECT ts, equity, available, pnl, pnl_pct, dd_pct, cycle, COALESCE(benchmarks, '')
 		FROM backtest_equity WHERE run_id = ? ORDER BY ts ASC
 	`), runID)
 	if err != nil {
@@ -202,9 +211,15 @@ func loadEquityPointsDB(runID string) ([]EquityPoint, error) {
 	points := make([]EquityPoint, 0)
 	for rows.Next() {
 		var point EquityPoint
-		if err := rows.Scan(&point.Timestamp, &point.Equity, &point.Available, &point.PnL, &point.PnLPct, &point.DrawdownPct, &point.Cycle); err != nil {
+		var benchmarks string
+		if err := rows.Scan(&point.Timestamp, &point.Equity, &point.Available, &point.PnL, &point.PnLPct, &point.DrawdownPct, &point.Cycle, &benchmarks); err != nil {
 			return nil, err
 		}
+		if benchmarks != "" {
+			if err := json.Unmarshal([]byte(benchmarks), &point.Benchmarks); err != nil {
+				return nil, err
+			}
+		}
 		points = append(points, point)
 	}
 	return points, rows.Err()
@@ -492,6 +507,48 @@ func listIndexEntriesDB() ([]RunIndexEntry, error) {
 	return entries, rows.Err()
 }
 
+func saveAnalysisDB(kind, id string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = persistenceDB.Exec(convertQuery(`
+		INSERT INTO backtest_analyses (id, kind, payload, created_at, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT(id) DO UPDATE SET payload=excluded.payload, updated_at=CURRENT_TIMESTAMP
+	`), kind+":"+id, kind, data)
+	return err
+}
+
+func loadAnalysisDB(kind, id string, v any) error {
+	var payload []byte
+	err := persistenceDB.QueryRow(convertQuery(`SELECT payload FROM backtest_analyses WHERE id = ?`), kind+":"+id).Scan(&payload)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return os.ErrNotExist
+		}
+		return err
+	}
+	return json.Unmarshal(payload, v)
+}
+
+func loadAnalysisIDsDB(kind string) ([]string, error) {
+	rows, err := persistenceDB.Query(convertQuery(`SELECT id FROM backtest_analyses WHERE kind = ? ORDER BY created_at DESC`), kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, strings.TrimPrefix(id, kind+":"))
+	}
+	return ids, rows.Err()
+}
+
 func deleteRunDB(runID string) error {
 	_, err := persistenceDB.Exec(convertQuery(`DELETE FROM backtest_runs WHERE run_id = ?`), runID)
 	return err
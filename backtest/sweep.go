@@ -0,0 +1,633 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nofx/logger"
+)
+
+// Sweep search modes
+const (
+	SweepModeGrid   = "grid"   // Every combination of the swept values
+	SweepModeRandom = "random" // A random sample of combinations
+)
+
+// Sweep ranking objectives
+const (
+	SweepObjectiveSharpe       = "sharpe"
+	SweepObjectiveReturnDD     = "return_dd" // Total return over max drawdown
+	SweepObjectiveProfitFactor = "profit_factor"
+)
+
+const (
+	defaultSweepConcurrency = 2
+	maxSweepConcurrency     = 8
+	defaultSweepSamples     = 20
+	maxSweepRuns            = 200
+	minSweepDrawdownPct     = 1.0 // Drawdowns below this count as this much in return_dd, so near-flat runs don't dominate
+)
+
+// SweepParams lists the values to try for each swept parameter; an empty list keeps the base config's value.
+type SweepParams struct {
+	BTCETHLeverage       []int             `json:"btc_eth_leverage,omitempty"`
+	AltcoinLeverage      []int             `json:"altcoin_leverage,omitempty"`
+	BTCETHPositionRatio  []float64         `json:"btc_eth_position_ratio,omitempty"`
+	AltcoinPositionRatio []float64         `json:"altcoin_position_ratio,omitempty"`
+	DecisionCadenceNBars []int             `json:"decision_cadence_nbars,omitempty"`
+	Timeframes           [][]string        `json:"timeframes,omitempty"`
+	PromptVariants       []string          `json:"prompt_variants,omitempty"`
+	Indicators           map[string][]bool `json:"indicators,omitempty"` // e.g. {"enable_rsi": [true, false]}
+}
+
+// SweepConfig describes a parameter sweep: one base config expanded into many child runs.
+type SweepConfig struct {
+	SweepID     string         `json:"sweep_id"`
+	UserID      string         `json:"user_id,omitempty"`
+	Base        BacktestConfig `json:"base"`
+	Params      SweepParams    `json:"params"`
+	Mode        string         `json:"mode"`              // grid | random
+	Samples     int            `json:"samples,omitempty"` // Combinations drawn in random mode
+	Seed        int64          `json:"seed,omitempty"`    // Random mode seed (0 = time based)
+	Concurrency int            `json:"concurrency,omitempty"`
+	Objective   string         `json:"objective"`
+}
+
+// SweepRunResult is one child run of a sweep and its score.
+type SweepRunResult struct {
+	RunID   string                 `json:"run_id"`
+	Params  map[string]interface{} `json:"params"`
+	State   RunState               `json:"state"`
+	Score   float64                `json:"score"`
+	Rank    int                    `json:"rank,omitempty"` // 1 = best; 0 until the run has metrics
+	Metrics *Metrics               `json:"metrics,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+// SweepStatus is the persisted state of a sweep with its child runs ranked by the objective.
+type SweepStatus struct {
+	SweepID     string           `json:"sweep_id"`
+	UserID      string           `json:"user_id,omitempty"`
+	Mode        string           `json:"mode"`
+	Objective   string           `json:"objective"`
+	Concurrency int              `json:"concurrency"`
+	State       RunState         `json:"state"`
+	Total       int              `json:"total"`
+	Finished    int              `json:"finished"`
+	Results     []SweepRunResult `json:"results"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// sweepRun tracks a sweep while its children are scheduled.
+type sweepRun struct {
+	mu        sync.Mutex
+	status    SweepStatus
+	ctx       context.Context
+	cancel    context.CancelFunc
+	cachePath string // Shared AI cache the children write to
+}
+
+// sweepDimension is one swept parameter with the values to try.
+type sweepDimension struct {
+	name   string
+	values []interface{}
+}
+
+func sweepCachePath(sweepID string) string {
+	return filepath.Join(backtestsRootDir, analysisSweep+"_"+sweepID+"_ai_cache.json")
+}
+
+// Validate checks the sweep and fills in defaults.
+func (sc *SweepConfig) Validate() error {
+	sc.SweepID = strings.TrimSpace(sc.SweepID)
+	if sc.SweepID == "" {
+		return fmt.Errorf("sweep_id cannot be empty")
+	}
+	if strings.ContainsAny(sc.SweepID, `/\`) {
+		return fmt.Errorf("invalid sweep_id")
+	}
+	if sc.Mode == "" {
+		sc.Mode = SweepModeGrid
+	}
+	if sc.Mode != SweepModeGrid && sc.Mode != SweepModeRandom {
+		return fmt.Errorf("unsupported sweep mode '%s'", sc.Mode)
+	}
+	if sc.Objective == "" {
+		sc.Objective = SweepObjectiveSharpe
+	}
+	switch sc.Objective {
+	case SweepObjectiveSharpe, SweepObjectiveReturnDD, SweepObjectiveProfitFactor:
+	default:
+		return fmt.Errorf("unsupported sweep objective '%s'", sc.Objective)
+	}
+	if sc.Concurrency <= 0 {
+		sc.Concurrency = defaultSweepConcurrency
+	}
+	if sc.Concurrency > maxSweepConcurrency {
+		sc.Concurrency = maxSweepConcurrency
+	}
+	for name := range sc.Params.Indicators {
+		if _, ok := sweepableIndicators[name]; !ok {
+			return fmt.Errorf("unsupported indicator toggle '%s'", name)
+		}
+	}
+	if len(sc.Params.dimensions()) == 0 {
+		return fmt.Errorf("sweep needs at least one parameter with values")
+	}
+
+	base := sc.Base
+	base.RunID = sc.SweepID
+	base.Symbols = append([]string(nil), sc.Base.Symbols...)
+	base.Timeframes = append([]string(nil), sc.Base.Timeframes...)
+	if sc.Base.GridConfig != nil {
+		grid := *sc.Base.GridConfig
+		base.GridConfig = &grid
+	}
+	if sc.Base.PairsConfig != nil {
+		pairs := *sc.Base.PairsConfig
+		base.PairsConfig = &pairs
+	}
+	if err := base.Validate(); err != nil {
+		return fmt.Errorf("invalid base config: %w", err)
+	}
+	return nil
+}
+
+// dimensions returns the swept parameters in a stable order.
+func (p SweepParams) dimensions() []sweepDimension {
+	var dims []sweepDimension
+	addInts := func(name string, values []int) {
+		if len(values) == 0 {
+			return
+		}
+		d := sweepDimension{name: name}
+		for _, v := range values {
+			d.values = append(d.values, v)
+		}
+		dims = append(dims, d)
+	}
+	addFloats := func(name string, values []float64) {
+		if len(values) == 0 {
+			return
+		}
+		d := sweepDimension{name: name}
+		for _, v := range values {
+			d.values = append(d.values, v)
+		}
+		dims = append(dims, d)
+	}
+
+	addInts("btc_eth_leverage", p.BTCETHLeverage)
+	addInts("altcoin_leverage", p.AltcoinLeverage)
+	addFloats("btc_eth_position_ratio", p.BTCETHPositionRatio)
+	addFloats("altcoin_position_ratio", p.AltcoinPositionRatio)
+	addInts("decision_cadence_nbars", p.DecisionCadenceNBars)
+	if len(p.Timeframes) > 0 {
+		d := sweepDimension{name: "timeframes"}
+		for _, tfs := range p.Timeframes {
+			d.values = append(d.values, append([]string(nil), tfs...))
+		}
+		dims = append(dims, d)
+	}
+	if len(p.PromptVariants) > 0 {
+		d := sweepDimension{name: "prompt_variant"}
+		for _, v := range p.PromptVariants {
+			d.values = append(d.values, v)
+		}
+		dims = append(dims, d)
+	}
+
+	names := make([]string, 0, len(p.Indicators))
+	for name, values := range p.Indicators {
+		if len(values) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d := sweepDimension{name: name}
+		for _, v := range p.Indicators[name] {
+			d.values = append(d.values, v)
+		}
+		dims = append(dims, d)
+	}
+	return dims
+}
+
+// expandSweep returns the parameter combinations to run: all of them in grid mode, a distinct sample in random mode.
+func expandSweep(sc *SweepConfig) ([]map[string]interface{}, error) {
+	dims := sc.Params.dimensions()
+	total := 1
+	for _, d := range dims {
+		total *= len(d.values)
+		if total > 1_000_000 {
+			break
+		}
+	}
+
+	var indices []int
+	switch sc.Mode {
+	case SweepModeRandom:
+		n := sc.Samples
+		if n <= 0 {
+			n = defaultSweepSamples
+		}
+		if n > maxSweepRuns {
+			return nil, fmt.Errorf("random sweep samples %d exceed limit %d", n, maxSweepRuns)
+		}
+		if n > total {
+			n = total
+		}
+		seed := sc.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		rng := rand.New(rand.NewSource(seed))
+		seen := make(map[int]bool, n)
+		for len(indices) < n {
+			idx := rng.Intn(total)
+			if !seen[idx] {
+				seen[idx] = true
+				indices = append(indices, idx)
+			}
+		}
+	default:
+		if total > maxSweepRuns {
+			return nil, fmt.Errorf("grid sweep expands to %d runs, limit is %d (use random mode)", total, maxSweepRuns)
+		}
+		for i := 0; i < total; i++ {
+			indices = append(indices, i)
+		}
+	}
+
+	combos := make([]map[string]interface{}, 0, len(indices))
+	for _, idx := range indices {
+		combo := make(map[string]interface{}, len(dims))
+		// Decode idx as a mixed-radix number, last dimension varying fastest
+		for i := len(dims) - 1; i >= 0; i-- {
+			n := len(dims[i].values)
+			combo[dims[i].name] = dims[i].values[idx%n]
+			idx /= n
+		}
+		combos = append(combos, combo)
+	}
+	return combos, nil
+}
+
+// sweepChildConfig builds a child run from the base config with one combination applied.
+func sweepChildConfig(sc *SweepConfig, index int, combo map[string]interface{}) BacktestConfig {
+	child := sc.Base
+	child.RunID = fmt.Sprintf("%s_%03d", sc.SweepID, index+1)
+	child.UserID = sc.UserID
+	child.Symbols = append([]string(nil), sc.Base.Symbols...)
+	child.Timeframes = append([]string(nil), sc.Base.Timeframes...)
+	child.ReplayOnly = false
+	// Children validate concurrently, so each gets its own copy of the configs Validate normalises in place
+	if sc.Base.GridConfig != nil {
+		grid := *sc.Base.GridConfig
+		child.GridConfig = &grid
+	}
+	if sc.Base.PairsConfig != nil {
+		pairs := *sc.Base.PairsConfig
+		child.PairsConfig = &pairs
+	}
+	child.CacheAI = true
+	if child.SharedAICachePath == "" {
+		child.SharedAICachePath = sweepCachePath(sc.SweepID)
+	}
+
+	overrides := StrategyOverrides{}
+	if sc.Base.Overrides != nil {
+		overrides = *sc.Base.Overrides
+	}
+	indicators := make(map[string]bool, len(overrides.Indicators))
+	for name, on := range overrides.Indicators {
+		indicators[name] = on
+	}
+
+	for name, value := range combo {
+		switch name {
+		case "btc_eth_leverage":
+			child.Leverage.BTCETHLeverage = value.(int)
+		case "altcoin_leverage":
+			child.Leverage.AltcoinLeverage = value.(int)
+		case "btc_eth_position_ratio":
+			overrides.BTCETHPositionRatio = value.(float64)
+		case "altcoin_position_ratio":
+			overrides.AltcoinPositionRatio = value.(float64)
+		case "decision_cadence_nbars":
+			child.DecisionCadenceNBars = value.(int)
+		case "timeframes":
+			child.Timeframes = append([]string(nil), value.([]string)...)
+			child.DecisionTimeframe = ""
+		case "prompt_variant":
+			child.PromptVariant = value.(string)
+		default:
+			indicators[name] = value.(bool)
+		}
+	}
+	if len(indicators) > 0 {
+		overrides.Indicators = indicators
+	}
+	child.Overrides = &overrides
+	return child
+}
+
+// SweepScore scores a run's metrics by the objective; higher is better.
+func SweepScore(m *Metrics, objective string) float64 {
+	if m == nil {
+		return 0
+	}
+	switch objective {
+	case SweepObjectiveReturnDD:
+		return m.TotalReturnPct / math.Max(m.MaxDrawdownPct, minSweepDrawdownPct)
+	case SweepObjectiveProfitFactor:
+		return m.ProfitFactor
+	default:
+		return m.SharpeRatio
+	}
+}
+
+// rankSweepResults orders results best first and numbers the scored ones.
+// Runs without metrics and liquidated runs sort after every scored run.
+func rankSweepResults(results []SweepRunResult) {
+	tier := func(r SweepRunResult) int {
+		switch {
+		case r.Metrics == nil:
+			return 2
+		case r.Metrics.Liquidated:
+			return 1
+		}
+		return 0
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		ti, tj := tier(results[i]), tier(results[j])
+		if ti != tj {
+			return ti < tj
+		}
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].RunID < results[j].RunID
+	})
+	rank := 0
+	for i := range results {
+		results[i].Rank = 0
+		if results[i].Metrics != nil {
+			rank++
+			results[i].Rank = rank
+		}
+	}
+}
+
+// StartSweep expands the sweep into child runs and schedules them in the background under the concurrency limit.
+func (m *Manager) StartSweep(ctx context.Context, sc SweepConfig) (*SweepStatus, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sw, children, err := m.newSweep(ctx, sc)
+	if err != nil {
+		return nil, err
+	}
+	go m.runSweep(sw, children)
+	return sw.snapshot(), nil
+}
+
+// newSweep validates and expands the sweep and registers it with the manager without starting any child.
+func (m *Manager) newSweep(ctx context.Context, sc SweepConfig) (*sweepRun, []BacktestConfig, error) {
+	if err := sc.Validate(); err != nil {
+		return nil, nil, err
+	}
+	combos, err := expandSweep(&sc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m.mu.Lock()
+	if _, exists := m.sweeps[sc.SweepID]; exists {
+		m.mu.Unlock()
+		return nil, nil, fmt.Errorf("sweep %s already exists", sc.SweepID)
+	}
+	if _, err := loadSweepStatus(sc.SweepID); err == nil {
+		m.mu.Unlock()
+		return nil, nil, fmt.Errorf("sweep %s already exists", sc.SweepID)
+	}
+
+	now := time.Now().UTC()
+	children := make([]BacktestConfig, len(combos))
+	sweepCtx, cancel := context.WithCancel(ctx)
+	sw := &sweepRun{
+		ctx:    sweepCtx,
+		cancel: cancel,
+		status: SweepStatus{
+			SweepID:     sc.SweepID,
+			UserID:      sc.UserID,
+			Mode:        sc.Mode,
+			Objective:   sc.Objective,
+			Concurrency: sc.Concurrency,
+			State:       RunStateRunning,
+			Total:       len(combos),
+			Results:     make([]SweepRunResult, len(combos)),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+	}
+	for i, combo := range combos {
+		children[i] = sweepChildConfig(&sc, i, combo)
+		sw.status.Results[i] = SweepRunResult{RunID: children[i].RunID, Params: combo, State: RunStateCreated}
+	}
+	if len(children) > 0 {
+		sw.cachePath = children[0].SharedAICachePath
+	}
+	m.sweeps[sc.SweepID] = sw
+	m.mu.Unlock()
+
+	if err := saveSweepStatus(sw.snapshot()); err != nil {
+		logger.Infof("failed to persist sweep %s: %v", sc.SweepID, err)
+	}
+	logger.Infof("📊 Sweep %s: %d runs (%s), concurrency %d, objective %s", sc.SweepID, len(children), sc.Mode, sc.Concurrency, sc.Objective)
+	return sw, children, nil
+}
+
+// runSweep runs the children with at most Concurrency active at once, then ranks them.
+// It blocks until every scheduled child has finished.
+func (m *Manager) runSweep(sw *sweepRun, children []BacktestConfig) {
+	ctx := sw.ctx
+	sem := make(chan struct{}, sw.status.Concurrency)
+	var wg sync.WaitGroup
+
+schedule:
+	for i, child := range children {
+		select {
+		case <-ctx.Done():
+			break schedule
+		case sem <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			<-sem
+			break schedule
+		}
+		wg.Add(1)
+		go func(i int, child BacktestConfig) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			m.runSweepChild(ctx, sw, i, child)
+		}(i, child)
+	}
+	wg.Wait()
+
+	sw.mu.Lock()
+	sw.status.State = RunStateCompleted
+	if ctx.Err() != nil {
+		sw.status.State = RunStateStopped
+		for i := range sw.status.Results {
+			if sw.status.Results[i].State == RunStateCreated {
+				sw.status.Results[i].State = RunStateStopped // Never scheduled
+			}
+		}
+	}
+	sw.status.UpdatedAt = time.Now().UTC()
+	rankSweepResults(sw.status.Results)
+	sw.mu.Unlock()
+	sw.cancel()
+
+	status := sw.snapshot()
+	if err := saveSweepStatus(status); err != nil {
+		logger.Infof("failed to persist sweep %s: %v", status.SweepID, err)
+	}
+	releaseSharedAICache(sw.cachePath)
+	m.mu.Lock()
+	delete(m.sweeps, status.SweepID)
+	m.mu.Unlock()
+	logger.Infof("📊 Sweep %s %s: %d/%d runs finished", status.SweepID, status.State, status.Finished, status.Total)
+}
+
+// runSweepChild runs one child to completion and records its score.
+func (m *Manager) runSweepChild(ctx context.Context, sw *sweepRun, index int, child BacktestConfig) {
+	sw.update(index, func(r *SweepRunResult) { r.State = RunStateRunning })
+
+	runner, err := m.Start(ctx, child)
+	if err != nil {
+		sw.update(index, func(r *SweepRunResult) {
+			r.State = RunStateFailed
+			r.Error = err.Error()
+		})
+		return
+	}
+	runErr := runner.Wait()
+	state := runner.Status()
+	metrics, metricsErr := LoadMetrics(child.RunID)
+
+	sw.update(index, func(r *SweepRunResult) {
+		r.State = state
+		if runErr != nil {
+			r.Error = runErr.Error()
+		}
+		if metricsErr == nil {
+			r.Metrics = metrics
+			r.Score = SweepScore(metrics, sw.status.Objective)
+		}
+	})
+	if err := saveSweepStatus(sw.snapshot()); err != nil {
+		logger.Infof("failed to persist sweep %s: %v", sw.status.SweepID, err)
+	}
+}
+
+func (sw *sweepRun) update(index int, fn func(*SweepRunResult)) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	fn(&sw.status.Results[index])
+	finished := 0
+	for _, r := range sw.status.Results {
+		if r.State != RunStateCreated && r.State != RunStateRunning {
+			finished++
+		}
+	}
+	sw.status.Finished = finished
+	sw.status.UpdatedAt = time.Now().UTC()
+}
+
+// snapshot returns a ranked copy of the sweep status.
+func (sw *sweepRun) snapshot() *SweepStatus {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	status := sw.status
+	status.Results = append([]SweepRunResult(nil), sw.status.Results...)
+	rankSweepResults(status.Results)
+	return &status
+}
+
+// GetSweep returns a sweep's progress and ranked results.
+func (m *Manager) GetSweep(sweepID string) (*SweepStatus, error) {
+	m.mu.RLock()
+	sw, ok := m.sweeps[sweepID]
+	m.mu.RUnlock()
+	if ok {
+		return sw.snapshot(), nil
+	}
+	status, err := loadSweepStatus(sweepID)
+	if err != nil {
+		return nil, err
+	}
+	if status.State == RunStateRunning {
+		status.State = RunStateStopped // Interrupted by a restart
+	}
+	return status, nil
+}
+
+// ListSweeps returns all sweeps, newest first.
+func (m *Manager) ListSweeps() ([]*SweepStatus, error) {
+	ids, err := loadAnalysisIDs(analysisSweep)
+	if err != nil {
+		return nil, err
+	}
+	sweeps := make([]*SweepStatus, 0, len(ids))
+	for _, id := range ids {
+		if status, err := m.GetSweep(id); err == nil {
+			sweeps = append(sweeps, status)
+		}
+	}
+	sort.Slice(sweeps, func(i, j int) bool {
+		return sweeps[i].CreatedAt.After(sweeps[j].CreatedAt)
+	})
+	return sweeps, nil
+}
+
+// StopSweep stops scheduling new children and stops the running ones.
+func (m *Manager) StopSweep(sweepID string) error {
+	m.mu.RLock()
+	sw, ok := m.sweeps[sweepID]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("sweep %s is not running", sweepID)
+	}
+	sw.cancel()
+	for _, r := range sw.snapshot().Results {
+		if r.State == RunStateRunning {
+			if err := m.Stop(r.RunID); err != nil {
+				logger.Infof("failed to stop sweep run %s: %v", r.RunID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func saveSweepStatus(status *SweepStatus) error {
+	return saveAnalysis(analysisSweep, status.SweepID, status)
+}
+
+func loadSweepStatus(sweepID string) (*SweepStatus, error) {
+	var status SweepStatus
+	if err := loadAnalysis(analysisSweep, sweepID, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,159 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"nofx/store"
+)
+
+func TestExpandSweep(t *testing.T) {
+	params := SweepParams{
+		BTCETHLeverage: []int{2, 5},
+		PromptVariants: []string{"conservative", "balanced", "aggressive"},
+		Indicators:     map[string][]bool{"enable_rsi": {true, false}},
+	}
+
+	t.Run("grid runs every combination, last dimension fastest", func(t *testing.T) {
+		combos, err := expandSweep(&SweepConfig{Mode: SweepModeGrid, Params: params})
+		if err != nil {
+			t.Fatalf("expandSweep: %v", err)
+		}
+		if len(combos) != 12 {
+			t.Fatalf("Expected 2x3x2 = 12 combinations, got %d", len(combos))
+		}
+		first, second, last := combos[0], combos[1], combos[11]
+		if first["btc_eth_leverage"] != 2 || first["prompt_variant"] != "conservative" || first["enable_rsi"] != true {
+			t.Errorf("Unexpected first combination %v", first)
+		}
+		if second["btc_eth_leverage"] != 2 || second["prompt_variant"] != "conservative" || second["enable_rsi"] != false {
+			t.Errorf("Expected the indicator toggle to vary fastest, got %v", second)
+		}
+		if last["btc_eth_leverage"] != 5 || last["prompt_variant"] != "aggressive" || last["enable_rsi"] != false {
+			t.Errorf("Unexpected last combination %v", last)
+		}
+		seen := make(map[string]bool)
+		for _, c := range combos {
+			seen[fmt.Sprint(c)] = true
+		}
+		if len(seen) != 12 {
+			t.Errorf("Expected 12 distinct combinations, got %d", len(seen))
+		}
+	})
+
+	t.Run("grid over the run limit is refused", func(t *testing.T) {
+		big := SweepParams{
+			BTCETHLeverage:       []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			AltcoinLeverage:      []int{1, 2, 3, 4, 5},
+			DecisionCadenceNBars: []int{1, 2, 3, 4, 5},
+		}
+		if _, err := expandSweep(&SweepConfig{Mode: SweepModeGrid, Params: big}); err == nil {
+			t.Error("Expected 250 grid runs to exceed the limit")
+		}
+	})
+
+	t.Run("random draws distinct combinations reproducibly", func(t *testing.T) {
+		sc := &SweepConfig{Mode: SweepModeRandom, Params: params, Samples: 5, Seed: 42}
+		a, err := expandSweep(sc)
+		if err != nil {
+			t.Fatalf("expandSweep: %v", err)
+		}
+		b, _ := expandSweep(sc)
+		if len(a) != 5 || fmt.Sprint(a) != fmt.Sprint(b) {
+			t.Fatalf("Expected the same 5 combinations for one seed, got %v and %v", a, b)
+		}
+		seen := make(map[string]bool)
+		for _, c := range a {
+			seen[fmt.Sprint(c)] = true
+		}
+		if len(seen) != 5 {
+			t.Errorf("Expected 5 distinct combinations, got %v", a)
+		}
+	})
+
+	t.Run("random samples are capped at the grid size", func(t *testing.T) {
+		combos, err := expandSweep(&SweepConfig{Mode: SweepModeRandom, Params: params, Samples: 50, Seed: 7})
+		if err != nil {
+			t.Fatalf("expandSweep: %v", err)
+		}
+		if len(combos) != 12 {
+			t.Errorf("Expected all 12 combinations, got %d", len(combos))
+		}
+		if _, err := expandSweep(&SweepConfig{Mode: SweepModeRandom, Params: params, Samples: maxSweepRuns + 1}); err == nil {
+			t.Error("Expected samples above the run limit to be refused")
+		}
+	})
+}
+
+func TestRankSweepResults(t *testing.T) {
+	results := []SweepRunResult{
+		{RunID: "s_001", Score: 1.2, Metrics: &Metrics{}},
+		{RunID: "s_002"}, // Still running
+		{RunID: "s_003", Score: 3.0, Metrics: &Metrics{Liquidated: true}},
+		{RunID: "s_004", Score: 2.5, Metrics: &Metrics{}},
+		{RunID: "s_005", Score: 1.2, Metrics: &Metrics{}},
+		{RunID: "s_006", Score: -0.4, Metrics: &Metrics{}},
+	}
+	rankSweepResults(results)
+
+	want := []struct {
+		runID string
+		rank  int
+	}{
+		{"s_004", 1},
+		{"s_001", 2}, // Ties break on run ID
+		{"s_005", 3},
+		{"s_006", 4},
+		{"s_003", 5}, // Liquidated after every healthy run, whatever its score
+		{"s_002", 0}, // No metrics: last and unranked
+	}
+	for i, w := range want {
+		if results[i].RunID != w.runID || results[i].Rank != w.rank {
+			t.Errorf("Position %d: expected %s ranked %d, got %s ranked %d", i, w.runID, w.rank, results[i].RunID, results[i].Rank)
+		}
+	}
+}
+
+func TestSweepStatusStorage(t *testing.T) {
+	check := func(t *testing.T) {
+		for _, id := range []string{"alpha", "beta"} {
+			if err := saveSweepStatus(&SweepStatus{SweepID: id, State: RunStateCompleted, Total: 2}); err != nil {
+				t.Fatalf("saveSweepStatus: %v", err)
+			}
+		}
+		if err := saveSweepStatus(&SweepStatus{SweepID: "beta", State: RunStateCompleted, Total: 3}); err != nil {
+			t.Fatalf("saveSweepStatus: %v", err)
+		}
+		status, err := loadSweepStatus("beta")
+		if err != nil || status.SweepID != "beta" || status.Total != 3 {
+			t.Fatalf("Expected the updated beta sweep back, got %+v (err %v)", status, err)
+		}
+		if _, err := loadSweepStatus("gamma"); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected os.ErrNotExist for an unknown sweep, got %v", err)
+		}
+		ids, err := loadAnalysisIDs(analysisSweep)
+		sort.Strings(ids)
+		if err != nil || fmt.Sprint(ids) != "[alpha beta]" {
+			t.Errorf("Expected sweeps [alpha beta], got %v (err %v)", ids, err)
+		}
+	}
+
+	t.Run("files", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		check(t)
+	})
+
+	t.Run("database", func(t *testing.T) {
+		st, err := store.New(filepath.Join(t.TempDir(), "backtest.db"))
+		if err != nil {
+			t.Fatalf("store.New: %v", err)
+		}
+		UseDatabaseWithType(st.DB(), false)
+		t.Cleanup(func() { UseDatabaseWithType(nil, false) })
+		check(t)
+	})
+}
@@ -0,0 +1,161 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"nofx/kernel"
+)
+
+// TrailingStop is a simulated trailing stop on one backtest position, as the live trader emulates it in software.
+type TrailingStop struct {
+	CallbackRate    float64 `json:"callback_rate"`              // Percent from the best price
+	ActivationPrice float64 `json:"activation_price,omitempty"` // 0 = trail from the start
+	Activated       bool    `json:"activated"`
+	BestPrice       float64 `json:"best_price"` // Highest high (long) or lowest low (short) since activation
+	StopPrice       float64 `json:"stop_price"` // 0 until activated
+	OpenTime        int64   `json:"open_time"`  // Entry of the position it protects; a reopened position starts without one
+}
+
+// attachTrailingStop starts trailing the position of symbol/side at the decision's callback rate.
+func (r *Runner) attachTrailingStop(symbol, side string, dec kernel.Decision) {
+	pos, ok := r.account.positions[positionKey(symbol, side)]
+	if !ok || dec.TrailingCallbackRate <= 0 {
+		return
+	}
+	if r.trailing == nil {
+		r.trailing = make(map[string]*TrailingStop)
+	}
+	r.trailing[positionKey(symbol, side)] = &TrailingStop{
+		CallbackRate:    dec.TrailingCallbackRate,
+		ActivationPrice: dec.TrailingActivationPrice,
+		OpenTime:        pos.OpenTime,
+	}
+}
+
+// setTrailingStops handles set_trailing_stop: every open side of the symbol starts trailing.
+func (r *Runner) setTrailingStops(dec kernel.Decision) (int, error) {
+	if dec.TrailingCallbackRate <= 0 {
+		return 0, fmt.Errorf("set_trailing_stop requires trailing_callback_rate")
+	}
+	attached := 0
+	for _, side := range []string{"long", "short"} {
+		if r.remainingPosition(dec.Symbol, side) <= epsilon {
+			continue
+		}
+		r.attachTrailingStop(dec.Symbol, side, dec)
+		attached++
+	}
+	if attached == 0 {
+		return 0, fmt.Errorf("no open position for %s", dec.Symbol)
+	}
+	return attached, nil
+}
+
+// checkTrailingStops closes positions whose trailing stop was hit in the bar closing at ts, then ratchets the rest.
+// The stop is tested against the level carried in from earlier bars before the bar's favourable extreme moves it,
+// since the order of high and low inside a bar is unknown; a bar that gaps through the stop fills at its open.
+func (r *Runner) checkTrailingStops(ts int64, cycle int) ([]TradeEvent, []string) {
+	keys := make([]string, 0, len(r.trailing))
+	for key := range r.trailing {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var events []TradeEvent
+	var notes []string
+	for _, key := range keys {
+		stop := r.trailing[key]
+		pos, ok := r.account.positions[key]
+		if !ok || pos.Quantity <= epsilon || pos.OpenTime != stop.OpenTime {
+			delete(r.trailing, key) // Closed by a decision, liquidated, or reopened since
+			continue
+		}
+		bar, _ := r.feed.decisionBarSnapshot(pos.Symbol, ts)
+		if bar == nil || bar.OpenTime < pos.OpenTime {
+			continue // Only bars that started after the entry can move or trigger the stop
+		}
+
+		if stop.Activated {
+			price, hit := 0.0, false
+			if pos.Side == "long" && bar.Low <= stop.StopPrice {
+				price, hit = math.Min(stop.StopPrice, bar.Open), true
+			} else if pos.Side == "short" && bar.High >= stop.StopPrice {
+				price, hit = math.Max(stop.StopPrice, bar.Open), true
+			}
+			if hit {
+				qty, lev := pos.Quantity, pos.Leverage
+				realized, fee, execPrice, err := r.account.Close(pos.Symbol, pos.Side, qty, price)
+				if err != nil {
+					notes = append(notes, fmt.Sprintf("%s %s trailing stop close failed: %v", pos.Symbol, pos.Side, err))
+					continue
+				}
+				delete(r.trailing, key)
+				events = append(events, TradeEvent{
+					Timestamp:     ts,
+					Symbol:        pos.Symbol,
+					Action:        "close_" + pos.Side,
+					Side:          pos.Side,
+					Quantity:      qty,
+					Price:         execPrice,
+					Fee:           fee,
+					OrderValue:    execPrice * qty,
+					RealizedPnL:   realized - fee,
+					Leverage:      lev,
+					Cycle:         cycle,
+					PositionAfter: r.remainingPosition(pos.Symbol, pos.Side),
+					Note:          "trailing stop",
+				})
+				notes = append(notes, fmt.Sprintf("%s %s closed by trailing stop at %.4f", pos.Symbol, pos.Side, execPrice))
+				continue
+			}
+		}
+		stop.ratchet(pos.Side, bar.High, bar.Low)
+	}
+	return events, notes
+}
+
+// ratchet activates the stop once the activation price trades and trails it behind the bar's favourable extreme.
+func (s *TrailingStop) ratchet(side string, high, low float64) {
+	best := high
+	if side == "short" {
+		best = low
+	}
+	if !s.Activated {
+		if s.ActivationPrice > 0 && ((side == "long" && high < s.ActivationPrice) || (side == "short" && low > s.ActivationPrice)) {
+			return
+		}
+		s.Activated = true
+		s.BestPrice = best
+	}
+	if side == "long" {
+		s.BestPrice = math.Max(s.BestPrice, best)
+		s.StopPrice = math.Max(s.StopPrice, s.BestPrice*(1-s.CallbackRate/100))
+		return
+	}
+	s.BestPrice = math.Min(s.BestPrice, best)
+	stop := s.BestPrice * (1 + s.CallbackRate/100)
+	if s.StopPrice == 0 || stop < s.StopPrice {
+		s.StopPrice = stop
+	}
+}
+
+func (r *Runner) trailingSnapshot() map[string]TrailingStop {
+	if len(r.trailing) == 0 {
+		return nil
+	}
+	snap := make(map[string]TrailingStop, len(r.trailing))
+	for key, stop := range r.trailing {
+		snap[key] = *stop
+	}
+	return snap
+}
+
+func (r *Runner) restoreTrailing(snap map[string]TrailingStop) {
+	r.trailing = make(map[string]*TrailingStop, len(snap))
+	for key, stop := range snap {
+		stop := stop
+		r.trailing[key] = &stop
+	}
+}
@@ -0,0 +1,100 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+
+	"nofx/kernel"
+	"nofx/market"
+)
+
+// testFeed builds a data feed over in-memory klines on one timeframe.
+func testFeed(tf string, series map[string][]market.Kline) *DataFeed {
+	df := &DataFeed{symbolSeries: make(map[string]*symbolSeries), primaryTF: tf}
+	for symbol, klines := range series {
+		ts := &timeframeSeries{klines: klines}
+		for _, k := range klines {
+			ts.closeTimes = append(ts.closeTimes, k.CloseTime)
+		}
+		df.symbols = append(df.symbols, symbol)
+		df.symbolSeries[symbol] = &symbolSeries{byTF: map[string]*timeframeSeries{tf: ts}}
+	}
+	return df
+}
+
+// testBars builds consecutive one-minute bars from open/high/low/close rows, starting at 0.
+func testBars(rows ...[4]float64) []market.Kline {
+	bars := make([]market.Kline, len(rows))
+	for i, row := range rows {
+		open := int64(i) * 60_000
+		bars[i] = market.Kline{OpenTime: open, CloseTime: open + 59_999, Open: row[0], High: row[1], Low: row[2], Close: row[3], Volume: 1000}
+	}
+	return bars
+}
+
+func TestTrailingStopRatchet(t *testing.T) {
+	long := TrailingStop{CallbackRate: 2, ActivationPrice: 105}
+	long.ratchet("long", 104, 99)
+	if long.Activated {
+		t.Fatalf("Long stop activated before its activation price traded")
+	}
+	long.ratchet("long", 110, 104)
+	if !long.Activated || math.Abs(long.StopPrice-107.8) > 1e-9 {
+		t.Fatalf("Expected long stop at 107.8 after activation, got %+v", long)
+	}
+	long.ratchet("long", 108, 100)
+	if math.Abs(long.StopPrice-107.8) > 1e-9 {
+		t.Errorf("Long stop must never move down, got %.4f", long.StopPrice)
+	}
+
+	short := TrailingStop{CallbackRate: 1}
+	short.ratchet("short", 101, 100)
+	short.ratchet("short", 99, 95)
+	if math.Abs(short.StopPrice-95.95) > 1e-9 {
+		t.Errorf("Expected short stop at 95.95, got %.4f", short.StopPrice)
+	}
+	short.ratchet("short", 97, 96)
+	if math.Abs(short.StopPrice-95.95) > 1e-9 {
+		t.Errorf("Short stop must never move up, got %.4f", short.StopPrice)
+	}
+}
+
+func TestCheckTrailingStops(t *testing.T) {
+	bars := testBars(
+		[4]float64{100, 100, 100, 100}, // Entry bar
+		[4]float64{100, 110, 100, 109}, // Stop trails to 107.8
+		[4]float64{109, 112, 108, 111}, // Not hit (low 108 > 107.8); trails to 109.76
+		[4]float64{105, 106, 100, 101}, // Gaps through 109.76: fills at the open
+	)
+	r := &Runner{
+		feed:    testFeed("1m", map[string][]market.Kline{"BTCUSDT": bars}),
+		account: NewBacktestAccount(10_000, 0, 0),
+	}
+	if _, _, _, err := r.account.Open("BTCUSDT", "long", 1, 1, 100, bars[0].CloseTime); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := r.setTrailingStops(kernel.Decision{Symbol: "BTCUSDT", Action: "set_trailing_stop", TrailingCallbackRate: 2}); err != nil {
+		t.Fatalf("setTrailingStops: %v", err)
+	}
+
+	for i := 1; i < 3; i++ {
+		if events, _ := r.checkTrailingStops(bars[i].CloseTime, i); len(events) != 0 {
+			t.Fatalf("Bar %d: unexpected close %+v", i, events)
+		}
+	}
+	if stop := r.trailing[positionKey("BTCUSDT", "long")]; math.Abs(stop.StopPrice-109.76) > 1e-9 {
+		t.Fatalf("Expected stop at 109.76, got %.4f", stop.StopPrice)
+	}
+
+	events, _ := r.checkTrailingStops(bars[3].CloseTime, 3)
+	if len(events) != 1 || events[0].Action != "close_long" || events[0].Price != 105 {
+		t.Fatalf("Expected the gap bar to close the long at its open 105, got %+v", events)
+	}
+	if r.remainingPosition("BTCUSDT", "long") > epsilon || len(r.trailing) != 0 {
+		t.Errorf("Position and trailing stop should be gone after the stop fill")
+	}
+
+	if _, err := r.setTrailingStops(kernel.Decision{Symbol: "BTCUSDT", TrailingCallbackRate: 2}); err == nil {
+		t.Errorf("Expected an error trailing a symbol with no position")
+	}
+}
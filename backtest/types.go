@@ -56,6 +56,8 @@ type EquityPoint struct {
 	PnLPct      float64 `json:"pnl_pct"`
 	DrawdownPct float64 `json:"dd_pct"`
 	Cycle       int     `json:"cycle"`
+
+	Benchmarks map[string]float64 `json:"benchmarks,omitempty"` // Benchmark equity at this bar, by benchmark name
 }
 
 // TradeEvent records a trade execution result or special event (such as liquidation).
@@ -81,7 +83,7 @@ type TradeEvent struct {
 type Metrics struct {
 	TotalReturnPct float64                  `json:"total_return_pct"`
 	MaxDrawdownPct float64                  `json:"max_drawdown_pct"`
-	SharpeRatio    float64                  `json:"sharpe_ratio"`
+	SharpeRatio    float64                  `json:"sharpe_ratio"` // Per closed position and not annualized, as in the live trader stats
 	ProfitFactor   float64                  `json:"profit_factor"`
 	WinRate        float64                  `json:"win_rate"`
 	Trades         int                      `json:"trades"`
@@ -91,6 +93,39 @@ type Metrics struct {
 	WorstSymbol    string                   `json:"worst_symbol"`
 	SymbolStats    map[string]SymbolMetrics `json:"symbol_stats"`
 	Liquidated     bool                     `json:"liquidated"`
+
+	SortinoRatio         float64                     `json:"sortino_ratio"`
+	CalmarRatio          float64                     `json:"calmar_ratio"` // Annualized return over max drawdown
+	Expectancy           float64                     `json:"expectancy"`   // Average net PnL per closed position
+	AvgHoldingMins       float64                     `json:"avg_holding_mins"`
+	MaxHoldingMins       float64                     `json:"max_holding_mins"`
+	TimeInMarketPct      float64                     `json:"time_in_market_pct"`
+	MaxConsecutiveLosses int                         `json:"max_consecutive_losses"`
+	TotalFees            float64                     `json:"total_fees"`
+	TotalSlippage        float64                     `json:"total_slippage"`
+	FeeDragPct           float64                     `json:"fee_drag_pct"`      // Fees as a percentage of the initial balance
+	SlippageDragPct      float64                     `json:"slippage_drag_pct"` // Slippage cost as a percentage of the initial balance
+	DirectionStats       map[string]DirectionMetrics `json:"direction_stats,omitempty"`
+	MonthlyReturns       []MonthlyReturn             `json:"monthly_returns,omitempty"`
+
+	Benchmarks map[string]BenchmarkMetrics `json:"benchmarks,omitempty"`
+	LiveReplay *LiveReplayStats            `json:"live_replay,omitempty"`
+}
+
+// DirectionMetrics records performance for long or short trades.
+type DirectionMetrics struct {
+	Trades   int     `json:"trades"`
+	WinRate  float64 `json:"win_rate"`
+	TotalPnL float64 `json:"total_pnl"`
+	AvgPnL   float64 `json:"avg_pnl"`
+}
+
+// MonthlyReturn is the equity change over one calendar month (UTC).
+type MonthlyReturn struct {
+	Month     string  `json:"month"` // YYYY-MM
+	ReturnPct float64 `json:"return_pct"`
+	PnL       float64 `json:"pnl"`
+	EndEquity float64 `json:"end_equity"`
 }
 
 // SymbolMetrics records performance for a single symbol.
@@ -121,6 +156,12 @@ type Checkpoint struct {
 	AICacheRef      string                    `json:"ai_cache_ref,omitempty"`
 	Liquidated      bool                      `json:"liquidated"`
 	LiquidationNote string                    `json:"liquidation_note,omitempty"`
+	Grid            *GridSnapshot             `json:"grid,omitempty"`
+	Pairs           *PairsSnapshot            `json:"pairs,omitempty"`
+	LiveReplay      *LiveReplaySnapshot       `json:"live_replay,omitempty"`
+	Fills           *FillModelSnapshot        `json:"fills,omitempty"`
+	MarginCalls     []string                  `json:"margin_calls,omitempty"` // Positions (or "*" for the cross account) under a margin call
+	TrailingStops   map[string]TrailingStop   `json:"trailing_stops,omitempty"`
 }
 
 // RunMetadata records the summary required for run.json.
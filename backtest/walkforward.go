@@ -0,0 +1,448 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nofx/logger"
+)
+
+const maxWalkForwardWindows = 24
+
+// WalkForwardConfig splits the base config's range into rolling in-sample/out-of-sample windows.
+// Each in-sample window is optimised with the sweep, and the winning parameters then run on the
+// out-of-sample window right after it.
+type WalkForwardConfig struct {
+	WalkForwardID   string      `json:"walk_forward_id"`
+	UserID          string      `json:"user_id,omitempty"`
+	Sweep           SweepConfig `json:"sweep"` // Base covers the full range; Params, Mode and Objective drive each optimisation
+	InSampleDays    int         `json:"in_sample_days"`
+	OutOfSampleDays int         `json:"out_of_sample_days"`
+	StepDays        int         `json:"step_days,omitempty"` // Defaults to OutOfSampleDays so out-of-sample windows tile the range
+	Anchored        bool        `json:"anchored,omitempty"`  // In-sample windows all start at StartTS and grow
+}
+
+// WalkForwardWindow is one in-sample optimisation and its out-of-sample run.
+type WalkForwardWindow struct {
+	Index            int                    `json:"index"`
+	InSampleStart    int64                  `json:"in_sample_start"`
+	InSampleEnd      int64                  `json:"in_sample_end"`
+	OutOfSampleStart int64                  `json:"out_of_sample_start"`
+	OutOfSampleEnd   int64                  `json:"out_of_sample_end"`
+	SweepID          string                 `json:"sweep_id"`
+	BestRunID        string                 `json:"best_run_id,omitempty"`
+	Params           map[string]interface{} `json:"params,omitempty"`
+	InSampleScore    float64                `json:"in_sample_score"`
+	RunID            string                 `json:"run_id"` // Out-of-sample run
+	State            RunState               `json:"state"`
+	Metrics          *Metrics               `json:"metrics,omitempty"` // Out-of-sample metrics
+	Error            string                 `json:"error,omitempty"`
+}
+
+// WalkForwardStatus is the persisted state of a walk-forward analysis with its stitched out-of-sample equity.
+type WalkForwardStatus struct {
+	WalkForwardID  string              `json:"walk_forward_id"`
+	UserID         string              `json:"user_id,omitempty"`
+	Objective      string              `json:"objective"`
+	State          RunState            `json:"state"`
+	InitialBalance float64             `json:"initial_balance"`
+	Total          int                 `json:"total"`
+	Finished       int                 `json:"finished"`
+	Windows        []WalkForwardWindow `json:"windows"`
+	Equity         []EquityPoint       `json:"equity"`
+	TotalReturnPct float64             `json:"total_return_pct"`
+	MaxDrawdownPct float64             `json:"max_drawdown_pct"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+// walkForwardRun tracks a walk-forward analysis while its windows run one after another.
+type walkForwardRun struct {
+	mu      sync.Mutex
+	status  WalkForwardStatus
+	sweep   SweepConfig
+	ctx     context.Context
+	cancel  context.CancelFunc
+	current string // Sweep or out-of-sample run in progress
+}
+
+func walkForwardCachePath(id string) string {
+	return filepath.Join(backtestsRootDir, analysisWalkForward+"_"+id+"_ai_cache.json")
+}
+
+// Validate checks the walk-forward settings and the sweep they wrap, and fills in defaults.
+func (wc *WalkForwardConfig) Validate() error {
+	wc.WalkForwardID = strings.TrimSpace(wc.WalkForwardID)
+	if wc.WalkForwardID == "" {
+		return fmt.Errorf("walk_forward_id cannot be empty")
+	}
+	if strings.ContainsAny(wc.WalkForwardID, `/\`) {
+		return fmt.Errorf("invalid walk_forward_id")
+	}
+	if wc.InSampleDays <= 0 || wc.OutOfSampleDays <= 0 {
+		return fmt.Errorf("in_sample_days and out_of_sample_days must be positive")
+	}
+	if wc.StepDays <= 0 {
+		wc.StepDays = wc.OutOfSampleDays
+	}
+
+	wc.Sweep.SweepID = wc.WalkForwardID
+	wc.Sweep.UserID = wc.UserID
+	if err := wc.Sweep.Validate(); err != nil {
+		return err
+	}
+	windows := walkForwardWindows(wc)
+	if len(windows) == 0 {
+		return fmt.Errorf("range is too short for a %d+%d day window", wc.InSampleDays, wc.OutOfSampleDays)
+	}
+	if len(windows) > maxWalkForwardWindows {
+		return fmt.Errorf("walk-forward splits into %d windows, limit is %d", len(windows), maxWalkForwardWindows)
+	}
+	return nil
+}
+
+// walkForwardWindows lays out the windows over the base range; a trailing partial out-of-sample window is dropped.
+func walkForwardWindows(wc *WalkForwardConfig) []WalkForwardWindow {
+	const day = int64(24 * 60 * 60)
+	start, end := wc.Sweep.Base.StartTS, wc.Sweep.Base.EndTS
+	inSample := int64(wc.InSampleDays) * day
+	outOfSample := int64(wc.OutOfSampleDays) * day
+	step := int64(wc.StepDays) * day
+
+	var windows []WalkForwardWindow
+	for offset := int64(0); start+offset+inSample+outOfSample <= end; offset += step {
+		w := WalkForwardWindow{
+			Index:            len(windows) + 1,
+			InSampleStart:    start + offset,
+			InSampleEnd:      start + offset + inSample,
+			OutOfSampleStart: start + offset + inSample,
+			OutOfSampleEnd:   start + offset + inSample + outOfSample,
+			State:            RunStateCreated,
+		}
+		if wc.Anchored {
+			w.InSampleStart = start
+		}
+		w.SweepID = fmt.Sprintf("%s_w%02d", wc.WalkForwardID, w.Index)
+		w.RunID = w.SweepID + "_oos"
+		windows = append(windows, w)
+		if len(windows) > maxWalkForwardWindows {
+			break
+		}
+	}
+	return windows
+}
+
+// StartWalkForward schedules the windows in the background; each window's sweep and out-of-sample run are regular runs.
+func (m *Manager) StartWalkForward(ctx context.Context, wc WalkForwardConfig) (*WalkForwardStatus, error) {
+	if err := wc.Validate(); err != nil {
+		return nil, err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	m.mu.Lock()
+	if _, exists := m.walkForwards[wc.WalkForwardID]; exists {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("walk-forward %s already exists", wc.WalkForwardID)
+	}
+	if _, err := loadWalkForwardStatus(wc.WalkForwardID); err == nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("walk-forward %s already exists", wc.WalkForwardID)
+	}
+
+	now := time.Now().UTC()
+	wfCtx, cancel := context.WithCancel(ctx)
+	wf := &walkForwardRun{
+		sweep:  wc.Sweep,
+		ctx:    wfCtx,
+		cancel: cancel,
+		status: WalkForwardStatus{
+			WalkForwardID:  wc.WalkForwardID,
+			UserID:         wc.UserID,
+			Objective:      wc.Sweep.Objective,
+			State:          RunStateRunning,
+			InitialBalance: wc.Sweep.Base.InitialBalance,
+			Windows:        walkForwardWindows(&wc),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		},
+	}
+	wf.status.Total = len(wf.status.Windows)
+	// Every window shares one AI cache, so overlapping in-sample ranges reuse decisions
+	if wf.sweep.Base.SharedAICachePath == "" {
+		wf.sweep.Base.SharedAICachePath = walkForwardCachePath(wc.WalkForwardID)
+	}
+	m.walkForwards[wc.WalkForwardID] = wf
+	m.mu.Unlock()
+
+	if err := saveWalkForwardStatus(wf.snapshot()); err != nil {
+		logger.Infof("failed to persist walk-forward %s: %v", wc.WalkForwardID, err)
+	}
+	logger.Infof("📊 Walk-forward %s: %d windows (%d/%d days, step %d)",
+		wc.WalkForwardID, wf.status.Total, wc.InSampleDays, wc.OutOfSampleDays, wc.StepDays)
+
+	go m.runWalkForward(wf)
+	return wf.snapshot(), nil
+}
+
+// runWalkForward optimises and then trades each window in turn, stitching the out-of-sample equity as it goes.
+func (m *Manager) runWalkForward(wf *walkForwardRun) {
+	for i := range wf.status.Windows {
+		if wf.ctx.Err() != nil {
+			break
+		}
+		m.runWalkForwardWindow(wf, i)
+		if err := saveWalkForwardStatus(wf.snapshot()); err != nil {
+			logger.Infof("failed to persist walk-forward %s: %v", wf.status.WalkForwardID, err)
+		}
+	}
+
+	wf.mu.Lock()
+	wf.status.State = RunStateCompleted
+	if wf.ctx.Err() != nil {
+		wf.status.State = RunStateStopped
+		for i := range wf.status.Windows {
+			if wf.status.Windows[i].State == RunStateCreated {
+				wf.status.Windows[i].State = RunStateStopped
+			}
+		}
+	}
+	wf.status.UpdatedAt = time.Now().UTC()
+	wf.mu.Unlock()
+	wf.cancel()
+
+	status := wf.snapshot()
+	if err := saveWalkForwardStatus(status); err != nil {
+		logger.Infof("failed to persist walk-forward %s: %v", status.WalkForwardID, err)
+	}
+	releaseSharedAICache(wf.sweep.Base.SharedAICachePath)
+	m.mu.Lock()
+	delete(m.walkForwards, status.WalkForwardID)
+	m.mu.Unlock()
+	logger.Infof("📊 Walk-forward %s %s: %d/%d windows, out-of-sample return %.2f%%",
+		status.WalkForwardID, status.State, status.Finished, status.Total, status.TotalReturnPct)
+}
+
+// runWalkForwardWindow sweeps the in-sample range, then runs the best parameters out of sample.
+func (m *Manager) runWalkForwardWindow(wf *walkForwardRun, index int) {
+	window := wf.window(index)
+	wf.updateWindow(index, func(w *WalkForwardWindow) { w.State = RunStateRunning })
+	fail := func(state RunState, msg string) {
+		wf.updateWindow(index, func(w *WalkForwardWindow) {
+			w.State = state
+			w.Error = msg
+		})
+	}
+
+	sc := wf.sweep
+	sc.SweepID = window.SweepID
+	sc.Base.StartTS = window.InSampleStart
+	sc.Base.EndTS = window.InSampleEnd
+	sw, children, err := m.newSweep(wf.ctx, sc)
+	if err != nil {
+		fail(RunStateFailed, fmt.Sprintf("in-sample sweep: %v", err))
+		return
+	}
+	wf.setCurrent(sc.SweepID)
+	m.runSweep(sw, children)
+	if wf.ctx.Err() != nil {
+		fail(RunStateStopped, "")
+		return
+	}
+
+	var best *SweepRunResult
+	for _, r := range sw.snapshot().Results {
+		if r.Metrics != nil && !r.Metrics.Liquidated {
+			best = &r
+			break
+		}
+	}
+	if best == nil {
+		fail(RunStateFailed, "no in-sample run produced usable metrics")
+		return
+	}
+	wf.updateWindow(index, func(w *WalkForwardWindow) {
+		w.BestRunID = best.RunID
+		w.Params = best.Params
+		w.InSampleScore = best.Score
+	})
+
+	child := sweepChildConfig(&sc, 0, best.Params)
+	child.RunID = window.RunID
+	child.StartTS = window.OutOfSampleStart
+	child.EndTS = window.OutOfSampleEnd
+	runner, err := m.Start(wf.ctx, child)
+	if err != nil {
+		fail(RunStateFailed, fmt.Sprintf("out-of-sample run: %v", err))
+		return
+	}
+	wf.setCurrent(child.RunID)
+	runErr := runner.Wait()
+	state := runner.Status()
+	metrics, _ := LoadMetrics(child.RunID)
+	points, err := LoadEquityPoints(child.RunID)
+	if err != nil {
+		logger.Infof("walk-forward %s: failed to load equity for %s: %v", wf.status.WalkForwardID, child.RunID, err)
+	}
+
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	w := &wf.status.Windows[index]
+	w.State = state
+	w.Metrics = metrics
+	if runErr != nil {
+		w.Error = runErr.Error()
+	}
+	wf.stitch(points, child.InitialBalance)
+	wf.status.Finished++
+	wf.status.UpdatedAt = time.Now().UTC()
+}
+
+// stitch appends an out-of-sample equity curve, rescaled so it starts where the previous window ended.
+// Callers hold wf.mu.
+func (wf *walkForwardRun) stitch(points []EquityPoint, windowBalance float64) {
+	initial := wf.status.InitialBalance
+	if len(points) == 0 || windowBalance <= 0 || initial <= 0 {
+		return
+	}
+	startEquity := initial
+	peak := initial
+	cycleOffset := 0
+	if n := len(wf.status.Equity); n > 0 {
+		last := wf.status.Equity[n-1]
+		startEquity = last.Equity
+		cycleOffset = last.Cycle
+		for _, p := range wf.status.Equity {
+			peak = math.Max(peak, p.Equity)
+		}
+	}
+
+	scale := startEquity / windowBalance
+	for _, p := range points {
+		equity := p.Equity * scale
+		peak = math.Max(peak, equity)
+		point := EquityPoint{
+			Timestamp: p.Timestamp,
+			Equity:    equity,
+			Available: p.Available * scale,
+			PnL:       equity - initial,
+			PnLPct:    (equity - initial) / initial * 100,
+			Cycle:     p.Cycle + cycleOffset,
+		}
+		if peak > 0 {
+			point.DrawdownPct = (peak - equity) / peak * 100
+		}
+		wf.status.MaxDrawdownPct = math.Max(wf.status.MaxDrawdownPct, point.DrawdownPct)
+		wf.status.Equity = append(wf.status.Equity, point)
+	}
+	wf.status.TotalReturnPct = wf.status.Equity[len(wf.status.Equity)-1].PnLPct
+}
+
+func (wf *walkForwardRun) window(index int) WalkForwardWindow {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	return wf.status.Windows[index]
+}
+
+func (wf *walkForwardRun) updateWindow(index int, fn func(*WalkForwardWindow)) {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	fn(&wf.status.Windows[index])
+	wf.status.UpdatedAt = time.Now().UTC()
+}
+
+func (wf *walkForwardRun) setCurrent(id string) {
+	wf.mu.Lock()
+	wf.current = id
+	wf.mu.Unlock()
+}
+
+// snapshot returns a copy of the walk-forward status.
+func (wf *walkForwardRun) snapshot() *WalkForwardStatus {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+	status := wf.status
+	status.Windows = append([]WalkForwardWindow(nil), wf.status.Windows...)
+	status.Equity = append([]EquityPoint(nil), wf.status.Equity...)
+	return &status
+}
+
+// GetWalkForward returns a walk-forward analysis with its windows and stitched equity.
+func (m *Manager) GetWalkForward(id string) (*WalkForwardStatus, error) {
+	m.mu.RLock()
+	wf, ok := m.walkForwards[id]
+	m.mu.RUnlock()
+	if ok {
+		return wf.snapshot(), nil
+	}
+	status, err := loadWalkForwardStatus(id)
+	if err != nil {
+		return nil, err
+	}
+	if status.State == RunStateRunning {
+		status.State = RunStateStopped // Interrupted by a restart
+	}
+	return status, nil
+}
+
+// ListWalkForwards returns all walk-forward analyses, newest first, without their equity curves.
+func (m *Manager) ListWalkForwards() ([]*WalkForwardStatus, error) {
+	ids, err := loadAnalysisIDs(analysisWalkForward)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*WalkForwardStatus, 0, len(ids))
+	for _, id := range ids {
+		if status, err := m.GetWalkForward(id); err == nil {
+			status.Equity = nil
+			items = append(items, status)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+// StopWalkForward stops the window in progress and skips the remaining ones.
+func (m *Manager) StopWalkForward(id string) error {
+	m.mu.RLock()
+	wf, ok := m.walkForwards[id]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("walk-forward %s is not running", id)
+	}
+	wf.cancel()
+	wf.mu.Lock()
+	current := wf.current
+	wf.mu.Unlock()
+	if current == "" {
+		return nil
+	}
+	if err := m.StopSweep(current); err == nil {
+		return nil
+	}
+	if err := m.Stop(current); err != nil {
+		logger.Infof("failed to stop walk-forward run %s: %v", current, err)
+	}
+	return nil
+}
+
+func saveWalkForwardStatus(status *WalkForwardStatus) error {
+	return saveAnalysis(analysisWalkForward, status.WalkForwardID, status)
+}
+
+func loadWalkForwardStatus(id string) (*WalkForwardStatus, error) {
+	var status WalkForwardStatus
+	if err := loadAnalysis(analysisWalkForward, id, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
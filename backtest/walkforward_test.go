@@ -0,0 +1,47 @@
+package backtest
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"nofx/store"
+)
+
+func TestWalkForwardStatusStorage(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "backtest.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	UseDatabaseWithType(st.DB(), false)
+	t.Cleanup(func() { UseDatabaseWithType(nil, false) })
+
+	m := &Manager{}
+	created := time.Now().UTC()
+	for i, id := range []string{"older", "newer"} {
+		status := &WalkForwardStatus{WalkForwardID: id, State: RunStateRunning, Total: 3,
+			Equity: []EquityPoint{{Timestamp: 1, Equity: 1000}}, CreatedAt: created.Add(time.Duration(i) * time.Hour)}
+		if err := saveWalkForwardStatus(status); err != nil {
+			t.Fatalf("saveWalkForwardStatus: %v", err)
+		}
+	}
+
+	status, err := m.GetWalkForward("older")
+	if err != nil || len(status.Equity) != 1 {
+		t.Fatalf("Expected the stored analysis with its equity, got %+v (err %v)", status, err)
+	}
+	if status.State != RunStateStopped {
+		t.Errorf("Expected a running analysis found on load to be reported stopped, got %s", status.State)
+	}
+
+	items, err := m.ListWalkForwards()
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Expected 2 analyses, got %d (err %v)", len(items), err)
+	}
+	if items[0].WalkForwardID != "newer" || items[0].Equity != nil {
+		t.Errorf("Expected the newest first without its equity curve, got %+v", items[0])
+	}
+	if sweeps, _ := loadAnalysisIDs(analysisSweep); len(sweeps) != 0 {
+		t.Errorf("Walk-forward analyses must not be listed as sweeps, got %v", sweeps)
+	}
+}
@@ -0,0 +1,230 @@
+package kernel
+
+import (
+	"encoding/json"
+	"fmt"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/mcp"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// DCA Bot Context and Types
+// ============================================================================
+
+// DCAContext contains the information the AI needs to decide whether to start a DCA deal
+type DCAContext struct {
+	Symbol       string  `json:"symbol"`
+	CurrentTime  string  `json:"current_time"`
+	CurrentPrice float64 `json:"current_price"`
+	Direction    string  `json:"direction"` // "long" or "short"
+
+	// Market data
+	ATR14          float64 `json:"atr14"`
+	BollingerUpper float64 `json:"bollinger_upper"`
+	BollingerLower float64 `json:"bollinger_lower"`
+	EMA20          float64 `json:"ema20"`
+	EMA50          float64 `json:"ema50"`
+	RSI14          float64 `json:"rsi14"`
+	MACD           float64 `json:"macd"`
+	FundingRate    float64 `json:"funding_rate"`
+	PriceChange1h  float64 `json:"price_change_1h"`
+	PriceChange4h  float64 `json:"price_change_4h"`
+
+	// Account and deal history
+	AvailableBalance float64 `json:"available_balance"`
+	MaxDealCapital   float64 `json:"max_deal_capital"` // Base + all safety orders, in USDT
+	CompletedDeals   int     `json:"completed_deals"`
+	LastDealPnL      float64 `json:"last_deal_pnl"`
+}
+
+// DCA gating actions
+const (
+	DCAActionStartDeal = "start_deal"
+	DCAActionWait      = "wait"
+)
+
+// ============================================================================
+// DCA Prompt Building
+// ============================================================================
+
+// BuildDCASystemPrompt builds the system prompt for the DCA deal-start gate
+func BuildDCASystemPrompt(config *store.DCAStrategyConfig, lang string) string {
+	direction := config.Direction
+	if direction == "" {
+		direction = "long"
+	}
+	if lang == "zh" {
+		return fmt.Sprintf(`# 你是专业的DCA（定投加仓）交易AI
+
+## 角色定义
+你负责决定 %s 的DCA机器人是否应该现在开启一笔新交易。开启后机器人会机械地执行：
+1. 市价建立基础仓位（%.2f USDT）
+2. 价格每逆向偏离一档挂一个加仓单，最多 %d 档，首档偏离 %.2f%%
+3. 在平均持仓价 %.2f%% 处统一止盈
+
+## 决策规则
+- 方向：%s
+- 仅在趋势不会持续单边逆向运行时开启交易（做多时避免急跌趋势，做空时避免急涨趋势）
+- 资金不足以覆盖全部加仓单时不要开启
+- 不确定时选择等待
+
+## 输出格式
+输出JSON数组，只包含一个决策：
+- symbol: 交易对
+- action: "start_deal" 或 "wait"
+- confidence: 信心度 0-100
+- reasoning: 决策理由
+
+示例:
+[{"symbol": "%s", "action": "start_deal", "confidence": 80, "reasoning": "价格回落至布林下轨，RSI超卖"}]
+`, config.Symbol, config.BaseOrderUSD, config.MaxSafetyOrders, config.PriceDeviationPct, config.TakeProfitPct, direction, config.Symbol)
+	}
+	return fmt.Sprintf(`# You are a Professional DCA Trading AI
+
+## Role Definition
+You decide whether the DCA bot for %s should start a new deal now. Once started the bot runs mechanically:
+1. Open the base position at market (%.2f USDT)
+2. Average in with up to %d safety orders as price moves against the deal, first at %.2f%% deviation
+3. Close everything with one take-profit %.2f%% from the average entry
+
+## Decision Rules
+- Direction: %s
+- Only start a deal when price is unlikely to keep trending against it (avoid sharp downtrends for long, sharp uptrends for short)
+- Do not start if available balance cannot cover every safety order
+- When in doubt, wait
+
+## Output Format
+Output a JSON array with a single decision:
+- symbol: Trading pair
+- action: "start_deal" or "wait"
+- confidence: Confidence 0-100
+- reasoning: Decision reason
+
+Example:
+[{"symbol": "%s", "action": "start_deal", "confidence": 80, "reasoning": "Price pulled back to the lower Bollinger band with RSI oversold"}]
+`, config.Symbol, config.BaseOrderUSD, config.MaxSafetyOrders, config.PriceDeviationPct, config.TakeProfitPct, direction, config.Symbol)
+}
+
+// BuildDCAUserPrompt builds the user prompt with current market and deal context
+func BuildDCAUserPrompt(ctx *DCAContext, lang string) string {
+	var sb strings.Builder
+	if lang == "zh" {
+		sb.WriteString(fmt.Sprintf("## 当前时间: %s\n\n", ctx.CurrentTime))
+		sb.WriteString("## 市场数据\n")
+		sb.WriteString(fmt.Sprintf("- 当前价格: $%.4f\n", ctx.CurrentPrice))
+		sb.WriteString(fmt.Sprintf("- 1小时涨跌: %.2f%%, 4小时涨跌: %.2f%%\n", ctx.PriceChange1h, ctx.PriceChange4h))
+		sb.WriteString(fmt.Sprintf("- ATR14: $%.4f\n", ctx.ATR14))
+		sb.WriteString(fmt.Sprintf("- 布林带: 上轨 $%.4f, 下轨 $%.4f\n", ctx.BollingerUpper, ctx.BollingerLower))
+		sb.WriteString(fmt.Sprintf("- EMA20: $%.4f, EMA50: $%.4f\n", ctx.EMA20, ctx.EMA50))
+		sb.WriteString(fmt.Sprintf("- RSI14: %.1f, MACD: %.4f\n", ctx.RSI14, ctx.MACD))
+		sb.WriteString(fmt.Sprintf("- 资金费率: %.4f%%\n\n", ctx.FundingRate*100))
+		sb.WriteString("## 账户与交易记录\n")
+		sb.WriteString(fmt.Sprintf("- 可用余额: %.2f USDT\n", ctx.AvailableBalance))
+		sb.WriteString(fmt.Sprintf("- 单笔交易最大占用: %.2f USDT\n", ctx.MaxDealCapital))
+		sb.WriteString(fmt.Sprintf("- 已完成交易: %d, 上一笔盈亏: %.2f USDT\n\n", ctx.CompletedDeals, ctx.LastDealPnL))
+		sb.WriteString(fmt.Sprintf("请决定是否现在开启新的%s交易。\n", ctx.Direction))
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("## Current Time: %s\n\n", ctx.CurrentTime))
+	sb.WriteString("## Market Data\n")
+	sb.WriteString(fmt.Sprintf("- Current Price: $%.4f\n", ctx.CurrentPrice))
+	sb.WriteString(fmt.Sprintf("- 1h Change: %.2f%%, 4h Change: %.2f%%\n", ctx.PriceChange1h, ctx.PriceChange4h))
+	sb.WriteString(fmt.Sprintf("- ATR14: $%.4f\n", ctx.ATR14))
+	sb.WriteString(fmt.Sprintf("- Bollinger Bands: Upper $%.4f, Lower $%.4f\n", ctx.BollingerUpper, ctx.BollingerLower))
+	sb.WriteString(fmt.Sprintf("- EMA20: $%.4f, EMA50: $%.4f\n", ctx.EMA20, ctx.EMA50))
+	sb.WriteString(fmt.Sprintf("- RSI14: %.1f, MACD: %.4f\n", ctx.RSI14, ctx.MACD))
+	sb.WriteString(fmt.Sprintf("- Funding Rate: %.4f%%\n\n", ctx.FundingRate*100))
+	sb.WriteString("## Account and Deal History\n")
+	sb.WriteString(fmt.Sprintf("- Available Balance: %.2f USDT\n", ctx.AvailableBalance))
+	sb.WriteString(fmt.Sprintf("- Max Capital per Deal: %.2f USDT\n", ctx.MaxDealCapital))
+	sb.WriteString(fmt.Sprintf("- Completed Deals: %d, Last Deal PnL: %.2f USDT\n\n", ctx.CompletedDeals, ctx.LastDealPnL))
+	sb.WriteString(fmt.Sprintf("Decide whether to start a new %s deal now.\n", ctx.Direction))
+	return sb.String()
+}
+
+// ============================================================================
+// DCA AI Decision
+// ============================================================================
+
+// GetDCAStartDecision asks the AI whether to start a new DCA deal
+// Unparseable responses fall back to "wait" so a bad reply never opens a position
+func GetDCAStartDecision(ctx *DCAContext, mcpClient mcp.AIClient, config *store.DCAStrategyConfig, lang string) (*FullDecision, error) {
+	startTime := time.Now()
+
+	systemPrompt := BuildDCASystemPrompt(config, lang)
+	userPrompt := BuildDCAUserPrompt(ctx, lang)
+
+	logger.Infof("🤖 [DCA] Asking AI whether to start a deal...")
+
+	response, err := mcpClient.CallWithMessages(systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("AI call failed: %w", err)
+	}
+
+	decision := Decision{Symbol: ctx.Symbol, Action: DCAActionWait, Reasoning: "Failed to parse AI response, waiting"}
+	var decisions []Decision
+	if jsonStr := extractJSONArray(response); jsonStr == "" {
+		logger.Warnf("Failed to parse DCA decision: no JSON array found in response")
+	} else if err := json.Unmarshal([]byte(jsonStr), &decisions); err != nil {
+		logger.Warnf("Failed to parse DCA decision: %v", err)
+	} else if len(decisions) > 0 && (decisions[0].Action == DCAActionStartDeal || decisions[0].Action == DCAActionWait) {
+		decision = decisions[0]
+		decision.Symbol = ctx.Symbol
+	}
+
+	duration := time.Since(startTime).Milliseconds()
+	logger.Infof("⏱️ [DCA] AI call duration: %d ms, action: %s", duration, decision.Action)
+
+	return &FullDecision{
+		SystemPrompt:        systemPrompt,
+		UserPrompt:          userPrompt,
+		CoTTrace:            extractCoTTrace(response),
+		Decisions:           []Decision{decision},
+		RawResponse:         response,
+		AIRequestDurationMs: duration,
+		Timestamp:           time.Now(),
+	}, nil
+}
+
+// BuildDCAContextFromMarketData builds the DCA gating context from market data
+func BuildDCAContextFromMarketData(mktData *market.Data, config *store.DCAStrategyConfig) *DCAContext {
+	direction := config.Direction
+	if direction == "" {
+		direction = "long"
+	}
+	ctx := &DCAContext{
+		Symbol:        config.Symbol,
+		CurrentTime:   time.Now().Format("2006-01-02 15:04:05"),
+		CurrentPrice:  mktData.CurrentPrice,
+		Direction:     direction,
+		EMA20:         mktData.CurrentEMA20,
+		MACD:          mktData.CurrentMACD,
+		FundingRate:   mktData.FundingRate,
+		PriceChange1h: mktData.PriceChange1h,
+		PriceChange4h: mktData.PriceChange4h,
+	}
+
+	if tf5m, ok := mktData.TimeframeData["5m"]; ok {
+		if len(tf5m.BOLLUpper) > 0 {
+			ctx.BollingerUpper = tf5m.BOLLUpper[len(tf5m.BOLLUpper)-1]
+			ctx.BollingerLower = tf5m.BOLLLower[len(tf5m.BOLLLower)-1]
+		}
+		ctx.ATR14 = tf5m.ATR14
+		if len(tf5m.RSI14Values) > 0 {
+			ctx.RSI14 = tf5m.RSI14Values[len(tf5m.RSI14Values)-1]
+		}
+	}
+	if mktData.LongerTermContext != nil {
+		if ctx.ATR14 == 0 {
+			ctx.ATR14 = mktData.LongerTermContext.ATR14
+		}
+		ctx.EMA50 = mktData.LongerTermContext.EMA50
+	}
+
+	return ctx
+}
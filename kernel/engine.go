@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"nofx/logger"
 	"nofx/market"
@@ -13,6 +14,7 @@ import (
 	"nofx/provider/nofxos"
 	"nofx/security"
 	"nofx/store"
+	"nofx/trader/types"
 	"regexp"
 	"strings"
 	"time"
@@ -127,12 +129,13 @@ type Context struct {
 	BTCETHLeverage     int                          `json:"-"`
 	AltcoinLeverage int                                `json:"-"`
 	Timeframes      []string                           `json:"-"`
+	ContractSpecs   map[string]*types.ContractSpec     `json:"-"` // Exchange trading rules by symbol (nil = use defaults)
 }
 
 // Decision AI trading decision
 type Decision struct {
 	Symbol string `json:"symbol"`
-	Action string `json:"action"` // Standard: "open_long", "open_short", "close_long", "close_short", "hold", "wait"
+	Action string `json:"action"` // Standard: "open_long", "open_short", "close_long", "close_short", "set_trailing_stop", "hold", "wait"
 	// Grid actions: "place_buy_limit", "place_sell_limit", "cancel_order", "cancel_all_orders", "pause_grid", "resume_grid", "adjust_grid"
 
 	// Opening position parameters
@@ -141,6 +144,18 @@ type Decision struct {
 	StopLoss        float64 `json:"stop_loss,omitempty"`
 	TakeProfit      float64 `json:"take_profit,omitempty"`
 
+	// Take-profit ladder (optional, replaces the single take_profit order when set)
+	// Each level closes ClosePct of the opening quantity; whatever is left is the runner
+	TakeProfitLevels []TakeProfitLevel `json:"take_profit_levels,omitempty"`
+
+	// Trailing stop parameters (open actions and "set_trailing_stop")
+	TrailingCallbackRate    float64 `json:"trailing_callback_rate,omitempty"`    // Distance from best price in percent (e.g. 1.5 = 1.5%)
+	TrailingActivationPrice float64 `json:"trailing_activation_price,omitempty"` // Price at which trailing starts (0 = immediately)
+
+	// Execution algorithm override for this order: "market", "twap", "iceberg" or "pov"
+	// Only honoured when the strategy's execution config allows AI overrides
+	Execution string `json:"execution,omitempty"`
+
 	// Grid trading parameters
 	Price      float64 `json:"price,omitempty"`       // Limit order price (for grid)
 	Quantity   float64 `json:"quantity,omitempty"`    // Order quantity (for grid)
@@ -153,6 +168,14 @@ type Decision struct {
 	Reasoning  string  `json:"reasoning"`
 }
 
+// TakeProfitLevel one rung of a take-profit ladder
+// Target is either an absolute Price or a RMultiple of the initial risk (|entry - stop_loss|)
+type TakeProfitLevel struct {
+	Price     float64 `json:"price,omitempty"`
+	RMultiple float64 `json:"r_multiple,omitempty"`
+	ClosePct  float64 `json:"close_pct"` // Percent of the opening quantity to close at this level
+}
+
 // FullDecision AI's complete decision (including chain of thought)
 type FullDecision struct {
 	SystemPrompt        string     `json:"system_prompt"`
@@ -310,6 +333,8 @@ func GetFullDecisionWithStrategy(ctx *Context, mcpClient mcp.AIClient, engine *S
 		riskConfig.AltcoinMaxLeverage,
 		riskConfig.BTCETHMaxPositionValueRatio,
 		riskConfig.AltcoinMaxPositionValueRatio,
+		ctx.ContractSpecs,
+		ctx.MarketDataMap,
 	)
 
 	if decision != nil {
@@ -1145,13 +1170,24 @@ func (e *StrategyEngine) BuildSystemPrompt(accountEquity float64, variant string
 	examplePositionSize := accountEquity * btcEthPosValueRatio
 	sb.WriteString(fmt.Sprintf("  {\"symbol\": \"BTCUSDT\", \"action\": \"open_short\", \"leverage\": %d, \"position_size_usd\": %.0f, \"stop_loss\": 97000, \"take_profit\": 91000, \"confidence\": 85, \"risk_usd\": 300},\n",
 		riskControl.BTCETHMaxLeverage, examplePositionSize))
+	sb.WriteString("  {\"symbol\": \"SOLUSDT\", \"action\": \"set_trailing_stop\", \"trailing_callback_rate\": 1.5},\n")
 	sb.WriteString("  {\"symbol\": \"ETHUSDT\", \"action\": \"close_long\"}\n")
 	sb.WriteString("]\n```\n")
 	sb.WriteString("</decision>\n\n")
 	sb.WriteString("## Field Description\n\n")
-	sb.WriteString("- `action`: open_long | open_short | close_long | close_short | hold | wait\n")
+	sb.WriteString("- `action`: open_long | open_short | close_long | close_short | set_trailing_stop | hold | wait\n")
 	sb.WriteString(fmt.Sprintf("- `confidence`: 0-100 (opening recommended ≥ %d)\n", riskControl.MinConfidence))
 	sb.WriteString("- Required when opening: leverage, position_size_usd, stop_loss, take_profit, confidence, risk_usd\n")
+	sb.WriteString("- Optional take-profit ladder instead of a single take_profit: `take_profit_levels`: [{\"r_multiple\": 2, \"close_pct\": 40}, {\"r_multiple\": 4, \"close_pct\": 30}] (each level sets `price` or `r_multiple`; the remainder is the runner, protect it with a trailing stop; the blended reward/risk across levels must still reach 3:1). The stop moves to break-even after the first level fills\n")
+	sb.WriteString("- Optional trailing stop (opening or `set_trailing_stop` on an existing position): `trailing_callback_rate` (percent from best price, 0.1-10), `trailing_activation_price` (omit to trail immediately)\n")
+	if exec := e.config.Execution; exec != nil && exec.AllowAIOverride {
+		defaultAlgo := exec.Algorithm
+		if defaultAlgo == "" {
+			defaultAlgo = "market"
+		}
+		sb.WriteString(fmt.Sprintf("- Optional `execution` for large orders: market | twap | iceberg | pov (default %s for orders ≥ %.0f USDT). Slicing cuts market impact in thin books but takes longer to fill\n",
+			defaultAlgo, exec.MinNotionalUSD))
+	}
 	sb.WriteString("- **IMPORTANT**: All numeric values must be calculated numbers, NOT formulas/expressions (e.g., use `27.76` not `3000 * 0.01`)\n\n")
 
 	// 8. Custom Prompt
@@ -1375,6 +1411,7 @@ func (e *StrategyEngine) BuildUserPrompt(ctx *Context) string {
 		sourceTags := e.formatCoinSourceTag(coin.Sources)
 		sb.WriteString(fmt.Sprintf("### %d. %s%s\n\n", displayedCount, coin.Symbol, sourceTags))
 		sb.WriteString(e.formatMarketData(marketData))
+		sb.WriteString(e.formatExchangeLimits(ctx, coin.Symbol, marketData.CurrentPrice))
 
 		if ctx.QuantDataMap != nil {
 			if quantData, hasQuant := ctx.QuantDataMap[coin.Symbol]; hasQuant {
@@ -1679,6 +1716,39 @@ func (e *StrategyEngine) formatTimeframeSeriesData(sb *strings.Builder, data *ma
 	sb.WriteString("\n")
 }
 
+// formatExchangeLimits renders the exchange's leverage cap and order minimum for symbol
+// The leverage shown is the lower of the strategy cap and the exchange cap
+func (e *StrategyEngine) formatExchangeLimits(ctx *Context, symbol string, price float64) string {
+	spec := ctx.ContractSpecs[symbol]
+	if spec == nil {
+		return ""
+	}
+
+	maxLeverage := ctx.AltcoinLeverage
+	if symbol == "BTCUSDT" || symbol == "ETHUSDT" {
+		maxLeverage = ctx.BTCETHLeverage
+	}
+	if exchangeMax := spec.MaxLeverageFor(0); exchangeMax > 0 && (maxLeverage <= 0 || exchangeMax < maxLeverage) {
+		maxLeverage = exchangeMax
+	}
+
+	parts := make([]string, 0, 3)
+	if maxLeverage > 0 {
+		parts = append(parts, fmt.Sprintf("Max Leverage %dx", maxLeverage))
+	}
+	if minOrder := spec.MinOrderValue(price); minOrder > 0 {
+		parts = append(parts, fmt.Sprintf("Min Order %.2f USDT", minOrder))
+	}
+	if len(spec.LeverageBrackets) > 1 {
+		parts = append(parts, fmt.Sprintf("Leverage drops to %dx above %.0f USDT",
+			spec.LeverageBrackets[1].MaxLeverage, spec.LeverageBrackets[0].NotionalCap))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Exchange Limits: " + strings.Join(parts, " | ") + "\n"
+}
+
 func (e *StrategyEngine) formatQuantData(data *QuantData) string {
 	if data == nil {
 		return ""
@@ -1795,7 +1865,7 @@ func formatFloatSlice(values []float64) string {
 // AI Response Parsing
 // ============================================================================
 
-func parseFullDecisionResponse(aiResponse string, accountEquity float64, btcEthLeverage, altcoinLeverage int, btcEthPosRatio, altcoinPosRatio float64) (*FullDecision, error) {
+func parseFullDecisionResponse(aiResponse string, accountEquity float64, btcEthLeverage, altcoinLeverage int, btcEthPosRatio, altcoinPosRatio float64, specs map[string]*types.ContractSpec, marketData map[string]*market.Data) (*FullDecision, error) {
 	cotTrace := extractCoTTrace(aiResponse)
 
 	decisions, err := extractDecisions(aiResponse)
@@ -1806,7 +1876,7 @@ func parseFullDecisionResponse(aiResponse string, accountEquity float64, btcEthL
 		}, fmt.Errorf("failed to extract decisions: %w", err)
 	}
 
-	if err := validateDecisions(decisions, accountEquity, btcEthLeverage, altcoinLeverage, btcEthPosRatio, altcoinPosRatio); err != nil {
+	if err := validateDecisions(decisions, accountEquity, btcEthLeverage, altcoinLeverage, btcEthPosRatio, altcoinPosRatio, specs, marketData); err != nil {
 		return &FullDecision{
 			CoTTrace:  cotTrace,
 			Decisions: decisions,
@@ -1972,16 +2042,22 @@ func compactArrayOpen(s string) string {
 // Decision Validation
 // ============================================================================
 
-func validateDecisions(decisions []Decision, accountEquity float64, btcEthLeverage, altcoinLeverage int, btcEthPosRatio, altcoinPosRatio float64) error {
+func validateDecisions(decisions []Decision, accountEquity float64, btcEthLeverage, altcoinLeverage int, btcEthPosRatio, altcoinPosRatio float64, specs map[string]*types.ContractSpec, marketData map[string]*market.Data) error {
 	for i := range decisions {
-		if err := validateDecision(&decisions[i], accountEquity, btcEthLeverage, altcoinLeverage, btcEthPosRatio, altcoinPosRatio); err != nil {
+		price := 0.0
+		if data, ok := marketData[decisions[i].Symbol]; ok && data != nil {
+			price = data.CurrentPrice
+		}
+		if err := validateDecision(&decisions[i], accountEquity, btcEthLeverage, altcoinLeverage, btcEthPosRatio, altcoinPosRatio, specs[decisions[i].Symbol], price); err != nil {
 			return fmt.Errorf("decision #%d validation failed: %w", i+1, err)
 		}
 	}
 	return nil
 }
 
-func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoinLeverage int, btcEthPosRatio, altcoinPosRatio float64) error {
+// spec and price are optional: with a contract spec, exchange leverage caps and order minimums
+// replace the built-in defaults
+func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoinLeverage int, btcEthPosRatio, altcoinPosRatio float64, spec *types.ContractSpec, price float64) error {
 	validActions := map[string]bool{
 		"open_long":   true,
 		"open_short":  true,
@@ -1989,12 +2065,27 @@ func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoi
 		"close_short": true,
 		"hold":        true,
 		"wait":        true,
+
+		"set_trailing_stop": true,
 	}
 
 	if !validActions[d.Action] {
 		return fmt.Errorf("invalid action: %s", d.Action)
 	}
 
+	switch d.Execution {
+	case "", "market", "twap", "iceberg", "pov":
+	default:
+		return fmt.Errorf("invalid execution algorithm: %s", d.Execution)
+	}
+
+	if d.TrailingCallbackRate != 0 && (d.TrailingCallbackRate < 0.1 || d.TrailingCallbackRate > 10) {
+		return fmt.Errorf("trailing callback rate must be between 0.1 and 10 percent: %.2f", d.TrailingCallbackRate)
+	}
+	if d.Action == "set_trailing_stop" && d.TrailingCallbackRate < 0.1 {
+		return fmt.Errorf("set_trailing_stop requires trailing_callback_rate between 0.1 and 10 percent")
+	}
+
 	if d.Action == "open_long" || d.Action == "open_short" {
 		maxLeverage := altcoinLeverage
 		posRatio := altcoinPosRatio
@@ -2005,6 +2096,10 @@ func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoi
 			maxPositionValue = accountEquity * posRatio
 		}
 
+		if exchangeMax := spec.MaxLeverageFor(d.PositionSizeUSD); exchangeMax > 0 && exchangeMax < maxLeverage {
+			maxLeverage = exchangeMax
+		}
+
 		if d.Leverage <= 0 {
 			return fmt.Errorf("leverage must be greater than 0: %d", d.Leverage)
 		}
@@ -2020,7 +2115,11 @@ func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoi
 		const minPositionSizeGeneral = 12.0
 		const minPositionSizeBTCETH = 60.0
 
-		if d.Symbol == "BTCUSDT" || d.Symbol == "ETHUSDT" {
+		if exchangeMin := spec.MinOrderValue(price); exchangeMin > 0 {
+			if d.PositionSizeUSD < exchangeMin {
+				return fmt.Errorf("%s opening amount too small (%.2f USDT), exchange minimum is %.2f USDT", d.Symbol, d.PositionSizeUSD, exchangeMin)
+			}
+		} else if d.Symbol == "BTCUSDT" || d.Symbol == "ETHUSDT" {
 			if d.PositionSizeUSD < minPositionSizeBTCETH {
 				return fmt.Errorf("%s opening amount too small (%.2f USDT), must be ≥%.2f USDT", d.Symbol, d.PositionSizeUSD, minPositionSizeBTCETH)
 			}
@@ -2038,6 +2137,20 @@ func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoi
 				return fmt.Errorf("altcoin single coin position value cannot exceed %.0f USDT (%.1fx account equity), actual: %.0f", maxPositionValue, posRatio, d.PositionSizeUSD)
 			}
 		}
+		if err := validateTakeProfitLevels(d); err != nil {
+			return err
+		}
+		if len(d.TakeProfitLevels) > 0 {
+			// The ladder replaces the single take_profit order, so the blended target carries the risk/reward check
+			if d.StopLoss <= 0 {
+				return fmt.Errorf("stop loss must be greater than 0")
+			}
+			if blendedR := ladderRiskReward(d, price); blendedR < 3.0 {
+				return fmt.Errorf("risk/reward ratio too low (blended %.2f:1 across take-profit levels), must be ≥3.0:1", blendedR)
+			}
+			return nil
+		}
+
 		if d.StopLoss <= 0 || d.TakeProfit <= 0 {
 			return fmt.Errorf("stop loss and take profit must be greater than 0")
 		}
@@ -2083,6 +2196,114 @@ func validateDecision(d *Decision, accountEquity float64, btcEthLeverage, altcoi
 	return nil
 }
 
+// validateTakeProfitLevels checks a take-profit ladder on an open decision
+func validateTakeProfitLevels(d *Decision) error {
+	if len(d.TakeProfitLevels) == 0 {
+		return nil
+	}
+	if len(d.TakeProfitLevels) > 5 {
+		return fmt.Errorf("take-profit ladder supports at most 5 levels, got %d", len(d.TakeProfitLevels))
+	}
+
+	var totalPct, lastR, lastPrice float64
+	for i, level := range d.TakeProfitLevels {
+		if level.ClosePct <= 0 {
+			return fmt.Errorf("take-profit level %d: close_pct must be greater than 0", i+1)
+		}
+		totalPct += level.ClosePct
+		if (level.Price > 0) == (level.RMultiple > 0) {
+			return fmt.Errorf("take-profit level %d: set exactly one of price or r_multiple", i+1)
+		}
+		if level.RMultiple > 0 {
+			if level.RMultiple <= lastR {
+				return fmt.Errorf("take-profit level %d: r_multiple must increase level by level", i+1)
+			}
+			lastR = level.RMultiple
+			continue
+		}
+		if d.Action == "open_long" && level.Price <= d.StopLoss {
+			return fmt.Errorf("take-profit level %d: price must be above stop loss for long positions", i+1)
+		}
+		if d.Action == "open_short" && level.Price >= d.StopLoss {
+			return fmt.Errorf("take-profit level %d: price must be below stop loss for short positions", i+1)
+		}
+		if lastPrice > 0 && ((d.Action == "open_long" && level.Price <= lastPrice) || (d.Action == "open_short" && level.Price >= lastPrice)) {
+			return fmt.Errorf("take-profit level %d: prices must move further from entry level by level", i+1)
+		}
+		lastPrice = level.Price
+	}
+	if totalPct > 100.0001 {
+		return fmt.Errorf("take-profit ladder closes %.1f%% of the position, must be ≤100%%", totalPct)
+	}
+	return nil
+}
+
+// furthestTakeProfitLevel returns the furthest fixed-price rung of the ladder (0 if none)
+func furthestTakeProfitLevel(d *Decision) float64 {
+	var furthest float64
+	for _, level := range d.TakeProfitLevels {
+		if level.Price <= 0 {
+			continue
+		}
+		if furthest == 0 || (d.Action == "open_long" && level.Price > furthest) || (d.Action == "open_short" && level.Price < furthest) {
+			furthest = level.Price
+		}
+	}
+	return furthest
+}
+
+// ladderRiskReward returns the reward/risk ratio of a take-profit ladder, weighting each level's R-multiple
+// by the share of the position it closes. A trailing runner is counted at the furthest level, which the
+// ladder's stop has moved behind by the time the runner is alone; an unprotected remainder is left out.
+// Fixed-price levels are measured from price, or from the entry the single take_profit check assumes when
+// no market price is known
+func ladderRiskReward(d *Decision, price float64) float64 {
+	entry := price
+	if entry <= 0 {
+		if furthest := furthestTakeProfitLevel(d); furthest > 0 {
+			entry = d.StopLoss + (furthest-d.StopLoss)*0.2
+		}
+	}
+	risk := math.Abs(entry - d.StopLoss)
+
+	var weighted, totalPct, furthestR float64
+	for _, level := range d.TakeProfitLevels {
+		r := level.RMultiple
+		if level.Price > 0 {
+			if risk == 0 {
+				return 0
+			}
+			r = (level.Price - entry) / risk
+			if d.Action == "open_short" {
+				r = -r
+			}
+		}
+		weighted += r * level.ClosePct
+		totalPct += level.ClosePct
+		furthestR = math.Max(furthestR, r)
+	}
+	if hasTrailingRunner(d) {
+		weighted += furthestR * (100 - totalPct)
+		totalPct = 100
+	}
+	if totalPct <= 0 {
+		return 0
+	}
+	return weighted / totalPct
+}
+
+// hasTrailingRunner reports whether a ladder leaves part of the position open under a trailing stop
+func hasTrailingRunner(d *Decision) bool {
+	if len(d.TakeProfitLevels) == 0 || d.TrailingCallbackRate <= 0 {
+		return false
+	}
+	var totalPct float64
+	for _, level := range d.TakeProfitLevels {
+		totalPct += level.ClosePct
+	}
+	return totalPct < 100
+}
+
 // ============================================================================
 // Helper Functions
 // ============================================================================
@@ -0,0 +1,178 @@
+package kernel
+
+import (
+	"encoding/json"
+	"fmt"
+	"nofx/logger"
+	"nofx/mcp"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Pairs Trading Context and Types
+// ============================================================================
+
+// PairsContext contains the information the AI needs to confirm a spread entry signal
+type PairsContext struct {
+	SymbolA     string  `json:"symbol_a"`
+	SymbolB     string  `json:"symbol_b"`
+	CurrentTime string  `json:"current_time"`
+	PriceA      float64 `json:"price_a"`
+	PriceB      float64 `json:"price_b"`
+	Direction   string  `json:"direction"` // "long_spread" (long A / short B) or "short_spread"
+
+	// Spread statistics over the rolling window
+	Timeframe    string  `json:"timeframe"`
+	LookbackBars int     `json:"lookback_bars"`
+	HedgeRatio   float64 `json:"hedge_ratio"`
+	ZScore       float64 `json:"z_score"`
+	Correlation  float64 `json:"correlation"`
+	ChangeA      float64 `json:"change_a"` // Price change of leg A over the window (%)
+	ChangeB      float64 `json:"change_b"` // Price change of leg B over the window (%)
+
+	// Account
+	AvailableBalance float64 `json:"available_balance"`
+	LastTradePnL     float64 `json:"last_trade_pnl"`
+}
+
+// Pairs confirmation actions
+const (
+	PairsActionEnter = "enter_spread"
+	PairsActionSkip  = "skip"
+)
+
+// ============================================================================
+// Pairs Prompt Building
+// ============================================================================
+
+// BuildPairsSystemPrompt builds the system prompt for confirming a spread entry
+func BuildPairsSystemPrompt(config *store.PairsStrategyConfig, lang string) string {
+	if lang == "zh" {
+		return fmt.Sprintf(`# 你是专业的统计套利交易AI
+
+## 角色定义
+你负责确认 %s / %s 配对交易的开仓信号。信号由滚动对冲比率和价差z分数机械产生：
+1. z分数达到 ±%.2f 时开仓：价差偏低时做多%s、做空%s，偏高时反向
+2. z分数回归到 ±%.2f 以内时两腿同时平仓
+3. z分数继续扩大到 ±%.2f 时两腿同时止损
+
+## 决策规则
+- 只有当价差偏离更可能是暂时性的、会回归均值时才确认
+- 若某一腿有明显的独立事件驱动（上币、暴雷、解锁等），两者关系可能已经破裂，应跳过
+- 相关性明显下降时应跳过
+- 不确定时选择跳过
+
+## 输出格式
+输出JSON数组，只包含一个决策：
+- symbol: 腿A交易对
+- action: "enter_spread" 或 "skip"
+- confidence: 信心度 0-100
+- reasoning: 决策理由
+
+示例:
+[{"symbol": "%s", "action": "enter_spread", "confidence": 75, "reasoning": "价差偏离由短期流动性导致，相关性稳定"}]
+`, config.SymbolA, config.SymbolB, config.EntryZ, config.SymbolA, config.SymbolB, config.ExitZ, config.StopZ, config.SymbolA)
+	}
+	return fmt.Sprintf(`# You are a Professional Statistical Arbitrage AI
+
+## Role Definition
+You confirm entry signals for the %s / %s pairs trade. Signals come mechanically from a rolling hedge ratio and the spread's z-score:
+1. Enter at a z-score of ±%.2f: long %s / short %s when the spread is low, the reverse when high
+2. Close both legs together when the z-score reverts within ±%.2f
+3. Stop out both legs together if the z-score stretches to ±%.2f
+
+## Decision Rules
+- Only confirm when the divergence is more likely temporary and will revert to the mean
+- Skip when one leg is driven by its own news (listing, exploit, unlock...), since the relationship may have broken
+- Skip when correlation has clearly weakened
+- When in doubt, skip
+
+## Output Format
+Output a JSON array with a single decision:
+- symbol: Leg A trading pair
+- action: "enter_spread" or "skip"
+- confidence: Confidence 0-100
+- reasoning: Decision reason
+
+Example:
+[{"symbol": "%s", "action": "enter_spread", "confidence": 75, "reasoning": "Divergence driven by short-term flows, correlation stable"}]
+`, config.SymbolA, config.SymbolB, config.EntryZ, config.SymbolA, config.SymbolB, config.ExitZ, config.StopZ, config.SymbolA)
+}
+
+// BuildPairsUserPrompt builds the user prompt with the current spread and account context
+func BuildPairsUserPrompt(ctx *PairsContext, lang string) string {
+	var sb strings.Builder
+	if lang == "zh" {
+		sb.WriteString(fmt.Sprintf("## 当前时间: %s\n\n", ctx.CurrentTime))
+		sb.WriteString("## 价差数据\n")
+		sb.WriteString(fmt.Sprintf("- %s: $%.4f (窗口内涨跌 %.2f%%)\n", ctx.SymbolA, ctx.PriceA, ctx.ChangeA))
+		sb.WriteString(fmt.Sprintf("- %s: $%.4f (窗口内涨跌 %.2f%%)\n", ctx.SymbolB, ctx.PriceB, ctx.ChangeB))
+		sb.WriteString(fmt.Sprintf("- 窗口: %d 根 %s K线\n", ctx.LookbackBars, ctx.Timeframe))
+		sb.WriteString(fmt.Sprintf("- 对冲比率: %.4f, 收益率相关性: %.3f\n", ctx.HedgeRatio, ctx.Correlation))
+		sb.WriteString(fmt.Sprintf("- 价差z分数: %.2f\n\n", ctx.ZScore))
+		sb.WriteString("## 账户\n")
+		sb.WriteString(fmt.Sprintf("- 可用余额: %.2f USDT\n", ctx.AvailableBalance))
+		sb.WriteString(fmt.Sprintf("- 上一笔配对交易盈亏: %.2f USDT\n\n", ctx.LastTradePnL))
+		sb.WriteString(fmt.Sprintf("信号方向: %s。请决定是否开仓。\n", ctx.Direction))
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("## Current Time: %s\n\n", ctx.CurrentTime))
+	sb.WriteString("## Spread Data\n")
+	sb.WriteString(fmt.Sprintf("- %s: $%.4f (%.2f%% over the window)\n", ctx.SymbolA, ctx.PriceA, ctx.ChangeA))
+	sb.WriteString(fmt.Sprintf("- %s: $%.4f (%.2f%% over the window)\n", ctx.SymbolB, ctx.PriceB, ctx.ChangeB))
+	sb.WriteString(fmt.Sprintf("- Window: %d %s bars\n", ctx.LookbackBars, ctx.Timeframe))
+	sb.WriteString(fmt.Sprintf("- Hedge Ratio: %.4f, Return Correlation: %.3f\n", ctx.HedgeRatio, ctx.Correlation))
+	sb.WriteString(fmt.Sprintf("- Spread Z-Score: %.2f\n\n", ctx.ZScore))
+	sb.WriteString("## Account\n")
+	sb.WriteString(fmt.Sprintf("- Available Balance: %.2f USDT\n", ctx.AvailableBalance))
+	sb.WriteString(fmt.Sprintf("- Last Pairs Trade PnL: %.2f USDT\n\n", ctx.LastTradePnL))
+	sb.WriteString(fmt.Sprintf("Signal: %s. Decide whether to enter now.\n", ctx.Direction))
+	return sb.String()
+}
+
+// ============================================================================
+// Pairs AI Decision
+// ============================================================================
+
+// GetPairsEntryDecision asks the AI to confirm a spread entry signal
+// Unparseable responses fall back to "skip" so a bad reply never opens a position
+func GetPairsEntryDecision(ctx *PairsContext, mcpClient mcp.AIClient, config *store.PairsStrategyConfig, lang string) (*FullDecision, error) {
+	startTime := time.Now()
+
+	systemPrompt := BuildPairsSystemPrompt(config, lang)
+	userPrompt := BuildPairsUserPrompt(ctx, lang)
+
+	logger.Infof("🤖 [Pairs] Asking AI to confirm %s entry...", ctx.Direction)
+
+	response, err := mcpClient.CallWithMessages(systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("AI call failed: %w", err)
+	}
+
+	decision := Decision{Symbol: ctx.SymbolA, Action: PairsActionSkip, Reasoning: "Failed to parse AI response, skipping"}
+	var decisions []Decision
+	if jsonStr := extractJSONArray(response); jsonStr == "" {
+		logger.Warnf("Failed to parse pairs decision: no JSON array found in response")
+	} else if err := json.Unmarshal([]byte(jsonStr), &decisions); err != nil {
+		logger.Warnf("Failed to parse pairs decision: %v", err)
+	} else if len(decisions) > 0 && (decisions[0].Action == PairsActionEnter || decisions[0].Action == PairsActionSkip) {
+		decision = decisions[0]
+		decision.Symbol = ctx.SymbolA
+	}
+
+	duration := time.Since(startTime).Milliseconds()
+	logger.Infof("⏱️ [Pairs] AI call duration: %d ms, action: %s", duration, decision.Action)
+
+	return &FullDecision{
+		SystemPrompt:        systemPrompt,
+		UserPrompt:          userPrompt,
+		CoTTrace:            extractCoTTrace(response),
+		Decisions:           []Decision{decision},
+		RawResponse:         response,
+		AIRequestDurationMs: duration,
+		Timestamp:           time.Now(),
+	}, nil
+}
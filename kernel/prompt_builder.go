@@ -108,6 +108,8 @@ func (pb *PromptBuilder) buildSystemPromptZH() string {
 - **position_size_usd**: 仓位大小（USDT，开新仓时必需）
 - **stop_loss**: 止损价格（开新仓时建议提供）
 - **take_profit**: 止盈价格（开新仓时建议提供）
+- **trailing_callback_rate**: 移动止损回调比例（百分比，0.1-10，可选；从最优价格回撤该比例时止损）
+- **trailing_activation_price**: 移动止损激活价格（可选，不填则立即开始跟踪）
 - **confidence**: 信心度（0-100）
 - **reasoning**: 推理过程（必需，必须详细说明决策依据）
 
@@ -243,6 +245,8 @@ func (pb *PromptBuilder) buildSystemPromptEN() string {
 - **position_size_usd**: Position size in USDT (required for new positions)
 - **stop_loss**: Stop-loss price (recommended for new positions)
 - **take_profit**: Take-profit price (recommended for new positions)
+- **trailing_callback_rate**: Trailing stop callback in percent (0.1-10, optional; stop trails the best price by this distance)
+- **trailing_activation_price**: Price at which the trailing stop activates (optional, trails immediately if omitted)
 - **confidence**: Confidence level (0-100)
 - **reasoning**: Detailed reasoning (required, must explain decision basis)
 
@@ -1,6 +1,7 @@
 package kernel
 
 import (
+	"nofx/trader/types"
 	"testing"
 )
 
@@ -84,7 +85,7 @@ func TestLeverageFallback(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Use default position value ratios for testing (10x for BTC/ETH, 1.5x for altcoins)
-			err := validateDecision(&tt.decision, tt.accountEquity, tt.btcEthLeverage, tt.altcoinLeverage, 10.0, 1.5)
+			err := validateDecision(&tt.decision, tt.accountEquity, tt.btcEthLeverage, tt.altcoinLeverage, 10.0, 1.5, nil, 0)
 
 			// Check error status
 			if (err != nil) != tt.wantError {
@@ -100,6 +101,133 @@ func TestLeverageFallback(t *testing.T) {
 	}
 }
 
+// TestTakeProfitLadderValidation tests take-profit ladder checks on open decisions
+func TestTakeProfitLadderValidation(t *testing.T) {
+	base := func(levels []TakeProfitLevel, trailing float64) Decision {
+		return Decision{
+			Symbol:               "SOLUSDT",
+			Action:               "open_long",
+			Leverage:             3,
+			PositionSizeUSD:      100,
+			StopLoss:             95,
+			TakeProfitLevels:     levels,
+			TrailingCallbackRate: trailing,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		decision  Decision
+		price     float64
+		wantError bool
+	}{
+		{
+			name:     "R-multiple ladder with trailing runner",
+			decision: base([]TakeProfitLevel{{RMultiple: 2, ClosePct: 40}, {RMultiple: 4, ClosePct: 30}}, 1.5),
+		},
+		{
+			name:      "Trailing runner doesn't excuse a blended ratio below 3R",
+			decision:  base([]TakeProfitLevel{{RMultiple: 1, ClosePct: 40}, {RMultiple: 2, ClosePct: 30}}, 1.5),
+			wantError: true,
+		},
+		{
+			name:      "R-multiple ladder without runner below 3R",
+			decision:  base([]TakeProfitLevel{{RMultiple: 1, ClosePct: 50}, {RMultiple: 2, ClosePct: 50}}, 0),
+			wantError: true,
+		},
+		{
+			name:      "Furthest level alone reaching 3R isn't enough",
+			decision:  base([]TakeProfitLevel{{RMultiple: 1, ClosePct: 80}, {RMultiple: 5, ClosePct: 20}}, 0),
+			wantError: true,
+		},
+		{
+			name:     "Price ladder blended from the market price",
+			decision: base([]TakeProfitLevel{{Price: 110, ClosePct: 50}, {Price: 130, ClosePct: 50}}, 0),
+			price:    100, // Risk 5: levels at 2R and 6R
+		},
+		{
+			name:      "Price ladder below 3R from the market price",
+			decision:  base([]TakeProfitLevel{{Price: 105, ClosePct: 50}, {Price: 115, ClosePct: 50}}, 0),
+			price:     100, // Levels at 1R and 3R
+			wantError: true,
+		},
+		{
+			name:     "Price ladder without a market price",
+			decision: base([]TakeProfitLevel{{Price: 125, ClosePct: 50}, {Price: 140, ClosePct: 50}}, 0),
+		},
+		{
+			name:      "Closes more than 100%",
+			decision:  base([]TakeProfitLevel{{RMultiple: 1, ClosePct: 60}, {RMultiple: 4, ClosePct: 60}}, 0),
+			wantError: true,
+		},
+		{
+			name:      "Level below stop loss",
+			decision:  base([]TakeProfitLevel{{Price: 90, ClosePct: 50}}, 1),
+			wantError: true,
+		},
+		{
+			name:      "Both price and r_multiple set",
+			decision:  base([]TakeProfitLevel{{Price: 110, RMultiple: 1, ClosePct: 50}}, 1),
+			wantError: true,
+		},
+		{
+			name:      "Levels out of order",
+			decision:  base([]TakeProfitLevel{{RMultiple: 2, ClosePct: 30}, {RMultiple: 1, ClosePct: 30}}, 1),
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDecision(&tt.decision, 1000, 10, 5, 10.0, 1.5, nil, tt.price)
+			if (err != nil) != tt.wantError {
+				t.Errorf("validateDecision() error = %v, wantError %v", err, tt.wantError)
+			}
+			if tt.decision.TakeProfit != 0 {
+				t.Errorf("validateDecision() must not rewrite take_profit, got %.4f", tt.decision.TakeProfit)
+			}
+		})
+	}
+}
+
+// TestContractSpecLimits tests that exchange contract specs replace the built-in minimums and cap leverage
+func TestContractSpecLimits(t *testing.T) {
+	spec := &types.ContractSpec{
+		Symbol:      "BTCUSDT",
+		MinQty:      0.0001,
+		MinNotional: 5,
+		MaxLeverage: 8,
+	}
+	open := func(size float64) Decision {
+		return Decision{
+			Symbol:          "BTCUSDT",
+			Action:          "open_long",
+			Leverage:        20,
+			PositionSizeUSD: size,
+			StopLoss:        90000,
+			TakeProfit:      110000,
+		}
+	}
+
+	// 30 USDT is under the 60 USDT default but above the exchange minimum (0.0001 BTC ≈ 10 USDT)
+	d := open(30)
+	if err := validateDecision(&d, 1000, 10, 5, 10.0, 1.5, spec, 100000); err != nil {
+		t.Fatalf("expected exchange minimum to apply, got %v", err)
+	}
+	if d.Leverage != 8 {
+		t.Errorf("expected leverage capped to exchange max 8x, got %dx", d.Leverage)
+	}
+
+	d = open(8)
+	if err := validateDecision(&d, 1000, 10, 5, 10.0, 1.5, spec, 100000); err == nil {
+		t.Error("expected error below exchange minimum order value")
+	}
+
+	d = open(30)
+	if err := validateDecision(&d, 1000, 10, 5, 10.0, 1.5, nil, 0); err == nil {
+		t.Error("expected default 60 USDT BTC minimum without a spec")
+	}
+}
 
 // contains checks if string contains substring (helper function)
 func contains(s, substr string) bool {
@@ -674,43 +674,16 @@ func (tm *TraderManager) addTraderFromStore(traderCfg *store.Trader, aiModelCfg
 	logger.Infof("📊 Loading trader %s: ScanIntervalMinutes=%d (from DB), ScanInterval=%v",
 		traderCfg.Name, traderCfg.ScanIntervalMinutes, traderConfig.ScanInterval)
 
-	// Set API keys based on exchange type (convert EncryptedString to string)
-	switch exchangeCfg.ExchangeType {
-	case "binance":
-		traderConfig.BinanceAPIKey = string(exchangeCfg.APIKey)
-		traderConfig.BinanceSecretKey = string(exchangeCfg.SecretKey)
-	case "bybit":
-		traderConfig.BybitAPIKey = string(exchangeCfg.APIKey)
-		traderConfig.BybitSecretKey = string(exchangeCfg.SecretKey)
-	case "okx":
-		traderConfig.OKXAPIKey = string(exchangeCfg.APIKey)
-		traderConfig.OKXSecretKey = string(exchangeCfg.SecretKey)
-		traderConfig.OKXPassphrase = string(exchangeCfg.Passphrase)
-	case "bitget":
-		traderConfig.BitgetAPIKey = string(exchangeCfg.APIKey)
-		traderConfig.BitgetSecretKey = string(exchangeCfg.SecretKey)
-		traderConfig.BitgetPassphrase = string(exchangeCfg.Passphrase)
-	case "gate":
-		traderConfig.GateAPIKey = string(exchangeCfg.APIKey)
-		traderConfig.GateSecretKey = string(exchangeCfg.SecretKey)
-	case "kucoin":
-		traderConfig.KuCoinAPIKey = string(exchangeCfg.APIKey)
-		traderConfig.KuCoinSecretKey = string(exchangeCfg.SecretKey)
-		traderConfig.KuCoinPassphrase = string(exchangeCfg.Passphrase)
-	case "hyperliquid":
-		traderConfig.HyperliquidPrivateKey = string(exchangeCfg.APIKey)
-		traderConfig.HyperliquidWalletAddr = exchangeCfg.HyperliquidWalletAddr
-		traderConfig.HyperliquidUnifiedAcct = exchangeCfg.HyperliquidUnifiedAcct
-	case "aster":
-		traderConfig.AsterUser = exchangeCfg.AsterUser
-		traderConfig.AsterSigner = exchangeCfg.AsterSigner
-		traderConfig.AsterPrivateKey = string(exchangeCfg.AsterPrivateKey)
-	case "lighter":
-		traderConfig.LighterPrivateKey = string(exchangeCfg.LighterPrivateKey)
-		traderConfig.LighterWalletAddr = exchangeCfg.LighterWalletAddr
-		traderConfig.LighterAPIKeyPrivateKey = string(exchangeCfg.LighterAPIKeyPrivateKey)
-		traderConfig.LighterAPIKeyIndex = exchangeCfg.LighterAPIKeyIndex
-		traderConfig.LighterTestnet = exchangeCfg.Testnet
+	// Set API keys based on exchange type
+	applyExchangeCredentials(&traderConfig, exchangeCfg)
+
+	// Funding arbitrage trades across the user's other exchange accounts too
+	if strategyConfig.StrategyType == "funding_arb" && strategyConfig.FundingArbConfig != nil {
+		venues, err := fundingArbVenueConfigs(st, traderCfg.UserID, exchangeCfg.ID, strategyConfig.FundingArbConfig.ExchangeIDs)
+		if err != nil {
+			return fmt.Errorf("failed to load funding arbitrage exchanges for trader %s: %w", traderCfg.Name, err)
+		}
+		traderConfig.ArbVenues = venues
 	}
 
 	// Set API keys based on AI model (convert EncryptedString to string)
@@ -762,6 +735,75 @@ func (tm *TraderManager) addTraderFromStore(traderCfg *store.Trader, aiModelCfg
 	return nil
 }
 
+// applyExchangeCredentials sets the credentials of exchangeCfg on traderConfig (convert EncryptedString to string)
+func applyExchangeCredentials(traderConfig *trader.AutoTraderConfig, exchangeCfg *store.Exchange) {
+	switch exchangeCfg.ExchangeType {
+	case "binance":
+		traderConfig.BinanceAPIKey = string(exchangeCfg.APIKey)
+		traderConfig.BinanceSecretKey = string(exchangeCfg.SecretKey)
+	case "bybit":
+		traderConfig.BybitAPIKey = string(exchangeCfg.APIKey)
+		traderConfig.BybitSecretKey = string(exchangeCfg.SecretKey)
+	case "okx":
+		traderConfig.OKXAPIKey = string(exchangeCfg.APIKey)
+		traderConfig.OKXSecretKey = string(exchangeCfg.SecretKey)
+		traderConfig.OKXPassphrase = string(exchangeCfg.Passphrase)
+	case "bitget":
+		traderConfig.BitgetAPIKey = string(exchangeCfg.APIKey)
+		traderConfig.BitgetSecretKey = string(exchangeCfg.SecretKey)
+		traderConfig.BitgetPassphrase = string(exchangeCfg.Passphrase)
+	case "gate":
+		traderConfig.GateAPIKey = string(exchangeCfg.APIKey)
+		traderConfig.GateSecretKey = string(exchangeCfg.SecretKey)
+	case "kucoin":
+		traderConfig.KuCoinAPIKey = string(exchangeCfg.APIKey)
+		traderConfig.KuCoinSecretKey = string(exchangeCfg.SecretKey)
+		traderConfig.KuCoinPassphrase = string(exchangeCfg.Passphrase)
+	case "hyperliquid":
+		traderConfig.HyperliquidPrivateKey = string(exchangeCfg.APIKey)
+		traderConfig.HyperliquidWalletAddr = exchangeCfg.HyperliquidWalletAddr
+		traderConfig.HyperliquidUnifiedAcct = exchangeCfg.HyperliquidUnifiedAcct
+	case "aster":
+		traderConfig.AsterUser = exchangeCfg.AsterUser
+		traderConfig.AsterSigner = exchangeCfg.AsterSigner
+		traderConfig.AsterPrivateKey = string(exchangeCfg.AsterPrivateKey)
+	case "lighter":
+		traderConfig.LighterPrivateKey = string(exchangeCfg.LighterPrivateKey)
+		traderConfig.LighterWalletAddr = exchangeCfg.LighterWalletAddr
+		traderConfig.LighterAPIKeyPrivateKey = string(exchangeCfg.LighterAPIKeyPrivateKey)
+		traderConfig.LighterAPIKeyIndex = exchangeCfg.LighterAPIKeyIndex
+		traderConfig.LighterTestnet = exchangeCfg.Testnet
+	}
+}
+
+// fundingArbVenueConfigs returns the user's other enabled exchange accounts for funding arbitrage
+// exchangeIDs limits the accounts when set; primaryID is the trader's own account and is skipped
+func fundingArbVenueConfigs(st *store.Store, userID, primaryID string, exchangeIDs []string) ([]trader.AutoTraderConfig, error) {
+	exchanges, err := st.Exchange().List(userID)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]bool, len(exchangeIDs))
+	for _, id := range exchangeIDs {
+		allowed[id] = true
+	}
+
+	var venues []trader.AutoTraderConfig
+	for _, ex := range exchanges {
+		if !ex.Enabled || ex.ID == primaryID || (len(allowed) > 0 && !allowed[ex.ID]) {
+			continue
+		}
+		venue := trader.AutoTraderConfig{
+			Exchange:           ex.ExchangeType,
+			ExchangeID:         ex.ID,
+			HyperliquidTestnet: ex.Testnet,
+		}
+		applyExchangeCredentials(&venue, ex)
+		venues = append(venues, venue)
+	}
+	return venues, nil
+}
+
 // GetTraderExecutor returns a TraderExecutor for the given trader ID
 // This is used by the debate module to execute consensus trades
 func (tm *TraderManager) GetTraderExecutor(traderID string) (debate.TraderExecutor, error) {
@@ -19,8 +19,10 @@ import (
 // FundingRateCache is the funding rate cache structure
 // Binance Funding Rate only updates every 8 hours, using 1-hour cache can significantly reduce API calls
 type FundingRateCache struct {
-	Rate      float64
-	UpdatedAt time.Time
+	Rate            float64
+	IntervalHours   float64 // Set for per-venue entries (GetVenueFundingRate)
+	NextFundingTime int64   // Unix milliseconds, per-venue entries only
+	UpdatedAt       time.Time
 }
 
 var (
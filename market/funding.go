@@ -0,0 +1,334 @@
+package market
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VenueFundingRate is the current funding rate of a perpetual on one exchange
+type VenueFundingRate struct {
+	Exchange        string  `json:"exchange"`
+	Symbol          string  `json:"symbol"`
+	Rate            float64 `json:"rate"`              // Rate per funding interval (0.0001 = 0.01%), positive = longs pay shorts
+	IntervalHours   float64 `json:"interval_hours"`    // Hours between funding settlements
+	NextFundingTime int64   `json:"next_funding_time"` // Unix milliseconds, 0 if the venue doesn't report it
+}
+
+// HourlyRate returns the funding rate per hour, so venues with different intervals compare directly
+func (r *VenueFundingRate) HourlyRate() float64 {
+	if r.IntervalHours <= 0 {
+		return r.Rate / 8
+	}
+	return r.Rate / r.IntervalHours
+}
+
+// APR returns the annualised funding rate in percent
+func (r *VenueFundingRate) APR() float64 {
+	return r.HourlyRate() * 24 * 365 * 100
+}
+
+// venueFundingTTL cross-venue rates drive entry/exit decisions, so they are cached far shorter than getFundingRate
+var venueFundingTTL = 5 * time.Minute
+
+// fundingBaseURLs public REST endpoints used for funding rates (overridden by tests)
+var fundingBaseURLs = map[string]string{
+	"binance":     "https://fapi.binance.com",
+	"aster":       "https://fapi.asterdex.com",
+	"bybit":       "https://api.bybit.com",
+	"okx":         "https://www.okx.com",
+	"bitget":      "https://api.bitget.com",
+	"gate":        "https://api.gateio.ws",
+	"kucoin":      "https://api-futures.kucoin.com",
+	"hyperliquid": "https://api.hyperliquid.xyz",
+}
+
+// GetVenueFundingRate returns the current funding rate of symbol (e.g. "BTCUSDT") on exchange
+// Results share fundingRateMap with getFundingRate, keyed by exchange:symbol
+func GetVenueFundingRate(exchange, symbol string) (*VenueFundingRate, error) {
+	symbol = Normalize(symbol)
+	key := exchange + ":" + symbol
+	if cached, ok := fundingRateMap.Load(key); ok {
+		cache := cached.(*FundingRateCache)
+		if time.Since(cache.UpdatedAt) < venueFundingTTL {
+			return &VenueFundingRate{
+				Exchange:        exchange,
+				Symbol:          symbol,
+				Rate:            cache.Rate,
+				IntervalHours:   cache.IntervalHours,
+				NextFundingTime: cache.NextFundingTime,
+			}, nil
+		}
+	}
+
+	baseURL, ok := fundingBaseURLs[exchange]
+	if !ok {
+		return nil, fmt.Errorf("funding rates not available for exchange %s", exchange)
+	}
+
+	var rate *VenueFundingRate
+	var err error
+	switch exchange {
+	case "binance", "aster":
+		rate, err = fetchBinanceStyleFunding(baseURL, symbol)
+	case "bybit":
+		rate, err = fetchBybitFunding(baseURL, symbol)
+	case "okx":
+		rate, err = fetchOKXFunding(baseURL, symbol)
+	case "bitget":
+		rate, err = fetchBitgetFunding(baseURL, symbol)
+	case "gate":
+		rate, err = fetchGateFunding(baseURL, symbol)
+	case "kucoin":
+		rate, err = fetchKuCoinFunding(baseURL, symbol)
+	case "hyperliquid":
+		rate, err = fetchHyperliquidFunding(baseURL, symbol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s funding rate for %s: %w", exchange, symbol, err)
+	}
+	rate.Exchange = exchange
+	rate.Symbol = symbol
+	if rate.IntervalHours <= 0 {
+		rate.IntervalHours = 8
+	}
+
+	fundingRateMap.Store(key, &FundingRateCache{
+		Rate:            rate.Rate,
+		IntervalHours:   rate.IntervalHours,
+		NextFundingTime: rate.NextFundingTime,
+		UpdatedAt:       time.Now(),
+	})
+	return rate, nil
+}
+
+// fundingBaseAsset returns the base asset of a USDT perpetual (BTCUSDT -> BTC)
+func fundingBaseAsset(symbol string) string {
+	return strings.TrimSuffix(symbol, "USDT")
+}
+
+// fetchFundingJSON performs a GET (or a POST when body is set) and decodes the JSON response into out
+func fetchFundingJSON(url string, body interface{}, out interface{}) error {
+	client := NewAPIClient().client
+
+	var resp *http.Response
+	var err error
+	if body != nil {
+		payload, _ := json.Marshal(body)
+		resp, err = client.Post(url, "application/json", bytes.NewReader(payload))
+	} else {
+		resp, err = client.Get(url)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(data))
+	}
+	return json.Unmarshal(data, out)
+}
+
+// fetchBinanceStyleFunding reads /fapi/v1/premiumIndex (Binance and its Aster clone settle every 8h)
+func fetchBinanceStyleFunding(baseURL, symbol string) (*VenueFundingRate, error) {
+	var result struct {
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+	}
+	if err := fetchFundingJSON(fmt.Sprintf("%s/fapi/v1/premiumIndex?symbol=%s", baseURL, symbol), nil, &result); err != nil {
+		return nil, err
+	}
+	rate, err := strconv.ParseFloat(result.LastFundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid funding rate %q", result.LastFundingRate)
+	}
+	return &VenueFundingRate{Rate: rate, IntervalHours: 8, NextFundingTime: result.NextFundingTime}, nil
+}
+
+// fetchBybitFunding reads the linear ticker, which carries the current rate and its interval
+func fetchBybitFunding(baseURL, symbol string) (*VenueFundingRate, error) {
+	var result struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			List []struct {
+				FundingRate         string `json:"fundingRate"`
+				NextFundingTime     string `json:"nextFundingTime"`
+				FundingIntervalHour string `json:"fundingIntervalHour"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := fetchFundingJSON(fmt.Sprintf("%s/v5/market/tickers?category=linear&symbol=%s", baseURL, symbol), nil, &result); err != nil {
+		return nil, err
+	}
+	if result.RetCode != 0 || len(result.Result.List) == 0 {
+		return nil, fmt.Errorf("no ticker (retCode %d: %s)", result.RetCode, result.RetMsg)
+	}
+	ticker := result.Result.List[0]
+	rate, err := strconv.ParseFloat(ticker.FundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid funding rate %q", ticker.FundingRate)
+	}
+	interval, _ := strconv.ParseFloat(ticker.FundingIntervalHour, 64)
+	next, _ := strconv.ParseInt(ticker.NextFundingTime, 10, 64)
+	return &VenueFundingRate{Rate: rate, IntervalHours: interval, NextFundingTime: next}, nil
+}
+
+// fetchOKXFunding reads /public/funding-rate; the interval is the gap between the current and next settlement
+func fetchOKXFunding(baseURL, symbol string) (*VenueFundingRate, error) {
+	var result struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			FundingRate     string `json:"fundingRate"`
+			FundingTime     string `json:"fundingTime"`
+			NextFundingTime string `json:"nextFundingTime"`
+		} `json:"data"`
+	}
+	instID := fundingBaseAsset(symbol) + "-USDT-SWAP"
+	if err := fetchFundingJSON(fmt.Sprintf("%s/api/v5/public/funding-rate?instId=%s", baseURL, instID), nil, &result); err != nil {
+		return nil, err
+	}
+	if result.Code != "0" || len(result.Data) == 0 {
+		return nil, fmt.Errorf("no funding data (code %s: %s)", result.Code, result.Msg)
+	}
+	data := result.Data[0]
+	rate, err := strconv.ParseFloat(data.FundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid funding rate %q", data.FundingRate)
+	}
+	current, _ := strconv.ParseInt(data.FundingTime, 10, 64)
+	next, _ := strconv.ParseInt(data.NextFundingTime, 10, 64)
+	interval := 0.0
+	if current > 0 && next > current {
+		interval = float64(next-current) / float64(time.Hour/time.Millisecond)
+	}
+	return &VenueFundingRate{Rate: rate, IntervalHours: interval, NextFundingTime: current}, nil
+}
+
+// fetchBitgetFunding reads /mix/market/current-fund-rate
+func fetchBitgetFunding(baseURL, symbol string) (*VenueFundingRate, error) {
+	var result struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			FundingRate         string `json:"fundingRate"`
+			FundingRateInterval string `json:"fundingRateInterval"`
+			NextUpdate          string `json:"nextUpdate"`
+		} `json:"data"`
+	}
+	url := fmt.Sprintf("%s/api/v2/mix/market/current-fund-rate?symbol=%s&productType=USDT-FUTURES", baseURL, symbol)
+	if err := fetchFundingJSON(url, nil, &result); err != nil {
+		return nil, err
+	}
+	if result.Code != "00000" || len(result.Data) == 0 {
+		return nil, fmt.Errorf("no funding data (code %s: %s)", result.Code, result.Msg)
+	}
+	data := result.Data[0]
+	rate, err := strconv.ParseFloat(data.FundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid funding rate %q", data.FundingRate)
+	}
+	interval, _ := strconv.ParseFloat(data.FundingRateInterval, 64)
+	next, _ := strconv.ParseInt(data.NextUpdate, 10, 64)
+	return &VenueFundingRate{Rate: rate, IntervalHours: interval, NextFundingTime: next}, nil
+}
+
+// fetchGateFunding reads the USDT contract, which reports the interval in seconds
+func fetchGateFunding(baseURL, symbol string) (*VenueFundingRate, error) {
+	var result struct {
+		FundingRate      string  `json:"funding_rate"`
+		FundingInterval  float64 `json:"funding_interval"`
+		FundingNextApply float64 `json:"funding_next_apply"`
+	}
+	contract := fundingBaseAsset(symbol) + "_USDT"
+	if err := fetchFundingJSON(fmt.Sprintf("%s/api/v4/futures/usdt/contracts/%s", baseURL, contract), nil, &result); err != nil {
+		return nil, err
+	}
+	rate, err := strconv.ParseFloat(result.FundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid funding rate %q", result.FundingRate)
+	}
+	return &VenueFundingRate{
+		Rate:            rate,
+		IntervalHours:   result.FundingInterval / 3600,
+		NextFundingTime: int64(result.FundingNextApply * 1000),
+	}, nil
+}
+
+// fetchKuCoinFunding reads the current funding rate; KuCoin lists BTC as XBT and reports granularity in ms
+func fetchKuCoinFunding(baseURL, symbol string) (*VenueFundingRate, error) {
+	var result struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			Value       float64 `json:"value"`
+			Granularity int64   `json:"granularity"`
+			TimePoint   int64   `json:"timePoint"`
+		} `json:"data"`
+	}
+	base := fundingBaseAsset(symbol)
+	if base == "BTC" {
+		base = "XBT"
+	}
+	if err := fetchFundingJSON(fmt.Sprintf("%s/api/v1/funding-rate/%sUSDTM/current", baseURL, base), nil, &result); err != nil {
+		return nil, err
+	}
+	if result.Code != "200000" {
+		return nil, fmt.Errorf("no funding data (code %s: %s)", result.Code, result.Msg)
+	}
+	interval := float64(result.Data.Granularity) / float64(time.Hour/time.Millisecond)
+	next := int64(0)
+	if result.Data.TimePoint > 0 {
+		next = result.Data.TimePoint + result.Data.Granularity
+	}
+	return &VenueFundingRate{Rate: result.Data.Value, IntervalHours: interval, NextFundingTime: next}, nil
+}
+
+// fetchHyperliquidFunding reads the asset contexts; Hyperliquid settles funding hourly
+func fetchHyperliquidFunding(baseURL, symbol string) (*VenueFundingRate, error) {
+	var result []json.RawMessage
+	if err := fetchFundingJSON(baseURL+"/info", map[string]string{"type": "metaAndAssetCtxs"}, &result); err != nil {
+		return nil, err
+	}
+	if len(result) < 2 {
+		return nil, fmt.Errorf("unexpected metaAndAssetCtxs response")
+	}
+	var meta struct {
+		Universe []struct {
+			Name string `json:"name"`
+		} `json:"universe"`
+	}
+	var ctxs []struct {
+		Funding string `json:"funding"`
+	}
+	if err := json.Unmarshal(result[0], &meta); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(result[1], &ctxs); err != nil {
+		return nil, err
+	}
+
+	coin := fundingBaseAsset(symbol)
+	for i, asset := range meta.Universe {
+		if asset.Name != coin || i >= len(ctxs) {
+			continue
+		}
+		rate, err := strconv.ParseFloat(ctxs[i].Funding, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid funding rate %q", ctxs[i].Funding)
+		}
+		return &VenueFundingRate{Rate: rate, IntervalHours: 1}, nil
+	}
+	return nil, fmt.Errorf("coin %s not listed", coin)
+}
@@ -0,0 +1,66 @@
+package market
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestGetVenueFundingRate_NormalisesIntervals checks rates from venues with different settlement
+// intervals come back comparable per hour
+func TestGetVenueFundingRate_NormalisesIntervals(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v5/public/funding-rate":
+			if r.URL.Query().Get("instId") != "ETH-USDT-SWAP" {
+				t.Errorf("unexpected OKX instId %s", r.URL.Query().Get("instId"))
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"code": "0",
+				"data": []map[string]string{{"fundingRate": "0.0004", "fundingTime": "1700000000000", "nextFundingTime": "1700014400000"}},
+			})
+		case "/api/v4/futures/usdt/contracts/ETH_USDT":
+			json.NewEncoder(w).Encode(map[string]interface{}{"funding_rate": "-0.0008", "funding_interval": 28800, "funding_next_apply": 1700000000})
+		case "/info":
+			json.NewEncoder(w).Encode([]interface{}{
+				map[string]interface{}{"universe": []map[string]string{{"name": "BTC"}, {"name": "ETH"}}},
+				[]map[string]string{{"funding": "0.00001"}, {"funding": "0.00005"}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	saved := fundingBaseURLs
+	fundingBaseURLs = map[string]string{"okx": server.URL, "gate": server.URL, "hyperliquid": server.URL}
+	defer func() { fundingBaseURLs = saved }()
+
+	tests := []struct {
+		exchange string
+		interval float64
+		hourly   float64
+	}{
+		{"okx", 4, 0.0001},          // 0.04% every 4h
+		{"gate", 8, -0.0001},        // -0.08% every 8h
+		{"hyperliquid", 1, 0.00005}, // hourly
+	}
+	for _, tt := range tests {
+		rate, err := GetVenueFundingRate(tt.exchange, "ETHUSDT")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.exchange, err)
+		}
+		if rate.IntervalHours != tt.interval {
+			t.Errorf("%s: expected %vh interval, got %v", tt.exchange, tt.interval, rate.IntervalHours)
+		}
+		if math.Abs(rate.HourlyRate()-tt.hourly) > 1e-12 {
+			t.Errorf("%s: expected hourly rate %v, got %v", tt.exchange, tt.hourly, rate.HourlyRate())
+		}
+	}
+
+	if _, err := GetVenueFundingRate("lighter", "ETHUSDT"); err == nil {
+		t.Error("expected an error for a venue without a funding endpoint")
+	}
+}
@@ -0,0 +1,226 @@
+package market
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Asset classes a backtest can run on. Crypto trades around the clock; the others follow a session calendar.
+const (
+	AssetCrypto = "crypto"
+	AssetStock  = "stock" // US equities, NYSE regular hours
+	AssetForex  = "forex"
+	AssetMetal  = "metal" // Spot gold/silver, quoted and traded on forex hours
+)
+
+// newYork is the reference timezone for US equity sessions and the forex week
+var newYork = loadNewYork()
+
+func loadNewYork() *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		// Without tzdata fall back to EST; sessions are then an hour off during daylight saving time
+		return time.FixedZone("EST", -5*60*60)
+	}
+	return loc
+}
+
+// NormalizeAssetClass validates an asset class, defaulting to crypto.
+func NormalizeAssetClass(class string) (string, error) {
+	class = strings.ToLower(strings.TrimSpace(class))
+	switch class {
+	case "":
+		return AssetCrypto, nil
+	case AssetCrypto, AssetStock, AssetForex, AssetMetal:
+		return class, nil
+	default:
+		return "", fmt.Errorf("unsupported asset class '%s'", class)
+	}
+}
+
+// NormalizeAssetSymbol normalizes a symbol for its asset class:
+// crypto goes through Normalize, stocks are upper-cased tickers and forex/metal pairs use the BASE/QUOTE form (EUR/USD, XAU/USD).
+func NormalizeAssetSymbol(class, symbol string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	switch class {
+	case AssetStock:
+		return symbol
+	case AssetForex, AssetMetal:
+		pair := strings.NewReplacer("/", "", "_", "", "-", "").Replace(symbol)
+		if len(pair) == 6 {
+			return pair[:3] + "/" + pair[3:]
+		}
+		return symbol
+	default:
+		return Normalize(symbol)
+	}
+}
+
+// InSession reports whether a bar spanning [openMs, closeMs] trades during the asset class's session.
+// Intraday bars count when any part of them overlaps the session; daily bars count when their date is a trading day.
+func InSession(class string, openMs, closeMs int64) bool {
+	switch class {
+	case AssetStock, AssetForex, AssetMetal:
+	default:
+		return true
+	}
+	if closeMs-openMs+1 >= (24 * time.Hour).Milliseconds() {
+		// Daily bars are stamped at the start of their date (UTC midnight, or NY midnight for Alpaca)
+		return IsTradingDay(class, time.UnixMilli(openMs).UTC().Add(12*time.Hour))
+	}
+	if class == AssetStock {
+		return overlapsStockSession(time.UnixMilli(openMs), time.UnixMilli(closeMs))
+	}
+	return forexOpen(time.UnixMilli(openMs)) || forexOpen(time.UnixMilli(closeMs))
+}
+
+// IsTradingDay reports whether the date of t (in New York) is a trading day for the asset class.
+func IsTradingDay(class string, t time.Time) bool {
+	t = t.In(newYork)
+	switch class {
+	case AssetStock:
+		return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday && !nyseHoliday(t)
+	case AssetForex, AssetMetal:
+		// The forex week closes Friday 17:00 and reopens Sunday 17:00, so Saturday is the only full day off
+		return t.Weekday() != time.Saturday && !forexHoliday(t)
+	default:
+		return true
+	}
+}
+
+// overlapsStockSession checks the regular session (09:30-16:00, 13:00 on early-close days) of each date the bar touches.
+func overlapsStockSession(open, close time.Time) bool {
+	for _, day := range []time.Time{open.In(newYork), close.In(newYork)} {
+		if !IsTradingDay(AssetStock, day) {
+			continue
+		}
+		y, m, d := day.Date()
+		sessionOpen := time.Date(y, m, d, 9, 30, 0, 0, newYork)
+		sessionClose := time.Date(y, m, d, 16, 0, 0, 0, newYork)
+		if nyseEarlyClose(day) {
+			sessionClose = time.Date(y, m, d, 13, 0, 0, 0, newYork)
+		}
+		if open.Before(sessionClose) && !close.Before(sessionOpen) {
+			return true
+		}
+	}
+	return false
+}
+
+// forexOpen reports whether the forex market is open at t: Sunday 17:00 to Friday 17:00 New York time.
+func forexOpen(t time.Time) bool {
+	t = t.In(newYork)
+	if forexHoliday(t) {
+		return false
+	}
+	switch t.Weekday() {
+	case time.Saturday:
+		return false
+	case time.Friday:
+		return t.Hour() < 17
+	case time.Sunday:
+		return t.Hour() >= 17
+	default:
+		return true
+	}
+}
+
+// forexHoliday covers the days interbank liquidity disappears: Christmas and New Year's Day.
+func forexHoliday(t time.Time) bool {
+	_, m, d := t.Date()
+	return (m == time.December && d == 25) || (m == time.January && d == 1)
+}
+
+// nyseHoliday reports whether the New York date of t is a full NYSE holiday.
+func nyseHoliday(t time.Time) bool {
+	y, m, d := t.Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	for _, h := range nyseHolidays(y) {
+		if h.Equal(date) {
+			return true
+		}
+	}
+	return false
+}
+
+// nyseHolidays lists the observed NYSE holidays of a year as UTC midnights.
+func nyseHolidays(year int) []time.Time {
+	fixed := func(m time.Month, d int) time.Time {
+		return observed(time.Date(year, m, d, 0, 0, 0, 0, time.UTC))
+	}
+	holidays := []time.Time{
+		nthWeekday(year, time.January, time.Monday, 3),    // Martin Luther King Jr. Day
+		nthWeekday(year, time.February, time.Monday, 3),   // Washington's Birthday
+		easter(year).AddDate(0, 0, -2),                    // Good Friday
+		lastWeekday(year, time.May, time.Monday),          // Memorial Day
+		fixed(time.July, 4),                               // Independence Day
+		nthWeekday(year, time.September, time.Monday, 1),  // Labor Day
+		nthWeekday(year, time.November, time.Thursday, 4), // Thanksgiving
+		fixed(time.December, 25),                          // Christmas
+	}
+	// NYSE does not observe a Saturday New Year's Day on the preceding Friday
+	if newYear := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC); newYear.Weekday() != time.Saturday {
+		holidays = append(holidays, observed(newYear))
+	}
+	if year >= 2022 {
+		holidays = append(holidays, fixed(time.June, 19)) // Juneteenth
+	}
+	return holidays
+}
+
+// nyseEarlyClose reports the 13:00 closes: July 3rd, the day after Thanksgiving and Christmas Eve.
+func nyseEarlyClose(t time.Time) bool {
+	y, m, d := t.Date()
+	switch {
+	case m == time.July && d == 3:
+		return true
+	case m == time.December && d == 24:
+		return true
+	case m == time.November:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Equal(nthWeekday(y, time.November, time.Thursday, 4).AddDate(0, 0, 1))
+	}
+	return false
+}
+
+// observed moves a fixed-date holiday off the weekend: Saturday to Friday, Sunday to Monday.
+func observed(t time.Time) time.Time {
+	switch t.Weekday() {
+	case time.Saturday:
+		return t.AddDate(0, 0, -1)
+	case time.Sunday:
+		return t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+7*(n-1))
+}
+
+func lastWeekday(year int, month time.Month, weekday time.Weekday) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+	offset := (int(last.Weekday()) - int(weekday) + 7) % 7
+	return last.AddDate(0, 0, -offset)
+}
+
+// easter returns Easter Sunday (Gregorian, anonymous algorithm).
+func easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,123 @@
+package market
+
+import (
+	"testing"
+	"time"
+)
+
+func nyTime(y int, m time.Month, d, hour, min int) time.Time {
+	return time.Date(y, m, d, hour, min, 0, 0, newYork)
+}
+
+func barInSession(class string, open time.Time, dur time.Duration) bool {
+	return InSession(class, open.UnixMilli(), open.Add(dur).UnixMilli()-1)
+}
+
+func TestNYSEHolidays(t *testing.T) {
+	holidays := []time.Time{
+		nyTime(2024, time.January, 1, 12, 0),   // New Year's Day
+		nyTime(2024, time.January, 15, 12, 0),  // MLK Day
+		nyTime(2024, time.February, 19, 12, 0), // Presidents' Day
+		nyTime(2024, time.March, 29, 12, 0),    // Good Friday
+		nyTime(2024, time.May, 27, 12, 0),      // Memorial Day
+		nyTime(2024, time.June, 19, 12, 0),     // Juneteenth
+		nyTime(2024, time.July, 4, 12, 0),      // Independence Day
+		nyTime(2024, time.September, 2, 12, 0), // Labor Day
+		nyTime(2024, time.November, 28, 12, 0), // Thanksgiving
+		nyTime(2024, time.December, 25, 12, 0), // Christmas
+		nyTime(2023, time.January, 2, 12, 0),   // New Year's Day observed on Monday
+		nyTime(2021, time.December, 24, 12, 0), // Christmas observed on Friday
+		nyTime(2026, time.July, 3, 12, 0),      // Independence Day observed on Friday
+	}
+	for _, day := range holidays {
+		if IsTradingDay(AssetStock, day) {
+			t.Errorf("%s should be an NYSE holiday", day.Format("2006-01-02"))
+		}
+	}
+
+	tradingDays := []time.Time{
+		nyTime(2021, time.June, 18, 12, 0),     // Juneteenth only observed from 2022
+		nyTime(2021, time.December, 31, 12, 0), // Saturday New Year's Day is not observed on Friday
+		nyTime(2024, time.March, 28, 12, 0),
+	}
+	for _, day := range tradingDays {
+		if !IsTradingDay(AssetStock, day) {
+			t.Errorf("%s should be a trading day", day.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestStockSessionHours(t *testing.T) {
+	tests := []struct {
+		name string
+		open time.Time
+		dur  time.Duration
+		want bool
+	}{
+		{"pre-market", nyTime(2024, time.March, 4, 9, 25), 5 * time.Minute, false},
+		{"first bar", nyTime(2024, time.March, 4, 9, 30), 5 * time.Minute, true},
+		{"last bar", nyTime(2024, time.March, 4, 15, 55), 5 * time.Minute, true},
+		{"after hours", nyTime(2024, time.March, 4, 16, 0), 5 * time.Minute, false},
+		{"hour bar straddling the open", nyTime(2024, time.March, 4, 9, 0), time.Hour, true},
+		{"weekend", nyTime(2024, time.March, 2, 11, 0), 5 * time.Minute, false},
+		{"holiday", nyTime(2024, time.July, 4, 11, 0), 5 * time.Minute, false},
+		{"early close", nyTime(2024, time.November, 29, 13, 0), 5 * time.Minute, false},
+		{"before early close", nyTime(2024, time.November, 29, 12, 55), 5 * time.Minute, true},
+		{"daily bar", time.Date(2024, time.March, 4, 5, 0, 0, 0, time.UTC), 24 * time.Hour, true},
+		{"daily bar on Good Friday", time.Date(2024, time.March, 29, 4, 0, 0, 0, time.UTC), 24 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		if got := barInSession(AssetStock, tt.open, tt.dur); got != tt.want {
+			t.Errorf("%s: InSession = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestForexSessionHours(t *testing.T) {
+	tests := []struct {
+		name string
+		open time.Time
+		want bool
+	}{
+		{"Friday before close", nyTime(2024, time.March, 8, 16, 0), true},
+		{"Friday after close", nyTime(2024, time.March, 8, 17, 0), false},
+		{"Saturday", nyTime(2024, time.March, 9, 12, 0), false},
+		{"Sunday before open", nyTime(2024, time.March, 10, 15, 0), false},
+		{"Sunday open", nyTime(2024, time.March, 10, 17, 0), true},
+		{"Wednesday night", nyTime(2024, time.March, 6, 23, 0), true},
+		{"Christmas", nyTime(2024, time.December, 25, 10, 0), false},
+	}
+	for _, tt := range tests {
+		for _, class := range []string{AssetForex, AssetMetal} {
+			if got := barInSession(class, tt.open, time.Hour); got != tt.want {
+				t.Errorf("%s %s: InSession = %v, want %v", class, tt.name, got, tt.want)
+			}
+		}
+	}
+	if !barInSession(AssetCrypto, nyTime(2024, time.March, 9, 12, 0), time.Hour) {
+		t.Error("crypto should trade on weekends")
+	}
+}
+
+func TestNormalizeAssetSymbol(t *testing.T) {
+	tests := []struct {
+		class, in, want string
+	}{
+		{AssetCrypto, "btc", "BTCUSDT"},
+		{AssetStock, " aapl ", "AAPL"},
+		{AssetForex, "eurusd", "EUR/USD"},
+		{AssetForex, "EUR/USD", "EUR/USD"},
+		{AssetMetal, "XAU_USD", "XAU/USD"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeAssetSymbol(tt.class, tt.in); got != tt.want {
+			t.Errorf("NormalizeAssetSymbol(%s, %q) = %q, want %q", tt.class, tt.in, got, tt.want)
+		}
+	}
+	if _, err := NormalizeAssetClass("bonds"); err == nil {
+		t.Error("expected an error for an unknown asset class")
+	}
+	if class, _ := NormalizeAssetClass(""); class != AssetCrypto {
+		t.Errorf("empty asset class should default to crypto, got %q", class)
+	}
+}
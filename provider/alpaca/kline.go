@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"nofx/config"
+	"strings"
 	"time"
 )
 
@@ -38,6 +39,7 @@ type BarsResponse struct {
 type Client struct {
 	apiKey    string
 	secretKey string
+	baseURL   string
 	client    *http.Client
 }
 
@@ -47,6 +49,7 @@ func NewClient() *Client {
 	return &Client{
 		apiKey:    cfg.AlpacaAPIKey,
 		secretKey: cfg.AlpacaSecretKey,
+		baseURL:   DataAPIURL,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -58,6 +61,7 @@ func NewClientWithKeys(apiKey, secretKey string) *Client {
 	return &Client{
 		apiKey:    apiKey,
 		secretKey: secretKey,
+		baseURL:   DataAPIURL,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -71,8 +75,6 @@ func (c *Client) GetBars(ctx context.Context, symbol string, timeframe string, l
 		return nil, fmt.Errorf("alpaca API keys not configured")
 	}
 
-	// Build URL
-	endpoint := fmt.Sprintf("%s/stocks/%s/bars", DataAPIURL, symbol)
 	params := url.Values{}
 	params.Set("timeframe", timeframe)
 	params.Set("limit", fmt.Sprintf("%d", limit))
@@ -91,7 +93,45 @@ func (c *Client) GetBars(ctx context.Context, symbol string, timeframe string, l
 	params.Set("start", start.Format(time.RFC3339))
 	params.Set("end", now.Format(time.RFC3339))
 
-	fullURL := endpoint + "?" + params.Encode()
+	result, err := c.getBars(ctx, symbol, params)
+	if err != nil {
+		return nil, err
+	}
+	return result.Bars, nil
+}
+
+// GetBarsRange fetches all bars between start and end (oldest first), following next_page_token.
+// Used by backtests, which need a fixed window rather than the most recent bars.
+func (c *Client) GetBarsRange(ctx context.Context, symbol string, timeframe string, start, end time.Time) ([]Bar, error) {
+	if c.apiKey == "" || c.secretKey == "" {
+		return nil, fmt.Errorf("alpaca API keys not configured")
+	}
+
+	params := url.Values{}
+	params.Set("timeframe", timeframe)
+	params.Set("limit", "10000")
+	params.Set("adjustment", "split") // Splits would otherwise show up as price gaps in long backtests
+	params.Set("feed", "iex")
+	params.Set("start", start.UTC().Format(time.RFC3339))
+	params.Set("end", end.UTC().Format(time.RFC3339))
+
+	var bars []Bar
+	for {
+		result, err := c.getBars(ctx, symbol, params)
+		if err != nil {
+			return nil, err
+		}
+		bars = append(bars, result.Bars...)
+		if result.NextPageToken == "" {
+			return bars, nil
+		}
+		params.Set("page_token", result.NextPageToken)
+	}
+}
+
+// getBars performs one bars request
+func (c *Client) getBars(ctx context.Context, symbol string, params url.Values) (*BarsResponse, error) {
+	fullURL := fmt.Sprintf("%s/stocks/%s/bars", c.baseURL, symbol) + "?" + params.Encode()
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
@@ -127,7 +167,7 @@ func (c *Client) GetBars(ctx context.Context, symbol string, timeframe string, l
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	return result.Bars, nil
+	return &result, nil
 }
 
 // MapTimeframe maps common timeframe strings to Alpaca format
@@ -169,3 +209,18 @@ func MapTimeframe(interval string) string {
 		return "5Min" // Default to 5 minutes
 	}
 }
+
+// RangeTimeframe maps a backtest timeframe to the exact Alpaca timeframe.
+// Unlike MapTimeframe it never substitutes a different bar size, since backtest bars must match the requested timeframe.
+func RangeTimeframe(interval string) (string, error) {
+	switch interval {
+	case "1m", "3m", "5m", "15m", "30m":
+		return strings.TrimSuffix(interval, "m") + "Min", nil
+	case "1h", "2h", "4h", "6h", "12h":
+		return strings.TrimSuffix(interval, "h") + "Hour", nil
+	case "1d":
+		return "1Day", nil
+	default:
+		return "", fmt.Errorf("alpaca has no %s bars", interval)
+	}
+}
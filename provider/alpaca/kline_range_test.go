@@ -0,0 +1,92 @@
+package alpaca
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// newFixtureServer serves recorded bars pages, choosing the page by page_token
+func newFixtureServer(t *testing.T, pages map[string]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("APCA-API-KEY-ID") != "key" || r.Header.Get("APCA-API-SECRET-KEY") != "secret" {
+			http.Error(w, `{"message":"forbidden"}`, http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/stocks/AAPL/bars" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("timeframe") != "5Min" || q.Get("start") != "2024-03-04T14:30:00Z" || q.Get("end") != "2024-03-04T15:00:00Z" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		file, ok := pages[q.Get("page_token")]
+		if !ok {
+			t.Errorf("unexpected page_token %q", q.Get("page_token"))
+			http.NotFound(w, r)
+			return
+		}
+		body, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("read fixture: %v", err)
+		}
+		w.Write(body)
+	}))
+}
+
+func TestGetBarsRangeFollowsPages(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{
+		"": "testdata/bars_aapl_page1.json",
+		"QUFQTHxNfDIwMjQtMDMtMDRUMTQ6MzU6MDAuMDAwMDAwMDAwWg==": "testdata/bars_aapl_page2.json",
+	})
+	defer srv.Close()
+
+	client := NewClientWithKeys("key", "secret")
+	client.baseURL = srv.URL
+
+	start := time.Date(2024, 3, 4, 14, 30, 0, 0, time.UTC)
+	bars, err := client.GetBarsRange(context.Background(), "AAPL", "5Min", start, start.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("GetBarsRange: %v", err)
+	}
+	if len(bars) != 3 {
+		t.Fatalf("expected 3 bars across both pages, got %d", len(bars))
+	}
+	if !bars[0].Timestamp.Equal(start) || bars[0].Open != 176.15 || bars[0].Volume != 412803 {
+		t.Errorf("first bar parsed wrong: %+v", bars[0])
+	}
+	if last := bars[2]; !last.Timestamp.Equal(start.Add(10*time.Minute)) || last.Close != 175.6 {
+		t.Errorf("last bar parsed wrong: %+v", last)
+	}
+}
+
+func TestGetBarsRangeAPIError(t *testing.T) {
+	srv := newFixtureServer(t, nil)
+	defer srv.Close()
+
+	client := NewClientWithKeys("wrong", "secret")
+	client.baseURL = srv.URL
+
+	start := time.Date(2024, 3, 4, 14, 30, 0, 0, time.UTC)
+	if _, err := client.GetBarsRange(context.Background(), "AAPL", "5Min", start, start.Add(30*time.Minute)); err == nil {
+		t.Fatal("expected an error for a 403 response")
+	}
+}
+
+func TestRangeTimeframe(t *testing.T) {
+	tests := map[string]string{"1m": "1Min", "3m": "3Min", "15m": "15Min", "1h": "1Hour", "6h": "6Hour", "1d": "1Day"}
+	for in, want := range tests {
+		got, err := RangeTimeframe(in)
+		if err != nil || got != want {
+			t.Errorf("RangeTimeframe(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := RangeTimeframe("1w"); err == nil {
+		t.Error("expected an error for an unsupported timeframe")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"nofx/config"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -70,14 +71,16 @@ type QuoteResponse struct {
 
 // Client is the Twelve Data API client
 type Client struct {
-	apiKey string
-	client *http.Client
+	apiKey  string
+	baseURL string
+	client  *http.Client
 }
 
 // NewClient creates a new Twelve Data client from config
 func NewClient() *Client {
 	return &Client{
-		apiKey: config.Get().TwelveDataKey,
+		apiKey:  config.Get().TwelveDataKey,
+		baseURL: BaseURL,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -87,7 +90,8 @@ func NewClient() *Client {
 // NewClientWithKey creates a new Twelve Data client with provided key
 func NewClientWithKey(apiKey string) *Client {
 	return &Client{
-		apiKey: apiKey,
+		apiKey:  apiKey,
+		baseURL: BaseURL,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -101,15 +105,60 @@ func (c *Client) GetTimeSeries(ctx context.Context, symbol string, interval stri
 		return nil, fmt.Errorf("twelve data API key not configured")
 	}
 
-	// Build URL
-	endpoint := fmt.Sprintf("%s/time_series", BaseURL)
 	params := url.Values{}
 	params.Set("symbol", symbol)
 	params.Set("interval", interval)
 	params.Set("outputsize", fmt.Sprintf("%d", limit))
-	params.Set("apikey", c.apiKey)
 
-	fullURL := endpoint + "?" + params.Encode()
+	return c.getTimeSeries(ctx, params)
+}
+
+// rangePageSize is the largest outputsize Twelve Data serves per request
+const rangePageSize = 5000
+
+// GetTimeSeriesRange fetches all bars between start and end, oldest first, with datetimes in UTC.
+// Used by backtests, which need a fixed window rather than the most recent bars.
+func (c *Client) GetTimeSeriesRange(ctx context.Context, symbol string, interval string, start, end time.Time) ([]Bar, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("twelve data API key not configured")
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("interval", interval)
+	params.Set("outputsize", fmt.Sprintf("%d", rangePageSize))
+	params.Set("timezone", "UTC")
+	params.Set("order", "ASC")
+	params.Set("end_date", end.UTC().Format("2006-01-02 15:04:05"))
+
+	var bars []Bar
+	from := start.UTC()
+	for {
+		params.Set("start_date", from.Format("2006-01-02 15:04:05"))
+		result, err := c.getTimeSeries(ctx, params)
+		if err != nil {
+			if len(bars) > 0 {
+				// A full last page leaves one more request, which reports no data once the range is exhausted
+				return bars, nil
+			}
+			return nil, err
+		}
+		bars = append(bars, result.Values...)
+		if len(result.Values) < rangePageSize {
+			return bars, nil
+		}
+		_, _, _, _, _, lastTS, err := ParseBar(result.Values[len(result.Values)-1])
+		if err != nil {
+			return nil, err
+		}
+		from = time.UnixMilli(lastTS).UTC().Add(time.Second)
+	}
+}
+
+// getTimeSeries performs one time_series request
+func (c *Client) getTimeSeries(ctx context.Context, params url.Values) (*TimeSeriesResponse, error) {
+	params.Set("apikey", c.apiKey)
+	fullURL := fmt.Sprintf("%s/time_series", c.baseURL) + "?" + params.Encode()
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
@@ -151,7 +200,7 @@ func (c *Client) GetQuote(ctx context.Context, symbol string) (*QuoteResponse, e
 	}
 
 	// Build URL
-	endpoint := fmt.Sprintf("%s/quote", BaseURL)
+	endpoint := fmt.Sprintf("%s/quote", c.baseURL)
 	params := url.Values{}
 	params.Set("symbol", symbol)
 	params.Set("apikey", c.apiKey)
@@ -231,6 +280,21 @@ func MapTimeframe(interval string) string {
 	}
 }
 
+// RangeInterval maps a backtest timeframe to the exact Twelve Data interval.
+// Unlike MapTimeframe it never substitutes a different bar size, since backtest bars must match the requested timeframe.
+func RangeInterval(interval string) (string, error) {
+	switch interval {
+	case "1m", "5m", "15m", "30m":
+		return strings.TrimSuffix(interval, "m") + "min", nil
+	case "1h", "2h", "4h":
+		return interval, nil
+	case "1d":
+		return "1day", nil
+	default:
+		return "", fmt.Errorf("twelve data has no %s interval", interval)
+	}
+}
+
 // ParseBar converts a Twelve Data bar to numeric values
 func ParseBar(bar Bar) (open, high, low, close, volume float64, timestamp int64, err error) {
 	open, err = strconv.ParseFloat(bar.Open, 64)
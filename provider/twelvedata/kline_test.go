@@ -0,0 +1,90 @@
+package twelvedata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// newFixtureServer answers every time_series request with a recorded response
+func newFixtureServer(t *testing.T, fixture string) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/time_series" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("apikey") != "key" || q.Get("symbol") != "EUR/USD" || q.Get("timezone") != "UTC" || q.Get("order") != "ASC" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		if q.Get("start_date") != "2024-03-04 00:00:00" || q.Get("end_date") != "2024-03-04 03:00:00" {
+			t.Errorf("unexpected range: %s - %s", q.Get("start_date"), q.Get("end_date"))
+		}
+		w.Write(body)
+	}))
+}
+
+func TestGetTimeSeriesRange(t *testing.T) {
+	srv := newFixtureServer(t, "testdata/time_series_eurusd.json")
+	defer srv.Close()
+
+	client := NewClientWithKey("key")
+	client.baseURL = srv.URL
+
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	bars, err := client.GetTimeSeriesRange(context.Background(), "EUR/USD", "1h", start, start.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("GetTimeSeriesRange: %v", err)
+	}
+	if len(bars) != 3 {
+		t.Fatalf("expected 3 bars, got %d", len(bars))
+	}
+
+	open, _, _, closePrice, volume, ts, err := ParseBar(bars[0])
+	if err != nil {
+		t.Fatalf("ParseBar: %v", err)
+	}
+	if open != 1.0834 || closePrice != 1.08371 || volume != 0 || ts != start.UnixMilli() {
+		t.Errorf("first bar parsed wrong: open=%v close=%v volume=%v ts=%d", open, closePrice, volume, ts)
+	}
+	_, _, _, _, _, lastTS, _ := ParseBar(bars[2])
+	if lastTS != start.Add(2*time.Hour).UnixMilli() {
+		t.Errorf("bars should be oldest first, last bar at %d", lastTS)
+	}
+}
+
+func TestGetTimeSeriesRangeNoData(t *testing.T) {
+	srv := newFixtureServer(t, "testdata/error_no_data.json")
+	defer srv.Close()
+
+	client := NewClientWithKey("key")
+	client.baseURL = srv.URL
+
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	if _, err := client.GetTimeSeriesRange(context.Background(), "EUR/USD", "1h", start, start.Add(3*time.Hour)); err == nil {
+		t.Fatal("expected the API error to be returned")
+	}
+}
+
+func TestRangeInterval(t *testing.T) {
+	tests := map[string]string{"1m": "1min", "30m": "30min", "1h": "1h", "4h": "4h", "1d": "1day"}
+	for in, want := range tests {
+		got, err := RangeInterval(in)
+		if err != nil || got != want {
+			t.Errorf("RangeInterval(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, tf := range []string{"3m", "6h", "12h"} {
+		if _, err := RangeInterval(tf); err == nil {
+			t.Errorf("expected an error for %s", tf)
+		}
+	}
+}
@@ -145,15 +145,16 @@ func (BacktestCheckpoint) TableName() string {
 
 // BacktestEquity GORM model
 type BacktestEquity struct {
-	ID        int64   `gorm:"primaryKey;autoIncrement"`
-	RunID     string  `gorm:"column:run_id;not null;index:idx_backtest_equity_run_ts"`
-	TS        int64   `gorm:"column:ts;type:bigint;not null;index:idx_backtest_equity_run_ts"`
-	Equity    float64 `gorm:"column:equity;not null"`
-	Available float64 `gorm:"column:available;not null"`
-	PnL       float64 `gorm:"column:pnl;not null"`
-	PnLPct    float64 `gorm:"column:pnl_pct;not null"`
-	DDPct     float64 `gorm:"column:dd_pct;not null"`
-	Cycle     int     `gorm:"column:cycle;not null"`
+	ID         int64   `gorm:"primaryKey;autoIncrement"`
+	RunID      string  `gorm:"column:run_id;not null;index:idx_backtest_equity_run_ts"`
+	TS         int64   `gorm:"column:ts;type:bigint;not null;index:idx_backtest_equity_run_ts"`
+	Equity     float64 `gorm:"column:equity;not null"`
+	Available  float64 `gorm:"column:available;not null"`
+	PnL        float64 `gorm:"column:pnl;not null"`
+	PnLPct     float64 `gorm:"column:pnl_pct;not null"`
+	DDPct      float64 `gorm:"column:dd_pct;not null"`
+	Cycle      int     `gorm:"column:cycle;not null"`
+	Benchmarks string  `gorm:"column:benchmarks;default:''"` // JSON map of benchmark equity, empty when none
 }
 
 func (BacktestEquity) TableName() string {
@@ -209,6 +210,19 @@ func (BacktestDecision) TableName() string {
 	return "backtest_decisions"
 }
 
+// BacktestAnalysis GORM model for multi-run analyses (parameter sweeps, walk-forward), one JSON document each
+type BacktestAnalysis struct {
+	ID        string    `gorm:"column:id;primaryKey"`
+	Kind      string    `gorm:"column:kind;not null;index:idx_backtest_analyses_kind"`
+	Payload   []byte    `gorm:"column:payload;not null"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (BacktestAnalysis) TableName() string {
+	return "backtest_analyses"
+}
+
 // initTables initializes backtest related tables
 func (s *BacktestStore) initTables() error {
 	// For PostgreSQL with existing tables, skip AutoMigrate to avoid type conflicts
@@ -221,9 +235,16 @@ func (s *BacktestStore) initTables() error {
 			// Fix ts column type from INTEGER to BIGINT (timestamps in milliseconds exceed int4 max)
 			s.db.Exec(`ALTER TABLE backtest_equity ALTER COLUMN ts TYPE BIGINT`)
 			s.db.Exec(`ALTER TABLE backtest_trades ALTER COLUMN ts TYPE BIGINT`)
+			s.db.Exec(`ALTER TABLE backtest_equity ADD COLUMN IF NOT EXISTS benchmarks TEXT DEFAULT ''`)
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_backtest_equity_run_ts ON backtest_equity(run_id, ts)`)
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_backtest_trades_run_ts ON backtest_trades(run_id, ts)`)
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_backtest_decisions_run_cycle ON backtest_decisions(run_id, cycle)`)
+			// Analyses were added after the initial schema
+			if !s.db.Migrator().HasTable(&BacktestAnalysis{}) {
+				if err := s.db.AutoMigrate(&BacktestAnalysis{}); err != nil {
+					return fmt.Errorf("failed to migrate backtest_analyses table: %w", err)
+				}
+			}
 			return nil
 		}
 	}
@@ -236,6 +257,7 @@ func (s *BacktestStore) initTables() error {
 		&BacktestTrade{},
 		&BacktestMetrics{},
 		&BacktestDecision{},
+		&BacktestAnalysis{},
 	); err != nil {
 		return fmt.Errorf("failed to migrate backtest tables: %w", err)
 	}
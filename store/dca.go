@@ -0,0 +1,179 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DCA deal statuses
+const (
+	DCADealActive    = "active"    // Base order filled, safety/take-profit orders working
+	DCADealCompleted = "completed" // Take-profit filled
+	DCADealCancelled = "cancelled" // Stopped manually or closed outside the bot
+)
+
+// DCA order kinds
+const (
+	DCAOrderBase       = "base"
+	DCAOrderSafety     = "safety"
+	DCAOrderTakeProfit = "take_profit"
+)
+
+// DCA order statuses
+const (
+	DCAOrderPending   = "pending"   // Planned, not yet on the exchange
+	DCAOrderOpen      = "open"      // Resting on the exchange
+	DCAOrderFilled    = "filled"    // Filled
+	DCAOrderCancelled = "cancelled" // Cancelled or replaced
+)
+
+// DCADeal one DCA deal: a base order plus the safety orders averaging into it
+// All time fields use int64 millisecond timestamps (UTC) to avoid timezone issues
+type DCADeal struct {
+	ID                 int64   `gorm:"primaryKey;autoIncrement" json:"id"`
+	TraderID           string  `gorm:"column:trader_id;not null;index:idx_dca_deals_trader_status" json:"trader_id"`
+	Symbol             string  `gorm:"column:symbol;not null" json:"symbol"`
+	Side               string  `gorm:"column:side;not null" json:"side"` // LONG or SHORT
+	Status             string  `gorm:"column:status;not null;default:active;index:idx_dca_deals_trader_status" json:"status"`
+	BasePrice          float64 `gorm:"column:base_price;default:0" json:"base_price"` // Base order fill price, anchor for safety levels
+	AvgEntryPrice      float64 `gorm:"column:avg_entry_price;default:0" json:"avg_entry_price"`
+	Quantity           float64 `gorm:"column:quantity;default:0" json:"quantity"`
+	Cost               float64 `gorm:"column:cost;default:0" json:"cost"` // Sum of price × quantity over all fills
+	SafetyOrdersFilled int     `gorm:"column:safety_orders_filled;default:0" json:"safety_orders_filled"`
+	TakeProfitPrice    float64 `gorm:"column:take_profit_price;default:0" json:"take_profit_price"`
+	TakeProfitOrderID  string  `gorm:"column:take_profit_order_id;default:''" json:"take_profit_order_id"`
+	ExitPrice          float64 `gorm:"column:exit_price;default:0" json:"exit_price"`
+	RealizedPnL        float64 `gorm:"column:realized_pnl;default:0" json:"realized_pnl"`
+	PositionID         int64   `gorm:"column:position_id;default:0" json:"position_id"` // trader_positions row reporting this deal
+	StartReason        string  `gorm:"column:start_reason;default:''" json:"start_reason"`
+	StartedAt          int64   `gorm:"column:started_at" json:"started_at"` // Unix milliseconds UTC
+	ClosedAt           int64   `gorm:"column:closed_at;default:0" json:"closed_at"`
+	UpdatedAt          int64   `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name
+func (DCADeal) TableName() string {
+	return "dca_deals"
+}
+
+// DCAOrder one order of a DCA deal
+type DCAOrder struct {
+	ID        int64   `gorm:"primaryKey;autoIncrement" json:"id"`
+	DealID    int64   `gorm:"column:deal_id;not null;index" json:"deal_id"`
+	Kind      string  `gorm:"column:kind;not null" json:"kind"`
+	Index     int     `gorm:"column:order_index;default:0" json:"index"` // Safety order number (1-based), 0 for base/take-profit
+	Price     float64 `gorm:"column:price;default:0" json:"price"`
+	Quantity  float64 `gorm:"column:quantity;default:0" json:"quantity"`
+	OrderID   string  `gorm:"column:order_id;default:''" json:"order_id"`
+	ClientID  string  `gorm:"column:client_id;default:''" json:"client_id"`
+	Status    string  `gorm:"column:status;not null;default:pending" json:"status"`
+	FilledAt  int64   `gorm:"column:filled_at;default:0" json:"filled_at"`
+	CreatedAt int64   `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt int64   `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name
+func (DCAOrder) TableName() string {
+	return "dca_orders"
+}
+
+// DCAStore DCA bot deal and safety-order storage
+type DCAStore struct {
+	db *gorm.DB
+}
+
+// NewDCAStore creates DCA storage instance
+func NewDCAStore(db *gorm.DB) *DCAStore {
+	return &DCAStore{db: db}
+}
+
+// InitTables initializes DCA tables
+func (s *DCAStore) InitTables() error {
+	if s.db.Dialector.Name() == "postgres" {
+		var tableExists int64
+		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'dca_deals'`).Scan(&tableExists)
+		if tableExists > 0 {
+			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_dca_orders_deal_id ON dca_orders(deal_id)`)
+			return nil
+		}
+	}
+	if err := s.db.AutoMigrate(&DCADeal{}, &DCAOrder{}); err != nil {
+		return fmt.Errorf("failed to migrate dca tables: %w", err)
+	}
+	return nil
+}
+
+// SaveDeal creates or updates a deal
+func (s *DCAStore) SaveDeal(deal *DCADeal) error {
+	nowMs := time.Now().UTC().UnixMilli()
+	if deal.StartedAt == 0 {
+		deal.StartedAt = nowMs
+	}
+	deal.UpdatedAt = nowMs
+	return s.db.Save(deal).Error
+}
+
+// SaveOrder creates or updates a deal order
+func (s *DCAStore) SaveOrder(order *DCAOrder) error {
+	nowMs := time.Now().UTC().UnixMilli()
+	if order.CreatedAt == 0 {
+		order.CreatedAt = nowMs
+	}
+	order.UpdatedAt = nowMs
+	return s.db.Save(order).Error
+}
+
+// GetActiveDeal returns the trader's active deal, or nil if it has none
+func (s *DCAStore) GetActiveDeal(traderID string) (*DCADeal, error) {
+	var deals []DCADeal
+	err := s.db.Where("trader_id = ? AND status = ?", traderID, DCADealActive).
+		Order("started_at DESC").Limit(1).Find(&deals).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(deals) == 0 {
+		return nil, nil
+	}
+	return &deals[0], nil
+}
+
+// GetDealOrders returns a deal's orders, base first then safety orders in index order
+func (s *DCAStore) GetDealOrders(dealID int64) ([]DCAOrder, error) {
+	var orders []DCAOrder
+	err := s.db.Where("deal_id = ?", dealID).Order("order_index ASC, id ASC").Find(&orders).Error
+	return orders, err
+}
+
+// GetLastClosedDeal returns the trader's most recently closed deal, or nil if none
+func (s *DCAStore) GetLastClosedDeal(traderID string) (*DCADeal, error) {
+	var deals []DCADeal
+	err := s.db.Where("trader_id = ? AND status <> ?", traderID, DCADealActive).
+		Order("closed_at DESC").Limit(1).Find(&deals).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(deals) == 0 {
+		return nil, nil
+	}
+	return &deals[0], nil
+}
+
+// CountCompletedDeals returns how many deals the trader has closed at take-profit
+func (s *DCAStore) CountCompletedDeals(traderID string) (int64, error) {
+	var count int64
+	err := s.db.Model(&DCADeal{}).Where("trader_id = ? AND status = ?", traderID, DCADealCompleted).Count(&count).Error
+	return count, err
+}
+
+// ListDeals returns the trader's most recent deals, newest first
+func (s *DCAStore) ListDeals(traderID string, limit int) ([]DCADeal, error) {
+	var deals []DCADeal
+	query := s.db.Where("trader_id = ?", traderID).Order("started_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&deals).Error
+	return deals, err
+}
@@ -80,19 +80,21 @@ type PositionSnapshot struct {
 
 // DecisionAction decision action
 type DecisionAction struct {
-	Action     string    `json:"action"`
-	Symbol     string    `json:"symbol"`
-	Quantity   float64   `json:"quantity"`
-	Leverage   int       `json:"leverage"`
-	Price      float64   `json:"price"`
-	StopLoss   float64   `json:"stop_loss,omitempty"`   // Stop loss price
-	TakeProfit float64   `json:"take_profit,omitempty"` // Take profit price
-	Confidence int       `json:"confidence,omitempty"`  // AI confidence (0-100)
-	Reasoning  string    `json:"reasoning,omitempty"`   // Brief reasoning
-	OrderID    int64     `json:"order_id"`
-	Timestamp  time.Time `json:"timestamp"`
-	Success    bool      `json:"success"`
-	Error      string    `json:"error"`
+	Action               string    `json:"action"`
+	Symbol               string    `json:"symbol"`
+	Quantity             float64   `json:"quantity"`
+	Leverage             int       `json:"leverage"`
+	Price                float64   `json:"price"`
+	StopLoss             float64   `json:"stop_loss,omitempty"`              // Stop loss price
+	TakeProfit           float64   `json:"take_profit,omitempty"`            // Take profit price
+	TrailingCallbackRate float64   `json:"trailing_callback_rate,omitempty"` // Trailing stop callback (percent)
+	Confidence           int       `json:"confidence,omitempty"`             // AI confidence (0-100)
+	Reasoning            string    `json:"reasoning,omitempty"`              // Brief reasoning
+	OrderID              int64     `json:"order_id"`
+	ClientOrderID        string    `json:"client_order_id,omitempty"` // Deterministic client order ID (retry-safe)
+	Timestamp            time.Time `json:"timestamp"`
+	Success              bool      `json:"success"`
+	Error                string    `json:"error"`
 }
 
 // Statistics statistics information
@@ -246,6 +248,24 @@ func (s *DecisionStore) GetRecordsByDate(traderID string, date time.Time) ([]*De
 	return records, nil
 }
 
+// GetRecordsInRange gets all records for a specified trader between start and end (inclusive), old to new
+func (s *DecisionStore) GetRecordsInRange(traderID string, start, end time.Time) ([]*DecisionRecord, error) {
+	var dbRecords []*DecisionRecordDB
+	err := s.db.Where("trader_id = ? AND timestamp >= ? AND timestamp <= ?", traderID, start, end).
+		Order("timestamp ASC").
+		Find(&dbRecords).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query decision records: %w", err)
+	}
+
+	records := make([]*DecisionRecord, len(dbRecords))
+	for i, db := range dbRecords {
+		records[i] = db.toRecord()
+	}
+
+	return records, nil
+}
+
 // CleanOldRecords cleans old records from N days ago
 func (s *DecisionStore) CleanOldRecords(traderID string, days int) (int64, error) {
 	cutoffTime := time.Now().AddDate(0, 0, -days)
@@ -0,0 +1,182 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Drift kinds detected by the position reconciler
+const (
+	DriftMissingLocal    = "missing_local"    // Exchange holds a position that has no local record
+	DriftMissingExchange = "missing_exchange" // Local record is open but the exchange is flat
+	DriftSizeDrift       = "size_drift"       // Both sides have the position but quantities differ
+	DriftSideMismatch    = "side_mismatch"    // Exchange and local hold opposite sides of the same symbol
+)
+
+// Drift statuses
+const (
+	DriftStatusOpen     = "OPEN"     // Needs manual attention
+	DriftStatusHealed   = "HEALED"   // Fixed automatically by the reconciler
+	DriftStatusResolved = "RESOLVED" // Marked resolved by the user
+)
+
+// PositionDrift a mismatch between local position records and the exchange
+// All time fields use int64 millisecond timestamps (UTC) to avoid timezone issues
+type PositionDrift struct {
+	ID               int64   `gorm:"primaryKey;autoIncrement" json:"id"`
+	TraderID         string  `gorm:"column:trader_id;not null;index:idx_drifts_trader_status" json:"trader_id"`
+	ExchangeID       string  `gorm:"column:exchange_id;not null;default:''" json:"exchange_id"`
+	Symbol           string  `gorm:"column:symbol;not null" json:"symbol"`
+	Side             string  `gorm:"column:side;not null" json:"side"` // LONG or SHORT
+	Kind             string  `gorm:"column:kind;not null" json:"kind"`
+	LocalQuantity    float64 `gorm:"column:local_quantity;default:0" json:"local_quantity"`
+	ExchangeQuantity float64 `gorm:"column:exchange_quantity;default:0" json:"exchange_quantity"`
+	OrderQuantity    float64 `gorm:"column:order_quantity;default:0" json:"order_quantity"` // Net quantity implied by OrderStore fills
+	LocalPositionID  int64   `gorm:"column:local_position_id;default:0" json:"local_position_id"`
+	Status           string  `gorm:"column:status;not null;default:OPEN;index:idx_drifts_trader_status" json:"status"`
+	Detail           string  `gorm:"column:detail;default:''" json:"detail"`
+	Resolution       string  `gorm:"column:resolution;default:''" json:"resolution"`
+	Occurrences      int     `gorm:"column:occurrences;default:1" json:"occurrences"`
+	FirstSeenAt      int64   `gorm:"column:first_seen_at" json:"first_seen_at"` // Unix milliseconds UTC
+	LastSeenAt       int64   `gorm:"column:last_seen_at" json:"last_seen_at"`   // Unix milliseconds UTC
+	ResolvedAt       int64   `gorm:"column:resolved_at;default:0" json:"resolved_at"`
+}
+
+// TableName returns the table name
+func (PositionDrift) TableName() string {
+	return "trader_position_drifts"
+}
+
+// DriftStore persisted queue of position drifts the reconciler could not heal
+type DriftStore struct {
+	db *gorm.DB
+}
+
+// NewDriftStore creates drift storage instance
+func NewDriftStore(db *gorm.DB) *DriftStore {
+	return &DriftStore{db: db}
+}
+
+// InitTables initializes drift tables
+func (s *DriftStore) InitTables() error {
+	if s.db.Dialector.Name() == "postgres" {
+		var tableExists int64
+		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'trader_position_drifts'`).Scan(&tableExists)
+		if tableExists > 0 {
+			return nil
+		}
+	}
+	if err := s.db.AutoMigrate(&PositionDrift{}); err != nil {
+		return fmt.Errorf("failed to migrate trader_position_drifts table: %w", err)
+	}
+	return nil
+}
+
+// Record upserts the drift of a trader/symbol/side: repeated detections update its OPEN row, or a HEALED
+// row last seen within confirmWindow (the heal didn't stick), bumping LastSeenAt and Occurrences so the table
+// doesn't grow every pass. A drift that returns after an older heal gets a new row, keeping the audit trail
+func (s *DriftStore) Record(drift *PositionDrift, confirmWindow time.Duration) error {
+	nowMs := time.Now().UTC().UnixMilli()
+	if drift.Status == "" {
+		drift.Status = DriftStatusOpen
+	}
+	var resolvedAt int64
+	if drift.Status != DriftStatusOpen {
+		resolvedAt = nowMs
+	}
+
+	var existing PositionDrift
+	err := s.db.Where("trader_id = ? AND symbol = ? AND side = ?", drift.TraderID, drift.Symbol, drift.Side).
+		Where("status = ? OR (status = ? AND last_seen_at >= ?)",
+			DriftStatusOpen, DriftStatusHealed, nowMs-confirmWindow.Milliseconds()).
+		Order("last_seen_at DESC").
+		First(&existing).Error
+	if err == nil {
+		drift.ID = existing.ID
+		return s.db.Model(&PositionDrift{}).Where("id = ?", existing.ID).Updates(map[string]interface{}{
+			"kind":              drift.Kind,
+			"status":            drift.Status,
+			"resolution":        drift.Resolution,
+			"local_quantity":    drift.LocalQuantity,
+			"exchange_quantity": drift.ExchangeQuantity,
+			"order_quantity":    drift.OrderQuantity,
+			"local_position_id": drift.LocalPositionID,
+			"detail":            drift.Detail,
+			"occurrences":       existing.Occurrences + 1,
+			"last_seen_at":      nowMs,
+			"resolved_at":       resolvedAt,
+		}).Error
+	}
+	if err != gorm.ErrRecordNotFound {
+		return fmt.Errorf("failed to query drift: %w", err)
+	}
+
+	if drift.Occurrences == 0 {
+		drift.Occurrences = 1
+	}
+	drift.FirstSeenAt = nowMs
+	drift.LastSeenAt = nowMs
+	drift.ResolvedAt = resolvedAt
+	return s.db.Create(drift).Error
+}
+
+// List returns drifts for a trader, newest first (status="" means all)
+func (s *DriftStore) List(traderID, status string, limit int) ([]*PositionDrift, error) {
+	var drifts []*PositionDrift
+	query := s.db.Where("trader_id = ?", traderID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	err := query.Order("last_seen_at DESC").Limit(limit).Find(&drifts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query drifts: %w", err)
+	}
+	return drifts, nil
+}
+
+// Get returns a single drift by ID (nil if not found)
+func (s *DriftStore) Get(id int64) (*PositionDrift, error) {
+	var drift PositionDrift
+	err := s.db.Where("id = ?", id).First(&drift).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get drift: %w", err)
+	}
+	return &drift, nil
+}
+
+// Resolve marks a drift as no longer open
+func (s *DriftStore) Resolve(id int64, status, resolution string) error {
+	return s.db.Model(&PositionDrift{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":      status,
+		"resolution":  resolution,
+		"resolved_at": time.Now().UTC().UnixMilli(),
+	}).Error
+}
+
+// ResolveStale closes open drifts for a trader that were not seen in the latest reconcile pass
+// seen holds "symbol_side" keys detected in that pass
+func (s *DriftStore) ResolveStale(traderID string, seen map[string]bool) (int, error) {
+	open, err := s.List(traderID, DriftStatusOpen, 1000)
+	if err != nil {
+		return 0, err
+	}
+	resolved := 0
+	for _, d := range open {
+		if seen[d.Symbol+"_"+d.Side] {
+			continue
+		}
+		if err := s.Resolve(d.ID, DriftStatusResolved, "no longer detected"); err != nil {
+			return resolved, err
+		}
+		resolved++
+	}
+	return resolved, nil
+}
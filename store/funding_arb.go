@@ -0,0 +1,126 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Funding arbitrage pair statuses
+const (
+	FundingArbPairOpen      = "open"
+	FundingArbPairUnwinding = "unwinding" // Only the first leg got on and closing it failed; retried every cycle
+	FundingArbPairClosed    = "closed"
+)
+
+// Funding arbitrage close reasons
+const (
+	FundingArbCloseSpread     = "spread_compressed" // Funding differential fell below ExitSpreadAPR
+	FundingArbCloseDrift      = "delta_drift"       // Leg notionals drifted apart
+	FundingArbCloseMargin     = "margin_stress"     // A leg got too close to liquidation
+	FundingArbCloseLegGone    = "leg_missing"       // A leg was closed or liquidated outside the bot
+	FundingArbCloseOpenFailed = "open_failed"       // The second leg failed to open and the first was unwound
+	FundingArbCloseManual     = "manual"
+)
+
+// FundingArbPair a long/short position pair on two exchanges, managed as one position
+// All time fields use int64 millisecond timestamps (UTC) to avoid timezone issues
+type FundingArbPair struct {
+	ID       int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	TraderID string `gorm:"column:trader_id;not null;index:idx_funding_arb_trader_status" json:"trader_id"`
+	Symbol   string `gorm:"column:symbol;not null" json:"symbol"`
+	Status   string `gorm:"column:status;not null;default:open;index:idx_funding_arb_trader_status" json:"status"`
+
+	// Long leg (venue paying the lowest funding)
+	LongExchangeID   string  `gorm:"column:long_exchange_id;not null" json:"long_exchange_id"`
+	LongExchange     string  `gorm:"column:long_exchange;not null" json:"long_exchange"`
+	LongQuantity     float64 `gorm:"column:long_quantity;default:0" json:"long_quantity"`
+	LongEntryPrice   float64 `gorm:"column:long_entry_price;default:0" json:"long_entry_price"`
+	LongExitPrice    float64 `gorm:"column:long_exit_price;default:0" json:"long_exit_price"`
+	LongEntryOrderID string  `gorm:"column:long_entry_order_id;default:''" json:"long_entry_order_id"`
+
+	// Short leg (venue charging the highest funding)
+	ShortExchangeID   string  `gorm:"column:short_exchange_id;not null" json:"short_exchange_id"`
+	ShortExchange     string  `gorm:"column:short_exchange;not null" json:"short_exchange"`
+	ShortQuantity     float64 `gorm:"column:short_quantity;default:0" json:"short_quantity"`
+	ShortEntryPrice   float64 `gorm:"column:short_entry_price;default:0" json:"short_entry_price"`
+	ShortExitPrice    float64 `gorm:"column:short_exit_price;default:0" json:"short_exit_price"`
+	ShortEntryOrderID string  `gorm:"column:short_entry_order_id;default:''" json:"short_entry_order_id"`
+
+	Leverage         int     `gorm:"column:leverage;default:1" json:"leverage"`
+	NotionalUSD      float64 `gorm:"column:notional_usd;default:0" json:"notional_usd"`
+	EntrySpreadAPR   float64 `gorm:"column:entry_spread_apr;default:0" json:"entry_spread_apr"`     // Annualised funding spread at entry (%)
+	CurrentSpreadAPR float64 `gorm:"column:current_spread_apr;default:0" json:"current_spread_apr"` // Latest annualised funding spread (%)
+	EstimatedFunding float64 `gorm:"column:estimated_funding;default:0" json:"estimated_funding"`   // Funding collected minus paid, estimated from published rates rather than exchange settlements (USDT)
+	DeltaDriftPct    float64 `gorm:"column:delta_drift_pct;default:0" json:"delta_drift_pct"`       // |long notional - short notional| / pair notional (%)
+	PricePnL         float64 `gorm:"column:price_pnl;default:0" json:"price_pnl"`                   // Combined leg PnL from price moves (USDT)
+	RealizedPnL      float64 `gorm:"column:realized_pnl;default:0" json:"realized_pnl"`             // PricePnL + EstimatedFunding at close
+	CloseReason      string  `gorm:"column:close_reason;default:''" json:"close_reason"`
+	LastAccrualAt    int64   `gorm:"column:last_accrual_at" json:"last_accrual_at"` // Funding accrued up to here (Unix ms UTC)
+	OpenedAt         int64   `gorm:"column:opened_at" json:"opened_at"`
+	ClosedAt         int64   `gorm:"column:closed_at;default:0" json:"closed_at"`
+	UpdatedAt        int64   `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name
+func (FundingArbPair) TableName() string {
+	return "funding_arb_pairs"
+}
+
+// FundingArbStore linked long/short pair storage for funding arbitrage
+type FundingArbStore struct {
+	db *gorm.DB
+}
+
+// NewFundingArbStore creates funding arbitrage storage instance
+func NewFundingArbStore(db *gorm.DB) *FundingArbStore {
+	return &FundingArbStore{db: db}
+}
+
+// InitTables initializes funding arbitrage tables
+func (s *FundingArbStore) InitTables() error {
+	if s.db.Dialector.Name() == "postgres" {
+		var tableExists int64
+		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'funding_arb_pairs'`).Scan(&tableExists)
+		if tableExists > 0 {
+			return nil
+		}
+	}
+	if err := s.db.AutoMigrate(&FundingArbPair{}); err != nil {
+		return fmt.Errorf("failed to migrate funding_arb_pairs table: %w", err)
+	}
+	return nil
+}
+
+// Save creates or updates a pair
+func (s *FundingArbStore) Save(pair *FundingArbPair) error {
+	nowMs := time.Now().UTC().UnixMilli()
+	if pair.OpenedAt == 0 {
+		pair.OpenedAt = nowMs
+	}
+	if pair.LastAccrualAt == 0 {
+		pair.LastAccrualAt = pair.OpenedAt
+	}
+	pair.UpdatedAt = nowMs
+	return s.db.Save(pair).Error
+}
+
+// GetOpenPairs returns the trader's open and unwinding pairs, oldest first
+func (s *FundingArbStore) GetOpenPairs(traderID string) ([]*FundingArbPair, error) {
+	var pairs []*FundingArbPair
+	err := s.db.Where("trader_id = ? AND status IN ?", traderID, []string{FundingArbPairOpen, FundingArbPairUnwinding}).
+		Order("opened_at ASC").Find(&pairs).Error
+	return pairs, err
+}
+
+// ListPairs returns the trader's most recent pairs, newest first
+func (s *FundingArbStore) ListPairs(traderID string, limit int) ([]*FundingArbPair, error) {
+	var pairs []*FundingArbPair
+	query := s.db.Where("trader_id = ?", traderID).Order("opened_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&pairs).Error
+	return pairs, err
+}
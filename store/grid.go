@@ -83,6 +83,9 @@ type GridInstanceModel struct {
 	StoppedAt *time.Time `json:"stopped_at,omitempty"`
 	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 
+	// Grid config the instance was laid out with (JSON); a restart under a different config retires it
+	ConfigSnapshot string `json:"config_snapshot,omitempty" gorm:"type:text"`
+
 	CurrentUpperPrice   float64 `json:"current_upper_price"`
 	CurrentLowerPrice   float64 `json:"current_lower_price"`
 	CurrentGridSpacing  float64 `json:"current_grid_spacing"`
@@ -127,6 +130,11 @@ type GridInstanceModel struct {
 	DailyProfit     float64   `json:"daily_profit" gorm:"default:0"`
 	DailyLoss       float64   `json:"daily_loss" gorm:"default:0"`
 	LastDailyReset  time.Time `json:"last_daily_reset"`
+
+	// Trailing grid state
+	InventoryQty   float64 `json:"inventory_qty" gorm:"default:0"`
+	InventoryEntry float64 `json:"inventory_entry" gorm:"default:0"`
+	TrailCount     int     `json:"trail_count" gorm:"default:0"`
 }
 
 func (GridInstanceModel) TableName() string {
@@ -234,6 +242,10 @@ func (s *GridStore) InitTables() error {
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_grid_events_instance_id ON grid_events(instance_id)`)
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_grid_events_level_id ON grid_events(level_id)`)
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_grid_regime_assessments_instance_id ON grid_regime_assessments(instance_id)`)
+			s.db.Exec(`ALTER TABLE grid_instances ADD COLUMN IF NOT EXISTS inventory_qty DOUBLE PRECISION DEFAULT 0`)
+			s.db.Exec(`ALTER TABLE grid_instances ADD COLUMN IF NOT EXISTS inventory_entry DOUBLE PRECISION DEFAULT 0`)
+			s.db.Exec(`ALTER TABLE grid_instances ADD COLUMN IF NOT EXISTS trail_count INTEGER DEFAULT 0`)
+			s.db.Exec(`ALTER TABLE grid_instances ADD COLUMN IF NOT EXISTS config_snapshot TEXT`)
 			return nil
 		}
 	}
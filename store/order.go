@@ -16,7 +16,7 @@ type TraderOrder struct {
 	ExchangeID        string  `gorm:"column:exchange_id;not null;default:''" json:"exchange_id"`
 	ExchangeType      string  `gorm:"column:exchange_type;not null;default:''" json:"exchange_type"`
 	ExchangeOrderID   string  `gorm:"column:exchange_order_id;not null;uniqueIndex:idx_orders_exchange_unique,priority:2" json:"exchange_order_id"`
-	ClientOrderID     string  `gorm:"column:client_order_id;default:''" json:"client_order_id"`
+	ClientOrderID     string  `gorm:"column:client_order_id;default:'';index:idx_orders_client_order_id" json:"client_order_id"`
 	Symbol            string  `gorm:"column:symbol;not null;index:idx_orders_symbol" json:"symbol"`
 	Side              string  `gorm:"column:side;not null" json:"side"`
 	PositionSide      string  `gorm:"column:position_side;default:''" json:"position_side"`
@@ -37,6 +37,7 @@ type TraderOrder struct {
 	PriceProtect      bool    `gorm:"column:price_protect;default:false" json:"price_protect"`
 	OrderAction       string  `gorm:"column:order_action;default:''" json:"order_action"`
 	RelatedPositionID int64   `gorm:"column:related_position_id;default:0" json:"related_position_id"`
+	ParentOrderID     int64   `gorm:"column:parent_order_id;default:0;index:idx_orders_parent_id" json:"parent_order_id"` // Algo parent order (0 = not a child order)
 	CreatedAt         int64   `gorm:"column:created_at" json:"created_at"`         // Unix milliseconds UTC
 	UpdatedAt         int64   `gorm:"column:updated_at" json:"updated_at"`         // Unix milliseconds UTC
 	FilledAt          int64   `gorm:"column:filled_at" json:"filled_at"`           // Unix milliseconds UTC
@@ -47,6 +48,21 @@ func (TraderOrder) TableName() string {
 	return "trader_orders"
 }
 
+// Execution algorithm parent order types
+// A parent tracks the whole working order; its slices are child orders pointing at it via ParentOrderID
+const (
+	OrderTypeTWAP    = "TWAP"
+	OrderTypeIceberg = "ICEBERG"
+	OrderTypePOV     = "POV"
+)
+
+// AlgoOrderTypes all execution algorithm parent order types
+var AlgoOrderTypes = []string{OrderTypeTWAP, OrderTypeIceberg, OrderTypePOV}
+
+// OrderStatusSubmitted marks a submission record on an OrderSync exchange: it links a client order ID to the
+// decision that sent it, keyed by the client order ID, while OrderSync records the resulting trades separately
+const OrderStatusSubmitted = "SUBMITTED"
+
 // TraderFill trade record
 // All time fields use int64 millisecond timestamps (UTC) to avoid timezone issues
 type TraderFill struct {
@@ -130,8 +146,13 @@ func (s *OrderStore) InitTables() error {
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_orders_trader_id ON trader_orders(trader_id)`)
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_orders_symbol ON trader_orders(symbol)`)
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_orders_status ON trader_orders(status)`)
+			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_orders_client_order_id ON trader_orders(client_order_id)`)
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_fills_trader_id ON trader_fills(trader_id)`)
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_fills_order_id ON trader_fills(order_id)`)
+
+			// Execution algorithm child orders (added after the initial schema)
+			s.db.Exec(`ALTER TABLE trader_orders ADD COLUMN IF NOT EXISTS parent_order_id BIGINT DEFAULT 0`)
+			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_orders_parent_id ON trader_orders(parent_order_id)`)
 			return nil
 		}
 	}
@@ -224,6 +245,23 @@ func (s *OrderStore) GetOrderByExchangeID(exchangeID, exchangeOrderID string) (*
 	return &order, nil
 }
 
+// GetOrderByClientID gets order by client order ID
+// Used for reconciliation of orders submitted with a deterministic client order ID
+func (s *OrderStore) GetOrderByClientID(exchangeID, clientOrderID string) (*TraderOrder, error) {
+	if clientOrderID == "" {
+		return nil, nil
+	}
+	var order TraderOrder
+	err := s.db.Where("exchange_id = ? AND client_order_id = ?", exchangeID, clientOrderID).First(&order).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	return &order, nil
+}
+
 // GetTraderOrders gets trader's order list
 func (s *OrderStore) GetTraderOrders(traderID string, limit int) ([]*TraderOrder, error) {
 	var orders []*TraderOrder
@@ -238,9 +276,10 @@ func (s *OrderStore) GetTraderOrders(traderID string, limit int) ([]*TraderOrder
 }
 
 // GetTraderOrdersFiltered gets trader's order list with optional symbol and status filters
+// Algo child orders are left out; they are listed under their parent by GetChildOrders
 func (s *OrderStore) GetTraderOrdersFiltered(traderID string, symbol string, status string, limit int) ([]*TraderOrder, error) {
 	var orders []*TraderOrder
-	query := s.db.Where("trader_id = ?", traderID)
+	query := s.db.Where("trader_id = ? AND parent_order_id = 0", traderID)
 
 	if symbol != "" {
 		query = query.Where("symbol = ?", symbol)
@@ -258,6 +297,43 @@ func (s *OrderStore) GetTraderOrdersFiltered(traderID string, symbol string, sta
 	return orders, nil
 }
 
+// GetOrderByID gets an order by its primary key
+func (s *OrderStore) GetOrderByID(id int64) (*TraderOrder, error) {
+	var order TraderOrder
+	err := s.db.Where("id = ?", id).First(&order).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	return &order, nil
+}
+
+// UpdateParentOrder saves an algo parent order's progress
+func (s *OrderStore) UpdateParentOrder(order *TraderOrder) error {
+	order.UpdatedAt = time.Now().UTC().UnixMilli()
+	return s.db.Save(order).Error
+}
+
+// GetChildOrders gets the slices placed by an algo parent order, oldest first
+func (s *OrderStore) GetChildOrders(parentID int64) ([]*TraderOrder, error) {
+	var orders []*TraderOrder
+	err := s.db.Where("parent_order_id = ?", parentID).
+		Order("created_at ASC").
+		Find(&orders).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query child orders: %w", err)
+	}
+	return orders, nil
+}
+
+// exchangeOrders restricts a query to rows that represent exchange fills once each
+// Algo parents, their tracking children and submission records are excluded; OrderSync records their trades separately
+func exchangeOrders(db *gorm.DB) *gorm.DB {
+	return db.Where("parent_order_id = 0 AND type NOT IN ? AND status <> ?", AlgoOrderTypes, OrderStatusSubmitted)
+}
+
 // GetOrderFills gets order's fill records
 func (s *OrderStore) GetOrderFills(orderID int64) ([]*TraderFill, error) {
 	var fills []*TraderFill
@@ -288,6 +364,7 @@ func (s *OrderStore) GetTraderOrderStats(traderID string) (map[string]interface{
 				SUM(commission) as total_commission,
 				SUM(filled_quantity * avg_fill_price) as total_volume`).
 		Where("trader_id = ?", traderID).
+		Scopes(exchangeOrders).
 		Scan(&r).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get order stats: %w", err)
@@ -421,3 +498,35 @@ func (s *OrderStore) GetRecentFillSymbolsByExchange(exchangeID string, sinceMs i
 	}
 	return symbols, nil
 }
+
+// GetNetPositionsFromOrders returns the net open quantity implied by filled orders, keyed by "SYMBOL_SIDE"
+// open_* orders add to the side, close_* orders subtract; keys whose net is ~0 are omitted
+// Used by the position reconciler as a third source of truth next to PositionStore and the exchange
+func (s *OrderStore) GetNetPositionsFromOrders(traderID string) (map[string]float64, error) {
+	type netRow struct {
+		Symbol       string
+		PositionSide string
+		Net          float64
+	}
+	var rows []netRow
+	err := s.db.Model(&TraderOrder{}).
+		Select(`symbol, position_side,
+			SUM(CASE WHEN order_action IN ('open_long', 'open_short') THEN filled_quantity
+			         WHEN order_action IN ('close_long', 'close_short') THEN -filled_quantity
+			         ELSE 0 END) as net`).
+		Where("trader_id = ? AND status = ?", traderID, "FILLED").
+		Scopes(exchangeOrders).
+		Group("symbol, position_side").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate orders: %w", err)
+	}
+
+	result := make(map[string]float64)
+	for _, r := range rows {
+		if r.Net > 0.00000001 {
+			result[r.Symbol+"_"+r.PositionSide] = r.Net
+		}
+	}
+	return result, nil
+}
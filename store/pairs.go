@@ -0,0 +1,128 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Pairs trade statuses
+const (
+	PairsTradeOpen      = "open"
+	PairsTradeUnwinding = "unwinding" // Only leg A got on and closing it failed; retried every cycle
+	PairsTradeClosed    = "closed"
+)
+
+// Pairs trade directions
+const (
+	PairsLongSpread  = "long_spread"  // Long A, short B: entered when the spread is stretched low
+	PairsShortSpread = "short_spread" // Short A, long B: entered when the spread is stretched high
+)
+
+// Pairs trade close reasons
+const (
+	PairsCloseReverted   = "mean_reverted" // Z-score came back within ExitZ
+	PairsCloseZStop      = "z_stop"        // Spread kept stretching to StopZ
+	PairsCloseStopLoss   = "stop_loss"     // Combined leg loss hit StopLossPct
+	PairsCloseMaxHolding = "max_holding"   // Held for MaxHoldingBars
+	PairsCloseLegGone    = "leg_missing"   // A leg was closed or liquidated outside the bot
+	PairsCloseOpenFailed = "open_failed"   // The second leg failed to open and the first was unwound
+	PairsCloseManual     = "manual"
+)
+
+// PairsTrade a spread position: two legs opened, monitored and closed as one unit
+// All time fields use int64 millisecond timestamps (UTC) to avoid timezone issues
+type PairsTrade struct {
+	ID        int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	TraderID  string `gorm:"column:trader_id;not null;index:idx_pairs_trader_status" json:"trader_id"`
+	Status    string `gorm:"column:status;not null;default:open;index:idx_pairs_trader_status" json:"status"`
+	Direction string `gorm:"column:direction;not null" json:"direction"` // long_spread | short_spread
+
+	// Leg A
+	SymbolA       string  `gorm:"column:symbol_a;not null" json:"symbol_a"`
+	QuantityA     float64 `gorm:"column:quantity_a;default:0" json:"quantity_a"`
+	EntryPriceA   float64 `gorm:"column:entry_price_a;default:0" json:"entry_price_a"`
+	ExitPriceA    float64 `gorm:"column:exit_price_a;default:0" json:"exit_price_a"`
+	EntryOrderIDA string  `gorm:"column:entry_order_id_a;default:''" json:"entry_order_id_a"`
+
+	// Leg B (hedge)
+	SymbolB       string  `gorm:"column:symbol_b;not null" json:"symbol_b"`
+	QuantityB     float64 `gorm:"column:quantity_b;default:0" json:"quantity_b"`
+	EntryPriceB   float64 `gorm:"column:entry_price_b;default:0" json:"entry_price_b"`
+	ExitPriceB    float64 `gorm:"column:exit_price_b;default:0" json:"exit_price_b"`
+	EntryOrderIDB string  `gorm:"column:entry_order_id_b;default:''" json:"entry_order_id_b"`
+
+	Leverage    int     `gorm:"column:leverage;default:1" json:"leverage"`
+	HedgeRatio  float64 `gorm:"column:hedge_ratio;default:0" json:"hedge_ratio"`     // Beta of log(A) on log(B) at entry
+	EntryZScore float64 `gorm:"column:entry_z_score;default:0" json:"entry_z_score"` // Spread z-score at entry
+	LastZScore  float64 `gorm:"column:last_z_score;default:0" json:"last_z_score"`   // Latest spread z-score
+	PricePnL    float64 `gorm:"column:price_pnl;default:0" json:"price_pnl"`         // Combined leg PnL at the latest check (USDT)
+	RealizedPnL float64 `gorm:"column:realized_pnl;default:0" json:"realized_pnl"`   // Combined leg PnL at close (USDT)
+	CloseReason string  `gorm:"column:close_reason;default:''" json:"close_reason"`
+	OpenedAt    int64   `gorm:"column:opened_at" json:"opened_at"`
+	ClosedAt    int64   `gorm:"column:closed_at;default:0" json:"closed_at"`
+	UpdatedAt   int64   `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName returns the table name
+func (PairsTrade) TableName() string {
+	return "pairs_trades"
+}
+
+// PairsStore linked two-leg spread trade storage for the pairs strategy
+type PairsStore struct {
+	db *gorm.DB
+}
+
+// NewPairsStore creates pairs trade storage instance
+func NewPairsStore(db *gorm.DB) *PairsStore {
+	return &PairsStore{db: db}
+}
+
+// InitTables initializes pairs trade tables
+func (s *PairsStore) InitTables() error {
+	if s.db.Dialector.Name() == "postgres" {
+		var tableExists int64
+		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'pairs_trades'`).Scan(&tableExists)
+		if tableExists > 0 {
+			return nil
+		}
+	}
+	if err := s.db.AutoMigrate(&PairsTrade{}); err != nil {
+		return fmt.Errorf("failed to migrate pairs_trades table: %w", err)
+	}
+	return nil
+}
+
+// Save creates or updates a trade
+func (s *PairsStore) Save(trade *PairsTrade) error {
+	nowMs := time.Now().UTC().UnixMilli()
+	if trade.OpenedAt == 0 {
+		trade.OpenedAt = nowMs
+	}
+	trade.UpdatedAt = nowMs
+	return s.db.Save(trade).Error
+}
+
+// GetOpenTrade returns the trader's open or unwinding spread trade, or nil when flat
+func (s *PairsStore) GetOpenTrade(traderID string) (*PairsTrade, error) {
+	var trades []*PairsTrade
+	err := s.db.Where("trader_id = ? AND status IN ?", traderID, []string{PairsTradeOpen, PairsTradeUnwinding}).
+		Order("opened_at DESC").Limit(1).Find(&trades).Error
+	if err != nil || len(trades) == 0 {
+		return nil, err
+	}
+	return trades[0], nil
+}
+
+// ListTrades returns the trader's most recent spread trades, newest first
+func (s *PairsStore) ListTrades(traderID string, limit int) ([]*PairsTrade, error) {
+	var trades []*PairsTrade
+	query := s.db.Where("trader_id = ?", traderID).Order("opened_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&trades).Error
+	return trades, err
+}
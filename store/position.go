@@ -5,6 +5,7 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -108,11 +109,48 @@ func (TraderPosition) TableName() string {
 	return "trader_positions"
 }
 
+// TraderPositionExit a single exit leg of a position (take-profit rung, scale-out, final close)
+// The parent TraderPosition keeps the aggregate (weighted exit price, total PnL); exits keep each leg
+type TraderPositionExit struct {
+	ID              int64   `gorm:"primaryKey;autoIncrement" json:"id"`
+	PositionID      int64   `gorm:"column:position_id;not null;index:idx_position_exits_position" json:"position_id"`
+	TraderID        string  `gorm:"column:trader_id;not null;index:idx_position_exits_trader" json:"trader_id"`
+	Symbol          string  `gorm:"column:symbol;not null" json:"symbol"`
+	Side            string  `gorm:"column:side;not null" json:"side"`
+	Quantity        float64 `gorm:"column:quantity;not null" json:"quantity"`
+	Price           float64 `gorm:"column:price;not null" json:"price"`
+	RealizedPnL     float64 `gorm:"column:realized_pnl;default:0" json:"realized_pnl"`
+	Fee             float64 `gorm:"column:fee;default:0" json:"fee"`
+	OrderID         string  `gorm:"column:order_id;default:''" json:"order_id"`                                                           // Trade/fill ID the exit was synced from
+	ExchangeOrderID string  `gorm:"column:exchange_order_id;default:'';index:idx_position_exits_exchange_order" json:"exchange_order_id"` // Exchange order that filled it
+	Reason          string  `gorm:"column:reason;default:''" json:"reason"`                                                               // e.g. sync, take_profit_1, stop_loss
+	ExitTime        int64   `gorm:"column:exit_time;not null" json:"exit_time"`                                                           // Unix milliseconds UTC
+	CreatedAt       int64   `gorm:"column:created_at" json:"created_at"`                                                                  // Unix milliseconds UTC
+}
+
+// TableName returns the table name
+func (TraderPositionExit) TableName() string {
+	return "trader_position_exits"
+}
+
 // PositionStore position storage
 type PositionStore struct {
 	db *gorm.DB
+
+	// Exit reasons by trader and exchange order ID, applied to legs OrderSync records later
+	pendingExitReasons map[string]pendingExitReason
+	pendingMu          sync.Mutex
+}
+
+// pendingExitReason an exit label waiting for the order's fills to be synced
+type pendingExitReason struct {
+	reason string
+	setAt  time.Time
 }
 
+// pendingExitReasonTTL is how long a label waits for its fills; OrderSync picks fills up within minutes
+const pendingExitReasonTTL = 24 * time.Hour
+
 // NewPositionStore creates position storage instance
 func NewPositionStore(db *gorm.DB) *PositionStore {
 	return &PositionStore{db: db}
@@ -125,6 +163,10 @@ func (s *PositionStore) isPostgres() bool {
 
 // InitTables initializes position tables
 func (s *PositionStore) InitTables() error {
+	if err := s.initExitsTable(); err != nil {
+		return err
+	}
+
 	// For PostgreSQL with existing table, skip AutoMigrate
 	if s.isPostgres() {
 		var tableExists int64
@@ -306,6 +348,101 @@ func (s *PositionStore) ClosePositionFully(id int64, exitPrice float64, exitOrde
 	}).Error
 }
 
+// initExitsTable initializes the position exits table
+func (s *PositionStore) initExitsTable() error {
+	if s.isPostgres() {
+		var tableExists int64
+		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'trader_position_exits'`).Scan(&tableExists)
+		if tableExists > 0 {
+			s.db.Exec(`ALTER TABLE trader_position_exits ADD COLUMN IF NOT EXISTS exchange_order_id TEXT DEFAULT ''`)
+			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_position_exits_exchange_order ON trader_position_exits(exchange_order_id)`)
+			return nil
+		}
+	}
+	if err := s.db.AutoMigrate(&TraderPositionExit{}); err != nil {
+		return fmt.Errorf("failed to migrate trader_position_exits table: %w", err)
+	}
+	return nil
+}
+
+// RecordExit records one exit leg of a position
+// Exits with an order ID are recorded once per position, so re-synced trades don't double count
+func (s *PositionStore) RecordExit(exit *TraderPositionExit) error {
+	if exit.OrderID != "" {
+		var count int64
+		s.db.Model(&TraderPositionExit{}).
+			Where("position_id = ? AND order_id = ?", exit.PositionID, exit.OrderID).
+			Count(&count)
+		if count > 0 {
+			return nil
+		}
+	}
+	if reason := s.pendingExitReasonFor(exit.TraderID, exit.ExchangeOrderID); reason != "" {
+		exit.Reason = reason
+	}
+	if exit.CreatedAt == 0 {
+		exit.CreatedAt = time.Now().UTC().UnixMilli()
+	}
+	return s.db.Create(exit).Error
+}
+
+// GetExits returns the exit legs of a position in time order
+func (s *PositionStore) GetExits(positionID int64) ([]*TraderPositionExit, error) {
+	var exits []*TraderPositionExit
+	err := s.db.Where("position_id = ?", positionID).Order("exit_time ASC, id ASC").Find(&exits).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query position exits: %w", err)
+	}
+	return exits, nil
+}
+
+// SetExitReason labels the exit legs filled by an exchange order (e.g. take_profit_2 for a ladder rung)
+// The label is also kept for the order's fills OrderSync hasn't recorded yet, until pendingExitReasonTTL
+func (s *PositionStore) SetExitReason(traderID, exchangeOrderID, reason string) error {
+	if exchangeOrderID == "" {
+		return nil
+	}
+	err := s.db.Model(&TraderPositionExit{}).
+		Where("trader_id = ? AND exchange_order_id = ?", traderID, exchangeOrderID).
+		Update("reason", reason).Error
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	if s.pendingExitReasons == nil {
+		s.pendingExitReasons = make(map[string]pendingExitReason)
+	}
+	for key, pending := range s.pendingExitReasons {
+		if now.Sub(pending.setAt) > pendingExitReasonTTL {
+			delete(s.pendingExitReasons, key)
+		}
+	}
+	s.pendingExitReasons[traderID+"|"+exchangeOrderID] = pendingExitReason{reason: reason, setAt: now}
+	return nil
+}
+
+// pendingExitReasonFor returns the unexpired label SetExitReason kept for an exchange order
+func (s *PositionStore) pendingExitReasonFor(traderID, exchangeOrderID string) string {
+	if exchangeOrderID == "" {
+		return ""
+	}
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	key := traderID + "|" + exchangeOrderID
+	pending, ok := s.pendingExitReasons[key]
+	if !ok {
+		return ""
+	}
+	if time.Since(pending.setAt) > pendingExitReasonTTL {
+		delete(s.pendingExitReasons, key)
+		return ""
+	}
+	return pending.reason
+}
+
 // DeleteAllOpenPositions deletes all OPEN positions for a trader
 func (s *PositionStore) DeleteAllOpenPositions(traderID string) error {
 	return s.db.Where("trader_id = ? AND status = ?", traderID, "OPEN").Delete(&TraderPosition{}).Error
@@ -330,6 +467,19 @@ func (s *PositionStore) GetOpenPositions(traderID string) ([]*TraderPosition, er
 	return positions, nil
 }
 
+// GetPositionByID gets a position by its ID, nil if it doesn't exist
+func (s *PositionStore) GetPositionByID(id int64) (*TraderPosition, error) {
+	var pos TraderPosition
+	err := s.db.Where("id = ?", id).First(&pos).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get position: %w", err)
+	}
+	return &pos, nil
+}
+
 // GetOpenPositionBySymbol gets open position for specified symbol and direction
 func (s *PositionStore) GetOpenPositionBySymbol(traderID, symbol, side string) (*TraderPosition, error) {
 	var pos TraderPosition
@@ -1182,6 +1332,43 @@ func (s *PositionStore) CreateOpenPosition(pos *TraderPosition) error {
 	return nil
 }
 
+// CorrectPositionQuantity overwrites quantity and entry price of an open position
+// Used by the position reconciler when the exchange is known to be authoritative
+func (s *PositionStore) CorrectPositionQuantity(id int64, quantity, entryPrice float64) error {
+	updates := map[string]interface{}{
+		"quantity":   quantity,
+		"updated_at": time.Now().UTC().UnixMilli(),
+	}
+	if entryPrice > 0 {
+		updates["entry_price"] = entryPrice
+	}
+	return s.db.Model(&TraderPosition{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// MergeOpenPositions folds duplicate open records of one exchange position into keepID
+// keepID takes the given quantity and entry price; the mergeIDs records are deleted, since they never were separate trades
+func (s *PositionStore) MergeOpenPositions(keepID int64, mergeIDs []int64, quantity, entryPrice float64) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"quantity":   quantity,
+			"updated_at": time.Now().UTC().UnixMilli(),
+		}
+		if entryPrice > 0 {
+			updates["entry_price"] = entryPrice
+		}
+		if err := tx.Model(&TraderPosition{}).Where("id = ?", keepID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update kept position: %w", err)
+		}
+		if len(mergeIDs) == 0 {
+			return nil
+		}
+		if err := tx.Where("id IN ? AND status = ?", mergeIDs, "OPEN").Delete(&TraderPosition{}).Error; err != nil {
+			return fmt.Errorf("failed to delete merged positions: %w", err)
+		}
+		return nil
+	})
+}
+
 // ClosePositionWithAccurateData closes a position with accurate data from exchange
 // exitTimeMs is Unix milliseconds UTC
 func (s *PositionStore) ClosePositionWithAccurateData(id int64, exitPrice float64, exitOrderID string, exitTimeMs int64, realizedPnL float64, fee float64, closeReason string) error {
@@ -25,17 +25,17 @@ func NewPositionBuilder(positionStore *PositionStore) *PositionBuilder {
 }
 
 // ProcessTrade processes a single trade and updates position accordingly
-// tradeTimeMs is Unix milliseconds UTC
+// tradeTimeMs is Unix milliseconds UTC; orderID is the trade's unique ID and exchangeOrderID the order it filled
 func (pb *PositionBuilder) ProcessTrade(
 	traderID, exchangeID, exchangeType, symbol, side, action string,
 	quantity, price, fee, realizedPnL float64,
 	tradeTimeMs int64,
-	orderID string,
+	orderID, exchangeOrderID string,
 ) error {
 	if strings.HasPrefix(action, "open_") {
 		return pb.handleOpen(traderID, exchangeID, exchangeType, symbol, side, quantity, price, fee, tradeTimeMs, orderID)
 	} else if strings.HasPrefix(action, "close_") {
-		return pb.handleClose(traderID, exchangeID, exchangeType, symbol, side, quantity, price, fee, realizedPnL, tradeTimeMs, orderID)
+		return pb.handleClose(traderID, exchangeID, exchangeType, symbol, side, quantity, price, fee, realizedPnL, tradeTimeMs, orderID, exchangeOrderID)
 	}
 	return nil
 }
@@ -98,7 +98,7 @@ func (pb *PositionBuilder) handleClose(
 	traderID, exchangeID, exchangeType, symbol, side string,
 	quantity, price, fee, realizedPnL float64,
 	tradeTimeMs int64,
-	orderID string,
+	orderID, exchangeOrderID string,
 ) error {
 	// Get OPEN position
 	position, err := pb.positionStore.GetOpenPositionBySymbol(traderID, symbol, side)
@@ -130,7 +130,11 @@ func (pb *PositionBuilder) handleClose(
 		// Partial close: reduce quantity and update weighted average exit price
 		logger.Infof("  📉 Partial close: %s %s %.6f → %.6f (closed %.6f @ %.2f, PnL: %.2f)",
 			symbol, side, position.Quantity, position.Quantity-quantity, quantity, price, realizedPnL)
-		return pb.positionStore.ReducePositionQuantity(position.ID, quantity, price, fee, realizedPnL)
+		if err := pb.positionStore.ReducePositionQuantity(position.ID, quantity, price, fee, realizedPnL); err != nil {
+			return err
+		}
+		pb.recordExit(position, quantity, price, fee, realizedPnL, tradeTimeMs, orderID, exchangeOrderID)
+		return nil
 	} else {
 		// Full close (or close with tolerance): mark as CLOSED
 		closeQty := quantity
@@ -162,7 +166,7 @@ func (pb *PositionBuilder) handleClose(
 		logger.Infof("  ✅ Full close: %s %s %.6f @ %.2f (avg exit: %.2f, entry: %.2f, PnL: %.2f)",
 			symbol, side, closeQty, price, finalExitPrice, position.EntryPrice, totalPnL)
 
-		return pb.positionStore.ClosePositionFully(
+		if err := pb.positionStore.ClosePositionFully(
 			position.ID,
 			finalExitPrice,
 			orderID,
@@ -170,7 +174,32 @@ func (pb *PositionBuilder) handleClose(
 			totalPnL,
 			totalFee,
 			"sync",
-		)
+		); err != nil {
+			return err
+		}
+		pb.recordExit(position, closeQty, price, fee, realizedPnL, tradeTimeMs, orderID, exchangeOrderID)
+		return nil
+	}
+}
+
+// recordExit stores one exit leg so partial closes keep their own price and PnL
+func (pb *PositionBuilder) recordExit(position *TraderPosition, quantity, price, fee, realizedPnL float64, tradeTimeMs int64, orderID, exchangeOrderID string) {
+	exit := &TraderPositionExit{
+		PositionID:      position.ID,
+		TraderID:        position.TraderID,
+		Symbol:          position.Symbol,
+		Side:            position.Side,
+		Quantity:        quantity,
+		Price:           price,
+		RealizedPnL:     realizedPnL,
+		Fee:             fee,
+		OrderID:         orderID,
+		ExchangeOrderID: exchangeOrderID,
+		Reason:          "sync",
+		ExitTime:        tradeTimeMs,
+	}
+	if err := pb.positionStore.RecordExit(exit); err != nil {
+		logger.Infof("  ⚠️  Failed to record exit leg: %v", err)
 	}
 }
 
@@ -29,6 +29,10 @@ type Store struct {
 	equity   *EquityStore
 	order    *OrderStore
 	grid     *GridStore
+	drift    *DriftStore
+	dca      *DCAStore
+	arb      *FundingArbStore
+	pairs    *PairsStore
 
 	mu sync.RWMutex
 }
@@ -160,6 +164,18 @@ func (s *Store) initTables() error {
 	if err := s.Grid().InitTables(); err != nil {
 		return fmt.Errorf("failed to initialize grid tables: %w", err)
 	}
+	if err := s.Drift().InitTables(); err != nil {
+		return fmt.Errorf("failed to initialize drift tables: %w", err)
+	}
+	if err := s.DCA().InitTables(); err != nil {
+		return fmt.Errorf("failed to initialize dca tables: %w", err)
+	}
+	if err := s.FundingArb().InitTables(); err != nil {
+		return fmt.Errorf("failed to initialize funding arbitrage tables: %w", err)
+	}
+	if err := s.Pairs().InitTables(); err != nil {
+		return fmt.Errorf("failed to initialize pairs tables: %w", err)
+	}
 	return nil
 }
 
@@ -293,6 +309,46 @@ func (s *Store) Grid() *GridStore {
 	return s.grid
 }
 
+// Drift gets position drift storage
+func (s *Store) Drift() *DriftStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.drift == nil {
+		s.drift = NewDriftStore(s.gdb)
+	}
+	return s.drift
+}
+
+// DCA gets DCA bot deal storage
+func (s *Store) DCA() *DCAStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dca == nil {
+		s.dca = NewDCAStore(s.gdb)
+	}
+	return s.dca
+}
+
+// FundingArb gets funding arbitrage pair storage
+func (s *Store) FundingArb() *FundingArbStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.arb == nil {
+		s.arb = NewFundingArbStore(s.gdb)
+	}
+	return s.arb
+}
+
+// Pairs gets pairs trading spread trade storage
+func (s *Store) Pairs() *PairsStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pairs == nil {
+		s.pairs = NewPairsStore(s.gdb)
+	}
+	return s.pairs
+}
+
 // Close closes database connection
 func (s *Store) Close() error {
 	if s.driver != nil {
@@ -32,7 +32,7 @@ func (Strategy) TableName() string { return "strategies" }
 
 // StrategyConfig strategy configuration details (JSON structure)
 type StrategyConfig struct {
-	// Strategy type: "ai_trading" (default) or "grid_trading"
+	// Strategy type: "ai_trading" (default), "grid_trading", "dca", "funding_arb" or "pairs"
 	StrategyType string `json:"strategy_type,omitempty"`
 
 	// language setting: "zh" for Chinese, "en" for English
@@ -51,6 +51,33 @@ type StrategyConfig struct {
 
 	// Grid trading configuration (only used when StrategyType == "grid_trading")
 	GridConfig *GridStrategyConfig `json:"grid_config,omitempty"`
+	// DCA bot configuration (only used when StrategyType == "dca")
+	DCAConfig *DCAStrategyConfig `json:"dca_config,omitempty"`
+	// Cross-exchange funding arbitrage configuration (only used when StrategyType == "funding_arb")
+	FundingArbConfig *FundingArbStrategyConfig `json:"funding_arb_config,omitempty"`
+	// Pairs / statistical arbitrage configuration (only used when StrategyType == "pairs")
+	PairsConfig *PairsStrategyConfig `json:"pairs_config,omitempty"`
+
+	// Execution algorithm for large AI orders (nil = every order is a single market order)
+	Execution *ExecutionConfig `json:"execution,omitempty"`
+}
+
+// ExecutionConfig how large open/close orders are worked instead of hitting the book in one market order
+type ExecutionConfig struct {
+	// Algorithm: "market" (default), "twap", "iceberg" or "pov"
+	Algorithm string `json:"algorithm"`
+	// Orders below this notional (USDT) always go out as one market order
+	MinNotionalUSD float64 `json:"min_notional_usd"`
+	// Window to work the order over, in seconds (capped below the scan interval, default 60)
+	DurationSeconds int `json:"duration_seconds"`
+	// TWAP: number of slices (default 5)
+	Slices int `json:"slices,omitempty"`
+	// Iceberg: visible clip size in USDT (default 1/5 of the order)
+	ClipUSD float64 `json:"clip_usd,omitempty"`
+	// POV: target share of traded volume, in percent (default 10)
+	ParticipationPct float64 `json:"participation_pct,omitempty"`
+	// Let the AI choose the algorithm per decision via the "execution" field
+	AllowAIOverride bool `json:"allow_ai_override,omitempty"`
 }
 
 // GridStrategyConfig grid trading specific configuration
@@ -85,6 +112,120 @@ type GridStrategyConfig struct {
 	EnableDirectionAdjust bool `json:"enable_direction_adjust"`
 	// Direction bias ratio for long_bias/short_bias modes (default 0.7 = 70%/30%)
 	DirectionBiasRatio float64 `json:"direction_bias_ratio"`
+	// Execution mode: "ai" (default, model places orders every cycle) | "mechanical" (rule-based, no model call)
+	ExecutionMode string `json:"execution_mode,omitempty"`
+	// Mechanical mode only: ask the AI to review bounds/pause state every N cycles (0 = never)
+	AIReviewIntervalCycles int `json:"ai_review_interval_cycles,omitempty"`
+	// Trailing mode: "" (pause on range breakout) | "trailing" (re-centre with the trend either way)
+	// | "infinity" (trail uptrends only, keeping the inventory left below as a base position)
+	TrailingMode string `json:"trailing_mode,omitempty"`
+	// Levels price must move beyond the range before the grid trails (default 1)
+	TrailingTriggerLevels int `json:"trailing_trigger_levels,omitempty"`
+	// Multi-symbol basket: one grid per entry sharing TotalInvestment, drawdown and daily loss limits
+	// (empty = single grid on Symbol)
+	Basket []GridBasketSymbol `json:"basket,omitempty"`
+}
+
+// GridBasketSymbol one symbol of a multi-symbol grid basket
+type GridBasketSymbol struct {
+	// Trading pair (e.g., "ETHUSDT")
+	Symbol string `json:"symbol"`
+	// Share of TotalInvestment relative to the other entries (0 on every entry = equal split)
+	Weight float64 `json:"weight,omitempty"`
+	// Manual bounds for this symbol (ignored when UseATRBounds is set)
+	UpperPrice float64 `json:"upper_price,omitempty"`
+	LowerPrice float64 `json:"lower_price,omitempty"`
+}
+
+// DCAStrategyConfig DCA / safety-order bot configuration
+// A deal opens with a base order, averages in with safety orders as price moves against it,
+// and closes everything with a single take-profit on the average entry
+type DCAStrategyConfig struct {
+	// Trading pair (e.g., "BTCUSDT")
+	Symbol string `json:"symbol"`
+	// Deal direction: "long" (default) | "short"
+	Direction string `json:"direction,omitempty"`
+	// Leverage (1-20)
+	Leverage int `json:"leverage"`
+	// Base order notional in USDT
+	BaseOrderUSD float64 `json:"base_order_usd"`
+	// First safety order size in USDT
+	SafetyOrderUSD float64 `json:"safety_order_usd"`
+	// Maximum number of safety orders per deal (0-25)
+	MaxSafetyOrders int `json:"max_safety_orders"`
+	// Price deviation of the first safety order from the base entry (%)
+	PriceDeviationPct float64 `json:"price_deviation_pct"`
+	// Multiplier applied to each subsequent safety order's deviation step (default 1.0)
+	StepScale float64 `json:"step_scale,omitempty"`
+	// Multiplier applied to each subsequent safety order's size (default 1.0)
+	VolumeScale float64 `json:"volume_scale,omitempty"`
+	// Take-profit distance from the average entry (%)
+	TakeProfitPct float64 `json:"take_profit_pct"`
+	// Place safety orders post-only for maker fees
+	UseMakerOnly bool `json:"use_maker_only"`
+	// Ask the AI whether to start each new deal (false = start immediately when idle)
+	AIGating bool `json:"ai_gating,omitempty"`
+	// Minutes to wait after a deal closes before starting the next one
+	CooldownMinutes int `json:"cooldown_minutes,omitempty"`
+	// Stop after this many completed deals (0 = unlimited)
+	MaxDeals int `json:"max_deals,omitempty"`
+}
+
+// FundingArbStrategyConfig market-neutral funding arbitrage across exchange accounts
+// Each pair is long the perp on the venue paying the lowest funding and short it on the venue
+// charging the highest, with matched notional, so the pair collects the funding differential
+type FundingArbStrategyConfig struct {
+	// Perpetuals to scan (e.g., ["BTCUSDT", "ETHUSDT"])
+	Symbols []string `json:"symbols"`
+	// Exchange account IDs to trade across (empty = every enabled account of the user)
+	ExchangeIDs []string `json:"exchange_ids,omitempty"`
+	// Notional per leg in USDT
+	NotionalUSD float64 `json:"notional_usd"`
+	// Leverage on both legs (1-10)
+	Leverage int `json:"leverage"`
+	// Maximum number of open pairs
+	MaxPairs int `json:"max_pairs"`
+	// Open a pair when the annualised funding spread exceeds this (%)
+	MinSpreadAPR float64 `json:"min_spread_apr"`
+	// Unwind a pair when its annualised funding spread compresses below this (%)
+	ExitSpreadAPR float64 `json:"exit_spread_apr"`
+	// Unwind when the legs' notionals drift apart by more than this share of the pair notional (%)
+	MaxDeltaDriftPct float64 `json:"max_delta_drift_pct"`
+	// Unwind when either leg's mark price is within this distance of its liquidation price (%)
+	MinLiquidationDistancePct float64 `json:"min_liquidation_distance_pct"`
+}
+
+// PairsStrategyConfig statistical arbitrage on the spread between two correlated perps
+// The spread is log(A) - hedge_ratio * log(B), with the hedge ratio re-estimated by rolling OLS every bar.
+// A stretched spread is traded back to its mean: long A / short B when the z-score is low, the reverse when high
+type PairsStrategyConfig struct {
+	// First leg (e.g., "ETHUSDT")
+	SymbolA string `json:"symbol_a"`
+	// Second leg, the hedge (e.g., "BTCUSDT")
+	SymbolB string `json:"symbol_b"`
+	// Kline timeframe the spread is computed on (default "1h")
+	Timeframe string `json:"timeframe,omitempty"`
+	// Rolling window for the hedge ratio and spread statistics, in bars (default 100)
+	LookbackBars int `json:"lookback_bars,omitempty"`
+	// Enter when |z-score| reaches this (default 2.0)
+	EntryZ float64 `json:"entry_z,omitempty"`
+	// Exit when the z-score reverts to within this of the mean (default 0.5)
+	ExitZ float64 `json:"exit_z,omitempty"`
+	// Stop out when |z-score| keeps stretching to this (default 4.0)
+	StopZ float64 `json:"stop_z,omitempty"`
+	// Skip entries while the legs' log-return correlation is below this (0 = no filter)
+	MinCorrelation float64 `json:"min_correlation,omitempty"`
+	// Notional of leg A in USDT; leg B is sized by the hedge ratio
+	NotionalUSD float64 `json:"notional_usd"`
+	// Leverage on both legs (1-10)
+	Leverage int `json:"leverage"`
+	// Shared stop: close both legs when their combined loss exceeds this share of the pair notional (%, 0 = off)
+	// Checked in software once per scan interval, not placed on the exchange, so a fast move can overshoot it
+	StopLossPct float64 `json:"stop_loss_pct,omitempty"`
+	// Close both legs after holding this many bars (0 = no limit)
+	MaxHoldingBars int `json:"max_holding_bars,omitempty"`
+	// Ask the AI to confirm each entry signal (false = enter mechanically)
+	AIConfirm bool `json:"ai_confirm,omitempty"`
 }
 
 // PromptSectionsConfig editable sections of System Prompt
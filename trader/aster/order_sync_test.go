@@ -0,0 +1,107 @@
+package aster
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"nofx/store"
+)
+
+// TestSyncOrdersLabelsExitsByExchangeOrder syncs rung fills whose trade IDs differ from their order ID
+func TestSyncOrdersLabelsExitsByExchangeOrder(t *testing.T) {
+	var mu sync.Mutex
+	var trades []AsterTradeRecord
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v3/userTrades" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(trades)
+	}))
+	defer mockServer.Close()
+
+	privateKey, _ := crypto.GenerateKey()
+	trader := &AsterTrader{
+		ctx:             context.Background(),
+		user:            "0x1234567890123456789012345678901234567890",
+		signer:          "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd",
+		privateKey:      privateKey,
+		client:          mockServer.Client(),
+		baseURL:         mockServer.URL,
+		symbolPrecision: make(map[string]SymbolPrecision),
+	}
+
+	st, err := store.New(filepath.Join(t.TempDir(), "sync.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	defer st.Close()
+
+	const traderID = "trader-1"
+	start := time.Now().Add(-time.Hour).UnixMilli()
+	fill := func(id, orderID int64, side, qty, pnl string, offset int64) AsterTradeRecord {
+		return AsterTradeRecord{ID: id, OrderID: orderID, Symbol: "BTCUSDT", Side: side, PositionSide: "LONG",
+			Price: "100", Qty: qty, RealizedPnl: pnl, Commission: "0.01", Time: start + offset}
+	}
+	syncOrders := func() {
+		t.Helper()
+		if err := trader.SyncOrdersFromAster(traderID, "exchange-1", "aster", st); err != nil {
+			t.Fatalf("SyncOrdersFromAster: %v", err)
+		}
+	}
+
+	// The ladder labels rung order 200 before OrderSync has seen its fills
+	if err := st.Position().SetExitReason(traderID, "200", "take_profit_1"); err != nil {
+		t.Fatalf("SetExitReason: %v", err)
+	}
+	mu.Lock()
+	trades = []AsterTradeRecord{
+		fill(1, 100, "BUY", "3", "0", 0),
+		fill(11, 200, "SELL", "1", "5", 1), // Rung 1 fills in two trades
+		fill(12, 200, "SELL", "1", "5", 2),
+	}
+	mu.Unlock()
+	syncOrders()
+	syncOrders() // Re-synced trades must not be recorded twice
+
+	open, err := st.Position().GetOpenPositionBySymbol(traderID, "BTCUSDT", "LONG")
+	if err != nil || open == nil {
+		t.Fatalf("Expected the long to stay open after the first rung, got %v (%v)", open, err)
+	}
+	exits, err := st.Position().GetExits(open.ID)
+	if err != nil {
+		t.Fatalf("GetExits: %v", err)
+	}
+	if len(exits) != 2 {
+		t.Fatalf("Expected one exit leg per rung trade, got %d", len(exits))
+	}
+	for _, exit := range exits {
+		if exit.ExchangeOrderID != "200" || exit.Reason != "take_profit_1" {
+			t.Errorf("Expected trade %s to be labelled take_profit_1 through order 200, got order %s reason %s",
+				exit.OrderID, exit.ExchangeOrderID, exit.Reason)
+		}
+	}
+
+	// Rung 2 fills before it is labelled
+	mu.Lock()
+	trades = append(trades, fill(21, 300, "SELL", "1", "6", 3))
+	mu.Unlock()
+	syncOrders()
+	if err := st.Position().SetExitReason(traderID, "300", "take_profit_2"); err != nil {
+		t.Fatalf("SetExitReason: %v", err)
+	}
+	exits, _ = st.Position().GetExits(open.ID)
+	if len(exits) != 3 || exits[2].OrderID != "21" || exits[2].Reason != "take_profit_2" {
+		t.Fatalf("Expected the recorded rung 2 leg to be relabelled, got %+v", exits[len(exits)-1])
+	}
+}
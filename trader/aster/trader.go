@@ -46,6 +46,8 @@ type SymbolPrecision struct {
 	QuantityPrecision int
 	TickSize          float64 // Price tick size
 	StepSize          float64 // Quantity step size
+	MinQty            float64 // Minimum order quantity
+	MinNotional       float64 // Minimum order value
 }
 
 // NewAsterTrader Create Aster trader
@@ -137,6 +139,13 @@ func (t *AsterTrader) getPrecision(symbol string) (SymbolPrecision, error) {
 				if stepSizeStr, ok := filter["stepSize"].(string); ok {
 					prec.StepSize, _ = strconv.ParseFloat(stepSizeStr, 64)
 				}
+				if minQtyStr, ok := filter["minQty"].(string); ok {
+					prec.MinQty, _ = strconv.ParseFloat(minQtyStr, 64)
+				}
+			case "MIN_NOTIONAL":
+				if notionalStr, ok := filter["notional"].(string); ok {
+					prec.MinNotional, _ = strconv.ParseFloat(notionalStr, 64)
+				}
 			}
 		}
 
@@ -151,6 +160,24 @@ func (t *AsterTrader) getPrecision(symbol string) (SymbolPrecision, error) {
 	return SymbolPrecision{}, fmt.Errorf("precision information not found for symbol %s", symbol)
 }
 
+// GetContractSpec gets tick size, lot step and minimums for symbol
+func (t *AsterTrader) GetContractSpec(symbol string) (*types.ContractSpec, error) {
+	prec, err := t.getPrecision(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ContractSpec{
+		Symbol:             symbol,
+		Exchange:           "aster",
+		TickSize:           prec.TickSize,
+		QtyStep:            prec.StepSize,
+		MinQty:             prec.MinQty,
+		MinNotional:        prec.MinNotional,
+		ContractMultiplier: 1,
+		UpdatedAt:          time.Now(),
+	}, nil
+}
+
 // roundToTickSize Round price/quantity to the nearest multiple of tick size/step size
 func roundToTickSize(value float64, tickSize float64) float64 {
 	if tickSize <= 0 {
@@ -576,6 +603,16 @@ func (t *AsterTrader) GetPositions() ([]map[string]interface{}, error) {
 
 // OpenLong Open long position
 func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, "")
+}
+
+// OpenLongWithClientID is OpenLong with a caller-supplied client order ID (retry-safe)
+func (t *AsterTrader) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, clientOrderID)
+}
+
+// openLong opens a long position using the given Aster client order ID (empty for none)
+func (t *AsterTrader) openLong(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	// Cancel all pending orders before opening position to prevent position stacking from residual orders
 	if err := t.CancelAllOrders(symbol); err != nil {
 		logger.Infof("  ⚠ Failed to cancel pending orders (continuing to open position): %v", err)
@@ -634,6 +671,10 @@ func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (m
 		"price":        priceStr,
 	}
 
+	if clientOrderID != "" {
+		params["newClientOrderId"] = clientOrderID
+	}
+
 	body, err := t.request("POST", "/fapi/v3/order", params)
 	if err != nil {
 		return nil, err
@@ -649,6 +690,16 @@ func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (m
 
 // OpenShort Open short position
 func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, "")
+}
+
+// OpenShortWithClientID is OpenShort with a caller-supplied client order ID (retry-safe)
+func (t *AsterTrader) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, clientOrderID)
+}
+
+// openShort opens a short position using the given Aster client order ID (empty for none)
+func (t *AsterTrader) openShort(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	// Cancel all pending orders before opening position to prevent position stacking from residual orders
 	if err := t.CancelAllOrders(symbol); err != nil {
 		logger.Infof("  ⚠ Failed to cancel pending orders (continuing to open position): %v", err)
@@ -707,6 +758,10 @@ func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (
 		"price":        priceStr,
 	}
 
+	if clientOrderID != "" {
+		params["newClientOrderId"] = clientOrderID
+	}
+
 	body, err := t.request("POST", "/fapi/v3/order", params)
 	if err != nil {
 		return nil, err
@@ -722,6 +777,16 @@ func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (
 
 // CloseLong Close long position
 func (t *AsterTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, "")
+}
+
+// CloseLongWithClientID is CloseLong with a caller-supplied client order ID (retry-safe)
+func (t *AsterTrader) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, clientOrderID)
+}
+
+// closeLong closes a long position using the given Aster client order ID (empty for none)
+func (t *AsterTrader) closeLong(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	// If quantity is 0, get current position quantity
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -782,6 +847,10 @@ func (t *AsterTrader) CloseLong(symbol string, quantity float64) (map[string]int
 		"price":        priceStr,
 	}
 
+	if clientOrderID != "" {
+		params["newClientOrderId"] = clientOrderID
+	}
+
 	body, err := t.request("POST", "/fapi/v3/order", params)
 	if err != nil {
 		return nil, err
@@ -804,6 +873,16 @@ func (t *AsterTrader) CloseLong(symbol string, quantity float64) (map[string]int
 
 // CloseShort Close short position
 func (t *AsterTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, "")
+}
+
+// CloseShortWithClientID is CloseShort with a caller-supplied client order ID (retry-safe)
+func (t *AsterTrader) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, clientOrderID)
+}
+
+// closeShort closes a short position using the given Aster client order ID (empty for none)
+func (t *AsterTrader) closeShort(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	// If quantity is 0, get current position quantity
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -865,6 +944,10 @@ func (t *AsterTrader) CloseShort(symbol string, quantity float64) (map[string]in
 		"price":        priceStr,
 	}
 
+	if clientOrderID != "" {
+		params["newClientOrderId"] = clientOrderID
+	}
+
 	body, err := t.request("POST", "/fapi/v3/order", params)
 	if err != nil {
 		return nil, err
@@ -1052,6 +1135,12 @@ func (t *AsterTrader) SetTakeProfit(symbol string, positionSide string, quantity
 	return err
 }
 
+// SetTrailingStop Aster has no native trailing stop order
+// Returns ErrTrailingStopNotSupported so the caller emulates it by ratcheting the stop-loss
+func (t *AsterTrader) SetTrailingStop(symbol string, positionSide string, quantity, callbackRate, activationPrice float64) error {
+	return types.ErrTrailingStopNotSupported
+}
+
 // CancelStopLossOrders Cancel stop-loss orders only (does not affect take-profit orders)
 func (t *AsterTrader) CancelStopLossOrders(symbol string) error {
 	// Get all open orders for this symbol
@@ -1244,6 +1333,36 @@ func (t *AsterTrader) FormatQuantity(symbol string, quantity float64) (string, e
 	return fmt.Sprintf("%v", formatted), nil
 }
 
+// GetOrderByClientID looks up an order by the newClientOrderId passed to *WithClientID
+// Returns nil, nil if Aster has no such order
+func (t *AsterTrader) GetOrderByClientID(symbol, clientOrderID string) (map[string]interface{}, error) {
+	params := map[string]interface{}{
+		"symbol":            symbol,
+		"origClientOrderId": clientOrderID,
+	}
+
+	body, err := t.request("GET", "/fapi/v3/order", params)
+	if err != nil {
+		// -2013: Order does not exist
+		if strings.Contains(err.Error(), "-2013") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order by client ID: %w", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse order response: %w", err)
+	}
+
+	return map[string]interface{}{
+		"orderId":       result["orderId"],
+		"clientOrderId": clientOrderID,
+		"symbol":        symbol,
+		"status":        result["status"],
+	}, nil
+}
+
 // GetOrderStatus Get order status
 func (t *AsterTrader) GetOrderStatus(symbol string, orderID string) (map[string]interface{}, error) {
 	params := map[string]interface{}{
@@ -1401,6 +1520,7 @@ func (t *AsterTrader) GetTrades(startTime time.Time, limit int) ([]types.TradeRe
 
 		trade := types.TradeRecord{
 			TradeID:      strconv.FormatInt(at.ID, 10),
+			OrderID:      strconv.FormatInt(at.OrderID, 10),
 			Symbol:       at.Symbol,
 			Side:         at.Side,
 			PositionSide: at.PositionSide,
@@ -1428,15 +1548,16 @@ func (t *AsterTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 	}
 
 	var orders []struct {
-		OrderID      int64  `json:"orderId"`
-		Symbol       string `json:"symbol"`
-		Side         string `json:"side"`
-		PositionSide string `json:"positionSide"`
-		Type         string `json:"type"`
-		Price        string `json:"price"`
-		StopPrice    string `json:"stopPrice"`
-		OrigQty      string `json:"origQty"`
-		Status       string `json:"status"`
+		OrderID       int64  `json:"orderId"`
+		ClientOrderID string `json:"clientOrderId"`
+		Symbol        string `json:"symbol"`
+		Side          string `json:"side"`
+		PositionSide  string `json:"positionSide"`
+		Type          string `json:"type"`
+		Price         string `json:"price"`
+		StopPrice     string `json:"stopPrice"`
+		OrigQty       string `json:"origQty"`
+		Status        string `json:"status"`
 	}
 
 	if err := json.Unmarshal(body, &orders); err != nil {
@@ -1459,6 +1580,8 @@ func (t *AsterTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 			StopPrice:    stopPrice,
 			Quantity:     quantity,
 			Status:       order.Status,
+
+			ClientOrderID: order.ClientOrderID,
 		})
 	}
 
@@ -1504,10 +1627,18 @@ func (t *AsterTrader) PlaceLimitOrder(req *types.LimitOrderRequest) (*types.Limi
 		"price":        priceStr,
 	}
 
+	// GTX = post-only, rejected instead of filling as taker
+	if req.PostOnly {
+		params["timeInForce"] = "GTX"
+	}
+
 	// Add reduceOnly if specified
 	if req.ReduceOnly {
 		params["reduceOnly"] = "true"
 	}
+	if req.ClientID != "" {
+		params["newClientOrderId"] = req.ClientID
+	}
 
 	body, err := t.request("POST", "/fapi/v3/order", params)
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/crypto"
@@ -299,3 +300,70 @@ func TestNewAsterTrader(t *testing.T) {
 		})
 	}
 }
+
+// TestAsterTrader_GridTraderConformance runs the GridTrader conformance suite against a mock Aster server
+func TestAsterTrader_GridTraderConformance(t *testing.T) {
+	recorder := &testutil.GridMockRecorder{}
+	book := func(levels [][2]string) [][]string {
+		var out [][]string
+		for _, level := range levels {
+			out = append(out, []string{level[0], level[1]})
+		}
+		return out
+	}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var respBody interface{}
+
+		switch {
+		case r.URL.Path == "/fapi/v3/order" && r.Method == "POST":
+			r.ParseForm()
+			price, _ := strconv.ParseFloat(r.PostForm.Get("price"), 64)
+			recorder.RecordOrder(testutil.GridOrderCapture{
+				Side:       r.PostForm.Get("side"),
+				Price:      price,
+				PostOnly:   r.PostForm.Get("timeInForce") == "GTX",
+				ReduceOnly: r.PostForm.Get("reduceOnly") == "true",
+				ClientID:   r.PostForm.Get("newClientOrderId"),
+			})
+			respBody = map[string]interface{}{
+				"orderId":       900001,
+				"clientOrderId": r.PostForm.Get("newClientOrderId"),
+				"status":        "NEW",
+			}
+
+		case r.URL.Path == "/fapi/v3/order" && r.Method == "DELETE":
+			recorder.RecordCancel(r.URL.Query().Get("orderId"))
+			respBody = map[string]interface{}{"orderId": 900001, "status": "CANCELED"}
+
+		case r.URL.Path == "/fapi/v3/depth":
+			respBody = map[string]interface{}{
+				"bids": book(testutil.GridMockBids),
+				"asks": book(testutil.GridMockAsks),
+			}
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(respBody)
+	}))
+	defer mockServer.Close()
+
+	privateKey, _ := crypto.GenerateKey()
+	trader := &AsterTrader{
+		ctx:        context.Background(),
+		user:       "0x1234567890123456789012345678901234567890",
+		signer:     "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd",
+		privateKey: privateKey,
+		client:     mockServer.Client(),
+		baseURL:    mockServer.URL,
+		symbolPrecision: map[string]SymbolPrecision{
+			"BTCUSDT": {PricePrecision: 1, QuantityPrecision: 3, TickSize: 0.1, StepSize: 0.001, MinQty: 0.001},
+		},
+	}
+
+	testutil.NewGridTraderTestSuite(t, trader, recorder, 0.05).RunAllTests()
+}
@@ -21,6 +21,7 @@ import (
 	"nofx/trader/okx"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -109,6 +110,9 @@ type AutoTraderConfig struct {
 
 	// Strategy configuration (use complete strategy config)
 	StrategyConfig *store.StrategyConfig // Strategy configuration (includes coin sources, indicators, risk control, prompts, etc.)
+
+	// Funding arbitrage venues: the user's other exchange accounts (only Exchange, ExchangeID and credentials are used)
+	ArbVenues []AutoTraderConfig
 }
 
 // AutoTrader automatic trader
@@ -142,7 +146,20 @@ type AutoTrader struct {
 	peakPnLCacheMutex     sync.RWMutex       // Cache read-write lock
 	lastBalanceSyncTime   time.Time          // Last balance sync time
 	userID                string             // User ID
-	gridState             *GridState         // Grid trading state (only used when StrategyType == "grid_trading")
+	gridState             *GridState         // Basket symbol being cycled; only touched while holding gridCycleMutex
+	gridBasket            map[string]*GridState // Grid state per basket symbol (only used when StrategyType == "grid_trading")
+	gridSymbols           []string           // Basket symbols in config order
+	gridBasketMutex       sync.RWMutex       // Guards gridBasket and gridSymbols, which the API reads
+	gridCycleMutex        sync.Mutex         // Serialises grid cycles, which switch gridState between basket symbols
+	dcaDeal               *store.DCADeal     // Active DCA deal (only used when StrategyType == "dca", nil when idle)
+	arbVenues             map[string]*fundingArbVenue // Exchange accounts by ID for funding arbitrage, primary included
+	reconciler            atomic.Pointer[PositionReconciler] // Position reconciler (nil when store is unavailable); read by the API while Run sets it
+	trailingStops         map[string]*trailingStopState // Software-emulated trailing stops (symbol_SIDE -> state)
+	trailingStopsMutex    sync.Mutex
+	tpLadders             map[string]*takeProfitLadder // Take-profit ladders being tracked (symbol_SIDE -> ladder)
+	tpLaddersMutex        sync.Mutex
+	algoOrders            map[string]int64 // Algo parent orders being worked in the background (symbol -> parent order ID)
+	algoOrdersMutex       sync.Mutex
 }
 
 // NewAutoTrader creates an automatic trader
@@ -229,10 +246,6 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 		config.Exchange = "binance"
 	}
 
-	// Create corresponding trader based on configuration
-	var trader Trader
-	var err error
-
 	// Record position mode (general)
 	marginModeStr := "Cross Margin"
 	if !config.IsCrossMargin {
@@ -240,57 +253,10 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 	}
 	logger.Infof("📊 [%s] Position mode: %s", config.Name, marginModeStr)
 
-	switch config.Exchange {
-	case "binance":
-		logger.Infof("🏦 [%s] Using Binance Futures trading", config.Name)
-		trader = binance.NewFuturesTrader(config.BinanceAPIKey, config.BinanceSecretKey, userID)
-	case "bybit":
-		logger.Infof("🏦 [%s] Using Bybit Futures trading", config.Name)
-		trader = bybit.NewBybitTrader(config.BybitAPIKey, config.BybitSecretKey)
-	case "okx":
-		logger.Infof("🏦 [%s] Using OKX Futures trading", config.Name)
-		trader = okx.NewOKXTrader(config.OKXAPIKey, config.OKXSecretKey, config.OKXPassphrase)
-	case "bitget":
-		logger.Infof("🏦 [%s] Using Bitget Futures trading", config.Name)
-		trader = bitget.NewBitgetTrader(config.BitgetAPIKey, config.BitgetSecretKey, config.BitgetPassphrase)
-	case "gate":
-		logger.Infof("🏦 [%s] Using Gate.io Futures trading", config.Name)
-		trader = gate.NewGateTrader(config.GateAPIKey, config.GateSecretKey)
-	case "kucoin":
-		logger.Infof("🏦 [%s] Using KuCoin Futures trading", config.Name)
-		trader = kucoin.NewKuCoinTrader(config.KuCoinAPIKey, config.KuCoinSecretKey, config.KuCoinPassphrase)
-	case "hyperliquid":
-		logger.Infof("🏦 [%s] Using Hyperliquid trading", config.Name)
-		trader, err = hyperliquid.NewHyperliquidTrader(config.HyperliquidPrivateKey, config.HyperliquidWalletAddr, config.HyperliquidTestnet, config.HyperliquidUnifiedAcct)
-		if err != nil {
-			return nil, fmt.Errorf("failed to initialize Hyperliquid trader: %w", err)
-		}
-	case "aster":
-		logger.Infof("🏦 [%s] Using Aster trading", config.Name)
-		trader, err = aster.NewAsterTrader(config.AsterUser, config.AsterSigner, config.AsterPrivateKey)
-		if err != nil {
-			return nil, fmt.Errorf("failed to initialize Aster trader: %w", err)
-		}
-	case "lighter":
-		logger.Infof("🏦 [%s] Using LIGHTER trading", config.Name)
-
-		if config.LighterWalletAddr == "" || config.LighterAPIKeyPrivateKey == "" {
-			return nil, fmt.Errorf("Lighter requires wallet address and API Key private key")
-		}
-
-		// Lighter only supports mainnet (testnet disabled)
-		trader, err = lighter.NewLighterTraderV2(
-			config.LighterWalletAddr,
-			config.LighterAPIKeyPrivateKey,
-			config.LighterAPIKeyIndex,
-			false, // Always use mainnet for Lighter
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to initialize LIGHTER trader: %w", err)
-		}
-		logger.Infof("✓ LIGHTER trader initialized successfully")
-	default:
-		return nil, fmt.Errorf("unsupported trading platform: %s", config.Exchange)
+	// Create corresponding trader based on configuration
+	trader, err := newExchangeTrader(config, userID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate initial balance configuration, auto-fetch from exchange if 0
@@ -339,6 +305,14 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 	strategyEngine := kernel.NewStrategyEngine(config.StrategyConfig)
 	logger.Infof("✓ [%s] Using strategy engine (strategy configuration loaded)", config.Name)
 
+	var arbVenues map[string]*fundingArbVenue
+	if config.StrategyConfig.StrategyType == "funding_arb" {
+		arbVenues, err = newFundingArbVenues(config, trader, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &AutoTrader{
 		id:                    config.ID,
 		name:                  config.Name,
@@ -358,15 +332,79 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 		callCount:             0,
 		isRunning:             false,
 		positionFirstSeenTime: make(map[string]int64),
+		trailingStops:         make(map[string]*trailingStopState),
+		tpLadders:             make(map[string]*takeProfitLadder),
+		algoOrders:            make(map[string]int64),
 		stopMonitorCh:         make(chan struct{}),
 		monitorWg:             sync.WaitGroup{},
 		peakPnLCache:          make(map[string]float64),
 		peakPnLCacheMutex:     sync.RWMutex{},
 		lastBalanceSyncTime:   time.Now(),
 		userID:                userID,
+		arbVenues:             arbVenues,
 	}, nil
 }
 
+// newExchangeTrader creates the exchange adapter selected by config.Exchange using its credentials
+func newExchangeTrader(config AutoTraderConfig, userID string) (Trader, error) {
+	var trader Trader
+	var err error
+
+	switch config.Exchange {
+	case "binance":
+		logger.Infof("🏦 [%s] Using Binance Futures trading", config.Name)
+		trader = binance.NewFuturesTrader(config.BinanceAPIKey, config.BinanceSecretKey, userID)
+	case "bybit":
+		logger.Infof("🏦 [%s] Using Bybit Futures trading", config.Name)
+		trader = bybit.NewBybitTrader(config.BybitAPIKey, config.BybitSecretKey)
+	case "okx":
+		logger.Infof("🏦 [%s] Using OKX Futures trading", config.Name)
+		trader = okx.NewOKXTrader(config.OKXAPIKey, config.OKXSecretKey, config.OKXPassphrase)
+	case "bitget":
+		logger.Infof("🏦 [%s] Using Bitget Futures trading", config.Name)
+		trader = bitget.NewBitgetTrader(config.BitgetAPIKey, config.BitgetSecretKey, config.BitgetPassphrase)
+	case "gate":
+		logger.Infof("🏦 [%s] Using Gate.io Futures trading", config.Name)
+		trader = gate.NewGateTrader(config.GateAPIKey, config.GateSecretKey)
+	case "kucoin":
+		logger.Infof("🏦 [%s] Using KuCoin Futures trading", config.Name)
+		trader = kucoin.NewKuCoinTrader(config.KuCoinAPIKey, config.KuCoinSecretKey, config.KuCoinPassphrase)
+	case "hyperliquid":
+		logger.Infof("🏦 [%s] Using Hyperliquid trading", config.Name)
+		trader, err = hyperliquid.NewHyperliquidTrader(config.HyperliquidPrivateKey, config.HyperliquidWalletAddr, config.HyperliquidTestnet, config.HyperliquidUnifiedAcct)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Hyperliquid trader: %w", err)
+		}
+	case "aster":
+		logger.Infof("🏦 [%s] Using Aster trading", config.Name)
+		trader, err = aster.NewAsterTrader(config.AsterUser, config.AsterSigner, config.AsterPrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Aster trader: %w", err)
+		}
+	case "lighter":
+		logger.Infof("🏦 [%s] Using LIGHTER trading", config.Name)
+	
+		if config.LighterWalletAddr == "" || config.LighterAPIKeyPrivateKey == "" {
+			return nil, fmt.Errorf("Lighter requires wallet address and API Key private key")
+		}
+	
+		// Lighter only supports mainnet (testnet disabled)
+		trader, err = lighter.NewLighterTraderV2(
+			config.LighterWalletAddr,
+			config.LighterAPIKeyPrivateKey,
+			config.LighterAPIKeyIndex,
+			false, // Always use mainnet for Lighter
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize LIGHTER trader: %w", err)
+		}
+		logger.Infof("✓ LIGHTER trader initialized successfully")
+	default:
+		return nil, fmt.Errorf("unsupported trading platform: %s", config.Exchange)
+	}
+	return trader, nil
+}
+
 // Run runs the automatic trading main loop
 func (at *AutoTrader) Run() error {
 	at.isRunningMutex.Lock()
@@ -386,6 +424,9 @@ func (at *AutoTrader) Run() error {
 	// Start drawdown monitoring
 	at.startDrawdownMonitor()
 
+	// Start exit monitoring (take-profit ladders and software trailing stops)
+	at.startExitMonitor()
+
 	// Start Lighter order sync if using Lighter exchange
 	if at.exchange == "lighter" {
 		if lighterTrader, ok := at.trader.(*lighter.LighterTraderV2); ok && at.store != nil {
@@ -458,29 +499,78 @@ func (at *AutoTrader) Run() error {
 		}
 	}
 
+	// Start position reconciler (compares local positions, order fills and exchange positions)
+	if at.store != nil {
+		reconciler := NewPositionReconciler(at.id, at.exchangeID, at.exchange, at.trader, at.store)
+		reconciler.Start()
+		at.reconciler.Store(reconciler)
+	}
+
 	ticker := time.NewTicker(at.config.ScanInterval)
 	defer ticker.Stop()
 
-	// Check if this is a grid trading strategy
+	// Check if this is a grid trading, DCA, funding arbitrage or pairs strategy
 	isGridStrategy := at.IsGridStrategy()
+	isDCAStrategy := !isGridStrategy && at.IsDCAStrategy()
+	isFundingArbStrategy := !isGridStrategy && !isDCAStrategy && at.IsFundingArbStrategy()
+	isPairsStrategy := !isGridStrategy && !isDCAStrategy && !isFundingArbStrategy && at.IsPairsStrategy()
 	if isGridStrategy {
 		logger.Infof("🔲 [%s] Grid trading strategy detected, initializing grid...", at.name)
 		if err := at.InitializeGrid(); err != nil {
-			logger.Errorf("❌ [%s] Failed to initialize grid: %v", at.name, err)
-			return fmt.Errorf("grid initialization failed: %w", err)
+			if len(at.gridBasketStates()) == 0 {
+				logger.Errorf("❌ [%s] Failed to initialize grid: %v", at.name, err)
+				return fmt.Errorf("grid initialization failed: %w", err)
+			}
+			// Basket symbols that failed are retried every cycle
+			logger.Warnf("⚠️ [%s] Grid basket partially initialized: %v", at.name, err)
+		}
+	} else if isDCAStrategy {
+		logger.Infof("🪜 [%s] DCA strategy detected, loading deals...", at.name)
+		if err := at.InitializeDCA(); err != nil {
+			logger.Errorf("❌ [%s] Failed to initialize DCA bot: %v", at.name, err)
+			return fmt.Errorf("dca initialization failed: %w", err)
+		}
+	} else if isFundingArbStrategy {
+		logger.Infof("⚖️ [%s] Funding arbitrage strategy detected, loading open pairs...", at.name)
+		if err := at.InitializeFundingArb(); err != nil {
+			logger.Errorf("❌ [%s] Failed to initialize funding arbitrage: %v", at.name, err)
+			return fmt.Errorf("funding arbitrage initialization failed: %w", err)
+		}
+	} else if isPairsStrategy {
+		logger.Infof("🔗 [%s] Pairs strategy detected, loading open spread trade...", at.name)
+		if err := at.InitializePairs(); err != nil {
+			logger.Errorf("❌ [%s] Failed to initialize pairs trading: %v", at.name, err)
+			return fmt.Errorf("pairs initialization failed: %w", err)
+		}
+	}
+
+	runStrategyCycle := func() {
+		switch {
+		case isGridStrategy:
+			if err := at.RunGridCycle(); err != nil {
+				logger.Infof("❌ Grid execution failed: %v", err)
+			}
+		case isDCAStrategy:
+			if err := at.RunDCACycle(); err != nil {
+				logger.Infof("❌ DCA execution failed: %v", err)
+			}
+		case isFundingArbStrategy:
+			if err := at.RunFundingArbCycle(); err != nil {
+				logger.Infof("❌ Funding arbitrage execution failed: %v", err)
+			}
+		case isPairsStrategy:
+			if err := at.RunPairsCycle(); err != nil {
+				logger.Infof("❌ Pairs execution failed: %v", err)
+			}
+		default:
+			if err := at.runCycle(); err != nil {
+				logger.Infof("❌ Execution failed: %v", err)
+			}
 		}
 	}
 
 	// Execute immediately on first run
-	if isGridStrategy {
-		if err := at.RunGridCycle(); err != nil {
-			logger.Infof("❌ Grid execution failed: %v", err)
-		}
-	} else {
-		if err := at.runCycle(); err != nil {
-			logger.Infof("❌ Execution failed: %v", err)
-		}
-	}
+	runStrategyCycle()
 
 	for {
 		at.isRunningMutex.RLock()
@@ -493,15 +583,7 @@ func (at *AutoTrader) Run() error {
 
 		select {
 		case <-ticker.C:
-			if isGridStrategy {
-				if err := at.RunGridCycle(); err != nil {
-					logger.Infof("❌ Grid execution failed: %v", err)
-				}
-			} else {
-				if err := at.runCycle(); err != nil {
-					logger.Infof("❌ Execution failed: %v", err)
-				}
-			}
+			runStrategyCycle()
 		case <-at.stopMonitorCh:
 			logger.Infof("[%s] ⏹ Stop signal received, exiting automatic trading main loop", at.name)
 			return nil
@@ -523,9 +605,21 @@ func (at *AutoTrader) Stop() {
 
 	close(at.stopMonitorCh) // Notify monitoring goroutine to stop
 	at.monitorWg.Wait()     // Wait for monitoring goroutine to finish
+	if reconciler := at.reconciler.Load(); reconciler != nil {
+		reconciler.Stop()
+	}
+	// Grid orders stay on the exchange; checkpoint so the next start re-attaches to them
+	at.gridCycleMutex.Lock()
+	at.checkpointGridBasket()
+	at.gridCycleMutex.Unlock()
 	logger.Info("⏹ Automatic trading system stopped")
 }
 
+// GetReconciler returns the position reconciler (nil if the trader has not started or has no store)
+func (at *AutoTrader) GetReconciler() *PositionReconciler {
+	return at.reconciler.Load()
+}
+
 // runCycle runs one trading cycle (using AI full decision-making)
 func (at *AutoTrader) runCycle() error {
 	at.callCount++
@@ -716,11 +810,15 @@ func (at *AutoTrader) runCycle() error {
 			Price:      0,
 			StopLoss:   d.StopLoss,
 			TakeProfit: d.TakeProfit,
+			TrailingCallbackRate: d.TrailingCallbackRate,
 			Confidence: d.Confidence,
 			Reasoning:  d.Reasoning,
 			Timestamp:  time.Now().UTC(),
 			Success:    false,
 		}
+		if isOrderAction(d.Action) {
+			actionRecord.ClientOrderID = at.clientOrderIDForCycle(d.Symbol, d.Action)
+		}
 
 		if err := at.executeDecisionWithRecord(&d, &actionRecord); err != nil {
 			logger.Infof("❌ Failed to execute decision (%s %s): %v", d.Symbol, d.Action, err)
@@ -926,6 +1024,16 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 		CandidateCoins: candidateCoins,
 	}
 
+	// Exchange trading rules for the symbols the AI may act on
+	specSymbols := make([]string, 0, len(candidateCoins)+len(positionInfos))
+	for _, coin := range candidateCoins {
+		specSymbols = append(specSymbols, coin.Symbol)
+	}
+	for _, pos := range positionInfos {
+		specSymbols = append(specSymbols, pos.Symbol)
+	}
+	ctx.ContractSpecs = at.contractSpecsFor(specSymbols)
+
 	// 7. Add recent closed trades (if store is available)
 	if at.store != nil {
 		// Get recent 10 closed trades for AI context
@@ -1049,6 +1157,8 @@ func (at *AutoTrader) executeDecisionWithRecord(decision *kernel.Decision, actio
 		return at.executeCloseLongWithRecord(decision, actionRecord)
 	case "close_short":
 		return at.executeCloseShortWithRecord(decision, actionRecord)
+	case "set_trailing_stop":
+		return at.executeSetTrailingStopWithRecord(decision, actionRecord)
 	case "hold", "wait":
 		// No execution needed, just record
 		return nil
@@ -1069,6 +1179,7 @@ func (at *AutoTrader) ExecuteDecision(d *kernel.Decision) error {
 		Leverage:   d.Leverage,
 		StopLoss:   d.StopLoss,
 		TakeProfit: d.TakeProfit,
+		TrailingCallbackRate: d.TrailingCallbackRate,
 		Confidence: d.Confidence,
 		Reasoning:  d.Reasoning,
 	}
@@ -1138,6 +1249,14 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 		decision.PositionSizeUSD = adjustedPositionSize
 	}
 
+	// [EXCHANGE RULES] Leverage can't exceed the exchange bracket for this notional
+	spec := at.contractSpec(decision.Symbol)
+	if exchangeMax := spec.MaxLeverageFor(decision.PositionSizeUSD); exchangeMax > 0 && decision.Leverage > exchangeMax {
+		logger.Infof("  ⚠️ %s leverage %dx exceeds exchange limit %dx for %.2f USDT, reducing",
+			decision.Symbol, decision.Leverage, exchangeMax, decision.PositionSizeUSD)
+		decision.Leverage = exchangeMax
+	}
+
 	// ⚠️ Auto-adjust position size if insufficient margin
 	// Formula: totalRequired = positionSize/leverage + positionSize*0.001 + positionSize/leverage*0.01
 	//        = positionSize * (1.01/leverage + 0.001)
@@ -1155,12 +1274,15 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 	}
 
 	// [CODE ENFORCED] Minimum position size check
-	if err := at.enforceMinPositionSize(decision.PositionSizeUSD); err != nil {
+	if err := at.enforceMinPositionSize(decision.PositionSizeUSD, spec.MinOrderValue(marketData.CurrentPrice)); err != nil {
 		return err
 	}
 
-	// Calculate quantity with adjusted position size
-	quantity := actualPositionSize / marketData.CurrentPrice
+	// Calculate quantity with adjusted position size, rounded down to the exchange lot step
+	quantity := spec.FloorQty(actualPositionSize / marketData.CurrentPrice)
+	if err := spec.CheckOrder(quantity, marketData.CurrentPrice); err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
 	actionRecord.Quantity = quantity
 	actionRecord.Price = marketData.CurrentPrice
 
@@ -1171,10 +1293,20 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 	}
 
 	// Open position
-	order, err := at.trader.OpenLong(decision.Symbol, quantity, decision.Leverage)
+	clientOrderID := at.ensureClientOrderID(actionRecord, decision.Symbol, "open_long")
+	order, err := at.executeOrder(decision, "open_long", quantity, marketData.CurrentPrice, decision.Leverage, clientOrderID,
+		func(filled, avgPrice float64, done bool) {
+			at.protectAlgoOpen(decision, "LONG", filled, avgPrice, done)
+		})
 	if err != nil {
 		return err
 	}
+	if isAlgoOrder(order) {
+		// Stop-loss and take-profit follow the child fills
+		at.positionFirstSeenTime[decision.Symbol+"_long"] = time.Now().UnixMilli()
+		logger.Infof("  ✓ %s order working in the background, parent order #%v", order["algo"], order["parentOrderId"])
+		return nil
+	}
 
 	// Record order ID
 	if orderID, ok := order["orderId"].(int64); ok {
@@ -1184,7 +1316,7 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 	logger.Infof("  ✓ Position opened successfully, order ID: %v, quantity: %.4f", order["orderId"], quantity)
 
 	// Record order to database and poll for confirmation
-	at.recordAndConfirmOrder(order, decision.Symbol, "open_long", quantity, marketData.CurrentPrice, decision.Leverage, 0)
+	at.recordAndConfirmOrder(order, decision.Symbol, "open_long", quantity, marketData.CurrentPrice, decision.Leverage, 0, clientOrderID)
 
 	// Record position opening time
 	posKey := decision.Symbol + "_long"
@@ -1194,9 +1326,7 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 	if err := at.trader.SetStopLoss(decision.Symbol, "LONG", quantity, decision.StopLoss); err != nil {
 		logger.Infof("  ⚠ Failed to set stop loss: %v", err)
 	}
-	if err := at.trader.SetTakeProfit(decision.Symbol, "LONG", quantity, decision.TakeProfit); err != nil {
-		logger.Infof("  ⚠ Failed to set take profit: %v", err)
-	}
+	at.placeTakeProfitOrders(decision, "LONG", quantity, marketData.CurrentPrice)
 
 	return nil
 }
@@ -1255,6 +1385,14 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 		decision.PositionSizeUSD = adjustedPositionSize
 	}
 
+	// [EXCHANGE RULES] Leverage can't exceed the exchange bracket for this notional
+	spec := at.contractSpec(decision.Symbol)
+	if exchangeMax := spec.MaxLeverageFor(decision.PositionSizeUSD); exchangeMax > 0 && decision.Leverage > exchangeMax {
+		logger.Infof("  ⚠️ %s leverage %dx exceeds exchange limit %dx for %.2f USDT, reducing",
+			decision.Symbol, decision.Leverage, exchangeMax, decision.PositionSizeUSD)
+		decision.Leverage = exchangeMax
+	}
+
 	// ⚠️ Auto-adjust position size if insufficient margin
 	// Formula: totalRequired = positionSize/leverage + positionSize*0.001 + positionSize/leverage*0.01
 	//        = positionSize * (1.01/leverage + 0.001)
@@ -1272,12 +1410,15 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 	}
 
 	// [CODE ENFORCED] Minimum position size check
-	if err := at.enforceMinPositionSize(decision.PositionSizeUSD); err != nil {
+	if err := at.enforceMinPositionSize(decision.PositionSizeUSD, spec.MinOrderValue(marketData.CurrentPrice)); err != nil {
 		return err
 	}
 
-	// Calculate quantity with adjusted position size
-	quantity := actualPositionSize / marketData.CurrentPrice
+	// Calculate quantity with adjusted position size, rounded down to the exchange lot step
+	quantity := spec.FloorQty(actualPositionSize / marketData.CurrentPrice)
+	if err := spec.CheckOrder(quantity, marketData.CurrentPrice); err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
 	actionRecord.Quantity = quantity
 	actionRecord.Price = marketData.CurrentPrice
 
@@ -1288,10 +1429,20 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 	}
 
 	// Open position
-	order, err := at.trader.OpenShort(decision.Symbol, quantity, decision.Leverage)
+	clientOrderID := at.ensureClientOrderID(actionRecord, decision.Symbol, "open_short")
+	order, err := at.executeOrder(decision, "open_short", quantity, marketData.CurrentPrice, decision.Leverage, clientOrderID,
+		func(filled, avgPrice float64, done bool) {
+			at.protectAlgoOpen(decision, "SHORT", filled, avgPrice, done)
+		})
 	if err != nil {
 		return err
 	}
+	if isAlgoOrder(order) {
+		// Stop-loss and take-profit follow the child fills
+		at.positionFirstSeenTime[decision.Symbol+"_short"] = time.Now().UnixMilli()
+		logger.Infof("  ✓ %s order working in the background, parent order #%v", order["algo"], order["parentOrderId"])
+		return nil
+	}
 
 	// Record order ID
 	if orderID, ok := order["orderId"].(int64); ok {
@@ -1301,7 +1452,7 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 	logger.Infof("  ✓ Position opened successfully, order ID: %v, quantity: %.4f", order["orderId"], quantity)
 
 	// Record order to database and poll for confirmation
-	at.recordAndConfirmOrder(order, decision.Symbol, "open_short", quantity, marketData.CurrentPrice, decision.Leverage, 0)
+	at.recordAndConfirmOrder(order, decision.Symbol, "open_short", quantity, marketData.CurrentPrice, decision.Leverage, 0, clientOrderID)
 
 	// Record position opening time
 	posKey := decision.Symbol + "_short"
@@ -1311,9 +1462,7 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 	if err := at.trader.SetStopLoss(decision.Symbol, "SHORT", quantity, decision.StopLoss); err != nil {
 		logger.Infof("  ⚠ Failed to set stop loss: %v", err)
 	}
-	if err := at.trader.SetTakeProfit(decision.Symbol, "SHORT", quantity, decision.TakeProfit); err != nil {
-		logger.Infof("  ⚠ Failed to set take profit: %v", err)
-	}
+	at.placeTakeProfitOrders(decision, "SHORT", quantity, marketData.CurrentPrice)
 
 	return nil
 }
@@ -1365,10 +1514,21 @@ func (at *AutoTrader) executeCloseLongWithRecord(decision *kernel.Decision, acti
 	}
 
 	// Close position
-	order, err := at.trader.CloseLong(decision.Symbol, 0) // 0 = close all
+	clientOrderID := at.ensureClientOrderID(actionRecord, decision.Symbol, "close_long")
+	order, err := at.executeOrder(decision, "close_long", quantity, marketData.CurrentPrice, 0, clientOrderID,
+		func(filled, avgPrice float64, done bool) {
+			if done {
+				at.removeTrailingStop(decision.Symbol, "LONG")
+				at.removeTakeProfitLadder(decision.Symbol, "LONG", true)
+			}
+		})
 	if err != nil {
 		return err
 	}
+	if isAlgoOrder(order) {
+		logger.Infof("  ✓ %s close working in the background, parent order #%v", order["algo"], order["parentOrderId"])
+		return nil
+	}
 
 	// Record order ID
 	if orderID, ok := order["orderId"].(int64); ok {
@@ -1376,7 +1536,9 @@ func (at *AutoTrader) executeCloseLongWithRecord(decision *kernel.Decision, acti
 	}
 
 	// Record order to database and poll for confirmation
-	at.recordAndConfirmOrder(order, decision.Symbol, "close_long", quantity, marketData.CurrentPrice, 0, entryPrice)
+	at.recordAndConfirmOrder(order, decision.Symbol, "close_long", quantity, marketData.CurrentPrice, 0, entryPrice, clientOrderID)
+	at.removeTrailingStop(decision.Symbol, "LONG")
+	at.removeTakeProfitLadder(decision.Symbol, "LONG", true)
 
 	logger.Infof("  ✓ Position closed successfully")
 	return nil
@@ -1429,10 +1591,21 @@ func (at *AutoTrader) executeCloseShortWithRecord(decision *kernel.Decision, act
 	}
 
 	// Close position
-	order, err := at.trader.CloseShort(decision.Symbol, 0) // 0 = close all
+	clientOrderID := at.ensureClientOrderID(actionRecord, decision.Symbol, "close_short")
+	order, err := at.executeOrder(decision, "close_short", quantity, marketData.CurrentPrice, 0, clientOrderID,
+		func(filled, avgPrice float64, done bool) {
+			if done {
+				at.removeTrailingStop(decision.Symbol, "SHORT")
+				at.removeTakeProfitLadder(decision.Symbol, "SHORT", true)
+			}
+		})
 	if err != nil {
 		return err
 	}
+	if isAlgoOrder(order) {
+		logger.Infof("  ✓ %s close working in the background, parent order #%v", order["algo"], order["parentOrderId"])
+		return nil
+	}
 
 	// Record order ID
 	if orderID, ok := order["orderId"].(int64); ok {
@@ -1440,7 +1613,9 @@ func (at *AutoTrader) executeCloseShortWithRecord(decision *kernel.Decision, act
 	}
 
 	// Record order to database and poll for confirmation
-	at.recordAndConfirmOrder(order, decision.Symbol, "close_short", quantity, marketData.CurrentPrice, 0, entryPrice)
+	at.recordAndConfirmOrder(order, decision.Symbol, "close_short", quantity, marketData.CurrentPrice, 0, entryPrice, clientOrderID)
+	at.removeTrailingStop(decision.Symbol, "SHORT")
+	at.removeTakeProfitLadder(decision.Symbol, "SHORT", true)
 
 	logger.Infof("  ✓ Position closed successfully")
 	return nil
@@ -1526,11 +1701,12 @@ func (at *AutoTrader) saveEquitySnapshot(ctx *kernel.Context) {
 
 // saveDecision saves AI decision log to database (only records AI input/output, for debugging)
 func (at *AutoTrader) saveDecision(record *store.DecisionRecord) error {
+	// Advance the cycle even without a store: per-cycle client order IDs depend on it
+	at.cycleNumber++
 	if at.store == nil {
 		return nil
 	}
 
-	at.cycleNumber++
 	record.CycleNumber = at.cycleNumber
 	record.TraderID = at.id
 
@@ -1584,6 +1760,22 @@ func (at *AutoTrader) GetStatus() map[string]interface{} {
 		result["strategy_type"] = at.config.StrategyConfig.StrategyType
 		if at.config.StrategyConfig.GridConfig != nil {
 			result["grid_symbol"] = at.config.StrategyConfig.GridConfig.Symbol
+			if basket := at.config.StrategyConfig.GridConfig.Basket; len(basket) > 0 {
+				symbols := make([]string, len(basket))
+				for i, entry := range basket {
+					symbols[i] = entry.Symbol
+				}
+				result["grid_symbols"] = symbols
+			}
+		}
+		if at.config.StrategyConfig.DCAConfig != nil {
+			result["dca_symbol"] = at.config.StrategyConfig.DCAConfig.Symbol
+		}
+		if at.config.StrategyConfig.FundingArbConfig != nil {
+			result["funding_arb_symbols"] = at.config.StrategyConfig.FundingArbConfig.Symbols
+		}
+		if pairs := at.config.StrategyConfig.PairsConfig; pairs != nil {
+			result["pairs_symbols"] = []string{pairs.SymbolA, pairs.SymbolB}
 		}
 	}
 
@@ -1758,8 +1950,10 @@ func sortDecisionsByPriority(decisions []kernel.Decision) []kernel.Decision {
 			return 1 // Highest priority: close positions first
 		case "open_long", "open_short":
 			return 2 // Second priority: open positions later
+		case "set_trailing_stop":
+			return 3 // Adjust protection after positions are settled
 		case "hold", "wait":
-			return 3 // Lowest priority: wait
+			return 4 // Lowest priority: wait
 		default:
 			return 999 // Unknown actions at the end
 		}
@@ -1806,6 +2000,11 @@ func (at *AutoTrader) startDrawdownMonitor() {
 
 // checkPositionDrawdown checks position drawdown situation
 func (at *AutoTrader) checkPositionDrawdown() {
+	// DCA deals, funding arbitrage pairs and spread trades manage their own exits; closing one leg would break them
+	if at.IsDCAStrategy() || at.IsFundingArbStrategy() || at.IsPairsStrategy() {
+		return
+	}
+
 	// Get current positions
 	positions, err := at.trader.GetPositions()
 	if err != nil {
@@ -1941,32 +2140,48 @@ func (at *AutoTrader) ClearPeakPnLCache(symbol, side string) {
 	delete(at.peakPnLCache, posKey)
 }
 
-// recordAndConfirmOrder polls order status for actual fill data and records position
-// action: open_long, open_short, close_long, close_short
-// entryPrice: entry price when closing (0 when opening)
-func (at *AutoTrader) recordAndConfirmOrder(orderResult map[string]interface{}, symbol, action string, quantity float64, price float64, leverage int, entryPrice float64) {
-	if at.store == nil {
-		return
+// exchangeHasOrderSync reports whether the exchange's OrderSync builds orders and positions from its trade history
+func exchangeHasOrderSync(exchange string) bool {
+	switch exchange {
+	case "binance", "lighter", "hyperliquid", "bybit", "okx", "bitget", "aster", "kucoin", "gate":
+		return true
 	}
+	return false
+}
 
-	// Get order ID (supports multiple types)
-	var orderID string
+// orderResultID returns the exchange order ID of an order result as OrderSync stores it
+// Supports the types exchanges report it as; JSON-decoded numeric IDs are float64, so they're printed without exponent
+func orderResultID(orderResult map[string]interface{}) string {
 	switch v := orderResult["orderId"].(type) {
+	case nil:
+		return ""
 	case int64:
-		orderID = fmt.Sprintf("%d", v)
+		return fmt.Sprintf("%d", v)
 	case float64:
-		orderID = fmt.Sprintf("%.0f", v)
+		return fmt.Sprintf("%.0f", v)
 	case string:
-		orderID = v
+		return v
 	default:
-		orderID = fmt.Sprintf("%v", v)
+		return fmt.Sprintf("%v", v)
 	}
+}
 
-	if orderID == "" || orderID == "0" {
-		logger.Infof("  ⚠️ Order ID is empty, skipping record")
+// recordAndConfirmOrder polls order status for actual fill data and records position
+// action: open_long, open_short, close_long, close_short
+// entryPrice: entry price when closing (0 when opening)
+// clientOrderID: deterministic client order ID the order was submitted with (empty if not supported)
+func (at *AutoTrader) recordAndConfirmOrder(orderResult map[string]interface{}, symbol, action string, quantity float64, price float64, leverage int, entryPrice float64, clientOrderID string) {
+	if at.store == nil {
 		return
 	}
 
+	// Algo parent orders record their child orders as they fill
+	if isAlgoOrder(orderResult) {
+		return
+	}
+
+	orderID := orderResultID(orderResult)
+
 	// Determine positionSide
 	var positionSide string
 	switch action {
@@ -1976,20 +2191,33 @@ func (at *AutoTrader) recordAndConfirmOrder(orderResult map[string]interface{},
 		positionSide = "SHORT"
 	}
 
-	var actualPrice = price
-	var actualQty = quantity
-	var fee float64
+	// Exchanges with OrderSync: fills are recorded by OrderSync from GetTrades, which carries no client order ID,
+	// so only a submission record keyed by the client order ID is written here (lets a restarted trader skip resubmits)
+	if exchangeHasOrderSync(at.exchange) {
+		if clientOrderID != "" {
+			submission := at.createOrderRecord(clientOrderID, symbol, action, positionSide, quantity, price, leverage)
+			submission.ClientOrderID = clientOrderID
+			submission.Status = store.OrderStatusSubmitted
+			if err := at.store.Order().CreateOrder(submission); err != nil {
+				logger.Infof("  ⚠️ Failed to record order submission: %v", err)
+			}
+		}
+		logger.Infof("  📝 Order submitted (id: %s, client id: %s), will be synced by OrderSync", orderID, clientOrderID)
+		return
+	}
 
-	// Exchanges with OrderSync: Skip immediate order recording, let OrderSync handle it
-	// This ensures accurate data from GetTrades API and avoids duplicate records
-	switch at.exchange {
-	case "binance", "lighter", "hyperliquid", "bybit", "okx", "bitget", "aster", "kucoin", "gate":
-		logger.Infof("  📝 Order submitted (id: %s), will be synced by OrderSync", orderID)
+	if orderID == "" || orderID == "0" {
+		logger.Infof("  ⚠️ Order ID is empty, skipping record")
 		return
 	}
 
+	var actualPrice = price
+	var actualQty = quantity
+	var fee float64
+
 	// For exchanges without OrderSync (e.g., Binance): record immediately and poll for fill data
 	orderRecord := at.createOrderRecord(orderID, symbol, action, positionSide, quantity, price, leverage)
+	orderRecord.ClientOrderID = clientOrderID
 	if err := at.store.Order().CreateOrder(orderRecord); err != nil {
 		logger.Infof("  ⚠️ Failed to record order: %v", err)
 	} else {
@@ -2101,7 +2329,7 @@ func (at *AutoTrader) recordPositionChange(orderID, symbol, side, action string,
 			at.id, at.exchangeID, at.exchange,
 			symbol, side, action,
 			quantity, price, fee, 0, // realizedPnL will be calculated
-			time.Now().UTC().UnixMilli(), orderID,
+			time.Now().UTC().UnixMilli(), orderID, orderID,
 		); err != nil {
 			logger.Infof("  ⚠️ Failed to process close position: %v", err)
 		} else {
@@ -2275,7 +2503,8 @@ func (at *AutoTrader) enforcePositionValueRatio(positionSizeUSD float64, equity
 }
 
 // enforceMinPositionSize checks minimum position size (CODE ENFORCED)
-func (at *AutoTrader) enforceMinPositionSize(positionSizeUSD float64) error {
+// exchangeMin is the exchange's smallest order value from the contract spec (0 = unknown)
+func (at *AutoTrader) enforceMinPositionSize(positionSizeUSD, exchangeMin float64) error {
 	if at.config.StrategyConfig == nil {
 		return nil
 	}
@@ -2283,6 +2512,9 @@ func (at *AutoTrader) enforceMinPositionSize(positionSizeUSD float64) error {
 	minSize := at.config.StrategyConfig.RiskControl.MinPositionSize
 	if minSize <= 0 {
 		minSize = 12 // Default: 12 USDT
+		if exchangeMin > 0 {
+			minSize = exchangeMin
+		}
 	}
 
 	if positionSizeUSD < minSize {
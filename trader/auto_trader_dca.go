@@ -0,0 +1,609 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// dcaCloseReasonTakeProfit labels the exit leg of a deal closed by its take-profit in position history
+const dcaCloseReasonTakeProfit = "dca_take_profit"
+
+// IsDCAStrategy returns true if current strategy is a DCA bot
+func (at *AutoTrader) IsDCAStrategy() bool {
+	if at.config.StrategyConfig == nil {
+		return false
+	}
+	return at.config.StrategyConfig.StrategyType == "dca" && at.config.StrategyConfig.DCAConfig != nil
+}
+
+// dcaConfig returns the DCA bot configuration
+func (at *AutoTrader) dcaConfig() *store.DCAStrategyConfig {
+	return at.config.StrategyConfig.DCAConfig
+}
+
+// InitializeDCA validates the DCA config and resumes the active deal if the bot was restarted mid-deal
+func (at *AutoTrader) InitializeDCA() error {
+	config := at.dcaConfig()
+	if config == nil {
+		return fmt.Errorf("dca configuration not found")
+	}
+	if at.store == nil {
+		return fmt.Errorf("dca strategy requires a store to persist deals")
+	}
+	if config.Symbol == "" || config.BaseOrderUSD <= 0 || config.TakeProfitPct <= 0 {
+		return fmt.Errorf("dca configuration needs symbol, base_order_usd and take_profit_pct")
+	}
+	if config.MaxSafetyOrders > 25 {
+		return fmt.Errorf("max_safety_orders %d exceeds limit 25", config.MaxSafetyOrders)
+	}
+	if config.Leverage <= 0 {
+		config.Leverage = 1
+	}
+
+	deal, err := at.store.DCA().GetActiveDeal(at.id)
+	if err != nil {
+		return fmt.Errorf("failed to load active dca deal: %w", err)
+	}
+	at.dcaDeal = deal
+	if deal != nil {
+		logger.Infof("[DCA] Resuming deal #%d: %s %.6f @ avg %.6f, %d safety orders filled",
+			deal.ID, deal.Side, deal.Quantity, deal.AvgEntryPrice, deal.SafetyOrdersFilled)
+	}
+	return nil
+}
+
+// RunDCACycle runs one DCA bot cycle: manage the active deal, or start a new one when idle
+func (at *AutoTrader) RunDCACycle() error {
+	if at.dcaDeal != nil {
+		return at.syncDCADeal()
+	}
+
+	config := at.dcaConfig()
+	if config.MaxDeals > 0 {
+		completed, err := at.store.DCA().CountCompletedDeals(at.id)
+		if err == nil && int(completed) >= config.MaxDeals {
+			logger.Infof("[DCA] Reached max deals (%d), not starting a new deal", config.MaxDeals)
+			return nil
+		}
+	}
+
+	lastDeal, _ := at.store.DCA().GetLastClosedDeal(at.id)
+	if lastDeal != nil && !at.cancelLeftoverDCAOrders(lastDeal) {
+		return nil
+	}
+	if lastDeal != nil && config.CooldownMinutes > 0 {
+		readyAt := time.UnixMilli(lastDeal.ClosedAt).Add(time.Duration(config.CooldownMinutes) * time.Minute)
+		if time.Now().Before(readyAt) {
+			return nil
+		}
+	}
+
+	reason := "idle, starting next deal"
+	var gate *kernel.FullDecision
+	if config.AIGating {
+		var err error
+		gate, err = at.askDCAStartGate(lastDeal)
+		if err != nil {
+			return err
+		}
+		d := gate.Decisions[0]
+		if d.Action != kernel.DCAActionStartDeal {
+			at.saveStrategyDecisionRecord(gate, fmt.Sprintf("AI gate: wait (%s)", d.Reasoning))
+			return nil
+		}
+		reason = "AI gate: " + d.Reasoning
+	}
+
+	err := at.startDCADeal(reason)
+	if gate == nil {
+		gate = &kernel.FullDecision{Decisions: []kernel.Decision{{Symbol: config.Symbol, Action: kernel.DCAActionStartDeal, Reasoning: reason}}}
+	}
+	if err != nil {
+		at.saveStrategyDecisionRecord(gate, fmt.Sprintf("Failed to start deal: %v", err))
+		return err
+	}
+	at.saveStrategyDecisionRecord(gate, fmt.Sprintf("Started deal #%d", at.dcaDeal.ID))
+	return nil
+}
+
+// askDCAStartGate asks the AI whether to start a new deal now
+func (at *AutoTrader) askDCAStartGate(lastDeal *store.DCADeal) (*kernel.FullDecision, error) {
+	config := at.dcaConfig()
+	lang := at.config.StrategyConfig.Language
+	if lang == "" {
+		lang = "en"
+	}
+
+	mktData, err := market.GetWithTimeframes(config.Symbol, []string{"5m", "4h"}, "5m", 50)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market data: %w", err)
+	}
+	ctx := kernel.BuildDCAContextFromMarketData(mktData, config)
+	ctx.MaxDealCapital = DCAMaxDealCapital(config)
+	if balance, err := at.trader.GetBalance(); err == nil {
+		ctx.AvailableBalance, _ = balance["availableBalance"].(float64)
+	}
+	if completed, err := at.store.DCA().CountCompletedDeals(at.id); err == nil {
+		ctx.CompletedDeals = int(completed)
+	}
+	if lastDeal != nil {
+		ctx.LastDealPnL = lastDeal.RealizedPnL
+	}
+
+	return kernel.GetDCAStartDecision(ctx, at.mcpClient, config, lang)
+}
+
+// startDCADeal opens the base order at market, then rests the safety orders and the take-profit
+func (at *AutoTrader) startDCADeal(reason string) error {
+	config := at.dcaConfig()
+	side := dcaSide(config)
+	action := "open_long"
+	if side == "SHORT" {
+		action = "open_short"
+	}
+
+	price, err := at.trader.GetMarketPrice(config.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get market price: %w", err)
+	}
+	spec := at.contractSpec(config.Symbol)
+	quantity := spec.FloorQty(config.BaseOrderUSD / price)
+	if err := spec.CheckOrder(quantity, price); err != nil {
+		return fmt.Errorf("dca base order below exchange minimum: %w", err)
+	}
+
+	deal := &store.DCADeal{
+		TraderID:    at.id,
+		Symbol:      config.Symbol,
+		Side:        side,
+		Status:      store.DCADealActive,
+		StartReason: reason,
+	}
+	if err := at.store.DCA().SaveDeal(deal); err != nil {
+		return fmt.Errorf("failed to save dca deal: %w", err)
+	}
+
+	if err := at.trader.SetMarginMode(config.Symbol, at.config.IsCrossMargin); err != nil {
+		logger.Infof("  ⚠️ Failed to set margin mode: %v", err)
+	}
+
+	// The deal ID stands in for the cycle so client IDs stay stable across restarts
+	clientOrderID := BuildClientOrderID(at.id, int(deal.ID), config.Symbol, "dca:base")
+	order, err := at.submitMarketOrder(action, config.Symbol, quantity, config.Leverage, clientOrderID)
+	if err != nil {
+		deal.Status = store.DCADealCancelled
+		deal.ClosedAt = time.Now().UTC().UnixMilli()
+		at.store.DCA().SaveDeal(deal)
+		return fmt.Errorf("failed to open dca base order: %w", err)
+	}
+	at.recordAndConfirmOrder(order, config.Symbol, action, quantity, price, config.Leverage, 0, clientOrderID)
+
+	// Anchor safety levels on the exchange's entry price when it is available
+	fillPrice := price
+	if _, entry, err := at.dcaExchangePosition(); err == nil && entry > 0 {
+		fillPrice = entry
+	}
+
+	deal.BasePrice = fillPrice
+	deal.AvgEntryPrice = fillPrice
+	deal.Quantity = quantity
+	deal.Cost = fillPrice * quantity
+	if err := at.store.DCA().SaveDeal(deal); err != nil {
+		logger.Warnf("[DCA] Failed to save deal #%d: %v", deal.ID, err)
+	}
+	at.store.DCA().SaveOrder(&store.DCAOrder{
+		DealID:   deal.ID,
+		Kind:     store.DCAOrderBase,
+		Price:    fillPrice,
+		Quantity: quantity,
+		OrderID:  orderResultID(order),
+		ClientID: clientOrderID,
+		Status:   store.DCAOrderFilled,
+		FilledAt: time.Now().UTC().UnixMilli(),
+	})
+	at.dcaDeal = deal
+	logger.Infof("[DCA] Deal #%d started: %s %.6f %s @ %.6f (%s)", deal.ID, side, quantity, config.Symbol, fillPrice, reason)
+
+	for _, so := range DCASafetyOrderPlan(config, fillPrice) {
+		o := &store.DCAOrder{
+			DealID:   deal.ID,
+			Kind:     store.DCAOrderSafety,
+			Index:    so.Index,
+			Price:    spec.RoundPrice(so.Price),
+			Quantity: spec.FloorQty(so.Quantity),
+			ClientID: BuildClientOrderID(at.id, int(deal.ID), config.Symbol, fmt.Sprintf("dca:so:%d", so.Index)),
+			Status:   store.DCAOrderPending,
+		}
+		at.placeDCAOrder(o)
+	}
+	at.replaceDCATakeProfit(0)
+	return nil
+}
+
+// placeDCAOrder submits a pending safety or take-profit order and persists it
+// Orders that fail stay pending and are retried next cycle under the same client ID
+func (at *AutoTrader) placeDCAOrder(o *store.DCAOrder) {
+	config := at.dcaConfig()
+	gridTrader, ok := at.trader.(GridTrader)
+	if !ok {
+		gridTrader = NewGridTraderAdapter(at.trader)
+	}
+
+	// Safety orders add to the position, the take-profit closes it
+	openSide, closeSide := "BUY", "SELL"
+	if dcaIsShort(config) {
+		openSide, closeSide = "SELL", "BUY"
+	}
+	req := &LimitOrderRequest{
+		Symbol:       config.Symbol,
+		Side:         openSide,
+		PositionSide: dcaSide(config),
+		Price:        o.Price,
+		Quantity:     o.Quantity,
+		Leverage:     config.Leverage,
+		PostOnly:     config.UseMakerOnly,
+		ClientID:     o.ClientID,
+	}
+	if o.Kind == store.DCAOrderTakeProfit {
+		req.Side = closeSide
+		req.PostOnly = false
+		req.ReduceOnly = true
+	}
+
+	if err := at.contractSpec(config.Symbol).CheckOrder(o.Quantity, o.Price); err != nil {
+		logger.Warnf("[DCA] Skipping %s order %d: %v", o.Kind, o.Index, err)
+		o.Status = store.DCAOrderCancelled
+	} else if result, err := at.placeGridLimitOrderIdempotent(gridTrader, req); err != nil {
+		logger.Warnf("[DCA] Failed to place %s order %d at %.6f: %v", o.Kind, o.Index, o.Price, err)
+	} else {
+		o.OrderID = result.OrderID
+		o.Status = store.DCAOrderOpen
+		logger.Infof("[DCA] Placed %s order %d: %s %.6f @ %.6f (id: %s)", o.Kind, o.Index, req.Side, o.Quantity, o.Price, o.OrderID)
+	}
+	if err := at.store.DCA().SaveOrder(o); err != nil {
+		logger.Warnf("[DCA] Failed to save %s order %d: %v", o.Kind, o.Index, err)
+	}
+}
+
+// replaceDCATakeProfit cancels the deal's current take-profit and rests a new one on the average entry
+// exchangeQty sizes the order when the exchange reported the position, so no dust is left behind.
+// If the old take-profit can't be cancelled, nothing is placed and syncDCADeal retries next cycle
+func (at *AutoTrader) replaceDCATakeProfit(exchangeQty float64) {
+	deal := at.dcaDeal
+	config := at.dcaConfig()
+
+	orders, err := at.store.DCA().GetDealOrders(deal.ID)
+	if err != nil {
+		logger.Warnf("[DCA] Failed to load orders of deal #%d: %v", deal.ID, err)
+		return
+	}
+	for i := range orders {
+		o := &orders[i]
+		if o.Kind != store.DCAOrderTakeProfit || (o.Status != store.DCAOrderOpen && o.Status != store.DCAOrderPending) {
+			continue
+		}
+		if !at.cancelDCAOrder(o) {
+			return
+		}
+	}
+
+	spec := at.contractSpec(config.Symbol)
+	quantity := deal.Quantity
+	if exchangeQty > 0 {
+		quantity = exchangeQty
+	}
+	tp := &store.DCAOrder{
+		DealID:   deal.ID,
+		Kind:     store.DCAOrderTakeProfit,
+		Index:    deal.SafetyOrdersFilled,
+		Price:    spec.RoundPrice(DCATakeProfitPrice(config, deal.AvgEntryPrice)),
+		Quantity: spec.FloorQty(quantity),
+		ClientID: BuildClientOrderID(at.id, int(deal.ID), config.Symbol, fmt.Sprintf("dca:tp:%d", deal.SafetyOrdersFilled)),
+		Status:   store.DCAOrderPending,
+	}
+	at.placeDCATakeProfit(tp)
+}
+
+// placeDCATakeProfit places a pending take-profit and points the deal at it
+func (at *AutoTrader) placeDCATakeProfit(tp *store.DCAOrder) {
+	deal := at.dcaDeal
+	at.placeDCAOrder(tp)
+
+	deal.TakeProfitPrice = tp.Price
+	deal.TakeProfitOrderID = tp.OrderID
+	if err := at.store.DCA().SaveDeal(deal); err != nil {
+		logger.Warnf("[DCA] Failed to save deal #%d: %v", deal.ID, err)
+	}
+}
+
+// cancelDCAOrder cancels a resting deal order and marks it cancelled
+// Returns false and leaves the order open when the exchange may still hold it; callers retry next cycle
+func (at *AutoTrader) cancelDCAOrder(o *store.DCAOrder) bool {
+	if o.Status == store.DCAOrderOpen && o.OrderID != "" {
+		gridTrader, ok := at.trader.(GridTrader)
+		if !ok {
+			gridTrader = NewGridTraderAdapter(at.trader)
+		}
+		if err := gridTrader.CancelOrder(at.dcaConfig().Symbol, o.OrderID); err != nil {
+			// An order that already ended can't be cancelled; only a confirmed cancel frees it
+			if filled, known := at.dcaOrderOutcome(o); !known || filled {
+				logger.Warnf("[DCA] Failed to cancel %s order %s: %v", o.Kind, o.OrderID, err)
+				return false
+			}
+		}
+	}
+	o.Status = store.DCAOrderCancelled
+	if err := at.store.DCA().SaveOrder(o); err != nil {
+		logger.Warnf("[DCA] Failed to save %s order %d: %v", o.Kind, o.Index, err)
+	}
+	return true
+}
+
+// cancelLeftoverDCAOrders cancels orders a closed deal could not cancel when it ended
+// Returns false while any is still resting, so no new deal starts next to them
+func (at *AutoTrader) cancelLeftoverDCAOrders(deal *store.DCADeal) bool {
+	orders, err := at.store.DCA().GetDealOrders(deal.ID)
+	if err != nil {
+		logger.Warnf("[DCA] Failed to load orders of deal #%d: %v", deal.ID, err)
+		return false
+	}
+	cleared := true
+	for i := range orders {
+		if orders[i].Status == store.DCAOrderOpen || orders[i].Status == store.DCAOrderPending {
+			cleared = at.cancelDCAOrder(&orders[i]) && cleared
+		}
+	}
+	return cleared
+}
+
+// syncDCADeal detects safety and take-profit fills of the active deal
+// A safety fill re-averages the deal and moves the take-profit; the take-profit fill completes the deal
+func (at *AutoTrader) syncDCADeal() error {
+	deal := at.dcaDeal
+	config := at.dcaConfig()
+
+	orders, err := at.store.DCA().GetDealOrders(deal.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load dca orders: %w", err)
+	}
+	openOrders, err := at.trader.GetOpenOrders(config.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get open orders: %w", err)
+	}
+	active := make(map[string]bool, len(openOrders))
+	for _, order := range openOrders {
+		active[order.OrderID] = true
+	}
+	exchangeQty, _, posErr := at.dcaExchangePosition()
+
+	var takeProfit, pendingTakeProfit *store.DCAOrder
+	safetyFilled := false
+	for i := range orders {
+		o := &orders[i]
+		switch {
+		case o.Status == store.DCAOrderPending && o.Kind == store.DCAOrderSafety:
+			at.placeDCAOrder(o)
+		case o.Status == store.DCAOrderPending && o.Kind == store.DCAOrderTakeProfit:
+			pendingTakeProfit = o
+		case o.Status != store.DCAOrderOpen:
+		case o.Kind == store.DCAOrderTakeProfit:
+			takeProfit = o
+		case !active[o.OrderID]:
+			// A vanished safety order filled if the exchange says so, or if the position grew past the deal
+			filled, known := at.dcaOrderOutcome(o)
+			if !known && posErr == nil {
+				filled, known = exchangeQty > deal.Quantity+o.Quantity/2, true
+			}
+			if !known {
+				continue
+			}
+			if !filled {
+				o.Status = store.DCAOrderCancelled
+				at.store.DCA().SaveOrder(o)
+				continue
+			}
+			at.fillDCASafetyOrder(o)
+			safetyFilled = true
+		}
+	}
+
+	if takeProfit != nil && !active[takeProfit.OrderID] {
+		filled, known := at.dcaOrderOutcome(takeProfit)
+		if !known && posErr == nil {
+			filled, known = exchangeQty < deal.Quantity/2, true
+		}
+		if known && filled {
+			at.completeDCADeal(takeProfit, orders)
+			return nil
+		}
+		if known {
+			takeProfit.Status = store.DCAOrderCancelled
+			at.store.DCA().SaveOrder(takeProfit)
+			safetyFilled = true // Re-arm the missing take-profit below
+		}
+	}
+
+	// Position closed outside the bot (manual close, liquidation): stop the deal
+	if !safetyFilled && posErr == nil && exchangeQty == 0 {
+		at.abandonDCADeal(orders)
+		return nil
+	}
+
+	switch {
+	case safetyFilled:
+		if posErr != nil {
+			exchangeQty = 0
+		}
+		at.replaceDCATakeProfit(exchangeQty)
+	case takeProfit != nil && takeProfit.Index != deal.SafetyOrdersFilled:
+		// The take-profit still targets an older average: a previous replace couldn't cancel it
+		if posErr != nil {
+			exchangeQty = 0
+		}
+		at.replaceDCATakeProfit(exchangeQty)
+	case pendingTakeProfit != nil:
+		// Placing the take-profit failed last time
+		at.placeDCATakeProfit(pendingTakeProfit)
+	}
+	return nil
+}
+
+// dcaOrderOutcome asks the exchange how a vanished order ended
+// Returns (filled, known); unknown statuses are left to the position check
+func (at *AutoTrader) dcaOrderOutcome(o *store.DCAOrder) (bool, bool) {
+	status, err := at.trader.GetOrderStatus(at.dcaConfig().Symbol, o.OrderID)
+	if err != nil {
+		return false, false
+	}
+	statusStr, _ := status["status"].(string)
+	switch strings.ToUpper(statusStr) {
+	case "FILLED":
+		return true, true
+	case "CANCELED", "CANCELLED", "EXPIRED", "REJECTED":
+		return false, true
+	}
+	return false, false
+}
+
+// fillDCASafetyOrder averages a filled safety order into the deal
+func (at *AutoTrader) fillDCASafetyOrder(o *store.DCAOrder) {
+	deal := at.dcaDeal
+	o.Status = store.DCAOrderFilled
+	o.FilledAt = time.Now().UTC().UnixMilli()
+	at.store.DCA().SaveOrder(o)
+
+	deal.Cost += o.Price * o.Quantity
+	deal.Quantity += o.Quantity
+	deal.AvgEntryPrice = deal.Cost / deal.Quantity
+	deal.SafetyOrdersFilled++
+	logger.Infof("[DCA] Deal #%d safety order %d filled @ %.6f, avg entry now %.6f (qty %.6f)",
+		deal.ID, o.Index, o.Price, deal.AvgEntryPrice, deal.Quantity)
+}
+
+// completeDCADeal closes the deal at its take-profit and cancels the safety orders still resting
+func (at *AutoTrader) completeDCADeal(tp *store.DCAOrder, orders []store.DCAOrder) {
+	deal := at.dcaDeal
+	nowMs := time.Now().UTC().UnixMilli()
+	tp.Status = store.DCAOrderFilled
+	tp.FilledAt = nowMs
+	at.store.DCA().SaveOrder(tp)
+
+	for i := range orders {
+		if orders[i].Kind == store.DCAOrderSafety && (orders[i].Status == store.DCAOrderOpen || orders[i].Status == store.DCAOrderPending) {
+			at.cancelDCAOrder(&orders[i])
+		}
+	}
+
+	action := "close_long"
+	if deal.Side == "SHORT" {
+		action = "close_short"
+	}
+	if err := at.store.Position().SetExitReason(at.id, tp.OrderID, dcaCloseReasonTakeProfit); err != nil {
+		logger.Warnf("[DCA] Failed to label take-profit exit: %v", err)
+	}
+
+	deal.Status = store.DCADealCompleted
+	deal.ExitPrice = tp.Price
+	deal.RealizedPnL = DCADealPnL(deal.Side, deal.AvgEntryPrice, tp.Price, tp.Quantity)
+	deal.ClosedAt = nowMs
+	if err := at.store.DCA().SaveDeal(deal); err != nil {
+		logger.Warnf("[DCA] Failed to save deal #%d: %v", deal.ID, err)
+	}
+	at.dcaDeal = nil
+
+	logger.Infof("[DCA] Deal #%d completed @ %.6f after %d safety orders, PnL %.2f USDT",
+		deal.ID, tp.Price, deal.SafetyOrdersFilled, deal.RealizedPnL)
+	at.saveStrategyDecisionRecord(&kernel.FullDecision{Decisions: []kernel.Decision{{
+		Symbol:    deal.Symbol,
+		Action:    action,
+		Price:     tp.Price,
+		Quantity:  tp.Quantity,
+		Reasoning: fmt.Sprintf("DCA deal #%d take-profit filled", deal.ID),
+	}}}, fmt.Sprintf("Deal #%d completed, PnL %.2f USDT", deal.ID, deal.RealizedPnL))
+}
+
+// abandonDCADeal cancels a deal whose position is gone from the exchange
+func (at *AutoTrader) abandonDCADeal(orders []store.DCAOrder) {
+	deal := at.dcaDeal
+	for i := range orders {
+		if orders[i].Status == store.DCAOrderOpen || orders[i].Status == store.DCAOrderPending {
+			at.cancelDCAOrder(&orders[i])
+		}
+	}
+
+	exitPrice, _ := at.trader.GetMarketPrice(deal.Symbol)
+	deal.Status = store.DCADealCancelled
+	deal.ExitPrice = exitPrice
+	if exitPrice > 0 {
+		deal.RealizedPnL = DCADealPnL(deal.Side, deal.AvgEntryPrice, exitPrice, deal.Quantity)
+	}
+	deal.ClosedAt = time.Now().UTC().UnixMilli()
+	if err := at.store.DCA().SaveDeal(deal); err != nil {
+		logger.Warnf("[DCA] Failed to save deal #%d: %v", deal.ID, err)
+	}
+	at.dcaDeal = nil
+	logger.Warnf("[DCA] Deal #%d position closed outside the bot, deal cancelled", deal.ID)
+}
+
+// dcaExchangePosition returns the exchange's size and entry price for the deal's symbol and side
+func (at *AutoTrader) dcaExchangePosition() (float64, float64, error) {
+	config := at.dcaConfig()
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return 0, 0, err
+	}
+	want := strings.ToLower(dcaSide(config))
+	for _, pos := range positions {
+		sym, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		if sym != config.Symbol || (side != "" && strings.ToLower(side) != want) {
+			continue
+		}
+		size, _ := pos["positionAmt"].(float64)
+		entry, _ := pos["entryPrice"].(float64)
+		return math.Abs(size), entry, nil
+	}
+	return 0, 0, nil
+}
+
+// saveStrategyDecisionRecord logs a mechanical strategy cycle (DCA, funding arbitrage) that changed positions
+// saveDecision advances the cycle and skips persisting when there is no store
+func (at *AutoTrader) saveStrategyDecisionRecord(decision *kernel.FullDecision, summary string) {
+	record := &store.DecisionRecord{
+		Timestamp:           time.Now().UTC(),
+		SystemPrompt:        decision.SystemPrompt,
+		InputPrompt:         decision.UserPrompt,
+		CoTTrace:            decision.CoTTrace,
+		RawResponse:         decision.RawResponse,
+		AIRequestDurationMs: decision.AIRequestDurationMs,
+		Success:             true,
+		ExecutionLog:        []string{summary},
+	}
+	if len(decision.Decisions) > 0 {
+		decisionJSON, _ := json.MarshalIndent(decision.Decisions, "", "  ")
+		record.DecisionJSON = string(decisionJSON)
+		for _, d := range decision.Decisions {
+			record.Decisions = append(record.Decisions, store.DecisionAction{
+				Action:     d.Action,
+				Symbol:     d.Symbol,
+				Quantity:   d.Quantity,
+				Price:      d.Price,
+				Confidence: d.Confidence,
+				Reasoning:  d.Reasoning,
+				Timestamp:  time.Now().UTC(),
+				Success:    true,
+			})
+		}
+	}
+
+	if err := at.saveDecision(record); err != nil {
+		logger.Warnf("Failed to save decision record: %v", err)
+	}
+}
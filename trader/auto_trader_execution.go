@@ -0,0 +1,509 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// errAlgoStopped an algo order was interrupted because the trader is stopping
+var errAlgoStopped = errors.New("execution stopped: trader is shutting down")
+
+// povSampleInterval how often a POV order samples traded volume and sends a child order
+const povSampleInterval = 15 * time.Second
+
+// algoFillPollInterval how long to wait between order status checks after sending a market child order
+var algoFillPollInterval = 500 * time.Millisecond
+
+// algoParentOrderTypes maps an execution algorithm to its parent order type in OrderStore
+var algoParentOrderTypes = map[string]string{
+	ExecutionTWAP:    store.OrderTypeTWAP,
+	ExecutionIceberg: store.OrderTypeIceberg,
+	ExecutionPOV:     store.OrderTypePOV,
+}
+
+// algoWork a parent order being worked by an execution algorithm
+type algoWork struct {
+	parent   *store.TraderOrder
+	algo     string
+	action   string
+	symbol   string
+	price    float64 // Reference price when the order started
+	leverage int
+	spec     *ContractSpec
+	children int
+	onFill   algoFillFunc
+}
+
+// algoFillFunc is called with the parent's cumulative fill after every child fill, and once more with done set
+// when the parent finishes (filled, stopped or failed)
+type algoFillFunc func(filled, avgPrice float64, done bool)
+
+// isClose reports whether the parent reduces a position
+func (w *algoWork) isClose() bool {
+	return w.action == "close_long" || w.action == "close_short"
+}
+
+// remaining returns the quantity still to fill, on the lot step
+func (w *algoWork) remaining() float64 {
+	rest := w.parent.Quantity - w.parent.FilledQuantity
+	if rest <= 1e-9 {
+		return 0 // Float residue, not an order (nil specs don't floor it away)
+	}
+	return w.spec.FloorQty(rest + 1e-12)
+}
+
+// executionConfig returns the strategy's execution algorithm settings (nil = every order at market)
+func (at *AutoTrader) executionConfig() *store.ExecutionConfig {
+	if at.config.StrategyConfig == nil {
+		return nil
+	}
+	return at.config.StrategyConfig.Execution
+}
+
+// executeOrder submits a decision's order, slicing it with an execution algorithm when the order is large enough
+// quantity is the full order size (for closes, the position size); price is the reference price used for notional
+// Market orders return the exchange response. Algo orders are worked in the background and return a summary of
+// their parent order straight away; onFill then reports each child fill so the caller can protect the position
+func (at *AutoTrader) executeOrder(decision *kernel.Decision, action string, quantity, price float64, leverage int, clientOrderID string, onFill algoFillFunc) (map[string]interface{}, error) {
+	if parentID, working := at.workingAlgoOrder(decision.Symbol); working {
+		return nil, fmt.Errorf("an execution algorithm is still working %s (parent order #%d)", decision.Symbol, parentID)
+	}
+	isClose := action == "close_long" || action == "close_short"
+	algo := SelectExecutionAlgo(at.executionConfig(), decision.Execution, quantity*price)
+	if algo == ExecutionMarket || quantity <= 0 || price <= 0 || at.store == nil {
+		if isClose {
+			quantity, leverage = 0, 0 // 0 = close all
+		}
+		return at.submitMarketOrder(action, decision.Symbol, quantity, leverage, clientOrderID)
+	}
+	if _, ok := at.trader.(GridTrader); !ok && algo == ExecutionIceberg {
+		logger.Infof("  ⚠️ %s has no native limit orders, working iceberg order as TWAP", at.exchange)
+		algo = ExecutionTWAP
+	}
+	return at.startAlgoOrder(algo, action, decision.Symbol, quantity, price, leverage, clientOrderID, onFill)
+}
+
+// isAlgoOrder reports whether an executeOrder result is an algo parent order rather than an exchange order
+func isAlgoOrder(order map[string]interface{}) bool {
+	_, isAlgo := order["parentOrderId"]
+	return isAlgo
+}
+
+// workingAlgoOrder returns the parent order ID of the algo order still working symbol, if any
+func (at *AutoTrader) workingAlgoOrder(symbol string) (int64, bool) {
+	at.algoOrdersMutex.Lock()
+	defer at.algoOrdersMutex.Unlock()
+	parentID, working := at.algoOrders[symbol]
+	return parentID, working
+}
+
+// startAlgoOrder records the parent order and works it with algo in the background until it fills,
+// the window ends or the trader stops
+// The parent is keyed by the decision's client order ID, so a retried cycle resumes it instead of starting over
+func (at *AutoTrader) startAlgoOrder(algo, action, symbol string, quantity, price float64, leverage int, clientOrderID string, onFill algoFillFunc) (map[string]interface{}, error) {
+	orders := at.store.Order()
+	parentOrderID := "algo-" + clientOrderID
+	parent, err := orders.GetOrderByExchangeID(at.exchangeID, parentOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		positionSide := "LONG"
+		if strings.HasSuffix(action, "_short") {
+			positionSide = "SHORT"
+		}
+		parent = at.createOrderRecord(parentOrderID, symbol, action, positionSide, quantity, price, leverage)
+		parent.Type = algoParentOrderTypes[algo]
+		parent.ClientOrderID = clientOrderID
+		if err := orders.CreateOrder(parent); err != nil {
+			return nil, fmt.Errorf("failed to record %s parent order: %w", algo, err)
+		}
+	} else if parent.Status == "FILLED" || parent.Status == "CANCELED" {
+		logger.Infof("  ♻️ %s parent order #%d already finished (%s), not working it again", algo, parent.ID, parent.Status)
+		if onFill != nil && parent.FilledQuantity > 0 {
+			onFill(parent.FilledQuantity, parent.AvgFillPrice, true)
+		}
+		return algoOrderResult(parent, algo), nil
+	}
+
+	w := &algoWork{parent: parent, algo: algo, action: action, symbol: symbol, price: price, leverage: leverage,
+		spec: at.contractSpec(symbol), onFill: onFill}
+	if children, err := orders.GetChildOrders(parent.ID); err == nil {
+		w.children = len(children)
+	}
+
+	at.algoOrdersMutex.Lock()
+	at.algoOrders[symbol] = parent.ID
+	at.algoOrdersMutex.Unlock()
+	window := ExecutionWindow(at.executionConfig(), at.config.ScanInterval)
+	logger.Infof("  🧩 Working %s %.6f %s via %s over %v (parent order #%d)", action, w.remaining(), symbol, algo, window, parent.ID)
+
+	result := algoOrderResult(parent, algo) // Before the worker starts updating the parent
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+		defer func() {
+			at.algoOrdersMutex.Lock()
+			delete(at.algoOrders, symbol)
+			at.algoOrdersMutex.Unlock()
+		}()
+		at.workAlgoOrder(w, time.Now().Add(window))
+	}()
+	return result, nil
+}
+
+// workAlgoOrder runs the algorithm, sends whatever it left unfilled at market and settles the parent order
+func (at *AutoTrader) workAlgoOrder(w *algoWork, deadline time.Time) {
+	var err error
+	switch w.algo {
+	case ExecutionTWAP:
+		err = at.workTWAP(w, deadline)
+	case ExecutionIceberg:
+		err = at.workIceberg(w, deadline)
+	case ExecutionPOV:
+		err = at.workPOV(w, deadline)
+	}
+
+	// Whatever the algo didn't fill by the deadline goes out at market so the decision completes
+	if err == nil {
+		if rest := w.remaining(); rest > 0 && (w.isClose() || w.spec.CheckOrder(rest, w.price) == nil) {
+			err = at.algoMarketSlice(w, rest)
+		}
+	}
+
+	parent := w.parent
+	parent.Status = "FILLED"
+	if err != nil {
+		parent.Status = "CANCELED"
+		logger.Warnf("  ⚠️ %s order #%d stopped after filling %.6f of %.6f: %v", w.algo, parent.ID, parent.FilledQuantity, parent.Quantity, err)
+	} else if rest := w.remaining(); rest > 0 {
+		// Below the exchange minimum, so it can't be sent on its own
+		parent.Status = "PARTIALLY_FILLED"
+		logger.Warnf("  ⚠️ %s order #%d left %.6f %s unfilled: below the exchange minimum", w.algo, parent.ID, rest, w.symbol)
+	}
+	if uerr := at.store.Order().UpdateParentOrder(parent); uerr != nil {
+		logger.Warnf("  ⚠️ Failed to update %s parent order #%d: %v", w.algo, parent.ID, uerr)
+	}
+	logger.Infof("  ✓ %s order #%d done: %.6f @ avg %.6f in %d child orders", w.algo, parent.ID, parent.FilledQuantity, parent.AvgFillPrice, w.children)
+	if w.onFill != nil {
+		w.onFill(parent.FilledQuantity, parent.AvgFillPrice, true)
+	}
+}
+
+// protectAlgoOpen sizes the stop-loss and take-profit of a position an algo order is still building to what has filled so far
+// A take-profit ladder and trailing stop need the final size, so they are placed once when the parent finishes
+func (at *AutoTrader) protectAlgoOpen(decision *kernel.Decision, side string, filled, avgPrice float64, done bool) {
+	if filled <= 0 {
+		return
+	}
+	if err := at.trader.CancelStopLossOrders(decision.Symbol); err != nil {
+		logger.Infof("  ⚠ Failed to cancel stop loss before resizing: %v", err)
+	}
+	if err := at.trader.SetStopLoss(decision.Symbol, side, filled, decision.StopLoss); err != nil {
+		logger.Infof("  ⚠ Failed to set stop loss: %v", err)
+	}
+	if !done && len(decision.TakeProfitLevels) > 0 {
+		return
+	}
+	if err := at.trader.CancelTakeProfitOrders(decision.Symbol); err != nil {
+		logger.Infof("  ⚠ Failed to cancel take profit before resizing: %v", err)
+	}
+	if !done {
+		if err := at.trader.SetTakeProfit(decision.Symbol, side, filled, decision.TakeProfit); err != nil {
+			logger.Infof("  ⚠ Failed to set take profit: %v", err)
+		}
+		return
+	}
+	at.placeTakeProfitOrders(decision, side, filled, avgPrice)
+}
+
+// algoOrderResult summarises a parent order in the shape of an exchange order response
+func algoOrderResult(parent *store.TraderOrder, algo string) map[string]interface{} {
+	return map[string]interface{}{
+		"orderId":       parent.ExchangeOrderID,
+		"clientOrderId": parent.ClientOrderID,
+		"parentOrderId": parent.ID,
+		"algo":          algo,
+		"status":        parent.Status,
+		"executedQty":   parent.FilledQuantity,
+		"avgPrice":      parent.AvgFillPrice,
+	}
+}
+
+// workTWAP sends equal market slices spaced evenly across the window
+func (at *AutoTrader) workTWAP(w *algoWork, deadline time.Time) error {
+	n := defaultTWAPSlices
+	if config := at.executionConfig(); config != nil && config.Slices > 0 {
+		n = config.Slices
+	}
+	slices := SliceQuantity(w.remaining(), n, w.spec, w.price)
+	spacing := time.Until(deadline) / time.Duration(len(slices))
+	for i, qty := range slices {
+		if i > 0 && !at.algoWait(spacing) {
+			return errAlgoStopped
+		}
+		if qty <= 0 {
+			continue
+		}
+		if err := at.algoMarketSlice(w, qty); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workIceberg rests one visible clip at a time at the touch, re-pricing any clip that doesn't fill in its share of the window
+func (at *AutoTrader) workIceberg(w *algoWork, deadline time.Time) error {
+	gridTrader := at.trader.(GridTrader)
+
+	clip := w.spec.FloorQty(w.parent.Quantity / defaultIcebergClips)
+	if config := at.executionConfig(); config != nil && config.ClipUSD > 0 {
+		clip = w.spec.FloorQty(config.ClipUSD / w.price)
+	}
+	if w.spec.CheckOrder(clip, w.price) != nil {
+		clip = w.remaining()
+	}
+	clipWait := time.Duration(float64(time.Until(deadline)) * clip / math.Max(w.remaining(), clip))
+	if clipWait < minExecutionSliceSpacing {
+		clipWait = minExecutionSliceSpacing
+	}
+
+	side := "BUY"
+	if w.action == "open_short" || w.action == "close_long" {
+		side = "SELL"
+	}
+	positionSide := "LONG"
+	if strings.HasSuffix(w.action, "_short") {
+		positionSide = "SHORT"
+	}
+
+	for w.remaining() > 0 && time.Now().Before(deadline) {
+		qty := math.Min(clip, w.remaining())
+		if !w.isClose() && w.spec.CheckOrder(qty, w.price) != nil {
+			return nil // Dust left over from partial clips; the final market slice picks it up if it can
+		}
+
+		// Passive at the touch: buys join the best bid, sells the best ask
+		bids, asks, err := gridTrader.GetOrderBook(w.symbol, 5)
+		if err != nil || len(bids) == 0 || len(asks) == 0 {
+			logger.Infof("  ⚠️ No order book for %s, sending iceberg clip at market", w.symbol)
+			if err := at.algoMarketSlice(w, qty); err != nil {
+				return err
+			}
+			continue
+		}
+		touch := bids[0][0]
+		if side == "SELL" {
+			touch = asks[0][0]
+		}
+
+		w.children++
+		clientID := BuildClientOrderID(at.id, int(w.parent.ID), w.symbol, fmt.Sprintf("%s:%d", w.algo, w.children))
+		result, err := at.placeGridLimitOrderIdempotent(gridTrader, &LimitOrderRequest{
+			Symbol:       w.symbol,
+			Side:         side,
+			PositionSide: positionSide,
+			Price:        w.spec.RoundPrice(touch),
+			Quantity:     qty,
+			Leverage:     w.leverage,
+			ReduceOnly:   w.isClose(),
+			ClientID:     clientID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to place iceberg clip: %w", err)
+		}
+
+		wait := clipWait
+		if left := time.Until(deadline); left < wait {
+			wait = left
+		}
+		filled, avgPrice, stopped := at.workLimitClip(gridTrader, w.symbol, result.OrderID, wait)
+		status := "FILLED"
+		if filled < qty {
+			status = "CANCELED"
+		}
+		at.recordAlgoChild(w, result.OrderID, clientID, "LIMIT", status, qty, filled, avgPrice)
+		if stopped {
+			return errAlgoStopped
+		}
+	}
+	return nil
+}
+
+// workLimitClip waits for a resting clip to fill, cancelling it when wait runs out
+// Returns the filled quantity, its average price, and whether the trader is stopping
+func (at *AutoTrader) workLimitClip(gridTrader GridTrader, symbol, orderID string, wait time.Duration) (float64, float64, bool) {
+	deadline := time.Now().Add(wait)
+	stopped := false
+	for time.Now().Before(deadline) {
+		if !at.algoWait(2 * time.Second) {
+			stopped = true
+			break
+		}
+		if status, err := at.trader.GetOrderStatus(symbol, orderID); err == nil {
+			if s, _ := status["status"].(string); s == "FILLED" {
+				filled, avgPrice, _ := orderStatusFill(status)
+				return filled, avgPrice, false
+			}
+		}
+	}
+
+	if err := gridTrader.CancelOrder(symbol, orderID); err != nil {
+		logger.Infof("  ⚠️ Failed to cancel iceberg clip %s: %v", orderID, err)
+	}
+	status, err := at.trader.GetOrderStatus(symbol, orderID)
+	if err != nil {
+		return 0, 0, stopped
+	}
+	filled, avgPrice, _ := orderStatusFill(status)
+	return filled, avgPrice, stopped
+}
+
+// workPOV sends child orders sized to a share of the volume traded since the last sample
+func (at *AutoTrader) workPOV(w *algoWork, deadline time.Time) error {
+	pct := defaultParticipationPct
+	if config := at.executionConfig(); config != nil && config.ParticipationPct > 0 {
+		pct = config.ParticipationPct
+	}
+	for w.remaining() > 0 && time.Now().Add(povSampleInterval).Before(deadline) {
+		if !at.algoWait(povSampleInterval) {
+			return errAlgoStopped
+		}
+		qty := POVChildQuantity(pct, recentTradedVolume(w.symbol, povSampleInterval), w.remaining(), w.spec)
+		if qty <= 0 || (qty < w.remaining() && w.spec.CheckOrder(qty, w.price) != nil) {
+			continue
+		}
+		if err := at.algoMarketSlice(w, qty); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recentTradedVolume estimates the base-asset volume traded over interval from the last closed 1m candle
+func recentTradedVolume(symbol string, interval time.Duration) float64 {
+	now := time.Now()
+	klines, err := market.GetKlinesRange(symbol, "1m", now.Add(-3*time.Minute), now)
+	if err != nil {
+		return 0
+	}
+	for i := len(klines) - 1; i >= 0; i-- {
+		if klines[i].CloseTime <= now.UnixMilli() {
+			return klines[i].Volume * interval.Minutes()
+		}
+	}
+	return 0
+}
+
+// algoMarketSlice sends one market child order; a close slice covering the rest closes the whole position so no dust remains
+func (at *AutoTrader) algoMarketSlice(w *algoWork, qty float64) error {
+	w.children++
+	clientID := BuildClientOrderID(at.id, int(w.parent.ID), w.symbol, fmt.Sprintf("%s:%d", w.algo, w.children))
+	submitQty, leverage := qty, w.leverage
+	if w.isClose() && qty >= w.remaining() {
+		submitQty, leverage = 0, 0 // 0 = close all
+	}
+	order, err := at.submitMarketOrder(w.action, w.symbol, submitQty, leverage, clientID)
+	if err != nil {
+		return fmt.Errorf("%s child order %d failed: %w", w.algo, w.children, err)
+	}
+
+	// Only a confirmed fill counts; an order whose status can't be read is left out of the parent's progress
+	// and stops the algo, so the remaining slices can't overfill if it did execute
+	orderID := fmt.Sprintf("%v", order["orderId"])
+	status, filled, avgPrice := "NEW", 0.0, 0.0
+	for i := 0; i < 5; i++ {
+		time.Sleep(algoFillPollInterval)
+		if resp, err := at.trader.GetOrderStatus(w.symbol, orderID); err == nil {
+			if s, _ := resp["status"].(string); s == "FILLED" || s == "PARTIALLY_FILLED" || s == "CANCELED" || s == "EXPIRED" {
+				status = s
+				filled, avgPrice, _ = orderStatusFill(resp)
+				if s == "FILLED" && filled <= 0 {
+					filled = qty // Some exchanges report FILLED without the executed quantity
+				}
+				break
+			}
+		}
+	}
+	if filled > 0 && avgPrice <= 0 {
+		avgPrice, _ = at.trader.GetMarketPrice(w.symbol)
+	}
+	at.recordAlgoChild(w, orderID, clientID, "MARKET", status, qty, filled, avgPrice)
+	if status == "NEW" {
+		return fmt.Errorf("%s child order %d (%s) fill not confirmed", w.algo, w.children, orderID)
+	}
+	return nil
+}
+
+// recordAlgoChild stores a child order under its parent and rolls its fill into the parent's progress
+func (at *AutoTrader) recordAlgoChild(w *algoWork, orderID, clientID, orderType, status string, qty, filled, avgPrice float64) {
+	if orderID == "" || orderID == "<nil>" {
+		orderID = clientID
+	}
+	child := at.createOrderRecord(orderID, w.symbol, w.action, w.parent.PositionSide, qty, avgPrice, w.leverage)
+	child.Type = orderType
+	child.ParentOrderID = w.parent.ID
+	child.ClientOrderID = clientID
+	child.Status = status
+	child.FilledQuantity = filled
+	child.AvgFillPrice = avgPrice
+	if filled > 0 {
+		child.FilledAt = time.Now().UTC().UnixMilli()
+	}
+	if err := at.store.Order().CreateOrder(child); err != nil {
+		logger.Warnf("  ⚠️ Failed to record %s child order: %v", w.algo, err)
+	}
+	if filled <= 0 {
+		return
+	}
+
+	parent := w.parent
+	cost := parent.AvgFillPrice*parent.FilledQuantity + avgPrice*filled
+	parent.FilledQuantity += filled
+	parent.AvgFillPrice = cost / parent.FilledQuantity
+	parent.Status = "PARTIALLY_FILLED"
+	if err := at.store.Order().UpdateParentOrder(parent); err != nil {
+		logger.Warnf("  ⚠️ Failed to update %s parent order #%d: %v", w.algo, parent.ID, err)
+	}
+	logger.Infof("  🧩 %s child %d: %.6f @ %.6f (%.6f/%.6f filled)", w.algo, w.children, filled, avgPrice, parent.FilledQuantity, parent.Quantity)
+	if w.onFill != nil {
+		w.onFill(parent.FilledQuantity, parent.AvgFillPrice, false)
+	}
+
+	// OrderSync exchanges pick the child trades up from their trade history
+	if !exchangeHasOrderSync(at.exchange) {
+		posBuilder := store.NewPositionBuilder(at.store.Position())
+		if err := posBuilder.ProcessTrade(
+			at.id, at.exchangeID, at.exchange,
+			market.Normalize(w.symbol), parent.PositionSide, w.action,
+			filled, avgPrice, 0, 0,
+			child.FilledAt, orderID, orderID,
+		); err != nil {
+			logger.Warnf("  ⚠️ Failed to record %s child fill: %v", w.algo, err)
+		}
+	}
+}
+
+// algoWait sleeps for d, returning false early if the trader is stopping
+func (at *AutoTrader) algoWait(d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-at.stopMonitorCh:
+		return false
+	}
+}
+
+// orderStatusFill extracts the executed quantity and average price from a GetOrderStatus response
+func orderStatusFill(status map[string]interface{}) (float64, float64, bool) {
+	qty, _ := status["executedQty"].(float64)
+	price, _ := status["avgPrice"].(float64)
+	return qty, price, qty > 0
+}
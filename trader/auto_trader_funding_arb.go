@@ -0,0 +1,450 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fundingArbVenue one exchange account the funding arbitrage strategy can put a leg on
+type fundingArbVenue struct {
+	exchangeID string
+	exchange   string
+	trader     Trader
+}
+
+// fundingArbLegState a leg's position as reported by its exchange
+type fundingArbLegState struct {
+	Quantity         float64
+	EntryPrice       float64
+	MarkPrice        float64
+	LiquidationPrice float64
+}
+
+// newFundingArbVenues creates a trader per configured arbitrage venue; the primary account reuses primary
+func newFundingArbVenues(config AutoTraderConfig, primary Trader, userID string) (map[string]*fundingArbVenue, error) {
+	venues := map[string]*fundingArbVenue{
+		config.ExchangeID: {exchangeID: config.ExchangeID, exchange: config.Exchange, trader: primary},
+	}
+	for _, venueConfig := range config.ArbVenues {
+		if _, exists := venues[venueConfig.ExchangeID]; exists {
+			continue
+		}
+		venueConfig.Name = config.Name
+		t, err := newExchangeTrader(venueConfig, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create funding arbitrage venue %s: %w", venueConfig.Exchange, err)
+		}
+		venues[venueConfig.ExchangeID] = &fundingArbVenue{exchangeID: venueConfig.ExchangeID, exchange: venueConfig.Exchange, trader: t}
+	}
+	return venues, nil
+}
+
+// IsFundingArbStrategy returns true if current strategy is cross-exchange funding arbitrage
+func (at *AutoTrader) IsFundingArbStrategy() bool {
+	if at.config.StrategyConfig == nil {
+		return false
+	}
+	return at.config.StrategyConfig.StrategyType == "funding_arb" && at.config.StrategyConfig.FundingArbConfig != nil
+}
+
+// fundingArbConfig returns the funding arbitrage configuration
+func (at *AutoTrader) fundingArbConfig() *store.FundingArbStrategyConfig {
+	return at.config.StrategyConfig.FundingArbConfig
+}
+
+// InitializeFundingArb validates the config and reports the pairs left open by a previous run
+func (at *AutoTrader) InitializeFundingArb() error {
+	config := at.fundingArbConfig()
+	if config == nil {
+		return fmt.Errorf("funding arbitrage configuration not found")
+	}
+	if at.store == nil {
+		return fmt.Errorf("funding arbitrage requires a store to persist position pairs")
+	}
+	if len(config.Symbols) == 0 || config.NotionalUSD <= 0 {
+		return fmt.Errorf("funding arbitrage configuration needs symbols and notional_usd")
+	}
+	if len(at.arbVenues) < 2 {
+		return fmt.Errorf("funding arbitrage needs at least two exchange accounts, got %d", len(at.arbVenues))
+	}
+	if config.Leverage <= 0 {
+		config.Leverage = 1
+	}
+	if config.Leverage > 10 {
+		return fmt.Errorf("leverage %d exceeds limit 10", config.Leverage)
+	}
+	if config.MaxPairs <= 0 {
+		config.MaxPairs = 1
+	}
+	for i, symbol := range config.Symbols {
+		config.Symbols[i] = market.Normalize(symbol)
+	}
+
+	pairs, err := at.store.FundingArb().GetOpenPairs(at.id)
+	if err != nil {
+		return fmt.Errorf("failed to load open funding arbitrage pairs: %w", err)
+	}
+	venueNames := make([]string, 0, len(at.arbVenues))
+	for _, v := range at.arbVenues {
+		venueNames = append(venueNames, v.exchange)
+	}
+	sort.Strings(venueNames)
+	logger.Infof("[FundingArb] Trading %v across %s", config.Symbols, strings.Join(venueNames, ", "))
+	for _, pair := range pairs {
+		logger.Infof("[FundingArb] Resuming %s pair #%d: %s long %s / short %s, est. funding %.4f USDT",
+			pair.Status, pair.ID, pair.Symbol, pair.LongExchange, pair.ShortExchange, pair.EstimatedFunding)
+		if at.arbVenues[pair.LongExchangeID] == nil || at.arbVenues[pair.ShortExchangeID] == nil {
+			logger.Warnf("[FundingArb] Pair #%d trades on an exchange account that is no longer configured, it will not be managed", pair.ID)
+		}
+	}
+	return nil
+}
+
+// RunFundingArbCycle runs one funding arbitrage cycle: monitor and unwind open pairs, retry half-open ones, then open new ones
+func (at *AutoTrader) RunFundingArbCycle() error {
+	config := at.fundingArbConfig()
+	pairs, err := at.store.FundingArb().GetOpenPairs(at.id)
+	if err != nil {
+		return fmt.Errorf("failed to load open funding arbitrage pairs: %w", err)
+	}
+
+	symbols := append([]string{}, config.Symbols...)
+	for _, pair := range pairs {
+		if pair.Status == store.FundingArbPairOpen {
+			symbols = append(symbols, pair.Symbol)
+		}
+	}
+	rates := at.fundingArbRates(symbols)
+
+	held := make(map[string]bool)
+	for _, pair := range pairs {
+		reason := pair.CloseReason
+		if pair.Status != store.FundingArbPairUnwinding {
+			reason = at.monitorFundingArbPair(pair, rates[pair.Symbol])
+		}
+		if reason == "" {
+			held[pair.Symbol] = true
+			continue
+		}
+		if err := at.closeFundingArbPair(pair, reason); err != nil {
+			logger.Errorf("[FundingArb] Failed to unwind pair #%d (%s): %v", pair.ID, reason, err)
+			held[pair.Symbol] = true
+		}
+	}
+
+	var candidates []*FundingArbCandidate
+	for _, symbol := range config.Symbols {
+		if c, ok := PickFundingArbPair(symbol, rates[symbol]); ok && !held[symbol] && c.SpreadAPR >= config.MinSpreadAPR {
+			candidates = append(candidates, c)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].SpreadAPR > candidates[j].SpreadAPR })
+
+	for _, c := range candidates {
+		if len(held) >= config.MaxPairs {
+			break
+		}
+		if err := at.openFundingArbPair(c); err != nil {
+			logger.Warnf("[FundingArb] Failed to open %s pair: %v", c.Symbol, err)
+			continue
+		}
+		held[c.Symbol] = true
+	}
+	return nil
+}
+
+// fundingArbRates fetches each symbol's funding rate on every venue, keyed by symbol then exchange account ID
+// Venues that don't list a symbol (or have no public funding endpoint) are left out
+func (at *AutoTrader) fundingArbRates(symbols []string) map[string]map[string]*market.VenueFundingRate {
+	rates := make(map[string]map[string]*market.VenueFundingRate)
+	for _, symbol := range symbols {
+		if rates[symbol] != nil {
+			continue
+		}
+		rates[symbol] = make(map[string]*market.VenueFundingRate)
+		for id, v := range at.arbVenues {
+			rate, err := market.GetVenueFundingRate(v.exchange, symbol)
+			if err != nil {
+				logger.Debugf("[FundingArb] No %s funding rate on %s: %v", symbol, v.exchange, err)
+				continue
+			}
+			rates[symbol][id] = rate
+		}
+	}
+	return rates
+}
+
+// openFundingArbPair opens matched long and short legs and stores them as one pair
+// If the second leg fails the first is closed again so the account is never left unhedged
+func (at *AutoTrader) openFundingArbPair(c *FundingArbCandidate) error {
+	config := at.fundingArbConfig()
+	long, short := at.arbVenues[c.LongVenueID], at.arbVenues[c.ShortVenueID]
+
+	longPrice, err := long.trader.GetMarketPrice(c.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get %s price: %w", long.exchange, err)
+	}
+	shortPrice, err := short.trader.GetMarketPrice(c.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get %s price: %w", short.exchange, err)
+	}
+
+	// Both legs trade the same quantity, so it has to fit both venues' lot steps
+	longSpec, _ := ContractSpecs().Get(long.trader, long.exchange, c.Symbol)
+	shortSpec, _ := ContractSpecs().Get(short.trader, short.exchange, c.Symbol)
+	quantity := config.NotionalUSD / ((longPrice + shortPrice) / 2)
+	for i := 0; i < 10; i++ {
+		floored := longSpec.FloorQty(shortSpec.FloorQty(quantity))
+		if floored == quantity {
+			break
+		}
+		quantity = floored
+	}
+	if err := longSpec.CheckOrder(quantity, longPrice); err != nil {
+		return fmt.Errorf("long leg below %s minimum: %w", long.exchange, err)
+	}
+	if err := shortSpec.CheckOrder(quantity, shortPrice); err != nil {
+		return fmt.Errorf("short leg below %s minimum: %w", short.exchange, err)
+	}
+
+	pair := &store.FundingArbPair{
+		TraderID:         at.id,
+		Symbol:           c.Symbol,
+		Status:           store.FundingArbPairOpen,
+		LongExchangeID:   long.exchangeID,
+		LongExchange:     long.exchange,
+		ShortExchangeID:  short.exchangeID,
+		ShortExchange:    short.exchange,
+		Leverage:         config.Leverage,
+		NotionalUSD:      config.NotionalUSD,
+		EntrySpreadAPR:   c.SpreadAPR,
+		CurrentSpreadAPR: c.SpreadAPR,
+	}
+	if err := at.store.FundingArb().Save(pair); err != nil {
+		return fmt.Errorf("failed to save funding arbitrage pair: %w", err)
+	}
+
+	for _, v := range []*fundingArbVenue{long, short} {
+		if err := v.trader.SetMarginMode(c.Symbol, at.config.IsCrossMargin); err != nil {
+			logger.Infof("  ⚠️ Failed to set margin mode on %s: %v", v.exchange, err)
+		}
+	}
+
+	// The pair ID stands in for the cycle so client IDs stay stable across restarts
+	longOrder, err := at.submitMarketOrderOn(long.trader, "open_long", c.Symbol, quantity, config.Leverage,
+		BuildClientOrderID(at.id, int(pair.ID), c.Symbol, "arb:long"))
+	if err != nil {
+		at.abortFundingArbPair(pair)
+		return fmt.Errorf("failed to open long leg on %s: %w", long.exchange, err)
+	}
+	shortOrder, err := at.submitMarketOrderOn(short.trader, "open_short", c.Symbol, quantity, config.Leverage,
+		BuildClientOrderID(at.id, int(pair.ID), c.Symbol, "arb:short"))
+	if err != nil {
+		if _, closeErr := at.submitMarketOrderOn(long.trader, "close_long", c.Symbol, quantity, 0,
+			BuildClientOrderID(at.id, int(pair.ID), c.Symbol, "arb:long:close")); closeErr != nil {
+			// Keep the pair so the next cycle retries closing the unhedged leg
+			logger.Errorf("[FundingArb] Long %s leg on %s is unhedged and could not be closed, retrying next cycle: %v", c.Symbol, long.exchange, closeErr)
+			pair.Status = store.FundingArbPairUnwinding
+			pair.CloseReason = store.FundingArbCloseOpenFailed
+			pair.LongQuantity, pair.LongEntryPrice = quantity, longPrice
+			pair.LongEntryOrderID = fmt.Sprintf("%v", longOrder["orderId"])
+			if err := at.store.FundingArb().Save(pair); err != nil {
+				logger.Warnf("[FundingArb] Failed to save pair #%d: %v", pair.ID, err)
+			}
+			return fmt.Errorf("failed to open short leg on %s: %w", short.exchange, err)
+		}
+		at.abortFundingArbPair(pair)
+		return fmt.Errorf("failed to open short leg on %s: %w", short.exchange, err)
+	}
+
+	pair.LongQuantity, pair.ShortQuantity = quantity, quantity
+	pair.LongEntryPrice, pair.ShortEntryPrice = longPrice, shortPrice
+	if leg, err := fundingArbLeg(long, c.Symbol, "long"); err == nil && leg.EntryPrice > 0 {
+		pair.LongEntryPrice = leg.EntryPrice
+	}
+	if leg, err := fundingArbLeg(short, c.Symbol, "short"); err == nil && leg.EntryPrice > 0 {
+		pair.ShortEntryPrice = leg.EntryPrice
+	}
+	pair.LongEntryOrderID = fmt.Sprintf("%v", longOrder["orderId"])
+	pair.ShortEntryOrderID = fmt.Sprintf("%v", shortOrder["orderId"])
+	if err := at.store.FundingArb().Save(pair); err != nil {
+		logger.Warnf("[FundingArb] Failed to save pair #%d: %v", pair.ID, err)
+	}
+
+	logger.Infof("[FundingArb] Pair #%d opened: %s long %.6f on %s @ %.6f / short on %s @ %.6f, spread %.2f%% APR",
+		pair.ID, c.Symbol, quantity, long.exchange, pair.LongEntryPrice, short.exchange, pair.ShortEntryPrice, c.SpreadAPR)
+	reasoning := fmt.Sprintf("Funding spread %.2f%% APR (long %s %.4f%%/h, short %s %.4f%%/h)",
+		c.SpreadAPR, long.exchange, c.LongRate.HourlyRate()*100, short.exchange, c.ShortRate.HourlyRate()*100)
+	at.saveStrategyDecisionRecord(&kernel.FullDecision{Decisions: []kernel.Decision{
+		{Symbol: c.Symbol, Action: "open_long", Leverage: config.Leverage, Price: pair.LongEntryPrice, Quantity: quantity, Reasoning: reasoning + ", long leg on " + long.exchange},
+		{Symbol: c.Symbol, Action: "open_short", Leverage: config.Leverage, Price: pair.ShortEntryPrice, Quantity: quantity, Reasoning: reasoning + ", short leg on " + short.exchange},
+	}}, fmt.Sprintf("Opened funding arbitrage pair #%d", pair.ID))
+	return nil
+}
+
+// abortFundingArbPair marks a pair that never got both legs on as closed
+// Only call it once no leg is left open on either venue
+func (at *AutoTrader) abortFundingArbPair(pair *store.FundingArbPair) {
+	pair.Status = store.FundingArbPairClosed
+	pair.CloseReason = store.FundingArbCloseOpenFailed
+	pair.ClosedAt = time.Now().UTC().UnixMilli()
+	if err := at.store.FundingArb().Save(pair); err != nil {
+		logger.Warnf("[FundingArb] Failed to save pair #%d: %v", pair.ID, err)
+	}
+}
+
+// monitorFundingArbPair accrues funding and refreshes drift and PnL for an open pair
+// Returns the close reason when the pair should be unwound, or "" to keep holding it
+func (at *AutoTrader) monitorFundingArbPair(pair *store.FundingArbPair, rates map[string]*market.VenueFundingRate) string {
+	config := at.fundingArbConfig()
+	long, short := at.arbVenues[pair.LongExchangeID], at.arbVenues[pair.ShortExchangeID]
+	if long == nil || short == nil {
+		return ""
+	}
+
+	longLeg, err := fundingArbLeg(long, pair.Symbol, "long")
+	if err != nil {
+		logger.Warnf("[FundingArb] Pair #%d: failed to get %s positions: %v", pair.ID, long.exchange, err)
+		return ""
+	}
+	shortLeg, err := fundingArbLeg(short, pair.Symbol, "short")
+	if err != nil {
+		logger.Warnf("[FundingArb] Pair #%d: failed to get %s positions: %v", pair.ID, short.exchange, err)
+		return ""
+	}
+	if longLeg.Quantity == 0 || shortLeg.Quantity == 0 {
+		return store.FundingArbCloseLegGone
+	}
+
+	longNotional := longLeg.Quantity * longLeg.MarkPrice
+	shortNotional := shortLeg.Quantity * shortLeg.MarkPrice
+	longRate, shortRate := rates[pair.LongExchangeID], rates[pair.ShortExchangeID]
+	if longRate != nil && shortRate != nil {
+		// Estimated from published rates; venues settle at different times, so this smooths between settlements
+		nowMs := time.Now().UTC().UnixMilli()
+		hours := float64(nowMs-pair.LastAccrualAt) / float64(time.Hour/time.Millisecond)
+		pair.EstimatedFunding += FundingArbAccrual(longNotional, shortNotional, longRate.HourlyRate(), shortRate.HourlyRate(), hours)
+		pair.LastAccrualAt = nowMs
+		pair.CurrentSpreadAPR = FundingSpreadAPR(longRate, shortRate)
+	}
+	pair.DeltaDriftPct = FundingArbDeltaDriftPct(longNotional, shortNotional)
+	pair.PricePnL = (longLeg.MarkPrice-pair.LongEntryPrice)*longLeg.Quantity + (pair.ShortEntryPrice-shortLeg.MarkPrice)*shortLeg.Quantity
+	if err := at.store.FundingArb().Save(pair); err != nil {
+		logger.Warnf("[FundingArb] Failed to save pair #%d: %v", pair.ID, err)
+	}
+
+	if config.MaxDeltaDriftPct > 0 && pair.DeltaDriftPct > config.MaxDeltaDriftPct {
+		return store.FundingArbCloseDrift
+	}
+	if config.MinLiquidationDistancePct > 0 {
+		for _, leg := range []fundingArbLegState{longLeg, shortLeg} {
+			if d := LiquidationDistancePct(leg.MarkPrice, leg.LiquidationPrice); d >= 0 && d < config.MinLiquidationDistancePct {
+				return store.FundingArbCloseMargin
+			}
+		}
+	}
+	if longRate != nil && shortRate != nil && pair.CurrentSpreadAPR < config.ExitSpreadAPR {
+		return store.FundingArbCloseSpread
+	}
+	return ""
+}
+
+// closeFundingArbPair closes both legs and settles the pair's PnL
+// A leg that fails to close leaves the pair open so the next cycle retries it
+func (at *AutoTrader) closeFundingArbPair(pair *store.FundingArbPair, reason string) error {
+	legs := []struct {
+		venue  *fundingArbVenue
+		side   string
+		action string
+		exit   *float64
+	}{
+		{at.arbVenues[pair.LongExchangeID], "long", "close_long", &pair.LongExitPrice},
+		{at.arbVenues[pair.ShortExchangeID], "short", "close_short", &pair.ShortExitPrice},
+	}
+
+	for _, leg := range legs {
+		if leg.venue == nil {
+			return fmt.Errorf("exchange account for the %s leg is no longer configured", leg.side)
+		}
+		state, err := fundingArbLeg(leg.venue, pair.Symbol, leg.side)
+		if err != nil {
+			return fmt.Errorf("failed to get %s positions: %w", leg.venue.exchange, err)
+		}
+		*leg.exit = state.MarkPrice
+		if state.Quantity == 0 {
+			*leg.exit, _ = leg.venue.trader.GetMarketPrice(pair.Symbol)
+			continue
+		}
+		order, err := at.submitMarketOrderOn(leg.venue.trader, leg.action, pair.Symbol, 0, 0, // 0 = close all
+			BuildClientOrderID(at.id, int(pair.ID), pair.Symbol, "arb:"+leg.side+":close"))
+		if err != nil {
+			return fmt.Errorf("failed to close %s leg on %s: %w", leg.side, leg.venue.exchange, err)
+		}
+		if leg.venue.exchangeID == at.exchangeID {
+			if err := at.store.Position().SetExitReason(at.id, orderResultID(order), "funding_arb_"+reason); err != nil {
+				logger.Warnf("[FundingArb] Failed to label %s leg exit: %v", leg.side, err)
+			}
+		}
+	}
+
+	pair.PricePnL = 0
+	if pair.LongExitPrice > 0 {
+		pair.PricePnL += (pair.LongExitPrice - pair.LongEntryPrice) * pair.LongQuantity
+	}
+	if pair.ShortExitPrice > 0 {
+		pair.PricePnL += (pair.ShortEntryPrice - pair.ShortExitPrice) * pair.ShortQuantity
+	}
+	pair.RealizedPnL = pair.PricePnL + pair.EstimatedFunding
+	pair.Status = store.FundingArbPairClosed
+	pair.CloseReason = reason
+	pair.ClosedAt = time.Now().UTC().UnixMilli()
+	if err := at.store.FundingArb().Save(pair); err != nil {
+		logger.Warnf("[FundingArb] Failed to save pair #%d: %v", pair.ID, err)
+	}
+
+	logger.Infof("[FundingArb] Pair #%d closed (%s): price PnL %.2f + est. funding %.2f = %.2f USDT",
+		pair.ID, reason, pair.PricePnL, pair.EstimatedFunding, pair.RealizedPnL)
+	reasoning := fmt.Sprintf("Unwound pair #%d: %s (spread %.2f%% APR, drift %.2f%%)", pair.ID, reason, pair.CurrentSpreadAPR, pair.DeltaDriftPct)
+	var decisions []kernel.Decision
+	if pair.LongQuantity > 0 {
+		decisions = append(decisions, kernel.Decision{Symbol: pair.Symbol, Action: "close_long", Price: pair.LongExitPrice, Quantity: pair.LongQuantity, Reasoning: reasoning + ", long leg on " + pair.LongExchange})
+	}
+	if pair.ShortQuantity > 0 {
+		decisions = append(decisions, kernel.Decision{Symbol: pair.Symbol, Action: "close_short", Price: pair.ShortExitPrice, Quantity: pair.ShortQuantity, Reasoning: reasoning + ", short leg on " + pair.ShortExchange})
+	}
+	at.saveStrategyDecisionRecord(&kernel.FullDecision{Decisions: decisions},
+		fmt.Sprintf("Closed funding arbitrage pair #%d, PnL %.2f USDT", pair.ID, pair.RealizedPnL))
+	return nil
+}
+
+// fundingArbLeg returns the venue's position for symbol on side; a zero Quantity means the leg is flat
+func fundingArbLeg(v *fundingArbVenue, symbol, side string) (fundingArbLegState, error) {
+	positions, err := v.trader.GetPositions()
+	if err != nil {
+		return fundingArbLegState{}, err
+	}
+	for _, pos := range positions {
+		sym, _ := pos["symbol"].(string)
+		posSide, _ := pos["side"].(string)
+		if sym != symbol || strings.ToLower(posSide) != side {
+			continue
+		}
+		size, _ := pos["positionAmt"].(float64)
+		state := fundingArbLegState{Quantity: math.Abs(size)}
+		state.EntryPrice, _ = pos["entryPrice"].(float64)
+		state.MarkPrice, _ = pos["markPrice"].(float64)
+		state.LiquidationPrice, _ = pos["liquidationPrice"].(float64)
+		if state.MarkPrice <= 0 {
+			state.MarkPrice, _ = v.trader.GetMarketPrice(symbol)
+		}
+		return state, nil
+	}
+	return fundingArbLegState{}, nil
+}
@@ -2,12 +2,15 @@ package trader
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"nofx/kernel"
 	"nofx/logger"
 	"nofx/market"
 	"nofx/store"
+	"nofx/trader/strategy"
+	"strings"
 	"sync"
 	"time"
 )
@@ -35,7 +38,7 @@ type GridState struct {
 	IsPaused    bool
 	IsInitialized bool
 
-	// Performance tracking
+	// Performance tracking (TotalProfit is realised grid profit; inventory PnL is tracked below)
 	TotalProfit   float64
 	TotalTrades   int
 	WinningTrades int
@@ -70,6 +73,16 @@ type GridState struct {
 	CurrentDirection       market.GridDirection
 	DirectionChangedAt     time.Time
 	DirectionChangeCount   int
+
+	// Trailing grid inventory
+	InventoryQty           float64 // Base inventory an infinity grid kept when levels trailed away
+	InventoryEntry         float64 // Average entry price of the base inventory
+	UnrealizedInventoryPnL float64 // Mark-to-market PnL of filled levels plus base inventory
+	TrailCount             int
+
+	// Persistence (grid_instances row this state is checkpointed to)
+	InstanceID string
+	StartedAt  time.Time
 }
 
 // NewGridState creates a new grid state
@@ -82,6 +95,75 @@ func NewGridState(config *store.GridStrategyConfig) *GridState {
 	}
 }
 
+// gridConfig returns the config of the grid being cycled
+// For a basket this is the current symbol's share of the strategy grid config
+func (at *AutoTrader) gridConfig() *store.GridStrategyConfig {
+	if at.gridState != nil && at.gridState.Config != nil {
+		return at.gridState.Config
+	}
+	return at.config.StrategyConfig.GridConfig
+}
+
+// gridBasketStates returns the grid state of every initialized basket symbol, in config order
+// It is safe to call from the API while a cycle is running
+func (at *AutoTrader) gridBasketStates() []*GridState {
+	at.gridBasketMutex.RLock()
+	defer at.gridBasketMutex.RUnlock()
+
+	states := make([]*GridState, 0, len(at.gridSymbols))
+	for _, symbol := range at.gridSymbols {
+		if state := at.gridBasket[symbol]; state != nil {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// setGridBasketState stores the state of a basket symbol, keeping the symbols in config order
+func (at *AutoTrader) setGridBasketState(state *GridState, order []string) {
+	at.gridBasketMutex.Lock()
+	defer at.gridBasketMutex.Unlock()
+
+	if at.gridBasket == nil {
+		at.gridBasket = make(map[string]*GridState)
+	}
+	at.gridBasket[state.Config.Symbol] = state
+	at.gridSymbols = at.gridSymbols[:0]
+	for _, symbol := range order {
+		if at.gridBasket[symbol] != nil {
+			at.gridSymbols = append(at.gridSymbols, symbol)
+		}
+	}
+}
+
+// primaryGridState returns the first basket symbol's state, which also carries the portfolio peak equity
+func (at *AutoTrader) primaryGridState() *GridState {
+	if states := at.gridBasketStates(); len(states) > 0 {
+		return states[0]
+	}
+	return nil
+}
+
+// forEachGridSymbol runs fn with gridState switched to each basket symbol in turn
+// The caller must hold gridCycleMutex. Errors are collected so one failing symbol doesn't stop the rest of the basket
+func (at *AutoTrader) forEachGridSymbol(fn func() error) error {
+	active := at.gridState
+	defer func() { at.gridState = active }()
+
+	states := at.gridBasketStates()
+	var errs []error
+	for _, state := range states {
+		at.gridState = state
+		if err := fn(); err != nil {
+			if len(states) <= 1 {
+				return err
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", state.Config.Symbol, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // ============================================================================
 // Breakout Detection
 // ============================================================================
@@ -98,7 +180,7 @@ const (
 // checkBreakout detects if price has broken out of grid range
 // Returns breakout type and percentage beyond boundary
 func (at *AutoTrader) checkBreakout() (BreakoutType, float64) {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridConfig()
 
 	currentPrice, err := at.trader.GetMarketPrice(gridConfig.Symbol)
 	if err != nil {
@@ -130,6 +212,7 @@ func (at *AutoTrader) checkBreakout() (BreakoutType, float64) {
 }
 
 // checkMaxDrawdown checks if current drawdown exceeds maximum allowed
+// Drawdown is measured on account equity, so it covers the whole basket; the peak is kept on the primary grid
 // Returns: (exceeded bool, currentDrawdown float64)
 func (at *AutoTrader) checkMaxDrawdown() (bool, float64) {
 	gridConfig := at.config.StrategyConfig.GridConfig
@@ -157,12 +240,13 @@ func (at *AutoTrader) checkMaxDrawdown() (bool, float64) {
 	}
 
 	// Update peak equity
-	at.gridState.mu.Lock()
-	if currentEquity > at.gridState.PeakEquity {
-		at.gridState.PeakEquity = currentEquity
+	state := at.primaryGridState()
+	state.mu.Lock()
+	if currentEquity > state.PeakEquity {
+		state.PeakEquity = currentEquity
 	}
-	peakEquity := at.gridState.PeakEquity
-	at.gridState.mu.Unlock()
+	peakEquity := state.PeakEquity
+	state.mu.Unlock()
 
 	if peakEquity <= 0 {
 		return false, 0
@@ -172,16 +256,16 @@ func (at *AutoTrader) checkMaxDrawdown() (bool, float64) {
 	drawdown := (peakEquity - currentEquity) / peakEquity * 100
 
 	// Update max drawdown tracking
-	at.gridState.mu.Lock()
-	if drawdown > at.gridState.MaxDrawdown {
-		at.gridState.MaxDrawdown = drawdown
+	state.mu.Lock()
+	if drawdown > state.MaxDrawdown {
+		state.MaxDrawdown = drawdown
 	}
-	at.gridState.mu.Unlock()
+	state.mu.Unlock()
 
 	return drawdown >= gridConfig.MaxDrawdownPct, drawdown
 }
 
-// checkDailyLossLimit checks if daily loss exceeds limit
+// checkDailyLossLimit checks if the basket's combined daily loss exceeds limit
 // Returns: (exceeded bool, dailyLossPct float64)
 func (at *AutoTrader) checkDailyLossLimit() (bool, float64) {
 	gridConfig := at.config.StrategyConfig.GridConfig
@@ -189,16 +273,20 @@ func (at *AutoTrader) checkDailyLossLimit() (bool, float64) {
 		return false, 0
 	}
 
-	at.gridState.mu.Lock()
-	// Reset daily PnL if new day
+	// Daily PnL is summed across every grid of the basket
+	dailyPnL := 0.0
 	now := time.Now()
-	if now.YearDay() != at.gridState.LastDailyReset.YearDay() ||
-		now.Year() != at.gridState.LastDailyReset.Year() {
-		at.gridState.DailyPnL = 0
-		at.gridState.LastDailyReset = now
+	for _, state := range at.gridBasketStates() {
+		state.mu.Lock()
+		// Reset daily PnL if new day
+		if now.YearDay() != state.LastDailyReset.YearDay() ||
+			now.Year() != state.LastDailyReset.Year() {
+			state.DailyPnL = 0
+			state.LastDailyReset = now
+		}
+		dailyPnL += state.DailyPnL
+		state.mu.Unlock()
 	}
-	dailyPnL := at.gridState.DailyPnL
-	at.gridState.mu.Unlock()
 
 	// Calculate daily loss as percentage of total investment
 	dailyLossPct := 0.0
@@ -219,7 +307,7 @@ func (at *AutoTrader) updateDailyPnL(realizedPnL float64) {
 
 // emergencyExit closes all positions and cancels all orders
 func (at *AutoTrader) emergencyExit(reason string) error {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridConfig()
 
 	logger.Errorf("[Grid] EMERGENCY EXIT: %s", reason)
 
@@ -235,20 +323,23 @@ func (at *AutoTrader) emergencyExit(reason string) error {
 			if sym, ok := pos["symbol"].(string); ok && sym == gridConfig.Symbol {
 				if size, ok := pos["positionAmt"].(float64); ok && size != 0 {
 					if size > 0 {
-						at.trader.CloseLong(gridConfig.Symbol, size)
+						at.submitMarketOrder("close_long", gridConfig.Symbol, size, 0, at.clientOrderIDForCycle(gridConfig.Symbol, "grid:exit:long"))
 					} else {
-						at.trader.CloseShort(gridConfig.Symbol, -size)
+						at.submitMarketOrder("close_short", gridConfig.Symbol, -size, 0, at.clientOrderIDForCycle(gridConfig.Symbol, "grid:exit:short"))
 					}
 				}
 			}
 		}
 	}
 
+	at.clearGridInventory()
+
 	// Pause grid
 	at.gridState.mu.Lock()
 	at.gridState.IsPaused = true
 	at.gridState.mu.Unlock()
 
+	at.recordGridEvent(GridEventEmergencyExit, -1, 0, 0, "", reason)
 	return nil
 }
 
@@ -284,7 +375,7 @@ func (at *AutoTrader) handleBreakout(breakoutType BreakoutType, breakoutPct floa
 
 // checkBoxBreakout checks for multi-period box breakouts and takes appropriate action
 func (at *AutoTrader) checkBoxBreakout() error {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridConfig()
 	if gridConfig == nil {
 		return nil
 	}
@@ -306,18 +397,15 @@ func (at *AutoTrader) checkBoxBreakout() error {
 	at.gridState.LongBoxLower = box.LongLower
 	at.gridState.mu.Unlock()
 
-	// Detect breakout
-	breakoutLevel, direction := detectBoxBreakout(box)
-
 	// Get current breakout state
-	state := &BreakoutState{
+	state := &strategy.BreakoutState{
 		Level:        market.BreakoutLevel(at.gridState.BreakoutLevel),
 		Direction:    at.gridState.BreakoutDirection,
 		ConfirmCount: at.gridState.BreakoutConfirmCount,
 	}
 
-	// Check if breakout is confirmed (3 candles)
-	confirmed := confirmBreakout(state, breakoutLevel, direction)
+	// Detect and confirm breakout (3 candles), using direction-aware actions if enabled
+	action, newDirection := strategy.EvaluateBoxBreakout(box, state, at.gridState.CurrentDirection, gridConfig.EnableDirectionAdjust)
 
 	// Update grid state
 	at.gridState.mu.Lock()
@@ -326,19 +414,7 @@ func (at *AutoTrader) checkBoxBreakout() error {
 	at.gridState.BreakoutConfirmCount = state.ConfirmCount
 	at.gridState.mu.Unlock()
 
-	if !confirmed {
-		return nil
-	}
-
-	// Take action based on breakout level
-	// Use direction-aware action if enabled
-	enableDirectionAdjust := gridConfig.EnableDirectionAdjust
-	action := getBreakoutActionWithDirection(breakoutLevel, enableDirectionAdjust)
-
-	// If direction adjustment action, determine the new direction
-	if action == BreakoutActionAdjustDirection {
-		box, _ := market.GetBoxData(gridConfig.Symbol)
-		newDirection := determineGridDirection(box, at.gridState.CurrentDirection, breakoutLevel, direction)
+	if action == strategy.BreakoutActionAdjustDirection {
 		return at.executeDirectionAdjustment(newDirection)
 	}
 
@@ -346,9 +422,9 @@ func (at *AutoTrader) checkBoxBreakout() error {
 }
 
 // executeBreakoutAction executes the appropriate action for a breakout
-func (at *AutoTrader) executeBreakoutAction(action BreakoutAction) error {
+func (at *AutoTrader) executeBreakoutAction(action strategy.BreakoutAction) error {
 	switch action {
-	case BreakoutActionReducePosition:
+	case strategy.BreakoutActionReducePosition:
 		// Short box breakout: reduce position to 50%
 		logger.Infof("Short box breakout confirmed, reducing position to 50%%")
 		at.gridState.mu.Lock()
@@ -356,7 +432,7 @@ func (at *AutoTrader) executeBreakoutAction(action BreakoutAction) error {
 		at.gridState.mu.Unlock()
 		return nil
 
-	case BreakoutActionPauseGrid:
+	case strategy.BreakoutActionPauseGrid:
 		// Mid box breakout: pause grid + cancel orders
 		logger.Infof("Mid box breakout confirmed, pausing grid and canceling orders")
 		at.gridState.mu.Lock()
@@ -364,7 +440,7 @@ func (at *AutoTrader) executeBreakoutAction(action BreakoutAction) error {
 		at.gridState.mu.Unlock()
 		return at.cancelAllGridOrders()
 
-	case BreakoutActionCloseAll:
+	case strategy.BreakoutActionCloseAll:
 		// Long box breakout: pause + cancel + close all
 		logger.Infof("Long box breakout confirmed, closing all positions")
 		at.gridState.mu.Lock()
@@ -375,7 +451,7 @@ func (at *AutoTrader) executeBreakoutAction(action BreakoutAction) error {
 		}
 		return at.closeAllPositions()
 
-	case BreakoutActionAdjustDirection:
+	case strategy.BreakoutActionAdjustDirection:
 		// Direction adjustment is handled separately via executeDirectionAdjustment
 		// This case should not be reached, but handle gracefully
 		logger.Infof("Direction adjustment action received via executeBreakoutAction")
@@ -407,8 +483,9 @@ func (at *AutoTrader) executeDirectionAdjustment(newDirection market.GridDirecti
 }
 
 // closeAllPositions closes all open positions for the grid symbol
+// An infinity grid keeps its base inventory: only the long beyond it is closed
 func (at *AutoTrader) closeAllPositions() error {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridConfig()
 	if gridConfig == nil {
 		return nil
 	}
@@ -418,6 +495,11 @@ func (at *AutoTrader) closeAllPositions() error {
 		return fmt.Errorf("failed to get positions: %w", err)
 	}
 
+	keepInventory := gridConfig.TrailingMode == strategy.GridTrailingInfinity
+	at.gridState.mu.RLock()
+	inventory := at.gridState.InventoryQty
+	at.gridState.mu.RUnlock()
+
 	for _, pos := range positions {
 		symbol, _ := pos["symbol"].(string)
 		if symbol != gridConfig.Symbol {
@@ -425,26 +507,32 @@ func (at *AutoTrader) closeAllPositions() error {
 		}
 
 		size, _ := pos["positionAmt"].(float64)
+		if keepInventory && size > 0 {
+			size = math.Max(size-inventory, 0)
+		}
 		if size == 0 {
 			continue
 		}
 
 		if size > 0 {
-			_, err = at.trader.CloseLong(symbol, size)
+			_, err = at.submitMarketOrder("close_long", symbol, size, 0, at.clientOrderIDForCycle(symbol, "grid:close_all:long"))
 		} else {
-			_, err = at.trader.CloseShort(symbol, -size)
+			_, err = at.submitMarketOrder("close_short", symbol, -size, 0, at.clientOrderIDForCycle(symbol, "grid:close_all:short"))
 		}
 		if err != nil {
 			logger.Infof("Failed to close position: %v", err)
 		}
 	}
+	if !keepInventory {
+		at.clearGridInventory()
+	}
 
 	return nil
 }
 
 // checkFalseBreakoutRecovery checks if price has returned to box after breakout
 func (at *AutoTrader) checkFalseBreakoutRecovery() error {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridConfig()
 	if gridConfig == nil {
 		return nil
 	}
@@ -487,13 +575,10 @@ func (at *AutoTrader) checkFalseBreakoutRecovery() error {
 
 	// Check for direction recovery toward neutral (if direction adjustment is enabled)
 	if gridConfig.EnableDirectionAdjust && currentDirection != market.GridDirectionNeutral {
-		if shouldRecoverDirection(box, currentDirection) {
-			newDirection := determineRecoveryDirection(box.CurrentPrice, box, currentDirection)
-			if newDirection != currentDirection {
-				logger.Infof("[Grid] Direction recovery: %s → %s (price back in short box)",
-					currentDirection, newDirection)
-				at.adjustGridDirection(newDirection)
-			}
+		if newDirection := strategy.RecoverGridDirection(box, currentDirection); newDirection != currentDirection {
+			logger.Infof("[Grid] Direction recovery: %s → %s (price back in short box)",
+				currentDirection, newDirection)
+			at.adjustGridDirection(newDirection)
 		}
 	}
 
@@ -504,14 +589,68 @@ func (at *AutoTrader) checkFalseBreakoutRecovery() error {
 // AutoTrader Grid Methods
 // ============================================================================
 
-// InitializeGrid initializes the grid state and calculates levels
+// InitializeGrid initializes the grid state and calculates levels for every basket symbol
 func (at *AutoTrader) InitializeGrid() error {
+	at.gridCycleMutex.Lock()
+	defer at.gridCycleMutex.Unlock()
+	return at.initializeGridBasket()
+}
+
+// initializeGridBasket lays out or restores every basket symbol that isn't initialized yet
+// Symbols that fail are retried on the next cycle; the ones already up are kept, so a partial start resumes
+func (at *AutoTrader) initializeGridBasket() error {
 	if at.config.StrategyConfig == nil || at.config.StrategyConfig.GridConfig == nil {
 		return fmt.Errorf("grid configuration not found")
 	}
 
-	gridConfig := at.config.StrategyConfig.GridConfig
-	at.gridState = NewGridState(gridConfig)
+	configs := strategy.GridBasketConfigs(at.config.StrategyConfig.GridConfig)
+	order := make([]string, len(configs))
+	for i, config := range configs {
+		order[i] = config.Symbol
+	}
+
+	var errs []error
+	for _, config := range configs {
+		at.gridBasketMutex.RLock()
+		existing := at.gridBasket[config.Symbol]
+		at.gridBasketMutex.RUnlock()
+		if existing != nil {
+			continue
+		}
+
+		at.gridState = NewGridState(config)
+		if err := at.initializeGridSymbol(); err != nil {
+			if len(configs) == 1 {
+				return err
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", config.Symbol, err))
+			continue
+		}
+		at.setGridBasketState(at.gridState, order)
+	}
+
+	if primary := at.primaryGridState(); primary != nil {
+		at.gridState = primary
+	}
+	return errors.Join(errs...)
+}
+
+// gridBasketReady returns true once every basket symbol has been initialized
+func (at *AutoTrader) gridBasketReady() bool {
+	if at.config.StrategyConfig == nil || at.config.StrategyConfig.GridConfig == nil {
+		return false
+	}
+	return len(at.gridBasketStates()) >= len(strategy.GridBasketConfigs(at.config.StrategyConfig.GridConfig))
+}
+
+// initializeGridSymbol restores or lays out the grid of the current state's symbol
+func (at *AutoTrader) initializeGridSymbol() error {
+	gridConfig := at.gridState.Config
+
+	// Resume the persisted grid and re-attach its resting orders if there is one
+	if at.restoreGridState(gridConfig) {
+		return nil
+	}
 
 	// Get current market price
 	price, err := at.trader.GetMarketPrice(gridConfig.Symbol)
@@ -541,6 +680,7 @@ func (at *AutoTrader) InitializeGrid() error {
 	// Initialize grid levels
 	at.initializeGridLevels(price, gridConfig)
 
+	at.startGridInstance()
 	at.gridState.IsInitialized = true
 
 	// CRITICAL: Set leverage on exchange before trading
@@ -554,15 +694,16 @@ func (at *AutoTrader) InitializeGrid() error {
 	logger.Infof("📊 [Grid] Initialized: %d levels, $%.2f - $%.2f, spacing $%.2f",
 		gridConfig.GridCount, at.gridState.LowerPrice, at.gridState.UpperPrice, at.gridState.GridSpacing)
 
+	at.recordGridEvent(GridEventInitialized, -1, price, 0, "",
+		fmt.Sprintf("%d levels, $%.2f - $%.2f", gridConfig.GridCount, at.gridState.LowerPrice, at.gridState.UpperPrice))
+	at.checkpointGridState()
+
 	return nil
 }
 
 // calculateDefaultBounds calculates default bounds based on price
 func (at *AutoTrader) calculateDefaultBounds(price float64, config *store.GridStrategyConfig) {
-	// Default: ±3% from current price
-	multiplier := 0.03 * float64(config.GridCount) / 10
-	at.gridState.UpperPrice = price * (1 + multiplier)
-	at.gridState.LowerPrice = price * (1 - multiplier)
+	at.gridState.UpperPrice, at.gridState.LowerPrice = strategy.GridDefaultBounds(price, config)
 }
 
 // calculateATRBounds calculates bounds using ATR
@@ -571,66 +712,14 @@ func (at *AutoTrader) calculateATRBounds(price float64, mktData *market.Data, co
 	if mktData.LongerTermContext != nil {
 		atr = mktData.LongerTermContext.ATR14
 	}
-
-	if atr <= 0 {
-		at.calculateDefaultBounds(price, config)
-		return
-	}
-
-	multiplier := config.ATRMultiplier
-	if multiplier <= 0 {
-		multiplier = 2.0
-	}
-
-	halfRange := atr * multiplier
-	at.gridState.UpperPrice = price + halfRange
-	at.gridState.LowerPrice = price - halfRange
+	at.gridState.UpperPrice, at.gridState.LowerPrice = strategy.GridATRBounds(price, atr, config)
 }
 
 // initializeGridLevels creates the grid level structure
 func (at *AutoTrader) initializeGridLevels(currentPrice float64, config *store.GridStrategyConfig) {
-	levels := make([]kernel.GridLevelInfo, config.GridCount)
-	totalWeight := 0.0
-	weights := make([]float64, config.GridCount)
-
-	// Calculate weights based on distribution
-	for i := 0; i < config.GridCount; i++ {
-		switch config.Distribution {
-		case "gaussian":
-			// Gaussian distribution - more weight in the middle
-			center := float64(config.GridCount-1) / 2
-			sigma := float64(config.GridCount) / 4
-			weights[i] = math.Exp(-math.Pow(float64(i)-center, 2) / (2 * sigma * sigma))
-		case "pyramid":
-			// Pyramid - more weight at bottom
-			weights[i] = float64(config.GridCount - i)
-		default: // uniform
-			weights[i] = 1.0
-		}
-		totalWeight += weights[i]
-	}
-
-	// Create levels
-	for i := 0; i < config.GridCount; i++ {
-		price := at.gridState.LowerPrice + float64(i)*at.gridState.GridSpacing
-		allocatedUSD := config.TotalInvestment * weights[i] / totalWeight
-
-		// Determine initial side (below current price = buy, above = sell)
-		side := "buy"
-		if price > currentPrice {
-			side = "sell"
-		}
-
-		levels[i] = kernel.GridLevelInfo{
-			Index:        i,
-			Price:        price,
-			State:        "empty",
-			Side:         side,
-			AllocatedUSD: allocatedUSD,
-		}
-	}
-
-	at.gridState.Levels = levels
+	// Level prices are snapped to the exchange tick size
+	spec := at.contractSpec(config.Symbol)
+	at.gridState.Levels = strategy.BuildGridLevels(at.gridState.LowerPrice, at.gridState.GridSpacing, currentPrice, config, spec)
 
 	// Apply direction-based side assignment if enabled
 	if config.EnableDirectionAdjust {
@@ -644,86 +733,17 @@ func (at *AutoTrader) applyGridDirection(currentPrice float64) {
 	config := at.gridState.Config
 	direction := at.gridState.CurrentDirection
 
-	// Get bias ratio from config, default to 0.7 (70%/30%)
-	biasRatio := config.DirectionBiasRatio
-	if biasRatio <= 0 || biasRatio > 1 {
-		biasRatio = 0.7
-	}
-
-	buyRatio, _ := direction.GetBuySellRatio(biasRatio)
-
-	// Calculate how many levels should be buy vs sell based on direction
-	totalLevels := len(at.gridState.Levels)
-	targetBuyLevels := int(float64(totalLevels) * buyRatio)
+	strategy.ApplyGridDirection(at.gridState.Levels, direction, config.DirectionBiasRatio, currentPrice)
 
-	// For neutral: use price-based assignment (buy below, sell above)
-	if direction == market.GridDirectionNeutral {
-		for i := range at.gridState.Levels {
-			if at.gridState.Levels[i].Price <= currentPrice {
-				at.gridState.Levels[i].Side = "buy"
-			} else {
-				at.gridState.Levels[i].Side = "sell"
-			}
-		}
-		return
-	}
-
-	// For long/long_bias: more buy levels
-	// For short/short_bias: more sell levels
-	switch direction {
-	case market.GridDirectionLong:
-		// 100% buy - all levels are buy
-		for i := range at.gridState.Levels {
-			at.gridState.Levels[i].Side = "buy"
-		}
-
-	case market.GridDirectionShort:
-		// 100% sell - all levels are sell
-		for i := range at.gridState.Levels {
-			at.gridState.Levels[i].Side = "sell"
-		}
-
-	case market.GridDirectionLongBias, market.GridDirectionShortBias:
-		// Assign sides based on position relative to current price
-		// For long_bias: keep all below as buy, convert some above to buy
-		// For short_bias: keep all above as sell, convert some below to sell
-		buyCount := 0
-		sellCount := 0
-
-		for i := range at.gridState.Levels {
-			needMoreBuys := buyCount < targetBuyLevels
-			needMoreSells := sellCount < (totalLevels - targetBuyLevels)
-
-			if at.gridState.Levels[i].Price <= currentPrice {
-				// Level below or at current price
-				if needMoreBuys {
-					at.gridState.Levels[i].Side = "buy"
-					buyCount++
-				} else {
-					at.gridState.Levels[i].Side = "sell"
-					sellCount++
-				}
-			} else {
-				// Level above current price
-				if needMoreSells && direction == market.GridDirectionShortBias {
-					at.gridState.Levels[i].Side = "sell"
-					sellCount++
-				} else if needMoreBuys && direction == market.GridDirectionLongBias {
-					at.gridState.Levels[i].Side = "buy"
-					buyCount++
-				} else if needMoreSells {
-					at.gridState.Levels[i].Side = "sell"
-					sellCount++
-				} else {
-					at.gridState.Levels[i].Side = "buy"
-					buyCount++
-				}
-			}
+	if direction != market.GridDirectionNeutral {
+		biasRatio := config.DirectionBiasRatio
+		if biasRatio <= 0 || biasRatio > 1 {
+			biasRatio = 0.7
 		}
+		buyRatio, _ := direction.GetBuySellRatio(biasRatio)
+		logger.Infof("[Grid] Applied direction %s: buy_ratio=%.0f%%, levels reconfigured",
+			direction, buyRatio*100)
 	}
-
-	logger.Infof("[Grid] Applied direction %s: buy_ratio=%.0f%%, levels reconfigured",
-		direction, buyRatio*100)
 }
 
 // adjustGridDirection handles runtime direction adjustment when breakout is detected
@@ -756,6 +776,7 @@ func (at *AutoTrader) adjustGridDirection(newDirection market.GridDirection) err
 }
 
 // RunGridCycle executes one grid trading cycle
+// Portfolio-wide limits are checked once, then each basket symbol runs its own cycle
 func (at *AutoTrader) RunGridCycle() error {
 	// Check if trader is stopped (early exit to prevent trades after Stop() is called)
 	at.isRunningMutex.RLock()
@@ -766,36 +787,55 @@ func (at *AutoTrader) RunGridCycle() error {
 		return nil
 	}
 
-	if at.gridState == nil || !at.gridState.IsInitialized {
-		if err := at.InitializeGrid(); err != nil {
-			return fmt.Errorf("failed to initialize grid: %w", err)
-		}
-	}
+	at.gridCycleMutex.Lock()
+	defer at.gridCycleMutex.Unlock()
 
-	// CRITICAL: Check for breakout before executing any trades
-	breakoutType, breakoutPct := at.checkBreakout()
-	if breakoutType != BreakoutNone {
-		if err := at.handleBreakout(breakoutType, breakoutPct); err != nil {
-			return err // Grid paused due to breakout
+	// Retry any basket symbol that failed to initialize; the rest keep trading meanwhile
+	if !at.gridBasketReady() {
+		if err := at.initializeGridBasket(); err != nil {
+			if len(at.gridBasketStates()) == 0 {
+				return fmt.Errorf("failed to initialize grid: %w", err)
+			}
+			logger.Warnf("[Grid] Some basket symbols are not initialized yet: %v", err)
 		}
 	}
 
-	// CRITICAL: Check max drawdown
+	// Checkpoint whatever this cycle changed, including early returns on pause/breakout
+	defer at.checkpointGridBasket()
+
+	// CRITICAL: Check max drawdown across the basket
 	exceeded, drawdown := at.checkMaxDrawdown()
 	if exceeded {
-		return at.emergencyExit(fmt.Sprintf("max drawdown exceeded: %.2f%%", drawdown))
+		reason := fmt.Sprintf("max drawdown exceeded: %.2f%%", drawdown)
+		return at.forEachGridSymbol(func() error { return at.emergencyExit(reason) })
 	}
 
-	// CRITICAL: Check daily loss limit
+	// CRITICAL: Check daily loss limit across the basket
 	dailyExceeded, dailyLossPct := at.checkDailyLossLimit()
 	if dailyExceeded {
 		logger.Errorf("[Grid] Daily loss limit exceeded: %.2f%%", dailyLossPct)
-		at.gridState.mu.Lock()
-		at.gridState.IsPaused = true
-		at.gridState.mu.Unlock()
+		for _, state := range at.gridBasketStates() {
+			state.mu.Lock()
+			state.IsPaused = true
+			state.mu.Unlock()
+		}
 		return fmt.Errorf("daily loss limit exceeded: %.2f%%", dailyLossPct)
 	}
 
+	return at.forEachGridSymbol(at.runGridSymbolCycle)
+}
+
+// runGridSymbolCycle runs the breakout checks and order cycle for the current basket symbol
+func (at *AutoTrader) runGridSymbolCycle() error {
+	// CRITICAL: Check for breakout before executing any trades (trailing grids follow the trend instead)
+	breakoutType, breakoutPct := at.checkBreakout()
+	if breakoutType != BreakoutNone && !at.trailGrid(breakoutType) {
+		if err := at.handleBreakout(breakoutType, breakoutPct); err != nil {
+			return err // Grid paused due to breakout
+		}
+	}
+	at.updateInventoryPnL()
+
 	// Check multi-period box breakout
 	if err := at.checkBoxBreakout(); err != nil {
 		logger.Infof("Box breakout check error: %v", err)
@@ -815,7 +855,12 @@ func (at *AutoTrader) RunGridCycle() error {
 		return nil
 	}
 
-	gridConfig := at.config.StrategyConfig.GridConfig
+	// Mechanical mode keeps the book filled by rule; the AI is only consulted for periodic reviews
+	if at.isMechanicalGrid() {
+		return at.runMechanicalGridCycle()
+	}
+
+	gridConfig := at.gridConfig()
 	lang := at.config.StrategyConfig.Language
 	if lang == "" {
 		lang = "en"
@@ -835,7 +880,7 @@ func (at *AutoTrader) RunGridCycle() error {
 
 	// Check if trader is stopped before executing any decisions (prevent trades after Stop())
 	at.isRunningMutex.RLock()
-	running = at.isRunning
+	running := at.isRunning
 	at.isRunningMutex.RUnlock()
 	if !running {
 		logger.Infof("[Grid] Trader stopped before decision execution, aborting grid cycle")
@@ -869,7 +914,7 @@ func (at *AutoTrader) RunGridCycle() error {
 
 // buildGridContext builds the context for AI grid decisions
 func (at *AutoTrader) buildGridContext() (*kernel.GridContext, error) {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridConfig()
 
 	// Get market data
 	mktData, err := market.GetWithTimeframes(gridConfig.Symbol, []string{"5m", "4h"}, "5m", 50)
@@ -953,11 +998,8 @@ func (at *AutoTrader) executeGridDecision(d *kernel.Decision) error {
 		logger.Infof("[Grid] Holding current state: %s", d.Reasoning)
 		return nil
 	// Support standard actions for closing positions
-	case "close_long":
-		_, err := at.trader.CloseLong(d.Symbol, d.Quantity)
-		return err
-	case "close_short":
-		_, err := at.trader.CloseShort(d.Symbol, d.Quantity)
+	case "close_long", "close_short":
+		_, err := at.submitMarketOrder(d.Action, d.Symbol, d.Quantity, 0, at.clientOrderIDForCycle(d.Symbol, "grid:"+d.Action))
 		return err
 	default:
 		logger.Warnf("[Grid] Unknown action: %s", d.Action)
@@ -966,6 +1008,7 @@ func (at *AutoTrader) executeGridDecision(d *kernel.Decision) error {
 }
 
 // checkTotalPositionLimit checks if adding a new position would exceed total limits
+// Positions and pending orders of every basket symbol count against the shared limit
 // Returns: (allowed bool, currentPositionValue float64, maxAllowed float64)
 func (at *AutoTrader) checkTotalPositionLimit(symbol string, additionalValue float64) (bool, float64, float64) {
 	gridConfig := at.config.StrategyConfig.GridConfig
@@ -974,17 +1017,24 @@ func (at *AutoTrader) checkTotalPositionLimit(symbol string, additionalValue flo
 	// Total position should not exceed: TotalInvestment × Leverage
 	maxTotalPositionValue := gridConfig.TotalInvestment * float64(gridConfig.Leverage)
 
+	// The limit covers every symbol of the basket
+	states := at.gridBasketStates()
+	symbols := map[string]bool{symbol: true}
+	for _, state := range states {
+		symbols[state.Config.Symbol] = true
+	}
+
 	// Get current position value from exchange
 	currentPositionValue := 0.0
 	positions, err := at.trader.GetPositions()
 	if err == nil {
 		for _, pos := range positions {
-			if sym, ok := pos["symbol"].(string); ok && sym == symbol {
+			if sym, ok := pos["symbol"].(string); ok && symbols[sym] {
 				if size, ok := pos["positionAmt"].(float64); ok {
 					if price, ok := pos["markPrice"].(float64); ok {
-						currentPositionValue = math.Abs(size) * price
+						currentPositionValue += math.Abs(size) * price
 					} else if entryPrice, ok := pos["entryPrice"].(float64); ok {
-						currentPositionValue = math.Abs(size) * entryPrice
+						currentPositionValue += math.Abs(size) * entryPrice
 					}
 				}
 			}
@@ -992,14 +1042,16 @@ func (at *AutoTrader) checkTotalPositionLimit(symbol string, additionalValue flo
 	}
 
 	// Also count pending orders as potential position
-	at.gridState.mu.RLock()
 	pendingValue := 0.0
-	for _, level := range at.gridState.Levels {
-		if level.State == "pending" {
-			pendingValue += level.OrderQuantity * level.Price
+	for _, state := range states {
+		state.mu.RLock()
+		for _, level := range state.Levels {
+			if level.State == "pending" {
+				pendingValue += level.OrderQuantity * level.Price
+			}
 		}
+		state.mu.RUnlock()
 	}
-	at.gridState.mu.RUnlock()
 
 	totalAfterOrder := currentPositionValue + pendingValue + additionalValue
 	allowed := totalAfterOrder <= maxTotalPositionValue
@@ -1012,11 +1064,11 @@ func (at *AutoTrader) placeGridLimitOrder(d *kernel.Decision, side string) error
 	// Check if trader supports GridTrader interface
 	gridTrader, ok := at.trader.(GridTrader)
 	if !ok {
-		// Fallback to adapter
+		// Fallback adapter rejects the order with ErrLimitOrdersNotSupported
 		gridTrader = NewGridTraderAdapter(at.trader)
 	}
 
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridConfig()
 
 	// CRITICAL: Validate and cap quantity to prevent excessive position sizes
 	// This protects against AI miscalculations or leverage misconfigurations
@@ -1062,6 +1114,14 @@ func (at *AutoTrader) placeGridLimitOrder(d *kernel.Decision, side string) error
 		}
 	}
 
+	// Snap to the exchange tick and lot step so the order isn't rejected for precision
+	spec := at.contractSpec(d.Symbol)
+	d.Price = spec.RoundPrice(d.Price)
+	quantity = spec.FloorQty(quantity)
+	if err := spec.CheckOrder(quantity, d.Price); err != nil {
+		return fmt.Errorf("grid order below exchange minimum: %w", err)
+	}
+
 	// CRITICAL: Check total position limit before placing order
 	orderValue := quantity * d.Price
 	allowed, currentValue, maxValue := at.checkTotalPositionLimit(d.Symbol, orderValue)
@@ -1079,10 +1139,10 @@ func (at *AutoTrader) placeGridLimitOrder(d *kernel.Decision, side string) error
 		Leverage:   gridConfig.Leverage,
 		PostOnly:   gridConfig.UseMakerOnly,
 		ReduceOnly: false,
-		ClientID:   fmt.Sprintf("grid-%d-%d", d.LevelIndex, time.Now().UnixNano()%1000000),
+		ClientID:   at.gridClientOrderID(d.Symbol, fmt.Sprintf("grid:%d:%s", d.LevelIndex, side)),
 	}
 
-	result, err := gridTrader.PlaceLimitOrder(req)
+	result, err := at.placeGridLimitOrderIdempotent(gridTrader, req)
 	if err != nil {
 		return fmt.Errorf("failed to place limit order: %w", err)
 	}
@@ -1099,10 +1159,49 @@ func (at *AutoTrader) placeGridLimitOrder(d *kernel.Decision, side string) error
 
 	logger.Infof("[Grid] Placed %s limit order at $%.2f, qty=%.4f, level=%d, orderID=%s",
 		side, d.Price, d.Quantity, d.LevelIndex, result.OrderID)
+	at.recordGridEvent(GridEventOrderPlaced, d.LevelIndex, d.Price, quantity, side, result.OrderID)
 
 	return nil
 }
 
+// placeGridLimitOrderIdempotent places a grid limit order unless one with the same client ID is already live
+// If the submit fails, the exchange is checked for the client ID before the error is returned
+func (at *AutoTrader) placeGridLimitOrderIdempotent(gridTrader GridTrader, req *LimitOrderRequest) (*LimitOrderResult, error) {
+	cot, ok := at.trader.(ClientOrderTrader)
+	if !ok {
+		return gridTrader.PlaceLimitOrder(req)
+	}
+
+	toResult := func(existing map[string]interface{}) *LimitOrderResult {
+		status, _ := existing["status"].(string)
+		return &LimitOrderResult{
+			OrderID:      fmt.Sprintf("%v", existing["orderId"]),
+			ClientID:     req.ClientID,
+			Symbol:       req.Symbol,
+			Side:         req.Side,
+			PositionSide: req.PositionSide,
+			Price:        req.Price,
+			Quantity:     req.Quantity,
+			Status:       status,
+		}
+	}
+
+	if existing := at.lookupClientOrder(cot, req.Symbol, req.ClientID); existing != nil {
+		logger.Infof("[Grid] Order with client ID %s already on exchange (id: %v), skipping resubmit", req.ClientID, existing["orderId"])
+		return toResult(existing), nil
+	}
+
+	result, err := gridTrader.PlaceLimitOrder(req)
+	if err == nil {
+		return result, nil
+	}
+	if existing := at.lookupClientOrder(cot, req.Symbol, req.ClientID); existing != nil {
+		logger.Infof("[Grid] Limit order submit returned error (%v) but exchange accepted client ID %s", err, req.ClientID)
+		return toResult(existing), nil
+	}
+	return nil, err
+}
+
 // cancelGridOrder cancels a specific grid order
 func (at *AutoTrader) cancelGridOrder(d *kernel.Decision) error {
 	gridTrader, ok := at.trader.(GridTrader)
@@ -1115,8 +1214,10 @@ func (at *AutoTrader) cancelGridOrder(d *kernel.Decision) error {
 	}
 
 	// Update state
+	levelIndex := -1
 	at.gridState.mu.Lock()
 	if levelIdx, ok := at.gridState.OrderBook[d.OrderID]; ok {
+		levelIndex = levelIdx
 		if levelIdx >= 0 && levelIdx < len(at.gridState.Levels) {
 			at.gridState.Levels[levelIdx].State = "empty"
 			at.gridState.Levels[levelIdx].OrderID = ""
@@ -1127,12 +1228,13 @@ func (at *AutoTrader) cancelGridOrder(d *kernel.Decision) error {
 	at.gridState.mu.Unlock()
 
 	logger.Infof("[Grid] Cancelled order: %s", d.OrderID)
+	at.recordGridEvent(GridEventOrderCanceled, levelIndex, 0, 0, "", d.OrderID)
 	return nil
 }
 
 // cancelAllGridOrders cancels all grid orders
 func (at *AutoTrader) cancelAllGridOrders() error {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridConfig()
 
 	if err := at.trader.CancelAllOrders(gridConfig.Symbol); err != nil {
 		return fmt.Errorf("failed to cancel all orders: %w", err)
@@ -1163,6 +1265,7 @@ func (at *AutoTrader) pauseGrid(reason string) error {
 	at.gridState.mu.Unlock()
 
 	logger.Infof("[Grid] Paused: %s", reason)
+	at.recordGridEvent(GridEventPaused, -1, 0, 0, "", reason)
 	return nil
 }
 
@@ -1173,6 +1276,7 @@ func (at *AutoTrader) resumeGrid() error {
 	at.gridState.mu.Unlock()
 
 	logger.Infof("[Grid] Resumed")
+	at.recordGridEvent(GridEventResumed, -1, 0, 0, "", "")
 	return nil
 }
 
@@ -1181,7 +1285,7 @@ func (at *AutoTrader) adjustGrid(d *kernel.Decision) error {
 	// Cancel existing orders first
 	at.cancelAllGridOrders()
 
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridConfig()
 
 	// Get current price
 	price, err := at.trader.GetMarketPrice(gridConfig.Symbol)
@@ -1197,14 +1301,15 @@ func (at *AutoTrader) adjustGrid(d *kernel.Decision) error {
 }
 
 // syncGridState syncs grid state with exchange
-func (at *AutoTrader) syncGridState() {
-	gridConfig := at.config.StrategyConfig.GridConfig
+// Returns the levels whose orders filled since the last sync
+func (at *AutoTrader) syncGridState() []kernel.GridLevelInfo {
+	gridConfig := at.gridConfig()
 
 	// Get open orders from exchange
 	openOrders, err := at.trader.GetOpenOrders(gridConfig.Symbol)
 	if err != nil {
 		logger.Warnf("[Grid] Failed to get open orders: %v", err)
-		return
+		return nil
 	}
 
 	// Build set of active order IDs
@@ -1228,42 +1333,88 @@ func (at *AutoTrader) syncGridState() {
 		}
 	}
 
+	// Ask the exchange how vanished orders ended; the position heuristic below covers unknown statuses
+	at.gridState.mu.RLock()
+	var vanished []string
+	for _, level := range at.gridState.Levels {
+		if level.State == "pending" && level.OrderID != "" && !activeOrderIDs[level.OrderID] {
+			vanished = append(vanished, level.OrderID)
+		}
+	}
+	at.gridState.mu.RUnlock()
+	finalStatus := make(map[string]string, len(vanished))
+	for _, orderID := range vanished {
+		if status, err := at.trader.GetOrderStatus(gridConfig.Symbol, orderID); err == nil {
+			if statusStr, _ := status["status"].(string); statusStr != "" {
+				finalStatus[orderID] = strings.ToUpper(statusStr)
+			}
+		}
+	}
+
+	// Mechanical grids count completed round trips in rearmGridFill, so win rate compares like with like
+	countFills := !at.isMechanicalGrid()
+
 	// Update levels based on order status
 	at.gridState.mu.Lock()
-	expectedPositionSize := 0.0
+	expectedPositionSize := at.gridState.InventoryQty
 	for _, level := range at.gridState.Levels {
 		if level.State == "filled" {
 			expectedPositionSize += level.PositionSize
 		}
 	}
 
+	type levelEvent struct {
+		eventType string
+		level     kernel.GridLevelInfo
+		orderID   string
+	}
+	var events []levelEvent
+	var filledLevels []kernel.GridLevelInfo
+
 	for i := range at.gridState.Levels {
 		level := &at.gridState.Levels[i]
 		if level.State == "pending" && level.OrderID != "" {
 			if !activeOrderIDs[level.OrderID] {
-				// Order no longer exists - check if position changed to determine fill vs cancel
-				// This is a heuristic - ideally we'd query order history
+				orderID := level.OrderID
+				// Order no longer exists - use the exchange's final status when it reported one,
+				// otherwise check if position changed to determine fill vs cancel
 				// If current position is larger than expected filled positions, this order was likely filled
-				if math.Abs(currentPositionSize) > math.Abs(expectedPositionSize) {
+				filled := math.Abs(currentPositionSize) > math.Abs(expectedPositionSize)
+				switch finalStatus[orderID] {
+				case "FILLED":
+					filled = true
+				case "CANCELED", "CANCELLED", "EXPIRED", "REJECTED":
+					filled = false
+				}
+				if filled {
 					// Position increased, likely filled
 					level.State = "filled"
 					level.PositionEntry = level.Price
 					level.PositionSize = level.OrderQuantity
-					at.gridState.TotalTrades++
+					if countFills {
+						at.gridState.TotalTrades++
+					}
 					logger.Infof("[Grid] Level %d order filled at $%.2f", i, level.Price)
+					events = append(events, levelEvent{GridEventOrderFilled, *level, orderID})
+					filledLevels = append(filledLevels, *level)
 				} else {
 					// Position didn't increase as expected, likely cancelled
+					events = append(events, levelEvent{GridEventOrderCanceled, *level, orderID})
 					level.State = "empty"
 					level.OrderID = ""
 					level.OrderQuantity = 0
 					logger.Infof("[Grid] Level %d order cancelled/expired", i)
 				}
-				delete(at.gridState.OrderBook, level.OrderID)
+				delete(at.gridState.OrderBook, orderID)
 			}
 		}
 	}
 	at.gridState.mu.Unlock()
 
+	for _, e := range events {
+		at.recordGridEvent(e.eventType, e.level.Index, e.level.Price, e.level.OrderQuantity, e.level.Side, e.orderID)
+	}
+
 	logger.Debugf("[Grid] Synced state: position=%.4f, orders=%d", currentPositionSize, len(openOrders))
 
 	// Check stop loss
@@ -1271,19 +1422,13 @@ func (at *AutoTrader) syncGridState() {
 
 	// Check grid skew
 	at.autoAdjustGrid()
+
+	return filledLevels
 }
 
-// saveGridDecisionRecord saves the grid decision to database
+// saveGridDecisionRecord saves the grid decision through saveDecision, which advances the cycle even without a store
 func (at *AutoTrader) saveGridDecisionRecord(decision *kernel.FullDecision) {
-	if at.store == nil {
-		return
-	}
-
-	at.cycleNumber++
-
 	record := &store.DecisionRecord{
-		TraderID:            at.id,
-		CycleNumber:         at.cycleNumber,
 		Timestamp:           time.Now().UTC(),
 		SystemPrompt:        decision.SystemPrompt,
 		InputPrompt:         decision.UserPrompt,
@@ -1318,7 +1463,7 @@ func (at *AutoTrader) saveGridDecisionRecord(decision *kernel.FullDecision) {
 
 	record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("Grid cycle completed with %d decisions", len(decision.Decisions)))
 
-	if err := at.store.Decision().LogDecision(record); err != nil {
+	if err := at.saveDecision(record); err != nil {
 		logger.Warnf("[Grid] Failed to save decision record: %v", err)
 	}
 }
@@ -1384,7 +1529,7 @@ func (at *AutoTrader) autoAdjustGrid() {
 	logger.Warnf("[Grid] Grid heavily skewed: buy_filled=%d, sell_filled=%d. Auto-adjusting...",
 		buyFilled, sellFilled)
 
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridConfig()
 
 	// Get current price
 	currentPrice, err := at.trader.GetMarketPrice(gridConfig.Symbol)
@@ -1481,10 +1626,7 @@ func (at *AutoTrader) autoAdjustGrid() {
 
 // calculateDefaultBoundsLocked calculates default bounds (caller must hold lock)
 func (at *AutoTrader) calculateDefaultBoundsLocked(price float64, config *store.GridStrategyConfig) {
-	// Default: ±3% from current price, scaled by grid count
-	multiplier := 0.03 * float64(config.GridCount) / 10
-	at.gridState.UpperPrice = price * (1 + multiplier)
-	at.gridState.LowerPrice = price * (1 - multiplier)
+	at.gridState.UpperPrice, at.gridState.LowerPrice = strategy.GridDefaultBounds(price, config)
 }
 
 // calculateATRBoundsLocked calculates bounds using ATR (caller must hold lock)
@@ -1493,66 +1635,14 @@ func (at *AutoTrader) calculateATRBoundsLocked(price float64, mktData *market.Da
 	if mktData.LongerTermContext != nil {
 		atr = mktData.LongerTermContext.ATR14
 	}
-
-	if atr <= 0 {
-		at.calculateDefaultBoundsLocked(price, config)
-		return
-	}
-
-	multiplier := config.ATRMultiplier
-	if multiplier <= 0 {
-		multiplier = 2.0
-	}
-
-	halfRange := atr * multiplier
-	at.gridState.UpperPrice = price + halfRange
-	at.gridState.LowerPrice = price - halfRange
+	at.gridState.UpperPrice, at.gridState.LowerPrice = strategy.GridATRBounds(price, atr, config)
 }
 
 // initializeGridLevelsLocked creates the grid level structure (caller must hold lock)
 func (at *AutoTrader) initializeGridLevelsLocked(currentPrice float64, config *store.GridStrategyConfig) {
-	levels := make([]kernel.GridLevelInfo, config.GridCount)
-	totalWeight := 0.0
-	weights := make([]float64, config.GridCount)
-
-	// Calculate weights based on distribution
-	for i := 0; i < config.GridCount; i++ {
-		switch config.Distribution {
-		case "gaussian":
-			// Gaussian distribution - more weight in the middle
-			center := float64(config.GridCount-1) / 2
-			sigma := float64(config.GridCount) / 4
-			weights[i] = math.Exp(-math.Pow(float64(i)-center, 2) / (2 * sigma * sigma))
-		case "pyramid":
-			// Pyramid - more weight at bottom
-			weights[i] = float64(config.GridCount - i)
-		default: // uniform
-			weights[i] = 1.0
-		}
-		totalWeight += weights[i]
-	}
-
-	// Create levels
-	for i := 0; i < config.GridCount; i++ {
-		price := at.gridState.LowerPrice + float64(i)*at.gridState.GridSpacing
-		allocatedUSD := config.TotalInvestment * weights[i] / totalWeight
-
-		// Determine initial side (below current price = buy, above = sell)
-		side := "buy"
-		if price > currentPrice {
-			side = "sell"
-		}
-
-		levels[i] = kernel.GridLevelInfo{
-			Index:        i,
-			Price:        price,
-			State:        "empty",
-			Side:         side,
-			AllocatedUSD: allocatedUSD,
-		}
-	}
-
-	at.gridState.Levels = levels
+	// Level prices are snapped to the exchange tick size
+	spec := at.contractSpec(config.Symbol)
+	at.gridState.Levels = strategy.BuildGridLevels(at.gridState.LowerPrice, at.gridState.GridSpacing, currentPrice, config, spec)
 
 	// Apply direction-based side assignment if enabled (note: caller holds lock)
 	if config.EnableDirectionAdjust {
@@ -1562,76 +1652,7 @@ func (at *AutoTrader) initializeGridLevelsLocked(currentPrice float64, config *s
 
 // applyGridDirectionLocked adjusts grid level sides based on the current direction (caller must hold lock)
 func (at *AutoTrader) applyGridDirectionLocked(currentPrice float64) {
-	config := at.gridState.Config
-	direction := at.gridState.CurrentDirection
-
-	// Get bias ratio from config, default to 0.7 (70%/30%)
-	biasRatio := config.DirectionBiasRatio
-	if biasRatio <= 0 || biasRatio > 1 {
-		biasRatio = 0.7
-	}
-
-	buyRatio, _ := direction.GetBuySellRatio(biasRatio)
-
-	// For neutral: use price-based assignment (buy below, sell above)
-	if direction == market.GridDirectionNeutral {
-		for i := range at.gridState.Levels {
-			if at.gridState.Levels[i].Price <= currentPrice {
-				at.gridState.Levels[i].Side = "buy"
-			} else {
-				at.gridState.Levels[i].Side = "sell"
-			}
-		}
-		return
-	}
-
-	totalLevels := len(at.gridState.Levels)
-	targetBuyLevels := int(float64(totalLevels) * buyRatio)
-
-	switch direction {
-	case market.GridDirectionLong:
-		for i := range at.gridState.Levels {
-			at.gridState.Levels[i].Side = "buy"
-		}
-
-	case market.GridDirectionShort:
-		for i := range at.gridState.Levels {
-			at.gridState.Levels[i].Side = "sell"
-		}
-
-	case market.GridDirectionLongBias, market.GridDirectionShortBias:
-		buyCount := 0
-		sellCount := 0
-
-		for i := range at.gridState.Levels {
-			needMoreBuys := buyCount < targetBuyLevels
-			needMoreSells := sellCount < (totalLevels - targetBuyLevels)
-
-			if at.gridState.Levels[i].Price <= currentPrice {
-				if needMoreBuys {
-					at.gridState.Levels[i].Side = "buy"
-					buyCount++
-				} else {
-					at.gridState.Levels[i].Side = "sell"
-					sellCount++
-				}
-			} else {
-				if needMoreSells && direction == market.GridDirectionShortBias {
-					at.gridState.Levels[i].Side = "sell"
-					sellCount++
-				} else if needMoreBuys && direction == market.GridDirectionLongBias {
-					at.gridState.Levels[i].Side = "buy"
-					buyCount++
-				} else if needMoreSells {
-					at.gridState.Levels[i].Side = "sell"
-					sellCount++
-				} else {
-					at.gridState.Levels[i].Side = "buy"
-					buyCount++
-				}
-			}
-		}
-	}
+	strategy.ApplyGridDirection(at.gridState.Levels, at.gridState.CurrentDirection, at.gridState.Config.DirectionBiasRatio, currentPrice)
 }
 
 // GridRiskInfo contains risk information for frontend display
@@ -1664,17 +1685,46 @@ type GridRiskInfo struct {
 	CurrentGridDirection    string `json:"current_grid_direction"`
 	DirectionChangeCount    int    `json:"direction_change_count"`
 	EnableDirectionAdjust   bool   `json:"enable_direction_adjust"`
+
+	// Trailing grid: realised grid profit and inventory PnL kept apart
+	RealizedGridProfit     float64 `json:"realized_grid_profit"`
+	UnrealizedInventoryPnL float64 `json:"unrealized_inventory_pnl"`
+	BaseInventoryQty       float64 `json:"base_inventory_qty"`
+	TrailCount             int     `json:"trail_count"`
+
+	// Basket: the fields above describe the first symbol, Basket lists every symbol
+	Symbol string          `json:"symbol"`
+	Basket []*GridRiskInfo `json:"basket,omitempty"`
 }
 
 // GetGridRiskInfo returns current risk information for frontend display
+// For a basket the top-level fields describe the first symbol and Basket holds one entry per symbol
 func (at *AutoTrader) GetGridRiskInfo() *GridRiskInfo {
-	gridConfig := at.config.StrategyConfig.GridConfig
-	if gridConfig == nil {
+	states := at.gridBasketStates()
+	if at.config.StrategyConfig == nil || at.config.StrategyConfig.GridConfig == nil || len(states) == 0 {
 		return &GridRiskInfo{}
 	}
 
-	at.gridState.mu.RLock()
-	defer at.gridState.mu.RUnlock()
+	positions, _ := at.trader.GetPositions()
+	infos := make([]*GridRiskInfo, len(states))
+	for i, state := range states {
+		infos[i] = at.gridSymbolRiskInfo(state, positions)
+	}
+	if len(infos) == 1 {
+		return infos[0]
+	}
+
+	primary := *infos[0]
+	primary.Basket = infos
+	return &primary
+}
+
+// gridSymbolRiskInfo builds the risk information of one basket symbol
+func (at *AutoTrader) gridSymbolRiskInfo(state *GridState, positions []map[string]interface{}) *GridRiskInfo {
+	gridConfig := state.Config
+
+	state.mu.RLock()
+	defer state.mu.RUnlock()
 
 	// Get current price
 	currentPrice, _ := at.trader.GetMarketPrice(gridConfig.Symbol)
@@ -1684,7 +1734,6 @@ func (at *AutoTrader) GetGridRiskInfo() *GridRiskInfo {
 	leverage := gridConfig.Leverage
 
 	// Get current position value
-	positions, _ := at.trader.GetPositions()
 	var currentPositionValue float64
 	var currentPositionSize float64
 	for _, pos := range positions {
@@ -1703,7 +1752,7 @@ func (at *AutoTrader) GetGridRiskInfo() *GridRiskInfo {
 	}
 
 	// Calculate max position based on regime
-	regimeLevel := market.RegimeLevel(at.gridState.CurrentRegimeLevel)
+	regimeLevel := market.RegimeLevel(state.CurrentRegimeLevel)
 	if regimeLevel == "" {
 		regimeLevel = market.RegimeLevelStandard
 	}
@@ -1770,26 +1819,33 @@ func (at *AutoTrader) GetGridRiskInfo() *GridRiskInfo {
 
 		RegimeLevel: string(regimeLevel),
 
-		ShortBoxUpper: at.gridState.ShortBoxUpper,
-		ShortBoxLower: at.gridState.ShortBoxLower,
-		MidBoxUpper:   at.gridState.MidBoxUpper,
-		MidBoxLower:   at.gridState.MidBoxLower,
-		LongBoxUpper:  at.gridState.LongBoxUpper,
-		LongBoxLower:  at.gridState.LongBoxLower,
+		ShortBoxUpper: state.ShortBoxUpper,
+		ShortBoxLower: state.ShortBoxLower,
+		MidBoxUpper:   state.MidBoxUpper,
+		MidBoxLower:   state.MidBoxLower,
+		LongBoxUpper:  state.LongBoxUpper,
+		LongBoxLower:  state.LongBoxLower,
 		CurrentPrice:  currentPrice,
 
-		BreakoutLevel:     at.gridState.BreakoutLevel,
-		BreakoutDirection: at.gridState.BreakoutDirection,
+		BreakoutLevel:     state.BreakoutLevel,
+		BreakoutDirection: state.BreakoutDirection,
 
-		CurrentGridDirection:  string(at.gridState.CurrentDirection),
-		DirectionChangeCount:  at.gridState.DirectionChangeCount,
+		CurrentGridDirection:  string(state.CurrentDirection),
+		DirectionChangeCount:  state.DirectionChangeCount,
 		EnableDirectionAdjust: gridConfig.EnableDirectionAdjust,
+
+		RealizedGridProfit:     state.TotalProfit,
+		UnrealizedInventoryPnL: state.UnrealizedInventoryPnL,
+		BaseInventoryQty:       state.InventoryQty,
+		TrailCount:             state.TrailCount,
+
+		Symbol: gridConfig.Symbol,
 	}
 }
 
 // checkAndExecuteStopLoss checks if any filled level has exceeded stop loss and closes it
 func (at *AutoTrader) checkAndExecuteStopLoss() {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridConfig()
 	if gridConfig.StopLossPct <= 0 {
 		return // Stop loss not configured
 	}
@@ -1827,9 +1883,9 @@ func (at *AutoTrader) checkAndExecuteStopLoss() {
 			// Close the position
 			var closeErr error
 			if level.Side == "buy" {
-				_, closeErr = at.trader.CloseLong(gridConfig.Symbol, level.PositionSize)
+				_, closeErr = at.submitMarketOrder("close_long", gridConfig.Symbol, level.PositionSize, 0, at.clientOrderIDForCycle(gridConfig.Symbol, fmt.Sprintf("grid:sl:%d", i)))
 			} else {
-				_, closeErr = at.trader.CloseShort(gridConfig.Symbol, level.PositionSize)
+				_, closeErr = at.submitMarketOrder("close_short", gridConfig.Symbol, level.PositionSize, 0, at.clientOrderIDForCycle(gridConfig.Symbol, fmt.Sprintf("grid:sl:%d", i)))
 			}
 
 			if closeErr != nil {
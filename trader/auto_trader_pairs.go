@@ -0,0 +1,409 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"nofx/trader/strategy"
+	"strings"
+	"time"
+)
+
+// pairsLegState a leg's position as reported by the exchange
+type pairsLegState struct {
+	Quantity   float64
+	EntryPrice float64
+	MarkPrice  float64
+}
+
+// IsPairsStrategy returns true if current strategy is pairs / statistical arbitrage
+func (at *AutoTrader) IsPairsStrategy() bool {
+	if at.config.StrategyConfig == nil {
+		return false
+	}
+	return at.config.StrategyConfig.StrategyType == "pairs" && at.config.StrategyConfig.PairsConfig != nil
+}
+
+// pairsConfig returns the pairs trading configuration
+func (at *AutoTrader) pairsConfig() *store.PairsStrategyConfig {
+	return at.config.StrategyConfig.PairsConfig
+}
+
+// InitializePairs validates the config and reports a spread trade left open by a previous run
+func (at *AutoTrader) InitializePairs() error {
+	config := at.pairsConfig()
+	if err := strategy.NormalizePairsConfig(config); err != nil {
+		return err
+	}
+	if at.store == nil {
+		return fmt.Errorf("pairs trading requires a store to persist spread trades")
+	}
+	if config.AIConfirm && at.mcpClient == nil {
+		return fmt.Errorf("pairs ai_confirm requires an AI model")
+	}
+
+	trade, err := at.store.Pairs().GetOpenTrade(at.id)
+	if err != nil {
+		return fmt.Errorf("failed to load open pairs trade: %w", err)
+	}
+	logger.Infof("[Pairs] Trading %s / %s on %s, %d-bar window, entry ±%.2f / exit ±%.2f / stop ±%.2f",
+		config.SymbolA, config.SymbolB, config.Timeframe, config.LookbackBars, config.EntryZ, config.ExitZ, config.StopZ)
+	if trade != nil {
+		logger.Infof("[Pairs] Resuming %s trade #%d: %s %s / %s, hedge ratio %.4f, entry z %.2f",
+			trade.Status, trade.ID, trade.Direction, trade.SymbolA, trade.SymbolB, trade.HedgeRatio, trade.EntryZScore)
+	}
+	if config.StopLossPct > 0 {
+		logger.Infof("[Pairs] Shared stop at %.2f%% is checked every %v; no stop orders rest on the exchange", config.StopLossPct, at.config.ScanInterval)
+	}
+	return nil
+}
+
+// RunPairsCycle runs one pairs cycle: retry a half-open trade, or recompute the spread and then manage
+// the open trade or look for an entry
+func (at *AutoTrader) RunPairsCycle() error {
+	config := at.pairsConfig()
+	trade, err := at.store.Pairs().GetOpenTrade(at.id)
+	if err != nil {
+		return fmt.Errorf("failed to load open pairs trade: %w", err)
+	}
+	if trade != nil && trade.Status == store.PairsTradeUnwinding {
+		return at.closePairsTrade(trade, trade.CloseReason, trade.LastZScore)
+	}
+
+	stats, err := at.pairsSpreadStats()
+	if err != nil {
+		return err
+	}
+	if trade != nil {
+		reason := at.monitorPairsTrade(trade, stats)
+		if reason == "" {
+			return nil
+		}
+		return at.closePairsTrade(trade, reason, stats.ZScore)
+	}
+
+	direction := strategy.PairsEntrySignal(stats, config)
+	if direction == "" {
+		logger.Debugf("[Pairs] No signal: z %.2f, hedge ratio %.4f, correlation %.3f", stats.ZScore, stats.HedgeRatio, stats.Correlation)
+		return nil
+	}
+
+	reason := fmt.Sprintf("Spread z-score %.2f (hedge ratio %.4f, correlation %.3f)", stats.ZScore, stats.HedgeRatio, stats.Correlation)
+	var gate *kernel.FullDecision
+	if config.AIConfirm {
+		gate, err = at.askPairsEntryGate(stats, direction)
+		if err != nil {
+			return err
+		}
+		d := gate.Decisions[0]
+		if d.Action != kernel.PairsActionEnter {
+			at.saveStrategyDecisionRecord(gate, fmt.Sprintf("AI skipped %s signal (%s)", direction, d.Reasoning))
+			return nil
+		}
+		reason += ", AI: " + d.Reasoning
+	}
+	return at.openPairsTrade(direction, stats, reason, gate)
+}
+
+// pairsSpreadStats fetches both legs' klines and computes the rolling hedge ratio and z-score
+func (at *AutoTrader) pairsSpreadStats() (*strategy.PairsStats, error) {
+	config := at.pairsConfig()
+	tfDuration, err := market.TFDuration(config.Timeframe)
+	if err != nil {
+		return nil, err
+	}
+	end := time.Now()
+	start := end.Add(-tfDuration * time.Duration(config.LookbackBars+5))
+
+	klinesA, err := market.GetKlinesRange(config.SymbolA, config.Timeframe, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s klines: %w", config.SymbolA, err)
+	}
+	klinesB, err := market.GetKlinesRange(config.SymbolB, config.Timeframe, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s klines: %w", config.SymbolB, err)
+	}
+	closesA, closesB := strategy.AlignPairsCloses(klinesA, klinesB)
+	stats, ok := strategy.PairsSpreadStats(closesA, closesB, config.LookbackBars)
+	if !ok {
+		return nil, fmt.Errorf("not enough aligned %s bars for %s / %s (have %d, need %d)",
+			config.Timeframe, config.SymbolA, config.SymbolB, len(closesA), config.LookbackBars)
+	}
+	return stats, nil
+}
+
+// askPairsEntryGate asks the AI to confirm a spread entry
+func (at *AutoTrader) askPairsEntryGate(stats *strategy.PairsStats, direction string) (*kernel.FullDecision, error) {
+	config := at.pairsConfig()
+	lang := at.config.StrategyConfig.Language
+	if lang == "" {
+		lang = "en"
+	}
+
+	ctx := &kernel.PairsContext{
+		SymbolA:      config.SymbolA,
+		SymbolB:      config.SymbolB,
+		CurrentTime:  time.Now().Format("2006-01-02 15:04:05"),
+		Direction:    direction,
+		Timeframe:    config.Timeframe,
+		LookbackBars: config.LookbackBars,
+		HedgeRatio:   stats.HedgeRatio,
+		ZScore:       stats.ZScore,
+		Correlation:  stats.Correlation,
+	}
+	ctx.PriceA, _ = at.trader.GetMarketPrice(config.SymbolA)
+	ctx.PriceB, _ = at.trader.GetMarketPrice(config.SymbolB)
+	if balance, err := at.trader.GetBalance(); err == nil {
+		ctx.AvailableBalance, _ = balance["availableBalance"].(float64)
+	}
+	if trades, err := at.store.Pairs().ListTrades(at.id, 1); err == nil && len(trades) > 0 {
+		ctx.LastTradePnL = trades[0].RealizedPnL
+	}
+
+	return kernel.GetPairsEntryDecision(ctx, at.mcpClient, config, lang)
+}
+
+// openPairsTrade opens both legs at market and stores them as one spread trade
+// If leg B fails leg A is closed again so the account is never left with a naked leg
+func (at *AutoTrader) openPairsTrade(direction string, stats *strategy.PairsStats, reason string, gate *kernel.FullDecision) error {
+	config := at.pairsConfig()
+	priceA, err := at.trader.GetMarketPrice(config.SymbolA)
+	if err != nil {
+		return fmt.Errorf("failed to get %s price: %w", config.SymbolA, err)
+	}
+	priceB, err := at.trader.GetMarketPrice(config.SymbolB)
+	if err != nil {
+		return fmt.Errorf("failed to get %s price: %w", config.SymbolB, err)
+	}
+
+	specA, specB := at.contractSpec(config.SymbolA), at.contractSpec(config.SymbolB)
+	qtyA, qtyB := strategy.PairsLegQuantities(config.NotionalUSD, stats.HedgeRatio, priceA, priceB)
+	qtyA, qtyB = specA.FloorQty(qtyA), specB.FloorQty(qtyB)
+	if err := specA.CheckOrder(qtyA, priceA); err != nil {
+		return fmt.Errorf("leg %s below exchange minimum: %w", config.SymbolA, err)
+	}
+	if err := specB.CheckOrder(qtyB, priceB); err != nil {
+		return fmt.Errorf("leg %s below exchange minimum: %w", config.SymbolB, err)
+	}
+
+	trade := &store.PairsTrade{
+		TraderID:    at.id,
+		Status:      store.PairsTradeOpen,
+		Direction:   direction,
+		SymbolA:     config.SymbolA,
+		SymbolB:     config.SymbolB,
+		Leverage:    config.Leverage,
+		HedgeRatio:  stats.HedgeRatio,
+		EntryZScore: stats.ZScore,
+		LastZScore:  stats.ZScore,
+	}
+	if err := at.store.Pairs().Save(trade); err != nil {
+		return fmt.Errorf("failed to save pairs trade: %w", err)
+	}
+
+	for _, symbol := range []string{config.SymbolA, config.SymbolB} {
+		if err := at.trader.SetMarginMode(symbol, at.config.IsCrossMargin); err != nil {
+			logger.Infof("  ⚠️ Failed to set margin mode for %s: %v", symbol, err)
+		}
+	}
+
+	// The trade ID stands in for the cycle so client IDs stay stable across restarts
+	sideA, sideB := strategy.PairsLegSides(direction)
+	orderA, err := at.submitMarketOrder("open_"+sideA, config.SymbolA, qtyA, config.Leverage,
+		BuildClientOrderID(at.id, int(trade.ID), config.SymbolA, "pairs:a"))
+	if err != nil {
+		at.abortPairsTrade(trade)
+		return fmt.Errorf("failed to open %s leg: %w", config.SymbolA, err)
+	}
+	orderB, err := at.submitMarketOrder("open_"+sideB, config.SymbolB, qtyB, config.Leverage,
+		BuildClientOrderID(at.id, int(trade.ID), config.SymbolB, "pairs:b"))
+	if err != nil {
+		if _, closeErr := at.submitMarketOrder("close_"+sideA, config.SymbolA, qtyA, 0,
+			BuildClientOrderID(at.id, int(trade.ID), config.SymbolA, "pairs:a:close")); closeErr != nil {
+			// Keep the trade so the next cycle retries closing the unhedged leg
+			logger.Errorf("[Pairs] %s leg is unhedged and could not be closed, retrying next cycle: %v", config.SymbolA, closeErr)
+			trade.Status = store.PairsTradeUnwinding
+			trade.CloseReason = store.PairsCloseOpenFailed
+			trade.QuantityA, trade.EntryPriceA = qtyA, priceA
+			trade.EntryOrderIDA = fmt.Sprintf("%v", orderA["orderId"])
+			if err := at.store.Pairs().Save(trade); err != nil {
+				logger.Warnf("[Pairs] Failed to save trade #%d: %v", trade.ID, err)
+			}
+			return fmt.Errorf("failed to open %s leg: %w", config.SymbolB, err)
+		}
+		at.abortPairsTrade(trade)
+		return fmt.Errorf("failed to open %s leg: %w", config.SymbolB, err)
+	}
+
+	trade.QuantityA, trade.QuantityB = qtyA, qtyB
+	trade.EntryPriceA, trade.EntryPriceB = priceA, priceB
+	if legs, err := at.pairsLegs(trade); err == nil {
+		if legs[0].EntryPrice > 0 {
+			trade.EntryPriceA = legs[0].EntryPrice
+		}
+		if legs[1].EntryPrice > 0 {
+			trade.EntryPriceB = legs[1].EntryPrice
+		}
+	}
+	trade.EntryOrderIDA = fmt.Sprintf("%v", orderA["orderId"])
+	trade.EntryOrderIDB = fmt.Sprintf("%v", orderB["orderId"])
+	if err := at.store.Pairs().Save(trade); err != nil {
+		logger.Warnf("[Pairs] Failed to save trade #%d: %v", trade.ID, err)
+	}
+
+	logger.Infof("[Pairs] Trade #%d opened %s: %s %.6f %s / %s %.6f %s, z %.2f",
+		trade.ID, direction, sideA, qtyA, config.SymbolA, sideB, qtyB, config.SymbolB, stats.ZScore)
+	if gate == nil {
+		gate = &kernel.FullDecision{}
+	}
+	gate.Decisions = []kernel.Decision{
+		{Symbol: config.SymbolA, Action: "open_" + sideA, Leverage: config.Leverage, Price: trade.EntryPriceA, Quantity: qtyA, Reasoning: reason},
+		{Symbol: config.SymbolB, Action: "open_" + sideB, Leverage: config.Leverage, Price: trade.EntryPriceB, Quantity: qtyB, Reasoning: reason},
+	}
+	at.saveStrategyDecisionRecord(gate, fmt.Sprintf("Opened pairs trade #%d (%s)", trade.ID, direction))
+	return nil
+}
+
+// abortPairsTrade marks a trade that never got both legs on as closed
+// Only call it once neither leg is left open
+func (at *AutoTrader) abortPairsTrade(trade *store.PairsTrade) {
+	trade.Status = store.PairsTradeClosed
+	trade.CloseReason = store.PairsCloseOpenFailed
+	trade.ClosedAt = time.Now().UTC().UnixMilli()
+	if err := at.store.Pairs().Save(trade); err != nil {
+		logger.Warnf("[Pairs] Failed to save trade #%d: %v", trade.ID, err)
+	}
+}
+
+// monitorPairsTrade refreshes the combined PnL and z-score of the open trade and applies the shared stop
+// The stop is evaluated here, once per scan interval, with no stop orders on the exchange: a gap between
+// cycles can carry the loss past StopLossPct before both legs are closed
+// Returns the close reason when both legs should be closed, or "" to keep holding
+func (at *AutoTrader) monitorPairsTrade(trade *store.PairsTrade, stats *strategy.PairsStats) string {
+	config := at.pairsConfig()
+	legs, err := at.pairsLegs(trade)
+	if err != nil {
+		logger.Warnf("[Pairs] Trade #%d: failed to get positions: %v", trade.ID, err)
+		return ""
+	}
+	if legs[0].Quantity == 0 || legs[1].Quantity == 0 {
+		return store.PairsCloseLegGone
+	}
+
+	trade.PricePnL = pairsTradePnL(trade, legs[0].MarkPrice, legs[1].MarkPrice)
+	trade.LastZScore = stats.ZScore
+	if err := at.store.Pairs().Save(trade); err != nil {
+		logger.Warnf("[Pairs] Failed to save trade #%d: %v", trade.ID, err)
+	}
+
+	pnlPct := 0.0
+	if notional := trade.QuantityA*trade.EntryPriceA + trade.QuantityB*trade.EntryPriceB; notional > 0 {
+		pnlPct = trade.PricePnL / notional * 100
+	}
+	barsHeld := 0
+	if tfDuration, err := market.TFDuration(config.Timeframe); err == nil {
+		barsHeld = int(time.Since(time.UnixMilli(trade.OpenedAt)) / tfDuration)
+	}
+	return strategy.PairsExitReason(trade.Direction, stats.ZScore, pnlPct, barsHeld, config)
+}
+
+// closePairsTrade closes both legs and settles the trade's PnL
+// A leg that fails to close leaves the trade open so the next cycle retries it
+func (at *AutoTrader) closePairsTrade(trade *store.PairsTrade, reason string, z float64) error {
+	legs, err := at.pairsLegs(trade)
+	if err != nil {
+		return fmt.Errorf("failed to get positions: %w", err)
+	}
+	sideA, sideB := strategy.PairsLegSides(trade.Direction)
+	exits := []struct {
+		symbol string
+		side   string
+		tag    string
+		state  pairsLegState
+		exit   *float64
+	}{
+		{trade.SymbolA, sideA, "pairs:a:close", legs[0], &trade.ExitPriceA},
+		{trade.SymbolB, sideB, "pairs:b:close", legs[1], &trade.ExitPriceB},
+	}
+
+	for _, leg := range exits {
+		*leg.exit = leg.state.MarkPrice
+		if leg.state.Quantity == 0 {
+			continue
+		}
+		order, err := at.submitMarketOrder("close_"+leg.side, leg.symbol, 0, 0, // 0 = close all
+			BuildClientOrderID(at.id, int(trade.ID), leg.symbol, leg.tag))
+		if err != nil {
+			return fmt.Errorf("failed to close %s leg: %w", leg.symbol, err)
+		}
+		if err := at.store.Position().SetExitReason(at.id, orderResultID(order), "pairs_"+reason); err != nil {
+			logger.Warnf("[Pairs] Failed to label %s leg exit: %v", leg.symbol, err)
+		}
+	}
+
+	trade.RealizedPnL = pairsTradePnL(trade, trade.ExitPriceA, trade.ExitPriceB)
+	trade.PricePnL = trade.RealizedPnL
+	trade.LastZScore = z
+	trade.Status = store.PairsTradeClosed
+	trade.CloseReason = reason
+	trade.ClosedAt = time.Now().UTC().UnixMilli()
+	if err := at.store.Pairs().Save(trade); err != nil {
+		logger.Warnf("[Pairs] Failed to save trade #%d: %v", trade.ID, err)
+	}
+
+	logger.Infof("[Pairs] Trade #%d closed (%s) at z %.2f: PnL %.2f USDT", trade.ID, reason, z, trade.RealizedPnL)
+	reasoning := fmt.Sprintf("Closed pairs trade #%d: %s (z %.2f, entry z %.2f)", trade.ID, reason, z, trade.EntryZScore)
+	var decisions []kernel.Decision
+	if trade.QuantityA > 0 {
+		decisions = append(decisions, kernel.Decision{Symbol: trade.SymbolA, Action: "close_" + sideA, Price: trade.ExitPriceA, Quantity: trade.QuantityA, Reasoning: reasoning})
+	}
+	if trade.QuantityB > 0 {
+		decisions = append(decisions, kernel.Decision{Symbol: trade.SymbolB, Action: "close_" + sideB, Price: trade.ExitPriceB, Quantity: trade.QuantityB, Reasoning: reasoning})
+	}
+	at.saveStrategyDecisionRecord(&kernel.FullDecision{Decisions: decisions},
+		fmt.Sprintf("Closed pairs trade #%d, PnL %.2f USDT", trade.ID, trade.RealizedPnL))
+	return nil
+}
+
+// pairsLegs returns the exchange positions of legs A and B; a zero Quantity means the leg is flat
+func (at *AutoTrader) pairsLegs(trade *store.PairsTrade) ([2]pairsLegState, error) {
+	var legs [2]pairsLegState
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return legs, err
+	}
+	sideA, sideB := strategy.PairsLegSides(trade.Direction)
+	wanted := [2][2]string{{trade.SymbolA, sideA}, {trade.SymbolB, sideB}}
+	for _, pos := range positions {
+		sym, _ := pos["symbol"].(string)
+		posSide, _ := pos["side"].(string)
+		for i, w := range wanted {
+			if sym != w[0] || strings.ToLower(posSide) != w[1] {
+				continue
+			}
+			size, _ := pos["positionAmt"].(float64)
+			legs[i].Quantity = math.Abs(size)
+			legs[i].EntryPrice, _ = pos["entryPrice"].(float64)
+			legs[i].MarkPrice, _ = pos["markPrice"].(float64)
+		}
+	}
+	for i, w := range wanted {
+		if legs[i].MarkPrice <= 0 {
+			legs[i].MarkPrice, _ = at.trader.GetMarketPrice(w[0])
+		}
+	}
+	return legs, nil
+}
+
+// pairsTradePnL is the combined price PnL of both legs at the given prices
+func pairsTradePnL(trade *store.PairsTrade, priceA, priceB float64) float64 {
+	pnlA := (priceA - trade.EntryPriceA) * trade.QuantityA
+	pnlB := (priceB - trade.EntryPriceB) * trade.QuantityB
+	if trade.Direction == store.PairsShortSpread {
+		return -pnlA + pnlB
+	}
+	return pnlA - pnlB
+}
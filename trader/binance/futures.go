@@ -8,11 +8,13 @@ import (
 	"nofx/hook"
 	"nofx/logger"
 	"nofx/trader/types"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/adshao/go-binance/v2/common"
 	"github.com/adshao/go-binance/v2/futures"
 )
 
@@ -44,6 +46,22 @@ func getBrOrderID() string {
 	return orderID
 }
 
+// binanceBrokerPrefix is prepended to every client order ID sent to Binance
+const binanceBrokerPrefix = "x-KzrpZaP9"
+
+// brokerClientOrderID maps a caller-supplied client order ID into Binance's broker format
+// The same input always yields the same ID, so the order can be looked up again after a timeout
+func brokerClientOrderID(clientOrderID string) string {
+	if clientOrderID == "" {
+		return getBrOrderID()
+	}
+	orderID := binanceBrokerPrefix + clientOrderID
+	if len(orderID) > 32 {
+		orderID = orderID[:32]
+	}
+	return orderID
+}
+
 // FuturesTrader Binance futures trader
 type FuturesTrader struct {
 	client *futures.Client
@@ -60,6 +78,11 @@ type FuturesTrader struct {
 
 	// Cache validity period (15 seconds)
 	cacheDuration time.Duration
+
+	// Exchange info cache (trading rules for every symbol, refreshed hourly)
+	cachedExchangeInfo     *futures.ExchangeInfo
+	exchangeInfoCacheTime  time.Time
+	exchangeInfoCacheMutex sync.Mutex
 }
 
 // NewFuturesTrader creates futures trader
@@ -316,6 +339,16 @@ func (t *FuturesTrader) SetLeverage(symbol string, leverage int) error {
 
 // OpenLong opens a long position
 func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, getBrOrderID())
+}
+
+// OpenLongWithClientID is OpenLong with a caller-supplied client order ID (retry-safe)
+func (t *FuturesTrader) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, brokerClientOrderID(clientOrderID))
+}
+
+// openLong opens a long position using the given Binance client order ID
+func (t *FuturesTrader) openLong(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	// First cancel all pending orders for this symbol (clean up old stop-loss and take-profit orders)
 	if err := t.CancelAllOrders(symbol); err != nil {
 		logger.Infof("  ⚠ Failed to cancel old pending orders (may not have any): %v", err)
@@ -352,7 +385,7 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 		PositionSide(futures.PositionSideTypeLong).
 		Type(futures.OrderTypeMarket).
 		Quantity(quantityStr).
-		NewClientOrderID(getBrOrderID()).
+		NewClientOrderID(clientOrderID).
 		Do(context.Background())
 
 	if err != nil {
@@ -366,11 +399,22 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 	result["orderId"] = order.OrderID
 	result["symbol"] = order.Symbol
 	result["status"] = order.Status
+	result["clientOrderId"] = order.ClientOrderID
 	return result, nil
 }
 
 // OpenShort opens a short position
 func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, getBrOrderID())
+}
+
+// OpenShortWithClientID is OpenShort with a caller-supplied client order ID (retry-safe)
+func (t *FuturesTrader) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, brokerClientOrderID(clientOrderID))
+}
+
+// openShort opens a short position using the given Binance client order ID
+func (t *FuturesTrader) openShort(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	// First cancel all pending orders for this symbol (clean up old stop-loss and take-profit orders)
 	if err := t.CancelAllOrders(symbol); err != nil {
 		logger.Infof("  ⚠ Failed to cancel old pending orders (may not have any): %v", err)
@@ -407,7 +451,7 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 		PositionSide(futures.PositionSideTypeShort).
 		Type(futures.OrderTypeMarket).
 		Quantity(quantityStr).
-		NewClientOrderID(getBrOrderID()).
+		NewClientOrderID(clientOrderID).
 		Do(context.Background())
 
 	if err != nil {
@@ -421,11 +465,22 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 	result["orderId"] = order.OrderID
 	result["symbol"] = order.Symbol
 	result["status"] = order.Status
+	result["clientOrderId"] = order.ClientOrderID
 	return result, nil
 }
 
 // CloseLong closes a long position
 func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, getBrOrderID())
+}
+
+// CloseLongWithClientID is CloseLong with a caller-supplied client order ID (retry-safe)
+func (t *FuturesTrader) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, brokerClientOrderID(clientOrderID))
+}
+
+// closeLong closes a long position using the given Binance client order ID
+func (t *FuturesTrader) closeLong(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	// If quantity is 0, get current position quantity
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -458,7 +513,7 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 		PositionSide(futures.PositionSideTypeLong).
 		Type(futures.OrderTypeMarket).
 		Quantity(quantityStr).
-		NewClientOrderID(getBrOrderID()).
+		NewClientOrderID(clientOrderID).
 		Do(context.Background())
 
 	if err != nil {
@@ -476,11 +531,22 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 	result["orderId"] = order.OrderID
 	result["symbol"] = order.Symbol
 	result["status"] = order.Status
+	result["clientOrderId"] = order.ClientOrderID
 	return result, nil
 }
 
 // CloseShort closes a short position
 func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, getBrOrderID())
+}
+
+// CloseShortWithClientID is CloseShort with a caller-supplied client order ID (retry-safe)
+func (t *FuturesTrader) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, brokerClientOrderID(clientOrderID))
+}
+
+// closeShort closes a short position using the given Binance client order ID
+func (t *FuturesTrader) closeShort(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	// If quantity is 0, get current position quantity
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -513,7 +579,7 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 		PositionSide(futures.PositionSideTypeShort).
 		Type(futures.OrderTypeMarket).
 		Quantity(quantityStr).
-		NewClientOrderID(getBrOrderID()).
+		NewClientOrderID(clientOrderID).
 		Do(context.Background())
 
 	if err != nil {
@@ -531,6 +597,7 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 	result["orderId"] = order.OrderID
 	result["symbol"] = order.Symbol
 	result["status"] = order.Status
+	result["clientOrderId"] = order.ClientOrderID
 	return result, nil
 }
 
@@ -750,6 +817,27 @@ func (t *FuturesTrader) PlaceLimitOrder(req *types.LimitOrderRequest) (*types.Li
 		side = futures.SideTypeSell
 		positionSide = futures.PositionSideTypeShort
 	}
+	// Hedge mode has no reduceOnly flag: a reduce-only order trades against the opposite position side
+	if req.ReduceOnly {
+		if side == futures.SideTypeBuy {
+			positionSide = futures.PositionSideTypeShort
+		} else {
+			positionSide = futures.PositionSideTypeLong
+		}
+	}
+	// Explicit position side wins (e.g. SELL on LONG = reduce-only take-profit in hedge mode)
+	switch strings.ToUpper(req.PositionSide) {
+	case "LONG":
+		positionSide = futures.PositionSideTypeLong
+	case "SHORT":
+		positionSide = futures.PositionSideTypeShort
+	}
+
+	// GTX = post-only, rejected instead of filling as taker
+	timeInForce := futures.TimeInForceTypeGTC
+	if req.PostOnly {
+		timeInForce = futures.TimeInForceTypeGTX
+	}
 
 	// Build order service with broker ID
 	orderService := t.client.NewCreateOrderService().
@@ -757,10 +845,10 @@ func (t *FuturesTrader) PlaceLimitOrder(req *types.LimitOrderRequest) (*types.Li
 		Side(side).
 		PositionSide(positionSide).
 		Type(futures.OrderTypeLimit).
-		TimeInForce(futures.TimeInForceTypeGTC).
+		TimeInForce(timeInForce).
 		Quantity(quantityStr).
 		Price(priceStr).
-		NewClientOrderID(getBrOrderID())
+		NewClientOrderID(brokerClientOrderID(req.ClientID))
 
 	// Execute order
 	order, err := orderService.Do(context.Background())
@@ -924,6 +1012,8 @@ func (t *FuturesTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error)
 			StopPrice:    stopPrice,
 			Quantity:     quantity,
 			Status:       string(order.Status),
+
+			ClientOrderID: strings.TrimPrefix(order.ClientOrderID, binanceBrokerPrefix),
 		})
 	}
 
@@ -1049,6 +1139,75 @@ func (t *FuturesTrader) SetTakeProfit(symbol string, positionSide string, quanti
 	return nil
 }
 
+// SetTrailingStop sets a native TRAILING_STOP_MARKET order using the Algo Order API
+// Replaces any existing trailing stop for the same position side
+func (t *FuturesTrader) SetTrailingStop(symbol string, positionSide string, quantity, callbackRate, activationPrice float64) error {
+	var side futures.SideType
+	var posSide futures.PositionSideType
+
+	if positionSide == "LONG" {
+		side = futures.SideTypeSell
+		posSide = futures.PositionSideTypeLong
+	} else {
+		side = futures.SideTypeBuy
+		posSide = futures.PositionSideTypeShort
+	}
+
+	// Binance accepts callback rates between 0.1% and 10%
+	if callbackRate < 0.1 || callbackRate > 10 {
+		return fmt.Errorf("callback rate %.2f%% out of range (0.1-10)", callbackRate)
+	}
+
+	// Trailing stops don't support closePosition, quantity is required
+	quantityStr, err := t.FormatQuantity(symbol, quantity)
+	if err != nil {
+		return err
+	}
+
+	t.cancelTrailingStopOrders(symbol, posSide)
+
+	service := t.client.NewCreateAlgoOrderService().
+		Symbol(symbol).
+		Side(side).
+		PositionSide(posSide).
+		Type(futures.AlgoOrderTypeTrailingStopMarket).
+		Quantity(quantityStr).
+		CallbackRate(fmt.Sprintf("%.1f", callbackRate)).
+		WorkingType(futures.WorkingTypeContractPrice).
+		ClientAlgoId(getBrOrderID())
+	if activationPrice > 0 {
+		service = service.ActivationPrice(fmt.Sprintf("%.8f", activationPrice))
+	}
+
+	if _, err := service.Do(context.Background()); err != nil {
+		return fmt.Errorf("failed to set trailing stop: %w", err)
+	}
+
+	logger.Infof("  Trailing stop set (Algo Order): callback %.1f%%, activation %.4f", callbackRate, activationPrice)
+	return nil
+}
+
+// cancelTrailingStopOrders cancels open trailing stop algo orders for one position side
+func (t *FuturesTrader) cancelTrailingStopOrders(symbol string, posSide futures.PositionSideType) {
+	algoOrders, err := t.client.NewListOpenAlgoOrdersService().
+		Symbol(symbol).
+		Do(context.Background())
+	if err != nil {
+		return
+	}
+
+	for _, algoOrder := range algoOrders {
+		if algoOrder.OrderType != futures.AlgoOrderTypeTrailingStopMarket || algoOrder.PositionSide != posSide {
+			continue
+		}
+		if _, err := t.client.NewCancelAlgoOrderService().
+			AlgoID(algoOrder.AlgoId).
+			Do(context.Background()); err != nil {
+			logger.Infof("  ⚠ Failed to cancel trailing stop (Algo ID: %d): %v", algoOrder.AlgoId, err)
+		}
+	}
+}
+
 // GetMinNotional gets minimum notional value (Binance requirement)
 func (t *FuturesTrader) GetMinNotional(symbol string) float64 {
 	// Use conservative default value of 10 USDT to ensure order passes exchange validation
@@ -1075,9 +1234,26 @@ func (t *FuturesTrader) CheckMinNotional(symbol string, quantity float64) error
 	return nil
 }
 
+// getExchangeInfo returns the trading rules of every symbol, fetched at most once an hour
+func (t *FuturesTrader) getExchangeInfo() (*futures.ExchangeInfo, error) {
+	t.exchangeInfoCacheMutex.Lock()
+	defer t.exchangeInfoCacheMutex.Unlock()
+
+	if t.cachedExchangeInfo != nil && time.Since(t.exchangeInfoCacheTime) < time.Hour {
+		return t.cachedExchangeInfo, nil
+	}
+	exchangeInfo, err := t.client.NewExchangeInfoService().Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	t.cachedExchangeInfo = exchangeInfo
+	t.exchangeInfoCacheTime = time.Now()
+	return exchangeInfo, nil
+}
+
 // GetSymbolPrecision gets the quantity precision for a trading pair
 func (t *FuturesTrader) GetSymbolPrecision(symbol string) (int, error) {
-	exchangeInfo, err := t.client.NewExchangeInfoService().Do(context.Background())
+	exchangeInfo, err := t.getExchangeInfo()
 	if err != nil {
 		return 0, fmt.Errorf("failed to get trading rules: %w", err)
 	}
@@ -1157,7 +1333,7 @@ func (t *FuturesTrader) FormatQuantity(symbol string, quantity float64) (string,
 
 // GetSymbolPricePrecision gets the price precision for a trading pair
 func (t *FuturesTrader) GetSymbolPricePrecision(symbol string) (int, error) {
-	exchangeInfo, err := t.client.NewExchangeInfoService().Do(context.Background())
+	exchangeInfo, err := t.getExchangeInfo()
 	if err != nil {
 		return 0, fmt.Errorf("failed to get trading rules: %w", err)
 	}
@@ -1179,6 +1355,68 @@ func (t *FuturesTrader) GetSymbolPricePrecision(symbol string) (int, error) {
 	return 2, nil
 }
 
+// GetContractSpec gets tick size, lot step, minimums and leverage brackets for a trading pair
+func (t *FuturesTrader) GetContractSpec(symbol string) (*types.ContractSpec, error) {
+	exchangeInfo, err := t.getExchangeInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading rules: %w", err)
+	}
+
+	var spec *types.ContractSpec
+	for i := range exchangeInfo.Symbols {
+		s := &exchangeInfo.Symbols[i]
+		if s.Symbol != symbol {
+			continue
+		}
+		spec = &types.ContractSpec{
+			Symbol:             symbol,
+			Exchange:           "binance",
+			ContractMultiplier: 1,
+			UpdatedAt:          time.Now(),
+		}
+		if f := s.PriceFilter(); f != nil {
+			spec.TickSize, _ = strconv.ParseFloat(f.TickSize, 64)
+		}
+		if f := s.LotSizeFilter(); f != nil {
+			spec.QtyStep, _ = strconv.ParseFloat(f.StepSize, 64)
+			spec.MinQty, _ = strconv.ParseFloat(f.MinQuantity, 64)
+		}
+		if f := s.MinNotionalFilter(); f != nil {
+			spec.MinNotional, _ = strconv.ParseFloat(f.Notional, 64)
+		}
+		break
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("symbol %s not found in exchange info", symbol)
+	}
+
+	// Leverage brackets require a signed request, spec is still usable without them
+	brackets, err := t.client.NewGetLeverageBracketService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		logger.Infof("  ⚠ Failed to get %s leverage brackets: %v", symbol, err)
+		return spec, nil
+	}
+	for _, lb := range brackets {
+		if lb.Symbol != symbol {
+			continue
+		}
+		for _, b := range lb.Brackets {
+			spec.LeverageBrackets = append(spec.LeverageBrackets, types.LeverageBracket{
+				NotionalCap:     b.NotionalCap,
+				MaxLeverage:     b.InitialLeverage,
+				MaintMarginRate: b.MaintMarginRatio,
+			})
+		}
+	}
+	sort.Slice(spec.LeverageBrackets, func(i, j int) bool {
+		return spec.LeverageBrackets[i].NotionalCap < spec.LeverageBrackets[j].NotionalCap
+	})
+	if len(spec.LeverageBrackets) > 0 {
+		spec.MaxLeverage = spec.LeverageBrackets[0].MaxLeverage
+	}
+	return spec, nil
+}
+
 // FormatPrice formats price to correct precision
 func (t *FuturesTrader) FormatPrice(symbol string, price float64) (string, error) {
 	precision, err := t.GetSymbolPricePrecision(symbol)
@@ -1205,6 +1443,34 @@ func stringContains(s, substr string) bool {
 	return false
 }
 
+// GetOrderByClientID looks up an order by the client order ID passed to *WithClientID
+// Returns nil, nil if Binance has no order with this client ID
+func (t *FuturesTrader) GetOrderByClientID(symbol, clientOrderID string) (map[string]interface{}, error) {
+	order, err := t.client.NewGetOrderService().
+		Symbol(symbol).
+		OrigClientOrderID(brokerClientOrderID(clientOrderID)).
+		Do(context.Background())
+	if err != nil {
+		// -2013: Order does not exist
+		if apiErr, ok := err.(*common.APIError); ok && apiErr.Code == -2013 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order by client ID: %w", err)
+	}
+
+	avgPrice, _ := strconv.ParseFloat(order.AvgPrice, 64)
+	executedQty, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+
+	return map[string]interface{}{
+		"orderId":       order.OrderID,
+		"clientOrderId": order.ClientOrderID,
+		"symbol":        order.Symbol,
+		"status":        string(order.Status),
+		"avgPrice":      avgPrice,
+		"executedQty":   executedQty,
+	}, nil
+}
+
 // GetOrderStatus gets order status
 func (t *FuturesTrader) GetOrderStatus(symbol string, orderID string) (map[string]interface{}, error) {
 	// Convert orderID to int64
@@ -1374,6 +1640,7 @@ func (t *FuturesTrader) GetTradesForSymbol(symbol string, startTime time.Time, l
 
 		trade := types.TradeRecord{
 			TradeID:      strconv.FormatInt(at.ID, 10),
+			OrderID:      strconv.FormatInt(at.OrderID, 10),
 			Symbol:       at.Symbol,
 			Side:         string(at.Side),
 			PositionSide: string(at.PositionSide),
@@ -1417,6 +1684,7 @@ func (t *FuturesTrader) GetTradesForSymbolFromID(symbol string, fromID int64, li
 
 		trade := types.TradeRecord{
 			TradeID:      strconv.FormatInt(at.ID, 10),
+			OrderID:      strconv.FormatInt(at.OrderID, 10),
 			Symbol:       at.Symbol,
 			Side:         string(at.Side),
 			PositionSide: string(at.PositionSide),
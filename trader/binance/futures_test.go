@@ -3,8 +3,11 @@ package binance
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -313,6 +316,69 @@ func TestFuturesTrader_CommonInterface(t *testing.T) {
 	suite.RunAllTests()
 }
 
+// TestFuturesTrader_GridTraderConformance runs the GridTrader conformance suite against a mock Binance server
+// Hedge mode has no reduceOnly flag, so a reduce-only order shows up as trading against the opposite position side
+func TestFuturesTrader_GridTraderConformance(t *testing.T) {
+	recorder := &testutil.GridMockRecorder{}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var respBody interface{}
+		// go-binance sends signed parameters in the body for POST and DELETE alike
+		body, _ := io.ReadAll(r.Body)
+		r.Form, _ = url.ParseQuery(string(body))
+		for k, v := range r.URL.Query() {
+			r.Form[k] = v
+		}
+
+		switch {
+		case r.URL.Path == "/fapi/v1/order" && r.Method == "POST":
+			side, positionSide := r.Form.Get("side"), r.Form.Get("positionSide")
+			price, _ := strconv.ParseFloat(r.Form.Get("price"), 64)
+			recorder.RecordOrder(testutil.GridOrderCapture{
+				Side:       side,
+				Price:      price,
+				PostOnly:   r.Form.Get("timeInForce") == "GTX",
+				ReduceOnly: (side == "SELL" && positionSide == "LONG") || (side == "BUY" && positionSide == "SHORT"),
+				ClientID:   strings.TrimPrefix(r.Form.Get("newClientOrderId"), "x-KzrpZaP9"),
+			})
+			respBody = map[string]interface{}{
+				"orderId":       900001,
+				"clientOrderId": r.Form.Get("newClientOrderId"),
+				"symbol":        r.Form.Get("symbol"),
+				"side":          side,
+				"positionSide":  positionSide,
+				"status":        "NEW",
+			}
+
+		case r.URL.Path == "/fapi/v1/order" && r.Method == "DELETE":
+			recorder.RecordCancel(r.Form.Get("orderId"))
+			respBody = map[string]interface{}{"orderId": 900001, "status": "CANCELED"}
+
+		case r.URL.Path == "/fapi/v1/depth":
+			respBody = map[string]interface{}{
+				"lastUpdateId": 1,
+				"bids":         testutil.GridMockBids,
+				"asks":         testutil.GridMockAsks,
+			}
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(respBody)
+	}))
+	defer mockServer.Close()
+
+	client := futures.NewClient("test_api_key", "test_secret_key")
+	client.BaseURL = mockServer.URL
+	client.HTTPClient = mockServer.Client()
+
+	trader := &FuturesTrader{client: client}
+	testutil.NewGridTraderTestSuite(t, trader, recorder, 0.05).RunAllTests()
+}
+
 // ============================================================
 // 3. Binance Futures specific unit tests
 // ============================================================
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"nofx/logger"
 	"strconv"
@@ -41,6 +42,7 @@ type BitgetTrader struct {
 
 	// HTTP client
 	httpClient *http.Client
+	baseURL    string // REST endpoint (bitgetBaseURL, overridden by tests)
 
 	// Balance cache
 	cachedBalance     map[string]interface{}
@@ -71,6 +73,9 @@ type BitgetContract struct {
 	SizeMultiplier float64 // Contract size multiplier
 	PricePlace   int     // Price decimal places
 	VolumePlace  int     // Volume decimal places
+	PriceEndStep int     // Price step in units of the last decimal place
+	MinTradeUSDT float64 // Minimum order value
+	MaxLever     int     // Maximum leverage
 }
 
 // BitgetResponse Bitget API response
@@ -93,6 +98,7 @@ func NewBitgetTrader(apiKey, secretKey, passphrase string) *BitgetTrader {
 		secretKey:      secretKey,
 		passphrase:     passphrase,
 		httpClient:     httpClient,
+		baseURL:        bitgetBaseURL,
 		cacheDuration:  15 * time.Second,
 		contractsCache: make(map[string]*BitgetContract),
 	}
@@ -171,7 +177,7 @@ func (t *BitgetTrader) doRequest(method, path string, body interface{}) ([]byte,
 	}
 	signature := t.sign(timestamp, method, path, signBody)
 
-	url := bitgetBaseURL + path
+	url := t.baseURL + path
 	req, err := http.NewRequest(method, url, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -391,6 +397,9 @@ func (t *BitgetTrader) getContract(symbol string) (*BitgetContract, error) {
 		SizeMultiplier string `json:"sizeMultiplier"`
 		PricePlace     string `json:"pricePlace"`
 		VolumePlace    string `json:"volumePlace"`
+		PriceEndStep   string `json:"priceEndStep"`
+		MinTradeUSDT   string `json:"minTradeUSDT"`
+		MaxLever       string `json:"maxLever"`
 	}
 
 	if err := json.Unmarshal(data, &contracts); err != nil {
@@ -405,6 +414,9 @@ func (t *BitgetTrader) getContract(symbol string) (*BitgetContract, error) {
 			sizeMult, _ := strconv.ParseFloat(c.SizeMultiplier, 64)
 			pricePlace, _ := strconv.Atoi(c.PricePlace)
 			volumePlace, _ := strconv.Atoi(c.VolumePlace)
+			priceEndStep, _ := strconv.Atoi(c.PriceEndStep)
+			minTradeUSDT, _ := strconv.ParseFloat(c.MinTradeUSDT, 64)
+			maxLever, _ := strconv.Atoi(c.MaxLever)
 
 			contract := &BitgetContract{
 				Symbol:         c.Symbol,
@@ -415,6 +427,9 @@ func (t *BitgetTrader) getContract(symbol string) (*BitgetContract, error) {
 				SizeMultiplier: sizeMult,
 				PricePlace:     pricePlace,
 				VolumePlace:    volumePlace,
+				PriceEndStep:   priceEndStep,
+				MinTradeUSDT:   minTradeUSDT,
+				MaxLever:       maxLever,
 			}
 
 			// Update cache
@@ -430,6 +445,34 @@ func (t *BitgetTrader) getContract(symbol string) (*BitgetContract, error) {
 	return nil, fmt.Errorf("contract info not found: %s", symbol)
 }
 
+// GetContractSpec gets tick size, lot step, minimums and max leverage for symbol
+func (t *BitgetTrader) GetContractSpec(symbol string) (*types.ContractSpec, error) {
+	contract, err := t.getContract(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	priceStep := contract.PriceEndStep
+	if priceStep <= 0 {
+		priceStep = 1
+	}
+	qtyStep := contract.SizeMultiplier
+	if qtyStep <= 0 {
+		qtyStep = math.Pow(10, -float64(contract.VolumePlace))
+	}
+	return &types.ContractSpec{
+		Symbol:             symbol,
+		Exchange:           "bitget",
+		TickSize:           float64(priceStep) * math.Pow(10, -float64(contract.PricePlace)),
+		QtyStep:            qtyStep,
+		MinQty:             contract.MinTradeNum,
+		MinNotional:        contract.MinTradeUSDT,
+		MaxLeverage:        contract.MaxLever,
+		ContractMultiplier: 1,
+		UpdatedAt:          time.Now(),
+	}, nil
+}
+
 // SetMarginMode sets margin mode
 func (t *BitgetTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
 	symbol = t.convertSymbol(symbol)
@@ -488,6 +531,16 @@ func (t *BitgetTrader) SetLeverage(symbol string, leverage int) error {
 
 // OpenLong opens long position
 func (t *BitgetTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, "")
+}
+
+// OpenLongWithClientID is OpenLong with a caller-supplied client order ID (retry-safe)
+func (t *BitgetTrader) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, clientOrderID)
+}
+
+// openLong opens a long position using the given Bitget client order ID (empty for none)
+func (t *BitgetTrader) openLong(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	symbol = t.convertSymbol(symbol)
 
 	// Cancel old orders first
@@ -514,6 +567,10 @@ func (t *BitgetTrader) OpenLong(symbol string, quantity float64, leverage int) (
 
 	logger.Infof("  📊 Bitget OpenLong: symbol=%s, qty=%s, leverage=%d", symbol, qtyStr, leverage)
 
+	if clientOrderID != "" {
+		body["clientOid"] = clientOrderID
+	}
+
 	data, err := t.doRequest("POST", bitgetOrderPath, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open long position: %w", err)
@@ -542,6 +599,16 @@ func (t *BitgetTrader) OpenLong(symbol string, quantity float64, leverage int) (
 
 // OpenShort opens short position
 func (t *BitgetTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, "")
+}
+
+// OpenShortWithClientID is OpenShort with a caller-supplied client order ID (retry-safe)
+func (t *BitgetTrader) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, clientOrderID)
+}
+
+// openShort opens a short position using the given Bitget client order ID (empty for none)
+func (t *BitgetTrader) openShort(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	symbol = t.convertSymbol(symbol)
 
 	// Cancel old orders first
@@ -568,6 +635,10 @@ func (t *BitgetTrader) OpenShort(symbol string, quantity float64, leverage int)
 
 	logger.Infof("  📊 Bitget OpenShort: symbol=%s, qty=%s, leverage=%d", symbol, qtyStr, leverage)
 
+	if clientOrderID != "" {
+		body["clientOid"] = clientOrderID
+	}
+
 	data, err := t.doRequest("POST", bitgetOrderPath, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open short position: %w", err)
@@ -596,6 +667,16 @@ func (t *BitgetTrader) OpenShort(symbol string, quantity float64, leverage int)
 
 // CloseLong closes long position
 func (t *BitgetTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, "")
+}
+
+// CloseLongWithClientID is CloseLong with a caller-supplied client order ID (retry-safe)
+func (t *BitgetTrader) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, clientOrderID)
+}
+
+// closeLong closes a long position using the given Bitget client order ID (empty for none)
+func (t *BitgetTrader) closeLong(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	symbol = t.convertSymbol(symbol)
 
 	// If quantity is 0, get current position
@@ -632,6 +713,10 @@ func (t *BitgetTrader) CloseLong(symbol string, quantity float64) (map[string]in
 
 	logger.Infof("  📊 Bitget CloseLong: symbol=%s, qty=%s", symbol, qtyStr)
 
+	if clientOrderID != "" {
+		body["clientOid"] = clientOrderID
+	}
+
 	data, err := t.doRequest("POST", bitgetOrderPath, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to close long position: %w", err)
@@ -659,6 +744,16 @@ func (t *BitgetTrader) CloseLong(symbol string, quantity float64) (map[string]in
 
 // CloseShort closes short position
 func (t *BitgetTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, "")
+}
+
+// CloseShortWithClientID is CloseShort with a caller-supplied client order ID (retry-safe)
+func (t *BitgetTrader) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, clientOrderID)
+}
+
+// closeShort closes a short position using the given Bitget client order ID (empty for none)
+func (t *BitgetTrader) closeShort(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	symbol = t.convertSymbol(symbol)
 
 	// If quantity is 0, get current position
@@ -700,6 +795,10 @@ func (t *BitgetTrader) CloseShort(symbol string, quantity float64) (map[string]i
 
 	logger.Infof("  📊 Bitget CloseShort: symbol=%s, qty=%s", symbol, qtyStr)
 
+	if clientOrderID != "" {
+		body["clientOid"] = clientOrderID
+	}
+
 	data, err := t.doRequest("POST", bitgetOrderPath, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to close short position: %w", err)
@@ -837,6 +936,64 @@ func (t *BitgetTrader) SetTakeProfit(symbol string, positionSide string, quantit
 	return nil
 }
 
+// SetTrailingStop sets a native trailing stop (track_plan plan order)
+// Bitget requires a trigger price for trailing plans, so the current price is used when trailing starts immediately
+func (t *BitgetTrader) SetTrailingStop(symbol string, positionSide string, quantity, callbackRate, activationPrice float64) error {
+	// Bitget accepts callback ratios up to 10%
+	if callbackRate <= 0 || callbackRate > 10 {
+		return fmt.Errorf("callback rate %.2f%% out of range (0-10)", callbackRate)
+	}
+
+	if activationPrice <= 0 {
+		price, err := t.GetMarketPrice(symbol)
+		if err != nil {
+			return err
+		}
+		activationPrice = price
+	}
+
+	// Replace any existing trailing stop
+	if err := t.cancelPlanOrders(symbol, "track_plan"); err != nil {
+		logger.Infof("  ⚠️ [Bitget] Failed to cancel existing trailing stops: %v", err)
+	}
+
+	symbol = t.convertSymbol(symbol)
+
+	side := "sell"
+	holdSide := "long"
+	if strings.ToUpper(positionSide) == "SHORT" {
+		side = "buy"
+		holdSide = "short"
+	}
+
+	qtyStr, _ := t.FormatQuantity(symbol, quantity)
+
+	body := map[string]interface{}{
+		"planType":      "track_plan",
+		"symbol":        symbol,
+		"productType":   "USDT-FUTURES",
+		"marginMode":    "crossed",
+		"marginCoin":    "USDT",
+		"triggerPrice":  fmt.Sprintf("%.8f", activationPrice),
+		"triggerType":   "mark_price",
+		"callbackRatio": fmt.Sprintf("%.2f", callbackRate),
+		"side":          side,
+		"tradeSide":     "close",
+		"orderType":     "market",
+		"size":          qtyStr,
+		"holdSide":      holdSide,
+		"clientOid":     genBitgetClientOid(),
+	}
+
+	_, err := t.doRequest("POST", "/api/v2/mix/order/place-plan-order", body)
+	if err != nil {
+		return fmt.Errorf("failed to set trailing stop: %w", err)
+	}
+
+	logger.Infof("  ✓ [Bitget] Trailing stop set: %s callback %.2f%% from %.4f", symbol, callbackRate, activationPrice)
+	return nil
+}
+
 // CancelStopLossOrders cancels stop loss orders
 func (t *BitgetTrader) CancelStopLossOrders(symbol string) error {
 	return t.cancelPlanOrders(symbol, "loss_plan")
@@ -949,6 +1106,60 @@ func (t *BitgetTrader) FormatQuantity(symbol string, quantity float64) (string,
 	return fmt.Sprintf(format, quantity), nil
 }
 
+// GetOrderByClientID looks up an order by the clientOid passed to *WithClientID
+// Returns nil, nil if Bitget has no such order
+func (t *BitgetTrader) GetOrderByClientID(symbol, clientOrderID string) (map[string]interface{}, error) {
+	symbol = t.convertSymbol(symbol)
+
+	params := map[string]interface{}{
+		"symbol":      symbol,
+		"productType": "USDT-FUTURES",
+		"clientOid":   clientOrderID,
+	}
+
+	data, err := t.doRequest("GET", "/api/v2/mix/order/detail", params)
+	if err != nil {
+		// 40109: order not found
+		if strings.Contains(err.Error(), "40109") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order by client ID: %w", err)
+	}
+
+	var order struct {
+		OrderId string `json:"orderId"`
+		State   string `json:"state"`
+	}
+	if err := json.Unmarshal(data, &order); err != nil {
+		return nil, err
+	}
+	if order.OrderId == "" {
+		return nil, nil
+	}
+
+	return map[string]interface{}{
+		"orderId":       order.OrderId,
+		"clientOrderId": clientOrderID,
+		"symbol":        symbol,
+		"status":        bitgetOrderStatus(order.State),
+	}, nil
+}
+
+// bitgetOrderStatus maps Bitget order states to the unified status names
+func bitgetOrderStatus(state string) string {
+	switch state {
+	case "filled":
+		return "FILLED"
+	case "new", "live":
+		return "NEW"
+	case "partially_filled":
+		return "PARTIALLY_FILLED"
+	case "canceled", "cancelled":
+		return "CANCELED"
+	}
+	return state
+}
+
 // GetOrderStatus gets order status
 func (t *BitgetTrader) GetOrderStatus(symbol string, orderID string) (map[string]interface{}, error) {
 	symbol = t.convertSymbol(symbol)
@@ -1117,6 +1328,7 @@ func (t *BitgetTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 		var orders struct {
 			EntrustedList []struct {
 				OrderId      string `json:"orderId"`
+				ClientOid    string `json:"clientOid"`
 				Symbol       string `json:"symbol"`
 				Side         string `json:"side"`         // buy/sell
 				TradeSide    string `json:"tradeSide"`    // open/close
@@ -1146,6 +1358,8 @@ func (t *BitgetTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 					StopPrice:    0,
 					Quantity:     quantity,
 					Status:       "NEW",
+
+					ClientOrderID: order.ClientOid,
 				})
 			}
 		}
@@ -1262,10 +1476,17 @@ func (t *BitgetTrader) PlaceLimitOrder(req *types.LimitOrderRequest) (*types.Lim
 		"clientOid":   genBitgetClientOid(),
 	}
 
+	// Post-only orders are rejected instead of filling as taker
+	if req.PostOnly {
+		body["force"] = "post_only"
+	}
 	// Add reduce only if specified
 	if req.ReduceOnly {
 		body["reduceOnly"] = "YES"
 	}
+	if req.ClientID != "" {
+		body["clientOid"] = req.ClientID
+	}
 
 	logger.Infof("[Bitget] PlaceLimitOrder: %s %s @ %.4f, qty=%s", symbol, side, req.Price, qtyStr)
 
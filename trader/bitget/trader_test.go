@@ -0,0 +1,87 @@
+package bitget
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"nofx/trader/testutil"
+)
+
+// newBitgetGridMockServer serves Bitget order, cancel and depth endpoints for the GridTrader conformance suite
+func newBitgetGridMockServer(recorder *testutil.GridMockRecorder) *httptest.Server {
+	book := func(levels [][2]string) [][]string {
+		var out [][]string
+		for _, level := range levels {
+			out = append(out, []string{level[0], level[1]})
+		}
+		return out
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data interface{}
+
+		switch r.URL.Path {
+		case bitgetOrderPath:
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			side := "BUY"
+			if body["side"] == "sell" {
+				side = "SELL"
+			}
+			price, _ := strconv.ParseFloat(body["price"].(string), 64)
+			clientOid, _ := body["clientOid"].(string)
+			recorder.RecordOrder(testutil.GridOrderCapture{
+				Side:       side,
+				Price:      price,
+				PostOnly:   body["force"] == "post_only",
+				ReduceOnly: body["reduceOnly"] == "YES",
+				ClientID:   clientOid,
+			})
+			data = map[string]string{"orderId": testutil.GridMockOrderID, "clientOid": clientOid}
+
+		case bitgetCancelOrderPath:
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			recorder.RecordCancel(body["orderId"].(string))
+			data = map[string]string{"orderId": body["orderId"].(string)}
+
+		case "/api/v2/mix/market/depth":
+			data = map[string]interface{}{
+				"bids": book(testutil.GridMockBids),
+				"asks": book(testutil.GridMockAsks),
+			}
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": "00000", "msg": "success", "data": data})
+	}))
+}
+
+// TestBitgetTrader_GridTraderConformance runs the GridTrader conformance suite against a mock Bitget server
+func TestBitgetTrader_GridTraderConformance(t *testing.T) {
+	recorder := &testutil.GridMockRecorder{}
+	mockServer := newBitgetGridMockServer(recorder)
+	defer mockServer.Close()
+
+	trader := &BitgetTrader{
+		apiKey:        "test_api_key",
+		secretKey:     "test_secret_key",
+		passphrase:    "test_passphrase",
+		httpClient:    mockServer.Client(),
+		baseURL:       mockServer.URL,
+		cacheDuration: 15 * time.Second,
+		contractsCache: map[string]*BitgetContract{
+			"BTCUSDT": {Symbol: "BTCUSDT", MinTradeNum: 0.001, SizeMultiplier: 0.001, PricePlace: 1, VolumePlace: 3, PriceEndStep: 1},
+		},
+		contractsCacheTime: time.Now(),
+	}
+
+	testutil.NewGridTraderTestSuite(t, trader, recorder, 0.05).RunAllTests()
+}
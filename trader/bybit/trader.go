@@ -11,6 +11,7 @@ import (
 	"math"
 	"net/http"
 	"nofx/logger"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -279,6 +280,16 @@ func (t *BybitTrader) GetPositions() ([]map[string]interface{}, error) {
 
 // OpenLong opens a long position
 func (t *BybitTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, "")
+}
+
+// OpenLongWithClientID is OpenLong with a caller-supplied orderLinkId (retry-safe)
+func (t *BybitTrader) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, clientOrderID)
+}
+
+// openLong opens a long position, tagging the order with orderLinkId when clientOrderID is set
+func (t *BybitTrader) openLong(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	logger.Infof("[Bybit] ===== OpenLong called: symbol=%s, qty=%.6f, leverage=%d =====", symbol, quantity, leverage)
 
 	// First cancel all pending orders for this symbol (clean up old orders)
@@ -307,6 +318,10 @@ func (t *BybitTrader) OpenLong(symbol string, quantity float64, leverage int) (m
 		"positionIdx": 0, // One-way position mode
 	}
 
+	if clientOrderID != "" {
+		params["orderLinkId"] = clientOrderID
+	}
+
 	logger.Infof("[Bybit] OpenLong placing order: %+v", params)
 
 	result, err := t.client.NewUtaBybitServiceWithParams(params).PlaceOrder(context.Background())
@@ -322,6 +337,16 @@ func (t *BybitTrader) OpenLong(symbol string, quantity float64, leverage int) (m
 
 // OpenShort opens a short position
 func (t *BybitTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, "")
+}
+
+// OpenShortWithClientID is OpenShort with a caller-supplied orderLinkId (retry-safe)
+func (t *BybitTrader) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, clientOrderID)
+}
+
+// openShort opens a short position, tagging the order with orderLinkId when clientOrderID is set
+func (t *BybitTrader) openShort(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	logger.Infof("[Bybit] ===== OpenShort called: symbol=%s, qty=%.6f, leverage=%d =====", symbol, quantity, leverage)
 
 	// First cancel all pending orders for this symbol (clean up old orders)
@@ -350,6 +375,10 @@ func (t *BybitTrader) OpenShort(symbol string, quantity float64, leverage int) (
 		"positionIdx": 0, // One-way position mode
 	}
 
+	if clientOrderID != "" {
+		params["orderLinkId"] = clientOrderID
+	}
+
 	logger.Infof("[Bybit] OpenShort placing order: %+v", params)
 
 	result, err := t.client.NewUtaBybitServiceWithParams(params).PlaceOrder(context.Background())
@@ -365,6 +394,16 @@ func (t *BybitTrader) OpenShort(symbol string, quantity float64, leverage int) (
 
 // CloseLong closes a long position
 func (t *BybitTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, "")
+}
+
+// CloseLongWithClientID is CloseLong with a caller-supplied orderLinkId (retry-safe)
+func (t *BybitTrader) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, clientOrderID)
+}
+
+// closeLong closes a long position, tagging the order with orderLinkId when clientOrderID is set
+func (t *BybitTrader) closeLong(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	// If quantity = 0, get current position quantity
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -396,6 +435,9 @@ func (t *BybitTrader) CloseLong(symbol string, quantity float64) (map[string]int
 		"positionIdx": 0,
 		"reduceOnly":  true,
 	}
+	if clientOrderID != "" {
+		params["orderLinkId"] = clientOrderID
+	}
 
 	result, err := t.client.NewUtaBybitServiceWithParams(params).PlaceOrder(context.Background())
 	if err != nil {
@@ -410,6 +452,16 @@ func (t *BybitTrader) CloseLong(symbol string, quantity float64) (map[string]int
 
 // CloseShort closes a short position
 func (t *BybitTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, "")
+}
+
+// CloseShortWithClientID is CloseShort with a caller-supplied orderLinkId (retry-safe)
+func (t *BybitTrader) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, clientOrderID)
+}
+
+// closeShort closes a short position, tagging the order with orderLinkId when clientOrderID is set
+func (t *BybitTrader) closeShort(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	// If quantity = 0, get current position quantity
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -441,6 +493,9 @@ func (t *BybitTrader) CloseShort(symbol string, quantity float64) (map[string]in
 		"positionIdx": 0,
 		"reduceOnly":  true,
 	}
+	if clientOrderID != "" {
+		params["orderLinkId"] = clientOrderID
+	}
 
 	result, err := t.client.NewUtaBybitServiceWithParams(params).PlaceOrder(context.Background())
 	if err != nil {
@@ -635,6 +690,48 @@ func (t *BybitTrader) SetTakeProfit(symbol string, positionSide string, quantity
 	return nil
 }
 
+// SetTrailingStop sets a native trailing stop on the position via /v5/position/trading-stop
+// Bybit takes the trailing distance as a price offset, so callbackRate is converted using the
+// activation price (or the current price when trailing starts immediately)
+func (t *BybitTrader) SetTrailingStop(symbol string, positionSide string, quantity, callbackRate, activationPrice float64) error {
+	if callbackRate <= 0 {
+		return fmt.Errorf("callback rate must be greater than 0")
+	}
+
+	refPrice := activationPrice
+	if refPrice <= 0 {
+		price, err := t.GetMarketPrice(symbol)
+		if err != nil {
+			return err
+		}
+		refPrice = price
+	}
+	distance := refPrice * callbackRate / 100
+
+	params := map[string]interface{}{
+		"category":     "linear",
+		"symbol":       symbol,
+		"tpslMode":     "Full",
+		"trailingStop": strconv.FormatFloat(distance, 'f', -1, 64),
+		"positionIdx":  0, // One-way position mode
+	}
+	if activationPrice > 0 {
+		params["activePrice"] = fmt.Sprintf("%v", activationPrice)
+	}
+
+	result, err := t.client.NewUtaBybitServiceWithParams(params).SetPositionTradingStop(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to set trailing stop: %w", err)
+	}
+
+	if result.RetCode != 0 {
+		return fmt.Errorf("failed to set trailing stop: %s", result.RetMsg)
+	}
+
+	logger.Infof("  ✓ [Bybit] Trailing stop set: %s distance %.4f (%.2f%%)", symbol, distance, callbackRate)
+	return nil
+}
+
 // CancelStopLossOrders cancels stop loss orders
 func (t *BybitTrader) CancelStopLossOrders(symbol string) error {
 	return t.cancelConditionalOrders(symbol, "StopLoss")
@@ -729,6 +826,96 @@ func (t *BybitTrader) getQtyStep(symbol string) float64 {
 	return qtyStep
 }
 
+// GetContractSpec gets tick size, lot step, minimums and risk-limit leverage tiers
+func (t *BybitTrader) GetContractSpec(symbol string) (*types.ContractSpec, error) {
+	var info struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			List []struct {
+				PriceFilter struct {
+					TickSize string `json:"tickSize"`
+				} `json:"priceFilter"`
+				LotSizeFilter struct {
+					QtyStep          string `json:"qtyStep"`
+					MinOrderQty      string `json:"minOrderQty"`
+					MinNotionalValue string `json:"minNotionalValue"`
+				} `json:"lotSizeFilter"`
+				LeverageFilter struct {
+					MaxLeverage string `json:"maxLeverage"`
+				} `json:"leverageFilter"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := t.publicGet("/v5/market/instruments-info?category=linear&symbol="+symbol, &info); err != nil {
+		return nil, err
+	}
+	if info.RetCode != 0 || len(info.Result.List) == 0 {
+		return nil, fmt.Errorf("instrument info not found for %s: %s", symbol, info.RetMsg)
+	}
+
+	inst := info.Result.List[0]
+	spec := &types.ContractSpec{
+		Symbol:             symbol,
+		Exchange:           "bybit",
+		ContractMultiplier: 1,
+		UpdatedAt:          time.Now(),
+	}
+	spec.TickSize, _ = strconv.ParseFloat(inst.PriceFilter.TickSize, 64)
+	spec.QtyStep, _ = strconv.ParseFloat(inst.LotSizeFilter.QtyStep, 64)
+	spec.MinQty, _ = strconv.ParseFloat(inst.LotSizeFilter.MinOrderQty, 64)
+	spec.MinNotional, _ = strconv.ParseFloat(inst.LotSizeFilter.MinNotionalValue, 64)
+	maxLeverage, _ := strconv.ParseFloat(inst.LeverageFilter.MaxLeverage, 64)
+	spec.MaxLeverage = int(maxLeverage)
+
+	var riskLimits struct {
+		RetCode int `json:"retCode"`
+		Result  struct {
+			List []struct {
+				RiskLimitValue    string `json:"riskLimitValue"`
+				MaintenanceMargin string `json:"maintenanceMargin"`
+				MaxLeverage       string `json:"maxLeverage"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := t.publicGet("/v5/market/risk-limit?category=linear&symbol="+symbol, &riskLimits); err != nil {
+		logger.Infof("⚠️ [Bybit] Failed to get risk limits for %s: %v", symbol, err)
+		return spec, nil
+	}
+	for _, rl := range riskLimits.Result.List {
+		limitValue, _ := strconv.ParseFloat(rl.RiskLimitValue, 64)
+		mmr, _ := strconv.ParseFloat(rl.MaintenanceMargin, 64)
+		lev, _ := strconv.ParseFloat(rl.MaxLeverage, 64)
+		spec.LeverageBrackets = append(spec.LeverageBrackets, types.LeverageBracket{
+			NotionalCap:     limitValue,
+			MaxLeverage:     int(lev),
+			MaintMarginRate: mmr / 100, // Bybit reports percent
+		})
+	}
+	sort.Slice(spec.LeverageBrackets, func(i, j int) bool {
+		return spec.LeverageBrackets[i].NotionalCap < spec.LeverageBrackets[j].NotionalCap
+	})
+	return spec, nil
+}
+
+// publicGet calls a public market endpoint on the client's base URL and decodes the JSON body into out
+func (t *BybitTrader) publicGet(path string, out interface{}) error {
+	resp, err := t.client.HTTPClient.Get(t.client.BaseURL + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, out)
+}
+
 // FormatQuantity formats quantity
 func (t *BybitTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
 	// Get qtyStep for this symbol
@@ -776,10 +963,12 @@ func (t *BybitTrader) parseOrderResult(result *bybit.ServerResponse) (map[string
 	}
 
 	orderId, _ := resultData["orderId"].(string)
+	orderLinkId, _ := resultData["orderLinkId"].(string)
 
 	return map[string]interface{}{
-		"orderId": orderId,
-		"status":  "NEW",
+		"orderId":       orderId,
+		"clientOrderId": orderLinkId,
+		"status":        "NEW",
 	}, nil
 }
 
@@ -822,28 +1011,69 @@ func (t *BybitTrader) GetOrderStatus(symbol string, orderID string) (map[string]
 	executedQty, _ := strconv.ParseFloat(cumExecQtyStr, 64)
 	commission, _ := strconv.ParseFloat(cumExecFeeStr, 64)
 
-	// Convert status to unified format
-	unifiedStatus := status
-	switch status {
-	case "Filled":
-		unifiedStatus = "FILLED"
-	case "New", "Created":
-		unifiedStatus = "NEW"
-	case "Cancelled", "Rejected":
-		unifiedStatus = "CANCELED"
-	case "PartiallyFilled":
-		unifiedStatus = "PARTIALLY_FILLED"
-	}
-
 	return map[string]interface{}{
 		"orderId":     orderID,
-		"status":      unifiedStatus,
+		"status":      unifyOrderStatus(status),
 		"avgPrice":    avgPrice,
 		"executedQty": executedQty,
 		"commission":  commission,
 	}, nil
 }
 
+// GetOrderByClientID looks up an order by the orderLinkId passed to *WithClientID
+// Returns nil, nil if Bybit has no order with this orderLinkId
+func (t *BybitTrader) GetOrderByClientID(symbol, clientOrderID string) (map[string]interface{}, error) {
+	params := map[string]interface{}{
+		"category":    "linear",
+		"symbol":      symbol,
+		"orderLinkId": clientOrderID,
+	}
+
+	// Realtime endpoint covers active orders and recently closed ones
+	result, err := t.client.NewUtaBybitServiceWithParams(params).GetOpenOrders(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order by client ID: %w", err)
+	}
+	if result.RetCode != 0 {
+		return nil, fmt.Errorf("API error: %s", result.RetMsg)
+	}
+
+	resultData, ok := result.Result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("return format error")
+	}
+	list, _ := resultData["list"].([]interface{})
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	order, _ := list[0].(map[string]interface{})
+	orderID, _ := order["orderId"].(string)
+	status, _ := order["orderStatus"].(string)
+
+	return map[string]interface{}{
+		"orderId":       orderID,
+		"clientOrderId": clientOrderID,
+		"symbol":        symbol,
+		"status":        unifyOrderStatus(status),
+	}, nil
+}
+
+// unifyOrderStatus converts Bybit order status to unified format
+func unifyOrderStatus(status string) string {
+	switch status {
+	case "Filled":
+		return "FILLED"
+	case "New", "Created":
+		return "NEW"
+	case "Cancelled", "Rejected":
+		return "CANCELED"
+	case "PartiallyFilled":
+		return "PARTIALLY_FILLED"
+	}
+	return status
+}
+
 func (t *BybitTrader) cancelConditionalOrders(symbol string, orderType string) error {
 	// First get all conditional orders
 	params := map[string]interface{}{
@@ -1073,6 +1303,7 @@ func (t *BybitTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 				}
 
 				orderId, _ := order["orderId"].(string)
+				orderLinkId, _ := order["orderLinkId"].(string)
 				sym, _ := order["symbol"].(string)
 				side, _ := order["side"].(string)
 				orderType, _ := order["orderType"].(string)
@@ -1099,6 +1330,8 @@ func (t *BybitTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 					StopPrice:    price,
 					Quantity:     quantity,
 					Status:       "NEW",
+
+					ClientOrderID: orderLinkId,
 				})
 			}
 		}
@@ -1143,10 +1376,17 @@ func (t *BybitTrader) PlaceLimitOrder(req *types.LimitOrderRequest) (*types.Limi
 		"positionIdx": 0,     // One-way position mode
 	}
 
+	// PostOnly is rejected instead of filling as taker
+	if req.PostOnly {
+		params["timeInForce"] = "PostOnly"
+	}
 	// Add reduce only if specified
 	if req.ReduceOnly {
 		params["reduceOnly"] = true
 	}
+	if req.ClientID != "" {
+		params["orderLinkId"] = req.ClientID
+	}
 
 	logger.Infof("[Bybit] PlaceLimitOrder: %s %s @ %s, qty=%s", req.Symbol, side, priceStr, qtyStr)
 
@@ -1212,8 +1452,8 @@ func (t *BybitTrader) GetOrderBook(symbol string, depth int) (bids, asks [][]flo
 	}
 
 	// Use HTTP request directly since the SDK doesn't expose GetOrderbook
-	url := fmt.Sprintf("https://api.bybit.com/v5/market/orderbook?category=linear&symbol=%s&limit=%d", symbol, depth)
-	resp, err := http.Get(url)
+	url := fmt.Sprintf("%s/v5/market/orderbook?category=linear&symbol=%s&limit=%d", t.client.BaseURL, symbol, depth)
+	resp, err := t.client.HTTPClient.Get(url)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get order book: %w", err)
 	}
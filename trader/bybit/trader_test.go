@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -469,3 +470,64 @@ func TestBybitTrader_MockServerSetLeverage(t *testing.T) {
 
 	assert.NotNil(t, mockServer)
 }
+
+// TestBybitTrader_GridTraderConformance runs the GridTrader conformance suite against a mock Bybit server
+func TestBybitTrader_GridTraderConformance(t *testing.T) {
+	recorder := &testutil.GridMockRecorder{}
+	book := func(levels [][2]string) [][]string {
+		var out [][]string
+		for _, level := range levels {
+			out = append(out, []string{level[0], level[1]})
+		}
+		return out
+	}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result interface{}
+
+		switch {
+		case r.URL.Path == "/v5/order/create" && r.Method == "POST":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			side := strings.ToUpper(body["side"].(string))
+			price, _ := strconv.ParseFloat(body["price"].(string), 64)
+			reduceOnly, _ := body["reduceOnly"].(bool)
+			orderLinkID, _ := body["orderLinkId"].(string)
+			recorder.RecordOrder(testutil.GridOrderCapture{
+				Side:       side,
+				Price:      price,
+				PostOnly:   body["timeInForce"] == "PostOnly",
+				ReduceOnly: reduceOnly,
+				ClientID:   orderLinkID,
+			})
+			result = map[string]interface{}{"orderId": testutil.GridMockOrderID, "orderLinkId": orderLinkID}
+
+		case r.URL.Path == "/v5/order/cancel" && r.Method == "POST":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			recorder.RecordCancel(body["orderId"].(string))
+			result = map[string]interface{}{"orderId": body["orderId"]}
+
+		case r.URL.Path == "/v5/market/orderbook":
+			result = map[string]interface{}{
+				"s": r.URL.Query().Get("symbol"),
+				"b": book(testutil.GridMockBids),
+				"a": book(testutil.GridMockAsks),
+			}
+
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"retCode": 0, "retMsg": "OK", "result": result})
+	}))
+	defer mockServer.Close()
+
+	trader := NewBybitTrader("test_api_key", "test_secret_key")
+	trader.client.BaseURL = mockServer.URL
+	trader.qtyStepCache["BTCUSDT"] = 0.001
+
+	testutil.NewGridTraderTestSuite(t, trader, recorder, 0.05).RunAllTests()
+}
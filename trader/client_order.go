@@ -0,0 +1,176 @@
+package trader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"nofx/logger"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// clientOrderIDPrefix marks client order IDs generated by nofx
+const clientOrderIDPrefix = "nx"
+
+// BuildClientOrderID derives a deterministic client order ID from trader, cycle and decision
+// The same inputs always produce the same ID, so a retried request maps to the same exchange order
+// Format: nx + 18 hex chars (20 chars, alphanumeric) - fits every exchange's client ID limits,
+// including Binance's broker prefix within its 32-char cap
+func BuildClientOrderID(traderID string, cycle int, symbol, action string) string {
+	key := fmt.Sprintf("%s|%d|%s|%s", traderID, cycle, strings.ToUpper(symbol), action)
+	sum := sha256.Sum256([]byte(key))
+	return clientOrderIDPrefix + hex.EncodeToString(sum[:])[:18]
+}
+
+// clientOrderIDForCycle returns the client order ID for a decision executed in the current cycle
+// cycleNumber is only incremented when the decision record is saved, so the running cycle is cycleNumber+1
+func (at *AutoTrader) clientOrderIDForCycle(symbol, action string) string {
+	return BuildClientOrderID(at.clientOrderNamespace(), at.cycleNumber+1, symbol, action)
+}
+
+// clientOrderNamespace is the trader part of per-cycle client order IDs, salted with the run's start time
+// The cycle number is restored from the last saved decision, so a cycle that placed orders but crashed or
+// failed to save its decision is numbered again after a restart - without the salt the new run would reuse
+// its IDs and have its orders skipped as already accepted. Retries within a run keep the same ID
+func (at *AutoTrader) clientOrderNamespace() string {
+	return fmt.Sprintf("%s@%d", at.id, at.startTime.UnixNano())
+}
+
+// ensureClientOrderID returns the client order ID recorded on actionRecord, deriving one if it is unset
+// Decisions from runCycle arrive with an ID already assigned; external decisions (e.g. debate consensus)
+// get one derived from the same cycle but in a separate namespace so they never collide with AI decisions
+func (at *AutoTrader) ensureClientOrderID(actionRecord *store.DecisionAction, symbol, action string) string {
+	if actionRecord.ClientOrderID == "" {
+		actionRecord.ClientOrderID = at.clientOrderIDForCycle(symbol, "external:"+action)
+	}
+	return actionRecord.ClientOrderID
+}
+
+// isOrderAction reports whether a decision action submits a market order
+func isOrderAction(action string) bool {
+	switch action {
+	case "open_long", "open_short", "close_long", "close_short":
+		return true
+	}
+	return false
+}
+
+// submitMarketOrder places a market order for action, tagging it with clientOrderID when the exchange supports it
+// Before submitting it checks whether an order with this client ID was already accepted (e.g. the previous
+// attempt timed out after the exchange took it), and after a failed submit it checks again before
+// reporting the error - so a retry never doubles the position
+func (at *AutoTrader) submitMarketOrder(action, symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return at.submitMarketOrderOn(at.trader, action, symbol, quantity, leverage, clientOrderID)
+}
+
+// submitMarketOrderOn is submitMarketOrder against any exchange account (e.g. a funding arbitrage leg)
+func (at *AutoTrader) submitMarketOrderOn(t Trader, action, symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	cot, ok := t.(ClientOrderTrader)
+	if !ok || clientOrderID == "" {
+		return submitMarketOrderLegacy(t, action, symbol, quantity, leverage)
+	}
+
+	if t == at.trader {
+		if recorded := at.recordedClientOrder(symbol, clientOrderID); recorded != nil {
+			logger.Infof("  ♻️ Order with client ID %s already recorded (status: %v), skipping resubmit",
+				clientOrderID, recorded["status"])
+			return recorded, nil
+		}
+	}
+	if existing := at.lookupClientOrder(cot, symbol, clientOrderID); existing != nil {
+		logger.Infof("  ♻️ Order with client ID %s already accepted by exchange (id: %v), skipping resubmit",
+			clientOrderID, existing["orderId"])
+		return existing, nil
+	}
+
+	var order map[string]interface{}
+	var err error
+	switch action {
+	case "open_long":
+		order, err = cot.OpenLongWithClientID(symbol, quantity, leverage, clientOrderID)
+	case "open_short":
+		order, err = cot.OpenShortWithClientID(symbol, quantity, leverage, clientOrderID)
+	case "close_long":
+		order, err = cot.CloseLongWithClientID(symbol, quantity, clientOrderID)
+	case "close_short":
+		order, err = cot.CloseShortWithClientID(symbol, quantity, clientOrderID)
+	default:
+		return nil, fmt.Errorf("unknown order action: %s", action)
+	}
+	if err == nil {
+		if order != nil && order["clientOrderId"] == nil {
+			order["clientOrderId"] = clientOrderID
+		}
+		return order, nil
+	}
+
+	// The request may have reached the exchange even though we got an error (timeout, dropped connection)
+	time.Sleep(500 * time.Millisecond)
+	if existing := at.lookupClientOrder(cot, symbol, clientOrderID); existing != nil {
+		logger.Infof("  ♻️ Order submit returned error (%v) but exchange accepted client ID %s (id: %v)",
+			err, clientOrderID, existing["orderId"])
+		return existing, nil
+	}
+	return nil, err
+}
+
+// recordedClientOrder returns the order this trader already recorded under clientOrderID, or nil if there is none
+// It covers a retry after restart even when the exchange lookup is unavailable; canceled/rejected records count as absent
+func (at *AutoTrader) recordedClientOrder(symbol, clientOrderID string) map[string]interface{} {
+	if at.store == nil {
+		return nil
+	}
+	order, err := at.store.Order().GetOrderByClientID(at.exchangeID, clientOrderID)
+	if err != nil {
+		logger.Infof("  ⚠️ Failed to look up recorded order by client ID %s: %v", clientOrderID, err)
+		return nil
+	}
+	if order == nil {
+		return nil
+	}
+	switch order.Status {
+	case "CANCELED", "EXPIRED", "REJECTED":
+		return nil
+	}
+	return map[string]interface{}{
+		"orderId":       order.ExchangeOrderID,
+		"clientOrderId": clientOrderID,
+		"symbol":        symbol,
+		"status":        order.Status,
+	}
+}
+
+// lookupClientOrder returns the live or filled order with clientOrderID, or nil if there is none
+// Canceled/rejected orders are treated as absent so the caller may submit again
+func (at *AutoTrader) lookupClientOrder(cot ClientOrderTrader, symbol, clientOrderID string) map[string]interface{} {
+	existing, err := cot.GetOrderByClientID(symbol, clientOrderID)
+	if err != nil {
+		logger.Infof("  ⚠️ Failed to look up order by client ID %s: %v", clientOrderID, err)
+		return nil
+	}
+	if existing == nil {
+		return nil
+	}
+	switch status, _ := existing["status"].(string); status {
+	case "CANCELED", "EXPIRED", "REJECTED":
+		return nil
+	}
+	return existing
+}
+
+// submitMarketOrderLegacy places a market order on traders without client order ID support
+func submitMarketOrderLegacy(t Trader, action, symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	switch action {
+	case "open_long":
+		return t.OpenLong(symbol, quantity, leverage)
+	case "open_short":
+		return t.OpenShort(symbol, quantity, leverage)
+	case "close_long":
+		return t.CloseLong(symbol, quantity)
+	case "close_short":
+		return t.CloseShort(symbol, quantity)
+	default:
+		return nil, fmt.Errorf("unknown order action: %s", action)
+	}
+}
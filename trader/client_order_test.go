@@ -0,0 +1,268 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"nofx/store"
+	"nofx/trader/aster"
+	"nofx/trader/binance"
+	"nofx/trader/bitget"
+	"nofx/trader/bybit"
+	"nofx/trader/gate"
+	"nofx/trader/hyperliquid"
+	"nofx/trader/kucoin"
+	"nofx/trader/lighter"
+	"nofx/trader/okx"
+)
+
+// Every exchange adapter supports retry-safe market orders
+var (
+	_ ClientOrderTrader = (*aster.AsterTrader)(nil)
+	_ ClientOrderTrader = (*binance.FuturesTrader)(nil)
+	_ ClientOrderTrader = (*bitget.BitgetTrader)(nil)
+	_ ClientOrderTrader = (*bybit.BybitTrader)(nil)
+	_ ClientOrderTrader = (*gate.GateTrader)(nil)
+	_ ClientOrderTrader = (*hyperliquid.HyperliquidTrader)(nil)
+	_ ClientOrderTrader = (*kucoin.KuCoinTrader)(nil)
+	_ ClientOrderTrader = (*lighter.LighterTraderV2)(nil)
+	_ ClientOrderTrader = (*okx.OKXTrader)(nil)
+)
+
+func TestBuildClientOrderID(t *testing.T) {
+	id := BuildClientOrderID("trader-1", 42, "BTCUSDT", "open_long")
+
+	// Deterministic: retries of the same decision map to the same ID
+	if again := BuildClientOrderID("trader-1", 42, "btcusdt", "open_long"); again != id {
+		t.Errorf("Expected same ID for same inputs, got %s and %s", id, again)
+	}
+
+	// Must be accepted by every exchange (alphanumeric, starts with a letter, short enough for broker prefixes)
+	if !regexp.MustCompile(`^[a-z][a-z0-9]{1,21}$`).MatchString(id) {
+		t.Errorf("Client order ID %q has invalid format", id)
+	}
+
+	tests := []struct {
+		name     string
+		traderID string
+		cycle    int
+		symbol   string
+		action   string
+	}{
+		{"different trader", "trader-2", 42, "BTCUSDT", "open_long"},
+		{"different cycle", "trader-1", 43, "BTCUSDT", "open_long"},
+		{"different symbol", "trader-1", 42, "ETHUSDT", "open_long"},
+		{"different action", "trader-1", 42, "BTCUSDT", "close_long"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if other := BuildClientOrderID(tt.traderID, tt.cycle, tt.symbol, tt.action); other == id {
+				t.Errorf("Expected different ID, got %s for both", id)
+			}
+		})
+	}
+}
+
+// fakeClientOrderTrader is an exchange that remembers orders by client ID
+type fakeClientOrderTrader struct {
+	Trader // Methods not used by submitMarketOrder are left unimplemented
+
+	orders  map[string]map[string]interface{}
+	submits int
+	// lostResponse makes the exchange accept the order but the submit return an error (e.g. a timeout)
+	lostResponse bool
+	// rejectSubmit makes the submit fail without the exchange taking the order
+	rejectSubmit bool
+}
+
+func newFakeClientOrderTrader() *fakeClientOrderTrader {
+	return &fakeClientOrderTrader{orders: make(map[string]map[string]interface{})}
+}
+
+func (f *fakeClientOrderTrader) place(symbol, clientOrderID string) (map[string]interface{}, error) {
+	f.submits++
+	if f.rejectSubmit {
+		return nil, errors.New("insufficient margin")
+	}
+	order := map[string]interface{}{"orderId": fmt.Sprintf("%d", f.submits), "symbol": symbol, "status": "FILLED"}
+	f.orders[clientOrderID] = order
+	if f.lostResponse {
+		return nil, errors.New("i/o timeout")
+	}
+	return order, nil
+}
+
+func (f *fakeClientOrderTrader) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return f.place(symbol, clientOrderID)
+}
+
+func (f *fakeClientOrderTrader) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return f.place(symbol, clientOrderID)
+}
+
+func (f *fakeClientOrderTrader) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return f.place(symbol, clientOrderID)
+}
+
+func (f *fakeClientOrderTrader) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return f.place(symbol, clientOrderID)
+}
+
+func (f *fakeClientOrderTrader) GetOrderByClientID(symbol, clientOrderID string) (map[string]interface{}, error) {
+	return f.orders[clientOrderID], nil
+}
+
+func TestSubmitMarketOrder_Retry(t *testing.T) {
+	const clientID = "nx0123456789abcdef01"
+
+	tests := []struct {
+		name         string
+		existing     string // Status of an order already on the exchange under clientID ("" = none)
+		lostResponse bool
+		rejectSubmit bool
+		wantSubmits  int
+		wantErr      bool
+		wantOrderID  string
+	}{
+		{"first submit", "", false, false, 1, false, "1"},
+		{"already filled is not resubmitted", "FILLED", false, false, 0, false, "prev"},
+		{"still open is not resubmitted", "NEW", false, false, 0, false, "prev"},
+		{"canceled is resubmitted", "CANCELED", false, false, 1, false, "1"},
+		{"rejected is resubmitted", "REJECTED", false, false, 1, false, "1"},
+		{"error after exchange accepted returns the order", "", true, false, 1, false, "1"},
+		{"error without exchange order is reported", "", false, true, 1, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exchange := newFakeClientOrderTrader()
+			exchange.lostResponse = tt.lostResponse
+			exchange.rejectSubmit = tt.rejectSubmit
+			if tt.existing != "" {
+				exchange.orders[clientID] = map[string]interface{}{"orderId": "prev", "status": tt.existing}
+			}
+			at := &AutoTrader{id: "trader-1", trader: exchange}
+
+			order, err := at.submitMarketOrder("open_long", "BTCUSDT", 0.01, 5, clientID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if exchange.submits != tt.wantSubmits {
+				t.Errorf("submits = %d, want %d", exchange.submits, tt.wantSubmits)
+			}
+			if tt.wantErr {
+				return
+			}
+			if order["orderId"] != tt.wantOrderID {
+				t.Errorf("orderId = %v, want %s", order["orderId"], tt.wantOrderID)
+			}
+		})
+	}
+}
+
+func TestClientOrderIDForCycle_PerRun(t *testing.T) {
+	start := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	at := &AutoTrader{id: "trader-1", startTime: start}
+
+	first := at.clientOrderIDForCycle("BTCUSDT", "open_long")
+	if err := at.saveDecision(&store.DecisionRecord{}); err != nil {
+		t.Fatalf("saveDecision: %v", err)
+	}
+	if next := at.clientOrderIDForCycle("BTCUSDT", "open_long"); next == first {
+		t.Errorf("Expected a new ID after the cycle advanced, got %s twice", first)
+	}
+
+	// A restarted trader may number a cycle again but must not reuse the previous run's IDs
+	restarted := &AutoTrader{id: "trader-1", startTime: start.Add(time.Hour)}
+	if again := restarted.clientOrderIDForCycle("BTCUSDT", "open_long"); again == first {
+		t.Errorf("Expected a different ID after restart, got %s for both runs", first)
+	}
+}
+
+func TestClientOrderIDForCycle_RestartWithUnsavedCycle(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "orders.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	start := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	exchange := newFakeClientOrderTrader()
+	newRun := func(started time.Time) *AutoTrader {
+		cycle, _ := st.Decision().GetLastCycleNumber("trader-1")
+		return &AutoTrader{id: "trader-1", exchange: "bybit", exchangeID: "exchange-1", trader: exchange, store: st,
+			cycleNumber: cycle, startTime: started}
+	}
+
+	// Cycle 3 is saved; cycle 4 places an order and crashes before its decision is saved
+	if err := st.Decision().LogDecision(&store.DecisionRecord{TraderID: "trader-1", CycleNumber: 3, Timestamp: start}); err != nil {
+		t.Fatalf("LogDecision: %v", err)
+	}
+	crashed := newRun(start)
+	crashedID := crashed.clientOrderIDForCycle("BTCUSDT", "open_long")
+	order, err := crashed.submitMarketOrder("open_long", "BTCUSDT", 0.01, 5, crashedID)
+	if err != nil {
+		t.Fatalf("submitMarketOrder: %v", err)
+	}
+	crashed.recordAndConfirmOrder(order, "BTCUSDT", "open_long", 0.01, 50000, 5, 0, crashedID)
+
+	// The restarted trader runs cycle 4 again: its order must go out, not be taken for the crashed run's
+	restarted := newRun(start.Add(time.Minute))
+	if restarted.cycleNumber != 3 {
+		t.Fatalf("Expected the restart to resume after the saved cycle 3, got %d", restarted.cycleNumber)
+	}
+	restartedID := restarted.clientOrderIDForCycle("BTCUSDT", "open_long")
+	if restartedID == crashedID {
+		t.Fatalf("Expected a new client order ID after restart, got %s for both runs", crashedID)
+	}
+	if _, err := restarted.submitMarketOrder("open_long", "BTCUSDT", 0.01, 5, restartedID); err != nil {
+		t.Fatalf("submitMarketOrder: %v", err)
+	}
+	if exchange.submits != 2 {
+		t.Errorf("Expected the restarted cycle's order submitted, got %d submits", exchange.submits)
+	}
+
+	// Within a run a retry keeps its ID and is not resubmitted
+	if _, err := restarted.submitMarketOrder("open_long", "BTCUSDT", 0.01, 5, restarted.clientOrderIDForCycle("BTCUSDT", "open_long")); err != nil {
+		t.Fatalf("submitMarketOrder: %v", err)
+	}
+	if exchange.submits != 2 {
+		t.Errorf("Expected a retry in the same run to be deduplicated, got %d submits", exchange.submits)
+	}
+}
+
+func TestSubmitMarketOrder_RecordedSubmission(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "orders.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	const clientID = "nx0123456789abcdef02"
+	exchange := newFakeClientOrderTrader()
+	at := &AutoTrader{id: "trader-1", exchange: "bybit", exchangeID: "exchange-1", trader: exchange, store: st}
+
+	// OrderSync exchanges record only a submission keyed by the client order ID
+	at.recordAndConfirmOrder(map[string]interface{}{"orderId": "123"}, "BTCUSDT", "open_long", 0.01, 50000, 5, 0, clientID)
+	recorded, err := st.Order().GetOrderByClientID("exchange-1", clientID)
+	if err != nil || recorded == nil {
+		t.Fatalf("Expected submission record, got %v (err %v)", recorded, err)
+	}
+	if recorded.Status != store.OrderStatusSubmitted {
+		t.Errorf("status = %s, want %s", recorded.Status, store.OrderStatusSubmitted)
+	}
+	stats, err := st.Order().GetTraderOrderStats("trader-1")
+	if err != nil {
+		t.Fatalf("GetTraderOrderStats: %v", err)
+	}
+	if stats["total_orders"] != 0 {
+		t.Errorf("Submission records must not count as orders, got %v", stats["total_orders"])
+	}
+
+	// A retry (e.g. after restart) finds the record even though the exchange lookup has nothing
+	if _, err := at.submitMarketOrder("open_long", "BTCUSDT", 0.01, 5, clientID); err != nil {
+		t.Fatalf("submitMarketOrder: %v", err)
+	}
+	if exchange.submits != 0 {
+		t.Errorf("Expected no resubmit of a recorded order, got %d submits", exchange.submits)
+	}
+}
@@ -0,0 +1,126 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/logger"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// Contract Spec Registry
+// Instrument trading rules (tick size, lot step, minimums, leverage caps) loaded
+// from each exchange through ContractSpecProvider and cached per exchange+symbol,
+// so sizing, validation, grid rounding and prompts share one source of truth
+// =============================================================================
+
+const (
+	contractSpecTTL      = time.Hour       // Specs rarely change, refresh hourly
+	contractSpecRetryTTL = 2 * time.Minute // Back off after a failed fetch
+)
+
+// contractSpecEntry cached lookup result (spec or error)
+type contractSpecEntry struct {
+	spec      *ContractSpec
+	err       error
+	fetchedAt time.Time
+}
+
+// ContractSpecRegistry caches contract specs for every exchange
+type ContractSpecRegistry struct {
+	mu      sync.RWMutex
+	entries map[string]*contractSpecEntry // exchange:symbol -> entry
+}
+
+// NewContractSpecRegistry creates an empty registry
+func NewContractSpecRegistry() *ContractSpecRegistry {
+	return &ContractSpecRegistry{entries: make(map[string]*contractSpecEntry)}
+}
+
+// defaultContractSpecs shared by every AutoTrader, specs are public per exchange
+var defaultContractSpecs = NewContractSpecRegistry()
+
+// ContractSpecs returns the process-wide contract-spec registry
+func ContractSpecs() *ContractSpecRegistry {
+	return defaultContractSpecs
+}
+
+// Get returns the spec for symbol on exchange, loading it from t on a cache miss
+func (r *ContractSpecRegistry) Get(t Trader, exchange, symbol string) (*ContractSpec, error) {
+	key := exchange + ":" + symbol
+
+	r.mu.RLock()
+	entry, ok := r.entries[key]
+	r.mu.RUnlock()
+	if ok && entry.fresh() {
+		return entry.spec, entry.err
+	}
+
+	provider, ok := t.(ContractSpecProvider)
+	if !ok {
+		return nil, fmt.Errorf("exchange %s does not provide contract specs", exchange)
+	}
+
+	spec, err := provider.GetContractSpec(symbol)
+	if err != nil {
+		logger.Infof("⚠️ Failed to load contract spec for %s on %s: %v", symbol, exchange, err)
+		// Keep serving the last good spec if there is one
+		if entry != nil && entry.spec != nil {
+			return entry.spec, nil
+		}
+	}
+
+	r.mu.Lock()
+	r.entries[key] = &contractSpecEntry{spec: spec, err: err, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	return spec, err
+}
+
+// Put stores a spec directly (used by tests and callers that already hold exchange metadata)
+func (r *ContractSpecRegistry) Put(spec *ContractSpec) {
+	r.mu.Lock()
+	r.entries[spec.Exchange+":"+spec.Symbol] = &contractSpecEntry{spec: spec, fetchedAt: time.Now()}
+	r.mu.Unlock()
+}
+
+// Invalidate drops the cached spec so the next Get refetches it
+func (r *ContractSpecRegistry) Invalidate(exchange, symbol string) {
+	r.mu.Lock()
+	delete(r.entries, exchange+":"+symbol)
+	r.mu.Unlock()
+}
+
+// fresh reports whether the cached entry can still be served
+func (e *contractSpecEntry) fresh() bool {
+	if e.err != nil {
+		return time.Since(e.fetchedAt) < contractSpecRetryTTL
+	}
+	return time.Since(e.fetchedAt) < contractSpecTTL
+}
+
+// contractSpec returns the spec for symbol on this trader's exchange, or nil if unavailable
+// A nil spec is safe to use: its rounding and limit helpers are no-ops
+func (at *AutoTrader) contractSpec(symbol string) *ContractSpec {
+	spec, err := ContractSpecs().Get(at.trader, at.exchange, symbol)
+	if err != nil {
+		return nil
+	}
+	return spec
+}
+
+// contractSpecsFor loads specs for the given symbols, skipping ones the exchange can't describe
+func (at *AutoTrader) contractSpecsFor(symbols []string) map[string]*ContractSpec {
+	if _, ok := at.trader.(ContractSpecProvider); !ok {
+		return nil
+	}
+	specs := make(map[string]*ContractSpec, len(symbols))
+	for _, symbol := range symbols {
+		if _, done := specs[symbol]; done {
+			continue
+		}
+		if spec := at.contractSpec(symbol); spec != nil {
+			specs[symbol] = spec
+		}
+	}
+	return specs
+}
@@ -0,0 +1,66 @@
+package trader
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContractSpecRounding(t *testing.T) {
+	spec := &ContractSpec{Symbol: "ETHUSDT", TickSize: 0.01, QtyStep: 0.001, MinQty: 0.001, MinNotional: 5}
+
+	if got := spec.RoundPrice(2345.6789); math.Abs(got-2345.68) > 1e-9 {
+		t.Errorf("RoundPrice: expected 2345.68, got %v", got)
+	}
+	if got := spec.FloorQty(0.0129); math.Abs(got-0.012) > 1e-12 {
+		t.Errorf("FloorQty: expected 0.012, got %v", got)
+	}
+	// Exact multiples must not lose a step to floating-point error
+	if got := (&ContractSpec{QtyStep: 0.1}).FloorQty(0.3); math.Abs(got-0.3) > 1e-12 {
+		t.Errorf("FloorQty(0.3): expected 0.3, got %v", got)
+	}
+
+	if err := spec.CheckOrder(0.002, 2000); err == nil {
+		t.Error("CheckOrder: expected min notional error for 4 USDT order")
+	}
+	if err := spec.CheckOrder(0.003, 2000); err != nil {
+		t.Errorf("CheckOrder: unexpected error %v", err)
+	}
+	if got := spec.MinOrderValue(10000); got != 10 {
+		t.Errorf("MinOrderValue: expected min qty to dominate (10), got %v", got)
+	}
+
+	var none *ContractSpec
+	if none.RoundPrice(1.23456) != 1.23456 || none.FloorQty(0.5) != 0.5 || none.CheckOrder(0.5, 1) != nil {
+		t.Error("nil spec helpers must be no-ops")
+	}
+}
+
+func TestContractSpecMaxLeverageFor(t *testing.T) {
+	spec := &ContractSpec{
+		MaxLeverage: 125,
+		LeverageBrackets: []LeverageBracket{
+			{NotionalCap: 50000, MaxLeverage: 125},
+			{NotionalCap: 250000, MaxLeverage: 100},
+			{NotionalCap: 1000000, MaxLeverage: 50},
+		},
+	}
+	tests := []struct {
+		notional float64
+		want     int
+	}{
+		{0, 125},
+		{50000, 125},
+		{50001, 100},
+		{900000, 50},
+		{5000000, 50}, // Beyond the last tier keeps its cap
+	}
+	for _, tt := range tests {
+		if got := spec.MaxLeverageFor(tt.notional); got != tt.want {
+			t.Errorf("MaxLeverageFor(%.0f): expected %d, got %d", tt.notional, tt.want, got)
+		}
+	}
+
+	if got := (&ContractSpec{MaxLeverage: 20}).MaxLeverageFor(1e9); got != 20 {
+		t.Errorf("without brackets expected flat cap 20, got %d", got)
+	}
+}
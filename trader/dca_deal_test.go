@@ -0,0 +1,271 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"nofx/kernel"
+	"nofx/store"
+)
+
+// dcaFakeTrader rests limit orders in memory and reports the order outcomes and position the test sets
+type dcaFakeTrader struct {
+	Trader // Methods the deal state machine doesn't use are left unimplemented
+
+	position  float64
+	open      map[string]bool   // Resting order IDs
+	outcomes  map[string]string // GetOrderStatus answers; missing IDs are unknown
+	placed    []*LimitOrderRequest
+	placeErr  error
+	cancelErr error
+}
+
+func (f *dcaFakeTrader) GetMarketPrice(symbol string) (float64, error) {
+	return 100, nil
+}
+
+func (f *dcaFakeTrader) GetPositions() ([]map[string]interface{}, error) {
+	if f.position == 0 {
+		return nil, nil
+	}
+	return []map[string]interface{}{{"symbol": "BTCUSDT", "side": "long", "positionAmt": f.position, "entryPrice": 100.0}}, nil
+}
+
+func (f *dcaFakeTrader) GetOpenOrders(symbol string) ([]OpenOrder, error) {
+	var orders []OpenOrder
+	for id := range f.open {
+		orders = append(orders, OpenOrder{OrderID: id, Symbol: symbol})
+	}
+	return orders, nil
+}
+
+func (f *dcaFakeTrader) GetOrderStatus(symbol, orderID string) (map[string]interface{}, error) {
+	status, ok := f.outcomes[orderID]
+	if !ok {
+		return nil, fmt.Errorf("order %s not found", orderID)
+	}
+	return map[string]interface{}{"status": status}, nil
+}
+
+func (f *dcaFakeTrader) PlaceLimitOrder(req *LimitOrderRequest) (*LimitOrderResult, error) {
+	if f.placeErr != nil {
+		return nil, f.placeErr
+	}
+	f.placed = append(f.placed, req)
+	id := fmt.Sprintf("o%d", len(f.placed))
+	f.open[id] = true
+	return &LimitOrderResult{OrderID: id, Status: "NEW"}, nil
+}
+
+func (f *dcaFakeTrader) CancelOrder(symbol, orderID string) error {
+	if f.cancelErr != nil {
+		return f.cancelErr
+	}
+	delete(f.open, orderID)
+	return nil
+}
+
+func (f *dcaFakeTrader) GetOrderBook(symbol string, depth int) (bids, asks [][]float64, err error) {
+	return nil, nil, nil
+}
+
+// newDCATestTrader seeds a long deal of 1 @ 100 with safety orders at 95 and 90 and a take-profit at 102
+func newDCATestTrader(t *testing.T) (*AutoTrader, *dcaFakeTrader) {
+	st, err := store.New(filepath.Join(t.TempDir(), "dca.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	exchange := &dcaFakeTrader{
+		position: 1,
+		open:     map[string]bool{"so1": true, "so2": true, "tp0": true},
+		outcomes: map[string]string{},
+	}
+	at := &AutoTrader{
+		id:     "dca-trader",
+		store:  st,
+		trader: exchange,
+		config: AutoTraderConfig{StrategyConfig: &store.StrategyConfig{StrategyType: "dca", DCAConfig: &store.DCAStrategyConfig{
+			Symbol: "BTCUSDT", Leverage: 1, BaseOrderUSD: 100, SafetyOrderUSD: 100,
+			MaxSafetyOrders: 2, PriceDeviationPct: 5, TakeProfitPct: 2,
+		}}},
+	}
+
+	deal := &store.DCADeal{TraderID: at.id, Symbol: "BTCUSDT", Side: "LONG", Status: store.DCADealActive,
+		BasePrice: 100, AvgEntryPrice: 100, Quantity: 1, Cost: 100, TakeProfitPrice: 102, TakeProfitOrderID: "tp0"}
+	if err := st.DCA().SaveDeal(deal); err != nil {
+		t.Fatalf("SaveDeal: %v", err)
+	}
+	for _, o := range []*store.DCAOrder{
+		{Kind: store.DCAOrderBase, Price: 100, Quantity: 1, OrderID: "base", Status: store.DCAOrderFilled},
+		{Kind: store.DCAOrderSafety, Index: 1, Price: 95, Quantity: 1, OrderID: "so1", Status: store.DCAOrderOpen},
+		{Kind: store.DCAOrderSafety, Index: 2, Price: 90, Quantity: 1, OrderID: "so2", Status: store.DCAOrderOpen},
+		{Kind: store.DCAOrderTakeProfit, Price: 102, Quantity: 1, OrderID: "tp0", Status: store.DCAOrderOpen},
+	} {
+		o.DealID = deal.ID
+		if err := st.DCA().SaveOrder(o); err != nil {
+			t.Fatalf("SaveOrder: %v", err)
+		}
+	}
+	at.dcaDeal = deal
+	return at, exchange
+}
+
+// fillSafetyOrder makes the 95 safety order vanish from the book as filled
+func fillSafetyOrder(exchange *dcaFakeTrader) {
+	delete(exchange.open, "so1")
+	exchange.outcomes["so1"] = "FILLED"
+	exchange.position = 2
+}
+
+// dealTakeProfits returns the deal's take-profit orders by status
+func dealTakeProfits(t *testing.T, at *AutoTrader, dealID int64) map[string][]store.DCAOrder {
+	orders, err := at.store.DCA().GetDealOrders(dealID)
+	if err != nil {
+		t.Fatalf("GetDealOrders: %v", err)
+	}
+	byStatus := make(map[string][]store.DCAOrder)
+	for _, o := range orders {
+		if o.Kind == store.DCAOrderTakeProfit {
+			byStatus[o.Status] = append(byStatus[o.Status], o)
+		}
+	}
+	return byStatus
+}
+
+func TestSyncDCADeal(t *testing.T) {
+	t.Run("safety fill re-averages and moves the take-profit", func(t *testing.T) {
+		at, exchange := newDCATestTrader(t)
+		fillSafetyOrder(exchange)
+
+		if err := at.syncDCADeal(); err != nil {
+			t.Fatalf("syncDCADeal: %v", err)
+		}
+		deal := at.dcaDeal
+		if deal.Quantity != 2 || deal.AvgEntryPrice != 97.5 || deal.SafetyOrdersFilled != 1 {
+			t.Fatalf("Expected 2 @ 97.5 after one safety fill, got %.2f @ %.2f (%d filled)", deal.Quantity, deal.AvgEntryPrice, deal.SafetyOrdersFilled)
+		}
+		tps := dealTakeProfits(t, at, deal.ID)
+		if len(tps[store.DCAOrderOpen]) != 1 || exchange.open["tp0"] {
+			t.Fatalf("Expected the old take-profit cancelled and one new one resting, got %+v", tps)
+		}
+		tp := tps[store.DCAOrderOpen][0]
+		if tp.Index != 1 || tp.Quantity != 2 || math.Abs(tp.Price-97.5*1.02) > 1e-9 || deal.TakeProfitOrderID != tp.OrderID {
+			t.Errorf("Expected a reduce-only take-profit for 2 @ %.4f, got %+v (deal points at %s)", 97.5*1.02, tp, deal.TakeProfitOrderID)
+		}
+	})
+
+	t.Run("failed cancel keeps the take-profit and retries", func(t *testing.T) {
+		at, exchange := newDCATestTrader(t)
+		fillSafetyOrder(exchange)
+		exchange.cancelErr = errors.New("exchange unavailable")
+
+		at.syncDCADeal()
+		tps := dealTakeProfits(t, at, at.dcaDeal.ID)
+		if len(tps[store.DCAOrderOpen]) != 1 || tps[store.DCAOrderOpen][0].OrderID != "tp0" || len(exchange.placed) != 0 {
+			t.Fatalf("An uncancelled take-profit must stay open with no second one placed, got %+v placed=%d", tps, len(exchange.placed))
+		}
+
+		exchange.cancelErr = nil
+		at.syncDCADeal()
+		tps = dealTakeProfits(t, at, at.dcaDeal.ID)
+		if len(tps[store.DCAOrderOpen]) != 1 || tps[store.DCAOrderOpen][0].Index != 1 || exchange.open["tp0"] {
+			t.Errorf("Expected the stale take-profit replaced next cycle, got %+v", tps)
+		}
+	})
+
+	t.Run("pending take-profit is retried", func(t *testing.T) {
+		at, exchange := newDCATestTrader(t)
+		fillSafetyOrder(exchange)
+		exchange.placeErr = errors.New("rate limited")
+
+		at.syncDCADeal()
+		if tps := dealTakeProfits(t, at, at.dcaDeal.ID); len(tps[store.DCAOrderPending]) != 1 {
+			t.Fatalf("Expected the new take-profit left pending, got %+v", tps)
+		}
+
+		exchange.placeErr = nil
+		at.syncDCADeal()
+		tps := dealTakeProfits(t, at, at.dcaDeal.ID)
+		if len(tps[store.DCAOrderPending]) != 0 || len(tps[store.DCAOrderOpen]) != 1 {
+			t.Fatalf("Expected the pending take-profit placed next cycle, got %+v", tps)
+		}
+		if at.dcaDeal.TakeProfitOrderID != tps[store.DCAOrderOpen][0].OrderID {
+			t.Errorf("Deal should point at the placed take-profit, got %s", at.dcaDeal.TakeProfitOrderID)
+		}
+	})
+
+	t.Run("take-profit fill completes the deal", func(t *testing.T) {
+		at, exchange := newDCATestTrader(t)
+		delete(exchange.open, "tp0")
+		exchange.outcomes["tp0"] = "FILLED"
+		exchange.position = 0
+		dealID := at.dcaDeal.ID
+
+		at.syncDCADeal()
+		if at.dcaDeal != nil {
+			t.Fatalf("Deal should be closed")
+		}
+		deals, _ := at.store.DCA().ListDeals(at.id, 1)
+		if deals[0].ID != dealID || deals[0].Status != store.DCADealCompleted || deals[0].RealizedPnL != 2 {
+			t.Errorf("Expected deal completed with 2 USDT PnL, got %+v", deals[0])
+		}
+		if exchange.open["so1"] || exchange.open["so2"] {
+			t.Errorf("Resting safety orders should be cancelled, still open: %v", exchange.open)
+		}
+
+		// OrderSync records the fill after the deal completed, under its own trade ID; it still gets the take-profit label
+		exits := syncTestFills(t, at.store, at.id,
+			TradeRecord{TradeID: "8001", OrderID: "entry", Symbol: "BTCUSDT", OrderAction: "open_long", Price: 100, Quantity: 1},
+			TradeRecord{TradeID: "8002", OrderID: "tp0", Symbol: "BTCUSDT", OrderAction: "close_long", Price: 102, Quantity: 1, RealizedPnL: 2},
+		)
+		if len(exits) != 1 || exits[0].OrderID != "8002" || exits[0].Reason != dcaCloseReasonTakeProfit {
+			t.Errorf("Expected trade 8002 labelled %s through order tp0, got %+v", dcaCloseReasonTakeProfit, exits)
+		}
+	})
+
+	t.Run("position closed outside the bot abandons the deal", func(t *testing.T) {
+		at, exchange := newDCATestTrader(t)
+		exchange.position = 0
+
+		at.syncDCADeal()
+		deals, _ := at.store.DCA().ListDeals(at.id, 1)
+		if at.dcaDeal != nil || deals[0].Status != store.DCADealCancelled {
+			t.Errorf("Expected the deal cancelled, got %+v", deals[0])
+		}
+	})
+}
+
+func TestDCALeftoverOrdersBlockNextDeal(t *testing.T) {
+	at, exchange := newDCATestTrader(t)
+	delete(exchange.open, "tp0")
+	exchange.outcomes["tp0"] = "FILLED"
+	exchange.position = 0
+	exchange.cancelErr = errors.New("exchange unavailable")
+
+	at.syncDCADeal()
+	lastDeal, _ := at.store.DCA().GetLastClosedDeal(at.id)
+	if lastDeal == nil || lastDeal.Status != store.DCADealCompleted {
+		t.Fatalf("Expected the deal completed even though its safety orders couldn't be cancelled, got %+v", lastDeal)
+	}
+
+	// Starting a deal here would call OpenLong, which the fake leaves unimplemented
+	if err := at.RunDCACycle(); err != nil || at.dcaDeal != nil {
+		t.Fatalf("No deal may start while the last deal's safety orders still rest, err=%v", err)
+	}
+
+	exchange.cancelErr = nil
+	if !at.cancelLeftoverDCAOrders(lastDeal) || len(exchange.open) != 0 {
+		t.Errorf("Expected the leftover safety orders cancelled, still open: %v", exchange.open)
+	}
+}
+
+func TestSaveStrategyDecisionRecordWithoutStore(t *testing.T) {
+	at := &AutoTrader{id: "dca-trader"}
+	at.saveStrategyDecisionRecord(&kernel.FullDecision{Decisions: []kernel.Decision{{Symbol: "BTCUSDT", Action: kernel.DCAActionStartDeal}}}, "Started deal #1")
+	if at.cycleNumber != 1 {
+		t.Errorf("Expected the cycle advanced without a store, got %d", at.cycleNumber)
+	}
+}
@@ -0,0 +1,105 @@
+package trader
+
+import (
+	"math"
+	"nofx/store"
+)
+
+// ============================================================================
+// DCA Layout Helpers
+// ============================================================================
+// Pure functions over the DCA config, shared by the live bot and its tests.
+
+// DCASafetyOrder one planned safety order of a deal
+type DCASafetyOrder struct {
+	Index        int     // 1-based safety order number
+	DeviationPct float64 // Cumulative deviation from the base price (%)
+	Price        float64
+	USD          float64 // Order notional in USDT
+	Quantity     float64
+}
+
+// dcaIsShort returns true if deals sell first and average up
+func dcaIsShort(config *store.DCAStrategyConfig) bool {
+	return config.Direction == "short"
+}
+
+// dcaSide returns the position side deals open
+func dcaSide(config *store.DCAStrategyConfig) string {
+	if dcaIsShort(config) {
+		return "SHORT"
+	}
+	return "LONG"
+}
+
+// DCASafetyOrderPlan lays out the safety orders of a deal opened at basePrice
+// Order i sits PriceDeviationPct × (1 + s + … + s^(i-1)) away from the base price for step scale s,
+// and is sized SafetyOrderUSD × VolumeScale^(i-1). Orders that would cross zero are dropped.
+func DCASafetyOrderPlan(config *store.DCAStrategyConfig, basePrice float64) []DCASafetyOrder {
+	if basePrice <= 0 || config.MaxSafetyOrders <= 0 || config.PriceDeviationPct <= 0 || config.SafetyOrderUSD <= 0 {
+		return nil
+	}
+	stepScale := config.StepScale
+	if stepScale <= 0 {
+		stepScale = 1.0
+	}
+	volumeScale := config.VolumeScale
+	if volumeScale <= 0 {
+		volumeScale = 1.0
+	}
+
+	var plan []DCASafetyOrder
+	deviation, step, usd := 0.0, config.PriceDeviationPct, config.SafetyOrderUSD
+	for i := 1; i <= config.MaxSafetyOrders; i++ {
+		deviation += step
+		price := basePrice * (1 - deviation/100)
+		if dcaIsShort(config) {
+			price = basePrice * (1 + deviation/100)
+		}
+		if price <= 0 {
+			break
+		}
+		plan = append(plan, DCASafetyOrder{
+			Index:        i,
+			DeviationPct: deviation,
+			Price:        price,
+			USD:          usd,
+			Quantity:     usd / price,
+		})
+		step *= stepScale
+		usd *= volumeScale
+	}
+	return plan
+}
+
+// DCATakeProfitPrice returns the take-profit price for a deal averaged in at avgEntry
+func DCATakeProfitPrice(config *store.DCAStrategyConfig, avgEntry float64) float64 {
+	if dcaIsShort(config) {
+		return avgEntry * (1 - config.TakeProfitPct/100)
+	}
+	return avgEntry * (1 + config.TakeProfitPct/100)
+}
+
+// DCAMaxDealCapital returns the notional a deal uses once every safety order has filled
+func DCAMaxDealCapital(config *store.DCAStrategyConfig) float64 {
+	total := config.BaseOrderUSD
+	volumeScale := config.VolumeScale
+	if volumeScale <= 0 {
+		volumeScale = 1.0
+	}
+	usd := config.SafetyOrderUSD
+	for i := 0; i < config.MaxSafetyOrders; i++ {
+		total += usd
+		usd *= volumeScale
+	}
+	return total
+}
+
+// DCADealPnL returns the PnL of closing quantity at exitPrice against avgEntry
+func DCADealPnL(side string, avgEntry, exitPrice, quantity float64) float64 {
+	pnl := (exitPrice - avgEntry) * quantity
+	if side == "SHORT" {
+		pnl = -pnl
+	}
+	return math.Round(pnl*100) / 100
+}
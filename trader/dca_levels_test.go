@@ -0,0 +1,54 @@
+package trader
+
+import (
+	"math"
+	"nofx/store"
+	"testing"
+)
+
+func TestDCASafetyOrderPlan(t *testing.T) {
+	config := &store.DCAStrategyConfig{
+		BaseOrderUSD:      100,
+		SafetyOrderUSD:    50,
+		MaxSafetyOrders:   3,
+		PriceDeviationPct: 2,
+		StepScale:         1.5,
+		VolumeScale:       2,
+		TakeProfitPct:     1,
+	}
+
+	plan := DCASafetyOrderPlan(config, 100)
+	if len(plan) != 3 {
+		t.Fatalf("expected 3 safety orders, got %d", len(plan))
+	}
+
+	// Deviations 2, 2+3, 2+3+4.5 and sizes 50, 100, 200
+	wantDeviation := []float64{2, 5, 9.5}
+	wantUSD := []float64{50, 100, 200}
+	for i, so := range plan {
+		if so.Index != i+1 || math.Abs(so.DeviationPct-wantDeviation[i]) > 1e-9 || so.USD != wantUSD[i] {
+			t.Errorf("safety order %d: got %+v", i+1, so)
+		}
+		if math.Abs(so.Price-(100-wantDeviation[i])) > 1e-9 {
+			t.Errorf("safety order %d: expected price %.2f, got %.4f", i+1, 100-wantDeviation[i], so.Price)
+		}
+	}
+	if got := DCAMaxDealCapital(config); got != 450 {
+		t.Errorf("expected max deal capital 450, got %.2f", got)
+	}
+	if got := DCATakeProfitPrice(config, 95); math.Abs(got-95.95) > 1e-9 {
+		t.Errorf("expected long take-profit 95.95, got %.4f", got)
+	}
+
+	config.Direction = "short"
+	plan = DCASafetyOrderPlan(config, 100)
+	if plan[0].Price != 102 || plan[2].Price != 109.5 {
+		t.Errorf("expected short safety orders above the base price, got %.2f/%.2f", plan[0].Price, plan[2].Price)
+	}
+	if got := DCATakeProfitPrice(config, 105); math.Abs(got-103.95) > 1e-9 {
+		t.Errorf("expected short take-profit 103.95, got %.4f", got)
+	}
+	if got := DCADealPnL("SHORT", 105, 103.95, 2); got != 2.1 {
+		t.Errorf("expected short deal PnL 2.1, got %.4f", got)
+	}
+}
@@ -2,6 +2,7 @@ package trader
 
 import (
 	"nofx/store"
+	"strings"
 	"testing"
 	"time"
 
@@ -142,7 +143,7 @@ func runStandardTests(t *testing.T, exchangeName string) {
 					trade.Symbol, trade.Side, trade.Action,
 					trade.Quantity, trade.Price, trade.Fee, trade.RealizedPnL,
 					time.Now().Add(time.Duration(i)*time.Second).UnixMilli(),
-					"",
+					"", "",
 				)
 				if err != nil {
 					t.Fatalf("Failed to process trade %d (%s): %v", i, trade.Action, err)
@@ -228,7 +229,7 @@ func TestPositionAccumulationBug(t *testing.T) {
 			"ETHUSDT", "LONG", "open_long",
 			0.1, 3500+float64(i*10), 0.5, 0,
 			time.Now().Add(time.Duration(i*2)*time.Second).UnixMilli(),
-			"",
+			"", "",
 		)
 		if err != nil {
 			t.Fatalf("Failed to open long %d: %v", i, err)
@@ -240,7 +241,7 @@ func TestPositionAccumulationBug(t *testing.T) {
 			"ETHUSDT", "LONG", "close_long",
 			0.1, 3600+float64(i*10), 0.5, 10,
 			time.Now().Add(time.Duration(i*2+1)*time.Second).UnixMilli(),
-			"",
+			"", "",
 		)
 		if err != nil {
 			t.Fatalf("Failed to close long %d: %v", i, err)
@@ -310,7 +311,7 @@ func TestQuantityPrecision(t *testing.T) {
 		"BTCUSDT", "LONG", "open_long",
 		0.01, 50000, 1.0, 0,
 		time.Now().UnixMilli(),
-		"",
+		"", "",
 	)
 	if err != nil {
 		t.Fatalf("Failed to open: %v", err)
@@ -323,7 +324,7 @@ func TestQuantityPrecision(t *testing.T) {
 		"BTCUSDT", "LONG", "close_long",
 		0.00999999, 51000, 1.0, 10,
 		time.Now().Add(time.Second).UnixMilli(),
-		"",
+		"", "",
 	)
 	if err != nil {
 		t.Fatalf("Failed to close: %v", err)
@@ -339,3 +340,26 @@ func TestQuantityPrecision(t *testing.T) {
 		t.Errorf("Expected 0 positions (precision tolerance), got %d", len(positions))
 	}
 }
+
+// syncTestFills feeds fills through PositionBuilder the way OrderSync does and returns the exit legs
+// of the position they closed
+func syncTestFills(t *testing.T, st *store.Store, traderID string, fills ...TradeRecord) []*store.TraderPositionExit {
+	t.Helper()
+	posBuilder := store.NewPositionBuilder(st.Position())
+	for i, trade := range fills {
+		side := "LONG"
+		if strings.HasSuffix(trade.OrderAction, "short") {
+			side = "SHORT"
+		}
+		if err := posBuilder.ProcessTrade(traderID, "exchange-1", "binance", trade.Symbol, side, trade.OrderAction,
+			trade.Quantity, trade.Price, trade.Fee, trade.RealizedPnL, int64(i+1), trade.TradeID, trade.OrderID); err != nil {
+			t.Fatalf("ProcessTrade(%s): %v", trade.TradeID, err)
+		}
+	}
+	closed, _ := st.Position().GetClosedPositions(traderID, 1)
+	if len(closed) != 1 {
+		t.Fatalf("Expected the synced position closed, got %d", len(closed))
+	}
+	exits, _ := st.Position().GetExits(closed[0].ID)
+	return exits
+}
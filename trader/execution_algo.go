@@ -0,0 +1,96 @@
+package trader
+
+import (
+	"nofx/store"
+	"time"
+)
+
+// Execution algorithms for working large orders
+const (
+	ExecutionMarket  = "market"
+	ExecutionTWAP    = "twap"
+	ExecutionIceberg = "iceberg"
+	ExecutionPOV     = "pov"
+)
+
+// Execution defaults, used when the strategy leaves a parameter unset
+const (
+	defaultExecutionWindow   = 60 * time.Second
+	defaultTWAPSlices        = 5
+	defaultIcebergClips      = 5
+	defaultParticipationPct  = 10.0
+	minExecutionSliceSpacing = 5 * time.Second
+)
+
+// isExecutionAlgo reports whether name is a known execution algorithm
+func isExecutionAlgo(name string) bool {
+	switch name {
+	case ExecutionMarket, ExecutionTWAP, ExecutionIceberg, ExecutionPOV:
+		return true
+	}
+	return false
+}
+
+// SelectExecutionAlgo picks how an order of notional USDT is executed
+// requested is the decision's own choice and wins when the config allows AI overrides;
+// otherwise orders at or above MinNotionalUSD use the configured algorithm and the rest go out at market
+func SelectExecutionAlgo(config *store.ExecutionConfig, requested string, notional float64) string {
+	if config == nil {
+		return ExecutionMarket
+	}
+	if config.AllowAIOverride && isExecutionAlgo(requested) {
+		return requested
+	}
+	if !isExecutionAlgo(config.Algorithm) || notional < config.MinNotionalUSD {
+		return ExecutionMarket
+	}
+	return config.Algorithm
+}
+
+// ExecutionWindow returns how long an algo order may work, capped so it finishes before the next scan
+func ExecutionWindow(config *store.ExecutionConfig, scanInterval time.Duration) time.Duration {
+	window := defaultExecutionWindow
+	if config != nil && config.DurationSeconds > 0 {
+		window = time.Duration(config.DurationSeconds) * time.Second
+	}
+	if limit := scanInterval * 4 / 5; scanInterval > 0 && window > limit {
+		window = limit
+	}
+	return window
+}
+
+// SliceQuantity splits quantity into up to n child quantities that each pass the contract spec at price
+// Slices are floored to the lot step and the last one takes the remainder, so they always sum to quantity
+func SliceQuantity(quantity float64, n int, spec *ContractSpec, price float64) []float64 {
+	if n < 1 {
+		n = 1
+	}
+	for ; n > 1; n-- {
+		slice := spec.FloorQty(quantity / float64(n))
+		if spec.CheckOrder(slice, price) == nil {
+			break
+		}
+	}
+
+	slices := make([]float64, 0, n)
+	remaining := quantity
+	for i := 0; i < n-1; i++ {
+		slice := spec.FloorQty(quantity / float64(n))
+		slices = append(slices, slice)
+		remaining -= slice
+	}
+	return append(slices, spec.FloorQty(remaining+1e-12))
+}
+
+// POVChildQuantity sizes a percent-of-volume child order from the volume traded over the last interval
+// Returns 0 when nothing traded, so the algo waits instead of becoming the only flow in the book
+func POVChildQuantity(participationPct, intervalVolume, remaining float64, spec *ContractSpec) float64 {
+	if participationPct <= 0 {
+		participationPct = defaultParticipationPct
+	}
+	qty := spec.FloorQty(intervalVolume * participationPct / 100)
+	if qty > remaining {
+		qty = remaining
+	}
+	return qty
+}
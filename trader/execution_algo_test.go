@@ -0,0 +1,214 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/store"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSelectExecutionAlgo(t *testing.T) {
+	config := &store.ExecutionConfig{Algorithm: "twap", MinNotionalUSD: 5000}
+
+	tests := []struct {
+		name      string
+		config    *store.ExecutionConfig
+		requested string
+		notional  float64
+		want      string
+	}{
+		{"no config", nil, "pov", 50000, ExecutionMarket},
+		{"below threshold", config, "", 1000, ExecutionMarket},
+		{"above threshold", config, "", 8000, ExecutionTWAP},
+		{"override not allowed", config, "iceberg", 8000, ExecutionTWAP},
+		{"override allowed", &store.ExecutionConfig{Algorithm: "twap", MinNotionalUSD: 5000, AllowAIOverride: true}, "iceberg", 1000, ExecutionIceberg},
+		{"unknown algorithm", &store.ExecutionConfig{Algorithm: "vwap"}, "", 8000, ExecutionMarket},
+	}
+	for _, tt := range tests {
+		if got := SelectExecutionAlgo(tt.config, tt.requested, tt.notional); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+
+	if got := ExecutionWindow(&store.ExecutionConfig{DurationSeconds: 600}, 3*time.Minute); got != 144*time.Second {
+		t.Errorf("expected window capped to 144s, got %v", got)
+	}
+}
+
+func TestSliceQuantity(t *testing.T) {
+	spec := &ContractSpec{QtyStep: 0.01, MinNotional: 5}
+
+	slices := SliceQuantity(1.07, 5, spec, 100)
+	if len(slices) != 5 {
+		t.Fatalf("expected 5 slices, got %v", slices)
+	}
+	sum := 0.0
+	for _, s := range slices[:4] {
+		if s != 0.21 {
+			t.Errorf("expected 0.21 slices, got %v", slices)
+		}
+		sum += s
+	}
+	sum += slices[4]
+	if math.Abs(sum-1.07) > 1e-9 || math.Abs(slices[4]-0.23) > 1e-9 {
+		t.Errorf("expected slices to sum to 1.07 with 0.23 last, got %v", slices)
+	}
+
+	// 0.12 at 100 is 12 USDT: only two slices clear the 5 USDT minimum
+	if slices := SliceQuantity(0.12, 5, spec, 100); len(slices) != 2 {
+		t.Errorf("expected slice count reduced to 2, got %v", slices)
+	}
+
+	if got := POVChildQuantity(10, 3.456, 1, spec); got != 0.34 {
+		t.Errorf("expected POV child 0.34, got %v", got)
+	}
+	if got := POVChildQuantity(10, 50, 1, spec); got != 1 {
+		t.Errorf("expected POV child capped at remaining 1, got %v", got)
+	}
+}
+
+// algoFakeTrader fills market orders in memory and records the protective orders placed against them
+type algoFakeTrader struct {
+	Trader // Methods the execution algorithms don't use are left unimplemented
+
+	sent        int
+	unconfirmed bool // GetOrderStatus can't find the orders
+	stopLosses  []float64
+	takeProfits []float64
+}
+
+func (f *algoFakeTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	f.sent++
+	return map[string]interface{}{"orderId": fmt.Sprintf("c%d", f.sent)}, nil
+}
+
+func (f *algoFakeTrader) GetOrderStatus(symbol, orderID string) (map[string]interface{}, error) {
+	if f.unconfirmed {
+		return nil, fmt.Errorf("order %s not found", orderID)
+	}
+	return map[string]interface{}{"status": "FILLED", "executedQty": 1.0, "avgPrice": 100.0}, nil
+}
+
+func (f *algoFakeTrader) GetMarketPrice(symbol string) (float64, error) { return 100, nil }
+
+func (f *algoFakeTrader) CancelStopLossOrders(symbol string) error { return nil }
+
+func (f *algoFakeTrader) CancelTakeProfitOrders(symbol string) error { return nil }
+
+func (f *algoFakeTrader) SetStopLoss(symbol, positionSide string, quantity, stopPrice float64) error {
+	f.stopLosses = append(f.stopLosses, quantity)
+	return nil
+}
+
+func (f *algoFakeTrader) SetTakeProfit(symbol, positionSide string, quantity, takeProfitPrice float64) error {
+	f.takeProfits = append(f.takeProfits, quantity)
+	return nil
+}
+
+func newAlgoTestTrader(t *testing.T) (*AutoTrader, *algoFakeTrader) {
+	st, err := store.New(filepath.Join(t.TempDir(), "algo.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	old := algoFillPollInterval
+	algoFillPollInterval = time.Millisecond
+	t.Cleanup(func() { algoFillPollInterval = old })
+
+	exchange := &algoFakeTrader{}
+	at := &AutoTrader{
+		id:         "algo-trader",
+		exchange:   "binance",
+		store:      st,
+		trader:     exchange,
+		algoOrders: make(map[string]int64),
+		config: AutoTraderConfig{ScanInterval: 100 * time.Millisecond, StrategyConfig: &store.StrategyConfig{
+			Execution: &store.ExecutionConfig{Algorithm: "twap", Slices: 2},
+		}},
+	}
+	return at, exchange
+}
+
+func TestAlgoOrderWorksInBackground(t *testing.T) {
+	at, exchange := newAlgoTestTrader(t)
+	decision := &kernel.Decision{Symbol: "ALGOTESTUSDT", StopLoss: 90, TakeProfit: 120}
+
+	order, err := at.executeOrder(decision, "open_long", 2, 100, 1, "cid-1",
+		func(filled, avgPrice float64, done bool) {
+			at.protectAlgoOpen(decision, "LONG", filled, avgPrice, done)
+		})
+	if err != nil || !isAlgoOrder(order) {
+		t.Fatalf("expected an algo parent order, got %v (err %v)", order, err)
+	}
+	if _, err := at.executeOrder(decision, "open_long", 2, 100, 1, "cid-2", nil); err == nil {
+		t.Error("expected a second order on the symbol to be refused while the first is working")
+	}
+	at.monitorWg.Wait()
+
+	if exchange.sent != 2 {
+		t.Fatalf("expected 2 child orders, got %d", exchange.sent)
+	}
+	// Resized after each child fill, then once more when the parent finishes
+	if want := []float64{1, 2, 2}; fmt.Sprint(exchange.stopLosses) != fmt.Sprint(want) || fmt.Sprint(exchange.takeProfits) != fmt.Sprint(want) {
+		t.Errorf("expected stop-loss and take-profit sized %v, got %v / %v", want, exchange.stopLosses, exchange.takeProfits)
+	}
+	if _, working := at.workingAlgoOrder(decision.Symbol); working {
+		t.Error("expected the symbol released once the parent finished")
+	}
+	parent, _ := at.store.Order().GetOrderByExchangeID(at.exchangeID, "algo-cid-1")
+	if parent == nil || parent.Status != "FILLED" || parent.FilledQuantity != 2 {
+		t.Errorf("expected the parent filled for 2, got %+v", parent)
+	}
+}
+
+func TestAlgoMarketSliceUnknownStatus(t *testing.T) {
+	at, exchange := newAlgoTestTrader(t)
+	exchange.unconfirmed = true
+	decision := &kernel.Decision{Symbol: "ALGOTESTUSDT", StopLoss: 90, TakeProfit: 120}
+
+	if _, err := at.executeOrder(decision, "open_long", 2, 100, 1, "cid-1",
+		func(filled, avgPrice float64, done bool) {
+			at.protectAlgoOpen(decision, "LONG", filled, avgPrice, done)
+		}); err != nil {
+		t.Fatalf("executeOrder: %v", err)
+	}
+	at.monitorWg.Wait()
+
+	if exchange.sent != 1 {
+		t.Errorf("expected the algo to stop after an unconfirmed child, sent %d", exchange.sent)
+	}
+	parent, _ := at.store.Order().GetOrderByExchangeID(at.exchangeID, "algo-cid-1")
+	if parent == nil || parent.Status != "CANCELED" || parent.FilledQuantity != 0 {
+		t.Errorf("expected the parent cancelled with nothing counted as filled, got %+v", parent)
+	}
+	children, _ := at.store.Order().GetChildOrders(parent.ID)
+	if len(children) != 1 || children[0].Status != "NEW" || children[0].FilledQuantity != 0 {
+		t.Errorf("expected one unconfirmed child order, got %+v", children)
+	}
+	if len(exchange.stopLosses) != 0 {
+		t.Errorf("expected no stop-loss for an unconfirmed fill, got %v", exchange.stopLosses)
+	}
+}
+
+func TestWorkAlgoOrderLeavesDustPartiallyFilled(t *testing.T) {
+	at, exchange := newAlgoTestTrader(t)
+	parent := at.createOrderRecord("algo-cid-1", "ALGOTESTUSDT", "open_long", "LONG", 2.5, 100, 1)
+	parent.FilledQuantity, parent.AvgFillPrice = 2, 100
+	if err := at.store.Order().CreateOrder(parent); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	// 0.5 left at 100 is 50 USDT, under the 60 USDT minimum: the market sweep can't send it
+	w := &algoWork{parent: parent, action: "open_long", symbol: "ALGOTESTUSDT", price: 100, leverage: 1,
+		spec: &ContractSpec{QtyStep: 0.01, MinNotional: 60}}
+	at.workAlgoOrder(w, time.Now())
+
+	if exchange.sent != 0 {
+		t.Errorf("expected no order for the dust, sent %d", exchange.sent)
+	}
+	stored, _ := at.store.Order().GetOrderByExchangeID(at.exchangeID, "algo-cid-1")
+	if stored == nil || stored.Status != "PARTIALLY_FILLED" || stored.FilledQuantity != 2 {
+		t.Errorf("expected the parent partially filled for 2, got %+v", stored)
+	}
+}
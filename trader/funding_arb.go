@@ -0,0 +1,89 @@
+package trader
+
+import (
+	"math"
+	"nofx/market"
+	"sort"
+)
+
+// hoursPerYear converts hourly funding rates to APR
+const hoursPerYear = 24 * 365
+
+// FundingArbCandidate is the best long/short venue split for one symbol
+type FundingArbCandidate struct {
+	Symbol       string
+	LongVenueID  string // Exchange account paying the lowest funding
+	ShortVenueID string // Exchange account charging the highest funding
+	LongRate     *market.VenueFundingRate
+	ShortRate    *market.VenueFundingRate
+	SpreadAPR    float64 // Annualised funding collected on the pair (%)
+}
+
+// FundingSpreadAPR returns the annualised funding earned by going long at longRate and short at shortRate (%)
+func FundingSpreadAPR(longRate, shortRate *market.VenueFundingRate) float64 {
+	return (shortRate.HourlyRate() - longRate.HourlyRate()) * hoursPerYear * 100
+}
+
+// PickFundingArbPair picks the venue pair with the widest funding spread for a symbol
+// rates is keyed by exchange account ID; returns false when fewer than two venues quote the symbol
+func PickFundingArbPair(symbol string, rates map[string]*market.VenueFundingRate) (*FundingArbCandidate, bool) {
+	if len(rates) < 2 {
+		return nil, false
+	}
+
+	// Sort IDs so ties resolve the same way every cycle
+	ids := make([]string, 0, len(rates))
+	for id := range rates {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	lowID, highID := ids[0], ids[0]
+	for _, id := range ids[1:] {
+		if rates[id].HourlyRate() < rates[lowID].HourlyRate() {
+			lowID = id
+		}
+		if rates[id].HourlyRate() > rates[highID].HourlyRate() {
+			highID = id
+		}
+	}
+	if lowID == highID {
+		return nil, false
+	}
+
+	return &FundingArbCandidate{
+		Symbol:       symbol,
+		LongVenueID:  lowID,
+		ShortVenueID: highID,
+		LongRate:     rates[lowID],
+		ShortRate:    rates[highID],
+		SpreadAPR:    FundingSpreadAPR(rates[lowID], rates[highID]),
+	}, true
+}
+
+// FundingArbAccrual returns the funding a pair collected over hours at the given hourly rates (USDT)
+// Positive rates mean longs pay shorts, so the short leg earns and the long leg pays
+func FundingArbAccrual(longNotional, shortNotional, longHourly, shortHourly, hours float64) float64 {
+	if hours <= 0 {
+		return 0
+	}
+	return (shortNotional*shortHourly - longNotional*longHourly) * hours
+}
+
+// FundingArbDeltaDriftPct returns how far the leg notionals have drifted apart, as a share of the average leg (%)
+func FundingArbDeltaDriftPct(longNotional, shortNotional float64) float64 {
+	avg := (longNotional + shortNotional) / 2
+	if avg <= 0 {
+		return 0
+	}
+	return math.Abs(longNotional-shortNotional) / avg * 100
+}
+
+// LiquidationDistancePct returns the distance from mark price to liquidation price (%)
+// Returns -1 when the exchange does not report a liquidation price
+func LiquidationDistancePct(markPrice, liquidationPrice float64) float64 {
+	if markPrice <= 0 || liquidationPrice <= 0 {
+		return -1
+	}
+	return math.Abs(markPrice-liquidationPrice) / markPrice * 100
+}
@@ -0,0 +1,156 @@
+package trader
+
+import (
+	"errors"
+	"math"
+	"nofx/market"
+	"nofx/store"
+	"path/filepath"
+	"testing"
+)
+
+func TestPickFundingArbPair(t *testing.T) {
+	rates := map[string]*market.VenueFundingRate{
+		"acct-binance": {Exchange: "binance", Rate: 0.0003, IntervalHours: 8},       // 0.0000375/h
+		"acct-hl":      {Exchange: "hyperliquid", Rate: -0.00001, IntervalHours: 1}, // -0.00001/h
+		"acct-okx":     {Exchange: "okx", Rate: 0.0002, IntervalHours: 4},           // 0.00005/h
+	}
+
+	pair, ok := PickFundingArbPair("ETHUSDT", rates)
+	if !ok {
+		t.Fatal("expected a pair")
+	}
+	if pair.LongVenueID != "acct-hl" || pair.ShortVenueID != "acct-okx" {
+		t.Errorf("expected long hyperliquid / short okx, got %s / %s", pair.LongVenueID, pair.ShortVenueID)
+	}
+	wantAPR := (0.00005 + 0.00001) * 24 * 365 * 100
+	if math.Abs(pair.SpreadAPR-wantAPR) > 1e-9 {
+		t.Errorf("expected spread APR %.4f, got %.4f", wantAPR, pair.SpreadAPR)
+	}
+
+	if _, ok := PickFundingArbPair("ETHUSDT", map[string]*market.VenueFundingRate{"acct-okx": rates["acct-okx"]}); ok {
+		t.Error("expected no pair with a single venue")
+	}
+
+	// 1000 USDT per leg for 8 hours: short earns 0.4, long receives 0.08
+	if got := FundingArbAccrual(1000, 1000, -0.00001, 0.00005, 8); math.Abs(got-0.48) > 1e-9 {
+		t.Errorf("expected accrual 0.48, got %.6f", got)
+	}
+	if got := FundingArbDeltaDriftPct(1050, 950); math.Abs(got-10) > 1e-9 {
+		t.Errorf("expected drift 10%%, got %.4f", got)
+	}
+	if got := LiquidationDistancePct(100, 80); got != 20 {
+		t.Errorf("expected liquidation distance 20%%, got %.4f", got)
+	}
+	if got := LiquidationDistancePct(100, 0); got != -1 {
+		t.Errorf("expected -1 without a liquidation price, got %.4f", got)
+	}
+}
+
+// arbFakeTrader holds one in-memory position per side and fails the orders the test asks it to
+type arbFakeTrader struct {
+	Trader // Methods the pair lifecycle doesn't use are left unimplemented
+
+	long, short float64
+	openErr     error
+	closeErr    error
+}
+
+func (f *arbFakeTrader) GetMarketPrice(symbol string) (float64, error) { return 100, nil }
+
+func (f *arbFakeTrader) SetMarginMode(symbol string, isCrossMargin bool) error { return nil }
+
+func (f *arbFakeTrader) GetPositions() ([]map[string]interface{}, error) {
+	var positions []map[string]interface{}
+	if f.long > 0 {
+		positions = append(positions, map[string]interface{}{"symbol": "BTCUSDT", "side": "long", "positionAmt": f.long, "entryPrice": 100.0, "markPrice": 100.0})
+	}
+	if f.short > 0 {
+		positions = append(positions, map[string]interface{}{"symbol": "BTCUSDT", "side": "short", "positionAmt": -f.short, "entryPrice": 100.0, "markPrice": 100.0})
+	}
+	return positions, nil
+}
+
+func (f *arbFakeTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	if f.openErr != nil {
+		return nil, f.openErr
+	}
+	f.long += quantity
+	return map[string]interface{}{"orderId": "long-1"}, nil
+}
+
+func (f *arbFakeTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	if f.openErr != nil {
+		return nil, f.openErr
+	}
+	f.short += quantity
+	return map[string]interface{}{"orderId": "short-1"}, nil
+}
+
+func (f *arbFakeTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	if f.closeErr != nil {
+		return nil, f.closeErr
+	}
+	f.long = 0
+	return map[string]interface{}{"orderId": float64(7100000001)}, nil // Numeric IDs decode from JSON as float64
+}
+
+func TestFundingArbHalfOpenPairUnwinds(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "arb.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	longVenue := &arbFakeTrader{closeErr: errors.New("exchange unavailable")}
+	shortVenue := &arbFakeTrader{openErr: errors.New("insufficient margin")}
+	at := &AutoTrader{
+		id:         "arb-trader",
+		exchangeID: "acct-long",
+		store:      st,
+		config: AutoTraderConfig{StrategyConfig: &store.StrategyConfig{StrategyType: "funding_arb", FundingArbConfig: &store.FundingArbStrategyConfig{
+			Leverage: 1, NotionalUSD: 1000, MaxPairs: 1,
+		}}},
+		arbVenues: map[string]*fundingArbVenue{
+			"acct-long":  {exchangeID: "acct-long", exchange: "long-venue", trader: longVenue},
+			"acct-short": {exchangeID: "acct-short", exchange: "short-venue", trader: shortVenue},
+		},
+	}
+
+	c := &FundingArbCandidate{Symbol: "BTCUSDT", LongVenueID: "acct-long", ShortVenueID: "acct-short",
+		LongRate: &market.VenueFundingRate{IntervalHours: 8}, ShortRate: &market.VenueFundingRate{IntervalHours: 8}}
+	if err := at.openFundingArbPair(c); err == nil {
+		t.Fatal("expected the short leg failure to be reported")
+	}
+	pairs, _ := st.FundingArb().GetOpenPairs(at.id)
+	if len(pairs) != 1 || pairs[0].Status != store.FundingArbPairUnwinding || pairs[0].LongQuantity != 10 {
+		t.Fatalf("expected one unwinding pair holding the 10 unit long leg, got %+v", pairs)
+	}
+
+	// The close keeps failing: the pair stays unwinding
+	if err := at.RunFundingArbCycle(); err != nil {
+		t.Fatalf("RunFundingArbCycle: %v", err)
+	}
+	if pairs, _ = st.FundingArb().GetOpenPairs(at.id); len(pairs) != 1 || longVenue.long != 10 {
+		t.Fatalf("expected the pair still unwinding after a failed retry, got %+v", pairs)
+	}
+
+	longVenue.closeErr = nil
+	if err := at.RunFundingArbCycle(); err != nil {
+		t.Fatalf("RunFundingArbCycle: %v", err)
+	}
+	if pairs, _ = st.FundingArb().GetOpenPairs(at.id); len(pairs) != 0 || longVenue.long != 0 {
+		t.Fatalf("expected the long leg closed and the pair settled, got %+v (long %.2f)", pairs, longVenue.long)
+	}
+	closed, _ := st.FundingArb().ListPairs(at.id, 1)
+	if closed[0].Status != store.FundingArbPairClosed || closed[0].CloseReason != store.FundingArbCloseOpenFailed {
+		t.Errorf("expected the pair closed as %s, got %s/%s", store.FundingArbCloseOpenFailed, closed[0].Status, closed[0].CloseReason)
+	}
+
+	// OrderSync records the unwind fill on the trader's own venue under its trade ID; it gets the unwind label
+	exits := syncTestFills(t, st, at.id,
+		TradeRecord{TradeID: "9001", OrderID: "7100000000", Symbol: "BTCUSDT", OrderAction: "open_long", Price: 100, Quantity: 10},
+		TradeRecord{TradeID: "9002", OrderID: "7100000001", Symbol: "BTCUSDT", OrderAction: "close_long", Price: 100, Quantity: 10},
+	)
+	if want := "funding_arb_" + store.FundingArbCloseOpenFailed; len(exits) != 1 || exits[0].Reason != want {
+		t.Errorf("Expected the unwind exit labelled %s, got %+v", want, exits)
+	}
+}
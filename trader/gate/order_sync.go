@@ -201,7 +201,7 @@ func (t *GateTrader) SyncOrdersFromGate(traderID string, exchangeID string, exch
 					traderID, exchangeID, exchangeType,
 					symbol, positionSide, trade.OrderAction,
 					trade.FillQty, trade.FillPrice, trade.Fee, trade.ProfitLoss,
-					execTimeMs, trade.TradeID,
+					execTimeMs, trade.TradeID, trade.OrderID,
 				); err != nil {
 					logger.Infof("  ⚠️ Retry position update for existing trade %s failed: %v", trade.TradeID, err)
 				}
@@ -273,7 +273,7 @@ func (t *GateTrader) SyncOrdersFromGate(traderID string, exchangeID string, exch
 				traderID, exchangeID, exchangeType,
 				symbol, positionSide, trade.OrderAction,
 				trade.FillQty, trade.FillPrice, trade.Fee, trade.ProfitLoss,
-				execTimeMs, trade.TradeID,
+				execTimeMs, trade.TradeID, trade.OrderID,
 			); err != nil {
 				logger.Infof("  ⚠️ Failed to sync position for trade %s: %v", trade.TradeID, err)
 			} else {
@@ -215,6 +215,32 @@ func (t *GateTrader) getContract(symbol string) (*gateapi.Contract, error) {
 	return &contract, nil
 }
 
+// GetContractSpec gets tick size, lot step, minimums and max leverage in base-asset units
+// Gate sizes orders in contracts of quanto_multiplier base currency each
+func (t *GateTrader) GetContractSpec(symbol string) (*types.ContractSpec, error) {
+	contract, err := t.getContract(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	quantoMultiplier, _ := strconv.ParseFloat(contract.QuantoMultiplier, 64)
+	if quantoMultiplier <= 0 {
+		quantoMultiplier = 1
+	}
+	tickSize, _ := strconv.ParseFloat(contract.OrderPriceRound, 64)
+	maxLeverage, _ := strconv.ParseFloat(contract.LeverageMax, 64)
+	return &types.ContractSpec{
+		Symbol:             symbol,
+		Exchange:           "gate",
+		TickSize:           tickSize,
+		QtyStep:            quantoMultiplier,
+		MinQty:             float64(contract.OrderSizeMin) * quantoMultiplier,
+		MaxLeverage:        int(maxLeverage),
+		ContractMultiplier: quantoMultiplier,
+		UpdatedAt:          time.Now(),
+	}, nil
+}
+
 // SetLeverage sets the leverage for a symbol
 func (t *GateTrader) SetLeverage(symbol string, leverage int) error {
 	symbol = t.convertSymbol(symbol)
@@ -244,6 +270,16 @@ func (t *GateTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
 
 // OpenLong opens a long position
 func (t *GateTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, "")
+}
+
+// OpenLongWithClientID is OpenLong with a caller-supplied client order ID (retry-safe)
+func (t *GateTrader) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, clientOrderID)
+}
+
+// openLong opens a long position using the given Gate client order ID (empty for none)
+func (t *GateTrader) openLong(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	symbol = t.convertSymbol(symbol)
 
 	// Cancel old orders first
@@ -278,6 +314,10 @@ func (t *GateTrader) OpenLong(symbol string, quantity float64, leverage int) (ma
 
 	logger.Infof("  [Gate] OpenLong: symbol=%s, size=%d, leverage=%d", symbol, size, leverage)
 
+	if clientOrderID != "" {
+		order.Text = "t-" + clientOrderID
+	}
+
 	result, _, err := t.client.FuturesApi.CreateFuturesOrder(t.ctx, "usdt", order, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open long position: %w", err)
@@ -302,6 +342,16 @@ func (t *GateTrader) OpenLong(symbol string, quantity float64, leverage int) (ma
 
 // OpenShort opens a short position
 func (t *GateTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, "")
+}
+
+// OpenShortWithClientID is OpenShort with a caller-supplied client order ID (retry-safe)
+func (t *GateTrader) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, clientOrderID)
+}
+
+// openShort opens a short position using the given Gate client order ID (empty for none)
+func (t *GateTrader) openShort(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	symbol = t.convertSymbol(symbol)
 
 	// Cancel old orders first
@@ -335,6 +385,10 @@ func (t *GateTrader) OpenShort(symbol string, quantity float64, leverage int) (m
 
 	logger.Infof("  [Gate] OpenShort: symbol=%s, size=%d, leverage=%d", symbol, -size, leverage)
 
+	if clientOrderID != "" {
+		order.Text = "t-" + clientOrderID
+	}
+
 	result, _, err := t.client.FuturesApi.CreateFuturesOrder(t.ctx, "usdt", order, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open short position: %w", err)
@@ -359,6 +413,16 @@ func (t *GateTrader) OpenShort(symbol string, quantity float64, leverage int) (m
 
 // CloseLong closes a long position
 func (t *GateTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, "")
+}
+
+// CloseLongWithClientID is CloseLong with a caller-supplied client order ID (retry-safe)
+func (t *GateTrader) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, clientOrderID)
+}
+
+// closeLong closes a long position using the given Gate client order ID (empty for none)
+func (t *GateTrader) closeLong(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	symbol = t.convertSymbol(symbol)
 
 	// If quantity is 0, get current position
@@ -403,6 +467,10 @@ func (t *GateTrader) CloseLong(symbol string, quantity float64) (map[string]inte
 
 	logger.Infof("  [Gate] CloseLong: symbol=%s, size=%d", symbol, -size)
 
+	if clientOrderID != "" {
+		order.Text = "t-" + clientOrderID
+	}
+
 	result, _, err := t.client.FuturesApi.CreateFuturesOrder(t.ctx, "usdt", order, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to close long position: %w", err)
@@ -427,6 +495,16 @@ func (t *GateTrader) CloseLong(symbol string, quantity float64) (map[string]inte
 
 // CloseShort closes a short position
 func (t *GateTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, "")
+}
+
+// CloseShortWithClientID is CloseShort with a caller-supplied client order ID (retry-safe)
+func (t *GateTrader) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, clientOrderID)
+}
+
+// closeShort closes a short position using the given Gate client order ID (empty for none)
+func (t *GateTrader) closeShort(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	symbol = t.convertSymbol(symbol)
 
 	// If quantity is 0, get current position
@@ -476,6 +554,10 @@ func (t *GateTrader) CloseShort(symbol string, quantity float64) (map[string]int
 
 	logger.Infof("  [Gate] CloseShort: symbol=%s, size=%d", symbol, size)
 
+	if clientOrderID != "" {
+		order.Text = "t-" + clientOrderID
+	}
+
 	result, _, err := t.client.FuturesApi.CreateFuturesOrder(t.ctx, "usdt", order, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to close short position: %w", err)
@@ -622,6 +704,12 @@ func (t *GateTrader) SetTakeProfit(symbol string, positionSide string, quantity,
 	return nil
 }
 
+// SetTrailingStop Gate has no native trailing stop order
+// Returns ErrTrailingStopNotSupported so the caller emulates it by ratcheting the stop-loss
+func (t *GateTrader) SetTrailingStop(symbol string, positionSide string, quantity, callbackRate, activationPrice float64) error {
+	return types.ErrTrailingStopNotSupported
+}
+
 // CancelStopLossOrders cancels stop loss orders
 func (t *GateTrader) CancelStopLossOrders(symbol string) error {
 	return t.cancelTriggerOrders(symbol, "stop_loss")
@@ -701,6 +789,21 @@ func (t *GateTrader) FormatQuantity(symbol string, quantity float64) (string, er
 	return fmt.Sprintf("%.4f", quantity), nil
 }
 
+// GetOrderByClientID looks up an order by the t- text passed to *WithClientID
+// Gate resolves custom IDs for open orders and for 60 seconds after they finish, which covers a timed-out submit
+// Returns nil, nil if Gate has no such order
+func (t *GateTrader) GetOrderByClientID(symbol, clientOrderID string) (map[string]interface{}, error) {
+	order, err := t.GetOrderStatus(symbol, "t-"+clientOrderID)
+	if err != nil {
+		if strings.Contains(err.Error(), "ORDER_NOT_FOUND") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order by client ID: %w", err)
+	}
+	order["clientOrderId"] = clientOrderID
+	return order, nil
+}
+
 // GetOrderStatus gets the status of an order
 func (t *GateTrader) GetOrderStatus(symbol string, orderID string) (map[string]interface{}, error) {
 	symbol = t.convertSymbol(symbol)
@@ -749,7 +852,7 @@ func (t *GateTrader) GetOrderStatus(symbol string, orderID string) (map[string]i
 	executedQty := math.Abs(float64(order.Size-order.Left)) * quantoMultiplier
 
 	return map[string]interface{}{
-		"orderId":     orderID,
+		"orderId":     fmt.Sprintf("%d", order.Id),
 		"symbol":      t.revertSymbol(symbol),
 		"status":      status,
 		"avgPrice":    fillPrice,
@@ -842,6 +945,8 @@ func (t *GateTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 			Price:    price,
 			Quantity: quantity,
 			Status:   "NEW",
+
+			ClientOrderID: strings.TrimPrefix(order.Text, "t-"),
 		})
 	}
 
@@ -883,6 +988,124 @@ func (t *GateTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 	return result, nil
 }
 
+// PlaceLimitOrder places a limit order for grid trading
+// Implements GridTrader interface
+func (t *GateTrader) PlaceLimitOrder(req *types.LimitOrderRequest) (*types.LimitOrderResult, error) {
+	symbol := t.convertSymbol(req.Symbol)
+
+	contract, err := t.getContract(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Leverage > 0 {
+		if err := t.SetLeverage(symbol, req.Leverage); err != nil {
+			logger.Warnf("  [Gate] Failed to set leverage: %v", err)
+		}
+	}
+
+	// Gate sizes orders in contracts, signed by side (negative = sell)
+	quantoMultiplier, _ := strconv.ParseFloat(contract.QuantoMultiplier, 64)
+	if quantoMultiplier <= 0 {
+		quantoMultiplier = 1
+	}
+	size := int64(math.Round(req.Quantity / quantoMultiplier))
+	if size <= 0 {
+		return nil, fmt.Errorf("quantity %.8f is below one contract (%s)", req.Quantity, contract.QuantoMultiplier)
+	}
+	if req.Side == "SELL" {
+		size = -size
+	}
+
+	price := req.Price
+	if tick, _ := strconv.ParseFloat(contract.OrderPriceRound, 64); tick > 0 {
+		price = math.Round(price/tick) * tick
+	}
+
+	// poc (pending-or-cancelled) is Gate's post-only time in force
+	tif := "gtc"
+	if req.PostOnly {
+		tif = "poc"
+	}
+
+	// Custom order text must carry the t- prefix
+	text := "t-nofx"
+	if req.ClientID != "" {
+		text = "t-" + req.ClientID
+	}
+
+	order := gateapi.FuturesOrder{
+		Contract:   symbol,
+		Size:       size,
+		Price:      strconv.FormatFloat(price, 'f', -1, 64),
+		Tif:        tif,
+		ReduceOnly: req.ReduceOnly,
+		Text:       text,
+	}
+
+	logger.Infof("  [Gate] PlaceLimitOrder: %s size=%d @ %s, tif=%s", symbol, size, order.Price, tif)
+
+	result, _, err := t.client.FuturesApi.CreateFuturesOrder(t.ctx, "usdt", order, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to place limit order: %w", err)
+	}
+
+	return &types.LimitOrderResult{
+		OrderID:      fmt.Sprintf("%d", result.Id),
+		ClientID:     req.ClientID,
+		Symbol:       req.Symbol,
+		Side:         req.Side,
+		PositionSide: req.PositionSide,
+		Price:        price,
+		Quantity:     math.Abs(float64(size)) * quantoMultiplier,
+		Status:       "NEW",
+	}, nil
+}
+
+// CancelOrder cancels a specific order by ID
+// Implements GridTrader interface
+func (t *GateTrader) CancelOrder(symbol, orderID string) error {
+	_, _, err := t.client.FuturesApi.CancelFuturesOrder(t.ctx, "usdt", orderID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to cancel order: %w", err)
+	}
+
+	logger.Infof("  [Gate] Order cancelled: %s %s", symbol, orderID)
+	return nil
+}
+
+// GetOrderBook gets the order book for a symbol, sizes converted from contracts to base units
+// Implements GridTrader interface
+func (t *GateTrader) GetOrderBook(symbol string, depth int) (bids, asks [][]float64, err error) {
+	symbol = t.convertSymbol(symbol)
+
+	quantoMultiplier := 1.0
+	if contract, err := t.getContract(symbol); err == nil {
+		if qm, _ := strconv.ParseFloat(contract.QuantoMultiplier, 64); qm > 0 {
+			quantoMultiplier = qm
+		}
+	}
+
+	opts := &gateapi.ListFuturesOrderBookOpts{
+		Limit: optional.NewInt32(int32(depth)),
+	}
+	book, _, err := t.client.FuturesApi.ListFuturesOrderBook(t.ctx, "usdt", symbol, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get order book: %w", err)
+	}
+
+	for _, b := range book.Bids {
+		price, _ := strconv.ParseFloat(b.P, 64)
+		bids = append(bids, []float64{price, float64(b.S) * quantoMultiplier})
+	}
+	for _, a := range book.Asks {
+		price, _ := strconv.ParseFloat(a.P, 64)
+		asks = append(asks, []float64{price, float64(a.S) * quantoMultiplier})
+	}
+
+	return bids, asks, nil
+}
+
 // clearCache clears all caches
 func (t *GateTrader) clearCache() {
 	t.balanceCacheMutex.Lock()
@@ -896,3 +1119,4 @@ func (t *GateTrader) clearCache() {
 
 // Ensure GateTrader implements Trader interface
 var _ types.Trader = (*GateTrader)(nil)
+var _ types.GridTrader = (*GateTrader)(nil)
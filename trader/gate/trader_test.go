@@ -2,12 +2,15 @@ package gate
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/gateio/gateapi-go/v6"
 	"github.com/stretchr/testify/assert"
 	"nofx/trader/testutil"
 	"nofx/trader/types"
@@ -335,3 +338,76 @@ func TestGateTrader_MockServerResponseFormat(t *testing.T) {
 	assert.NotNil(t, suite.mockServer)
 	assert.NotEmpty(t, suite.mockServer.URL)
 }
+
+// ============================================================
+// Part 4: GridTrader conformance
+// ============================================================
+
+// TestGateTrader_GridTraderConformance runs the GridTrader conformance suite against a mock Gate server
+// BTC_USDT contracts are 0.001 BTC, so order and book sizes travel as signed contract counts
+func TestGateTrader_GridTraderConformance(t *testing.T) {
+	recorder := &testutil.GridMockRecorder{}
+	book := func(levels [][2]string) []map[string]interface{} {
+		var out []map[string]interface{}
+		for _, level := range levels {
+			size, _ := strconv.ParseFloat(level[1], 64)
+			out = append(out, map[string]interface{}{"p": level[0], "s": int64(math.Round(size / 0.001))})
+		}
+		return out
+	}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var respBody interface{}
+
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/futures/usdt/orders":
+			var order gateapi.FuturesOrder
+			json.NewDecoder(r.Body).Decode(&order)
+			side := "BUY"
+			if order.Size < 0 {
+				side = "SELL"
+			}
+			price, _ := strconv.ParseFloat(order.Price, 64)
+			recorder.RecordOrder(testutil.GridOrderCapture{
+				Side:       side,
+				Price:      price,
+				PostOnly:   order.Tif == "poc",
+				ReduceOnly: order.ReduceOnly,
+				ClientID:   strings.TrimPrefix(order.Text, "t-"),
+			})
+			order.Id = 900001
+			order.Status = "open"
+			respBody = order
+
+		case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/futures/usdt/orders/"):
+			orderID := strings.TrimPrefix(r.URL.Path, "/futures/usdt/orders/")
+			recorder.RecordCancel(orderID)
+			respBody = map[string]interface{}{"id": 900001, "status": "finished", "finish_as": "cancelled"}
+
+		case r.URL.Path == "/futures/usdt/order_book":
+			respBody = map[string]interface{}{
+				"bids": book(testutil.GridMockBids),
+				"asks": book(testutil.GridMockAsks),
+			}
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(respBody)
+	}))
+	defer mockServer.Close()
+
+	trader := NewGateTrader("test_api_key", "test_secret_key")
+	trader.client.GetConfig().BasePath = mockServer.URL
+	trader.contractsCache["BTC_USDT"] = &gateapi.Contract{
+		Name:             "BTC_USDT",
+		QuantoMultiplier: "0.001",
+		OrderPriceRound:  "0.1",
+		OrderSizeMin:     1,
+	}
+
+	testutil.NewGridTraderTestSuite(t, trader, recorder, 0.05).RunAllTests()
+}
@@ -0,0 +1,91 @@
+package trader
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"nofx/store"
+)
+
+// gridBasketFakeTrader quotes a fixed price per symbol; symbols missing from prices fail to quote
+type gridBasketFakeTrader struct {
+	Trader // Methods basket initialization doesn't use are left unimplemented
+
+	mu     sync.Mutex
+	prices map[string]float64
+}
+
+func (f *gridBasketFakeTrader) GetMarketPrice(symbol string) (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if price, ok := f.prices[symbol]; ok {
+		return price, nil
+	}
+	return 0, errors.New("no quote")
+}
+
+func (f *gridBasketFakeTrader) GetPositions() ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *gridBasketFakeTrader) SetLeverage(symbol string, leverage int) error {
+	return nil
+}
+
+func newGridBasketTrader(exchange Trader) *AutoTrader {
+	return &AutoTrader{
+		id:     "basket-trader",
+		trader: exchange,
+		config: AutoTraderConfig{StrategyConfig: &store.StrategyConfig{GridConfig: &store.GridStrategyConfig{
+			GridCount: 5, TotalInvestment: 1000, Leverage: 2,
+			Basket: []store.GridBasketSymbol{
+				{Symbol: "BTCUSDT", UpperPrice: 110, LowerPrice: 90},
+				{Symbol: "ETHUSDT", UpperPrice: 22, LowerPrice: 18},
+			},
+		}}},
+	}
+}
+
+func TestInitializeGridBasketResumes(t *testing.T) {
+	exchange := &gridBasketFakeTrader{prices: map[string]float64{"BTCUSDT": 100}}
+	at := newGridBasketTrader(exchange)
+
+	// ETH can't be quoted yet: BTC comes up on its own and the error names ETH
+	if err := at.InitializeGrid(); err == nil {
+		t.Fatalf("Expected an error for the symbol that failed")
+	}
+	states := at.gridBasketStates()
+	if len(states) != 1 || states[0].Config.Symbol != "BTCUSDT" || at.gridBasketReady() {
+		t.Fatalf("Expected only BTC initialized, got %d states", len(states))
+	}
+	btc := states[0]
+
+	// The retry initializes ETH and keeps BTC's state rather than laying it out again
+	exchange.mu.Lock()
+	exchange.prices["ETHUSDT"] = 20
+	exchange.mu.Unlock()
+	if err := at.InitializeGrid(); err != nil {
+		t.Fatalf("Retry failed: %v", err)
+	}
+	states = at.gridBasketStates()
+	if len(states) != 2 || states[0] != btc || states[1].Config.Symbol != "ETHUSDT" || !at.gridBasketReady() {
+		t.Fatalf("Expected BTC kept and ETH added in config order, got %d states", len(states))
+	}
+}
+
+func TestGetGridRiskInfoReportsEverySymbol(t *testing.T) {
+	exchange := &gridBasketFakeTrader{prices: map[string]float64{"BTCUSDT": 100, "ETHUSDT": 20}}
+	at := newGridBasketTrader(exchange)
+	if err := at.InitializeGrid(); err != nil {
+		t.Fatalf("InitializeGrid: %v", err)
+	}
+
+	info := at.GetGridRiskInfo()
+	if info.Symbol != "BTCUSDT" || len(info.Basket) != 2 {
+		t.Fatalf("Expected BTC on top and both symbols in the basket, got %s with %d entries", info.Symbol, len(info.Basket))
+	}
+	if info.Basket[1].Symbol != "ETHUSDT" || info.Basket[1].CurrentPrice != 20 {
+		t.Errorf("Expected ETH's own risk info, got %+v", info.Basket[1])
+	}
+}
@@ -0,0 +1,287 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"nofx/trader/strategy"
+)
+
+// Grid execution modes (GridStrategyConfig.ExecutionMode)
+const (
+	GridExecutionAI         = "ai"
+	GridExecutionMechanical = "mechanical"
+)
+
+// gridReviewActions are the AI grid actions honoured during a mechanical-mode review
+// Order placement stays with the mechanical engine, which owns the book
+var gridReviewActions = map[string]bool{
+	"pause_grid":        true,
+	"resume_grid":       true,
+	"adjust_grid":       true,
+	"cancel_all_orders": true,
+	"hold":              true,
+}
+
+// isMechanicalGrid returns true if the grid keeps its book filled by rule instead of asking the AI
+func (at *AutoTrader) isMechanicalGrid() bool {
+	gridConfig := at.gridConfig()
+	return gridConfig != nil && gridConfig.ExecutionMode == GridExecutionMechanical
+}
+
+// runMechanicalGridCycle runs one AI-free grid cycle:
+// re-arm the opposite order on each fill, arm empty levels around price, and apply regime limits
+// Breakout, drawdown and skew rules run as usual in RunGridCycle and syncGridState
+func (at *AutoTrader) runMechanicalGridCycle() error {
+	gridConfig := at.gridConfig()
+
+	// Optional periodic AI review of bounds and pause state
+	var review *kernel.FullDecision
+	if gridConfig.AIReviewIntervalCycles > 0 && (at.cycleNumber+1)%gridConfig.AIReviewIntervalCycles == 0 {
+		review = at.reviewGridWithAI()
+	}
+
+	at.gridState.mu.RLock()
+	lower, spacing := at.gridState.LowerPrice, at.gridState.GridSpacing
+	at.gridState.mu.RUnlock()
+
+	// Detect fills (also runs stop loss and the autoAdjustGrid skew rebalance)
+	filled := at.syncGridState()
+
+	at.gridState.mu.RLock()
+	relaid := at.gridState.LowerPrice != lower || at.gridState.GridSpacing != spacing
+	isPaused := at.gridState.IsPaused
+	at.gridState.mu.RUnlock()
+	if isPaused {
+		at.saveMechanicalGridRecord(review, nil)
+		return nil
+	}
+
+	price, err := at.trader.GetMarketPrice(gridConfig.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get market price: %w", err)
+	}
+	leverage, openAllowed := at.applyGridRegime(price)
+
+	var placed []kernel.Decision
+
+	// Re-arm the opposite order one level away from each fill (skipped if the skew rebalance re-laid the grid)
+	if !relaid {
+		for _, level := range filled {
+			if d := at.rearmGridFill(level); d != nil {
+				placed = append(placed, *d)
+			}
+		}
+	}
+
+	// Arm empty levels around price; opening exposure is gated by the regime position limit
+	if openAllowed {
+		at.gridState.mu.RLock()
+		indices := strategy.GridLevelsToArm(at.gridState.Levels, price)
+		levels := make([]kernel.GridLevelInfo, len(indices))
+		for i, idx := range indices {
+			levels[i] = at.gridState.Levels[idx]
+		}
+		at.gridState.mu.RUnlock()
+
+		for _, level := range levels {
+			qty := at.mechanicalLevelQuantity(level, leverage)
+			if d := at.placeMechanicalGridOrder(level.Index, level.Side, qty, "arm level"); d != nil {
+				placed = append(placed, *d)
+			}
+		}
+	}
+
+	at.saveMechanicalGridRecord(review, placed)
+	return nil
+}
+
+// rearmGridFill places the counter order for a filled level
+// A fill that closes a neighbour's round trip books the spacing and re-arms the neighbour's original order
+func (at *AutoTrader) rearmGridFill(level kernel.GridLevelInfo) *kernel.Decision {
+	target, counter := level.Index+1, "sell"
+	if level.Side == "sell" {
+		target, counter = level.Index-1, "buy"
+	}
+
+	at.gridState.mu.Lock()
+	if target < 0 || target >= len(at.gridState.Levels) {
+		at.gridState.mu.Unlock()
+		return nil
+	}
+	neighbour := &at.gridState.Levels[target]
+	if neighbour.State == "pending" {
+		at.gridState.mu.Unlock()
+		return nil
+	}
+
+	qty := level.OrderQuantity
+	profit := 0.0
+	closing := neighbour.State == "filled" && neighbour.Side == counter
+	if closing {
+		qty = neighbour.PositionSize
+		profit = math.Abs(level.Price-neighbour.Price) * math.Min(qty, level.OrderQuantity)
+		self := &at.gridState.Levels[level.Index]
+		self.State = "empty"
+		self.OrderID = ""
+		self.OrderQuantity = 0
+		self.PositionSize = 0
+		self.PositionEntry = 0
+		neighbour.State = "empty"
+		neighbour.PositionSize = 0
+		neighbour.PositionEntry = 0
+		at.gridState.TotalTrades++
+		if profit > 0 {
+			at.gridState.WinningTrades++
+		}
+	}
+	at.gridState.mu.Unlock()
+
+	if closing {
+		at.updateDailyPnL(profit)
+	}
+	return at.placeMechanicalGridOrder(target, counter, qty, fmt.Sprintf("counter for level %d fill", level.Index))
+}
+
+// placeMechanicalGridOrder places a grid limit order on a level through the normal caps and limits
+func (at *AutoTrader) placeMechanicalGridOrder(index int, side string, quantity float64, reason string) *kernel.Decision {
+	gridConfig := at.gridConfig()
+	if quantity <= 0 {
+		return nil
+	}
+
+	at.gridState.mu.Lock()
+	if index < 0 || index >= len(at.gridState.Levels) {
+		at.gridState.mu.Unlock()
+		return nil
+	}
+	at.gridState.Levels[index].Side = side
+	price := at.gridState.Levels[index].Price
+	at.gridState.mu.Unlock()
+
+	d := &kernel.Decision{
+		Symbol:     gridConfig.Symbol,
+		Action:     "place_buy_limit",
+		Price:      price,
+		Quantity:   quantity,
+		LevelIndex: index,
+		Reasoning:  reason,
+	}
+	orderSide := "BUY"
+	if side == "sell" {
+		d.Action = "place_sell_limit"
+		orderSide = "SELL"
+	}
+
+	if err := at.placeGridLimitOrder(d, orderSide); err != nil {
+		logger.Warnf("[Grid] Mechanical %s at level %d failed: %v", d.Action, index, err)
+		return nil
+	}
+	return d
+}
+
+// mechanicalLevelQuantity sizes a level from its allocation, the regime leverage cap and any breakout reduction
+func (at *AutoTrader) mechanicalLevelQuantity(level kernel.GridLevelInfo, leverage int) float64 {
+	if level.Price <= 0 {
+		return 0
+	}
+	at.gridState.mu.RLock()
+	reduction := at.gridState.PositionReductionPct
+	at.gridState.mu.RUnlock()
+
+	return level.AllocatedUSD * float64(leverage) * (1 - reduction/100) / level.Price
+}
+
+// applyGridRegime classifies the current regime and returns the leverage to size new levels with
+// and whether opening exposure is still under the regime position limit
+func (at *AutoTrader) applyGridRegime(price float64) (int, bool) {
+	gridConfig := at.gridConfig()
+	leverage := gridConfig.Leverage
+
+	mktData, err := market.GetWithTimeframes(gridConfig.Symbol, []string{"5m", "4h"}, "5m", 50)
+	if err != nil || price <= 0 {
+		return leverage, true
+	}
+	ctx := kernel.BuildGridContextFromMarketData(mktData, gridConfig)
+	level := classifyRegimeLevel(ctx.BollingerWidth, ctx.ATR14/price*100)
+
+	at.gridState.mu.Lock()
+	at.gridState.CurrentRegimeLevel = string(level)
+	at.gridState.mu.Unlock()
+
+	limits := at.gridRegimeLimits()
+	if limit := getRegimeLeverageLimit(level, limits); limit < leverage {
+		leverage = limit
+	}
+
+	exposure := 0.0
+	if positions, err := at.trader.GetPositions(); err == nil {
+		for _, pos := range positions {
+			if sym, _ := pos["symbol"].(string); sym == gridConfig.Symbol {
+				size, _ := pos["positionAmt"].(float64)
+				exposure = math.Abs(size) * price
+			}
+		}
+	}
+	maxExposure := gridConfig.TotalInvestment * float64(gridConfig.Leverage) * getRegimePositionLimit(level, limits) / 100
+
+	return leverage, exposure < maxExposure
+}
+
+// gridRegimeLimits returns the trader's saved grid config for its regime overrides,
+// or an empty config (built-in defaults) when none is saved
+func (at *AutoTrader) gridRegimeLimits() *store.GridConfigModel {
+	if gs := at.gridStore(); gs != nil {
+		if config, err := gs.LoadGridConfigByTrader(at.id); err == nil {
+			return config
+		}
+	}
+	return &store.GridConfigModel{}
+}
+
+// reviewGridWithAI asks the AI for a bound/pause review and applies only the review actions
+func (at *AutoTrader) reviewGridWithAI() *kernel.FullDecision {
+	gridConfig := at.gridConfig()
+	lang := at.config.StrategyConfig.Language
+	if lang == "" {
+		lang = "en"
+	}
+
+	gridCtx, err := at.buildGridContext()
+	if err != nil {
+		logger.Warnf("[Grid] AI review skipped: %v", err)
+		return nil
+	}
+	decision, err := kernel.GetGridDecisions(gridCtx, at.mcpClient, gridConfig, lang)
+	if err != nil {
+		logger.Warnf("[Grid] AI review failed: %v", err)
+		return nil
+	}
+
+	applied := decision.Decisions[:0]
+	for _, d := range decision.Decisions {
+		if !gridReviewActions[d.Action] {
+			logger.Infof("[Grid] AI review: skipping %s, orders are managed mechanically", d.Action)
+			continue
+		}
+		if err := at.executeGridDecision(&d); err != nil {
+			logger.Warnf("[Grid] AI review action %s failed: %v", d.Action, err)
+		}
+		applied = append(applied, d)
+	}
+	decision.Decisions = applied
+	return decision
+}
+
+// saveMechanicalGridRecord logs the cycle's orders, merged into the AI review record when one ran
+func (at *AutoTrader) saveMechanicalGridRecord(review *kernel.FullDecision, placed []kernel.Decision) {
+	record := review
+	if record == nil {
+		record = &kernel.FullDecision{}
+	}
+	record.Decisions = append(record.Decisions, placed...)
+	at.saveGridDecisionRecord(record)
+}
@@ -0,0 +1,124 @@
+package trader
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"nofx/kernel"
+	"nofx/store"
+)
+
+// mechanicalGridFakeTrader accepts every grid limit order and holds no positions
+type mechanicalGridFakeTrader struct {
+	Trader // Methods the mechanical engine doesn't use are left unimplemented
+
+	placed []*LimitOrderRequest
+}
+
+func (f *mechanicalGridFakeTrader) GetPositions() ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *mechanicalGridFakeTrader) PlaceLimitOrder(req *LimitOrderRequest) (*LimitOrderResult, error) {
+	f.placed = append(f.placed, req)
+	return &LimitOrderResult{OrderID: fmt.Sprintf("o%d", len(f.placed)), Status: "NEW"}, nil
+}
+
+func (f *mechanicalGridFakeTrader) CancelOrder(symbol, orderID string) error {
+	return nil
+}
+
+func (f *mechanicalGridFakeTrader) GetOrderBook(symbol string, depth int) (bids, asks [][]float64, err error) {
+	return nil, nil, nil
+}
+
+func newMechanicalGridTrader(st *store.Store, exchange Trader) *AutoTrader {
+	at := newGridRecoveryTrader(st, exchange, &store.GridStrategyConfig{
+		Symbol: "BTCUSDT", GridCount: 5, TotalInvestment: 1000, Leverage: 1,
+		UpperPrice: 110, LowerPrice: 90, ExecutionMode: GridExecutionMechanical,
+	})
+	at.gridState.LowerPrice, at.gridState.UpperPrice, at.gridState.GridSpacing = 90, 110, 5
+	at.gridState.Levels = []kernel.GridLevelInfo{
+		{Index: 0, Price: 90, State: "empty", Side: "buy", AllocatedUSD: 200},
+		{Index: 1, Price: 95, State: "filled", Side: "buy", AllocatedUSD: 200, OrderQuantity: 1, PositionSize: 1, PositionEntry: 95},
+		{Index: 2, Price: 100, State: "filled", Side: "sell", AllocatedUSD: 200, OrderQuantity: 1, PositionSize: 1, PositionEntry: 100},
+		{Index: 3, Price: 105, State: "empty", Side: "sell", AllocatedUSD: 200},
+		{Index: 4, Price: 110, State: "empty", Side: "sell", AllocatedUSD: 200},
+	}
+	return at
+}
+
+func TestRearmGridFill(t *testing.T) {
+	t.Run("closing fill books a round trip", func(t *testing.T) {
+		exchange := &mechanicalGridFakeTrader{}
+		at := newMechanicalGridTrader(nil, exchange)
+
+		// The 100 sell closes the long the 95 buy opened and re-arms the buy
+		d := at.rearmGridFill(at.gridState.Levels[2])
+		if d == nil || d.Action != "place_buy_limit" || d.LevelIndex != 1 {
+			t.Fatalf("Expected the buy re-armed at level 1, got %+v", d)
+		}
+		if at.gridState.TotalTrades != 1 || at.gridState.WinningTrades != 1 {
+			t.Errorf("Expected one winning round trip, got total=%d winning=%d", at.gridState.TotalTrades, at.gridState.WinningTrades)
+		}
+		if at.gridState.TotalProfit != 5 {
+			t.Errorf("Expected one spacing of profit, got %.2f", at.gridState.TotalProfit)
+		}
+		if at.gridState.Levels[2].State != "empty" {
+			t.Errorf("The closing level should be freed, got %s", at.gridState.Levels[2].State)
+		}
+	})
+
+	t.Run("opening fill only places the counter order", func(t *testing.T) {
+		exchange := &mechanicalGridFakeTrader{}
+		at := newMechanicalGridTrader(nil, exchange)
+
+		// The 95 buy has an empty level above it to re-arm as a sell
+		at.gridState.Levels[2] = kernel.GridLevelInfo{Index: 2, Price: 100, State: "empty", Side: "buy", AllocatedUSD: 200}
+		d := at.rearmGridFill(at.gridState.Levels[1])
+		if d == nil || d.Action != "place_sell_limit" || d.LevelIndex != 2 {
+			t.Fatalf("Expected a sell armed at level 2, got %+v", d)
+		}
+		if at.gridState.TotalTrades != 0 || at.gridState.WinningTrades != 0 {
+			t.Errorf("An opening fill is not a trade, got total=%d winning=%d", at.gridState.TotalTrades, at.gridState.WinningTrades)
+		}
+	})
+}
+
+func TestSaveGridDecisionRecordAdvancesCycleOnce(t *testing.T) {
+	at := newMechanicalGridTrader(nil, &mechanicalGridFakeTrader{})
+	at.saveMechanicalGridRecord(nil, nil)
+	if at.cycleNumber != 1 {
+		t.Errorf("Without a store: expected cycle 1, got %d", at.cycleNumber)
+	}
+
+	st, err := store.New(filepath.Join(t.TempDir(), "grid.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	at = newMechanicalGridTrader(st, &mechanicalGridFakeTrader{})
+	at.saveMechanicalGridRecord(nil, nil)
+	if at.cycleNumber != 1 {
+		t.Errorf("With a store: expected cycle 1, got %d", at.cycleNumber)
+	}
+}
+
+func TestGridRegimeLimits(t *testing.T) {
+	at := newMechanicalGridTrader(nil, &mechanicalGridFakeTrader{})
+	if limits := at.gridRegimeLimits(); limits.NarrowRegimeLeverage != 0 {
+		t.Errorf("Without a store the built-in defaults apply, got %+v", limits)
+	}
+
+	st, err := store.New(filepath.Join(t.TempDir(), "grid.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	if err := st.Grid().SaveGridConfig(&store.GridConfigModel{ID: "cfg", TraderID: "grid-trader", IsActive: true, NarrowRegimeLeverage: 5}); err != nil {
+		t.Fatalf("SaveGridConfig: %v", err)
+	}
+	at = newMechanicalGridTrader(st, &mechanicalGridFakeTrader{})
+	if limits := at.gridRegimeLimits(); limits.NarrowRegimeLeverage != 5 {
+		t.Errorf("Expected the saved narrow regime leverage 5, got %d", limits.NarrowRegimeLeverage)
+	}
+}
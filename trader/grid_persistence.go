@@ -0,0 +1,502 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// Grid State Persistence
+// Grid state is checkpointed into GridStore every cycle so a restarted trader
+// can rebuild its levels from the database and re-attach to the limit orders
+// still resting on the exchange instead of orphaning them
+// ============================================================================
+
+// Grid instance states stored in grid_instances.state
+const (
+	GridInstanceRunning = "running"
+	GridInstancePaused  = "paused"
+	GridInstanceStopped = "stopped"
+)
+
+// Grid event types stored in grid_events.event_type
+const (
+	GridEventInitialized   = "initialized"
+	GridEventRecovered     = "recovered"
+	GridEventOrderPlaced   = "order_placed"
+	GridEventOrderFilled   = "order_filled"
+	GridEventOrderCanceled = "order_cancelled"
+	GridEventOrderAdopted  = "order_adopted"
+	GridEventOrphanCancel  = "orphan_cancelled"
+	GridEventPaused        = "paused"
+	GridEventResumed       = "resumed"
+	GridEventEmergencyExit = "emergency_exit"
+	GridEventRetired       = "retired"
+	GridEventTrailed       = "trailed"
+)
+
+// gridClientOrderIDPrefix marks the client order IDs of grid limit orders, so recovery can tell
+// the grid's own leftovers from manual orders and other strategies' orders on the same symbol
+const gridClientOrderIDPrefix = "nxg"
+
+// gridClientOrderID returns the per-cycle client order ID of a grid limit order
+// It keeps BuildClientOrderID's length, swapping its prefix for the grid one
+func (at *AutoTrader) gridClientOrderID(symbol, action string) string {
+	id := at.clientOrderIDForCycle(symbol, action)
+	return gridClientOrderIDPrefix + id[len(gridClientOrderIDPrefix):]
+}
+
+// isGridClientOrderID reports whether a client order ID was issued for a grid limit order
+func isGridClientOrderID(clientOrderID string) bool {
+	return strings.HasPrefix(clientOrderID, gridClientOrderIDPrefix)
+}
+
+// gridLayout the config fields a grid's levels and order sizes are derived from
+// Risk limits, execution and trailing settings can change without invalidating a running grid
+type gridLayout struct {
+	Symbol          string  `json:"symbol"`
+	UpperPrice      float64 `json:"upper_price"`
+	LowerPrice      float64 `json:"lower_price"`
+	UseATRBounds    bool    `json:"use_atr_bounds"`
+	ATRMultiplier   float64 `json:"atr_multiplier"`
+	GridCount       int     `json:"grid_count"`
+	Distribution    string  `json:"distribution"`
+	TotalInvestment float64 `json:"total_investment"`
+	Leverage        int     `json:"leverage"`
+}
+
+// gridConfigSnapshot serializes the layout a grid instance is built from
+func gridConfigSnapshot(config *store.GridStrategyConfig) string {
+	data, err := json.Marshal(gridLayout{
+		Symbol:          config.Symbol,
+		UpperPrice:      config.UpperPrice,
+		LowerPrice:      config.LowerPrice,
+		UseATRBounds:    config.UseATRBounds,
+		ATRMultiplier:   config.ATRMultiplier,
+		GridCount:       config.GridCount,
+		Distribution:    config.Distribution,
+		TotalInvestment: config.TotalInvestment,
+		Leverage:        config.Leverage,
+	})
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// gridLayoutMatches reports whether a persisted snapshot describes the same layout as config
+// Snapshots written before only the layout was kept hold the whole config; its layout fields share the JSON keys
+func gridLayoutMatches(snapshot string, config *store.GridStrategyConfig) bool {
+	var layout gridLayout
+	if err := json.Unmarshal([]byte(snapshot), &layout); err != nil {
+		return false
+	}
+	data, err := json.Marshal(layout)
+	return err == nil && string(data) == gridConfigSnapshot(config)
+}
+
+// gridOrderMatch result of matching persisted levels against exchange open orders
+type gridOrderMatch struct {
+	Missing []int             // Pending levels whose order is no longer open (filled or canceled while offline)
+	Adopted map[int]OpenOrder // Empty levels that an unknown open order sits on
+	Orphans []OpenOrder       // Open grid limit orders inside the grid range that belong to no level
+}
+
+// matchGridOrders reconciles restored levels with the exchange's open orders
+// An unknown order is adopted by an empty level on the same side within 10% of the grid spacing,
+// any other grid order (by client ID prefix) inside the grid range (±1 spacing) is an orphan from an earlier grid.
+// Orders without the grid prefix - manual orders, other strategies, or exchanges that don't report
+// client IDs - are never orphaned
+func matchGridOrders(levels []kernel.GridLevelInfo, orders []OpenOrder, spacing float64) gridOrderMatch {
+	result := gridOrderMatch{Adopted: make(map[int]OpenOrder)}
+
+	open := make(map[string]bool, len(orders))
+	for _, o := range orders {
+		open[o.OrderID] = true
+	}
+	known := make(map[string]bool)
+	for _, level := range levels {
+		if level.OrderID == "" {
+			continue
+		}
+		known[level.OrderID] = true
+		if level.State == "pending" && !open[level.OrderID] {
+			result.Missing = append(result.Missing, level.Index)
+		}
+	}
+	if len(levels) == 0 {
+		return result
+	}
+
+	lower := levels[0].Price - spacing
+	upper := levels[len(levels)-1].Price + spacing
+	tolerance := spacing * 0.1
+
+	for _, o := range orders {
+		if known[o.OrderID] || !strings.EqualFold(o.Type, "LIMIT") {
+			continue
+		}
+		if o.Price < lower || o.Price > upper {
+			continue // Not ours - leave manual orders outside the grid alone
+		}
+
+		adopted := false
+		for _, level := range levels {
+			if level.State != "empty" || !strings.EqualFold(level.Side, o.Side) {
+				continue
+			}
+			if _, taken := result.Adopted[level.Index]; taken {
+				continue
+			}
+			if math.Abs(level.Price-o.Price) <= tolerance {
+				result.Adopted[level.Index] = o
+				adopted = true
+				break
+			}
+		}
+		if !adopted && isGridClientOrderID(o.ClientOrderID) {
+			result.Orphans = append(result.Orphans, o)
+		}
+	}
+	return result
+}
+
+// gridStore returns the grid store, or nil when running without persistence
+func (at *AutoTrader) gridStore() *store.GridStore {
+	if at.store == nil {
+		return nil
+	}
+	return at.store.Grid()
+}
+
+// gridLevelID builds the stable row ID for a level of an instance
+func gridLevelID(instanceID string, index int) string {
+	return fmt.Sprintf("%s:%d", instanceID, index)
+}
+
+// gridInstanceConfigID returns the config ID the current grid's instances are stored under:
+// the trader ID for a single grid, trader ID and symbol for each grid of a basket
+func (at *AutoTrader) gridInstanceConfigID() string {
+	if gridConfig := at.config.StrategyConfig.GridConfig; gridConfig != nil && len(gridConfig.Basket) > 0 {
+		return at.id + ":" + at.gridState.Config.Symbol
+	}
+	return at.id
+}
+
+// startGridInstance assigns a fresh instance ID to the current grid state
+func (at *AutoTrader) startGridInstance() {
+	at.gridState.InstanceID = uuid.New().String()
+	at.gridState.StartedAt = time.Now().UTC()
+}
+
+// restoreGridState rebuilds grid state from the last persisted instance
+// Returns false (and the caller builds a fresh grid) if there is nothing usable to restore
+func (at *AutoTrader) restoreGridState(config *store.GridStrategyConfig) bool {
+	gs := at.gridStore()
+	if gs == nil {
+		return false
+	}
+
+	instance, err := gs.LoadGridInstance(at.gridInstanceConfigID())
+	if err != nil || instance.State == GridInstanceStopped {
+		return false
+	}
+	levels, err := gs.LoadGridLevels(instance.ID)
+	if err != nil {
+		logger.Warnf("[Grid] Failed to load persisted levels: %v", err)
+		return false
+	}
+	if instance.Symbol != config.Symbol || len(levels) != config.GridCount || !gridLayoutMatches(instance.ConfigSnapshot, config) {
+		logger.Infof("[Grid] Persisted grid %s (%s, %d levels) was laid out under a different config, starting fresh",
+			instance.ID, instance.Symbol, len(levels))
+		at.retireGridInstance(instance, levels)
+		return false
+	}
+
+	state := at.gridState
+	state.mu.Lock()
+	state.InstanceID = instance.ID
+	state.StartedAt = instance.StartedAt
+	state.UpperPrice = instance.CurrentUpperPrice
+	state.LowerPrice = instance.CurrentLowerPrice
+	state.GridSpacing = instance.CurrentGridSpacing
+	state.IsPaused = instance.State == GridInstancePaused
+	state.CurrentRegimeLevel = instance.CurrentRegimeLevel
+	state.ShortBoxUpper, state.ShortBoxLower = instance.ShortBoxUpper, instance.ShortBoxLower
+	state.MidBoxUpper, state.MidBoxLower = instance.MidBoxUpper, instance.MidBoxLower
+	state.LongBoxUpper, state.LongBoxLower = instance.LongBoxUpper, instance.LongBoxLower
+	state.BreakoutLevel = instance.BreakoutLevel
+	state.BreakoutDirection = instance.BreakoutDirection
+	state.BreakoutConfirmCount = instance.BreakoutConfirmCount
+	state.PositionReductionPct = instance.PositionReductionPct
+	if instance.CurrentDirection != "" {
+		state.CurrentDirection = market.GridDirection(instance.CurrentDirection)
+	}
+	state.DirectionChangedAt = instance.DirectionChangedAt
+	state.DirectionChangeCount = instance.DirectionChangeCount
+	state.TotalProfit = instance.TotalProfit
+	state.TotalTrades = instance.TotalTrades
+	state.WinningTrades = instance.WinningTrades
+	state.MaxDrawdown = instance.MaxDrawdown
+	state.PeakEquity = instance.PeakEquity
+	state.DailyPnL = instance.DailyProfit - instance.DailyLoss
+	state.LastDailyReset = instance.LastDailyReset
+	state.InventoryQty = instance.InventoryQty
+	state.InventoryEntry = instance.InventoryEntry
+	state.TrailCount = instance.TrailCount
+
+	state.Levels = make([]kernel.GridLevelInfo, len(levels))
+	state.OrderBook = make(map[string]int)
+	for i, l := range levels {
+		state.Levels[i] = kernel.GridLevelInfo{
+			Index:         l.LevelIndex,
+			Price:         l.Price,
+			State:         l.State,
+			Side:          l.Side,
+			OrderID:       l.OrderID,
+			OrderQuantity: l.OrderQuantity,
+			PositionSize:  l.PositionSize,
+			PositionEntry: l.PositionEntry,
+			AllocatedUSD:  l.AllocatedUSD,
+		}
+		if l.State == "pending" && l.OrderID != "" {
+			state.OrderBook[l.OrderID] = i
+		}
+	}
+	state.IsInitialized = true
+	state.mu.Unlock()
+
+	at.reattachGridOrders(config.Symbol)
+
+	logger.Infof("📊 [Grid] Recovered instance %s: %d levels, $%.2f - $%.2f, %d pending orders",
+		instance.ID, len(levels), instance.CurrentLowerPrice, instance.CurrentUpperPrice, len(state.OrderBook))
+	at.recordGridEvent(GridEventRecovered, -1, 0, 0, "", fmt.Sprintf("recovered %d levels", len(levels)))
+	at.checkpointGridState()
+	return true
+}
+
+// reattachGridOrders matches restored levels against the exchange's open orders:
+// unknown orders sitting on an empty level are adopted, leftovers inside the grid are canceled.
+// Pending levels whose order disappeared are left for syncGridState to classify as filled or canceled
+func (at *AutoTrader) reattachGridOrders(symbol string) {
+	openOrders, err := at.trader.GetOpenOrders(symbol)
+	if err != nil {
+		logger.Warnf("[Grid] Failed to get open orders for recovery, will resync next cycle: %v", err)
+		return
+	}
+
+	at.gridState.mu.Lock()
+	match := matchGridOrders(at.gridState.Levels, openOrders, at.gridState.GridSpacing)
+	for index, order := range match.Adopted {
+		level := &at.gridState.Levels[index]
+		level.State = "pending"
+		level.OrderID = order.OrderID
+		level.OrderQuantity = order.Quantity
+		at.gridState.OrderBook[order.OrderID] = index
+	}
+	at.gridState.mu.Unlock()
+
+	for index, order := range match.Adopted {
+		logger.Infof("[Grid] Adopted open order %s at $%.4f into level %d", order.OrderID, order.Price, index)
+		at.recordGridEvent(GridEventOrderAdopted, index, order.Price, order.Quantity, order.Side, order.OrderID)
+	}
+	if len(match.Missing) > 0 {
+		logger.Infof("[Grid] %d pending orders closed while offline, resolving on next sync", len(match.Missing))
+	}
+
+	if len(match.Orphans) == 0 {
+		return
+	}
+	gridTrader, ok := at.trader.(GridTrader)
+	if !ok {
+		gridTrader = NewGridTraderAdapter(at.trader)
+	}
+	for _, order := range match.Orphans {
+		if err := gridTrader.CancelOrder(symbol, order.OrderID); err != nil {
+			logger.Warnf("[Grid] Failed to cancel orphaned order %s: %v", order.OrderID, err)
+			continue
+		}
+		logger.Infof("[Grid] Cancelled orphaned order %s at $%.4f", order.OrderID, order.Price)
+		at.recordGridEvent(GridEventOrphanCancel, -1, order.Price, order.Quantity, order.Side, order.OrderID)
+	}
+}
+
+// retireGridInstance cancels a previous instance's resting orders and marks it stopped
+func (at *AutoTrader) retireGridInstance(instance *store.GridInstanceModel, levels []store.GridLevelModel) {
+	gridTrader, ok := at.trader.(GridTrader)
+	if !ok {
+		gridTrader = NewGridTraderAdapter(at.trader)
+	}
+	for _, level := range levels {
+		if level.State != "pending" || level.OrderID == "" {
+			continue
+		}
+		if err := gridTrader.CancelOrder(instance.Symbol, level.OrderID); err != nil {
+			logger.Warnf("[Grid] Failed to cancel order %s of retired grid: %v", level.OrderID, err)
+		}
+	}
+
+	now := time.Now().UTC()
+	instance.State = GridInstanceStopped
+	instance.StoppedAt = &now
+	if err := at.gridStore().SaveGridInstance(instance); err != nil {
+		logger.Warnf("[Grid] Failed to mark grid instance %s stopped: %v", instance.ID, err)
+	}
+	if err := at.gridStore().SaveGridEvent(&store.GridEventModel{
+		ID:         uuid.New().String(),
+		InstanceID: instance.ID,
+		EventType:  GridEventRetired,
+		Message:    "grid configuration changed",
+	}); err != nil {
+		logger.Warnf("[Grid] Failed to record grid event: %v", err)
+	}
+}
+
+// checkpointGridBasket checkpoints the grid of every basket symbol
+func (at *AutoTrader) checkpointGridBasket() {
+	at.forEachGridSymbol(func() error {
+		at.checkpointGridState()
+		return nil
+	})
+}
+
+// checkpointGridState saves the instance row and every level row for the current grid
+func (at *AutoTrader) checkpointGridState() {
+	gs := at.gridStore()
+	if gs == nil || at.gridState == nil {
+		return
+	}
+
+	state := at.gridState
+	state.mu.RLock()
+	if state.InstanceID == "" || !state.IsInitialized {
+		state.mu.RUnlock()
+		return
+	}
+
+	status := GridInstanceRunning
+	if state.IsPaused {
+		status = GridInstancePaused
+	}
+	activeLevels := 0
+	for _, level := range state.Levels {
+		if level.State != "empty" {
+			activeLevels++
+		}
+	}
+	dailyProfit, dailyLoss := 0.0, 0.0
+	if state.DailyPnL >= 0 {
+		dailyProfit = state.DailyPnL
+	} else {
+		dailyLoss = -state.DailyPnL
+	}
+
+	instance := &store.GridInstanceModel{
+		ID:                   state.InstanceID,
+		ConfigID:             at.gridInstanceConfigID(),
+		Symbol:               state.Config.Symbol,
+		ConfigSnapshot:       gridConfigSnapshot(state.Config),
+		State:                status,
+		StartedAt:            state.StartedAt,
+		CurrentUpperPrice:    state.UpperPrice,
+		CurrentLowerPrice:    state.LowerPrice,
+		CurrentGridSpacing:   state.GridSpacing,
+		ActiveLevelCount:     activeLevels,
+		CurrentRegimeLevel:   state.CurrentRegimeLevel,
+		ShortBoxUpper:        state.ShortBoxUpper,
+		ShortBoxLower:        state.ShortBoxLower,
+		MidBoxUpper:          state.MidBoxUpper,
+		MidBoxLower:          state.MidBoxLower,
+		LongBoxUpper:         state.LongBoxUpper,
+		LongBoxLower:         state.LongBoxLower,
+		BreakoutLevel:        state.BreakoutLevel,
+		BreakoutDirection:    state.BreakoutDirection,
+		BreakoutConfirmCount: state.BreakoutConfirmCount,
+		PositionReductionPct: state.PositionReductionPct,
+		CurrentDirection:     string(state.CurrentDirection),
+		DirectionChangedAt:   state.DirectionChangedAt,
+		DirectionChangeCount: state.DirectionChangeCount,
+		TotalProfit:          state.TotalProfit,
+		TotalTrades:          state.TotalTrades,
+		WinningTrades:        state.WinningTrades,
+		MaxDrawdown:          state.MaxDrawdown,
+		PeakEquity:           state.PeakEquity,
+		DailyProfit:          dailyProfit,
+		DailyLoss:            dailyLoss,
+		LastDailyReset:       state.LastDailyReset,
+		InventoryQty:         state.InventoryQty,
+		InventoryEntry:       state.InventoryEntry,
+		TrailCount:           state.TrailCount,
+	}
+
+	levels := make([]store.GridLevelModel, len(state.Levels))
+	for i, level := range state.Levels {
+		weight := 0.0
+		if state.Config.TotalInvestment > 0 {
+			weight = level.AllocatedUSD / state.Config.TotalInvestment
+		}
+		levels[i] = store.GridLevelModel{
+			ID:               gridLevelID(state.InstanceID, level.Index),
+			InstanceID:       state.InstanceID,
+			LevelIndex:       level.Index,
+			Price:            level.Price,
+			State:            level.State,
+			Side:             level.Side,
+			OrderID:          level.OrderID,
+			OrderPrice:       level.Price,
+			OrderQuantity:    level.OrderQuantity,
+			PositionSize:     level.PositionSize,
+			PositionEntry:    level.PositionEntry,
+			AllocationWeight: weight,
+			AllocatedUSD:     level.AllocatedUSD,
+		}
+	}
+	state.mu.RUnlock()
+
+	if err := gs.SaveGridInstance(instance); err != nil {
+		logger.Warnf("[Grid] Failed to checkpoint grid instance: %v", err)
+		return
+	}
+	if err := gs.SaveGridLevels(levels); err != nil {
+		logger.Warnf("[Grid] Failed to checkpoint grid levels: %v", err)
+	}
+}
+
+// recordGridEvent appends an event to the current instance's history (levelIndex -1 = not level-specific)
+func (at *AutoTrader) recordGridEvent(eventType string, levelIndex int, price, quantity float64, side, message string) {
+	gs := at.gridStore()
+	if gs == nil || at.gridState == nil {
+		return
+	}
+	at.gridState.mu.RLock()
+	instanceID := at.gridState.InstanceID
+	at.gridState.mu.RUnlock()
+	if instanceID == "" {
+		return
+	}
+
+	event := &store.GridEventModel{
+		ID:         uuid.New().String(),
+		InstanceID: instanceID,
+		EventType:  eventType,
+		Price:      price,
+		Quantity:   quantity,
+		Side:       side,
+		Message:    message,
+	}
+	if levelIndex >= 0 {
+		event.LevelID = gridLevelID(instanceID, levelIndex)
+	}
+	if err := gs.SaveGridEvent(event); err != nil {
+		logger.Warnf("[Grid] Failed to record grid event %s: %v", eventType, err)
+	}
+}
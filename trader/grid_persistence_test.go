@@ -0,0 +1,200 @@
+package trader
+
+import (
+	"encoding/json"
+	"nofx/kernel"
+	"nofx/store"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchGridOrders(t *testing.T) {
+	levels := []kernel.GridLevelInfo{
+		{Index: 0, Price: 90, State: "pending", Side: "buy", OrderID: "a"},
+		{Index: 1, Price: 95, State: "pending", Side: "buy", OrderID: "b"},
+		{Index: 2, Price: 100, State: "empty", Side: "buy"},
+		{Index: 3, Price: 105, State: "empty", Side: "sell"},
+		{Index: 4, Price: 110, State: "filled", Side: "sell"},
+	}
+	orders := []OpenOrder{
+		{OrderID: "a", Side: "BUY", Type: "LIMIT", Price: 90},                                         // Known, still open
+		{OrderID: "x", Side: "BUY", Type: "LIMIT", Price: 100.2},                                      // Sits on empty level 2
+		{OrderID: "y", Side: "BUY", Type: "LIMIT", Price: 105, ClientOrderID: "nxg0000000000000000y"}, // Wrong side for level 3
+		{OrderID: "w", Side: "BUY", Type: "LIMIT", Price: 104},                                        // Manual, not the grid's to cancel
+		{OrderID: "z", Side: "SELL", Type: "LIMIT", Price: 150},                                       // Outside the grid
+		{OrderID: "s", Side: "SELL", Type: "STOP_MARKET", StopPrice: 80},                              // Not a grid order
+	}
+
+	match := matchGridOrders(levels, orders, 5)
+
+	if len(match.Missing) != 1 || match.Missing[0] != 1 {
+		t.Errorf("expected level 1 missing, got %v", match.Missing)
+	}
+	if len(match.Adopted) != 1 || match.Adopted[2].OrderID != "x" {
+		t.Errorf("expected order x adopted by level 2, got %+v", match.Adopted)
+	}
+	if len(match.Orphans) != 1 || match.Orphans[0].OrderID != "y" {
+		t.Errorf("expected only order y orphaned, got %+v", match.Orphans)
+	}
+}
+
+// gridRecoveryFakeTrader serves open orders and records cancels for grid recovery
+type gridRecoveryFakeTrader struct {
+	Trader // Methods recovery doesn't use are left unimplemented
+
+	orders   []OpenOrder
+	canceled []string
+}
+
+func (f *gridRecoveryFakeTrader) GetOpenOrders(symbol string) ([]OpenOrder, error) {
+	return f.orders, nil
+}
+
+func (f *gridRecoveryFakeTrader) CancelOrder(symbol, orderID string) error {
+	f.canceled = append(f.canceled, orderID)
+	return nil
+}
+
+// newGridRecoveryTrader builds a trader on st whose grid config is config
+func newGridRecoveryTrader(st *store.Store, exchange Trader, config *store.GridStrategyConfig) *AutoTrader {
+	at := &AutoTrader{
+		id:     "grid-trader",
+		store:  st,
+		trader: exchange,
+		config: AutoTraderConfig{StrategyConfig: &store.StrategyConfig{GridConfig: config}},
+	}
+	at.gridState = NewGridState(config)
+	return at
+}
+
+// checkpointTestGrid persists a five-level grid with two resting buy orders
+func checkpointTestGrid(t *testing.T, st *store.Store, config *store.GridStrategyConfig) string {
+	at := newGridRecoveryTrader(st, &gridRecoveryFakeTrader{}, config)
+	at.gridState.LowerPrice, at.gridState.UpperPrice, at.gridState.GridSpacing = 90, 110, 5
+	at.gridState.Levels = []kernel.GridLevelInfo{
+		{Index: 0, Price: 90, State: "pending", Side: "buy", OrderID: "a", OrderQuantity: 1},
+		{Index: 1, Price: 95, State: "pending", Side: "buy", OrderID: "b", OrderQuantity: 1},
+		{Index: 2, Price: 100, State: "empty", Side: "buy"},
+		{Index: 3, Price: 105, State: "empty", Side: "sell"},
+		{Index: 4, Price: 110, State: "empty", Side: "sell"},
+	}
+	at.gridState.TotalTrades = 7
+	at.startGridInstance()
+	at.gridState.IsInitialized = true
+	at.checkpointGridState()
+	return at.gridState.InstanceID
+}
+
+func TestRestoreGridState(t *testing.T) {
+	config := &store.GridStrategyConfig{Symbol: "BTCUSDT", GridCount: 5, TotalInvestment: 1000, Leverage: 2}
+
+	t.Run("resumes and only cancels grid orphans", func(t *testing.T) {
+		st, err := store.New(filepath.Join(t.TempDir(), "grid.db"))
+		if err != nil {
+			t.Fatalf("store.New: %v", err)
+		}
+		defer st.Close()
+		instanceID := checkpointTestGrid(t, st, config)
+
+		exchange := &gridRecoveryFakeTrader{orders: []OpenOrder{
+			{OrderID: "a", Side: "BUY", Type: "LIMIT", Price: 90, ClientOrderID: "nxg0000000000000000a"},
+			{OrderID: "x", Side: "BUY", Type: "LIMIT", Price: 100, Quantity: 1, ClientOrderID: "nxg0000000000000000x"}, // Placed before the crash, sits on level 2
+			{OrderID: "g", Side: "BUY", Type: "LIMIT", Price: 104, ClientOrderID: "nxg0000000000000000g"},              // Earlier grid's leftover
+			{OrderID: "m", Side: "BUY", Type: "LIMIT", Price: 103},                                                     // Manual order
+			{OrderID: "d", Side: "BUY", Type: "LIMIT", Price: 97, ClientOrderID: "nx00000000000000000d"},               // Another strategy's order
+		}}
+		at := newGridRecoveryTrader(st, exchange, config)
+		if !at.restoreGridState(config) {
+			t.Fatalf("Expected the persisted grid to be restored")
+		}
+
+		state := at.gridState
+		if state.InstanceID != instanceID || len(state.Levels) != 5 || state.TotalTrades != 7 || state.GridSpacing != 5 {
+			t.Errorf("Restored state doesn't match the checkpoint: %+v", state)
+		}
+		if state.Levels[2].State != "pending" || state.Levels[2].OrderID != "x" || state.OrderBook["x"] != 2 {
+			t.Errorf("Expected order x adopted by level 2, got %+v", state.Levels[2])
+		}
+		if state.Levels[1].OrderID != "b" || state.OrderBook["b"] != 1 {
+			t.Errorf("Level 1 should keep its missing order for the next sync, got %+v", state.Levels[1])
+		}
+		if len(exchange.canceled) != 1 || exchange.canceled[0] != "g" {
+			t.Errorf("Expected only the grid orphan g canceled, got %v", exchange.canceled)
+		}
+	})
+
+	t.Run("resumes when only non-layout settings changed", func(t *testing.T) {
+		st, err := store.New(filepath.Join(t.TempDir(), "grid.db"))
+		if err != nil {
+			t.Fatalf("store.New: %v", err)
+		}
+		defer st.Close()
+		instanceID := checkpointTestGrid(t, st, config)
+
+		changed := *config
+		changed.MaxDrawdownPct = 15 // Risk and execution settings don't move levels or order sizes
+		changed.UseMakerOnly = true
+		changed.ExecutionMode = "mechanical"
+		changed.TrailingMode = "trailing"
+		exchange := &gridRecoveryFakeTrader{}
+		at := newGridRecoveryTrader(st, exchange, &changed)
+		if !at.restoreGridState(&changed) {
+			t.Fatalf("Expected the grid to resume after a non-layout config change")
+		}
+		if at.gridState.InstanceID != instanceID || len(exchange.canceled) != 0 {
+			t.Errorf("Expected instance %s resumed with no orders canceled, got %s canceling %v",
+				instanceID, at.gridState.InstanceID, exchange.canceled)
+		}
+	})
+
+	t.Run("resumes an instance snapshotted with the whole config", func(t *testing.T) {
+		st, err := store.New(filepath.Join(t.TempDir(), "grid.db"))
+		if err != nil {
+			t.Fatalf("store.New: %v", err)
+		}
+		defer st.Close()
+		instanceID := checkpointTestGrid(t, st, config)
+		instance, err := st.Grid().LoadGridInstanceByID(instanceID)
+		if err != nil {
+			t.Fatalf("LoadGridInstanceByID: %v", err)
+		}
+		legacy, _ := json.Marshal(config)
+		instance.ConfigSnapshot = string(legacy)
+		if err := st.Grid().SaveGridInstance(instance); err != nil {
+			t.Fatalf("SaveGridInstance: %v", err)
+		}
+
+		at := newGridRecoveryTrader(st, &gridRecoveryFakeTrader{}, config)
+		if !at.restoreGridState(config) {
+			t.Fatalf("Expected a grid snapshotted before layout-only snapshots to resume")
+		}
+	})
+
+	t.Run("retires the instance when the config changed", func(t *testing.T) {
+		st, err := store.New(filepath.Join(t.TempDir(), "grid.db"))
+		if err != nil {
+			t.Fatalf("store.New: %v", err)
+		}
+		defer st.Close()
+		instanceID := checkpointTestGrid(t, st, config)
+
+		changed := *config
+		changed.Leverage = 5 // Same symbol and level count, different sizing
+		exchange := &gridRecoveryFakeTrader{}
+		at := newGridRecoveryTrader(st, exchange, &changed)
+		if at.restoreGridState(&changed) {
+			t.Fatalf("A grid laid out under another config must not be restored")
+		}
+		if strings.Join(exchange.canceled, ",") != "a,b" {
+			t.Errorf("Expected the retired grid's resting orders canceled, got %v", exchange.canceled)
+		}
+		instance, err := st.Grid().LoadGridInstanceByID(instanceID)
+		if err != nil {
+			t.Fatalf("LoadGridInstanceByID: %v", err)
+		}
+		if instance.State != GridInstanceStopped {
+			t.Errorf("Expected the old instance stopped, got %s", instance.State)
+		}
+	})
+}
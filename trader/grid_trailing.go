@@ -0,0 +1,161 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/trader/strategy"
+)
+
+// trailGrid moves a trailing grid with the trend instead of pausing on a range breakout.
+// Returns false when the grid doesn't trail this breakout and the normal breakout rules apply
+func (at *AutoTrader) trailGrid(breakoutType BreakoutType) bool {
+	gridConfig := at.gridConfig()
+	switch {
+	case gridConfig.TrailingMode == strategy.GridTrailingBoth:
+	case gridConfig.TrailingMode == strategy.GridTrailingInfinity && breakoutType == BreakoutUpper:
+	case gridConfig.TrailingMode == strategy.GridTrailingInfinity && breakoutType == BreakoutLower:
+		at.holdInfinityGridBelowRange()
+		return true
+	default:
+		return false
+	}
+
+	price, err := at.trader.GetMarketPrice(gridConfig.Symbol)
+	if err != nil {
+		logger.Warnf("[Grid] Trailing skipped, failed to get market price: %v", err)
+		return true
+	}
+
+	// Fetched before locking: a cache miss queries the exchange
+	spec := at.contractSpec(gridConfig.Symbol)
+
+	at.gridState.mu.Lock()
+	lower, spacing := at.gridState.LowerPrice, at.gridState.GridSpacing
+	shift := strategy.GridTrailShift(gridConfig, lower, at.gridState.UpperPrice, spacing, price)
+	if shift == 0 {
+		// Not far enough outside the range yet; hold the grid rather than pause it
+		at.gridState.mu.Unlock()
+		return true
+	}
+
+	trailed, dropped := strategy.TrailGridLevels(at.gridState.Levels, shift, lower, spacing, price, spec)
+	at.gridState.Levels = trailed
+	at.gridState.LowerPrice = lower + float64(shift)*spacing
+	at.gridState.UpperPrice = at.gridState.LowerPrice + spacing*float64(len(trailed)-1)
+	at.gridState.OrderBook = make(map[string]int)
+	for i, level := range trailed {
+		if level.State == "pending" && level.OrderID != "" {
+			at.gridState.OrderBook[level.OrderID] = i
+		}
+	}
+	if gridConfig.EnableDirectionAdjust {
+		at.applyGridDirection(price)
+	}
+	at.gridState.TrailCount++
+	newLower, newUpper := at.gridState.LowerPrice, at.gridState.UpperPrice
+	at.gridState.mu.Unlock()
+
+	at.releaseTrailedLevels(dropped, price)
+
+	logger.Infof("📊 [Grid] Trailed %d levels %s: $%.2f - $%.2f (%d levels dropped)",
+		int(math.Abs(float64(shift))), breakoutType, newLower, newUpper, len(dropped))
+	at.recordGridEvent(GridEventTrailed, -1, price, 0, "",
+		fmt.Sprintf("shift %d levels, $%.2f - $%.2f", shift, newLower, newUpper))
+	return true
+}
+
+// holdInfinityGridBelowRange handles a downside breakout of an infinity grid, which only trails up.
+// The base inventory and filled levels are held and the resting sells stay up to catch a rebound;
+// the breakout pause is skipped so the inventory isn't treated as a failed range.
+// Max drawdown still exits the whole position, and a long box breakout closes only the levels
+func (at *AutoTrader) holdInfinityGridBelowRange() {
+	at.gridState.mu.RLock()
+	lower, inventory := at.gridState.LowerPrice, at.gridState.InventoryQty
+	at.gridState.mu.RUnlock()
+	logger.Infof("[Grid] Infinity grid below its range ($%.2f), holding %.4f base inventory and filled levels",
+		lower, inventory)
+}
+
+// releaseTrailedLevels cancels the resting orders of levels the grid trailed away from and
+// closes their positions, except that an infinity grid keeps long inventory as its base position
+func (at *AutoTrader) releaseTrailedLevels(dropped []kernel.GridLevelInfo, price float64) {
+	gridConfig := at.gridConfig()
+	gridTrader, ok := at.trader.(GridTrader)
+	if !ok {
+		gridTrader = NewGridTraderAdapter(at.trader)
+	}
+
+	for _, level := range dropped {
+		switch {
+		case level.State == "pending" && level.OrderID != "":
+			if err := gridTrader.CancelOrder(gridConfig.Symbol, level.OrderID); err != nil {
+				logger.Warnf("[Grid] Failed to cancel trailed order %s: %v", level.OrderID, err)
+			}
+
+		case level.State == "filled" && level.PositionSize > 0:
+			if level.Side == "buy" && gridConfig.TrailingMode == strategy.GridTrailingInfinity {
+				at.gridState.mu.Lock()
+				cost := at.gridState.InventoryQty*at.gridState.InventoryEntry + level.PositionSize*level.PositionEntry
+				at.gridState.InventoryQty += level.PositionSize
+				at.gridState.InventoryEntry = cost / at.gridState.InventoryQty
+				at.gridState.mu.Unlock()
+				continue
+			}
+
+			var realized float64
+			if level.Side == "buy" {
+				_, err := at.submitMarketOrder("close_long", gridConfig.Symbol, level.PositionSize, 0, at.clientOrderIDForCycle(gridConfig.Symbol, fmt.Sprintf("grid:trail:%d", level.Index)))
+				if err != nil {
+					logger.Warnf("[Grid] Failed to close trailed long at level %d: %v", level.Index, err)
+					continue
+				}
+				realized = (price - level.PositionEntry) * level.PositionSize
+			} else {
+				_, err := at.submitMarketOrder("close_short", gridConfig.Symbol, level.PositionSize, 0, at.clientOrderIDForCycle(gridConfig.Symbol, fmt.Sprintf("grid:trail:%d", level.Index)))
+				if err != nil {
+					logger.Warnf("[Grid] Failed to close trailed short at level %d: %v", level.Index, err)
+					continue
+				}
+				realized = (level.PositionEntry - price) * level.PositionSize
+			}
+			at.updateDailyPnL(realized)
+		}
+	}
+}
+
+// updateInventoryPnL marks filled levels and the base inventory to price, kept apart from realised grid profit
+func (at *AutoTrader) updateInventoryPnL() {
+	price, err := at.trader.GetMarketPrice(at.gridConfig().Symbol)
+	if err != nil || price <= 0 {
+		return
+	}
+
+	at.gridState.mu.Lock()
+	defer at.gridState.mu.Unlock()
+
+	pnl := (price - at.gridState.InventoryEntry) * at.gridState.InventoryQty
+	for i := range at.gridState.Levels {
+		level := &at.gridState.Levels[i]
+		if level.State != "filled" {
+			level.UnrealizedPnL = 0
+			continue
+		}
+		level.UnrealizedPnL = (price - level.PositionEntry) * level.PositionSize
+		if level.Side == "sell" {
+			level.UnrealizedPnL = -level.UnrealizedPnL
+		}
+		pnl += level.UnrealizedPnL
+	}
+	at.gridState.UnrealizedInventoryPnL = pnl
+}
+
+// clearGridInventory forgets the base inventory once the grid's positions have been closed
+func (at *AutoTrader) clearGridInventory() {
+	at.gridState.mu.Lock()
+	at.gridState.InventoryQty = 0
+	at.gridState.InventoryEntry = 0
+	at.gridState.UnrealizedInventoryPnL = 0
+	at.gridState.mu.Unlock()
+}
@@ -0,0 +1,89 @@
+package trader
+
+import (
+	"testing"
+
+	"nofx/kernel"
+	"nofx/store"
+	"nofx/trader/strategy"
+)
+
+// infinityGridFakeTrader holds one long position and records market closes
+type infinityGridFakeTrader struct {
+	Trader // Methods the breakout paths don't use are left unimplemented
+
+	price    float64
+	position float64
+	closed   []float64
+}
+
+func (f *infinityGridFakeTrader) GetMarketPrice(symbol string) (float64, error) {
+	return f.price, nil
+}
+
+func (f *infinityGridFakeTrader) GetPositions() ([]map[string]interface{}, error) {
+	return []map[string]interface{}{{"symbol": "BTCUSDT", "positionAmt": f.position}}, nil
+}
+
+func (f *infinityGridFakeTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	f.closed = append(f.closed, quantity)
+	return map[string]interface{}{"orderId": "close"}, nil
+}
+
+func newInfinityGridTrader(exchange Trader) *AutoTrader {
+	at := newGridRecoveryTrader(nil, exchange, &store.GridStrategyConfig{
+		Symbol: "BTCUSDT", GridCount: 3, TotalInvestment: 1000, Leverage: 1,
+		TrailingMode: strategy.GridTrailingInfinity,
+	})
+	at.gridState.LowerPrice, at.gridState.UpperPrice, at.gridState.GridSpacing = 100, 110, 5
+	at.gridState.Levels = []kernel.GridLevelInfo{
+		{Index: 0, Price: 100, State: "filled", Side: "buy", PositionSize: 1, PositionEntry: 100},
+		{Index: 1, Price: 105, State: "empty", Side: "buy"},
+		{Index: 2, Price: 110, State: "pending", Side: "sell", OrderID: "s", OrderQuantity: 1},
+	}
+	at.gridState.InventoryQty, at.gridState.InventoryEntry = 2, 90
+	return at
+}
+
+func TestInfinityGridDownsideBreakout(t *testing.T) {
+	exchange := &infinityGridFakeTrader{price: 80, position: 3}
+	at := newInfinityGridTrader(exchange)
+
+	// The downside is handled by the infinity grid itself: no pause, nothing closed or moved
+	if !at.trailGrid(BreakoutLower) {
+		t.Fatalf("An infinity grid must handle its downside breakout instead of the pause rules")
+	}
+	if at.gridState.IsPaused || at.gridState.LowerPrice != 100 || len(exchange.closed) != 0 {
+		t.Errorf("Expected the grid held as is, got paused=%v lower=%.0f closes=%v",
+			at.gridState.IsPaused, at.gridState.LowerPrice, exchange.closed)
+	}
+	if at.gridState.Levels[2].OrderID != "s" || at.gridState.InventoryQty != 2 {
+		t.Errorf("The resting sell and base inventory should be kept, got %+v inventory=%.0f",
+			at.gridState.Levels[2], at.gridState.InventoryQty)
+	}
+
+	// A long box breakout closes only what the levels hold
+	if err := at.closeAllPositions(); err != nil {
+		t.Fatalf("closeAllPositions: %v", err)
+	}
+	if len(exchange.closed) != 1 || exchange.closed[0] != 1 {
+		t.Errorf("Expected only the 1 level unit closed, got %v", exchange.closed)
+	}
+	if at.gridState.InventoryQty != 2 {
+		t.Errorf("Base inventory must survive a close-all, got %.0f", at.gridState.InventoryQty)
+	}
+}
+
+func TestTrailingGridClosesAll(t *testing.T) {
+	exchange := &infinityGridFakeTrader{price: 80, position: 3}
+	at := newInfinityGridTrader(exchange)
+	at.gridState.Config.TrailingMode = strategy.GridTrailingBoth
+
+	if err := at.closeAllPositions(); err != nil {
+		t.Fatalf("closeAllPositions: %v", err)
+	}
+	if len(exchange.closed) != 1 || exchange.closed[0] != 3 || at.gridState.InventoryQty != 0 {
+		t.Errorf("Expected the whole position closed and inventory cleared, got %v inventory=%.0f",
+			exchange.closed, at.gridState.InventoryQty)
+	}
+}
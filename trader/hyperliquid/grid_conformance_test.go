@@ -0,0 +1,135 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sonirico/go-hyperliquid"
+	"nofx/trader/testutil"
+)
+
+// newHyperliquidGridMockServer serves the info and exchange endpoints the GridTrader methods use,
+// recording every order action and cancel it receives
+func newHyperliquidGridMockServer(recorder *testutil.GridMockRecorder) *httptest.Server {
+	book := func(levels [][2]string) []map[string]interface{} {
+		var out []map[string]interface{}
+		for _, level := range levels {
+			out = append(out, map[string]interface{}{"px": level[0], "sz": level[1], "n": 1})
+		}
+		return out
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		reqType, _ := reqBody["type"].(string)
+		action, _ := reqBody["action"].(map[string]interface{})
+		if reqType == "" && action != nil {
+			reqType, _ = action["type"].(string)
+		}
+
+		var respBody interface{}
+		switch reqType {
+		case "meta":
+			respBody = map[string]interface{}{
+				"universe": []map[string]interface{}{
+					{"name": "BTC", "szDecimals": 4, "maxLeverage": 50},
+				},
+				"marginTables": []interface{}{},
+			}
+
+		case "spotMeta":
+			respBody = map[string]interface{}{"universe": []interface{}{}, "tokens": []interface{}{}}
+
+		case "l2Book":
+			respBody = map[string]interface{}{
+				"coin":   "BTC",
+				"levels": [][]map[string]interface{}{book(testutil.GridMockBids), book(testutil.GridMockAsks)},
+				"time":   1,
+			}
+
+		case "order":
+			orders, _ := action["orders"].([]interface{})
+			order, _ := orders[0].(map[string]interface{})
+			orderType, _ := order["t"].(map[string]interface{})
+			limit, _ := orderType["limit"].(map[string]interface{})
+			tif, _ := limit["tif"].(string)
+			side := "SELL"
+			if isBuy, _ := order["b"].(bool); isBuy {
+				side = "BUY"
+			}
+			price, _ := strconv.ParseFloat(order["p"].(string), 64)
+			reduceOnly, _ := order["r"].(bool)
+			cloid, _ := order["c"].(string)
+			recorder.RecordOrder(testutil.GridOrderCapture{
+				Side:       side,
+				Price:      price,
+				PostOnly:   strings.Trim(tif, `"`) == string(hyperliquid.TifAlo),
+				ReduceOnly: reduceOnly,
+				ClientID:   cloid,
+			})
+			oid, _ := strconv.ParseInt(testutil.GridMockOrderID, 10, 64)
+			respBody = map[string]interface{}{
+				"status": "ok",
+				"response": map[string]interface{}{
+					"type": "order",
+					"data": map[string]interface{}{
+						"statuses": []map[string]interface{}{{"resting": map[string]interface{}{"oid": oid}}},
+					},
+				},
+			}
+
+		case "cancel":
+			cancels, _ := action["cancels"].([]interface{})
+			cancel, _ := cancels[0].(map[string]interface{})
+			oid, _ := cancel["o"].(float64)
+			recorder.RecordCancel(strconv.FormatInt(int64(oid), 10))
+			respBody = map[string]interface{}{
+				"status": "ok",
+				"response": map[string]interface{}{
+					"type": "cancel",
+					"data": map[string]interface{}{"statuses": []string{"success"}},
+				},
+			}
+
+		default:
+			respBody = map[string]interface{}{"status": "ok"}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(respBody)
+	}))
+}
+
+// TestHyperliquidTrader_GridTraderConformance runs the GridTrader conformance suite against a mock
+// Hyperliquid server. Client IDs travel as cloid hashes, so the suite compares them encoded
+func TestHyperliquidTrader_GridTraderConformance(t *testing.T) {
+	privateKey, err := crypto.HexToECDSA("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+
+	recorder := &testutil.GridMockRecorder{}
+	mockServer := newHyperliquidGridMockServer(recorder)
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	walletAddr := "0x9999999999999999999999999999999999999999"
+	trader := &HyperliquidTrader{
+		exchange:      hyperliquid.NewExchange(ctx, privateKey, mockServer.URL, nil, "", walletAddr, nil),
+		ctx:           ctx,
+		walletAddr:    walletAddr,
+		meta:          &hyperliquid.Meta{Universe: []hyperliquid.AssetInfo{{Name: "BTC", SzDecimals: 4}}},
+		isCrossMargin: true,
+	}
+
+	suite := testutil.NewGridTraderTestSuite(t, trader, recorder, 0.05)
+	suite.EncodeClientID = hyperliquidCloid
+	suite.RunAllTests()
+}
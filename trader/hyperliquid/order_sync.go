@@ -119,7 +119,7 @@ func (t *HyperliquidTrader) SyncOrdersFromHyperliquid(traderID string, exchangeI
 				traderID, exchangeID, exchangeType,
 				symbol, positionSide, orderAction,
 				trade.Quantity, trade.Price, trade.Fee, trade.RealizedPnL,
-				tradeTimeMs, trade.TradeID,
+				tradeTimeMs, trade.TradeID, trade.OrderID,
 			); err != nil {
 				logger.Infof("  ⚠️ Failed to sync position for trade %s: %v", trade.TradeID, err)
 			} else {
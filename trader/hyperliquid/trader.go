@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"crypto/ecdsa"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"nofx/logger"
 	"strconv"
@@ -716,6 +718,16 @@ func (t *HyperliquidTrader) refreshMetaIfNeeded(coin string) error {
 
 // OpenLong opens a long position (supports both crypto and xyz dex)
 func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, "")
+}
+
+// OpenLongWithClientID is OpenLong with a caller-supplied client order ID (retry-safe)
+func (t *HyperliquidTrader) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, clientOrderID)
+}
+
+// openLong opens a long position using the given client order ID as cloid (empty for none; xyz dex orders carry none)
+func (t *HyperliquidTrader) openLong(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	// First cancel all pending orders for this coin
 	if err := t.CancelAllOrders(symbol); err != nil {
 		logger.Infof("  ⚠ Failed to cancel old pending orders: %v", err)
@@ -770,6 +782,11 @@ func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage i
 			ReduceOnly: false,
 		}
 
+		if clientOrderID != "" {
+			cloid := hyperliquidCloid(clientOrderID)
+			order.ClientOrderID = &cloid
+		}
+
 		_, err = t.exchange.Order(t.ctx, order, defaultBuilder)
 		if err != nil {
 			return nil, fmt.Errorf("failed to open long position: %w", err)
@@ -788,6 +805,16 @@ func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage i
 
 // OpenShort opens a short position (supports both crypto and xyz dex)
 func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, "")
+}
+
+// OpenShortWithClientID is OpenShort with a caller-supplied client order ID (retry-safe)
+func (t *HyperliquidTrader) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, clientOrderID)
+}
+
+// openShort opens a short position using the given client order ID as cloid (empty for none; xyz dex orders carry none)
+func (t *HyperliquidTrader) openShort(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	// First cancel all pending orders for this coin
 	if err := t.CancelAllOrders(symbol); err != nil {
 		logger.Infof("  ⚠ Failed to cancel old pending orders: %v", err)
@@ -842,6 +869,11 @@ func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage
 			ReduceOnly: false,
 		}
 
+		if clientOrderID != "" {
+			cloid := hyperliquidCloid(clientOrderID)
+			order.ClientOrderID = &cloid
+		}
+
 		_, err = t.exchange.Order(t.ctx, order, defaultBuilder)
 		if err != nil {
 			return nil, fmt.Errorf("failed to open short position: %w", err)
@@ -860,6 +892,16 @@ func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage
 
 // CloseLong closes a long position (supports both crypto and xyz dex)
 func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, "")
+}
+
+// CloseLongWithClientID is CloseLong with a caller-supplied client order ID (retry-safe)
+func (t *HyperliquidTrader) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, clientOrderID)
+}
+
+// closeLong closes a long position using the given client order ID as cloid (empty for none; xyz dex orders carry none)
+func (t *HyperliquidTrader) closeLong(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	// Hyperliquid symbol format
 	coin := convertSymbolToHyperliquid(symbol)
 	isXyz := strings.HasPrefix(coin, "xyz:")
@@ -924,6 +966,11 @@ func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[stri
 			ReduceOnly: true,
 		}
 
+		if clientOrderID != "" {
+			cloid := hyperliquidCloid(clientOrderID)
+			order.ClientOrderID = &cloid
+		}
+
 		_, err = t.exchange.Order(t.ctx, order, defaultBuilder)
 		if err != nil {
 			return nil, fmt.Errorf("failed to close long position: %w", err)
@@ -947,6 +994,16 @@ func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[stri
 
 // CloseShort closes a short position (supports both crypto and xyz dex)
 func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, "")
+}
+
+// CloseShortWithClientID is CloseShort with a caller-supplied client order ID (retry-safe)
+func (t *HyperliquidTrader) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, clientOrderID)
+}
+
+// closeShort closes a short position using the given client order ID as cloid (empty for none; xyz dex orders carry none)
+func (t *HyperliquidTrader) closeShort(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	// Hyperliquid symbol format
 	coin := convertSymbolToHyperliquid(symbol)
 	isXyz := strings.HasPrefix(coin, "xyz:")
@@ -1011,6 +1068,11 @@ func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[str
 			ReduceOnly: true,
 		}
 
+		if clientOrderID != "" {
+			cloid := hyperliquidCloid(clientOrderID)
+			order.ClientOrderID = &cloid
+		}
+
 		_, err = t.exchange.Order(t.ctx, order, defaultBuilder)
 		if err != nil {
 			return nil, fmt.Errorf("failed to close short position: %w", err)
@@ -1800,6 +1862,12 @@ func (t *HyperliquidTrader) SetTakeProfit(symbol string, positionSide string, qu
 	return nil
 }
 
+// SetTrailingStop Hyperliquid has no native trailing stop order
+// Returns ErrTrailingStopNotSupported so the caller emulates it by ratcheting the stop-loss
+func (t *HyperliquidTrader) SetTrailingStop(symbol string, positionSide string, quantity, callbackRate, activationPrice float64) error {
+	return types.ErrTrailingStopNotSupported
+}
+
 // FormatQuantity formats quantity to correct precision
 func (t *HyperliquidTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
 	coin := convertSymbolToHyperliquid(symbol)
@@ -1810,6 +1878,52 @@ func (t *HyperliquidTrader) FormatQuantity(symbol string, quantity float64) (str
 	return fmt.Sprintf(formatStr, quantity), nil
 }
 
+// GetContractSpec gets lot step, minimum order value and max leverage for symbol
+// Hyperliquid prices use 5 significant figures rather than a fixed tick, so TickSize is left at 0
+func (t *HyperliquidTrader) GetContractSpec(symbol string) (*types.ContractSpec, error) {
+	coin := convertSymbolToHyperliquid(symbol)
+
+	szDecimals, maxLeverage, found := 0, 0, false
+	if strings.HasPrefix(coin, "xyz:") {
+		t.xyzMetaMutex.RLock()
+		if t.xyzMeta != nil {
+			for _, asset := range t.xyzMeta.Universe {
+				if asset.Name == coin {
+					szDecimals, maxLeverage, found = asset.SzDecimals, asset.MaxLeverage, true
+					break
+				}
+			}
+		}
+		t.xyzMetaMutex.RUnlock()
+	} else {
+		t.metaMutex.RLock()
+		if t.meta != nil {
+			for _, asset := range t.meta.Universe {
+				if asset.Name == coin {
+					szDecimals, maxLeverage, found = asset.SzDecimals, asset.MaxLeverage, true
+					break
+				}
+			}
+		}
+		t.metaMutex.RUnlock()
+	}
+	if !found {
+		return nil, fmt.Errorf("asset %s not found in meta", coin)
+	}
+
+	step := math.Pow(10, -float64(szDecimals))
+	return &types.ContractSpec{
+		Symbol:             symbol,
+		Exchange:           "hyperliquid",
+		QtyStep:            step,
+		MinQty:             step,
+		MinNotional:        10, // Hyperliquid rejects orders under $10
+		MaxLeverage:        maxLeverage,
+		ContractMultiplier: 1,
+		UpdatedAt:          time.Now(),
+	}, nil
+}
+
 // getSzDecimals gets quantity precision for coin
 func (t *HyperliquidTrader) getSzDecimals(coin string) int {
 	// ✅ Concurrency safe: Use read lock to protect meta field access
@@ -1909,6 +2023,33 @@ func convertSymbolToHyperliquid(symbol string) string {
 	return base
 }
 
+// GetOrderByClientID looks up an order by the cloid derived from the client ID passed to *WithClientID
+// Returns nil, nil if Hyperliquid has no such order
+func (t *HyperliquidTrader) GetOrderByClientID(symbol, clientOrderID string) (map[string]interface{}, error) {
+	result, err := t.exchange.Info().QueryOrderByCloid(t.ctx, t.walletAddr, hyperliquidCloid(clientOrderID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order by client ID: %w", err)
+	}
+	if result.Status != hyperliquid.OrderQueryStatusSuccess {
+		return nil, nil // unknownOid
+	}
+
+	status := "CANCELED" // Every other terminal status is a cancel or reject variant
+	switch result.Order.Status {
+	case hyperliquid.OrderStatusValueOpen, hyperliquid.OrderStatusValueTriggered:
+		status = "NEW"
+	case hyperliquid.OrderStatusValueFilled:
+		status = "FILLED"
+	}
+
+	return map[string]interface{}{
+		"orderId":       result.Order.Order.Oid,
+		"clientOrderId": clientOrderID,
+		"symbol":        symbol,
+		"status":        status,
+	}, nil
+}
+
 // GetOrderStatus gets order status
 // Hyperliquid uses IOC orders, usually filled or cancelled immediately
 // For completed orders, need to query historical records
@@ -2073,6 +2214,7 @@ func (t *HyperliquidTrader) GetTrades(startTime time.Time, limit int) ([]types.T
 		// Hyperliquid uses one-way mode, so PositionSide is "BOTH"
 		trade := types.TradeRecord{
 			TradeID:      strconv.FormatInt(fill.Tid, 10),
+			OrderID:      strconv.FormatInt(fill.Oid, 10),
 			Symbol:       fill.Coin,
 			Side:         side,
 			PositionSide: "BOTH", // Hyperliquid doesn't have hedge mode
@@ -2156,6 +2298,12 @@ func (t *HyperliquidTrader) PlaceLimitOrder(req *types.LimitOrderRequest) (*type
 
 	logger.Infof("[Hyperliquid] PlaceLimitOrder: %s %s @ %.4f, qty=%.4f", coin, req.Side, roundedPrice, roundedQuantity)
 
+	// Alo (add liquidity only) is Hyperliquid's post-only time in force
+	tif := hyperliquid.TifGtc
+	if req.PostOnly {
+		tif = hyperliquid.TifAlo
+	}
+
 	order := hyperliquid.CreateOrderRequest{
 		Coin:  coin,
 		IsBuy: isBuy,
@@ -2163,24 +2311,36 @@ func (t *HyperliquidTrader) PlaceLimitOrder(req *types.LimitOrderRequest) (*type
 		Price: roundedPrice,
 		OrderType: hyperliquid.OrderType{
 			Limit: &hyperliquid.LimitOrderType{
-				Tif: hyperliquid.TifGtc, // Good Till Cancel for grid orders
+				Tif: tif,
 			},
 		},
 		ReduceOnly: req.ReduceOnly,
 	}
+	if req.ClientID != "" {
+		cloid := hyperliquidCloid(req.ClientID)
+		order.ClientOrderID = &cloid
+	}
 
-	_, err := t.exchange.Order(t.ctx, order, defaultBuilder)
+	status, err := t.exchange.Order(t.ctx, order, defaultBuilder)
 	if err != nil {
 		return nil, fmt.Errorf("failed to place limit order: %w", err)
 	}
+	if status.Error != nil {
+		return nil, fmt.Errorf("limit order rejected: %s", *status.Error)
+	}
 
-	// Note: Hyperliquid's Order response doesn't return the order ID directly
-	// We would need to query open orders to get it, but for grid trading
-	// we can track orders by price level instead
-	orderID := fmt.Sprintf("%d", time.Now().UnixNano())
+	var orderID, orderStatus string
+	switch {
+	case status.Resting != nil:
+		orderID, orderStatus = strconv.FormatInt(status.Resting.Oid, 10), "NEW"
+	case status.Filled != nil:
+		orderID, orderStatus = strconv.Itoa(status.Filled.Oid), "FILLED"
+	default:
+		return nil, fmt.Errorf("limit order response has no order ID")
+	}
 
-	logger.Infof("✓ [Hyperliquid] Limit order placed: %s %s @ %.4f",
-		coin, req.Side, roundedPrice)
+	logger.Infof("✓ [Hyperliquid] Limit order placed: %s %s @ %.4f, orderID=%s",
+		coin, req.Side, roundedPrice, orderID)
 
 	return &types.LimitOrderResult{
 		OrderID:      orderID,
@@ -2190,10 +2350,16 @@ func (t *HyperliquidTrader) PlaceLimitOrder(req *types.LimitOrderRequest) (*type
 		PositionSide: req.PositionSide,
 		Price:        roundedPrice,
 		Quantity:     roundedQuantity,
-		Status:       "NEW",
+		Status:       orderStatus,
 	}, nil
 }
 
+// hyperliquidCloid maps a client order ID onto Hyperliquid's 16-byte hex cloid
+// The mapping is deterministic, so the same client ID always yields the same cloid
+func hyperliquidCloid(clientID string) string {
+	return "0x" + hex.EncodeToString(crypto.Keccak256([]byte(clientID))[:16])
+}
+
 // CancelOrder cancels a specific order by ID
 // Implements GridTrader interface
 func (t *HyperliquidTrader) CancelOrder(symbol, orderID string) error {
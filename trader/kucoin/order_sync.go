@@ -260,6 +260,7 @@ func (t *KuCoinTrade) ToTradeRecord() types.TradeRecord {
 
 	return types.TradeRecord{
 		TradeID:      t.TradeID,
+		OrderID:      t.OrderID,
 		Symbol:       t.Symbol,
 		Side:         t.Side,
 		PositionSide: positionSide,
@@ -382,7 +383,7 @@ func (t *KuCoinTrader) SyncOrdersFromKuCoin(traderID string, exchangeID string,
 			traderID, exchangeID, exchangeType,
 			symbol, positionSide, trade.OrderAction,
 			trade.FillQty, trade.FillPrice, trade.Fee, trade.ProfitLoss,
-			execTimeMs, trade.TradeID,
+			execTimeMs, trade.TradeID, trade.OrderID,
 		); err != nil {
 			logger.Infof("  ⚠️ Failed to sync position for trade %s: %v", trade.TradeID, err)
 		} else {
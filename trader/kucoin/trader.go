@@ -33,6 +33,7 @@ const (
 	kucoinPositionModePath = "/api/v1/position/margin/auto-deposit-status"
 	kucoinFillsPath        = "/api/v1/fills"
 	kucoinRecentFillsPath  = "/api/v1/recentFills"
+	kucoinDepthPath        = "/api/v1/level2/depth"
 )
 
 // API channel configuration
@@ -49,6 +50,7 @@ type KuCoinTrader struct {
 
 	// HTTP client
 	httpClient *http.Client
+	baseURL    string // REST endpoint (kucoinBaseURL, overridden by tests)
 
 	// Server time offset (local - server) in milliseconds
 	serverTimeOffset int64
@@ -107,6 +109,7 @@ func NewKuCoinTrader(apiKey, secretKey, passphrase string) *KuCoinTrader {
 		secretKey:      secretKey,
 		passphrase:     passphrase,
 		httpClient:     httpClient,
+		baseURL:        kucoinBaseURL,
 		cacheDuration:  15 * time.Second,
 		contractsCache: make(map[string]*KuCoinContract),
 	}
@@ -122,7 +125,7 @@ func NewKuCoinTrader(apiKey, secretKey, passphrase string) *KuCoinTrader {
 
 // syncServerTime fetches KuCoin server time and calculates offset
 func (t *KuCoinTrader) syncServerTime() error {
-	resp, err := t.httpClient.Get(kucoinBaseURL + "/api/v1/timestamp")
+	resp, err := t.httpClient.Get(t.baseURL + "/api/v1/timestamp")
 	if err != nil {
 		return fmt.Errorf("failed to get server time: %w", err)
 	}
@@ -209,7 +212,7 @@ func (t *KuCoinTrader) doRequest(method, path string, body interface{}) ([]byte,
 	signature := t.sign(timestamp, method, path, string(bodyBytes))
 	signedPassphrase := t.signPassphrase(t.passphrase)
 
-	req, err := http.NewRequest(method, kucoinBaseURL+path, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequest(method, t.baseURL+path, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -559,6 +562,16 @@ func (t *KuCoinTrader) SetLeverage(symbol string, leverage int) error {
 
 // OpenLong opens long position
 func (t *KuCoinTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, "")
+}
+
+// OpenLongWithClientID is OpenLong with a caller-supplied client order ID (retry-safe)
+func (t *KuCoinTrader) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, clientOrderID)
+}
+
+// openLong opens a long position using the given KuCoin client order ID (empty for none)
+func (t *KuCoinTrader) openLong(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	// Cancel old orders
 	t.CancelAllOrders(symbol)
 
@@ -586,6 +599,10 @@ func (t *KuCoinTrader) OpenLong(symbol string, quantity float64, leverage int) (
 		"marginMode": "CROSS", // Use cross margin mode
 	}
 
+	if clientOrderID != "" {
+		body["clientOid"] = clientOrderID
+	}
+
 	data, err := t.doRequest("POST", kucoinOrderPath, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open long position: %w", err)
@@ -614,6 +631,16 @@ func (t *KuCoinTrader) OpenLong(symbol string, quantity float64, leverage int) (
 
 // OpenShort opens short position
 func (t *KuCoinTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, "")
+}
+
+// OpenShortWithClientID is OpenShort with a caller-supplied client order ID (retry-safe)
+func (t *KuCoinTrader) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, clientOrderID)
+}
+
+// openShort opens a short position using the given KuCoin client order ID (empty for none)
+func (t *KuCoinTrader) openShort(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	// Cancel old orders
 	t.CancelAllOrders(symbol)
 
@@ -641,6 +668,10 @@ func (t *KuCoinTrader) OpenShort(symbol string, quantity float64, leverage int)
 		"marginMode": "CROSS", // Use cross margin mode
 	}
 
+	if clientOrderID != "" {
+		body["clientOid"] = clientOrderID
+	}
+
 	data, err := t.doRequest("POST", kucoinOrderPath, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open short position: %w", err)
@@ -694,6 +725,16 @@ func (t *KuCoinTrader) queryOrderFillPrice(orderId string) float64 {
 
 // CloseLong closes long position
 func (t *KuCoinTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, "")
+}
+
+// CloseLongWithClientID is CloseLong with a caller-supplied client order ID (retry-safe)
+func (t *KuCoinTrader) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, clientOrderID)
+}
+
+// closeLong closes a long position using the given KuCoin client order ID (empty for none)
+func (t *KuCoinTrader) closeLong(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	// Invalidate position cache and get fresh positions
 	t.InvalidatePositionCache()
 	positions, err := t.GetPositions()
@@ -748,6 +789,10 @@ func (t *KuCoinTrader) CloseLong(symbol string, quantity float64) (map[string]in
 		"marginMode": marginMode, // Use position's margin mode
 	}
 
+	if clientOrderID != "" {
+		body["clientOid"] = clientOrderID
+	}
+
 	data, err := t.doRequest("POST", kucoinOrderPath, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to close long position: %w", err)
@@ -775,6 +820,16 @@ func (t *KuCoinTrader) CloseLong(symbol string, quantity float64) (map[string]in
 
 // CloseShort closes short position
 func (t *KuCoinTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, "")
+}
+
+// CloseShortWithClientID is CloseShort with a caller-supplied client order ID (retry-safe)
+func (t *KuCoinTrader) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, clientOrderID)
+}
+
+// closeShort closes a short position using the given KuCoin client order ID (empty for none)
+func (t *KuCoinTrader) closeShort(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	// Invalidate position cache and get fresh positions
 	t.InvalidatePositionCache()
 	positions, err := t.GetPositions()
@@ -829,6 +884,10 @@ func (t *KuCoinTrader) CloseShort(symbol string, quantity float64) (map[string]i
 		"marginMode": marginMode, // Use position's margin mode
 	}
 
+	if clientOrderID != "" {
+		body["clientOid"] = clientOrderID
+	}
+
 	data, err := t.doRequest("POST", kucoinOrderPath, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to close short position: %w", err)
@@ -956,6 +1015,12 @@ func (t *KuCoinTrader) SetTakeProfit(symbol string, positionSide string, quantit
 	return nil
 }
 
+// SetTrailingStop KuCoin has no native trailing stop order
+// Returns ErrTrailingStopNotSupported so the caller emulates it by ratcheting the stop-loss
+func (t *KuCoinTrader) SetTrailingStop(symbol string, positionSide string, quantity, callbackRate, activationPrice float64) error {
+	return types.ErrTrailingStopNotSupported
+}
+
 // CancelStopLossOrders cancels stop loss orders
 func (t *KuCoinTrader) CancelStopLossOrders(symbol string) error {
 	return t.cancelStopOrdersByType(symbol, "sl")
@@ -1053,6 +1118,27 @@ func (t *KuCoinTrader) CancelAllOrders(symbol string) error {
 	return nil
 }
 
+// GetContractSpec gets tick size, lot step and max leverage in base-asset units
+// KuCoin sizes orders in lots of Multiplier base currency each
+func (t *KuCoinTrader) GetContractSpec(symbol string) (*types.ContractSpec, error) {
+	contract, err := t.getContract(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	lotQty := contract.LotSize * contract.Multiplier
+	return &types.ContractSpec{
+		Symbol:             symbol,
+		Exchange:           "kucoin",
+		TickSize:           contract.TickSize,
+		QtyStep:            lotQty,
+		MinQty:             lotQty,
+		MaxLeverage:        int(contract.MaxLeverage),
+		ContractMultiplier: contract.Multiplier,
+		UpdatedAt:          time.Now(),
+	}, nil
+}
+
 // FormatQuantity formats quantity to correct precision
 func (t *KuCoinTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
 	contract, err := t.getContract(symbol)
@@ -1069,6 +1155,49 @@ func (t *KuCoinTrader) FormatQuantity(symbol string, quantity float64) (string,
 	return strconv.FormatInt(lotsInt, 10), nil
 }
 
+// GetOrderByClientID looks up an order by the clientOid passed to *WithClientID
+// Returns nil, nil if KuCoin has no such order
+func (t *KuCoinTrader) GetOrderByClientID(symbol, clientOrderID string) (map[string]interface{}, error) {
+	path := kucoinOrderPath + "/byClientOid?clientOid=" + clientOrderID
+	data, err := t.doRequest("GET", path, nil)
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "not exist") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order by client ID: %w", err)
+	}
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	var order struct {
+		Id          string `json:"id"`
+		IsActive    bool   `json:"isActive"`
+		CancelExist bool   `json:"cancelExist"`
+	}
+	if err := json.Unmarshal(data, &order); err != nil {
+		return nil, err
+	}
+	if order.Id == "" {
+		return nil, nil
+	}
+
+	status := "NEW"
+	if !order.IsActive {
+		status = "FILLED"
+		if order.CancelExist {
+			status = "CANCELED"
+		}
+	}
+
+	return map[string]interface{}{
+		"orderId":       order.Id,
+		"clientOrderId": clientOrderID,
+		"symbol":        symbol,
+		"status":        status,
+	}, nil
+}
+
 // GetOrderStatus gets order status
 func (t *KuCoinTrader) GetOrderStatus(symbol string, orderID string) (map[string]interface{}, error) {
 	path := fmt.Sprintf("%s/%s", kucoinOrderPath, orderID)
@@ -1204,6 +1333,7 @@ func (t *KuCoinTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 	var response struct {
 		Items []struct {
 			Id       string  `json:"id"`
+			ClientOid string `json:"clientOid"`
 			Symbol   string  `json:"symbol"`
 			Side     string  `json:"side"`
 			Type     string  `json:"type"`
@@ -1217,6 +1347,7 @@ func (t *KuCoinTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 		// Try alternate format
 		var items []struct {
 			Id       string  `json:"id"`
+			ClientOid string `json:"clientOid"`
 			Symbol   string  `json:"symbol"`
 			Side     string  `json:"side"`
 			Type     string  `json:"type"`
@@ -1249,6 +1380,8 @@ func (t *KuCoinTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 			Price:        price,
 			Quantity:     float64(item.Size),
 			Status:       "NEW",
+
+			ClientOrderID: item.ClientOid,
 		})
 	}
 
@@ -1291,3 +1424,137 @@ func (t *KuCoinTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 
 	return orders, nil
 }
+
+// PlaceLimitOrder places a limit order for grid trading
+// Implements GridTrader interface
+func (t *KuCoinTrader) PlaceLimitOrder(req *types.LimitOrderRequest) (*types.LimitOrderResult, error) {
+	kcSymbol := t.convertSymbol(req.Symbol)
+
+	contract, err := t.getContract(req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contract info: %w", err)
+	}
+
+	lots, err := t.quantityToLots(req.Symbol, req.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate lots: %w", err)
+	}
+	if lots <= 0 {
+		return nil, fmt.Errorf("quantity %.8f is below one lot (%.8f)", req.Quantity, contract.Multiplier)
+	}
+
+	price := req.Price
+	if contract.TickSize > 0 {
+		price = math.Round(price/contract.TickSize) * contract.TickSize
+	}
+
+	side := "buy"
+	if req.Side == "SELL" {
+		side = "sell"
+	}
+
+	leverage := req.Leverage
+	if leverage <= 0 {
+		leverage = 1
+	}
+
+	clientOid := req.ClientID
+	if clientOid == "" {
+		clientOid = fmt.Sprintf("nfx%d", time.Now().UnixNano())
+	}
+
+	body := map[string]interface{}{
+		"clientOid":  clientOid,
+		"symbol":     kcSymbol,
+		"side":       side,
+		"type":       "limit",
+		"price":      strconv.FormatFloat(price, 'f', -1, 64),
+		"size":       lots,
+		"leverage":   fmt.Sprintf("%d", leverage),
+		"reduceOnly": req.ReduceOnly,
+		"postOnly":   req.PostOnly,
+		"marginMode": "CROSS",
+	}
+
+	logger.Infof("[KuCoin] PlaceLimitOrder: %s %s @ %.4f, lots=%d", kcSymbol, side, price, lots)
+
+	data, err := t.doRequest("POST", kucoinOrderPath, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to place limit order: %w", err)
+	}
+
+	var result struct {
+		OrderId   string `json:"orderId"`
+		ClientOid string `json:"clientOid"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse order response: %w", err)
+	}
+	if result.ClientOid == "" {
+		result.ClientOid = clientOid
+	}
+
+	return &types.LimitOrderResult{
+		OrderID:      result.OrderId,
+		ClientID:     result.ClientOid,
+		Symbol:       req.Symbol,
+		Side:         req.Side,
+		PositionSide: req.PositionSide,
+		Price:        price,
+		Quantity:     float64(lots) * contract.Multiplier,
+		Status:       "NEW",
+	}, nil
+}
+
+// CancelOrder cancels a specific order by ID
+// Implements GridTrader interface
+func (t *KuCoinTrader) CancelOrder(symbol, orderID string) error {
+	path := fmt.Sprintf("%s/%s", kucoinCancelOrderPath, orderID)
+	if _, err := t.doRequest("DELETE", path, nil); err != nil {
+		return fmt.Errorf("failed to cancel order: %w", err)
+	}
+
+	logger.Infof("✓ KuCoin order cancelled: %s %s", symbol, orderID)
+	return nil
+}
+
+// GetOrderBook gets the order book for a symbol, sizes converted from lots to base units
+// KuCoin only serves 20 or 100 level snapshots, trimmed to depth
+// Implements GridTrader interface
+func (t *KuCoinTrader) GetOrderBook(symbol string, depth int) (bids, asks [][]float64, err error) {
+	contract, err := t.getContract(symbol)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get contract info: %w", err)
+	}
+
+	levels := 20
+	if depth > 20 {
+		levels = 100
+	}
+	path := fmt.Sprintf("%s%d?symbol=%s", kucoinDepthPath, levels, t.convertSymbol(symbol))
+	data, err := t.doRequest("GET", path, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get order book: %w", err)
+	}
+
+	var book struct {
+		Bids [][]float64 `json:"bids"`
+		Asks [][]float64 `json:"asks"`
+	}
+	if err := json.Unmarshal(data, &book); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse order book: %w", err)
+	}
+
+	convert := func(side [][]float64) [][]float64 {
+		var out [][]float64
+		for _, level := range side {
+			if len(level) < 2 || (depth > 0 && len(out) >= depth) {
+				continue
+			}
+			out = append(out, []float64{level[0], level[1] * contract.Multiplier})
+		}
+		return out
+	}
+
+	return convert(book.Bids), convert(book.Asks), nil
+}
@@ -0,0 +1,93 @@
+package kucoin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"nofx/trader/testutil"
+)
+
+// newKuCoinGridMockServer serves KuCoin order, cancel and depth endpoints for the GridTrader conformance suite
+// XBTUSDTM lots are 0.001 BTC, so book sizes are served in lots
+func newKuCoinGridMockServer(recorder *testutil.GridMockRecorder) *httptest.Server {
+	book := func(levels [][2]string) [][]float64 {
+		var out [][]float64
+		for _, level := range levels {
+			price, _ := strconv.ParseFloat(level[0], 64)
+			size, _ := strconv.ParseFloat(level[1], 64)
+			out = append(out, []float64{price, size / 0.001})
+		}
+		return out
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data interface{}
+
+		switch {
+		case r.Method == "POST" && r.URL.Path == kucoinOrderPath:
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			side := "BUY"
+			if body["side"] == "sell" {
+				side = "SELL"
+			}
+			price, _ := strconv.ParseFloat(body["price"].(string), 64)
+			postOnly, _ := body["postOnly"].(bool)
+			reduceOnly, _ := body["reduceOnly"].(bool)
+			clientOid, _ := body["clientOid"].(string)
+			recorder.RecordOrder(testutil.GridOrderCapture{
+				Side:       side,
+				Price:      price,
+				PostOnly:   postOnly,
+				ReduceOnly: reduceOnly,
+				ClientID:   clientOid,
+			})
+			data = map[string]string{"orderId": testutil.GridMockOrderID, "clientOid": clientOid}
+
+		case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, kucoinCancelOrderPath+"/"):
+			orderID := strings.TrimPrefix(r.URL.Path, kucoinCancelOrderPath+"/")
+			recorder.RecordCancel(orderID)
+			data = map[string][]string{"cancelledOrderIds": {orderID}}
+
+		case r.URL.Path == kucoinDepthPath+"20":
+			data = map[string]interface{}{
+				"symbol": r.URL.Query().Get("symbol"),
+				"bids":   book(testutil.GridMockBids),
+				"asks":   book(testutil.GridMockAsks),
+			}
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": "200000", "data": data})
+	}))
+}
+
+// TestKuCoinTrader_GridTraderConformance runs the GridTrader conformance suite against a mock KuCoin server
+func TestKuCoinTrader_GridTraderConformance(t *testing.T) {
+	recorder := &testutil.GridMockRecorder{}
+	mockServer := newKuCoinGridMockServer(recorder)
+	defer mockServer.Close()
+
+	trader := &KuCoinTrader{
+		apiKey:        "test_api_key",
+		secretKey:     "test_secret_key",
+		passphrase:    "test_passphrase",
+		httpClient:    mockServer.Client(),
+		baseURL:       mockServer.URL,
+		cacheDuration: 15 * time.Second,
+		contractsCache: map[string]*KuCoinContract{
+			"XBTUSDTM": {Symbol: "XBTUSDTM", Multiplier: 0.001, LotSize: 1, TickSize: 0.1, MaxOrderQty: 1000000},
+		},
+		contractsCacheTime: time.Now(),
+	}
+
+	testutil.NewGridTraderTestSuite(t, trader, recorder, 0.05).RunAllTests()
+}
@@ -0,0 +1,153 @@
+package lighter
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	lighterClient "github.com/elliottech/lighter-go/client"
+	"nofx/trader/testutil"
+)
+
+const (
+	gridMockMarketID      = 1
+	gridMockPriceDecimals = 1
+	gridMockSizeDecimals  = 4
+)
+
+// gridNonceClient hands out increasing nonces so the SDK can sign without a live API
+type gridNonceClient struct {
+	nonce int64
+}
+
+func (c *gridNonceClient) GetNextNonce(accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	c.nonce++
+	return c.nonce, nil
+}
+
+func (c *gridNonceClient) GetApiKey(accountIndex int64, apiKeyIndex uint8) (string, error) {
+	return "", nil
+}
+
+// newLighterGridMockServer serves the market list, order book, sendTx and active orders endpoints
+// the GridTrader methods use, decoding each signed create or cancel transaction it receives
+func newLighterGridMockServer(recorder *testutil.GridMockRecorder) *httptest.Server {
+	book := func(levels [][2]string) [][]string {
+		var out [][]string
+		for _, level := range levels {
+			out = append(out, []string{level[0], level[1]})
+		}
+		return out
+	}
+	priceScale := math.Pow10(gridMockPriceDecimals)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var respBody interface{}
+
+		switch r.URL.Path {
+		case "/api/v1/orderBooks":
+			respBody = map[string]interface{}{
+				"code": 200,
+				"order_books": []map[string]interface{}{{
+					"symbol":                   "BTC",
+					"market_id":                gridMockMarketID,
+					"status":                   "active",
+					"supported_size_decimals":  gridMockSizeDecimals,
+					"supported_price_decimals": gridMockPriceDecimals,
+					"min_base_amount":          "0.0001",
+					"min_quote_amount":         "10",
+				}},
+			}
+
+		case "/api/v1/orderBook":
+			respBody = map[string]interface{}{
+				"code": 200,
+				"data": map[string]interface{}{"bids": book(testutil.GridMockBids), "asks": book(testutil.GridMockAsks)},
+			}
+
+		case "/api/v1/sendTx":
+			var tx struct {
+				MarketIndex      uint8
+				ClientOrderIndex int64
+				Price            uint32
+				IsAsk            uint8
+				TimeInForce      uint8
+				ReduceOnly       uint8
+				Index            int64
+			}
+			json.Unmarshal([]byte(r.FormValue("tx_info")), &tx)
+			if r.FormValue("tx_type") == "15" {
+				recorder.RecordCancel(strconv.FormatInt(tx.Index, 10))
+			} else {
+				side := "BUY"
+				if tx.IsAsk == 1 {
+					side = "SELL"
+				}
+				recorder.RecordOrder(testutil.GridOrderCapture{
+					Side:       side,
+					Price:      float64(tx.Price) / priceScale,
+					PostOnly:   tx.TimeInForce == 2,
+					ReduceOnly: tx.ReduceOnly == 1,
+					ClientID:   strconv.FormatInt(tx.ClientOrderIndex, 10),
+				})
+			}
+			respBody = map[string]interface{}{"code": 200, "tx_hash": "0xgrid"}
+
+		case "/api/v1/accountActiveOrders":
+			orderIndex, _ := strconv.ParseInt(testutil.GridMockOrderID, 10, 64)
+			respBody = map[string]interface{}{
+				"code":   200,
+				"orders": []map[string]interface{}{{"order_index": orderIndex, "market_index": gridMockMarketID}},
+			}
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(respBody)
+	}))
+}
+
+// TestLighterTrader_GridTraderConformance runs the GridTrader conformance suite against a mock
+// Lighter server. Client IDs travel as numeric client order indexes, so the suite compares them encoded
+func TestLighterTrader_GridTraderConformance(t *testing.T) {
+	recorder := &testutil.GridMockRecorder{}
+	mockServer := newLighterGridMockServer(recorder)
+	defer mockServer.Close()
+
+	const accountIndex, apiKeyIndex = 100, 2
+	nonces := &gridNonceClient{}
+	txClient, err := lighterClient.NewTxClient(nonces, strings.Repeat("01", 40), accountIndex, apiKeyIndex, 300)
+	if err != nil {
+		t.Fatalf("Failed to create tx client: %v", err)
+	}
+
+	trader := &LighterTraderV2{
+		ctx:             context.Background(),
+		client:          mockServer.Client(),
+		baseURL:         mockServer.URL,
+		httpClient:      nonces,
+		txClient:        txClient,
+		accountIndex:    accountIndex,
+		apiKeyIndex:     apiKeyIndex,
+		apiKeyValid:     true,
+		authToken:       "test_auth_token",
+		tokenExpiry:     time.Now().Add(8 * time.Hour),
+		symbolPrecision: make(map[string]SymbolPrecision),
+		marketIndexMap:  make(map[string]uint16),
+	}
+
+	suite := testutil.NewGridTraderTestSuite(t, trader, recorder, 0.05)
+	suite.EncodeClientID = func(clientID string) string {
+		return strconv.FormatInt(lighterClientOrderIndex(clientID), 10)
+	}
+	suite.RunAllTests()
+}
@@ -9,6 +9,8 @@ import (
 	"nofx/logger"
 	"strconv"
 
+	tradertypes "nofx/trader/types"
+
 	"github.com/elliottech/lighter-go/types"
 )
 
@@ -56,6 +58,12 @@ func (t *LighterTraderV2) SetTakeProfit(symbol string, positionSide string, quan
 	return nil
 }
 
+// SetTrailingStop Lighter has no native trailing stop order
+// Returns ErrTrailingStopNotSupported so the caller emulates it by ratcheting the stop-loss
+func (t *LighterTraderV2) SetTrailingStop(symbol string, positionSide string, quantity, callbackRate, activationPrice float64) error {
+	return tradertypes.ErrTrailingStopNotSupported
+}
+
 // CancelAllOrders Cancel all orders (implements Trader interface)
 func (t *LighterTraderV2) CancelAllOrders(symbol string) error {
 	if t.txClient == nil {
@@ -155,6 +163,92 @@ func (t *LighterTraderV2) GetOrderStatus(symbol string, orderID string) (map[str
 	}, nil
 }
 
+// GetOrderByClientID looks up an order by the client order index derived from the client ID passed to *WithClientID,
+// searching active orders first and then the most recent inactive ones. Returns nil, nil if Lighter has no such order
+func (t *LighterTraderV2) GetOrderByClientID(symbol, clientOrderID string) (map[string]interface{}, error) {
+	index := lighterClientOrderIndex(clientOrderID)
+
+	active, err := t.GetActiveOrders(symbol)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range active {
+		if order.ClientOrderIndex == index {
+			return lighterClientOrder(symbol, clientOrderID, order, "NEW"), nil
+		}
+	}
+
+	inactive, err := t.getInactiveOrders(symbol, 100)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range inactive {
+		if order.ClientOrderIndex != index {
+			continue
+		}
+		status := "CANCELED" // canceled, canceled-post-only, canceled-reduce-only, ...
+		if order.Status == "filled" {
+			status = "FILLED"
+		}
+		return lighterClientOrder(symbol, clientOrderID, order, status), nil
+	}
+	return nil, nil
+}
+
+func lighterClientOrder(symbol, clientOrderID string, order OrderResponse, status string) map[string]interface{} {
+	return map[string]interface{}{
+		"orderId":       fmt.Sprintf("%d", order.OrderIndex),
+		"clientOrderId": clientOrderID,
+		"symbol":        symbol,
+		"status":        status,
+	}
+}
+
+// getInactiveOrders gets the most recent filled or canceled orders of a market
+func (t *LighterTraderV2) getInactiveOrders(symbol string, limit int) ([]OrderResponse, error) {
+	if err := t.ensureAuthToken(); err != nil {
+		return nil, fmt.Errorf("invalid auth token: %w", err)
+	}
+
+	marketIndex, err := t.getMarketIndex(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market index: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v1/accountInactiveOrders?account_index=%d&market_id=%d&limit=%d&auth=%s",
+		t.baseURL, t.accountIndex, marketIndex, limit, url.QueryEscape(t.authToken))
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var apiResp struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Orders  []OrderResponse `json:"orders"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w, body: %s", err, string(body))
+	}
+	if apiResp.Code != 200 {
+		return nil, fmt.Errorf("failed to get inactive orders (code %d): %s", apiResp.Code, apiResp.Message)
+	}
+	return apiResp.Orders, nil
+}
+
 // CancelStopLossOrders Cancel only stop-loss orders (implements Trader interface)
 func (t *LighterTraderV2) CancelStopLossOrders(symbol string) error {
 	// LIGHTER cannot distinguish between stop-loss and take-profit orders yet, will cancel all stop orders
@@ -552,12 +552,15 @@ func (t *LighterTraderV2) GetTrades(startTime time.Time, limit int) ([]tradertyp
 		// IsMakerAsk: true = ask (seller) is maker, false = bid (buyer) is maker
 		var side string
 		var isTaker bool
+		var orderID int64
 		if lt.BidAccountID == t.accountIndex {
 			side = "BUY"
 			isTaker = lt.IsMakerAsk // If maker is ask, then we (bid) are taker
+			orderID = lt.BidID
 		} else if lt.AskAccountID == t.accountIndex {
 			side = "SELL"
 			isTaker = !lt.IsMakerAsk // If maker is NOT ask, then we (ask) are taker
+			orderID = lt.AskID
 		} else {
 			// Neither bid nor ask is our account - skip this trade
 			continue
@@ -618,6 +621,7 @@ func (t *LighterTraderV2) GetTrades(startTime time.Time, limit int) ([]tradertyp
 
 			closeTrade := tradertypes.TradeRecord{
 				TradeID:      fmt.Sprintf("%d_close", lt.TradeID),
+				OrderID:      fmt.Sprintf("%d", orderID),
 				Symbol:       symbol,
 				Side:         side,
 				PositionSide: closeSide,
@@ -632,6 +636,7 @@ func (t *LighterTraderV2) GetTrades(startTime time.Time, limit int) ([]tradertyp
 
 			openTrade := tradertypes.TradeRecord{
 				TradeID:      fmt.Sprintf("%d_open", lt.TradeID),
+				OrderID:      fmt.Sprintf("%d", orderID),
 				Symbol:       symbol,
 				Side:         side,
 				PositionSide: openSide,
@@ -674,6 +679,7 @@ func (t *LighterTraderV2) GetTrades(startTime time.Time, limit int) ([]tradertyp
 
 		trade := tradertypes.TradeRecord{
 			TradeID:      fmt.Sprintf("%d", lt.TradeID),
+			OrderID:      fmt.Sprintf("%d", orderID),
 			Symbol:       symbol,
 			Side:         side,
 			PositionSide: positionSide,
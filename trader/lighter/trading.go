@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"nofx/logger"
@@ -18,6 +20,16 @@ import (
 
 // OpenLong Open long position (implements Trader interface)
 func (t *LighterTraderV2) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, "")
+}
+
+// OpenLongWithClientID is OpenLong with a caller-supplied client order ID (retry-safe)
+func (t *LighterTraderV2) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, clientOrderID)
+}
+
+// openLong opens a long position tagged with the client order index derived from clientOrderID (empty for none)
+func (t *LighterTraderV2) openLong(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	if t.txClient == nil {
 		return nil, fmt.Errorf("TxClient not initialized, please set API Key first")
 	}
@@ -41,7 +53,7 @@ func (t *LighterTraderV2) OpenLong(symbol string, quantity float64, leverage int
 	}
 
 	// 4. Create market buy order (open long)
-	orderResult, err := t.CreateOrder(symbol, false, quantity, 0, "market", false)
+	orderResult, err := t.createOrder(symbol, false, quantity, 0, "market", false, false, lighterClientOrderIndex(clientOrderID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open long: %w", err)
 	}
@@ -59,6 +71,16 @@ func (t *LighterTraderV2) OpenLong(symbol string, quantity float64, leverage int
 
 // OpenShort Open short position (implements Trader interface)
 func (t *LighterTraderV2) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, "")
+}
+
+// OpenShortWithClientID is OpenShort with a caller-supplied client order ID (retry-safe)
+func (t *LighterTraderV2) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, clientOrderID)
+}
+
+// openShort opens a short position tagged with the client order index derived from clientOrderID (empty for none)
+func (t *LighterTraderV2) openShort(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
 	if t.txClient == nil {
 		return nil, fmt.Errorf("TxClient not initialized, please set API Key first")
 	}
@@ -82,7 +104,7 @@ func (t *LighterTraderV2) OpenShort(symbol string, quantity float64, leverage in
 	}
 
 	// 4. Create market sell order (open short)
-	orderResult, err := t.CreateOrder(symbol, true, quantity, 0, "market", false)
+	orderResult, err := t.createOrder(symbol, true, quantity, 0, "market", false, false, lighterClientOrderIndex(clientOrderID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open short: %w", err)
 	}
@@ -100,6 +122,16 @@ func (t *LighterTraderV2) OpenShort(symbol string, quantity float64, leverage in
 
 // CloseLong Close long position (implements Trader interface)
 func (t *LighterTraderV2) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, "")
+}
+
+// CloseLongWithClientID is CloseLong with a caller-supplied client order ID (retry-safe)
+func (t *LighterTraderV2) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, clientOrderID)
+}
+
+// closeLong closes a long position tagged with the client order index derived from clientOrderID (empty for none)
+func (t *LighterTraderV2) closeLong(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	if t.txClient == nil {
 		return nil, fmt.Errorf("TxClient not initialized")
 	}
@@ -127,7 +159,7 @@ func (t *LighterTraderV2) CloseLong(symbol string, quantity float64) (map[string
 	}
 
 	// Create market sell order to close (reduceOnly=true)
-	orderResult, err := t.CreateOrder(symbol, true, quantity, 0, "market", true)
+	orderResult, err := t.createOrder(symbol, true, quantity, 0, "market", true, false, lighterClientOrderIndex(clientOrderID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to close long: %w", err)
 	}
@@ -144,6 +176,16 @@ func (t *LighterTraderV2) CloseLong(symbol string, quantity float64) (map[string
 
 // CloseShort Close short position (implements Trader interface)
 func (t *LighterTraderV2) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, "")
+}
+
+// CloseShortWithClientID is CloseShort with a caller-supplied client order ID (retry-safe)
+func (t *LighterTraderV2) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, clientOrderID)
+}
+
+// closeShort closes a short position tagged with the client order index derived from clientOrderID (empty for none)
+func (t *LighterTraderV2) closeShort(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
 	if t.txClient == nil {
 		return nil, fmt.Errorf("TxClient not initialized")
 	}
@@ -171,7 +213,7 @@ func (t *LighterTraderV2) CloseShort(symbol string, quantity float64) (map[strin
 	}
 
 	// Create market buy order to close (reduceOnly=true)
-	orderResult, err := t.CreateOrder(symbol, false, quantity, 0, "market", true)
+	orderResult, err := t.createOrder(symbol, false, quantity, 0, "market", true, false, lighterClientOrderIndex(clientOrderID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to close short: %w", err)
 	}
@@ -188,6 +230,12 @@ func (t *LighterTraderV2) CloseShort(symbol string, quantity float64) (map[strin
 
 // CreateOrder Create order (market or limit) - uses official SDK for signing
 func (t *LighterTraderV2) CreateOrder(symbol string, isAsk bool, quantity float64, price float64, orderType string, reduceOnly bool) (map[string]interface{}, error) {
+	return t.createOrder(symbol, isAsk, quantity, price, orderType, reduceOnly, false, 0)
+}
+
+// createOrder signs and submits an order; postOnly limit orders rest as maker only and
+// a non-zero clientOrderIndex tags the order so it can be matched back to the grid level
+func (t *LighterTraderV2) createOrder(symbol string, isAsk bool, quantity float64, price float64, orderType string, reduceOnly, postOnly bool, clientOrderIndex int64) (map[string]interface{}, error) {
 	if t.txClient == nil {
 		return nil, fmt.Errorf("TxClient not initialized")
 	}
@@ -200,8 +248,7 @@ func (t *LighterTraderV2) CreateOrder(symbol string, isAsk bool, quantity float6
 	marketIndex := uint8(marketInfo.MarketID) // SDK expects uint8
 
 	// Build order request
-	// Use ClientOrderIndex=0 for market orders (same as web UI)
-	clientOrderIndex := int64(0)
+	// ClientOrderIndex=0 leaves the order untagged (same as web UI)
 
 	var orderTypeValue uint8 = 0 // 0=limit, 1=market
 	if orderType == "market" {
@@ -251,6 +298,9 @@ func (t *LighterTraderV2) CreateOrder(symbol string, isAsk bool, quantity float6
 
 	if orderType == "limit" {
 		timeInForce = 1 // GoodTillTime for limit orders
+		if postOnly {
+			timeInForce = 2 // PostOnly: rejected instead of crossing the book
+		}
 		orderExpiry = time.Now().Add(7 * 24 * time.Hour).UnixMilli()
 	}
 
@@ -511,12 +561,32 @@ func (t *LighterTraderV2) getMarketIndex(symbol string) (uint16, error) {
 	return marketInfo.MarketID, nil
 }
 
+// GetContractSpec gets tick size, lot step and minimums for symbol
+func (t *LighterTraderV2) GetContractSpec(symbol string) (*tradertypes.ContractSpec, error) {
+	market, err := t.getMarketInfo(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &tradertypes.ContractSpec{
+		Symbol:             symbol,
+		Exchange:           "lighter",
+		TickSize:           math.Pow(10, -float64(market.PriceDecimals)),
+		QtyStep:            math.Pow(10, -float64(market.SizeDecimals)),
+		MinQty:             market.MinBaseAmount,
+		MinNotional:        market.MinQuoteAmount,
+		ContractMultiplier: 1,
+		UpdatedAt:          time.Now(),
+	}, nil
+}
+
 // MarketInfo Market information
 type MarketInfo struct {
-	Symbol        string `json:"symbol"`
-	MarketID      uint16 `json:"market_id"`
-	SizeDecimals  int    `json:"size_decimals"`
-	PriceDecimals int    `json:"price_decimals"`
+	Symbol         string  `json:"symbol"`
+	MarketID       uint16  `json:"market_id"`
+	SizeDecimals   int     `json:"size_decimals"`
+	PriceDecimals  int     `json:"price_decimals"`
+	MinBaseAmount  float64 `json:"min_base_amount"`
+	MinQuoteAmount float64 `json:"min_quote_amount"`
 }
 
 // fetchMarketList Fetch market list from API with caching (TTL: 1 hour)
@@ -560,6 +630,8 @@ func (t *LighterTraderV2) fetchMarketList() ([]MarketInfo, error) {
 			Status                 string `json:"status"`
 			SupportedSizeDecimals  int    `json:"supported_size_decimals"`
 			SupportedPriceDecimals int    `json:"supported_price_decimals"`
+			MinBaseAmount          string `json:"min_base_amount"`
+			MinQuoteAmount         string `json:"min_quote_amount"`
 		} `json:"order_books"`
 	}
 
@@ -575,11 +647,15 @@ func (t *LighterTraderV2) fetchMarketList() ([]MarketInfo, error) {
 	markets := make([]MarketInfo, 0, len(apiResp.OrderBooks))
 	for _, market := range apiResp.OrderBooks {
 		if market.Status == "active" {
+			minBase, _ := strconv.ParseFloat(market.MinBaseAmount, 64)
+			minQuote, _ := strconv.ParseFloat(market.MinQuoteAmount, 64)
 			markets = append(markets, MarketInfo{
-				Symbol:        market.Symbol,
-				MarketID:      market.MarketID,
-				SizeDecimals:  market.SupportedSizeDecimals,
-				PriceDecimals: market.SupportedPriceDecimals,
+				Symbol:         market.Symbol,
+				MarketID:       market.MarketID,
+				SizeDecimals:   market.SupportedSizeDecimals,
+				PriceDecimals:  market.SupportedPriceDecimals,
+				MinBaseAmount:  minBase,
+				MinQuoteAmount: minQuote,
 			})
 		}
 	}
@@ -945,7 +1021,8 @@ func (t *LighterTraderV2) PlaceLimitOrder(req *tradertypes.LimitOrderRequest) (*
 	}
 
 	// Create limit order using existing CreateOrder function
-	orderResult, err := t.CreateOrder(req.Symbol, isAsk, req.Quantity, req.Price, "limit", req.ReduceOnly)
+	orderResult, err := t.createOrder(req.Symbol, isAsk, req.Quantity, req.Price, "limit",
+		req.ReduceOnly, req.PostOnly, lighterClientOrderIndex(req.ClientID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to place limit order: %w", err)
 	}
@@ -972,3 +1049,18 @@ func (t *LighterTraderV2) PlaceLimitOrder(req *tradertypes.LimitOrderRequest) (*
 		Status:       "NEW",
 	}, nil
 }
+
+// lighterClientOrderIndex maps a client order ID onto Lighter's numeric client order index,
+// which must be non-zero and fit in 48 bits
+func lighterClientOrderIndex(clientID string) int64 {
+	if clientID == "" {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(clientID))
+	index := int64(h.Sum64() & (1<<48 - 1))
+	if index == 0 {
+		index = 1
+	}
+	return index
+}
@@ -54,6 +54,7 @@ type CreateOrderRequest struct {
 type OrderResponse struct {
 	OrderID             string `json:"order_id"`
 	OrderIndex          int64  `json:"order_index"`
+	ClientOrderIndex    int64  `json:"client_order_index"`
 	MarketIndex         int    `json:"market_index"`
 	Side                string `json:"side"`                  // "bid" or "ask"
 	Type                string `json:"type"`                  // "limit", "market", etc.
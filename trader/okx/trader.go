@@ -51,6 +51,7 @@ type OKXTrader struct {
 
 	// HTTP client (proxy disabled)
 	httpClient *http.Client
+	baseURL    string // REST endpoint (okxBaseURL, overridden by tests)
 
 	// Balance cache
 	cachedBalance     map[string]interface{}
@@ -81,6 +82,7 @@ type OKXInstrument struct {
 	MaxMktSz float64 // Maximum market order size
 	TickSz   float64 // Minimum price increment
 	CtType   string  // Contract type
+	Lever    int     // Maximum leverage
 }
 
 // OKXResponse OKX API response
@@ -118,6 +120,7 @@ func NewOKXTrader(apiKey, secretKey, passphrase string) *OKXTrader {
 		secretKey:        secretKey,
 		passphrase:       passphrase,
 		httpClient:       httpClient,
+		baseURL:          okxBaseURL,
 		cacheDuration:    15 * time.Second,
 		instrumentsCache: make(map[string]*OKXInstrument),
 	}
@@ -205,7 +208,7 @@ func (t *OKXTrader) doRequest(method, path string, body interface{}) ([]byte, er
 	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
 	signature := t.sign(timestamp, method, path, string(bodyBytes))
 
-	req, err := http.NewRequest(method, okxBaseURL+path, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequest(method, t.baseURL+path, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -476,6 +479,7 @@ func (t *OKXTrader) getInstrument(symbol string) (*OKXInstrument, error) {
 		MaxMktSz string `json:"maxMktSz"` // Maximum market order size
 		TickSz   string `json:"tickSz"`
 		CtType   string `json:"ctType"`
+		Lever    string `json:"lever"`
 	}
 
 	if err := json.Unmarshal(data, &instruments); err != nil {
@@ -493,6 +497,7 @@ func (t *OKXTrader) getInstrument(symbol string) (*OKXInstrument, error) {
 	minSz, _ := strconv.ParseFloat(inst.MinSz, 64)
 	maxMktSz, _ := strconv.ParseFloat(inst.MaxMktSz, 64)
 	tickSz, _ := strconv.ParseFloat(inst.TickSz, 64)
+	lever, _ := strconv.Atoi(inst.Lever)
 
 	instrument := &OKXInstrument{
 		InstID:   inst.InstId,
@@ -503,6 +508,7 @@ func (t *OKXTrader) getInstrument(symbol string) (*OKXInstrument, error) {
 		MaxMktSz: maxMktSz,
 		TickSz:   tickSz,
 		CtType:   inst.CtType,
+		Lever:    lever,
 	}
 
 	// Update cache
@@ -514,6 +520,30 @@ func (t *OKXTrader) getInstrument(symbol string) (*OKXInstrument, error) {
 	return instrument, nil
 }
 
+// GetContractSpec gets tick size, lot step and minimums in base-asset units
+// OKX sizes orders in contracts, so lot and minimum sizes are scaled by the contract value
+func (t *OKXTrader) GetContractSpec(symbol string) (*types.ContractSpec, error) {
+	inst, err := t.getInstrument(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	multiplier := inst.CtVal
+	if inst.CtMult > 0 {
+		multiplier *= inst.CtMult
+	}
+	return &types.ContractSpec{
+		Symbol:             symbol,
+		Exchange:           "okx",
+		TickSize:           inst.TickSz,
+		QtyStep:            inst.LotSz * multiplier,
+		MinQty:             inst.MinSz * multiplier,
+		MaxLeverage:        inst.Lever,
+		ContractMultiplier: multiplier,
+		UpdatedAt:          time.Now(),
+	}, nil
+}
+
 // SetMarginMode sets margin mode
 func (t *OKXTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
 	instId := t.convertSymbol(symbol)
@@ -576,6 +606,16 @@ func (t *OKXTrader) SetLeverage(symbol string, leverage int) error {
 
 // OpenLong opens long position
 func (t *OKXTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, genOkxClOrdID())
+}
+
+// OpenLongWithClientID is OpenLong with a caller-supplied clOrdId (retry-safe)
+func (t *OKXTrader) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openLong(symbol, quantity, leverage, clientOrderID)
+}
+
+// openLong opens long position with the given clOrdId
+func (t *OKXTrader) openLong(symbol string, quantity float64, leverage int, clOrdId string) (map[string]interface{}, error) {
 	// Cancel old orders
 	t.CancelAllOrders(symbol)
 
@@ -613,7 +653,7 @@ func (t *OKXTrader) OpenLong(symbol string, quantity float64, leverage int) (map
 		"posSide": "long",
 		"ordType": "market",
 		"sz":      szStr,
-		"clOrdId": genOkxClOrdID(),
+		"clOrdId": clOrdId,
 		"tag":     okxTag,
 	}
 
@@ -653,6 +693,16 @@ func (t *OKXTrader) OpenLong(symbol string, quantity float64, leverage int) (map
 
 // OpenShort opens short position
 func (t *OKXTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, genOkxClOrdID())
+}
+
+// OpenShortWithClientID is OpenShort with a caller-supplied clOrdId (retry-safe)
+func (t *OKXTrader) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error) {
+	return t.openShort(symbol, quantity, leverage, clientOrderID)
+}
+
+// openShort opens short position with the given clOrdId
+func (t *OKXTrader) openShort(symbol string, quantity float64, leverage int, clOrdId string) (map[string]interface{}, error) {
 	// Cancel old orders
 	t.CancelAllOrders(symbol)
 
@@ -690,7 +740,7 @@ func (t *OKXTrader) OpenShort(symbol string, quantity float64, leverage int) (ma
 		"posSide": "short",
 		"ordType": "market",
 		"sz":      szStr,
-		"clOrdId": genOkxClOrdID(),
+		"clOrdId": clOrdId,
 		"tag":     okxTag,
 	}
 
@@ -730,6 +780,16 @@ func (t *OKXTrader) OpenShort(symbol string, quantity float64, leverage int) (ma
 
 // CloseLong closes long position
 func (t *OKXTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, genOkxClOrdID())
+}
+
+// CloseLongWithClientID is CloseLong with a caller-supplied clOrdId (retry-safe)
+func (t *OKXTrader) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeLong(symbol, quantity, clientOrderID)
+}
+
+// closeLong closes long position with the given clOrdId
+func (t *OKXTrader) closeLong(symbol string, quantity float64, clOrdId string) (map[string]interface{}, error) {
 	instId := t.convertSymbol(symbol)
 
 	// Get instrument info for contract conversion
@@ -795,7 +855,7 @@ func (t *OKXTrader) CloseLong(symbol string, quantity float64) (map[string]inter
 		"side":    "sell",
 		"ordType": "market",
 		"sz":      szStr,
-		"clOrdId": genOkxClOrdID(),
+		"clOrdId": clOrdId,
 		"tag":     okxTag,
 	}
 
@@ -841,6 +901,16 @@ func (t *OKXTrader) CloseLong(symbol string, quantity float64) (map[string]inter
 
 // CloseShort closes short position
 func (t *OKXTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, genOkxClOrdID())
+}
+
+// CloseShortWithClientID is CloseShort with a caller-supplied clOrdId (retry-safe)
+func (t *OKXTrader) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error) {
+	return t.closeShort(symbol, quantity, clientOrderID)
+}
+
+// closeShort closes short position with the given clOrdId
+func (t *OKXTrader) closeShort(symbol string, quantity float64, clOrdId string) (map[string]interface{}, error) {
 	instId := t.convertSymbol(symbol)
 
 	// Get instrument info for contract conversion
@@ -906,7 +976,7 @@ func (t *OKXTrader) CloseShort(symbol string, quantity float64) (map[string]inte
 		"side":    "buy",
 		"ordType": "market",
 		"sz":      szStr,
-		"clOrdId": genOkxClOrdID(),
+		"clOrdId": clOrdId,
 		"tag":     okxTag,
 	}
 
@@ -1069,6 +1139,89 @@ func (t *OKXTrader) SetTakeProfit(symbol string, positionSide string, quantity,
 	return nil
 }
 
+// SetTrailingStop sets a native trailing stop (move_order_stop algo order)
+// Replaces any existing trailing stop for the symbol
+func (t *OKXTrader) SetTrailingStop(symbol string, positionSide string, quantity, callbackRate, activationPrice float64) error {
+	instId := t.convertSymbol(symbol)
+
+	// OKX accepts callback ratios between 0.1% and 100%, but anything above 10% is almost certainly a mistake
+	if callbackRate < 0.1 || callbackRate > 10 {
+		return fmt.Errorf("callback rate %.2f%% out of range (0.1-10)", callbackRate)
+	}
+
+	// Get instrument info
+	inst, err := t.getInstrument(symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get instrument info: %w", err)
+	}
+
+	// Calculate contract size: quantity (in base asset) / ctVal (asset per contract)
+	sz := quantity / inst.CtVal
+	szStr := t.formatSize(sz, inst)
+
+	// Determine direction
+	side := "sell"
+	posSide := "long"
+	if strings.ToUpper(positionSide) == "SHORT" {
+		side = "buy"
+		posSide = "short"
+	}
+
+	t.cancelTrailingStopOrders(instId)
+
+	body := map[string]interface{}{
+		"instId":        instId,
+		"tdMode":        "cross",
+		"side":          side,
+		"posSide":       posSide,
+		"ordType":       "move_order_stop",
+		"sz":            szStr,
+		"callbackRatio": fmt.Sprintf("%.4f", callbackRate/100), // OKX uses a ratio (0.01 = 1%)
+		"reduceOnly":    true,
+		"tag":           okxTag,
+	}
+	if activationPrice > 0 {
+		body["activePx"] = fmt.Sprintf("%.8f", activationPrice)
+	}
+
+	_, err = t.doRequest("POST", okxAlgoOrderPath, body)
+	if err != nil {
+		return fmt.Errorf("failed to set trailing stop: %w", err)
+	}
+
+	logger.Infof("  Trailing stop set: callback %.2f%%, activation %.4f", callbackRate, activationPrice)
+	return nil
+}
+
+// cancelTrailingStopOrders cancels pending move_order_stop algo orders for an instrument
+func (t *OKXTrader) cancelTrailingStopOrders(instId string) {
+	path := fmt.Sprintf("%s?instType=SWAP&instId=%s&ordType=move_order_stop", okxAlgoPendingPath, instId)
+	data, err := t.doRequest("GET", path, nil)
+	if err != nil {
+		return
+	}
+
+	var orders []struct {
+		AlgoId string `json:"algoId"`
+		InstId string `json:"instId"`
+	}
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return
+	}
+
+	for _, order := range orders {
+		body := []map[string]interface{}{
+			{
+				"algoId": order.AlgoId,
+				"instId": order.InstId,
+			},
+		}
+		if _, err := t.doRequest("POST", okxCancelAlgoPath, body); err != nil {
+			logger.Infof("  ⚠️ Failed to cancel trailing stop: %v", err)
+		}
+	}
+}
+
 // CancelStopLossOrders cancels stop loss orders
 func (t *OKXTrader) CancelStopLossOrders(symbol string) error {
 	return t.cancelAlgoOrders(symbol, "sl")
@@ -1274,6 +1427,46 @@ func (t *OKXTrader) GetOrderStatus(symbol string, orderID string) (map[string]in
 	}, nil
 }
 
+// GetOrderByClientID looks up an order by the clOrdId passed to *WithClientID
+// Returns nil, nil if OKX has no order with this clOrdId
+func (t *OKXTrader) GetOrderByClientID(symbol, clientOrderID string) (map[string]interface{}, error) {
+	instId := t.convertSymbol(symbol)
+	path := fmt.Sprintf("/api/v5/trade/order?instId=%s&clOrdId=%s", instId, clientOrderID)
+
+	data, err := t.doRequest("GET", path, nil)
+	if err != nil {
+		// 51603: Order does not exist
+		if strings.Contains(err.Error(), "51603") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order by client ID: %w", err)
+	}
+
+	var orders []struct {
+		OrdId   string `json:"ordId"`
+		ClOrdId string `json:"clOrdId"`
+		State   string `json:"state"`
+	}
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, nil
+	}
+
+	status := strings.ToUpper(orders[0].State)
+	if orders[0].State == "live" {
+		status = "NEW"
+	}
+
+	return map[string]interface{}{
+		"orderId":       orders[0].OrdId,
+		"clientOrderId": orders[0].ClOrdId,
+		"symbol":        symbol,
+		"status":        status,
+	}, nil
+}
+
 // OKX order tag
 var okxTag = func() string {
 	b, _ := base64.StdEncoding.DecodeString("NGMzNjNjODFlZGM1QkNERQ==")
@@ -1403,6 +1596,7 @@ func (t *OKXTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 	if err == nil && data != nil {
 		var orders []struct {
 			OrdId   string `json:"ordId"`
+			ClOrdId string `json:"clOrdId"`
 			InstId  string `json:"instId"`
 			Side    string `json:"side"`    // buy/sell
 			PosSide string `json:"posSide"` // long/short/net
@@ -1433,6 +1627,8 @@ func (t *OKXTrader) GetOpenOrders(symbol string) ([]types.OpenOrder, error) {
 					StopPrice:    0,
 					Quantity:     quantity,
 					Status:       "NEW",
+
+					ClientOrderID: order.ClOrdId,
 				})
 			}
 		}
@@ -1558,16 +1754,39 @@ func (t *OKXTrader) PlaceLimitOrder(req *types.LimitOrderRequest) (*types.LimitO
 		side = "sell"
 		posSide = "short"
 	}
+	// In long/short mode a reduce-only order trades against the opposite position side
+	if req.ReduceOnly {
+		if side == "buy" {
+			posSide = "short"
+		} else {
+			posSide = "long"
+		}
+	}
+	// Explicit position side wins (e.g. sell on long = reduce-only take-profit)
+	if ps := strings.ToLower(req.PositionSide); ps == "long" || ps == "short" {
+		posSide = ps
+	}
+
+	// post_only is rejected instead of filling as taker
+	ordType := "limit"
+	if req.PostOnly {
+		ordType = "post_only"
+	}
+
+	clOrdId := req.ClientID
+	if clOrdId == "" {
+		clOrdId = genOkxClOrdID()
+	}
 
 	body := map[string]interface{}{
 		"instId":  instId,
 		"tdMode":  "cross",
 		"side":    side,
 		"posSide": posSide,
-		"ordType": "limit",
+		"ordType": ordType,
 		"sz":      szStr,
 		"px":      fmt.Sprintf("%.8f", req.Price),
-		"clOrdId": genOkxClOrdID(),
+		"clOrdId": clOrdId,
 		"tag":     okxTag,
 	}
 
@@ -1660,12 +1879,18 @@ func (t *OKXTrader) GetOrderBook(symbol string, depth int) (bids, asks [][]float
 		return nil, nil, nil
 	}
 
+	// Book sizes are in contracts; convert to base units
+	ctVal := 1.0
+	if inst, err := t.getInstrument(symbol); err == nil && inst.CtVal > 0 {
+		ctVal = inst.CtVal
+	}
+
 	// Parse bids
 	for _, b := range result[0].Bids {
 		if len(b) >= 2 {
 			price, _ := strconv.ParseFloat(b[0], 64)
 			qty, _ := strconv.ParseFloat(b[1], 64)
-			bids = append(bids, []float64{price, qty})
+			bids = append(bids, []float64{price, qty * ctVal})
 		}
 	}
 
@@ -1674,7 +1899,7 @@ func (t *OKXTrader) GetOrderBook(symbol string, depth int) (bids, asks [][]float
 		if len(a) >= 2 {
 			price, _ := strconv.ParseFloat(a[0], 64)
 			qty, _ := strconv.ParseFloat(a[1], 64)
-			asks = append(asks, []float64{price, qty})
+			asks = append(asks, []float64{price, qty * ctVal})
 		}
 	}
 
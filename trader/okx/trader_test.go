@@ -0,0 +1,91 @@
+package okx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"nofx/trader/testutil"
+)
+
+// newOKXGridMockServer serves OKX order, cancel and book endpoints for the GridTrader conformance suite
+// BTC-USDT-SWAP contracts are 0.01 BTC, so book sizes are served in contracts
+func newOKXGridMockServer(recorder *testutil.GridMockRecorder) *httptest.Server {
+	book := func(levels [][2]string) [][]string {
+		var out [][]string
+		for _, level := range levels {
+			size, _ := strconv.ParseFloat(level[1], 64)
+			out = append(out, []string{level[0], strconv.FormatFloat(size/0.01, 'f', -1, 64), "0", "1"})
+		}
+		return out
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data interface{}
+
+		switch r.URL.Path {
+		case okxOrderPath:
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			side := "BUY"
+			if body["side"] == "sell" {
+				side = "SELL"
+			}
+			price, _ := strconv.ParseFloat(body["px"].(string), 64)
+			reduceOnly, _ := body["reduceOnly"].(bool)
+			clOrdID, _ := body["clOrdId"].(string)
+			recorder.RecordOrder(testutil.GridOrderCapture{
+				Side:       side,
+				Price:      price,
+				PostOnly:   body["ordType"] == "post_only",
+				ReduceOnly: reduceOnly,
+				ClientID:   clOrdID,
+			})
+			data = []map[string]string{{"ordId": testutil.GridMockOrderID, "clOrdId": clOrdID, "sCode": "0"}}
+
+		case okxCancelOrderPath:
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			recorder.RecordCancel(body["ordId"].(string))
+			data = []map[string]string{{"ordId": body["ordId"].(string), "sCode": "0"}}
+
+		case "/api/v5/market/books":
+			data = []map[string]interface{}{{
+				"bids": book(testutil.GridMockBids),
+				"asks": book(testutil.GridMockAsks),
+			}}
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": "0", "msg": "", "data": data})
+	}))
+}
+
+// TestOKXTrader_GridTraderConformance runs the GridTrader conformance suite against a mock OKX server
+func TestOKXTrader_GridTraderConformance(t *testing.T) {
+	recorder := &testutil.GridMockRecorder{}
+	mockServer := newOKXGridMockServer(recorder)
+	defer mockServer.Close()
+
+	trader := &OKXTrader{
+		apiKey:        "test_api_key",
+		secretKey:     "test_secret_key",
+		passphrase:    "test_passphrase",
+		positionMode:  "long_short_mode",
+		httpClient:    mockServer.Client(),
+		baseURL:       mockServer.URL,
+		cacheDuration: 15 * time.Second,
+		instrumentsCache: map[string]*OKXInstrument{
+			"BTC-USDT-SWAP": {InstID: "BTC-USDT-SWAP", CtVal: 0.01, LotSz: 1, MinSz: 1, TickSz: 0.1},
+		},
+		instrumentsCacheTime: time.Now(),
+	}
+
+	testutil.NewGridTraderTestSuite(t, trader, recorder, 0.05).RunAllTests()
+}
@@ -0,0 +1,121 @@
+package trader
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"nofx/store"
+	"nofx/trader/strategy"
+)
+
+// pairsFakeTrader holds long/short positions per symbol in memory and fails the orders the test asks it to
+type pairsFakeTrader struct {
+	Trader // Methods the spread trade lifecycle doesn't use are left unimplemented
+
+	long, short map[string]float64
+	openErr     map[string]error
+	closeErr    error
+}
+
+func (f *pairsFakeTrader) GetMarketPrice(symbol string) (float64, error) { return 100, nil }
+
+func (f *pairsFakeTrader) SetMarginMode(symbol string, isCrossMargin bool) error { return nil }
+
+func (f *pairsFakeTrader) GetPositions() ([]map[string]interface{}, error) {
+	var positions []map[string]interface{}
+	for symbol, qty := range f.long {
+		if qty > 0 {
+			positions = append(positions, map[string]interface{}{"symbol": symbol, "side": "long", "positionAmt": qty, "entryPrice": 100.0, "markPrice": 100.0})
+		}
+	}
+	for symbol, qty := range f.short {
+		if qty > 0 {
+			positions = append(positions, map[string]interface{}{"symbol": symbol, "side": "short", "positionAmt": -qty, "entryPrice": 100.0, "markPrice": 100.0})
+		}
+	}
+	return positions, nil
+}
+
+func (f *pairsFakeTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	if err := f.openErr[symbol]; err != nil {
+		return nil, err
+	}
+	f.long[symbol] += quantity
+	return map[string]interface{}{"orderId": "open-long-" + symbol}, nil
+}
+
+func (f *pairsFakeTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	if err := f.openErr[symbol]; err != nil {
+		return nil, err
+	}
+	f.short[symbol] += quantity
+	return map[string]interface{}{"orderId": "open-short-" + symbol}, nil
+}
+
+func (f *pairsFakeTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	if f.closeErr != nil {
+		return nil, f.closeErr
+	}
+	f.long[symbol] = 0
+	return map[string]interface{}{"orderId": float64(8200000001)}, nil // Numeric IDs decode from JSON as float64
+}
+
+func TestPairsHalfOpenTradeUnwinds(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "pairs.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	exchange := &pairsFakeTrader{
+		long:     map[string]float64{},
+		short:    map[string]float64{},
+		openErr:  map[string]error{"BTCUSDT": errors.New("insufficient margin")},
+		closeErr: errors.New("exchange unavailable"),
+	}
+	at := &AutoTrader{
+		id:     "pairs-trader",
+		store:  st,
+		trader: exchange,
+		config: AutoTraderConfig{StrategyConfig: &store.StrategyConfig{StrategyType: "pairs", PairsConfig: &store.PairsStrategyConfig{
+			SymbolA: "ETHUSDT", SymbolB: "BTCUSDT", NotionalUSD: 1000, Leverage: 1,
+		}}},
+	}
+
+	stats := &strategy.PairsStats{HedgeRatio: 1, ZScore: -2.5}
+	if err := at.openPairsTrade(store.PairsLongSpread, stats, "test", nil); err == nil {
+		t.Fatal("expected the leg B failure to be reported")
+	}
+	trade, _ := st.Pairs().GetOpenTrade(at.id)
+	if trade == nil || trade.Status != store.PairsTradeUnwinding || trade.QuantityA != 10 || exchange.long["ETHUSDT"] != 10 {
+		t.Fatalf("expected an unwinding trade holding the 10 unit ETHUSDT leg, got %+v", trade)
+	}
+
+	// Unwinding retries before touching klines, so a failing close just leaves the trade as it is
+	if err := at.RunPairsCycle(); err == nil {
+		t.Fatal("expected the failed close to be reported")
+	}
+	if trade, _ = st.Pairs().GetOpenTrade(at.id); trade == nil || trade.Status != store.PairsTradeUnwinding {
+		t.Fatalf("expected the trade still unwinding after a failed retry, got %+v", trade)
+	}
+
+	exchange.closeErr = nil
+	if err := at.RunPairsCycle(); err != nil {
+		t.Fatalf("RunPairsCycle: %v", err)
+	}
+	if trade, _ = st.Pairs().GetOpenTrade(at.id); trade != nil || exchange.long["ETHUSDT"] != 0 {
+		t.Fatalf("expected the leg closed and the trade settled, got %+v", trade)
+	}
+	trades, _ := st.Pairs().ListTrades(at.id, 1)
+	if trades[0].Status != store.PairsTradeClosed || trades[0].CloseReason != store.PairsCloseOpenFailed {
+		t.Errorf("expected the trade closed as %s, got %s/%s", store.PairsCloseOpenFailed, trades[0].Status, trades[0].CloseReason)
+	}
+
+	// OrderSync records the leg's close fill under its trade ID; it gets the pairs label
+	exits := syncTestFills(t, st, at.id,
+		TradeRecord{TradeID: "9101", OrderID: "8200000000", Symbol: "ETHUSDT", OrderAction: "open_long", Price: 100, Quantity: 10},
+		TradeRecord{TradeID: "9102", OrderID: "8200000001", Symbol: "ETHUSDT", OrderAction: "close_long", Price: 100, Quantity: 10},
+	)
+	if want := "pairs_" + store.PairsCloseOpenFailed; len(exits) != 1 || exits[0].Reason != want {
+		t.Errorf("Expected the leg exit labelled %s, got %+v", want, exits)
+	}
+}
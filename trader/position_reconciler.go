@@ -0,0 +1,515 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// Position Reconciler
+// Compares PositionStore open positions, OrderStore fills and exchange GetPositions,
+// heals the safe mismatches and queues the rest in the DriftStore for manual review
+// =============================================================================
+
+const (
+	// defaultReconcileInterval how often the reconciler runs
+	defaultReconcileInterval = 5 * time.Minute
+	// reconcileTolerancePct quantity difference (percent) still considered equal (rounding, lot steps)
+	reconcileTolerancePct = 1.0
+	// reconcileConfirmPasses consecutive passes a mismatch must be seen before acting on it
+	// OrderSync runs every 30s, so a fresh order can briefly look like drift - wait for it to catch up
+	reconcileConfirmPasses = 2
+)
+
+// tradeHistoryProvider is implemented by traders that expose their unified trade history
+// Used to rebuild exit data for positions that were closed outside nofx
+type tradeHistoryProvider interface {
+	GetTrades(startTime time.Time, limit int) ([]TradeRecord, error)
+}
+
+// reconcileSnapshot quantity of one symbol+side as seen by a single source
+type reconcileSnapshot struct {
+	Quantity   float64
+	EntryPrice float64
+	PositionID int64          // Oldest local position ID (PositionStore only)
+	EntryTime  int64          // Oldest local entry time in Unix ms (PositionStore only)
+	Rows       []reconcileRow // Every open local record, oldest first (PositionStore only)
+}
+
+// reconcileRow one open PositionStore record behind a local snapshot
+type reconcileRow struct {
+	ID       int64
+	Quantity float64
+}
+
+// DriftFinding a single mismatch found in one reconcile pass
+type DriftFinding struct {
+	Symbol           string  `json:"symbol"`
+	Side             string  `json:"side"` // LONG or SHORT
+	Kind             string  `json:"kind"`
+	LocalQuantity    float64 `json:"local_quantity"`
+	ExchangeQuantity float64 `json:"exchange_quantity"`
+	OrderQuantity    float64 `json:"order_quantity"`
+	LocalPositionID  int64   `json:"local_position_id"`
+	Healed           bool    `json:"healed"`
+	Detail           string  `json:"detail"`
+
+	exchangeEntryPrice float64
+	localEntryTime     int64
+	localRows          []reconcileRow
+}
+
+// key returns the symbol_side_kind key used to confirm a mismatch across passes
+func (f *DriftFinding) key() string {
+	return f.Symbol + "_" + f.Side + "_" + f.Kind
+}
+
+// ReconcileReport result of one reconcile pass
+type ReconcileReport struct {
+	TraderID  string         `json:"trader_id"`
+	CheckedAt time.Time      `json:"checked_at"`
+	Findings  []DriftFinding `json:"findings"`
+	Pending   int            `json:"pending"` // Mismatches waiting for confirmation in the next pass
+	Healed    int            `json:"healed"`
+	Queued    int            `json:"queued"`
+}
+
+// PositionReconciler background reconciler for one trader
+type PositionReconciler struct {
+	traderID     string
+	exchangeID   string
+	exchangeType string
+	trader       Trader
+	store        *store.Store
+	interval     time.Duration
+
+	mu         sync.Mutex
+	seenCount  map[string]int // symbol_side_kind -> consecutive passes seen
+	lastReport *ReconcileReport
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewPositionReconciler creates a reconciler for one trader
+func NewPositionReconciler(traderID, exchangeID, exchangeType string, t Trader, st *store.Store) *PositionReconciler {
+	return &PositionReconciler{
+		traderID:     traderID,
+		exchangeID:   exchangeID,
+		exchangeType: exchangeType,
+		trader:       t,
+		store:        st,
+		interval:     defaultReconcileInterval,
+		seenCount:    make(map[string]int),
+	}
+}
+
+// Start starts the background reconcile loop
+func (r *PositionReconciler) Start() {
+	r.stopCh = make(chan struct{})
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		logger.Infof("🔍 [%s] Position reconciler started (every %v)", r.traderID, r.interval)
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := r.ReconcileOnce(); err != nil {
+					logger.Warnf("[%s] Position reconcile failed: %v", r.traderID, err)
+				}
+			case <-r.stopCh:
+				logger.Infof("⏹ [%s] Position reconciler stopped", r.traderID)
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background reconcile loop
+func (r *PositionReconciler) Stop() {
+	if r.stopCh == nil {
+		return
+	}
+	close(r.stopCh)
+	r.wg.Wait()
+	r.stopCh = nil
+}
+
+// LastReport returns the most recent reconcile report (nil before the first pass)
+func (r *PositionReconciler) LastReport() *ReconcileReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastReport
+}
+
+// ReconcileOnce runs a single reconcile pass
+func (r *PositionReconciler) ReconcileOnce() (*ReconcileReport, error) {
+	if r.store == nil {
+		return nil, fmt.Errorf("store is nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	local, err := r.loadLocal()
+	if err != nil {
+		return nil, err
+	}
+	exchange, err := r.loadExchange()
+	if err != nil {
+		return nil, err
+	}
+	rawOrders, err := r.store.Order().GetNetPositionsFromOrders(r.traderID)
+	if err != nil {
+		return nil, err
+	}
+	orders := make(map[string]float64, len(rawOrders))
+	for key, qty := range rawOrders {
+		idx := strings.LastIndex(key, "_")
+		orders[market.Normalize(key[:idx])+"_"+strings.ToUpper(key[idx+1:])] += qty
+	}
+
+	report := &ReconcileReport{TraderID: r.traderID, CheckedAt: time.Now().UTC()}
+	findings := classifyDrifts(local, exchange, orders, reconcileTolerancePct)
+
+	seen := make(map[string]bool)
+	nextCount := make(map[string]int)
+	for i := range findings {
+		f := &findings[i]
+		key := f.key()
+		seen[f.Symbol+"_"+f.Side] = true
+		nextCount[key] = r.seenCount[key] + 1
+		if nextCount[key] < reconcileConfirmPasses {
+			report.Pending++
+			continue
+		}
+
+		if err := r.heal(f); err != nil {
+			f.Detail = fmt.Sprintf("%s (auto-heal skipped: %v)", f.Detail, err)
+		}
+
+		drift := &store.PositionDrift{
+			TraderID:         r.traderID,
+			ExchangeID:       r.exchangeID,
+			Symbol:           f.Symbol,
+			Side:             f.Side,
+			Kind:             f.Kind,
+			LocalQuantity:    f.LocalQuantity,
+			ExchangeQuantity: f.ExchangeQuantity,
+			OrderQuantity:    f.OrderQuantity,
+			LocalPositionID:  f.LocalPositionID,
+			Detail:           f.Detail,
+		}
+		if f.Healed {
+			report.Healed++
+			drift.Status = store.DriftStatusHealed
+			drift.Resolution = "auto-healed"
+			delete(nextCount, key)
+		} else {
+			report.Queued++
+		}
+		if err := r.store.Drift().Record(drift, reconcileConfirmPasses*r.interval); err != nil {
+			logger.Warnf("[%s] Failed to record drift %s: %v", r.traderID, key, err)
+		}
+		report.Findings = append(report.Findings, *f)
+	}
+	r.seenCount = nextCount
+
+	if _, err := r.store.Drift().ResolveStale(r.traderID, seen); err != nil {
+		logger.Warnf("[%s] Failed to resolve stale drifts: %v", r.traderID, err)
+	}
+
+	if report.Healed > 0 || report.Queued > 0 {
+		logger.Infof("🔍 [%s] Reconcile: %d healed, %d queued for review, %d pending confirmation",
+			r.traderID, report.Healed, report.Queued, report.Pending)
+	}
+	r.lastReport = report
+	return report, nil
+}
+
+// loadLocal loads open positions from PositionStore keyed by SYMBOL_SIDE
+func (r *PositionReconciler) loadLocal() (map[string]reconcileSnapshot, error) {
+	positions, err := r.store.Position().GetOpenPositions(r.traderID)
+	if err != nil {
+		return nil, err
+	}
+	// Oldest first, so the oldest record is the one kept when duplicates are merged
+	sort.SliceStable(positions, func(i, j int) bool {
+		if positions[i].EntryTime != positions[j].EntryTime {
+			return positions[i].EntryTime < positions[j].EntryTime
+		}
+		return positions[i].ID < positions[j].ID
+	})
+
+	result := make(map[string]reconcileSnapshot)
+	for _, pos := range positions {
+		key := market.Normalize(pos.Symbol) + "_" + strings.ToUpper(pos.Side)
+		snap := result[key]
+		// Multiple open records for the same key are summed; healing merges or closes all of them
+		snap.Quantity += pos.Quantity
+		snap.Rows = append(snap.Rows, reconcileRow{ID: pos.ID, Quantity: pos.Quantity})
+		if snap.PositionID == 0 {
+			snap.PositionID = pos.ID
+			snap.EntryTime = pos.EntryTime
+			snap.EntryPrice = pos.EntryPrice
+		}
+		result[key] = snap
+	}
+	return result, nil
+}
+
+// loadExchange loads positions from the exchange keyed by SYMBOL_SIDE
+func (r *PositionReconciler) loadExchange() (map[string]reconcileSnapshot, error) {
+	positions, err := r.trader.GetPositions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange positions: %w", err)
+	}
+	result := make(map[string]reconcileSnapshot)
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		qty, _ := pos["positionAmt"].(float64)
+		entry, _ := pos["entryPrice"].(float64)
+		qty = math.Abs(qty)
+		if symbol == "" || side == "" || qty == 0 {
+			continue
+		}
+		key := market.Normalize(symbol) + "_" + strings.ToUpper(side)
+		snap := result[key]
+		snap.Quantity += qty
+		snap.EntryPrice = entry
+		result[key] = snap
+	}
+	return result, nil
+}
+
+// classifyDrifts compares the three sources and returns one finding per mismatching symbol+side
+// Keys are "SYMBOL_SIDE"; orders holds the net quantity implied by OrderStore fills
+func classifyDrifts(local, exchange map[string]reconcileSnapshot, orders map[string]float64, tolerancePct float64) []DriftFinding {
+	splitKey := func(key string) (string, string) {
+		idx := strings.LastIndex(key, "_")
+		return key[:idx], key[idx+1:]
+	}
+	opposite := func(side string) string {
+		if side == "LONG" {
+			return "SHORT"
+		}
+		return "LONG"
+	}
+
+	keys := make(map[string]bool)
+	for k := range local {
+		keys[k] = true
+	}
+	for k := range exchange {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var findings []DriftFinding
+	for _, key := range sorted {
+		symbol, side := splitKey(key)
+		loc, hasLocal := local[key]
+		exch, hasExchange := exchange[key]
+
+		f := DriftFinding{
+			Symbol:             symbol,
+			Side:               side,
+			LocalQuantity:      loc.Quantity,
+			ExchangeQuantity:   exch.Quantity,
+			OrderQuantity:      orders[key],
+			LocalPositionID:    loc.PositionID,
+			exchangeEntryPrice: exch.EntryPrice,
+			localEntryTime:     loc.EntryTime,
+			localRows:          loc.Rows,
+		}
+
+		switch {
+		case hasLocal && hasExchange:
+			if quantitiesMatch(loc.Quantity, exch.Quantity, tolerancePct) {
+				continue
+			}
+			f.Kind = store.DriftSizeDrift
+			f.Detail = fmt.Sprintf("local %.8f vs exchange %.8f", loc.Quantity, exch.Quantity)
+		case hasExchange:
+			// Exchange holds this side while local only knows the opposite side → side mismatch
+			oppKey := symbol + "_" + opposite(side)
+			if _, localOpp := local[oppKey]; localOpp {
+				if _, exchOpp := exchange[oppKey]; !exchOpp {
+					f.Kind = store.DriftSideMismatch
+					f.Detail = fmt.Sprintf("exchange holds %s, local holds %s", side, opposite(side))
+					findings = append(findings, f)
+					continue
+				}
+			}
+			f.Kind = store.DriftMissingLocal
+			f.Detail = fmt.Sprintf("exchange holds %.8f with no local record", exch.Quantity)
+		default:
+			// Reported once via the exchange-side key when it's a side mismatch
+			oppKey := symbol + "_" + opposite(side)
+			if _, exchOpp := exchange[oppKey]; exchOpp {
+				if _, localOpp := local[oppKey]; !localOpp {
+					continue
+				}
+			}
+			f.Kind = store.DriftMissingExchange
+			f.Detail = fmt.Sprintf("local holds %.8f but exchange is flat", loc.Quantity)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// quantitiesMatch reports whether a and b are equal within tolerancePct of the larger value
+func quantitiesMatch(a, b, tolerancePct float64) bool {
+	larger := math.Max(math.Abs(a), math.Abs(b))
+	if larger == 0 {
+		return true
+	}
+	return math.Abs(a-b)/larger*100 <= tolerancePct
+}
+
+// heal fixes a finding when it is safe to do so and sets f.Healed
+// Safe cases:
+//   - missing_exchange: the closing trades can be rebuilt from exchange history
+//   - missing_local / size_drift: OrderStore fills agree with the exchange, so the local record is the odd one out
+//
+// Everything else (manual trades, side mismatches) is left for the drift queue
+func (r *PositionReconciler) heal(f *DriftFinding) error {
+	ps := r.store.Position()
+	nowMs := time.Now().UTC().UnixMilli()
+
+	switch f.Kind {
+	case store.DriftMissingExchange:
+		closed, err := r.rebuildClose(f)
+		if err != nil {
+			return err
+		}
+		// Every open record is closed, splitting PnL and fees by quantity, or the drift would reappear next pass
+		for _, row := range f.localRows {
+			share := row.Quantity / f.LocalQuantity
+			if err := ps.ClosePositionFully(row.ID, closed.ExitPrice, closed.OrderID,
+				closed.ExitTime.UTC().UnixMilli(), closed.RealizedPnL*share, closed.Fee*share, "reconciled"); err != nil {
+				return err
+			}
+		}
+		f.Healed = true
+		f.Detail += fmt.Sprintf("; closed %d local record(s) from trade history @ %.6f", len(f.localRows), closed.ExitPrice)
+
+	case store.DriftMissingLocal:
+		if !quantitiesMatch(f.OrderQuantity, f.ExchangeQuantity, reconcileTolerancePct) {
+			return fmt.Errorf("order fills (%.8f) don't explain exchange position, likely a manual trade", f.OrderQuantity)
+		}
+		pos := &store.TraderPosition{
+			TraderID:     r.traderID,
+			ExchangeID:   r.exchangeID,
+			ExchangeType: r.exchangeType,
+			Symbol:       f.Symbol,
+			Side:         f.Side,
+			Quantity:     f.ExchangeQuantity,
+			EntryPrice:   f.exchangeEntryPrice,
+			EntryTime:    nowMs,
+			Leverage:     1,
+			Source:       "reconciler",
+			CreatedAt:    nowMs,
+			UpdatedAt:    nowMs,
+		}
+		if err := ps.CreateOpenPosition(pos); err != nil {
+			return err
+		}
+		f.Healed = true
+		f.Detail += "; local record recreated from exchange"
+
+	case store.DriftSizeDrift:
+		if !quantitiesMatch(f.OrderQuantity, f.ExchangeQuantity, reconcileTolerancePct) {
+			return fmt.Errorf("order fills (%.8f) don't match exchange size", f.OrderQuantity)
+		}
+		if len(f.localRows) > 1 {
+			// Duplicate records for one exchange position: keep the oldest at the exchange size, drop the rest
+			merged := make([]int64, 0, len(f.localRows)-1)
+			for _, row := range f.localRows[1:] {
+				merged = append(merged, row.ID)
+			}
+			if err := ps.MergeOpenPositions(f.LocalPositionID, merged, f.ExchangeQuantity, f.exchangeEntryPrice); err != nil {
+				return err
+			}
+			f.Healed = true
+			f.Detail += fmt.Sprintf("; merged %d local records and corrected quantity to exchange", len(f.localRows))
+			break
+		}
+		if err := ps.CorrectPositionQuantity(f.LocalPositionID, f.ExchangeQuantity, f.exchangeEntryPrice); err != nil {
+			return err
+		}
+		f.Healed = true
+		f.Detail += "; local quantity corrected to exchange"
+
+	default:
+		return fmt.Errorf("%s requires manual review", f.Kind)
+	}
+
+	logger.Infof("🩹 [%s] Reconciler healed %s %s %s", r.traderID, f.Kind, f.Symbol, f.Side)
+	return nil
+}
+
+// rebuildClose reconstructs the close of a local position from exchange trade history
+// Returns the aggregated close (VWAP exit price, summed PnL and fees)
+func (r *PositionReconciler) rebuildClose(f *DriftFinding) (*ClosedPnLRecord, error) {
+	provider, ok := r.trader.(tradeHistoryProvider)
+	if !ok {
+		return nil, fmt.Errorf("exchange doesn't expose trade history")
+	}
+
+	// Start a little before entry so the opening trades are included for FIFO matching
+	start := time.UnixMilli(f.localEntryTime).Add(-time.Minute)
+	if f.localEntryTime == 0 || time.Since(start) > 7*24*time.Hour {
+		start = time.Now().Add(-7 * 24 * time.Hour)
+	}
+	trades, err := provider.GetTrades(start, 1000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trades: %w", err)
+	}
+
+	side := strings.ToLower(f.Side)
+	var agg ClosedPnLRecord
+	var notional float64
+	for _, rec := range RebuildPositionsFromTrades(trades) {
+		if market.Normalize(rec.Symbol) != f.Symbol || rec.Side != side {
+			continue
+		}
+		if rec.ExitTime.UnixMilli() < f.localEntryTime {
+			continue
+		}
+		agg.Quantity += rec.Quantity
+		agg.RealizedPnL += rec.RealizedPnL
+		agg.Fee += rec.Fee
+		notional += rec.ExitPrice * rec.Quantity
+		if rec.ExitTime.After(agg.ExitTime) {
+			agg.ExitTime = rec.ExitTime
+			agg.OrderID = rec.OrderID
+		}
+	}
+	if agg.Quantity == 0 {
+		return nil, fmt.Errorf("no closing trades found since entry")
+	}
+	if !quantitiesMatch(agg.Quantity, f.LocalQuantity, reconcileTolerancePct) {
+		return nil, fmt.Errorf("closing trades (%.8f) don't cover local quantity (%.8f)", agg.Quantity, f.LocalQuantity)
+	}
+	agg.ExitPrice = notional / agg.Quantity
+	return &agg, nil
+}
@@ -0,0 +1,277 @@
+package trader
+
+import (
+	"math"
+	"nofx/store"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClassifyDrifts(t *testing.T) {
+	tests := []struct {
+		name     string
+		local    map[string]reconcileSnapshot
+		exchange map[string]reconcileSnapshot
+		want     map[string]string // symbol_side -> kind
+	}{
+		{
+			name:     "in sync",
+			local:    map[string]reconcileSnapshot{"BTCUSDT_LONG": {Quantity: 0.1}},
+			exchange: map[string]reconcileSnapshot{"BTCUSDT_LONG": {Quantity: 0.1}},
+			want:     map[string]string{},
+		},
+		{
+			name:     "within tolerance",
+			local:    map[string]reconcileSnapshot{"BTCUSDT_LONG": {Quantity: 1.0}},
+			exchange: map[string]reconcileSnapshot{"BTCUSDT_LONG": {Quantity: 0.995}},
+			want:     map[string]string{},
+		},
+		{
+			name:     "missing local",
+			local:    map[string]reconcileSnapshot{},
+			exchange: map[string]reconcileSnapshot{"ETHUSDT_SHORT": {Quantity: 2}},
+			want:     map[string]string{"ETHUSDT_SHORT": store.DriftMissingLocal},
+		},
+		{
+			name:     "missing exchange",
+			local:    map[string]reconcileSnapshot{"ETHUSDT_LONG": {Quantity: 2, PositionID: 7}},
+			exchange: map[string]reconcileSnapshot{},
+			want:     map[string]string{"ETHUSDT_LONG": store.DriftMissingExchange},
+		},
+		{
+			name:     "size drift",
+			local:    map[string]reconcileSnapshot{"SOLUSDT_LONG": {Quantity: 10}},
+			exchange: map[string]reconcileSnapshot{"SOLUSDT_LONG": {Quantity: 5}},
+			want:     map[string]string{"SOLUSDT_LONG": store.DriftSizeDrift},
+		},
+		{
+			name:     "side mismatch reported once",
+			local:    map[string]reconcileSnapshot{"BTCUSDT_LONG": {Quantity: 0.1}},
+			exchange: map[string]reconcileSnapshot{"BTCUSDT_SHORT": {Quantity: 0.1}},
+			want:     map[string]string{"BTCUSDT_SHORT": store.DriftSideMismatch},
+		},
+		{
+			name: "hedge mode both sides",
+			local: map[string]reconcileSnapshot{
+				"BTCUSDT_LONG": {Quantity: 0.1},
+			},
+			exchange: map[string]reconcileSnapshot{
+				"BTCUSDT_LONG":  {Quantity: 0.1},
+				"BTCUSDT_SHORT": {Quantity: 0.2},
+			},
+			want: map[string]string{"BTCUSDT_SHORT": store.DriftMissingLocal},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := classifyDrifts(tt.local, tt.exchange, nil, reconcileTolerancePct)
+			if len(findings) != len(tt.want) {
+				t.Fatalf("Expected %d findings, got %d: %+v", len(tt.want), len(findings), findings)
+			}
+			for _, f := range findings {
+				key := f.Symbol + "_" + f.Side
+				if tt.want[key] != f.Kind {
+					t.Errorf("%s: expected kind %q, got %q", key, tt.want[key], f.Kind)
+				}
+			}
+		})
+	}
+}
+
+// reconcileFakeTrader serves fixed exchange positions and trade history
+type reconcileFakeTrader struct {
+	Trader // Methods the reconciler doesn't use are left unimplemented
+
+	positions []map[string]interface{}
+	trades    []TradeRecord
+}
+
+func (f *reconcileFakeTrader) GetPositions() ([]map[string]interface{}, error) {
+	return f.positions, nil
+}
+
+func (f *reconcileFakeTrader) GetTrades(startTime time.Time, limit int) ([]TradeRecord, error) {
+	return f.trades, nil
+}
+
+func newReconcileTestStore(t *testing.T) *store.Store {
+	t.Helper()
+	st, err := store.New(filepath.Join(t.TempDir(), "reconcile.db"))
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	return st
+}
+
+func createOpenTestPosition(t *testing.T, st *store.Store, symbol, side string, qty, entry float64, entryTime int64) {
+	t.Helper()
+	pos := &store.TraderPosition{
+		TraderID: "trader-1", ExchangeID: "exchange-1", Symbol: symbol, Side: side,
+		Quantity: qty, EntryPrice: entry, EntryTime: entryTime, Leverage: 1,
+	}
+	if err := st.Position().CreateOpenPosition(pos); err != nil {
+		t.Fatalf("CreateOpenPosition: %v", err)
+	}
+}
+
+// reconcilePasses runs n passes and returns the last report
+func reconcilePasses(t *testing.T, r *PositionReconciler, n int) *ReconcileReport {
+	t.Helper()
+	var report *ReconcileReport
+	for i := 0; i < n; i++ {
+		var err error
+		if report, err = r.ReconcileOnce(); err != nil {
+			t.Fatalf("ReconcileOnce: %v", err)
+		}
+	}
+	return report
+}
+
+func TestReconcileHeal_MergesDuplicateRecords(t *testing.T) {
+	st := newReconcileTestStore(t)
+	nowMs := time.Now().UTC().UnixMilli()
+	createOpenTestPosition(t, st, "BTCUSDT", "LONG", 0.1, 50000, nowMs-2000)
+	createOpenTestPosition(t, st, "BTCUSDT", "LONG", 0.1, 50100, nowMs-1000)
+	if err := st.Order().CreateOrder(&store.TraderOrder{
+		TraderID: "trader-1", ExchangeID: "exchange-1", ExchangeOrderID: "1", Symbol: "BTCUSDT", Side: "BUY",
+		PositionSide: "LONG", Type: "MARKET", OrderAction: "open_long", Quantity: 0.1, Status: "FILLED", FilledQuantity: 0.1,
+	}); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	exchange := &reconcileFakeTrader{positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.1, "entryPrice": 50000.0},
+	}}
+	r := NewPositionReconciler("trader-1", "exchange-1", "binance", exchange, st)
+
+	if report := reconcilePasses(t, r, 1); report.Pending != 1 || report.Healed != 0 {
+		t.Fatalf("First pass should only note the drift, got %+v", report)
+	}
+	if report := reconcilePasses(t, r, 1); report.Healed != 1 {
+		t.Fatalf("Second pass should heal, got %+v", report)
+	}
+
+	open, err := st.Position().GetOpenPositions("trader-1")
+	if err != nil {
+		t.Fatalf("GetOpenPositions: %v", err)
+	}
+	if len(open) != 1 || open[0].Quantity != 0.1 || open[0].EntryTime != nowMs-2000 {
+		t.Fatalf("Expected the oldest record kept at the exchange size, got %+v", open)
+	}
+	if report := reconcilePasses(t, r, 1); len(report.Findings) != 0 || report.Pending != 0 {
+		t.Errorf("Drift should be cleared after healing, got %+v", report)
+	}
+
+	// A second heal of the same symbol/side updates the existing drift row instead of appending one
+	createOpenTestPosition(t, st, "BTCUSDT", "LONG", 0.1, 50200, nowMs)
+	reconcilePasses(t, r, 3)
+	drifts, err := st.Drift().List("trader-1", "", 10)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(drifts) != 1 || drifts[0].Status != store.DriftStatusHealed || drifts[0].Occurrences != 2 {
+		t.Errorf("Expected one HEALED drift seen twice, got %+v", drifts)
+	}
+}
+
+func TestReconcileHeal_LaterHealGetsNewDriftRow(t *testing.T) {
+	st := newReconcileTestStore(t)
+	nowMs := time.Now().UTC().UnixMilli()
+	createOpenTestPosition(t, st, "BTCUSDT", "LONG", 0.1, 50000, nowMs-2000)
+	createOpenTestPosition(t, st, "BTCUSDT", "LONG", 0.1, 50100, nowMs-1000)
+	if err := st.Order().CreateOrder(&store.TraderOrder{
+		TraderID: "trader-1", ExchangeID: "exchange-1", ExchangeOrderID: "1", Symbol: "BTCUSDT", Side: "BUY",
+		PositionSide: "LONG", Type: "MARKET", OrderAction: "open_long", Quantity: 0.1, Status: "FILLED", FilledQuantity: 0.1,
+	}); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	exchange := &reconcileFakeTrader{positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.1, "entryPrice": 50000.0},
+	}}
+	r := NewPositionReconciler("trader-1", "exchange-1", "binance", exchange, st)
+	r.interval = 5 * time.Millisecond // Confirm window of two passes: 10ms
+	if report := reconcilePasses(t, r, 2); report.Healed != 1 {
+		t.Fatalf("Expected the duplicate healed, got %+v", report)
+	}
+
+	// The same drift comes back well after the heal: a new episode, not an update of the healed row
+	time.Sleep(20 * time.Millisecond)
+	createOpenTestPosition(t, st, "BTCUSDT", "LONG", 0.1, 50200, nowMs)
+	if report := reconcilePasses(t, r, 2); report.Healed != 1 {
+		t.Fatalf("Expected the second duplicate healed, got %+v", report)
+	}
+	drifts, err := st.Drift().List("trader-1", "", 10)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(drifts) != 2 || drifts[0].Occurrences != 1 || drifts[1].Occurrences != 1 || drifts[0].ID == drifts[1].ID {
+		t.Errorf("Expected two HEALED drifts seen once each, got %+v", drifts)
+	}
+}
+
+func TestReconcileHeal_ClosesEveryRecordWhenExchangeFlat(t *testing.T) {
+	st := newReconcileTestStore(t)
+	entry := time.Now().Add(-time.Hour)
+	createOpenTestPosition(t, st, "ETHUSDT", "LONG", 0.1, 3000, entry.UnixMilli())
+	createOpenTestPosition(t, st, "ETHUSDT", "LONG", 0.2, 3000, entry.UnixMilli()+1)
+	exchange := &reconcileFakeTrader{trades: []TradeRecord{
+		{TradeID: "1", Symbol: "ETHUSDT", Side: "BUY", PositionSide: "LONG", Price: 3000, Quantity: 0.3, Fee: 0.3, Time: entry},
+		{TradeID: "2", Symbol: "ETHUSDT", Side: "SELL", PositionSide: "LONG", Price: 3100, Quantity: 0.3, RealizedPnL: 30, Fee: 0.3, Time: entry.Add(30 * time.Minute)},
+	}}
+	r := NewPositionReconciler("trader-1", "exchange-1", "binance", exchange, st)
+
+	if report := reconcilePasses(t, r, 2); report.Healed != 1 {
+		t.Fatalf("Expected the missing_exchange drift healed, got %+v", report)
+	}
+	open, _ := st.Position().GetOpenPositions("trader-1")
+	if len(open) != 0 {
+		t.Fatalf("Expected every local record closed, %d still open", len(open))
+	}
+	closed, err := st.Position().GetClosedPositions("trader-1", 10)
+	if err != nil {
+		t.Fatalf("GetClosedPositions: %v", err)
+	}
+	var pnl float64
+	for _, pos := range closed {
+		pnl += pos.RealizedPnL
+		if pos.ExitPrice != 3100 {
+			t.Errorf("Position %d exit price = %v, want 3100", pos.ID, pos.ExitPrice)
+		}
+	}
+	if len(closed) != 2 || math.Abs(pnl-30) > 1e-9 {
+		t.Errorf("Expected 2 closed records sharing 30 PnL, got %d records with %.4f", len(closed), pnl)
+	}
+	if report := reconcilePasses(t, r, 1); len(report.Findings) != 0 || report.Pending != 0 {
+		t.Errorf("Drift should be cleared after healing, got %+v", report)
+	}
+}
+
+func TestReconcile_QueuedDriftIsUpserted(t *testing.T) {
+	st := newReconcileTestStore(t)
+	createOpenTestPosition(t, st, "BTCUSDT", "LONG", 0.1, 50000, time.Now().UTC().UnixMilli())
+	exchange := &reconcileFakeTrader{positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "short", "positionAmt": -0.1, "entryPrice": 50000.0},
+	}}
+	r := NewPositionReconciler("trader-1", "exchange-1", "binance", exchange, st)
+
+	if report := reconcilePasses(t, r, 4); report.Queued != 1 {
+		t.Fatalf("Expected the side mismatch queued, got %+v", report)
+	}
+	drifts, err := st.Drift().List("trader-1", "", 10)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(drifts) != 1 || drifts[0].Status != store.DriftStatusOpen || drifts[0].Occurrences != 3 {
+		t.Errorf("Expected one OPEN drift seen 3 times, got %+v", drifts)
+	}
+
+	// Once the exchange matches again the drift is resolved
+	exchange.positions = []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.1, "entryPrice": 50000.0},
+	}
+	reconcilePasses(t, r, 1)
+	if open, _ := st.Drift().List("trader-1", store.DriftStatusOpen, 10); len(open) != 0 {
+		t.Errorf("Expected no open drifts, got %+v", open)
+	}
+}
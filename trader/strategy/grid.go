@@ -0,0 +1,319 @@
+package strategy
+
+import (
+	"math"
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/store"
+	"nofx/trader/types"
+)
+
+// ============================================================================
+// Grid Layout Helpers
+// ============================================================================
+// These are pure functions over the grid config so the backtester can replay
+// the exact level layout, direction and breakout rules the live grid uses.
+
+// GridDefaultBounds returns the fallback grid range: ±3% of price per 10 levels
+func GridDefaultBounds(price float64, config *store.GridStrategyConfig) (upper, lower float64) {
+	multiplier := 0.03 * float64(config.GridCount) / 10
+	return price * (1 + multiplier), price * (1 - multiplier)
+}
+
+// GridATRBounds returns a range of ATRMultiplier×ATR on either side of price
+// Falls back to GridDefaultBounds when no ATR is available
+func GridATRBounds(price, atr float64, config *store.GridStrategyConfig) (upper, lower float64) {
+	if atr <= 0 {
+		return GridDefaultBounds(price, config)
+	}
+
+	multiplier := config.ATRMultiplier
+	if multiplier <= 0 {
+		multiplier = 2.0
+	}
+
+	halfRange := atr * multiplier
+	return price + halfRange, price - halfRange
+}
+
+// BuildGridLevels lays out config.GridCount levels starting at lower, spaced by spacing
+// Investment is split by the configured distribution and level prices are snapped to the spec tick size
+func BuildGridLevels(lower, spacing, currentPrice float64, config *store.GridStrategyConfig, spec *types.ContractSpec) []kernel.GridLevelInfo {
+	levels := make([]kernel.GridLevelInfo, config.GridCount)
+	totalWeight := 0.0
+	weights := make([]float64, config.GridCount)
+
+	// Calculate weights based on distribution
+	for i := 0; i < config.GridCount; i++ {
+		switch config.Distribution {
+		case "gaussian":
+			// Gaussian distribution - more weight in the middle
+			center := float64(config.GridCount-1) / 2
+			sigma := float64(config.GridCount) / 4
+			weights[i] = math.Exp(-math.Pow(float64(i)-center, 2) / (2 * sigma * sigma))
+		case "pyramid":
+			// Pyramid - more weight at bottom
+			weights[i] = float64(config.GridCount - i)
+		default: // uniform
+			weights[i] = 1.0
+		}
+		totalWeight += weights[i]
+	}
+
+	// Create levels
+	for i := 0; i < config.GridCount; i++ {
+		price := spec.RoundPrice(lower + float64(i)*spacing)
+		allocatedUSD := config.TotalInvestment * weights[i] / totalWeight
+
+		// Determine initial side (below current price = buy, above = sell)
+		side := "buy"
+		if price > currentPrice {
+			side = "sell"
+		}
+
+		levels[i] = kernel.GridLevelInfo{
+			Index:        i,
+			Price:        price,
+			State:        "empty",
+			Side:         side,
+			AllocatedUSD: allocatedUSD,
+		}
+	}
+
+	return levels
+}
+
+// GridLevelsToArm returns the empty levels that can rest a limit order at price:
+// buy levels below it and sell levels above it, leaving the level nearest to price as the grid gap
+func GridLevelsToArm(levels []kernel.GridLevelInfo, price float64) []int {
+	gap := -1
+	best := math.MaxFloat64
+	for i, level := range levels {
+		if d := math.Abs(level.Price - price); d < best {
+			best, gap = d, i
+		}
+	}
+
+	var indices []int
+	for i, level := range levels {
+		if i == gap || level.State != "empty" {
+			continue
+		}
+		if (level.Side == "buy" && level.Price < price) || (level.Side == "sell" && level.Price > price) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// ApplyGridDirection reassigns level sides according to direction and the bias ratio
+// A bias ratio outside (0, 1] falls back to the default 0.7 (70%/30%)
+func ApplyGridDirection(levels []kernel.GridLevelInfo, direction market.GridDirection, biasRatio, currentPrice float64) {
+	if biasRatio <= 0 || biasRatio > 1 {
+		biasRatio = 0.7
+	}
+
+	buyRatio, _ := direction.GetBuySellRatio(biasRatio)
+
+	// For neutral: use price-based assignment (buy below, sell above)
+	if direction == market.GridDirectionNeutral {
+		for i := range levels {
+			if levels[i].Price <= currentPrice {
+				levels[i].Side = "buy"
+			} else {
+				levels[i].Side = "sell"
+			}
+		}
+		return
+	}
+
+	// Calculate how many levels should be buy vs sell based on direction
+	totalLevels := len(levels)
+	targetBuyLevels := int(float64(totalLevels) * buyRatio)
+
+	switch direction {
+	case market.GridDirectionLong:
+		// 100% buy - all levels are buy
+		for i := range levels {
+			levels[i].Side = "buy"
+		}
+
+	case market.GridDirectionShort:
+		// 100% sell - all levels are sell
+		for i := range levels {
+			levels[i].Side = "sell"
+		}
+
+	case market.GridDirectionLongBias, market.GridDirectionShortBias:
+		// Assign sides based on position relative to current price
+		// For long_bias: keep all below as buy, convert some above to buy
+		// For short_bias: keep all above as sell, convert some below to sell
+		buyCount := 0
+		sellCount := 0
+
+		for i := range levels {
+			needMoreBuys := buyCount < targetBuyLevels
+			needMoreSells := sellCount < (totalLevels - targetBuyLevels)
+
+			if levels[i].Price <= currentPrice {
+				// Level below or at current price
+				if needMoreBuys {
+					levels[i].Side = "buy"
+					buyCount++
+				} else {
+					levels[i].Side = "sell"
+					sellCount++
+				}
+			} else {
+				// Level above current price
+				if needMoreSells && direction == market.GridDirectionShortBias {
+					levels[i].Side = "sell"
+					sellCount++
+				} else if needMoreBuys && direction == market.GridDirectionLongBias {
+					levels[i].Side = "buy"
+					buyCount++
+				} else if needMoreSells {
+					levels[i].Side = "sell"
+					sellCount++
+				} else {
+					levels[i].Side = "buy"
+					buyCount++
+				}
+			}
+		}
+	}
+}
+
+// EvaluateBoxBreakout runs one multi-period box check and advances the breakout confirmation state
+// Returns the action to take once a breakout is confirmed (BreakoutActionNone otherwise) and,
+// for BreakoutActionAdjustDirection, the direction the grid should switch to
+func EvaluateBoxBreakout(box *market.BoxData, state *BreakoutState, currentDirection market.GridDirection, enableDirectionAdjust bool) (BreakoutAction, market.GridDirection) {
+	breakoutLevel, direction := detectBoxBreakout(box)
+
+	// Check if breakout is confirmed (3 candles)
+	if !confirmBreakout(state, breakoutLevel, direction) {
+		return BreakoutActionNone, currentDirection
+	}
+
+	action := getBreakoutActionWithDirection(breakoutLevel, enableDirectionAdjust)
+	if action == BreakoutActionAdjustDirection {
+		return action, determineGridDirection(box, currentDirection, breakoutLevel, direction)
+	}
+	return action, currentDirection
+}
+
+// RecoverGridDirection steps a biased grid one notch back toward neutral once price is inside the short box
+func RecoverGridDirection(box *market.BoxData, currentDirection market.GridDirection) market.GridDirection {
+	if !shouldRecoverDirection(box, currentDirection) {
+		return currentDirection
+	}
+	return determineRecoveryDirection(box.CurrentPrice, box, currentDirection)
+}
+
+// GridBasketConfigs returns one per-symbol grid config for each basket entry, with
+// TotalInvestment split by weight (equal split when no entry has a weight) and the
+// entry's own bounds. A config without a basket is returned as its only entry.
+func GridBasketConfigs(config *store.GridStrategyConfig) []*store.GridStrategyConfig {
+	if len(config.Basket) == 0 {
+		return []*store.GridStrategyConfig{config}
+	}
+
+	totalWeight := 0.0
+	for _, entry := range config.Basket {
+		if entry.Weight > 0 {
+			totalWeight += entry.Weight
+		}
+	}
+
+	configs := make([]*store.GridStrategyConfig, 0, len(config.Basket))
+	for _, entry := range config.Basket {
+		share := 1 / float64(len(config.Basket))
+		if totalWeight > 0 {
+			share = math.Max(entry.Weight, 0) / totalWeight
+		}
+
+		symbolConfig := *config
+		symbolConfig.Basket = nil
+		symbolConfig.Symbol = entry.Symbol
+		symbolConfig.TotalInvestment = config.TotalInvestment * share
+		if entry.UpperPrice > 0 && entry.LowerPrice > 0 {
+			symbolConfig.UpperPrice = entry.UpperPrice
+			symbolConfig.LowerPrice = entry.LowerPrice
+		}
+		configs = append(configs, &symbolConfig)
+	}
+	return configs
+}
+
+// Grid trailing modes (GridStrategyConfig.TrailingMode)
+const (
+	GridTrailingBoth     = "trailing"
+	GridTrailingInfinity = "infinity"
+)
+
+// GridTrailShift returns how many levels the grid should move to put price back mid-range:
+// positive trails up, negative trails down. It stays 0 until price is TrailingTriggerLevels
+// spacings beyond the range, and infinity grids only trail up.
+func GridTrailShift(config *store.GridStrategyConfig, lower, upper, spacing, price float64) int {
+	if spacing <= 0 {
+		return 0
+	}
+	if config.TrailingMode != GridTrailingBoth && config.TrailingMode != GridTrailingInfinity {
+		return 0
+	}
+	trigger := config.TrailingTriggerLevels
+	if trigger <= 0 {
+		trigger = 1
+	}
+
+	beyond := 0.0
+	if price > upper {
+		beyond = (price - upper) / spacing
+	} else if price < lower && config.TrailingMode == GridTrailingBoth {
+		beyond = (lower - price) / spacing
+	}
+	if beyond < float64(trigger) {
+		return 0
+	}
+	return int(math.Round((price - (lower+upper)/2) / spacing))
+}
+
+// TrailGridLevels moves the grid by shift spacings from lower. Levels left behind on the far side
+// are returned as dropped and the same number of empty levels is added on the trend side, taking
+// over the dropped allocations. Levels are re-indexed and empty levels re-sided around price.
+func TrailGridLevels(levels []kernel.GridLevelInfo, shift int, lower, spacing, price float64, spec *types.ContractSpec) (trailed, dropped []kernel.GridLevelInfo) {
+	n := len(levels)
+	if shift == 0 || n == 0 {
+		return levels, nil
+	}
+
+	for i, level := range levels {
+		if i-shift < 0 || i-shift >= n {
+			dropped = append(dropped, level)
+		}
+	}
+
+	newLower := lower + float64(shift)*spacing
+	trailed = make([]kernel.GridLevelInfo, n)
+	added := 0
+	for j := range trailed {
+		if src := j + shift; src >= 0 && src < n {
+			trailed[j] = levels[src]
+		} else {
+			trailed[j] = kernel.GridLevelInfo{
+				Price:        spec.RoundPrice(newLower + float64(j)*spacing),
+				State:        "empty",
+				AllocatedUSD: dropped[added].AllocatedUSD,
+			}
+			added++
+		}
+		trailed[j].Index = j
+		if trailed[j].State == "empty" {
+			trailed[j].Side = "buy"
+			if trailed[j].Price > price {
+				trailed[j].Side = "sell"
+			}
+		}
+	}
+	return trailed, dropped
+}
@@ -0,0 +1,97 @@
+package strategy
+
+import (
+	"nofx/store"
+	"testing"
+)
+
+func TestGridLevelsToArm(t *testing.T) {
+	config := &store.GridStrategyConfig{GridCount: 5, TotalInvestment: 500}
+	levels := BuildGridLevels(90, 5, 101, config, nil) // 90 95 100 105 110
+	levels[0].State = "pending"
+
+	got := GridLevelsToArm(levels, 101)
+
+	// Level 0 already rests an order and level 2 (100) is the gap nearest to price
+	want := []int{1, 3, 4}
+	if len(got) != len(want) {
+		t.Fatalf("expected levels %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected levels %v, got %v", want, got)
+		}
+	}
+	if levels[1].Side != "buy" || levels[3].Side != "sell" {
+		t.Errorf("expected buys below and sells above price, got %s/%s", levels[1].Side, levels[3].Side)
+	}
+}
+
+func TestGridBasketConfigs(t *testing.T) {
+	config := &store.GridStrategyConfig{Symbol: "BTCUSDT", GridCount: 10, TotalInvestment: 1000, UpperPrice: 110, LowerPrice: 90}
+	if got := GridBasketConfigs(config); len(got) != 1 || got[0] != config {
+		t.Fatalf("expected the config itself without a basket, got %v", got)
+	}
+
+	config.Basket = []store.GridBasketSymbol{
+		{Symbol: "BTCUSDT", Weight: 3},
+		{Symbol: "ETHUSDT", Weight: 1, UpperPrice: 4000, LowerPrice: 3000},
+	}
+	got := GridBasketConfigs(config)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 configs, got %d", len(got))
+	}
+	if got[0].Symbol != "BTCUSDT" || got[0].TotalInvestment != 750 || got[0].UpperPrice != 110 {
+		t.Errorf("unexpected BTC config: %+v", got[0])
+	}
+	if got[1].Symbol != "ETHUSDT" || got[1].TotalInvestment != 250 || got[1].LowerPrice != 3000 {
+		t.Errorf("unexpected ETH config: %+v", got[1])
+	}
+	if got[1].GridCount != 10 || len(got[1].Basket) != 0 {
+		t.Errorf("expected shared settings without the basket, got %+v", got[1])
+	}
+
+	config.Basket[0].Weight, config.Basket[1].Weight = 0, 0
+	if got := GridBasketConfigs(config); got[0].TotalInvestment != 500 || got[1].TotalInvestment != 500 {
+		t.Errorf("expected an equal split without weights, got %.0f/%.0f", got[0].TotalInvestment, got[1].TotalInvestment)
+	}
+}
+
+func TestTrailGridLevels(t *testing.T) {
+	config := &store.GridStrategyConfig{GridCount: 5, TotalInvestment: 500, TrailingMode: GridTrailingBoth}
+	levels := BuildGridLevels(90, 5, 101, config, nil) // 90 95 100 105 110
+	levels[0].State, levels[0].OrderID = "pending", "o-90"
+	levels[1].State, levels[1].PositionSize = "filled", 1
+
+	// Inside the trigger distance the grid holds still
+	if shift := GridTrailShift(config, 90, 110, 5, 113); shift != 0 {
+		t.Fatalf("expected no trail within one level of the range, got %d", shift)
+	}
+	shift := GridTrailShift(config, 90, 110, 5, 116)
+	if shift != 3 {
+		t.Fatalf("expected a 3 level trail to re-centre on 116, got %d", shift)
+	}
+
+	trailed, dropped := TrailGridLevels(levels, shift, 90, 5, 116, nil)
+	if len(dropped) != 3 || dropped[0].OrderID != "o-90" || dropped[1].PositionSize != 1 {
+		t.Fatalf("expected the bottom 3 levels dropped, got %+v", dropped)
+	}
+	wantPrices := []float64{105, 110, 115, 120, 125}
+	for i, level := range trailed {
+		if level.Index != i || level.Price != wantPrices[i] {
+			t.Fatalf("level %d: expected index %d at %.0f, got %d at %.0f", i, i, wantPrices[i], level.Index, level.Price)
+		}
+	}
+	if trailed[4].State != "empty" || trailed[4].Side != "sell" || trailed[4].AllocatedUSD != dropped[2].AllocatedUSD {
+		t.Errorf("expected a new empty sell level with a dropped allocation, got %+v", trailed[4])
+	}
+	if trailed[0].Side != "buy" {
+		t.Errorf("expected empty levels below price re-sided to buy, got %s", trailed[0].Side)
+	}
+
+	// Infinity grids never trail down
+	config.TrailingMode = GridTrailingInfinity
+	if shift := GridTrailShift(config, 90, 110, 5, 80); shift != 0 {
+		t.Errorf("expected infinity grid not to trail down, got %d", shift)
+	}
+}
@@ -0,0 +1,237 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"nofx/market"
+	"nofx/store"
+	"strings"
+)
+
+// Pairs strategy defaults, used when the config leaves a parameter unset
+const (
+	defaultPairsTimeframe    = "1h"
+	defaultPairsLookbackBars = 100
+	defaultPairsEntryZ       = 2.0
+	defaultPairsExitZ        = 0.5
+	defaultPairsStopZ        = 4.0
+	minPairsLookbackBars     = 20
+)
+
+// PairsStats rolling hedge ratio and spread statistics for a pair
+// The spread is log(A) - HedgeRatio*log(B); ZScore is the latest spread's distance from its rolling mean
+type PairsStats struct {
+	HedgeRatio  float64
+	Spread      float64
+	Mean        float64
+	StdDev      float64
+	ZScore      float64
+	Correlation float64 // Correlation of the legs' log returns over the window
+}
+
+// NormalizePairsConfig validates a pairs config and fills in defaults in place
+func NormalizePairsConfig(config *store.PairsStrategyConfig) error {
+	if config == nil {
+		return fmt.Errorf("pairs configuration not found")
+	}
+	config.SymbolA = market.Normalize(strings.TrimSpace(config.SymbolA))
+	config.SymbolB = market.Normalize(strings.TrimSpace(config.SymbolB))
+	if config.SymbolA == "" || config.SymbolB == "" || config.SymbolA == config.SymbolB {
+		return fmt.Errorf("pairs configuration needs two different symbols")
+	}
+	if config.NotionalUSD <= 0 {
+		return fmt.Errorf("pairs configuration needs a positive notional_usd")
+	}
+	if config.Timeframe == "" {
+		config.Timeframe = defaultPairsTimeframe
+	}
+	tf, err := market.NormalizeTimeframe(config.Timeframe)
+	if err != nil {
+		return fmt.Errorf("invalid pairs timeframe: %w", err)
+	}
+	config.Timeframe = tf
+	if config.LookbackBars <= 0 {
+		config.LookbackBars = defaultPairsLookbackBars
+	}
+	if config.LookbackBars < minPairsLookbackBars {
+		return fmt.Errorf("lookback_bars must be at least %d", minPairsLookbackBars)
+	}
+	if config.EntryZ <= 0 {
+		config.EntryZ = defaultPairsEntryZ
+	}
+	if config.ExitZ <= 0 {
+		config.ExitZ = defaultPairsExitZ
+	}
+	if config.StopZ <= 0 {
+		config.StopZ = defaultPairsStopZ
+	}
+	if config.ExitZ >= config.EntryZ || config.StopZ <= config.EntryZ {
+		return fmt.Errorf("pairs thresholds must satisfy exit_z < entry_z < stop_z")
+	}
+	if config.Leverage <= 0 {
+		config.Leverage = 1
+	}
+	if config.Leverage > 10 {
+		return fmt.Errorf("leverage %d exceeds limit 10", config.Leverage)
+	}
+	return nil
+}
+
+// AlignPairsCloses returns the closes of the bars both series share, matched by open time
+func AlignPairsCloses(a, b []market.Kline) ([]float64, []float64) {
+	byTime := make(map[int64]float64, len(b))
+	for _, k := range b {
+		byTime[k.OpenTime] = k.Close
+	}
+	closesA := make([]float64, 0, len(a))
+	closesB := make([]float64, 0, len(a))
+	for _, k := range a {
+		if closeB, ok := byTime[k.OpenTime]; ok {
+			closesA = append(closesA, k.Close)
+			closesB = append(closesB, closeB)
+		}
+	}
+	return closesA, closesB
+}
+
+// PairsSpreadStats fits log(A) = alpha + beta*log(B) by OLS over the last lookback aligned closes
+// and returns the hedge ratio with the spread's z-score. Returns false if there is too little data or no variance
+func PairsSpreadStats(closesA, closesB []float64, lookback int) (*PairsStats, bool) {
+	n := len(closesA)
+	if len(closesB) < n {
+		n = len(closesB)
+	}
+	if lookback > n {
+		return nil, false
+	}
+	logA := make([]float64, lookback)
+	logB := make([]float64, lookback)
+	for i := 0; i < lookback; i++ {
+		pa, pb := closesA[len(closesA)-lookback+i], closesB[len(closesB)-lookback+i]
+		if pa <= 0 || pb <= 0 {
+			return nil, false
+		}
+		logA[i], logB[i] = math.Log(pa), math.Log(pb)
+	}
+
+	meanA, meanB := mean(logA), mean(logB)
+	var cov, varB float64
+	for i := range logA {
+		cov += (logA[i] - meanA) * (logB[i] - meanB)
+		varB += (logB[i] - meanB) * (logB[i] - meanB)
+	}
+	if varB <= 0 {
+		return nil, false
+	}
+	beta := cov / varB
+
+	spreads := make([]float64, lookback)
+	for i := range logA {
+		spreads[i] = logA[i] - beta*logB[i]
+	}
+	spreadMean := mean(spreads)
+	var variance float64
+	for _, s := range spreads {
+		variance += (s - spreadMean) * (s - spreadMean)
+	}
+	std := math.Sqrt(variance / float64(lookback))
+	if std <= 0 {
+		return nil, false
+	}
+
+	stats := &PairsStats{
+		HedgeRatio: beta,
+		Spread:     spreads[lookback-1],
+		Mean:       spreadMean,
+		StdDev:     std,
+	}
+	stats.ZScore = (stats.Spread - spreadMean) / std
+	stats.Correlation = logReturnCorrelation(logA, logB)
+	return stats, true
+}
+
+// PairsEntrySignal returns the spread direction to enter, or "" when there is no signal
+// A negative hedge ratio (legs moving against each other) never signals, since both legs would point the same way
+func PairsEntrySignal(stats *PairsStats, config *store.PairsStrategyConfig) string {
+	if stats == nil || stats.HedgeRatio <= 0 || stats.Correlation < config.MinCorrelation {
+		return ""
+	}
+	switch {
+	case stats.ZScore <= -config.EntryZ && stats.ZScore > -config.StopZ:
+		return store.PairsLongSpread
+	case stats.ZScore >= config.EntryZ && stats.ZScore < config.StopZ:
+		return store.PairsShortSpread
+	}
+	return ""
+}
+
+// PairsExitReason returns the close reason for an open spread trade, or "" to keep holding it
+// pnlPct is the combined leg PnL as a share of the pair notional (%)
+func PairsExitReason(direction string, z, pnlPct float64, barsHeld int, config *store.PairsStrategyConfig) string {
+	// Measure the z-score in the trade's favour: positive while the spread is still stretched
+	stretch := z
+	if direction == store.PairsLongSpread {
+		stretch = -z
+	}
+	switch {
+	case config.StopLossPct > 0 && pnlPct <= -config.StopLossPct:
+		return store.PairsCloseStopLoss
+	case stretch >= config.StopZ:
+		return store.PairsCloseZStop
+	case stretch <= config.ExitZ:
+		return store.PairsCloseReverted
+	case config.MaxHoldingBars > 0 && barsHeld >= config.MaxHoldingBars:
+		return store.PairsCloseMaxHolding
+	}
+	return ""
+}
+
+// PairsLegQuantities sizes the legs: leg A carries notionalUSD and leg B hedgeRatio times that
+func PairsLegQuantities(notionalUSD, hedgeRatio, priceA, priceB float64) (float64, float64) {
+	if priceA <= 0 || priceB <= 0 {
+		return 0, 0
+	}
+	return notionalUSD / priceA, notionalUSD * math.Abs(hedgeRatio) / priceB
+}
+
+// PairsLegSides returns the position sides of legs A and B for a spread direction
+func PairsLegSides(direction string) (string, string) {
+	if direction == store.PairsShortSpread {
+		return "short", "long"
+	}
+	return "long", "short"
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// logReturnCorrelation returns the Pearson correlation of bar-to-bar changes in two log-price series
+func logReturnCorrelation(logA, logB []float64) float64 {
+	if len(logA) < 3 {
+		return 0
+	}
+	retA := make([]float64, len(logA)-1)
+	retB := make([]float64, len(logA)-1)
+	for i := 1; i < len(logA); i++ {
+		retA[i-1], retB[i-1] = logA[i]-logA[i-1], logB[i]-logB[i-1]
+	}
+	meanA, meanB := mean(retA), mean(retB)
+	var cov, varA, varB float64
+	for i := range retA {
+		cov += (retA[i] - meanA) * (retB[i] - meanB)
+		varA += (retA[i] - meanA) * (retA[i] - meanA)
+		varB += (retB[i] - meanB) * (retB[i] - meanB)
+	}
+	if varA <= 0 || varB <= 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
@@ -0,0 +1,87 @@
+package strategy
+
+import (
+	"math"
+	"nofx/store"
+	"testing"
+)
+
+func TestPairsSpreadStats(t *testing.T) {
+	// B wanders; A tracks B^1.5 with a small alternating residual, then the last bar jumps
+	closesA := make([]float64, 60)
+	closesB := make([]float64, 60)
+	for i := range closesB {
+		closesB[i] = 100 * math.Exp(0.05*math.Sin(float64(i)/5))
+		residual := 0.002
+		if i%2 == 1 {
+			residual = -0.002
+		}
+		closesA[i] = math.Exp(1.5*math.Log(closesB[i]) + residual)
+	}
+	closesA[59] *= 1.02
+
+	stats, ok := PairsSpreadStats(closesA, closesB, 50)
+	if !ok {
+		t.Fatal("expected stats")
+	}
+	if math.Abs(stats.HedgeRatio-1.5) > 0.05 {
+		t.Errorf("expected hedge ratio near 1.5, got %.4f", stats.HedgeRatio)
+	}
+	if stats.ZScore < 3 {
+		t.Errorf("expected a stretched spread, got z %.2f", stats.ZScore)
+	}
+	if _, ok := PairsSpreadStats(closesA, closesB, 61); ok {
+		t.Error("expected no stats with a window longer than the data")
+	}
+}
+
+func TestPairsSignals(t *testing.T) {
+	config := &store.PairsStrategyConfig{SymbolA: "eth", SymbolB: "BTC", NotionalUSD: 1000}
+	if err := NormalizePairsConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	if config.SymbolA != "ETHUSDT" || config.EntryZ != 2 || config.ExitZ != 0.5 || config.StopZ != 4 {
+		t.Fatalf("unexpected defaults: %+v", config)
+	}
+	config.StopLossPct = 3
+
+	tests := []struct {
+		z    float64
+		want string
+	}{
+		{-2.5, store.PairsLongSpread},
+		{2.1, store.PairsShortSpread},
+		{1.2, ""},
+		{4.5, ""}, // Already past the stop
+	}
+	for _, tt := range tests {
+		if got := PairsEntrySignal(&PairsStats{HedgeRatio: 1, ZScore: tt.z, Correlation: 0.8}, config); got != tt.want {
+			t.Errorf("z %.2f: expected %q, got %q", tt.z, tt.want, got)
+		}
+	}
+	if got := PairsEntrySignal(&PairsStats{HedgeRatio: -0.5, ZScore: 3}, config); got != "" {
+		t.Errorf("expected no signal with a negative hedge ratio, got %q", got)
+	}
+
+	exits := []struct {
+		direction string
+		z, pnlPct float64
+		want      string
+	}{
+		{store.PairsLongSpread, -1.5, 0, ""},
+		{store.PairsLongSpread, -0.3, 0, store.PairsCloseReverted},
+		{store.PairsLongSpread, -4.2, 0, store.PairsCloseZStop},
+		{store.PairsShortSpread, 4.0, 0, store.PairsCloseZStop},
+		{store.PairsShortSpread, 1.5, -3.5, store.PairsCloseStopLoss},
+	}
+	for _, tt := range exits {
+		if got := PairsExitReason(tt.direction, tt.z, tt.pnlPct, 0, config); got != tt.want {
+			t.Errorf("%s z %.2f pnl %.1f%%: expected %q, got %q", tt.direction, tt.z, tt.pnlPct, tt.want, got)
+		}
+	}
+
+	qtyA, qtyB := PairsLegQuantities(1000, 0.8, 2000, 40000)
+	if math.Abs(qtyA-0.5) > 1e-9 || math.Abs(qtyB-0.02) > 1e-9 {
+		t.Errorf("expected legs 0.5 / 0.02, got %v / %v", qtyA, qtyB)
+	}
+}
@@ -0,0 +1,388 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"strings"
+)
+
+// =============================================================================
+// Take-Profit Ladders
+// A decision can split its take-profit into several rungs, each placed as its own
+// reduce-only order. Once the position shrinks, fills are confirmed by order ID (or by
+// size where rungs have no order of their own), and the stop-loss follows the ladder
+// (break-even after TP1, previous rung after TP2+)
+// =============================================================================
+
+// takeProfitRung one rung of a take-profit ladder
+type takeProfitRung struct {
+	Price    float64
+	Quantity float64
+	OrderID  string // Empty when the exchange only supports SetTakeProfit (not individually cancelable)
+	Filled   bool
+}
+
+// takeProfitLadder take-profit rungs for one position
+type takeProfitLadder struct {
+	Symbol          string
+	Side            string // LONG or SHORT
+	EntryPrice      float64
+	StopLoss        float64 // Stop-loss currently placed by the ladder
+	InitialQuantity float64
+	Rungs           []*takeProfitRung
+}
+
+// buildTakeProfitLadder converts decision levels into priced rungs
+// R-multiple levels are priced from the initial risk |entry - stopLoss|. Rungs are snapped to the
+// contract's tick and lot step: flooring residue goes to the last rung, and a rung below the exchange
+// minimum folds into the next one (the last into the one before), so every rung is a valid order
+func buildTakeProfitLadder(symbol, side string, entryPrice, stopLoss, quantity float64, levels []kernel.TakeProfitLevel, spec *ContractSpec) (*takeProfitLadder, error) {
+	if entryPrice <= 0 || quantity <= 0 {
+		return nil, fmt.Errorf("invalid entry price or quantity")
+	}
+	risk := math.Abs(entryPrice - stopLoss)
+
+	ladder := &takeProfitLadder{
+		Symbol:          symbol,
+		Side:            side,
+		EntryPrice:      entryPrice,
+		StopLoss:        stopLoss,
+		InitialQuantity: quantity,
+	}
+	var target, placed float64
+	for i, level := range levels {
+		price := level.Price
+		if price <= 0 {
+			if risk == 0 || stopLoss <= 0 {
+				return nil, fmt.Errorf("level %d: r_multiple needs a stop loss", i+1)
+			}
+			if side == "LONG" {
+				price = entryPrice + level.RMultiple*risk
+			} else {
+				price = entryPrice - level.RMultiple*risk
+			}
+		}
+		price = spec.RoundPrice(price)
+		if (side == "LONG" && price <= entryPrice) || (side == "SHORT" && price >= entryPrice) {
+			return nil, fmt.Errorf("level %d: price %.6f is not beyond entry %.6f", i+1, price, entryPrice)
+		}
+		rungQty := spec.FloorQty(quantity * level.ClosePct / 100)
+		target += quantity * level.ClosePct / 100
+		placed += rungQty
+		ladder.Rungs = append(ladder.Rungs, &takeProfitRung{
+			Price:    price,
+			Quantity: rungQty,
+		})
+	}
+	if len(ladder.Rungs) == 0 {
+		return ladder, nil
+	}
+	last := ladder.Rungs[len(ladder.Rungs)-1]
+	last.Quantity = spec.FloorQty(last.Quantity + math.Min(target, quantity) - placed)
+
+	valid := make([]*takeProfitRung, 0, len(ladder.Rungs))
+	var carry float64
+	var checkErr error
+	for _, rung := range ladder.Rungs {
+		rung.Quantity = spec.FloorQty(rung.Quantity + carry)
+		carry = 0
+		if err := spec.CheckOrder(rung.Quantity, rung.Price); err != nil {
+			carry, checkErr = rung.Quantity, err
+			continue
+		}
+		valid = append(valid, rung)
+	}
+	if len(valid) == 0 {
+		return nil, fmt.Errorf("no take-profit rung meets the exchange minimum: %w", checkErr)
+	}
+	if carry > 0 {
+		last := valid[len(valid)-1]
+		last.Quantity = spec.FloorQty(last.Quantity + carry)
+	}
+	if len(valid) < len(ladder.Rungs) {
+		logger.Infof("  ⚠ Take-profit ladder: %d rung(s) below the exchange minimum merged into their neighbours", len(ladder.Rungs)-len(valid))
+	}
+	ladder.Rungs = valid
+	return ladder, nil
+}
+
+// quantityBeforeNextRung returns the position size left before the first unfilled rung
+// A smaller position means something filled or was reduced since the last check
+func (l *takeProfitLadder) quantityBeforeNextRung() float64 {
+	remaining := l.InitialQuantity
+	for _, rung := range l.Rungs {
+		if rung.Filled {
+			remaining -= rung.Quantity
+		}
+	}
+	return remaining * 0.999
+}
+
+// runnerQuantity returns the quantity left open after every rung fills
+func (l *takeProfitLadder) runnerQuantity() float64 {
+	remaining := l.InitialQuantity
+	for _, rung := range l.Rungs {
+		remaining -= rung.Quantity
+	}
+	if remaining < l.InitialQuantity*0.001 {
+		return 0
+	}
+	return remaining
+}
+
+// markFilled marks rungs filled and returns the newly filled indexes
+// Rungs placed as their own orders are filled once filledOrders reports their order ID; the others fall
+// back to the shrinking position size, since rungs fill in order: rung i is filled once the position has
+// shrunk by the cumulative rung quantity
+func (l *takeProfitLadder) markFilled(remaining float64, filledOrders map[string]bool) []int {
+	var filled []int
+	var cumulative float64
+	for i, rung := range l.Rungs {
+		cumulative += rung.Quantity
+		if rung.Filled {
+			continue
+		}
+		if rung.OrderID != "" {
+			if filledOrders[rung.OrderID] {
+				rung.Filled = true
+				filled = append(filled, i)
+			}
+			continue
+		}
+		// Allow a little slack for lot-size rounding
+		if remaining <= l.InitialQuantity-cumulative+rung.Quantity*0.05 {
+			rung.Filled = true
+			filled = append(filled, i)
+		}
+	}
+	return filled
+}
+
+// filledRungOrders asks the exchange which resting rung orders have filled
+// Called only once the position has shrunk, so a steady position costs no status requests
+func (at *AutoTrader) filledRungOrders(ladder *takeProfitLadder) map[string]bool {
+	filled := make(map[string]bool)
+	for _, rung := range ladder.Rungs {
+		if rung.Filled || rung.OrderID == "" {
+			continue
+		}
+		status, err := at.trader.GetOrderStatus(ladder.Symbol, rung.OrderID)
+		if err != nil {
+			logger.Infof("⚠️ Take-profit ladder: failed to get order %s status: %v", rung.OrderID, err)
+			continue
+		}
+		if s, _ := status["status"].(string); s == "FILLED" {
+			filled[rung.OrderID] = true
+		}
+	}
+	return filled
+}
+
+// stopAfter returns the stop-loss the ladder moves to once rung i has filled
+// Break-even after the first rung, the previous rung's price after later ones
+func (l *takeProfitLadder) stopAfter(i int) float64 {
+	if i <= 0 {
+		return l.EntryPrice
+	}
+	return l.Rungs[i-1].Price
+}
+
+// isTighter reports whether stop a protects more profit than stop b for this side
+func (l *takeProfitLadder) isTighter(a, b float64) bool {
+	if b <= 0 {
+		return true
+	}
+	if l.Side == "LONG" {
+		return a > b
+	}
+	return a < b
+}
+
+// placeTakeProfitOrders places the take-profit side of a freshly opened position:
+// either the single take_profit order or the ladder, plus the trailing stop if requested
+func (at *AutoTrader) placeTakeProfitOrders(decision *kernel.Decision, side string, quantity, entryPrice float64) {
+	trailingQty := quantity
+
+	if len(decision.TakeProfitLevels) > 0 {
+		ladder, err := at.placeTakeProfitLadder(decision, side, quantity, entryPrice)
+		if err != nil {
+			logger.Infof("  ⚠ Failed to place take-profit ladder: %v", err)
+		} else if runner := ladder.runnerQuantity(); runner > 0 {
+			trailingQty = runner
+		}
+	} else if err := at.trader.SetTakeProfit(decision.Symbol, side, quantity, decision.TakeProfit); err != nil {
+		logger.Infof("  ⚠ Failed to set take profit: %v", err)
+	}
+
+	if decision.TrailingCallbackRate > 0 {
+		if err := at.setTrailingStop(decision.Symbol, side, trailingQty, decision.TrailingCallbackRate,
+			decision.TrailingActivationPrice, decision.StopLoss); err != nil {
+			logger.Infof("  ⚠ Failed to set trailing stop: %v", err)
+		}
+	}
+}
+
+// placeTakeProfitLadder places every rung as a separate reduce-only order and starts tracking the ladder
+func (at *AutoTrader) placeTakeProfitLadder(decision *kernel.Decision, side string, quantity, entryPrice float64) (*takeProfitLadder, error) {
+	spec := at.contractSpec(decision.Symbol)
+	ladder, err := buildTakeProfitLadder(decision.Symbol, side, entryPrice, decision.StopLoss, quantity, decision.TakeProfitLevels, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	orderSide := "SELL"
+	if side == "SHORT" {
+		orderSide = "BUY"
+	}
+
+	gridTrader, native := at.trader.(GridTrader)
+	for i, rung := range ladder.Rungs {
+		if native {
+			result, err := gridTrader.PlaceLimitOrder(&LimitOrderRequest{
+				Symbol:       decision.Symbol,
+				Side:         orderSide,
+				PositionSide: side,
+				Price:        rung.Price,
+				Quantity:     rung.Quantity,
+				ReduceOnly:   true,
+				ClientID:     at.clientOrderIDForCycle(decision.Symbol, fmt.Sprintf("tp:%s:%d", side, i)),
+			})
+			if err != nil {
+				logger.Infof("  ⚠ Failed to place take-profit %d: %v", i+1, err)
+				continue
+			}
+			rung.OrderID = result.OrderID
+		} else if err := at.trader.SetTakeProfit(decision.Symbol, side, rung.Quantity, rung.Price); err != nil {
+			logger.Infof("  ⚠ Failed to place take-profit %d: %v", i+1, err)
+			continue
+		}
+		logger.Infof("  🎯 Take-profit %d: %.6f @ %.6f", i+1, rung.Quantity, rung.Price)
+	}
+
+	at.tpLaddersMutex.Lock()
+	at.tpLadders[decision.Symbol+"_"+side] = ladder
+	at.tpLaddersMutex.Unlock()
+	return ladder, nil
+}
+
+// removeTakeProfitLadder stops tracking a ladder, optionally canceling rungs that haven't filled
+func (at *AutoTrader) removeTakeProfitLadder(symbol, side string, cancelOrders bool) {
+	at.tpLaddersMutex.Lock()
+	ladder := at.tpLadders[symbol+"_"+side]
+	delete(at.tpLadders, symbol+"_"+side)
+	at.tpLaddersMutex.Unlock()
+
+	if ladder != nil && cancelOrders {
+		at.cancelOpenRungs(ladder)
+	}
+}
+
+// cancelOpenRungs cancels rung orders that are still resting on the exchange
+func (at *AutoTrader) cancelOpenRungs(ladder *takeProfitLadder) {
+	gridTrader, ok := at.trader.(GridTrader)
+	if !ok {
+		return
+	}
+	for _, rung := range ladder.Rungs {
+		if rung.Filled || rung.OrderID == "" {
+			continue
+		}
+		if err := gridTrader.CancelOrder(ladder.Symbol, rung.OrderID); err != nil {
+			logger.Infof("⚠️ Failed to cancel take-profit order %s: %v", rung.OrderID, err)
+		}
+	}
+}
+
+// updateTakeProfitLadders detects filled rungs and moves the stop-loss behind them
+// The mutex only guards the map: ladders are snapshotted first and exchange calls run unlocked. Only this loop
+// mutates an existing ladder; placeTakeProfitLadder replaces the entry with a fresh one instead
+func (at *AutoTrader) updateTakeProfitLadders() {
+	at.tpLaddersMutex.Lock()
+	ladders := make(map[string]*takeProfitLadder, len(at.tpLadders))
+	for key, ladder := range at.tpLadders {
+		ladders[key] = ladder
+	}
+	at.tpLaddersMutex.Unlock()
+
+	if len(ladders) == 0 {
+		return
+	}
+
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		logger.Infof("⚠️ Take-profit ladder: failed to get positions: %v", err)
+		return
+	}
+	quantities := make(map[string]float64)
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		qty, _ := pos["positionAmt"].(float64)
+		quantities[symbol+"_"+strings.ToUpper(side)] = math.Abs(qty)
+	}
+
+	for key, ladder := range ladders {
+		remaining := quantities[key]
+		if remaining == 0 {
+			// Position closed by the last rung, the stop or manually - clean up resting rungs
+			at.tpLaddersMutex.Lock()
+			current := at.tpLadders[key] == ladder
+			if current {
+				delete(at.tpLadders, key)
+			}
+			at.tpLaddersMutex.Unlock()
+			if current {
+				at.cancelOpenRungs(ladder)
+			}
+			continue
+		}
+
+		if remaining >= ladder.quantityBeforeNextRung() {
+			continue
+		}
+		filled := ladder.markFilled(remaining, at.filledRungOrders(ladder))
+		if len(filled) == 0 {
+			continue
+		}
+		for _, i := range filled {
+			rung := ladder.Rungs[i]
+			logger.Infof("🎯 Take-profit %d filled: %s %s %.6f @ %.6f", i+1, ladder.Symbol, ladder.Side, rung.Quantity, rung.Price)
+			if at.store != nil && rung.OrderID != "" {
+				if err := at.store.Position().SetExitReason(at.id, rung.OrderID, fmt.Sprintf("take_profit_%d", i+1)); err != nil {
+					logger.Infof("⚠️ Failed to label take-profit exit: %v", err)
+				}
+			}
+		}
+
+		newStop := ladder.stopAfter(filled[len(filled)-1])
+		if !ladder.isTighter(newStop, ladder.StopLoss) || !at.raiseTrailingStopFloor(key, ladder, newStop) {
+			continue
+		}
+
+		if err := at.replaceStopLoss(ladder.Symbol, ladder.Side, remaining, newStop); err != nil {
+			logger.Infof("⚠️ Take-profit ladder: failed to move %s %s stop to %.6f: %v", ladder.Symbol, ladder.Side, newStop, err)
+			continue
+		}
+		logger.Infof("🛡️ Stop moved after take-profit: %s %s %.6f → %.6f", ladder.Symbol, ladder.Side, ladder.StopLoss, newStop)
+		ladder.StopLoss = newStop
+	}
+}
+
+// raiseTrailingStopFloor keeps a software trailing stop in step with a ladder stop move
+// Returns false if the trailing stop is already tighter, in which case the ladder leaves the stop alone
+func (at *AutoTrader) raiseTrailingStopFloor(key string, ladder *takeProfitLadder, stop float64) bool {
+	at.trailingStopsMutex.Lock()
+	defer at.trailingStopsMutex.Unlock()
+
+	state, ok := at.trailingStops[key]
+	if !ok {
+		return true
+	}
+	if !ladder.isTighter(stop, state.StopPrice) {
+		return false
+	}
+	state.StopPrice = stop
+	return true
+}
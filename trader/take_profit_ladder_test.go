@@ -0,0 +1,228 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTakeProfitLadder(t *testing.T) {
+	levels := []kernel.TakeProfitLevel{
+		{RMultiple: 1, ClosePct: 40},
+		{RMultiple: 2, ClosePct: 30},
+	}
+	ladder, err := buildTakeProfitLadder("BTCUSDT", "LONG", 100, 95, 10, levels, nil)
+	if err != nil {
+		t.Fatalf("buildTakeProfitLadder() error = %v", err)
+	}
+
+	wantPrices := []float64{105, 110}
+	wantQty := []float64{4, 3}
+	for i, rung := range ladder.Rungs {
+		if math.Abs(rung.Price-wantPrices[i]) > 1e-9 || math.Abs(rung.Quantity-wantQty[i]) > 1e-9 {
+			t.Errorf("rung %d: got %.4f @ %.4f, want %.4f @ %.4f", i, rung.Quantity, rung.Price, wantQty[i], wantPrices[i])
+		}
+	}
+	if runner := ladder.runnerQuantity(); math.Abs(runner-3) > 1e-9 {
+		t.Errorf("runnerQuantity() = %.4f, want 3", runner)
+	}
+
+	// Nothing filled while the position is intact
+	if filled := ladder.markFilled(10, nil); len(filled) != 0 {
+		t.Errorf("markFilled(10) = %v, want none", filled)
+	}
+	// TP1 filled (rounded down a lot step) → stop to break-even
+	filled := ladder.markFilled(6.01, nil)
+	if len(filled) != 1 || filled[0] != 0 {
+		t.Fatalf("markFilled(6.01) = %v, want [0]", filled)
+	}
+	if stop := ladder.stopAfter(0); stop != 100 {
+		t.Errorf("stopAfter(0) = %.4f, want break-even 100", stop)
+	}
+	// TP2 filled → stop to TP1
+	filled = ladder.markFilled(3, nil)
+	if len(filled) != 1 || filled[0] != 1 {
+		t.Fatalf("markFilled(3) = %v, want [1]", filled)
+	}
+	if stop := ladder.stopAfter(1); stop != 105 {
+		t.Errorf("stopAfter(1) = %.4f, want 105", stop)
+	}
+
+	// Short ladders price below entry
+	short, err := buildTakeProfitLadder("BTCUSDT", "SHORT", 100, 104, 1, []kernel.TakeProfitLevel{{RMultiple: 1.5, ClosePct: 100}}, nil)
+	if err != nil {
+		t.Fatalf("buildTakeProfitLadder(short) error = %v", err)
+	}
+	if math.Abs(short.Rungs[0].Price-94) > 1e-9 {
+		t.Errorf("short rung price = %.4f, want 94", short.Rungs[0].Price)
+	}
+	if short.runnerQuantity() != 0 {
+		t.Errorf("short runnerQuantity() = %.4f, want 0", short.runnerQuantity())
+	}
+	if !short.isTighter(99, 104) || short.isTighter(105, 104) {
+		t.Errorf("isTighter() wrong for short side")
+	}
+}
+
+func TestBuildTakeProfitLadderSnapsToSpec(t *testing.T) {
+	spec := &ContractSpec{Symbol: "BTCUSDT", TickSize: 0.1, QtyStep: 0.001, MinQty: 0.002}
+	pcts := func(p ...float64) []kernel.TakeProfitLevel {
+		var levels []kernel.TakeProfitLevel
+		for i, pct := range p {
+			levels = append(levels, kernel.TakeProfitLevel{RMultiple: float64(i + 1), ClosePct: pct})
+		}
+		return levels
+	}
+
+	tests := []struct {
+		name    string
+		levels  []kernel.TakeProfitLevel
+		spec    *ContractSpec
+		prices  []float64
+		qty     []float64
+		wantErr bool
+	}{
+		{"flooring residue goes to the last rung", pcts(33, 33, 34), spec, []float64{105.1, 110.1, 115.1}, []float64{0.003, 0.003, 0.004}, false},
+		{"runner left open", pcts(45, 30), spec, []float64{105.1, 110.1}, []float64{0.004, 0.003}, false},
+		{"undersized rung folds into the next", pcts(10, 45, 45), spec, []float64{110.1, 115.1}, []float64{0.005, 0.005}, false},
+		{"undersized last rung folds into the one before", pcts(50, 40, 10), spec, []float64{105.1, 110.1}, []float64{0.005, 0.005}, false},
+		{"nothing meets the minimum", pcts(50, 50), &ContractSpec{Symbol: "BTCUSDT", QtyStep: 0.001, MinNotional: 100}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Entry 100.03 with the stop at 95: 1R = 5.03, so raw rung prices sit off the tick
+			ladder, err := buildTakeProfitLadder("BTCUSDT", "LONG", 100.03, 95, 0.01, tt.levels, tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %d rungs", len(ladder.Rungs))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildTakeProfitLadder() error = %v", err)
+			}
+			if len(ladder.Rungs) != len(tt.qty) {
+				t.Fatalf("Expected %d rungs, got %d", len(tt.qty), len(ladder.Rungs))
+			}
+			for i, rung := range ladder.Rungs {
+				if math.Abs(rung.Price-tt.prices[i]) > 1e-9 || math.Abs(rung.Quantity-tt.qty[i]) > 1e-9 {
+					t.Errorf("rung %d: got %.4f @ %.4f, want %.4f @ %.4f", i, rung.Quantity, rung.Price, tt.qty[i], tt.prices[i])
+				}
+				if err := tt.spec.CheckOrder(rung.Quantity, rung.Price); err != nil {
+					t.Errorf("rung %d is not a valid order: %v", i, err)
+				}
+			}
+		})
+	}
+}
+
+// ladderFakeTrader serves position size and rung order status, and records stop-loss changes
+type ladderFakeTrader struct {
+	Trader // Methods the ladder doesn't use are left unimplemented
+
+	positionAmt float64
+	filled      map[string]bool
+	orders      []OpenOrder
+	calls       []string
+	ladderMu    *sync.Mutex // Exchange calls must not run while this is held
+	lockedCalls int
+}
+
+// record logs an exchange call and whether it ran under the ladder lock
+func (f *ladderFakeTrader) record(call string) {
+	f.calls = append(f.calls, call)
+	if f.ladderMu != nil {
+		if !f.ladderMu.TryLock() {
+			f.lockedCalls++
+			return
+		}
+		f.ladderMu.Unlock()
+	}
+}
+
+func (f *ladderFakeTrader) GetPositions() ([]map[string]interface{}, error) {
+	return []map[string]interface{}{{"symbol": "BTCUSDT", "side": "long", "positionAmt": f.positionAmt}}, nil
+}
+
+func (f *ladderFakeTrader) GetOrderStatus(symbol string, orderID string) (map[string]interface{}, error) {
+	f.record("status " + orderID)
+	if f.filled[orderID] {
+		return map[string]interface{}{"status": "FILLED"}, nil
+	}
+	return map[string]interface{}{"status": "NEW"}, nil
+}
+
+func (f *ladderFakeTrader) GetOpenOrders(symbol string) ([]OpenOrder, error) {
+	return f.orders, nil
+}
+
+func (f *ladderFakeTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	f.record(fmt.Sprintf("set %s %.0f", positionSide, stopPrice))
+	return nil
+}
+
+func (f *ladderFakeTrader) CancelOrder(symbol, orderID string) error {
+	f.record("cancel " + orderID)
+	return nil
+}
+
+func TestUpdateTakeProfitLadders(t *testing.T) {
+	ladder, err := buildTakeProfitLadder("BTCUSDT", "LONG", 100, 95, 10, []kernel.TakeProfitLevel{
+		{RMultiple: 1, ClosePct: 40},
+		{RMultiple: 2, ClosePct: 30},
+	}, nil)
+	if err != nil {
+		t.Fatalf("buildTakeProfitLadder() error = %v", err)
+	}
+	ladder.Rungs[0].OrderID = "tp1"
+	ladder.Rungs[1].OrderID = "tp2"
+
+	exchange := &ladderFakeTrader{
+		positionAmt: 10,
+		filled:      map[string]bool{},
+		orders: []OpenOrder{
+			{OrderID: "sl-long", Type: "STOP_MARKET", PositionSide: "LONG", StopPrice: 95},
+			{OrderID: "sl-short", Type: "STOP_MARKET", PositionSide: "SHORT", StopPrice: 120},
+		},
+	}
+	at := &AutoTrader{trader: exchange, tpLadders: map[string]*takeProfitLadder{"BTCUSDT_LONG": ladder}}
+	exchange.ladderMu = &at.tpLaddersMutex
+
+	// Position intact: no status requests
+	at.updateTakeProfitLadders()
+	if len(exchange.calls) != 0 {
+		t.Fatalf("calls = %v, want none while the position is intact", exchange.calls)
+	}
+
+	// Reduced by hand by as much as TP1, but TP1 hasn't filled: the stop stays
+	exchange.positionAmt = 6
+	at.updateTakeProfitLadders()
+	if ladder.Rungs[0].Filled || ladder.StopLoss != 95 {
+		t.Fatalf("A manual reduction must not count as a take-profit fill")
+	}
+
+	// TP1 fills: the long stop moves to break-even and the short side's stop is left alone
+	exchange.filled["tp1"] = true
+	exchange.calls = nil
+	at.updateTakeProfitLadders()
+	if !ladder.Rungs[0].Filled || ladder.Rungs[1].Filled || ladder.StopLoss != 100 {
+		t.Fatalf("Expected TP1 filled and stop at break-even, got stop %.2f", ladder.StopLoss)
+	}
+	want := []string{"status tp1", "status tp2", "set LONG 100", "cancel sl-long"}
+	if strings.Join(exchange.calls, ",") != strings.Join(want, ",") {
+		t.Errorf("calls = %v, want %v", exchange.calls, want)
+	}
+
+	// Position closed: the ladder is dropped
+	exchange.positionAmt = 0
+	at.updateTakeProfitLadders()
+	if len(at.tpLadders) != 0 {
+		t.Errorf("Expected the ladder dropped once the position closed, %d left", len(at.tpLadders))
+	}
+	if exchange.lockedCalls != 0 {
+		t.Errorf("%d exchange calls ran while holding the ladder lock", exchange.lockedCalls)
+	}
+}
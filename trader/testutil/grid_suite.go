@@ -0,0 +1,195 @@
+package testutil
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nofx/trader/types"
+)
+
+// GridMockBook is the order book every exchange mock server serves for GridTraderTestSuite,
+// as [price, size] pairs in base-asset units, best level first
+var (
+	GridMockBids = [][2]string{{"49990", "1.5"}, {"49980", "2"}, {"49970", "3"}}
+	GridMockAsks = [][2]string{{"50010", "1.2"}, {"50020", "2.5"}, {"50030", "4"}}
+)
+
+// GridMockOrderID is the exchange order ID mock servers return for placed limit orders
+const GridMockOrderID = "900001"
+
+// GridOrderCapture is a limit order as received by a mock server, decoded from the
+// exchange's wire format back into the fields the grid relies on
+type GridOrderCapture struct {
+	Side       string // BUY or SELL
+	Price      float64
+	PostOnly   bool
+	ReduceOnly bool
+	ClientID   string // Client ID with any exchange prefix stripped, or as encoded on the wire (see EncodeClientID)
+}
+
+// GridMockRecorder collects the orders and cancellations an exchange mock server receives
+type GridMockRecorder struct {
+	mu      sync.Mutex
+	orders  []GridOrderCapture
+	cancels []string
+}
+
+// RecordOrder stores a decoded limit order
+func (r *GridMockRecorder) RecordOrder(order GridOrderCapture) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orders = append(r.orders, order)
+}
+
+// RecordCancel stores the order ID of a cancel request
+func (r *GridMockRecorder) RecordCancel(orderID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cancels = append(r.cancels, orderID)
+}
+
+// LastOrder returns the most recently recorded limit order
+func (r *GridMockRecorder) LastOrder() (GridOrderCapture, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.orders) == 0 {
+		return GridOrderCapture{}, false
+	}
+	return r.orders[len(r.orders)-1], true
+}
+
+// Cancels returns the order IDs of all recorded cancel requests
+func (r *GridMockRecorder) Cancels() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.cancels...)
+}
+
+// GridTraderTestSuite GridTrader conformance suite
+// Runs the limit-order contract the grid engine relies on against an exchange adapter
+// pointed at a local mock server that decodes requests into Recorder
+//
+// Usage:
+//  1. Start an httptest server that answers the exchange's order, cancel and book endpoints
+//     (serving GridMockBids/GridMockAsks and GridMockOrderID) and records into a GridMockRecorder
+//  2. Point the adapter at the server and call RunAllTests()
+type GridTraderTestSuite struct {
+	T        *testing.T
+	Trader   types.GridTrader
+	Recorder *GridMockRecorder
+	Symbol   string
+	Quantity float64
+
+	// EncodeClientID maps a client ID the way the adapter sends it, for exchanges that can't carry
+	// it verbatim (Hyperliquid cloid hashes, Lighter numeric indexes); nil compares it as is
+	EncodeClientID func(clientID string) string
+}
+
+// NewGridTraderTestSuite creates a conformance suite for BTCUSDT orders of quantity
+func NewGridTraderTestSuite(t *testing.T, trader types.GridTrader, recorder *GridMockRecorder, quantity float64) *GridTraderTestSuite {
+	return &GridTraderTestSuite{
+		T:        t,
+		Trader:   trader,
+		Recorder: recorder,
+		Symbol:   "BTCUSDT",
+		Quantity: quantity,
+	}
+}
+
+// RunAllTests Run all GridTrader conformance tests
+func (s *GridTraderTestSuite) RunAllTests() {
+	s.T.Run("PlaceLimitOrder_PostOnly", func(t *testing.T) { s.TestPlaceLimitOrderPostOnly(t) })
+	s.T.Run("PlaceLimitOrder_ReduceOnly", func(t *testing.T) { s.TestPlaceLimitOrderReduceOnly(t) })
+	s.T.Run("CancelOrder", func(t *testing.T) { s.TestCancelOrder(t) })
+	s.T.Run("GetOrderBook", func(t *testing.T) { s.TestGetOrderBook(t) })
+}
+
+// wireClientID returns the client ID the mock server should have received for clientID
+func (s *GridTraderTestSuite) wireClientID(clientID string) string {
+	if s.EncodeClientID == nil {
+		return clientID
+	}
+	return s.EncodeClientID(clientID)
+}
+
+// TestPlaceLimitOrderPostOnly checks a post-only buy reaches the exchange as a maker-only
+// order carrying the grid's client ID
+func (s *GridTraderTestSuite) TestPlaceLimitOrderPostOnly(t *testing.T) {
+	req := &types.LimitOrderRequest{
+		Symbol:   s.Symbol,
+		Side:     "BUY",
+		Price:    49900,
+		Quantity: s.Quantity,
+		PostOnly: true,
+		ClientID: "nxgridpostonly0001",
+	}
+
+	result, err := s.Trader.PlaceLimitOrder(req)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, GridMockOrderID, result.OrderID)
+	assert.Equal(t, "BUY", result.Side)
+	assert.InDelta(t, 49900, result.Price, 1e-6)
+
+	order, ok := s.Recorder.LastOrder()
+	require.True(t, ok, "mock server received no order")
+	assert.Equal(t, "BUY", order.Side)
+	assert.InDelta(t, 49900, order.Price, 1e-6)
+	assert.True(t, order.PostOnly, "order should be post-only")
+	assert.False(t, order.ReduceOnly, "order should not be reduce-only")
+	assert.Equal(t, s.wireClientID(req.ClientID), order.ClientID)
+}
+
+// TestPlaceLimitOrderReduceOnly checks a reduce-only sell is flagged so it can only close inventory
+func (s *GridTraderTestSuite) TestPlaceLimitOrderReduceOnly(t *testing.T) {
+	req := &types.LimitOrderRequest{
+		Symbol:       s.Symbol,
+		Side:         "SELL",
+		PositionSide: "LONG",
+		Price:        50100,
+		Quantity:     s.Quantity,
+		ReduceOnly:   true,
+		ClientID:     "nxgridreduceonly01",
+	}
+
+	result, err := s.Trader.PlaceLimitOrder(req)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, GridMockOrderID, result.OrderID)
+
+	order, ok := s.Recorder.LastOrder()
+	require.True(t, ok, "mock server received no order")
+	assert.Equal(t, "SELL", order.Side)
+	assert.InDelta(t, 50100, order.Price, 1e-6)
+	assert.True(t, order.ReduceOnly, "order should be reduce-only")
+	assert.False(t, order.PostOnly, "order should not be post-only")
+	assert.Equal(t, s.wireClientID(req.ClientID), order.ClientID)
+}
+
+// TestCancelOrder checks CancelOrder cancels exactly the requested order
+func (s *GridTraderTestSuite) TestCancelOrder(t *testing.T) {
+	require.NoError(t, s.Trader.CancelOrder(s.Symbol, GridMockOrderID))
+	assert.Contains(t, s.Recorder.Cancels(), GridMockOrderID)
+}
+
+// TestGetOrderBook checks the book comes back best level first, uncrossed and in base units
+func (s *GridTraderTestSuite) TestGetOrderBook(t *testing.T) {
+	bids, asks, err := s.Trader.GetOrderBook(s.Symbol, 5)
+	require.NoError(t, err)
+	require.Len(t, bids, len(GridMockBids))
+	require.Len(t, asks, len(GridMockAsks))
+
+	for i := 1; i < len(bids); i++ {
+		assert.Greater(t, bids[i-1][0], bids[i][0], "bids should be sorted descending")
+	}
+	for i := 1; i < len(asks); i++ {
+		assert.Less(t, asks[i-1][0], asks[i][0], "asks should be sorted ascending")
+	}
+	assert.Less(t, bids[0][0], asks[0][0], "best bid should be below best ask")
+	assert.InDelta(t, 49990, bids[0][0], 1e-6)
+	assert.InDelta(t, 1.5, bids[0][1], 1e-6)
+	assert.InDelta(t, 50010, asks[0][0], 1e-6)
+	assert.InDelta(t, 1.2, asks[0][1], 1e-6)
+}
@@ -0,0 +1,304 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// Trailing Stops
+// Native trailing orders are used where the exchange supports them; elsewhere the
+// stop-loss is ratcheted in software (new stop placed, then the side's old stop cancelled) as price moves
+// =============================================================================
+
+const (
+	// trailingStopCheckInterval how often emulated trailing stops and take-profit ladders are re-evaluated
+	trailingStopCheckInterval = 15 * time.Second
+	// trailingStopMinStepPct minimum stop improvement (percent) before the stop order is replaced
+	// Keeps API calls down when price is grinding by a few ticks
+	trailingStopMinStepPct = 0.1
+)
+
+// trailingStopState software-emulated trailing stop for one position
+// Kept in memory only - emulated stops are lost on restart, the last placed stop-loss stays on the exchange
+type trailingStopState struct {
+	Symbol          string
+	Side            string // LONG or SHORT
+	CallbackRate    float64
+	ActivationPrice float64
+	Activated       bool
+	BestPrice       float64 // Highest price since activation (LONG) or lowest (SHORT)
+	StopPrice       float64 // Stop-loss currently placed on the exchange (0 = none)
+}
+
+// update feeds the latest price and returns the new stop price if the stop should move
+func (s *trailingStopState) update(price float64) (float64, bool) {
+	if price <= 0 {
+		return 0, false
+	}
+
+	if !s.Activated {
+		switch {
+		case s.ActivationPrice <= 0,
+			s.Side == "LONG" && price >= s.ActivationPrice,
+			s.Side == "SHORT" && price <= s.ActivationPrice:
+			s.Activated = true
+			s.BestPrice = price
+		default:
+			return 0, false
+		}
+	}
+
+	var candidate float64
+	if s.Side == "LONG" {
+		s.BestPrice = math.Max(s.BestPrice, price)
+		candidate = s.BestPrice * (1 - s.CallbackRate/100)
+		if s.StopPrice > 0 && candidate <= s.StopPrice*(1+trailingStopMinStepPct/100) {
+			return 0, false
+		}
+	} else {
+		s.BestPrice = math.Min(s.BestPrice, price)
+		candidate = s.BestPrice * (1 + s.CallbackRate/100)
+		if s.StopPrice > 0 && candidate >= s.StopPrice*(1-trailingStopMinStepPct/100) {
+			return 0, false
+		}
+	}
+	return candidate, true
+}
+
+// setTrailingStop places a trailing stop for a position, emulating it in software if the exchange has no native support
+// initialStop is the stop-loss already on the exchange (0 if none); the emulated stop never moves below it
+func (at *AutoTrader) setTrailingStop(symbol, side string, quantity, callbackRate, activationPrice, initialStop float64) error {
+	err := at.trader.SetTrailingStop(symbol, side, quantity, callbackRate, activationPrice)
+	if err == nil {
+		at.removeTrailingStop(symbol, side) // Native order now owns the trailing
+		return nil
+	}
+	if !errors.Is(err, ErrTrailingStopNotSupported) {
+		return err
+	}
+
+	at.trailingStopsMutex.Lock()
+	at.trailingStops[symbol+"_"+side] = &trailingStopState{
+		Symbol:          symbol,
+		Side:            side,
+		CallbackRate:    callbackRate,
+		ActivationPrice: activationPrice,
+		StopPrice:       initialStop,
+	}
+	at.trailingStopsMutex.Unlock()
+
+	logger.Infof("  🪜 Trailing stop emulated in software: %s %s callback %.2f%%, activation %.4f",
+		symbol, side, callbackRate, activationPrice)
+	return nil
+}
+
+// removeTrailingStop stops emulating the trailing stop for a position
+func (at *AutoTrader) removeTrailingStop(symbol, side string) {
+	at.trailingStopsMutex.Lock()
+	delete(at.trailingStops, symbol+"_"+side)
+	at.trailingStopsMutex.Unlock()
+}
+
+// startExitMonitor starts the loop that follows take-profit ladders and ratchets software trailing stops
+func (at *AutoTrader) startExitMonitor() {
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+
+		ticker := time.NewTicker(trailingStopCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				at.updateTakeProfitLadders()
+				at.updateTrailingStops()
+			case <-at.stopMonitorCh:
+				return
+			}
+		}
+	}()
+}
+
+// updateTrailingStops ratchets emulated trailing stops towards the current price
+// The mutex only guards the map: states are snapshotted first and exchange calls run unlocked. Only this loop
+// mutates an existing state; setTrailingStop replaces the entry with a fresh one instead
+func (at *AutoTrader) updateTrailingStops() {
+	at.trailingStopsMutex.Lock()
+	states := make(map[string]*trailingStopState, len(at.trailingStops))
+	for key, state := range at.trailingStops {
+		states[key] = state
+	}
+	at.trailingStopsMutex.Unlock()
+
+	if len(states) == 0 {
+		return
+	}
+
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		logger.Infof("⚠️ Trailing stop: failed to get positions: %v", err)
+		return
+	}
+	quantities := make(map[string]float64)
+	prices := make(map[string]float64)
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		qty, _ := pos["positionAmt"].(float64)
+		key := symbol + "_" + strings.ToUpper(side)
+		quantities[key] = math.Abs(qty)
+		if markPrice, ok := pos["markPrice"].(float64); ok {
+			prices[key] = markPrice
+		}
+	}
+
+	for key, state := range states {
+		quantity := quantities[key]
+		if quantity == 0 {
+			// Position closed (stop hit, manual close, etc.)
+			at.trailingStopsMutex.Lock()
+			if at.trailingStops[key] == state {
+				delete(at.trailingStops, key)
+			}
+			at.trailingStopsMutex.Unlock()
+			continue
+		}
+
+		price := prices[key]
+		if price <= 0 {
+			if price, err = at.trader.GetMarketPrice(state.Symbol); err != nil {
+				continue
+			}
+		}
+
+		newStop, moved := state.update(price)
+		if !moved {
+			continue
+		}
+
+		if err := at.replaceStopLoss(state.Symbol, state.Side, quantity, newStop); err != nil {
+			// Leave StopPrice unchanged so the next tick retries
+			logger.Infof("⚠️ Trailing stop: failed to move %s %s stop to %.4f: %v", state.Symbol, state.Side, newStop, err)
+			continue
+		}
+		logger.Infof("🪜 Trailing stop moved: %s %s %.4f → %.4f (best price %.4f)",
+			state.Symbol, state.Side, state.StopPrice, newStop, state.BestPrice)
+		state.StopPrice = newStop
+	}
+}
+
+// orderCanceler is implemented by traders that can cancel a single order by ID
+type orderCanceler interface {
+	CancelOrder(symbol, orderID string) error
+}
+
+// replaceStopLoss moves the stop-loss of one position side to stopPrice
+// The new stop is placed before the old one is cancelled, so the position is never unprotected, and only
+// stops of that side are cancelled - the opposite side's stop in hedge mode stays in place
+func (at *AutoTrader) replaceStopLoss(symbol, side string, quantity, stopPrice float64) error {
+	canceler, ok := at.trader.(orderCanceler)
+	if !ok {
+		// No per-order cancel: fall back to replacing every stop-loss of the symbol
+		if err := at.trader.CancelStopLossOrders(symbol); err != nil {
+			logger.Infof("⚠️ Failed to cancel old stop-loss for %s: %v", symbol, err)
+		}
+		return at.trader.SetStopLoss(symbol, side, quantity, stopPrice)
+	}
+
+	previous, err := at.stopLossOrders(symbol, side)
+	if err != nil {
+		return fmt.Errorf("failed to list stop-loss orders: %w", err)
+	}
+	if err := at.trader.SetStopLoss(symbol, side, quantity, stopPrice); err != nil {
+		return err
+	}
+	for _, order := range previous {
+		// Exchanges that amend the position stop in place keep the order ID; don't cancel the new stop
+		if math.Abs(order.StopPrice-stopPrice) <= stopPrice*1e-9 {
+			continue
+		}
+		if err := canceler.CancelOrder(symbol, order.OrderID); err != nil {
+			logger.Infof("⚠️ Failed to cancel old stop-loss %s for %s %s: %v", order.OrderID, symbol, side, err)
+		}
+	}
+	return nil
+}
+
+// stopLossOrders returns the open stop-loss orders protecting one side of a position
+func (at *AutoTrader) stopLossOrders(symbol, side string) ([]OpenOrder, error) {
+	orders, err := at.trader.GetOpenOrders(symbol)
+	if err != nil {
+		return nil, err
+	}
+	var stops []OpenOrder
+	for _, order := range orders {
+		orderType := strings.ToUpper(order.Type)
+		if !strings.Contains(orderType, "STOP") || strings.Contains(orderType, "TAKE_PROFIT") || order.StopPrice <= 0 {
+			continue
+		}
+		if order.PositionSide != "" && !strings.EqualFold(order.PositionSide, side) && !strings.EqualFold(order.PositionSide, "BOTH") {
+			continue
+		}
+		stops = append(stops, order)
+	}
+	return stops, nil
+}
+
+// executeSetTrailingStopWithRecord attaches a trailing stop to an existing position
+func (at *AutoTrader) executeSetTrailingStopWithRecord(decision *kernel.Decision, actionRecord *store.DecisionAction) error {
+	logger.Infof("  🪜 Set trailing stop: %s (callback %.2f%%)", decision.Symbol, decision.TrailingCallbackRate)
+
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	found := false
+	for _, pos := range positions {
+		if pos["symbol"] != decision.Symbol {
+			continue
+		}
+		side, _ := pos["side"].(string)
+		qty, _ := pos["positionAmt"].(float64)
+		if qty == 0 {
+			continue
+		}
+		found = true
+		qty = math.Abs(qty)
+		side = strings.ToUpper(side)
+		if err := at.setTrailingStop(decision.Symbol, side, qty,
+			decision.TrailingCallbackRate, decision.TrailingActivationPrice, at.currentStopLoss(decision.Symbol, side)); err != nil {
+			return err
+		}
+		actionRecord.Quantity += qty
+	}
+	if !found {
+		return fmt.Errorf("no open position for %s", decision.Symbol)
+	}
+	return nil
+}
+
+// currentStopLoss returns the trigger price of the stop-loss currently protecting a position (0 if none)
+// Used as the floor for emulated trailing stops so attaching one never loosens an existing stop
+func (at *AutoTrader) currentStopLoss(symbol, side string) float64 {
+	orders, err := at.stopLossOrders(symbol, side)
+	if err != nil {
+		return 0
+	}
+	var stop float64
+	for _, order := range orders {
+		// Keep the tightest stop
+		if stop == 0 || (side == "LONG" && order.StopPrice > stop) || (side == "SHORT" && order.StopPrice < stop) {
+			stop = order.StopPrice
+		}
+	}
+	return stop
+}
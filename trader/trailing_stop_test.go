@@ -0,0 +1,118 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTrailingStopStateUpdate(t *testing.T) {
+	type step struct {
+		price    float64
+		wantMove bool
+		wantStop float64
+	}
+	tests := []struct {
+		name  string
+		state trailingStopState
+		steps []step
+	}{
+		{
+			name:  "long trails highs and never moves down",
+			state: trailingStopState{Side: "LONG", CallbackRate: 2},
+			steps: []step{
+				{price: 100, wantMove: true, wantStop: 98},
+				{price: 105, wantMove: true, wantStop: 102.9},
+				{price: 101, wantMove: false},
+				{price: 105.05, wantMove: false}, // Below minimum step
+				{price: 110, wantMove: true, wantStop: 107.8},
+			},
+		},
+		{
+			name:  "short trails lows",
+			state: trailingStopState{Side: "SHORT", CallbackRate: 1},
+			steps: []step{
+				{price: 200, wantMove: true, wantStop: 202},
+				{price: 210, wantMove: false},
+				{price: 190, wantMove: true, wantStop: 191.9},
+			},
+		},
+		{
+			name:  "waits for activation price",
+			state: trailingStopState{Side: "LONG", CallbackRate: 1, ActivationPrice: 120},
+			steps: []step{
+				{price: 110, wantMove: false},
+				{price: 119.9, wantMove: false},
+				{price: 120, wantMove: true, wantStop: 118.8},
+			},
+		},
+		{
+			name:  "does not loosen the initial stop",
+			state: trailingStopState{Side: "LONG", CallbackRate: 5, StopPrice: 97},
+			steps: []step{
+				{price: 100, wantMove: false}, // 95 would be looser than 97
+				{price: 104, wantMove: true, wantStop: 98.8},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state
+			for i, s := range tt.steps {
+				stop, moved := state.update(s.price)
+				if moved != s.wantMove {
+					t.Fatalf("step %d (price %.2f): expected moved=%v, got %v", i, s.price, s.wantMove, moved)
+				}
+				if !moved {
+					continue
+				}
+				if math.Abs(stop-s.wantStop) > 1e-9 {
+					t.Errorf("step %d (price %.2f): expected stop %.4f, got %.4f", i, s.price, s.wantStop, stop)
+				}
+				state.StopPrice = stop
+			}
+		})
+	}
+}
+
+// stopOrderFakeTrader records stop-loss placements and cancels in call order
+type stopOrderFakeTrader struct {
+	Trader // Methods replaceStopLoss doesn't use are left unimplemented
+
+	orders []OpenOrder
+	calls  []string
+}
+
+func (f *stopOrderFakeTrader) GetOpenOrders(symbol string) ([]OpenOrder, error) {
+	return f.orders, nil
+}
+
+func (f *stopOrderFakeTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	f.calls = append(f.calls, fmt.Sprintf("set %s %.0f", positionSide, stopPrice))
+	return nil
+}
+
+func (f *stopOrderFakeTrader) CancelOrder(symbol, orderID string) error {
+	f.calls = append(f.calls, "cancel "+orderID)
+	return nil
+}
+
+func TestReplaceStopLoss(t *testing.T) {
+	exchange := &stopOrderFakeTrader{orders: []OpenOrder{
+		{OrderID: "1", Type: "STOP_MARKET", PositionSide: "LONG", StopPrice: 95},
+		{OrderID: "2", Type: "STOP_MARKET", PositionSide: "SHORT", StopPrice: 110},
+		{OrderID: "3", Type: "TAKE_PROFIT_MARKET", PositionSide: "LONG", StopPrice: 120},
+	}}
+	at := &AutoTrader{trader: exchange}
+
+	if err := at.replaceStopLoss("BTCUSDT", "LONG", 1, 98); err != nil {
+		t.Fatalf("replaceStopLoss: %v", err)
+	}
+	// New stop first, then only the long side's old stop-loss
+	want := []string{"set LONG 98", "cancel 1"}
+	if strings.Join(exchange.calls, ",") != strings.Join(want, ",") {
+		t.Errorf("calls = %v, want %v", exchange.calls, want)
+	}
+}
@@ -1,11 +1,20 @@
 package types
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"nofx/logger"
 	"time"
 )
 
+// ErrTrailingStopNotSupported is returned by SetTrailingStop on exchanges without native trailing orders
+// Callers should fall back to software emulation (ratcheting a regular stop-loss)
+var ErrTrailingStopNotSupported = errors.New("native trailing stop not supported")
+
+// ErrLimitOrdersNotSupported is returned by GridTraderAdapter for traders without a native GridTrader implementation
+var ErrLimitOrdersNotSupported = errors.New("native limit orders not supported")
+
 // ClosedPnLRecord represents a single closed position record from exchange
 type ClosedPnLRecord struct {
 	Symbol       string    // Trading pair (e.g., "BTCUSDT")
@@ -27,6 +36,7 @@ type ClosedPnLRecord struct {
 // Used for reconstructing position history with unified algorithm
 type TradeRecord struct {
 	TradeID      string    // Unique trade ID from exchange
+	OrderID      string    // Exchange order ID the trade filled
 	Symbol       string    // Trading pair (e.g., "BTCUSDT")
 	Side         string    // "BUY" or "SELL"
 	PositionSide string    // "LONG", "SHORT", or "BOTH" (for one-way mode)
@@ -74,6 +84,12 @@ type Trader interface {
 	// SetTakeProfit Set take-profit order
 	SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error
 
+	// SetTrailingStop Set trailing stop order
+	// callbackRate: distance from the best price in percent (e.g. 1.5 = 1.5%)
+	// activationPrice: price at which trailing starts (0 = start immediately from current price)
+	// Returns ErrTrailingStopNotSupported if the exchange has no native trailing orders
+	SetTrailingStop(symbol string, positionSide string, quantity, callbackRate, activationPrice float64) error
+
 	// CancelStopLossOrders Cancel only stop-loss orders (BUG fix: don't delete take-profit when adjusting stop-loss)
 	CancelStopLossOrders(symbol string) error
 
@@ -104,6 +120,126 @@ type Trader interface {
 	GetOpenOrders(symbol string) ([]OpenOrder, error)
 }
 
+// ClientOrderTrader is implemented by traders whose market orders accept a caller-supplied client order ID
+// The same client ID always maps to the same exchange order, so a timed-out request can be
+// looked up with GetOrderByClientID instead of being blindly resubmitted
+type ClientOrderTrader interface {
+	// OpenLongWithClientID Open long position tagged with clientOrderID
+	OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error)
+
+	// OpenShortWithClientID Open short position tagged with clientOrderID
+	OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (map[string]interface{}, error)
+
+	// CloseLongWithClientID Close long position tagged with clientOrderID (quantity=0 means close all)
+	CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error)
+
+	// CloseShortWithClientID Close short position tagged with clientOrderID (quantity=0 means close all)
+	CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (map[string]interface{}, error)
+
+	// GetOrderByClientID Get order by client order ID
+	// Returns nil, nil if the exchange has no order with this client ID
+	GetOrderByClientID(symbol, clientOrderID string) (map[string]interface{}, error)
+}
+
+// LeverageBracket one notional tier of an exchange's leverage schedule
+// Positions up to NotionalCap may use at most MaxLeverage
+type LeverageBracket struct {
+	NotionalCap     float64 `json:"notional_cap"`      // Upper notional bound of this tier (USDT)
+	MaxLeverage     int     `json:"max_leverage"`      // Max leverage allowed inside this tier
+	MaintMarginRate float64 `json:"maint_margin_rate"` // Maintenance margin rate (e.g. 0.004 = 0.4%)
+}
+
+// ContractSpec instrument trading rules, normalized across exchanges
+// Quantities are expressed in base-asset units (not exchange contracts); zero means unknown/unrestricted
+type ContractSpec struct {
+	Symbol             string            `json:"symbol"`
+	Exchange           string            `json:"exchange"`
+	TickSize           float64           `json:"tick_size"`           // Minimum price increment
+	QtyStep            float64           `json:"qty_step"`            // Quantity increment (base asset)
+	MinQty             float64           `json:"min_qty"`             // Minimum order quantity (base asset)
+	MinNotional        float64           `json:"min_notional"`        // Minimum order value (USDT)
+	MaxLeverage        int               `json:"max_leverage"`        // Max leverage for the smallest position
+	LeverageBrackets   []LeverageBracket `json:"leverage_brackets"`   // Sorted by NotionalCap ascending
+	ContractMultiplier float64           `json:"contract_multiplier"` // Base asset per exchange contract (1 for linear USDT pairs)
+	UpdatedAt          time.Time         `json:"updated_at"`
+}
+
+// RoundPrice rounds price to the nearest tick
+func (s *ContractSpec) RoundPrice(price float64) float64 {
+	if s == nil || s.TickSize <= 0 {
+		return price
+	}
+	return roundStep(math.Round(price/s.TickSize)*s.TickSize, s.TickSize)
+}
+
+// FloorQty rounds quantity down to the lot step so an order never exceeds the sized amount
+func (s *ContractSpec) FloorQty(quantity float64) float64 {
+	if s == nil || s.QtyStep <= 0 {
+		return quantity
+	}
+	// Small epsilon so 0.3/0.1 doesn't floor to 2
+	return roundStep(math.Floor(quantity/s.QtyStep+1e-9)*s.QtyStep, s.QtyStep)
+}
+
+// MinOrderValue returns the smallest order value (USDT) accepted at price
+func (s *ContractSpec) MinOrderValue(price float64) float64 {
+	if s == nil {
+		return 0
+	}
+	return math.Max(s.MinNotional, s.MinQty*price)
+}
+
+// MaxLeverageFor returns the max leverage allowed for a position of the given notional
+// Returns 0 if the exchange didn't report a leverage cap
+func (s *ContractSpec) MaxLeverageFor(notional float64) int {
+	if s == nil {
+		return 0
+	}
+	for _, b := range s.LeverageBrackets {
+		if notional <= b.NotionalCap {
+			return b.MaxLeverage
+		}
+	}
+	if n := len(s.LeverageBrackets); n > 0 {
+		return s.LeverageBrackets[n-1].MaxLeverage
+	}
+	return s.MaxLeverage
+}
+
+// CheckOrder validates quantity and price against the spec's minimums
+func (s *ContractSpec) CheckOrder(quantity, price float64) error {
+	if s == nil {
+		return nil
+	}
+	if quantity <= 0 {
+		return fmt.Errorf("%s quantity %.8f rounds to zero (step %g)", s.Symbol, quantity, s.QtyStep)
+	}
+	if s.MinQty > 0 && quantity < s.MinQty {
+		return fmt.Errorf("%s quantity %.8f below exchange minimum %g", s.Symbol, quantity, s.MinQty)
+	}
+	if s.MinNotional > 0 && quantity*price < s.MinNotional {
+		return fmt.Errorf("%s order value %.2f USDT below exchange minimum %.2f USDT", s.Symbol, quantity*price, s.MinNotional)
+	}
+	return nil
+}
+
+// roundStep strips floating-point noise left by step arithmetic (e.g. 0.30000000000000004)
+func roundStep(value, step float64) float64 {
+	decimals := 0
+	for step < 1 && decimals < 12 {
+		step *= 10
+		decimals++
+	}
+	pow := math.Pow(10, float64(decimals+2))
+	return math.Round(value*pow) / pow
+}
+
+// ContractSpecProvider is implemented by traders that can report instrument trading rules
+type ContractSpecProvider interface {
+	// GetContractSpec Get tick size, lot step, minimums and leverage caps for symbol
+	GetContractSpec(symbol string) (*ContractSpec, error)
+}
+
 // OpenOrder represents a pending order on the exchange
 type OpenOrder struct {
 	OrderID      string  `json:"order_id"`
@@ -115,6 +251,10 @@ type OpenOrder struct {
 	StopPrice    float64 `json:"stop_price"`    // Trigger price (for stop orders)
 	Quantity     float64 `json:"quantity"`
 	Status       string  `json:"status"` // NEW
+
+	// ClientOrderID is the client order ID the order was placed with, exchange prefixes stripped
+	// Empty where the exchange only keeps a hash of it (Hyperliquid cloid, Lighter client index)
+	ClientOrderID string `json:"client_order_id,omitempty"`
 }
 
 // LimitOrderRequest represents a limit order request for grid trading
@@ -160,7 +300,8 @@ type GridTrader interface {
 }
 
 // GridTraderAdapter wraps a basic Trader to provide GridTrader interface
-// Uses stop orders as a fallback when limit orders aren't directly available
+// Every supported exchange implements GridTrader natively; the adapter only keeps
+// cancellation working for other traders and refuses to place grid orders
 type GridTraderAdapter struct {
 	Trader
 }
@@ -170,39 +311,10 @@ func NewGridTraderAdapter(t Trader) *GridTraderAdapter {
 	return &GridTraderAdapter{Trader: t}
 }
 
-// PlaceLimitOrder implements limit order using available methods
-// For exchanges without native limit order support, this uses conditional orders
+// PlaceLimitOrder always fails: emulating resting limit orders with stop orders fills
+// on the wrong side of the book, so grids need a native GridTrader
 func (a *GridTraderAdapter) PlaceLimitOrder(req *LimitOrderRequest) (*LimitOrderResult, error) {
-	// CRITICAL FIX: Set leverage before placing order
-	if req.Leverage > 0 {
-		if err := a.Trader.SetLeverage(req.Symbol, req.Leverage); err != nil {
-			logger.Warnf("[Grid] Failed to set leverage %dx: %v", req.Leverage, err)
-			// Continue anyway - some exchanges don't require explicit leverage setting
-		}
-	}
-
-	// Use SetStopLoss/SetTakeProfit as conditional limit orders
-	// For buy orders below current price, use stop-loss mechanism
-	// For sell orders above current price, use take-profit mechanism
-	var err error
-	if req.Side == "BUY" {
-		err = a.Trader.SetStopLoss(req.Symbol, "SHORT", req.Quantity, req.Price)
-	} else {
-		err = a.Trader.SetTakeProfit(req.Symbol, "LONG", req.Quantity, req.Price)
-	}
-	if err != nil {
-		return nil, err
-	}
-	return &LimitOrderResult{
-		OrderID:      req.ClientID,
-		ClientID:     req.ClientID,
-		Symbol:       req.Symbol,
-		Side:         req.Side,
-		PositionSide: req.PositionSide,
-		Price:        req.Price,
-		Quantity:     req.Quantity,
-		Status:       "NEW",
-	}, nil
+	return nil, fmt.Errorf("%w: cannot place %s %s @ %.4f", ErrLimitOrdersNotSupported, req.Symbol, req.Side, req.Price)
 }
 
 // CancelOrder cancels a specific order
@@ -11,8 +11,11 @@ export interface SystemStatus {
   stop_until: string
   last_reset_time: string
   ai_provider: string
-  strategy_type?: 'ai_trading' | 'grid_trading'
+  strategy_type?: 'ai_trading' | 'grid_trading' | 'dca' | 'funding_arb' | 'pairs'
   grid_symbol?: string
+  dca_symbol?: string
+  funding_arb_symbols?: string[]
+  pairs_symbols?: string[]
 }
 
 export interface AccountInfo {
@@ -321,6 +324,7 @@ export interface BacktestEquityPoint {
   pnl_pct: number;
   dd_pct: number;
   cycle: number;
+  benchmarks?: Record<string, number>;
 }
 
 export interface BacktestTradeEvent {
@@ -364,6 +368,54 @@ export interface BacktestMetrics {
       win_rate: number;
     }
   >;
+  sortino_ratio?: number;
+  calmar_ratio?: number;
+  expectancy?: number;
+  avg_holding_mins?: number;
+  max_holding_mins?: number;
+  time_in_market_pct?: number;
+  max_consecutive_losses?: number;
+  total_fees?: number;
+  total_slippage?: number;
+  fee_drag_pct?: number;
+  slippage_drag_pct?: number;
+  direction_stats?: Record<
+    string,
+    {
+      trades: number;
+      win_rate: number;
+      total_pnl: number;
+      avg_pnl: number;
+    }
+  >;
+  monthly_returns?: Array<{
+    month: string;
+    return_pct: number;
+    pnl: number;
+    end_equity: number;
+  }>;
+  benchmarks?: Record<
+    string,
+    {
+      return_pct: number;
+      excess_return_pct: number;
+      max_drawdown_pct: number;
+      alpha: number;
+      beta: number;
+      correlation: number;
+    }
+  >;
+  live_replay?: {
+    live_cycles: number;
+    replayed: number;
+    skipped_low_confidence: number;
+    skipped_failed_live: number;
+    stop_loss_hits: number;
+    take_profit_hits: number;
+    fill_gap_samples: number;
+    avg_fill_gap_bps: number;
+    max_fill_gap_bps: number;
+  };
 }
 
 export interface BacktestStartConfig {
@@ -371,6 +423,7 @@ export interface BacktestStartConfig {
   ai_model_id?: string;
   strategy_id?: string; // Optional: use saved strategy from Strategy Studio
   symbols: string[];
+  asset_class?: 'crypto' | 'stock' | 'forex' | 'metal';
   timeframes: string[];
   decision_timeframe: string;
   decision_cadence_nbars: number;
@@ -380,6 +433,12 @@ export interface BacktestStartConfig {
   fee_bps: number;
   slippage_bps: number;
   fill_policy: string;
+  fill_model?: {
+    max_volume_pct?: number;
+    impact_factor?: number;
+    latency_seconds?: number;
+    max_queue_bars?: number;
+  };
   prompt_variant?: string;
   prompt_template?: string;
   custom_prompt?: string;
@@ -401,6 +460,28 @@ export interface BacktestStartConfig {
     btc_eth_leverage?: number;
     altcoin_leverage?: number;
   };
+  benchmarks?: Array<'btc_hold' | 'equal_weight' | 'ema_cross'>;
+  margin?: {
+    mode?: 'isolated' | 'cross';
+    brackets?: Record<
+      string,
+      Array<{
+        notional_cap: number;
+        max_leverage: number;
+        maint_margin_rate: number;
+      }>
+    >;
+    spec_trader_id?: string;
+    margin_call_ratio?: number;
+  };
+  live_replay?: {
+    trader_id: string;
+    min_confidence?: number;
+    stop_loss_pct?: number;
+    take_profit_pct?: number;
+    ignore_ai_stops?: boolean;
+    include_failed?: boolean;
+  };
 }
 
 // Kline data for backtest chart
@@ -464,8 +545,8 @@ export interface PromptSectionsConfig {
 }
 
 export interface StrategyConfig {
-  // Strategy type: "ai_trading" (default) or "grid_trading"
-  strategy_type?: 'ai_trading' | 'grid_trading';
+  // Strategy type: "ai_trading" (default), "grid_trading", "dca", "funding_arb" or "pairs"
+  strategy_type?: 'ai_trading' | 'grid_trading' | 'dca' | 'funding_arb' | 'pairs';
   // Language setting: "zh" for Chinese, "en" for English
   // Determines the language used for data formatting and prompt generation
   language?: 'zh' | 'en';
@@ -476,6 +557,26 @@ export interface StrategyConfig {
   prompt_sections?: PromptSectionsConfig;
   // Grid trading configuration (only used when strategy_type is 'grid_trading')
   grid_config?: GridStrategyConfig;
+  // DCA bot configuration (only used when strategy_type is 'dca')
+  dca_config?: DCAStrategyConfig;
+  // Funding arbitrage configuration (only used when strategy_type is 'funding_arb')
+  funding_arb_config?: FundingArbStrategyConfig;
+  // Pairs / statistical arbitrage configuration (only used when strategy_type is 'pairs')
+  pairs_config?: PairsStrategyConfig;
+  // Execution algorithm for large orders (omit = single market orders)
+  execution?: ExecutionConfig;
+}
+
+// How large open/close orders are worked
+export interface ExecutionConfig {
+  algorithm: 'market' | 'twap' | 'iceberg' | 'pov';
+  // Orders below this notional (USDT) always go out at market
+  min_notional_usd: number;
+  duration_seconds: number;
+  slices?: number;
+  clip_usd?: number;
+  participation_pct?: number;
+  allow_ai_override?: boolean;
 }
 
 // Grid trading specific configuration
@@ -510,6 +611,114 @@ export interface GridStrategyConfig {
   enable_direction_adjust?: boolean;
   // Direction bias ratio for long_bias/short_bias modes (default 0.7 = 70%/30%)
   direction_bias_ratio?: number;
+  // Execution mode: "ai" (default) | "mechanical" (rule-based, no model call per cycle)
+  execution_mode?: 'ai' | 'mechanical';
+  // Mechanical mode only: AI review of bounds/pause state every N cycles (0 = never)
+  ai_review_interval_cycles?: number;
+  // "" (pause on breakout) | "trailing" (re-centre either way) | "infinity" (trail up, keep base inventory)
+  trailing_mode?: '' | 'trailing' | 'infinity';
+  // Levels beyond the range before the grid trails (default 1)
+  trailing_trigger_levels?: number;
+  // Multi-symbol basket sharing total_investment and the drawdown/daily loss limits (empty = single symbol)
+  basket?: GridBasketSymbol[];
+}
+
+export interface GridBasketSymbol {
+  symbol: string;
+  weight?: number;       // share of total_investment (0 everywhere = equal split)
+  upper_price?: number;  // manual bounds, ignored with use_atr_bounds
+  lower_price?: number;
+}
+
+// DCA / safety-order bot configuration
+export interface DCAStrategyConfig {
+  symbol: string;
+  direction?: 'long' | 'short';
+  leverage: number;
+  // Base order notional in USDT
+  base_order_usd: number;
+  // First safety order notional in USDT
+  safety_order_usd: number;
+  max_safety_orders: number;
+  // Deviation of the first safety order from the base entry (%)
+  price_deviation_pct: number;
+  // Multiplier for each subsequent deviation step / order size (default 1.0)
+  step_scale?: number;
+  volume_scale?: number;
+  // Take-profit distance from the average entry (%)
+  take_profit_pct: number;
+  use_maker_only: boolean;
+  // Ask the AI before starting each deal
+  ai_gating?: boolean;
+  cooldown_minutes?: number;
+  // Stop after this many completed deals (0 = unlimited)
+  max_deals?: number;
+}
+
+// One DCA deal as returned by GET /traders/:id/dca-deals
+export interface DCADeal {
+  id: number;
+  symbol: string;
+  side: 'LONG' | 'SHORT';
+  status: 'active' | 'completed' | 'cancelled';
+  base_price: number;
+  avg_entry_price: number;
+  quantity: number;
+  safety_orders_filled: number;
+  take_profit_price: number;
+  exit_price: number;
+  realized_pnl: number;
+  start_reason: string;
+  started_at: number; // Unix milliseconds
+  closed_at: number;
+}
+
+export interface DCADealsResponse {
+  deals: DCADeal[];
+  closed_deals: number;
+  total_realized_pnl: number;
+}
+
+// Cross-exchange funding-rate arbitrage configuration
+export interface FundingArbStrategyConfig {
+  symbols: string[];
+  // Exchange account IDs to trade across (empty = every enabled account)
+  exchange_ids?: string[];
+  // Notional per leg in USDT
+  notional_usd: number;
+  leverage: number;
+  max_pairs: number;
+  // Open above / unwind below this annualised funding spread (%)
+  min_spread_apr: number;
+  exit_spread_apr: number;
+  // Unwind when leg notionals drift apart by more than this (%)
+  max_delta_drift_pct?: number;
+  // Unwind when a leg's mark price is this close to liquidation (%)
+  min_liquidation_distance_pct?: number;
+}
+
+// Pairs / statistical arbitrage configuration
+export interface PairsStrategyConfig {
+  symbol_a: string;
+  // Hedge leg, sized by the rolling hedge ratio
+  symbol_b: string;
+  timeframe?: string;
+  lookback_bars?: number;
+  // Z-score thresholds: enter at ±entry_z, exit within ±exit_z, stop at ±stop_z
+  entry_z?: number;
+  exit_z?: number;
+  stop_z?: number;
+  // Skip entries below this log-return correlation (0 = no filter)
+  min_correlation?: number;
+  // Notional of leg A in USDT
+  notional_usd: number;
+  leverage: number;
+  // Shared stop on the combined loss of both legs (% of pair notional),
+  // checked by the bot once per scan interval rather than placed on the exchange
+  stop_loss_pct?: number;
+  max_holding_bars?: number;
+  // Ask the AI to confirm each entry signal
+  ai_confirm?: boolean;
 }
 
 export interface CoinSourceConfig {
@@ -824,4 +1033,14 @@ export interface GridRiskInfo {
   // Breakout state
   breakout_level: string
   breakout_direction: string
+
+  // Trailing grid: realised grid profit and inventory PnL kept apart
+  realized_grid_profit?: number
+  unrealized_inventory_pnl?: number
+  base_inventory_qty?: number
+  trail_count?: number
+
+  // Basket: the fields above describe the first symbol, basket lists every symbol
+  symbol?: string
+  basket?: GridRiskInfo[]
 }